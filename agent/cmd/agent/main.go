@@ -6,7 +6,8 @@
 //  2. Build logger
 //  3. Extract embedded restic and rclone binaries (idempotent)
 //  4. Optionally connect to Docker (non-fatal if unavailable)
-//  5. Build executor (job queue + restic wrapper + hooks runner)
+//  5. Open the keystore and build executor (job queue + restic wrapper +
+//     hooks runner)
 //  6. Build connection manager (gRPC client)
 //  7. Start executor worker and connection loop
 //  8. Block until SIGINT/SIGTERM, then graceful shutdown
@@ -15,11 +16,14 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -30,7 +34,9 @@ import (
 	"github.com/arkeep-io/arkeep/agent/internal/docker"
 	"github.com/arkeep-io/arkeep/agent/internal/executor"
 	"github.com/arkeep-io/arkeep/agent/internal/hooks"
+	"github.com/arkeep-io/arkeep/agent/internal/keystore"
 	"github.com/arkeep-io/arkeep/agent/internal/restic"
+	"github.com/arkeep-io/arkeep/shared/keyseal"
 )
 
 var (
@@ -40,15 +46,16 @@ var (
 )
 
 type config struct {
-	serverAddr     string
-	serverHTTPAddr string
-	sharedSecret   string
-	stateDir       string
-	dockerSocket   string
-	logLevel       string
-	grpcTLSCA      string
-	grpcInsecure   bool
-	dockerHostRoot string
+	serverAddr      string
+	serverHTTPAddr  string
+	sharedSecret    string
+	stateDir        string
+	dockerSocket    string
+	logLevel        string
+	grpcTLSCA       string
+	grpcInsecure    bool
+	dockerHostRoot  string
+	allowedCommands []string
 }
 
 func main() {
@@ -73,6 +80,7 @@ receives backup jobs, and executes them using the embedded restic binary.`,
 	}
 
 	root.AddCommand(newVersionCmd())
+	root.AddCommand(newEscrowCmd())
 
 	root.PersistentFlags().StringVar(&cfg.serverAddr, "server-addr", envOrDefault("ARKEEP_SERVER_ADDR", "localhost:9090"), "Arkeep server gRPC address (host:port)")
 	root.PersistentFlags().StringVar(&cfg.sharedSecret, "agent-secret", envOrDefault("ARKEEP_AGENT_SECRET", ""), "Shared secret for gRPC authentication (must match server ARKEEP_AGENT_SECRET)")
@@ -82,6 +90,7 @@ receives backup jobs, and executes them using the embedded restic binary.`,
 	root.PersistentFlags().StringVar(&cfg.grpcTLSCA, "grpc-tls-ca", envOrDefault("ARKEEP_GRPC_TLS_CA", ""), "Path to CA certificate for gRPC TLS (for self-signed server certs; leave empty for system pool)")
 	root.PersistentFlags().BoolVar(&cfg.grpcInsecure, "grpc-insecure", envOrDefault("ARKEEP_GRPC_INSECURE", "false") == "true", "Disable TLS for gRPC transport (development only — never use in production)")
 	root.PersistentFlags().StringVar(&cfg.serverHTTPAddr, "server-http-addr", envOrDefault("ARKEEP_SERVER_HTTP_ADDR", ""), "Base URL of the server HTTP API for enrollment (default: derived from --server-addr with port 8080)")
+	root.PersistentFlags().StringSliceVar(&cfg.allowedCommands, "allowed-commands", envList("ARKEEP_ALLOWED_COMMANDS"), "Programs command sources may run on this agent, by name (looked up in PATH) or absolute path, comma-separated (default: none, command sources are refused)")
 	root.PersistentFlags().StringVar(&cfg.dockerHostRoot, "docker-host-root", envOrDefault("ARKEEP_DOCKER_HOST_ROOT", ""), "Container path where the host filesystem is mounted (default: /hostfs when running inside Docker, empty otherwise). Override only if you mount the host filesystem at a custom path.")
 
 	return root
@@ -97,6 +106,80 @@ func newVersionCmd() *cobra.Command {
 	}
 }
 
+// newEscrowCmd returns the admin tooling of zero-knowledge policies' escrow
+// kits. Both subcommands run offline, on the machine that holds the escrow
+// private key, never on the server.
+func newEscrowCmd() *cobra.Command {
+	escrow := &cobra.Command{
+		Use:   "escrow",
+		Short: "Manage the escrow kits of zero-knowledge policies",
+	}
+
+	var privateKeyFile string
+	keygen := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate an escrow key pair",
+		Long: `Generate the X25519 key pair escrow kits are sealed to. The private key is
+written to --private-key-file (which must not exist); the public key is
+printed, to be set in Settings > Escrow on the server.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			priv, pub, err := keyseal.GenerateKey()
+			if err != nil {
+				return err
+			}
+			f, err := os.OpenFile(privateKeyFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to create private key file: %w", err)
+			}
+			if _, err := fmt.Fprintln(f, priv); err != nil {
+				_ = f.Close()
+				return fmt.Errorf("failed to write private key file: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write private key file: %w", err)
+			}
+			fmt.Printf("public key:  %s\nfingerprint: %s\n", pub, keyseal.Fingerprint(pub))
+			return nil
+		},
+	}
+	keygen.Flags().StringVar(&privateKeyFile, "private-key-file", "arkeep-escrow.key", "File to write the escrow private key to")
+
+	open := &cobra.Command{
+		Use:   "open <kit-file>",
+		Short: "Open an escrow kit and print its repository keys",
+		Long: `Open an escrow kit downloaded from GET /api/v1/agents/{id}/escrow-kit (the
+base64 "kit" field, saved to a file) with the escrow private key, and print
+the repository keys as JSON. A key ID is "<policy ID>/<destination ID>"; its
+password opens the policy's repository on that destination with plain restic.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			priv, err := os.ReadFile(privateKeyFile)
+			if err != nil {
+				return fmt.Errorf("failed to read private key file: %w", err)
+			}
+			raw, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read kit file: %w", err)
+			}
+			sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(raw)))
+			if err != nil {
+				return fmt.Errorf("kit file is not base64: %w", err)
+			}
+			kit, err := keystore.OpenEscrowKit(string(bytes.TrimSpace(priv)), sealed)
+			if err != nil {
+				return err
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(kit)
+		},
+	}
+	open.Flags().StringVar(&privateKeyFile, "private-key-file", "arkeep-escrow.key", "File holding the escrow private key")
+
+	escrow.AddCommand(keygen, open)
+	return escrow
+}
+
 func run(ctx context.Context, cfg *config) error {
 	logger, err := buildLogger(cfg.logLevel)
 	if err != nil {
@@ -171,8 +254,20 @@ func run(ctx context.Context, cfg *config) error {
 	// --- Hooks runner ---
 	hooksRunner := hooks.NewRunner(0) // 0 = use DefaultTimeout (5 minutes)
 
+	// --- Keystore ---
+	// Holds the repository keys of zero-knowledge policies. The fingerprint
+	// of the key-share public key is logged so that admins can check it
+	// against the one the server shows when sharing a key with this agent.
+	keys, err := keystore.Open(cfg.stateDir)
+	if err != nil {
+		return fmt.Errorf("failed to open keystore: %w", err)
+	}
+	logger.Info("keystore ready",
+		zap.String("key_share_fingerprint", keyseal.Fingerprint(keys.PublicKey())),
+	)
+
 	// --- Executor ---
-	exec := executor.New(wrapper, dockerClient, hooksRunner, logger, cfg.dockerHostRoot)
+	exec := executor.New(wrapper, dockerClient, hooksRunner, keys, logger, cfg.dockerHostRoot, cfg.allowedCommands)
 
 	// --- Load mTLS credentials from state-dir (written by enrollment) ---
 	// If all three files are present the agent was enrolled previously and can
@@ -213,7 +308,7 @@ func run(ctx context.Context, cfg *config) error {
 
 	// Pass dockerClient so the connection manager can handle JOB_TYPE_LIST_VOLUMES
 	// requests from the server. May be nil if Docker is unavailable on this host.
-	mgr := connection.New(connCfg, exec, dockerClient, logger)
+	mgr := connection.New(connCfg, exec, dockerClient, keys, logger)
 
 	// --- Start ---
 	// The executor worker and connection manager run concurrently.
@@ -297,6 +392,18 @@ func envOrDefault(key, defaultVal string) string {
 	return defaultVal
 }
 
+// envList returns the comma-separated values of an environment variable,
+// nil when it is unset or empty.
+func envList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 // isRunningInDocker reports whether the process is running inside a Docker
 // container. It checks /.dockerenv first (created by the Docker runtime in
 // most configurations), then falls back to scanning /proc/self/cgroup for
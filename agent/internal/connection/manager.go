@@ -42,7 +42,10 @@ import (
 
 	"github.com/arkeep-io/arkeep/agent/internal/docker"
 	"github.com/arkeep-io/arkeep/agent/internal/executor"
+	"github.com/arkeep-io/arkeep/agent/internal/keystore"
 	"github.com/arkeep-io/arkeep/agent/internal/metrics"
+	"github.com/arkeep-io/arkeep/agent/internal/restic"
+	"github.com/arkeep-io/arkeep/shared/keyseal"
 	proto "github.com/arkeep-io/arkeep/shared/proto"
 )
 
@@ -159,6 +162,7 @@ type Manager struct {
 	cfg    Config
 	exec   *executor.Executor
 	docker *docker.Client // may be nil if Docker is unavailable on this host
+	keys   *keystore.Store
 	logger *zap.Logger
 
 	// mu protects client and logStreams — both are replaced on every reconnect.
@@ -173,12 +177,14 @@ type Manager struct {
 
 // New creates a Manager. Call Run to start the connection loop.
 // dockerClient may be nil — if it is, LIST_VOLUMES requests are answered
-// with an error instead of crashing.
-func New(cfg Config, exec *executor.Executor, dockerClient *docker.Client, logger *zap.Logger) *Manager {
+// with an error instead of crashing. keys answers SHARE_KEY requests and
+// provides the public key announced at registration.
+func New(cfg Config, exec *executor.Executor, dockerClient *docker.Client, keys *keystore.Store, logger *zap.Logger) *Manager {
 	return &Manager{
 		cfg:        cfg,
 		exec:       exec,
 		docker:     dockerClient,
+		keys:       keys,
 		logger:     logger.Named("connection"),
 		logStreams:  make(map[string]proto.AgentService_StreamLogsClient),
 	}
@@ -469,6 +475,9 @@ func (m *Manager) register(ctx context.Context, client proto.AgentServiceClient)
 		Arch:         runtime.GOARCH,
 		Capabilities: caps,
 		AgentId:      state.AgentID, // empty on first run; server uses it as primary dedup key
+		// Other agents seal the keys of zero-knowledge policies they share
+		// with this one to this key.
+		KeySharePublicKey: m.keys.PublicKey(),
 	})
 	if err != nil {
 		return "", "", fmt.Errorf("register RPC failed: %w", err)
@@ -535,6 +544,31 @@ func (m *Manager) jobStreamLoop(ctx context.Context, client proto.AgentServiceCl
 			continue
 		}
 
+		// SHARE_KEY seals a repository key to another agent; like
+		// LIST_VOLUMES it is answered inline via its own RPC.
+		if assignment.Type == proto.JobType_JOB_TYPE_SHARE_KEY {
+			go m.handleKeyShareRequest(assignment.JobId, assignment.Payload, agentID)
+			continue
+		}
+
+		// SCAN_REPOSITORY lists the snapshots of a repository being
+		// imported; like LIST_VOLUMES it is answered inline via its own RPC.
+		if assignment.Type == proto.JobType_JOB_TYPE_SCAN_REPOSITORY {
+			go m.handleRepositoryScanRequest(assignment.JobId, assignment.Payload, agentID)
+			continue
+		}
+
+		// ABORT targets a job the executor is running or has queued; like
+		// LIST_VOLUMES it never reaches the queue.
+		if assignment.Type == proto.JobType_JOB_TYPE_ABORT {
+			if !m.exec.Abort(assignment.JobId) {
+				m.logger.Warn("abort requested for a job that is neither running nor queued",
+					zap.String("job_id", assignment.JobId),
+				)
+			}
+			continue
+		}
+
 		job, err := m.protoToJob(assignment)
 		if err != nil {
 			m.logger.Error("failed to parse job assignment",
@@ -600,6 +634,123 @@ func (m *Manager) handleVolumeListRequest(correlationID, agentID string) {
 	}
 }
 
+// keySharePayload mirrors the struct serialized by the server scheduler for
+// JOB_TYPE_SHARE_KEY assignments.
+type keySharePayload struct {
+	KeyID              string `json:"key_id"`
+	RecipientPublicKey string `json:"recipient_public_key"`
+	KeyFingerprint     string `json:"key_fingerprint"`
+}
+
+// handleKeyShareRequest seals the repository key named in payload to the
+// recipient agent's public key and reports it back via the ReportKeyShare
+// RPC. shareID is the assignment's job ID. The key never leaves the agent
+// unsealed; the server only relays the sealed key to the recipient.
+func (m *Manager) handleKeyShareRequest(shareID string, payload []byte, agentID string) {
+	m.mu.RLock()
+	client := m.client
+	ctx := m.sessionCtx
+	m.mu.RUnlock()
+
+	if client == nil {
+		m.logger.Warn("handleKeyShareRequest: no active client, cannot respond",
+			zap.String("share_id", shareID),
+		)
+		return
+	}
+
+	report := &proto.KeyShareReport{
+		AgentId: agentID,
+		ShareId: shareID,
+	}
+	var req keySharePayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		report.Error = fmt.Sprintf("invalid key share payload: %v", err)
+	} else if key, err := m.keys.Get(req.KeyID); err != nil {
+		report.Error = err.Error()
+	} else if req.KeyFingerprint != "" && keyseal.Fingerprint(key) != req.KeyFingerprint {
+		report.Error = keystore.ErrFingerprintMismatch.Error()
+	} else if sealed, err := m.keys.Share(req.KeyID, req.RecipientPublicKey); err != nil {
+		report.Error = err.Error()
+	} else {
+		report.SealedKey = sealed
+		m.logger.Info("repository key shared",
+			zap.String("share_id", shareID),
+			zap.String("key_id", req.KeyID),
+			zap.String("recipient_key_fingerprint", keyseal.Fingerprint(req.RecipientPublicKey)),
+		)
+	}
+
+	if _, err := client.ReportKeyShare(ctx, report); err != nil {
+		m.logger.Warn("handleKeyShareRequest: ReportKeyShare RPC failed",
+			zap.String("share_id", shareID),
+			zap.Error(err),
+		)
+	}
+}
+
+// handleRepositoryScanRequest lists the snapshots of the repository in
+// payload and reports them back via the ReportRepositoryScan RPC. importID
+// is the assignment's job ID.
+func (m *Manager) handleRepositoryScanRequest(importID string, payload []byte, agentID string) {
+	m.mu.RLock()
+	client := m.client
+	ctx := m.sessionCtx
+	m.mu.RUnlock()
+
+	if client == nil {
+		m.logger.Warn("handleRepositoryScanRequest: no active client, cannot respond",
+			zap.String("import_id", importID),
+		)
+		return
+	}
+
+	report := &proto.RepositoryScanReport{
+		AgentId:  agentID,
+		ImportId: importID,
+	}
+	snapshots, err := m.exec.ScanRepository(ctx, payload)
+	if err != nil {
+		report.Error = err.Error()
+	} else {
+		report.Snapshots = make([]*proto.ScannedSnapshot, 0, len(snapshots))
+		for _, snap := range snapshots {
+			t, err := time.Parse(time.RFC3339Nano, snap.Time)
+			if err != nil {
+				m.logger.Warn("skipping snapshot with an invalid time",
+					zap.String("import_id", importID),
+					zap.String("snapshot_id", snap.ID),
+					zap.String("time", snap.Time),
+				)
+				continue
+			}
+			scanned := &proto.ScannedSnapshot{
+				SnapshotId: snap.ID,
+				Time:       timestamppb.New(t),
+				Hostname:   snap.Hostname,
+				Paths:      snap.Paths,
+				Tags:       snap.Tags,
+			}
+			if snap.Summary != nil {
+				scanned.SizeBytes = snap.Summary.TotalBytesProcessed
+				scanned.FileCount = snap.Summary.TotalFilesProcessed
+			}
+			report.Snapshots = append(report.Snapshots, scanned)
+		}
+		m.logger.Info("repository scanned",
+			zap.String("import_id", importID),
+			zap.Int("snapshots", len(report.Snapshots)),
+		)
+	}
+
+	if _, err := client.ReportRepositoryScan(ctx, report); err != nil {
+		m.logger.Warn("handleRepositoryScanRequest: ReportRepositoryScan RPC failed",
+			zap.String("import_id", importID),
+			zap.Error(err),
+		)
+	}
+}
+
 // SendLog implements executor.LogSink. It writes a log entry to the open
 // StreamLogs stream for the given job. If no stream is open the line is
 // dropped with a warning — this should not happen in normal operation because
@@ -685,6 +836,18 @@ func (m *Manager) closeLogStream(jobID string) {
 //   - "running"          → opens the log stream before reporting
 //   - "success"/"failed" → reports status then closes the log stream
 func (m *Manager) ReportStatus(jobID, status, message string) {
+	m.reportStatus(jobID, status, "", message)
+}
+
+// ReportFailure implements executor.StatusReporter. It reports a failed job
+// together with the failure class the server uses for retry decisions.
+func (m *Manager) ReportFailure(jobID, failureClass, message string) {
+	m.reportStatus(jobID, "failed", failureClass, message)
+}
+
+// reportStatus sends a JobStatusReport and manages the job's log stream:
+// opened on "running", closed on any terminal status.
+func (m *Manager) reportStatus(jobID, status, failureClass, message string) {
 	if status == "running" {
 		m.openLogStream(jobID)
 	}
@@ -698,9 +861,10 @@ func (m *Manager) ReportStatus(jobID, status, message string) {
 		_, err := client.ReportJobStatus(m.sessionCtx, &proto.JobStatusReport{
 			JobId:     jobID,
 			AgentId:   agentID,
-			Status:    statusToProto(status),
-			Message:   message,
-			Timestamp: timestamppb.Now(),
+			Status:       statusToProto(status),
+			Message:      message,
+			Timestamp:    timestamppb.Now(),
+			FailureClass: failureClassToProto(failureClass),
 		})
 		if err != nil {
 			m.logger.Warn("ReportStatus: RPC failed",
@@ -723,8 +887,10 @@ func (m *Manager) ReportStatus(jobID, status, message string) {
 
 // ReportDestinationResult implements executor.StatusReporter. It calls
 // ReportDestinationStatus via gRPC to persist the per-destination outcome
-// (snapshot ID, size, status, started_at) after each destination backup completes or fails.
-func (m *Manager) ReportDestinationResult(jobID, destinationID, status, snapshotID string, startedAt time.Time, sizeBytes int64, errMsg string) {
+// (snapshot ID, size, change statistics, status, started_at) after each
+// destination backup completes or fails. result is nil on failure. Returns
+// the snapshot the server asks to hold, or "".
+func (m *Manager) ReportDestinationResult(jobID, destinationID, status string, startedAt time.Time, result *restic.BackupResult, keyFingerprint, errMsg string) string {
 	m.mu.RLock()
 	client := m.client
 	agentID := m.agentID
@@ -736,19 +902,44 @@ func (m *Manager) ReportDestinationResult(jobID, destinationID, status, snapshot
 			zap.String("destination_id", destinationID),
 			zap.String("status", status),
 		)
-		return
+		return ""
 	}
 
-	_, err := client.ReportDestinationStatus(m.sessionCtx, &proto.DestinationStatusReport{
+	report := &proto.DestinationStatusReport{
 		JobId:         jobID,
 		AgentId:       agentID,
 		DestinationId: destinationID,
 		Status:        status,
-		SnapshotId:    snapshotID,
-		SizeBytes:     sizeBytes,
-		Error:         errMsg,
-		StartedAt:     timestamppb.New(startedAt),
-	})
+		Error:          errMsg,
+		StartedAt:      timestamppb.New(startedAt),
+		KeyFingerprint: keyFingerprint,
+	}
+	if result != nil {
+		report.SnapshotId = result.SnapshotID
+		report.SizeBytes = int64(result.TotalBytesProcessed)
+		report.FilesNew = result.FilesNew
+		report.FilesChanged = result.FilesChanged
+		report.FilesUnmodified = result.FilesUnmodified
+		report.TotalFiles = result.TotalFiles
+		report.DataAdded = result.DataAdded
+		report.FileErrorCount = result.FileErrorCount
+		for _, fe := range result.FileErrors {
+			report.FileErrors = append(report.FileErrors, &proto.FileError{
+				Path:    fe.Path,
+				During:  fe.During,
+				Message: fe.Message,
+			})
+		}
+		for _, ss := range result.StdinSnapshots {
+			report.StdinSnapshots = append(report.StdinSnapshots, &proto.StdinSnapshot{
+				SnapshotId: ss.SnapshotID,
+				Filename:   ss.Filename,
+				Tags:       ss.Tags,
+				SizeBytes:  int64(ss.SizeBytes),
+			})
+		}
+	}
+	resp, err := client.ReportDestinationStatus(m.sessionCtx, report)
 	if err != nil {
 		m.logger.Warn("ReportDestinationResult: RPC failed",
 			zap.String("job_id", jobID),
@@ -756,21 +947,209 @@ func (m *Manager) ReportDestinationResult(jobID, destinationID, status, snapshot
 			zap.String("status", status),
 			zap.Error(err),
 		)
+		return ""
+	}
+	return resp.GetHoldSnapshotId()
+}
+
+// ReportReplication implements executor.StatusReporter. It calls
+// ReportDestinationStatus via gRPC with the snapshots a replication job
+// found copied to a destination, so the server records them in the catalog.
+func (m *Manager) ReportReplication(jobID, destinationID string, startedAt time.Time, copies []restic.SnapshotCopy) {
+	m.mu.RLock()
+	client := m.client
+	agentID := m.agentID
+	m.mu.RUnlock()
+
+	if client == nil {
+		m.logger.Warn("ReportReplication: no active client, result lost",
+			zap.String("job_id", jobID),
+			zap.String("destination_id", destinationID),
+		)
+		return
+	}
+
+	report := &proto.DestinationStatusReport{
+		JobId:         jobID,
+		AgentId:       agentID,
+		DestinationId: destinationID,
+		Status:        "succeeded",
+		StartedAt:     timestamppb.New(startedAt),
+	}
+	for _, c := range copies {
+		copied := &proto.CopiedSnapshot{
+			SnapshotId: c.ID,
+			OriginalId: c.OriginalID,
+			Tags:       c.Tags,
+			Hostname:   c.Hostname,
+		}
+		if t, err := time.Parse(time.RFC3339Nano, c.Time); err == nil {
+			copied.Time = timestamppb.New(t)
+		}
+		report.CopiedSnapshots = append(report.CopiedSnapshots, copied)
+	}
+	if _, err := client.ReportDestinationStatus(m.sessionCtx, report); err != nil {
+		m.logger.Warn("ReportReplication: RPC failed",
+			zap.String("job_id", jobID),
+			zap.String("destination_id", destinationID),
+			zap.Error(err),
+		)
 	}
 }
 
+// ReportSnapshotHold implements executor.StatusReporter. It calls
+// ReportSnapshotHold via gRPC so the server follows the snapshot restic
+// rewrote when it was tagged as held.
+func (m *Manager) ReportSnapshotHold(jobID, destinationID, oldSnapshotID, newSnapshotID string) {
+	m.mu.RLock()
+	client := m.client
+	agentID := m.agentID
+	m.mu.RUnlock()
+
+	if client == nil {
+		m.logger.Warn("ReportSnapshotHold: no active client, result lost",
+			zap.String("job_id", jobID),
+			zap.String("snapshot_id", oldSnapshotID),
+		)
+		return
+	}
+
+	_, err := client.ReportSnapshotHold(m.sessionCtx, &proto.SnapshotHoldReport{
+		JobId:         jobID,
+		AgentId:       agentID,
+		DestinationId: destinationID,
+		OldSnapshotId: oldSnapshotID,
+		NewSnapshotId: newSnapshotID,
+	})
+	if err != nil {
+		m.logger.Warn("ReportSnapshotHold: RPC failed",
+			zap.String("job_id", jobID),
+			zap.String("snapshot_id", oldSnapshotID),
+			zap.Error(err),
+		)
+	}
+}
+
+// ReportProgress implements executor.StatusReporter. It calls ReportProgress
+// via gRPC with the latest restic status event of a running job. Progress is
+// best effort: a lost report is superseded by the next one, so failures are
+// only logged at debug level.
+func (m *Manager) ReportProgress(jobID, destinationID string, ev restic.ProgressEvent) {
+	m.mu.RLock()
+	client := m.client
+	agentID := m.agentID
+	m.mu.RUnlock()
+
+	if client == nil {
+		return
+	}
+
+	report := &proto.JobProgress{
+		JobId:            jobID,
+		AgentId:          agentID,
+		DestinationId:    destinationID,
+		PercentDone:      ev.PercentDone,
+		FilesDone:        ev.FilesDone,
+		TotalFiles:       ev.TotalFiles,
+		BytesDone:        ev.BytesDone,
+		TotalBytes:       ev.TotalBytes,
+		SecondsRemaining: ev.SecondsRemaining,
+		SecondsElapsed:   ev.SecondsElapsed,
+	}
+	if len(ev.CurrentFiles) > 0 {
+		report.CurrentFile = ev.CurrentFiles[0]
+	}
+	if _, err := client.ReportProgress(m.sessionCtx, report); err != nil {
+		m.logger.Debug("ReportProgress: RPC failed",
+			zap.String("job_id", jobID),
+			zap.Error(err),
+		)
+	}
+}
+
+// ReportDryRun implements executor.StatusReporter. It calls ReportDryRun via
+// gRPC to store the size estimate of a dry-run job.
+func (m *Manager) ReportDryRun(jobID string, result *restic.DryRunResult) {
+	m.mu.RLock()
+	client := m.client
+	agentID := m.agentID
+	m.mu.RUnlock()
+
+	if client == nil {
+		m.logger.Warn("ReportDryRun: no active client, result lost",
+			zap.String("job_id", jobID),
+		)
+		return
+	}
+
+	report := &proto.DryRunReport{
+		JobId:           jobID,
+		AgentId:         agentID,
+		TotalFiles:      result.TotalFiles,
+		TotalBytes:      result.TotalBytes,
+		DataAdded:       result.DataAdded,
+		UnreadableCount: result.UnreadableCount,
+	}
+	for _, d := range result.LargestDirs {
+		report.LargestDirectories = append(report.LargestDirectories, &proto.DirectorySize{
+			Path:  d.Path,
+			Bytes: d.Bytes,
+			Files: d.Files,
+		})
+	}
+	for _, u := range result.Unreadable {
+		report.UnreadablePaths = append(report.UnreadablePaths, &proto.UnreadablePath{
+			Path:  u.Path,
+			Error: u.Error,
+		})
+	}
+
+	if _, err := client.ReportDryRun(m.sessionCtx, report); err != nil {
+		m.logger.Warn("ReportDryRun: RPC failed",
+			zap.String("job_id", jobID),
+			zap.Error(err),
+		)
+	}
+}
+
+// ReportKeyEscrow implements executor.StatusReporter. It calls
+// ReportKeyEscrow via gRPC so the server keeps the agent's latest escrow
+// kit. Unlike the other reports the error is returned: the executor exports
+// the kit again after the next backup until one is delivered.
+func (m *Manager) ReportKeyEscrow(escrowKeyFingerprint string, kit []byte, keyCount int) error {
+	m.mu.RLock()
+	client := m.client
+	agentID := m.agentID
+	m.mu.RUnlock()
+
+	if client == nil {
+		return errors.New("no active client")
+	}
+
+	_, err := client.ReportKeyEscrow(m.sessionCtx, &proto.KeyEscrowReport{
+		AgentId:              agentID,
+		EscrowKeyFingerprint: escrowKeyFingerprint,
+		Kit:                  kit,
+		KeyCount:             uint32(keyCount),
+	})
+	if err != nil {
+		return fmt.Errorf("ReportKeyEscrow RPC failed: %w", err)
+	}
+	return nil
+}
+
 // protoToJob converts a proto.JobAssignment to an executor.JobAssignment.
 // The payload bytes are passed through as-is — the executor deserializes them
-// according to the job type. LIST_VOLUMES assignments never reach this function
-// because they are intercepted earlier in jobStreamLoop.
+// according to the job type. LIST_VOLUMES, SHARE_KEY and ABORT assignments
+// never reach this function because they are intercepted earlier in jobStreamLoop.
 func (m *Manager) protoToJob(p *proto.JobAssignment) (executor.JobAssignment, error) {
 	if p.JobId == "" {
 		return executor.JobAssignment{}, errors.New("job assignment missing job_id")
 	}
 
 	switch p.Type {
-	case proto.JobType_JOB_TYPE_BACKUP, proto.JobType_JOB_TYPE_RESTORE:
-		// Both types are handled by the executor — payload is passed through as-is.
+	case proto.JobType_JOB_TYPE_BACKUP, proto.JobType_JOB_TYPE_RESTORE, proto.JobType_JOB_TYPE_DRY_RUN, proto.JobType_JOB_TYPE_REPLICATE:
+		// All four types are handled by the executor — payload is passed through as-is.
 	default:
 		return executor.JobAssignment{}, fmt.Errorf("unsupported job type: %v", p.Type)
 	}
@@ -811,6 +1190,22 @@ func statusToProto(status string) proto.JobStatus {
 	}
 }
 
+// failureClassToProto converts an executor failure class to the proto enum.
+func failureClassToProto(class string) proto.FailureClass {
+	switch class {
+	case executor.FailureNetwork:
+		return proto.FailureClass_FAILURE_CLASS_NETWORK
+	case executor.FailureHook:
+		return proto.FailureClass_FAILURE_CLASS_HOOK
+	case executor.FailureWrongPassword:
+		return proto.FailureClass_FAILURE_CLASS_WRONG_PASSWORD
+	case executor.FailureOther:
+		return proto.FailureClass_FAILURE_CLASS_OTHER
+	default:
+		return proto.FailureClass_FAILURE_CLASS_UNSPECIFIED
+	}
+}
+
 // nextBackoff returns the next backoff duration, capped at backoffMax.
 func nextBackoff(current time.Duration) time.Duration {
 	next := time.Duration(float64(current) * backoffFactor)
@@ -0,0 +1,230 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// VolumeContainer is a container that mounts a given volume.
+type VolumeContainer struct {
+	// ID is the full container ID.
+	ID string
+	// Name is the human-readable name of the container (without leading slash).
+	Name string
+	// State is the container state as reported by the daemon
+	// (e.g. "running", "paused", "exited").
+	State string
+}
+
+// ListVolumeContainers returns every container, running or not, that mounts
+// the named volume.
+func (c *Client) ListVolumeContainers(ctx context.Context, volume string) ([]VolumeContainer, error) {
+	containers, err := c.docker.ContainerList(ctx, containertypes.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("volume", volume)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDockerUnavailable, err)
+	}
+
+	result := make([]VolumeContainer, 0, len(containers))
+	for _, ctr := range containers {
+		name := ctr.ID[:12]
+		if len(ctr.Names) > 0 {
+			name = strings.TrimPrefix(ctr.Names[0], "/")
+		}
+		result = append(result, VolumeContainer{ID: ctr.ID, Name: name, State: ctr.State})
+	}
+	return result, nil
+}
+
+// ContainerInfo holds the metadata of a container relevant to label-driven
+// volume discovery.
+type ContainerInfo struct {
+	// ID is the full container ID.
+	ID string
+	// Name is the human-readable name of the container (without leading slash).
+	Name string
+	// State is the container state as reported by the daemon.
+	State string
+	// Labels are the Docker labels attached to the container.
+	Labels map[string]string
+	// Volumes are the names of the named volumes the container mounts.
+	// Bind-mounts are not included.
+	Volumes []string
+}
+
+// ListContainers returns every container, running or not, with the named
+// volumes it mounts.
+func (c *Client) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
+	containers, err := c.docker.ContainerList(ctx, containertypes.ListOptions{All: true})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDockerUnavailable, err)
+	}
+
+	result := make([]ContainerInfo, 0, len(containers))
+	for _, ctr := range containers {
+		name := ctr.ID[:12]
+		if len(ctr.Names) > 0 {
+			name = strings.TrimPrefix(ctr.Names[0], "/")
+		}
+		info := ContainerInfo{ID: ctr.ID, Name: name, State: ctr.State, Labels: ctr.Labels}
+		for _, m := range ctr.Mounts {
+			if m.Type == mount.TypeVolume && m.Name != "" {
+				info.Volumes = append(info.Volumes, m.Name)
+			}
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// ExecResult holds the outcome of a command run inside a container.
+type ExecResult struct {
+	// Output is the combined stdout and stderr of the command.
+	Output string
+	// ExitCode is the exit status of the command.
+	ExitCode int
+}
+
+// Exec runs cmd inside a running container and waits for it to exit.
+// Cancelling ctx stops waiting but does not kill the process: the daemon
+// has no API for it. A non-zero exit status is not an error, callers check
+// ExitCode.
+func (c *Client) Exec(ctx context.Context, id string, cmd []string) (ExecResult, error) {
+	created, err := c.docker.ContainerExecCreate(ctx, id, containertypes.ExecOptions{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("docker: exec in container %s: %w", id, err)
+	}
+
+	attach, err := c.docker.ContainerExecAttach(ctx, created.ID, containertypes.ExecAttachOptions{})
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("docker: exec in container %s: %w", id, err)
+	}
+	defer attach.Close()
+
+	// The stream ends when the process exits. Reading it in a goroutine
+	// lets a cancelled ctx return without waiting for the process.
+	var output bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(&output, &output, attach.Reader)
+		done <- err
+	}()
+	select {
+	case <-ctx.Done():
+		return ExecResult{}, ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return ExecResult{}, fmt.Errorf("docker: exec in container %s: read output: %w", id, err)
+		}
+	}
+
+	inspect, err := c.docker.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("docker: exec in container %s: %w", id, err)
+	}
+	return ExecResult{Output: strings.TrimSpace(output.String()), ExitCode: inspect.ExitCode}, nil
+}
+
+// ExecStream runs cmd inside a running container with the extra environment
+// env, copying its stdout and stderr to the given writers as it runs, and
+// returns its exit status once it has exited. Cancelling ctx closes the
+// stream: the process then gets a broken pipe on its next write.
+func (c *Client) ExecStream(ctx context.Context, id string, cmd, env []string, stdout, stderr io.Writer) (int, error) {
+	created, err := c.docker.ContainerExecCreate(ctx, id, containertypes.ExecOptions{
+		Cmd:          cmd,
+		Env:          env,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("docker: exec in container %s: %w", id, err)
+	}
+
+	attach, err := c.docker.ContainerExecAttach(ctx, created.ID, containertypes.ExecAttachOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("docker: exec in container %s: %w", id, err)
+	}
+	defer attach.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(stdout, stderr, attach.Reader)
+		done <- err
+	}()
+	select {
+	case <-ctx.Done():
+		attach.Close()
+		<-done
+		return 0, ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return 0, fmt.Errorf("docker: exec in container %s: %w", id, err)
+		}
+	}
+
+	inspect, err := c.docker.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return 0, fmt.Errorf("docker: exec in container %s: %w", id, err)
+	}
+	return inspect.ExitCode, nil
+}
+
+// ContainerEnv returns the environment variables a container was created
+// with, as KEY=value strings.
+func (c *Client) ContainerEnv(ctx context.Context, id string) ([]string, error) {
+	info, err := c.docker.ContainerInspect(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("docker: inspect container %s: %w", id, err)
+	}
+	if info.Config == nil {
+		return nil, nil
+	}
+	return info.Config.Env, nil
+}
+
+// StopContainer stops a container, killing it if it has not exited after
+// timeoutSeconds.
+func (c *Client) StopContainer(ctx context.Context, id string, timeoutSeconds int) error {
+	if err := c.docker.ContainerStop(ctx, id, containertypes.StopOptions{Timeout: &timeoutSeconds}); err != nil {
+		return fmt.Errorf("docker: stop container %s: %w", id, err)
+	}
+	return nil
+}
+
+// StartContainer starts a stopped container.
+func (c *Client) StartContainer(ctx context.Context, id string) error {
+	if err := c.docker.ContainerStart(ctx, id, containertypes.StartOptions{}); err != nil {
+		return fmt.Errorf("docker: start container %s: %w", id, err)
+	}
+	return nil
+}
+
+// PauseContainer freezes the processes of a running container.
+func (c *Client) PauseContainer(ctx context.Context, id string) error {
+	if err := c.docker.ContainerPause(ctx, id); err != nil {
+		return fmt.Errorf("docker: pause container %s: %w", id, err)
+	}
+	return nil
+}
+
+// UnpauseContainer resumes the processes of a paused container.
+func (c *Client) UnpauseContainer(ctx context.Context, id string) error {
+	if err := c.docker.ContainerUnpause(ctx, id); err != nil {
+		return fmt.Errorf("docker: unpause container %s: %w", id, err)
+	}
+	return nil
+}
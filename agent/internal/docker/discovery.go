@@ -1,10 +1,12 @@
-// Package docker provides read-only discovery of Docker volumes via the Docker
-// daemon socket. It is used by the executor to resolve backup sources of the
-// form "docker-volume://<volume-name>" into the actual mountpoint path on the
+// Package docker provides discovery of Docker volumes via the Docker daemon
+// socket. It is used by the executor to resolve backup sources of the form
+// "docker-volume://<volume-name>" into the actual mountpoint path on the
 // host filesystem.
 //
-// The Docker socket is mounted read-only in the agent container — this package
-// never writes to the daemon, it only issues List and Inspect calls.
+// Discovery only issues List and Inspect calls. The only calls that change
+// daemon state are the container lifecycle methods in containers.go, used
+// to stop or pause the containers of a volume while it is backed up when
+// the source asks for it.
 //
 // If Docker is not available on the host (socket missing or daemon not running),
 // all methods return ErrDockerUnavailable so the executor can skip volume
@@ -0,0 +1,112 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/arkeep-io/arkeep/agent/internal/restic"
+)
+
+// commandPayload mirrors the struct serialized by the server scheduler for
+// command sources: a command whose stdout is stored as Filename in a
+// snapshot of its own, with Tags on top of the job's.
+type commandPayload struct {
+	Command  string   `json:"command"`
+	Filename string   `json:"filename"`
+	Tags     []string `json:"tags"`
+}
+
+// splitCommand splits a command source into its program and arguments.
+// restic runs them without a shell, so words are only split on blanks and
+// grouped with single or double quotes; shell operators are rejected rather
+// than passed on as literal arguments.
+func splitCommand(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case strings.ContainsRune("|&;<>$`\n", r):
+			return nil, fmt.Errorf("command sources run without a shell: %q is not supported", r)
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote in command")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	if len(words) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return words, nil
+}
+
+// commandArgs returns the program and arguments of a command source, or an
+// error when the program is not allowed on this agent. Programs are matched
+// as written: an allowed path allows that path only, and an allowed name the
+// program looked up in PATH, never another path ending with it.
+func (e *Executor) commandArgs(command string) ([]string, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(e.allowedCommands, args[0]) {
+		return nil, fmt.Errorf("%s is not in this agent's allowed commands (--allowed-commands)", args[0])
+	}
+	return args, nil
+}
+
+// backupCommands stores the output of each command source of the job in a
+// snapshot of its own on d. restic runs the command itself, with
+// --stdin-from-command, and fails the backup when it exits non-zero. It
+// returns the snapshots taken, and stops at the first failure.
+func (e *Executor) backupCommands(ctx context.Context, run *backupRun, d restic.Destination, dest destinationPayload) ([]restic.StdinSnapshot, error) {
+	var snapshots []restic.StdinSnapshot
+	for _, c := range run.payload.Commands {
+		args, err := e.commandArgs(c.Command)
+		if err != nil {
+			return snapshots, fmt.Errorf("command %q: %w", c.Command, err)
+		}
+		run.log("info", fmt.Sprintf("backing up the output of %s as %s to destination %s", args[0], c.Filename, dest.DestinationID))
+
+		tags := append(slices.Clone(run.payload.Tags), c.Tags...)
+		result, err := e.wrapper.Backup(ctx, d, restic.BackupOptions{
+			Tags:          tags,
+			StdinCommand:  args,
+			StdinFilename: c.Filename,
+		}, nil)
+		if err != nil {
+			return snapshots, fmt.Errorf("command %q failed: %w", c.Command, err)
+		}
+		run.log("info", fmt.Sprintf("output of %s completed (snapshot: %s, size: %d bytes)", args[0], result.SnapshotID, result.TotalBytesProcessed))
+		snapshots = append(snapshots, restic.StdinSnapshot{
+			SnapshotID: result.SnapshotID,
+			Filename:   c.Filename,
+			Tags:       tags,
+			SizeBytes:  result.TotalBytesProcessed,
+		})
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	cases := map[string][]string{
+		"etcdctl snapshot save -":                 {"etcdctl", "snapshot", "save", "-"},
+		`ldapsearch -x -b "dc=example,dc=com"`:    {"ldapsearch", "-x", "-b", "dc=example,dc=com"},
+		"  vault operator raft snapshot save '' ": {"vault", "operator", "raft", "snapshot", "save", ""},
+		`/opt/bin/export --label 'a "b" c'`:       {"/opt/bin/export", "--label", `a "b" c`},
+	}
+	for command, want := range cases {
+		got, err := splitCommand(command)
+		if err != nil {
+			t.Errorf("splitCommand(%q): %v", command, err)
+			continue
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("splitCommand(%q) = %q, want %q", command, got, want)
+		}
+	}
+
+	for _, command := range []string{"", "   ", "pg_dumpall | gzip", "export; rm -rf /", "echo $HOME", `echo "unterminated`} {
+		if got, err := splitCommand(command); err == nil {
+			t.Errorf("splitCommand(%q) = %q, want an error", command, got)
+		}
+	}
+}
+
+func TestCommandArgs(t *testing.T) {
+	e := &Executor{allowedCommands: []string{"etcdctl", "/usr/local/bin/vault"}}
+	for command, allowed := range map[string]bool{
+		"etcdctl snapshot save -":                          true,
+		"/usr/local/bin/vault operator raft snapshot save": true,
+		"vault operator raft snapshot save":                false,
+		"/tmp/etcdctl snapshot save -":                     false,
+		"sh -c etcdctl":                                    false,
+	} {
+		if _, err := e.commandArgs(command); (err == nil) != allowed {
+			t.Errorf("commandArgs(%q) error = %v, want allowed = %v", command, err, allowed)
+		}
+	}
+
+	if _, err := (&Executor{}).commandArgs("etcdctl snapshot save -"); err == nil {
+		t.Error("commandArgs allowed a command without an allowlist")
+	}
+}
@@ -0,0 +1,200 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/arkeep-io/arkeep/agent/internal/restic"
+)
+
+// containerExecer is the subset of the Docker client used to dump the
+// databases of docker-dump sources. Implemented by *docker.Client.
+type containerExecer interface {
+	ContainerEnv(ctx context.Context, id string) ([]string, error)
+	ExecStream(ctx context.Context, id string, cmd, env []string, stdout, stderr io.Writer) (int, error)
+}
+
+// Exit status of a shell that could not find the command it was asked to
+// run.
+const execNotFound = 127
+
+// containerCredentials returns the user and password the official image of
+// engine was initialised with, read from the container's environment. Empty
+// values are left to the engine's default.
+func containerCredentials(engine string, containerEnv []string) (string, string) {
+	env := make(map[string]string, len(containerEnv))
+	for _, kv := range containerEnv {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	switch engine {
+	case enginePostgres:
+		return env["POSTGRES_USER"], env["POSTGRES_PASSWORD"]
+	case engineMySQL:
+		// The MariaDB images accept the MySQL variables too.
+		for _, prefix := range []string{"MARIADB_", "MYSQL_"} {
+			if password := env[prefix+"ROOT_PASSWORD"]; password != "" {
+				return "root", password
+			}
+		}
+		for _, prefix := range []string{"MARIADB_", "MYSQL_"} {
+			if user := env[prefix+"USER"]; user != "" {
+				return user, env[prefix+"PASSWORD"]
+			}
+		}
+	case engineMongoDB:
+		return env["MONGO_INITDB_ROOT_USERNAME"], env["MONGO_INITDB_ROOT_PASSWORD"]
+	}
+	return "", ""
+}
+
+// containerDumpCommand returns the command that dumps db to stdout inside
+// its container, and the environment it runs with. A custom command runs
+// through sh -c. The presets connect through the server's local socket and
+// dump the whole server when no database is named. With
+// db.ContainerCredentials they take the user and password from containerEnv.
+func containerDumpCommand(db databasePayload, containerEnv []string) ([]string, []string, error) {
+	if db.Engine == "" {
+		if db.Command == "" {
+			return nil, nil, errors.New("no dump command")
+		}
+		return []string{"sh", "-c", db.Command}, nil, nil
+	}
+	if db.ContainerCredentials {
+		db.Username, db.Password = containerCredentials(db.Engine, containerEnv)
+	}
+
+	var env []string
+	switch db.Engine {
+	case enginePostgres:
+		if db.Username == "" {
+			// Run as root, the tools would log in as a "root" role.
+			db.Username = "postgres"
+		}
+		if db.Password != "" {
+			env = append(env, "PGPASSWORD="+db.Password)
+		}
+		args := []string{"--username=" + db.Username, "--no-password", "--clean", "--if-exists"}
+		if db.Database == "" {
+			return append([]string{"pg_dumpall"}, args...), env, nil
+		}
+		return append([]string{"pg_dump", "--dbname=" + db.Database}, args...), env, nil
+
+	case engineMySQL:
+		if db.Username == "" {
+			db.Username = "root"
+		}
+		if db.Password != "" {
+			env = append(env, "MYSQL_PWD="+db.Password)
+		}
+		// MariaDB 11 images no longer ship the mysqldump name.
+		cmd := []string{"sh", "-c", `exec "$(command -v mariadb-dump || echo mysqldump)" "$@"`, "mysqldump",
+			"--user=" + db.Username, "--single-transaction", "--routines", "--triggers"}
+		if db.Database == "" {
+			return append(cmd, "--all-databases"), env, nil
+		}
+		return append(cmd, db.Database), env, nil
+
+	case engineMongoDB:
+		cmd := []string{"mongodump", "--archive"}
+		if db.Username != "" {
+			cmd = append(cmd, "--username="+db.Username, "--authenticationDatabase=admin")
+		}
+		if db.Password != "" {
+			// mongodump reads passwords from the command line or a config
+			// file only. The command line is visible inside the container
+			// alone, whose processes can read the password anyway.
+			cmd = append(cmd, "--password="+db.Password)
+		}
+		if db.Database != "" {
+			cmd = append(cmd, "--db="+db.Database)
+		}
+		return cmd, env, nil
+	}
+	return nil, nil, fmt.Errorf("no dump preset for %q databases in containers", db.Engine)
+}
+
+// dumpContainerDatabase runs the dump of db inside its container, copying
+// the dump to stdout.
+func dumpContainerDatabase(ctx context.Context, ctl containerExecer, db databasePayload, stdout io.Writer) error {
+	var containerEnv []string
+	if db.ContainerCredentials {
+		var err error
+		if containerEnv, err = ctl.ContainerEnv(ctx, db.Container); err != nil {
+			return err
+		}
+	}
+	cmd, env, err := containerDumpCommand(db, containerEnv)
+	if err != nil {
+		return err
+	}
+
+	stderr := &tailBuffer{limit: toolStderrLimit}
+	code, err := ctl.ExecStream(ctx, db.Container, cmd, env, stdout, stderr)
+	if err != nil {
+		return err
+	}
+	name := cmd[0]
+	if db.Engine == "" {
+		name = "the dump command"
+	}
+	switch code {
+	case 0:
+		return nil
+	case execNotFound:
+		return fmt.Errorf("%s is not installed in container %s: %s", name, db.Container, stderr.String())
+	}
+	return fmt.Errorf("%s exited with status %d: %s", name, code, stderr.String())
+}
+
+// backupContainerDatabase pipes the dump of a docker-dump source into
+// restic, like backupDatabase does with the dump tools of the host.
+func (e *Executor) backupContainerDatabase(ctx context.Context, run *backupRun, d restic.Destination, db databasePayload) (restic.StdinSnapshot, error) {
+	if e.docker == nil {
+		return restic.StdinSnapshot{}, errors.New("docker-dump sources require Docker but Docker is unavailable on this host")
+	}
+
+	dumpCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stdout, w := io.Pipe()
+	dumped := make(chan error, 1)
+	go func() {
+		err := dumpContainerDatabase(dumpCtx, e.docker, db, w)
+		w.Close()
+		dumped <- err
+	}()
+
+	tags := append(slices.Clone(run.payload.Tags), db.Tags...)
+	result, backupErr := e.wrapper.Backup(ctx, d, restic.BackupOptions{
+		Tags:          tags,
+		Stdin:         stdout,
+		StdinFilename: db.Filename,
+	}, nil)
+	if backupErr != nil {
+		// restic stopped reading: unblock the copy and stop the dump.
+		stdout.CloseWithError(backupErr)
+		cancel()
+	}
+	dumpErr := <-dumped
+
+	if backupErr != nil {
+		return restic.StdinSnapshot{}, backupErr
+	}
+	if dumpErr != nil {
+		if err := e.wrapper.ForgetSnapshot(context.WithoutCancel(ctx), d, result.SnapshotID); err != nil {
+			run.log("warn", fmt.Sprintf("failed to forget the incomplete snapshot %s: %v", result.SnapshotID, err))
+		}
+		return restic.StdinSnapshot{}, dumpErr
+	}
+	return restic.StdinSnapshot{
+		SnapshotID: result.SnapshotID,
+		Filename:   db.Filename,
+		Tags:       tags,
+		SizeBytes:  result.TotalBytesProcessed,
+	}, nil
+}
@@ -0,0 +1,133 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeExecer is a containerExecer that records the command it runs and
+// plays back a fixed output and exit status.
+type fakeExecer struct {
+	env      []string
+	output   string
+	stderr   string
+	exitCode int
+
+	cmd     []string
+	execEnv []string
+}
+
+func (f *fakeExecer) ContainerEnv(context.Context, string) ([]string, error) {
+	return f.env, nil
+}
+
+func (f *fakeExecer) ExecStream(_ context.Context, _ string, cmd, env []string, stdout, stderr io.Writer) (int, error) {
+	f.cmd, f.execEnv = cmd, env
+	io.WriteString(stdout, f.output)
+	io.WriteString(stderr, f.stderr)
+	return f.exitCode, nil
+}
+
+func TestContainerDumpCommand(t *testing.T) {
+	cases := []struct {
+		name         string
+		db           databasePayload
+		containerEnv []string
+		want         []string
+		env          []string
+	}{
+		{
+			name:         "postgres with the container's credentials",
+			db:           databasePayload{Engine: enginePostgres, Database: "app", ContainerCredentials: true},
+			containerEnv: []string{"POSTGRES_USER=app", "POSTGRES_PASSWORD=s3cret", "PGDATA=/var/lib/postgresql/data"},
+			want:         []string{"pg_dump", "--dbname=app", "--username=app", "--no-password", "--clean", "--if-exists"},
+			env:          []string{"PGPASSWORD=s3cret"},
+		},
+		{
+			name: "whole postgres server",
+			db:   databasePayload{Engine: enginePostgres},
+			want: []string{"pg_dumpall", "--username=postgres", "--no-password", "--clean", "--if-exists"},
+		},
+		{
+			name:         "mariadb root password",
+			db:           databasePayload{Engine: engineMySQL, Database: "shop", ContainerCredentials: true},
+			containerEnv: []string{"MARIADB_USER=shop", "MARIADB_PASSWORD=user-pw", "MARIADB_ROOT_PASSWORD=root-pw"},
+			want: []string{"sh", "-c", `exec "$(command -v mariadb-dump || echo mysqldump)" "$@"`, "mysqldump",
+				"--user=root", "--single-transaction", "--routines", "--triggers", "shop"},
+			env: []string{"MYSQL_PWD=root-pw"},
+		},
+		{
+			name:         "mongodb",
+			db:           databasePayload{Engine: engineMongoDB, Database: "events", ContainerCredentials: true},
+			containerEnv: []string{"MONGO_INITDB_ROOT_USERNAME=admin", "MONGO_INITDB_ROOT_PASSWORD=s3cret"},
+			want:         []string{"mongodump", "--archive", "--username=admin", "--authenticationDatabase=admin", "--password=s3cret", "--db=events"},
+		},
+		{
+			name:         "credentials are only read on request",
+			db:           databasePayload{Engine: engineMongoDB},
+			containerEnv: []string{"MONGO_INITDB_ROOT_USERNAME=admin", "MONGO_INITDB_ROOT_PASSWORD=s3cret"},
+			want:         []string{"mongodump", "--archive"},
+		},
+		{
+			name: "custom command",
+			db:   databasePayload{Command: "influx backup --portable -"},
+			want: []string{"sh", "-c", "influx backup --portable -"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, env, err := containerDumpCommand(tc.db, tc.containerEnv)
+			if err != nil {
+				t.Fatalf("containerDumpCommand: %v", err)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("command = %q, want %q", got, tc.want)
+			}
+			if !slices.Equal(env, tc.env) {
+				t.Errorf("env = %v, want %v", env, tc.env)
+			}
+		})
+	}
+
+	if _, _, err := containerDumpCommand(databasePayload{Engine: engineRedis}, nil); err == nil {
+		t.Error("containerDumpCommand returned a Redis preset")
+	}
+}
+
+func TestDumpContainerDatabase(t *testing.T) {
+	db := databasePayload{Engine: enginePostgres, Database: "app", Container: "pg", ContainerCredentials: true}
+
+	t.Run("streams the dump", func(t *testing.T) {
+		ctl := &fakeExecer{env: []string{"POSTGRES_PASSWORD=s3cret"}, output: "CREATE TABLE t ();\n"}
+		var out bytes.Buffer
+		if err := dumpContainerDatabase(context.Background(), ctl, db, &out); err != nil {
+			t.Fatalf("dumpContainerDatabase: %v", err)
+		}
+		if out.String() != ctl.output {
+			t.Errorf("dump = %q, want %q", out.String(), ctl.output)
+		}
+		if !slices.Equal(ctl.execEnv, []string{"PGPASSWORD=s3cret"}) {
+			t.Errorf("exec env = %v", ctl.execEnv)
+		}
+	})
+
+	t.Run("reports a failed dump with its stderr", func(t *testing.T) {
+		ctl := &fakeExecer{stderr: `pg_dump: error: database "app" does not exist`, exitCode: 1}
+		err := dumpContainerDatabase(context.Background(), ctl, db, io.Discard)
+		if err == nil || !strings.Contains(err.Error(), "status 1") || !strings.Contains(err.Error(), "does not exist") {
+			t.Errorf("err = %v", err)
+		}
+	})
+
+	t.Run("explains a missing tool", func(t *testing.T) {
+		ctl := &fakeExecer{exitCode: execNotFound}
+		err := dumpContainerDatabase(context.Background(), ctl, db, io.Discard)
+		if err == nil || !strings.Contains(err.Error(), "pg_dump is not installed in container pg") {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
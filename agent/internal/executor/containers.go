@@ -0,0 +1,166 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arkeep-io/arkeep/agent/internal/docker"
+)
+
+// Container modes of a docker-volume source, set with the "containers"
+// query parameter of its URI (docker-volume://<name>?containers=stop).
+const (
+	containersStop  = "stop"
+	containersPause = "pause"
+)
+
+const (
+	// containerStopTimeout is the grace period a container gets to exit
+	// after SIGTERM before the daemon kills it.
+	containerStopTimeout = 30 * time.Second
+	// containerRestoreTimeout bounds the restart or unpause of each
+	// container after the backup. Restoring runs on its own context so that
+	// it happens even when the job was cancelled.
+	containerRestoreTimeout = 2 * time.Minute
+)
+
+// containerController is the subset of the Docker client used to stop or
+// pause the containers of a volume around a backup. Implemented by
+// *docker.Client.
+type containerController interface {
+	ListVolumeContainers(ctx context.Context, volume string) ([]docker.VolumeContainer, error)
+	StopContainer(ctx context.Context, id string, timeoutSeconds int) error
+	StartContainer(ctx context.Context, id string) error
+	PauseContainer(ctx context.Context, id string) error
+	UnpauseContainer(ctx context.Context, id string) error
+}
+
+// quiescedContainer is a container the executor stopped or paused and must
+// bring back once the backup is over.
+type quiescedContainer struct {
+	docker.VolumeContainer
+	mode string
+}
+
+// parseVolumeSource splits a docker-volume:// source into the volume name
+// and its container mode ("" when the containers are left running).
+func parseVolumeSource(src string) (name, mode string, err error) {
+	name, query, _ := strings.Cut(strings.TrimPrefix(src, "docker-volume://"), "?")
+	if query == "" {
+		return name, "", nil
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid source %q: %w", src, err)
+	}
+	mode = values.Get("containers")
+	if mode != "" && mode != containersStop && mode != containersPause {
+		return "", "", fmt.Errorf("invalid source %q: unknown container mode %q", src, mode)
+	}
+	return name, mode, nil
+}
+
+// quiesceContainers stops or pauses the running containers that mount the
+// docker-volume sources with a container mode, so that restic reads a
+// consistent copy of the volume. It returns the function that brings the
+// containers back to their previous state; it is safe to call more than
+// once and must be called even when quiesceContainers fails, which undoes
+// the containers already stopped or paused.
+func (e *Executor) quiesceContainers(ctx context.Context, sourcesJSON string, log func(level, msg string)) (func(), error) {
+	var raw []string
+	if err := json.Unmarshal([]byte(sourcesJSON), &raw); err != nil {
+		return func() {}, fmt.Errorf("invalid sources JSON: %w", err)
+	}
+	var ctl containerController
+	if e.docker != nil {
+		ctl = e.docker
+	}
+	return quiesceContainers(ctx, ctl, raw, log)
+}
+
+// quiesceContainers is the implementation of Executor.quiesceContainers
+// over a containerController, which is nil when Docker is unavailable.
+func quiesceContainers(ctx context.Context, ctl containerController, sources []string, log func(level, msg string)) (func(), error) {
+	var quiesced []quiescedContainer
+	resume := sync.OnceFunc(func() { resumeContainers(ctl, quiesced, log) })
+
+	seen := make(map[string]bool)
+	for _, src := range sources {
+		if !strings.HasPrefix(src, "docker-volume://") {
+			continue
+		}
+		volume, mode, err := parseVolumeSource(src)
+		if err != nil {
+			return resume, err
+		}
+		if mode == "" {
+			continue
+		}
+		if ctl == nil {
+			return resume, fmt.Errorf("source %q requires Docker but Docker is unavailable on this host", src)
+		}
+
+		containers, err := ctl.ListVolumeContainers(ctx, volume)
+		if err != nil {
+			return resume, fmt.Errorf("failed to list containers of Docker volume %q: %w", volume, err)
+		}
+		for _, c := range containers {
+			// A container mounting several volumes is handled once, and
+			// containers that are not running are left as they are.
+			if seen[c.ID] || c.State != "running" {
+				continue
+			}
+			seen[c.ID] = true
+
+			if mode == containersStop {
+				log("info", fmt.Sprintf("stopping container %s (volume %s)", c.Name, volume))
+				err = ctl.StopContainer(ctx, c.ID, int(containerStopTimeout/time.Second))
+			} else {
+				log("info", fmt.Sprintf("pausing container %s (volume %s)", c.Name, volume))
+				err = ctl.PauseContainer(ctx, c.ID)
+			}
+			// A stop that failed, for instance because the job was cancelled
+			// while waiting for the container to exit, may still have stopped
+			// it: starting a running container is a no-op, so it is restarted
+			// in any case.
+			if err == nil || mode == containersStop {
+				quiesced = append(quiesced, quiescedContainer{VolumeContainer: c, mode: mode})
+			}
+			if err != nil {
+				return resume, fmt.Errorf("failed to %s container %s: %w", mode, c.Name, err)
+			}
+		}
+	}
+	return resume, nil
+}
+
+// resumeContainers restarts or unpauses the quiesced containers, in reverse
+// order. Each step gets its own timeout, detached from the job's context,
+// and a failure is logged without stopping the others.
+func resumeContainers(ctl containerController, quiesced []quiescedContainer, log func(level, msg string)) {
+	for i := len(quiesced) - 1; i >= 0; i-- {
+		c := quiesced[i]
+		ctx, cancel := context.WithTimeout(context.Background(), containerRestoreTimeout)
+		var err error
+		if c.mode == containersStop {
+			log("info", fmt.Sprintf("starting container %s", c.Name))
+			err = ctl.StartContainer(ctx, c.ID)
+		} else {
+			log("info", fmt.Sprintf("unpausing container %s", c.Name))
+			err = ctl.UnpauseContainer(ctx, c.ID)
+		}
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", containerRestoreTimeout)
+		}
+		cancel()
+		if err != nil {
+			log("error", fmt.Sprintf("failed to restore container %s to its previous state: %v", c.Name, err))
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/arkeep-io/arkeep/agent/internal/docker"
+)
+
+// fakeContainers is a containerController that records the calls made to it.
+type fakeContainers struct {
+	volumes map[string][]docker.VolumeContainer
+	failOn  string // "<action> <id>" that returns an error
+	calls   []string
+}
+
+func (f *fakeContainers) ListVolumeContainers(_ context.Context, volume string) ([]docker.VolumeContainer, error) {
+	return f.volumes[volume], nil
+}
+
+func (f *fakeContainers) do(action, id string) error {
+	f.calls = append(f.calls, action+" "+id)
+	if f.failOn == action+" "+id {
+		return errors.New("daemon error")
+	}
+	return nil
+}
+
+func (f *fakeContainers) StopContainer(_ context.Context, id string, _ int) error {
+	return f.do("stop", id)
+}
+
+func (f *fakeContainers) StartContainer(_ context.Context, id string) error {
+	return f.do("start", id)
+}
+
+func (f *fakeContainers) PauseContainer(_ context.Context, id string) error {
+	return f.do("pause", id)
+}
+
+func (f *fakeContainers) UnpauseContainer(_ context.Context, id string) error {
+	return f.do("unpause", id)
+}
+
+func TestParseVolumeSource(t *testing.T) {
+	cases := map[string]struct {
+		name, mode string
+		wantErr    bool
+	}{
+		"docker-volume://pgdata":                  {name: "pgdata"},
+		"docker-volume://pgdata?containers=stop":  {name: "pgdata", mode: "stop"},
+		"docker-volume://pgdata?containers=pause": {name: "pgdata", mode: "pause"},
+		"docker-volume://pgdata?containers=kill":  {wantErr: true},
+	}
+	for src, want := range cases {
+		name, mode, err := parseVolumeSource(src)
+		if (err != nil) != want.wantErr {
+			t.Errorf("%s: err = %v, want error %v", src, err, want.wantErr)
+			continue
+		}
+		if name != want.name || mode != want.mode {
+			t.Errorf("%s: got (%q, %q), want (%q, %q)", src, name, mode, want.name, want.mode)
+		}
+	}
+}
+
+func TestQuiesceContainers(t *testing.T) {
+	log := func(level, msg string) {}
+	volumes := map[string][]docker.VolumeContainer{
+		"pgdata":  {{ID: "db", Name: "postgres", State: "running"}, {ID: "old", Name: "migrate", State: "exited"}},
+		"uploads": {{ID: "app", Name: "web", State: "running"}, {ID: "db", Name: "postgres", State: "running"}},
+		"cache":   {{ID: "redis", Name: "redis", State: "running"}},
+	}
+	sources := []string{
+		"/etc",
+		"docker-volume://pgdata?containers=stop",
+		"docker-volume://uploads?containers=pause",
+		"docker-volume://cache",
+	}
+
+	t.Run("restores the containers in reverse order, once", func(t *testing.T) {
+		ctl := &fakeContainers{volumes: volumes}
+		resume, err := quiesceContainers(context.Background(), ctl, sources, log)
+		if err != nil {
+			t.Fatalf("quiesceContainers: %v", err)
+		}
+		resume()
+		resume()
+		want := []string{"stop db", "pause app", "unpause app", "start db"}
+		if !slices.Equal(ctl.calls, want) {
+			t.Errorf("calls = %v, want %v", ctl.calls, want)
+		}
+	})
+
+	t.Run("restores the containers already quiesced on failure", func(t *testing.T) {
+		ctl := &fakeContainers{volumes: volumes, failOn: "pause app"}
+		resume, err := quiesceContainers(context.Background(), ctl, sources, log)
+		if err == nil {
+			t.Fatal("quiesceContainers succeeded, want the pause error")
+		}
+		resume()
+		want := []string{"stop db", "pause app", "start db"}
+		if !slices.Equal(ctl.calls, want) {
+			t.Errorf("calls = %v, want %v", ctl.calls, want)
+		}
+	})
+
+	t.Run("fails without Docker", func(t *testing.T) {
+		resume, err := quiesceContainers(context.Background(), nil, sources, log)
+		if err == nil {
+			t.Fatal("quiesceContainers succeeded without Docker")
+		}
+		resume()
+	})
+}
@@ -0,0 +1,320 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/arkeep-io/arkeep/agent/internal/restic"
+)
+
+// Database engines of database sources, as sent by the server.
+const (
+	enginePostgres = "postgres"
+	engineMySQL    = "mysql"
+	engineMongoDB  = "mongodb"
+	engineRedis    = "redis"
+	engineSQLite   = "sqlite"
+)
+
+// toolStderrLimit is how much of a dump or restore tool's stderr is kept
+// for the error message when it fails.
+const toolStderrLimit = 2048
+
+// databasePayload mirrors the struct serialized by the server scheduler for
+// database sources and by the snapshot handler for database restores. The
+// password arrives decrypted.
+type databasePayload struct {
+	Engine   string `json:"engine"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Database is the database to dump or restore into: a file path for
+	// SQLite, empty for Redis, which dumps the whole instance.
+	Database string `json:"database"`
+	// Filename is the name of the dump in its snapshot; Tags are added to
+	// the job's tags on the dump's snapshot.
+	Filename string   `json:"filename"`
+	Tags     []string `json:"tags"`
+	// SourceDatabase, for restores, is the database the dump was taken of.
+	SourceDatabase string `json:"source_database"`
+	// Container is set for docker-dump sources, dumped inside the named
+	// container through the Docker API: see containerDumpCommand.
+	Container            string `json:"container"`
+	Command              string `json:"command"`
+	ContainerCredentials bool   `json:"container_credentials"`
+}
+
+// toolCommand returns the program, arguments and extra environment that
+// dump db to stdout, or with restore set, load a dump read from stdin into
+// db. Passwords go through the environment, never the command line. The
+// MongoDB tools take theirs from a config file written by databaseCommand.
+func toolCommand(db databasePayload, restore bool) (string, []string, []string, error) {
+	var env []string
+	switch db.Engine {
+	case enginePostgres:
+		args := connectionArgs(db, "--host=", "--port=", "--username=")
+		args = append(args, "--no-password", "--dbname="+db.Database)
+		if db.Password != "" {
+			env = append(env, "PGPASSWORD="+db.Password)
+		}
+		if restore {
+			return "psql", append(args, "--set=ON_ERROR_STOP=1", "--quiet"), env, nil
+		}
+		return "pg_dump", append(args, "--clean", "--if-exists"), env, nil
+
+	case engineMySQL:
+		args := connectionArgs(db, "--host=", "--port=", "--user=")
+		if db.Password != "" {
+			env = append(env, "MYSQL_PWD="+db.Password)
+		}
+		if restore {
+			return "mysql", append(args, "--database="+db.Database), env, nil
+		}
+		return "mysqldump", append(args, "--single-transaction", "--routines", "--triggers", db.Database), env, nil
+
+	case engineMongoDB:
+		args := connectionArgs(db, "--host=", "--port=", "--username=")
+		if db.Username != "" {
+			// Users are usually defined in the admin database, not in the
+			// one being dumped.
+			args = append(args, "--authenticationDatabase=admin")
+		}
+		args = append(args, "--archive")
+		if restore {
+			source := db.SourceDatabase
+			if source == "" {
+				source = db.Database
+			}
+			return "mongorestore", append(args, "--drop", "--nsFrom="+source+".*", "--nsTo="+db.Database+".*"), env, nil
+		}
+		return "mongodump", append(args, "--db="+db.Database), env, nil
+
+	case engineRedis:
+		if restore {
+			return "", nil, nil, errors.New("redis dumps cannot be loaded into a running server: restore the RDB file instead")
+		}
+		var args []string
+		if db.Host != "" {
+			args = append(args, "-h", db.Host)
+		}
+		if db.Port != 0 {
+			args = append(args, "-p", strconv.Itoa(db.Port))
+		}
+		if db.Username != "" {
+			args = append(args, "--user", db.Username)
+		}
+		if db.Password != "" {
+			env = append(env, "REDISCLI_AUTH="+db.Password)
+		}
+		return "redis-cli", append(args, "--rdb", "-"), env, nil
+
+	case engineSQLite:
+		if restore {
+			return "sqlite3", []string{"-bail", db.Database}, nil, nil
+		}
+		return "sqlite3", []string{"-bail", "-readonly", db.Database, ".dump"}, nil, nil
+	}
+	return "", nil, nil, fmt.Errorf("unknown database engine %q", db.Engine)
+}
+
+// connectionArgs returns the host, port and user options of a connection,
+// for tools that take them as --option=value.
+func connectionArgs(db databasePayload, host, port, user string) []string {
+	var args []string
+	if db.Host != "" {
+		args = append(args, host+db.Host)
+	}
+	if db.Port != 0 {
+		args = append(args, port+strconv.Itoa(db.Port))
+	}
+	if db.Username != "" {
+		args = append(args, user+db.Username)
+	}
+	return args
+}
+
+// databaseCommand builds the command of the dump or restore tool of db. The
+// returned function removes the files the command needs and must be called
+// once it has exited.
+func (e *Executor) databaseCommand(ctx context.Context, db databasePayload, restore bool) (*exec.Cmd, func(), error) {
+	if db.Engine == engineSQLite {
+		db.Database = translateLocalPath(db.Database, e.dockerHostRoot)
+	}
+	name, args, env, err := toolCommand(db, restore)
+	if err != nil {
+		return nil, func() {}, err
+	}
+
+	cleanup := func() {}
+	if db.Engine == engineMongoDB && db.Password != "" {
+		f, err := os.CreateTemp("", "arkeep-mongo-*.yaml")
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("failed to write %s config: %w", name, err)
+		}
+		cleanup = func() { os.Remove(f.Name()) }
+		// A JSON string is a valid YAML scalar.
+		quoted, _ := json.Marshal(db.Password)
+		_, err = f.WriteString("password: " + string(quoted) + "\n")
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			cleanup()
+			return nil, func() {}, fmt.Errorf("failed to write %s config: %w", name, err)
+		}
+		args = append(args, "--config="+f.Name())
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	return cmd, cleanup, nil
+}
+
+// databaseName names db in the logs.
+func databaseName(db databasePayload) string {
+	if db.Container != "" && db.Engine == "" {
+		return "container " + db.Container
+	}
+	name := db.Engine
+	if db.Database != "" {
+		name += " database " + db.Database
+	}
+	if db.Container != "" {
+		name += " in container " + db.Container
+	}
+	return name
+}
+
+// backupDatabases dumps each database source of the job into a snapshot of
+// its own on d, streaming the dump tool's output into restic backup --stdin
+// without an intermediate file. It returns the snapshots taken, and stops at
+// the first failure.
+func (e *Executor) backupDatabases(ctx context.Context, run *backupRun, d restic.Destination, dest destinationPayload) ([]restic.StdinSnapshot, error) {
+	var snapshots []restic.StdinSnapshot
+	for _, db := range run.payload.Databases {
+		run.log("info", fmt.Sprintf("dumping %s to destination %s", databaseName(db), dest.DestinationID))
+		snap, err := e.backupDatabase(ctx, run, d, db)
+		if err != nil {
+			return snapshots, fmt.Errorf("dump of %s failed: %w", databaseName(db), err)
+		}
+		run.log("info", fmt.Sprintf("dump of %s completed (snapshot: %s, size: %d bytes)", databaseName(db), snap.SnapshotID, snap.SizeBytes))
+		snapshots = append(snapshots, snap)
+	}
+	return snapshots, nil
+}
+
+// backupDatabase pipes the dump of db into restic. A dump tool that fails
+// after restic has stored part of its output leaves a truncated snapshot,
+// which is forgotten.
+func (e *Executor) backupDatabase(ctx context.Context, run *backupRun, d restic.Destination, db databasePayload) (restic.StdinSnapshot, error) {
+	if db.Container != "" {
+		return e.backupContainerDatabase(ctx, run, d, db)
+	}
+	cmd, cleanup, err := e.databaseCommand(ctx, db, false)
+	defer cleanup()
+	if err != nil {
+		return restic.StdinSnapshot{}, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return restic.StdinSnapshot{}, err
+	}
+	stderr := &tailBuffer{limit: toolStderrLimit}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return restic.StdinSnapshot{}, toolError(cmd, err)
+	}
+
+	tags := append(slices.Clone(run.payload.Tags), db.Tags...)
+	result, backupErr := e.wrapper.Backup(ctx, d, restic.BackupOptions{
+		Tags:          tags,
+		Stdin:         stdout,
+		StdinFilename: db.Filename,
+	}, nil)
+	if backupErr != nil {
+		// restic stopped reading: the dump tool would block on a full pipe.
+		_ = cmd.Process.Kill()
+	}
+	waitErr := cmd.Wait()
+
+	if backupErr != nil {
+		return restic.StdinSnapshot{}, backupErr
+	}
+	if waitErr != nil {
+		if err := e.wrapper.ForgetSnapshot(context.WithoutCancel(ctx), d, result.SnapshotID); err != nil {
+			run.log("warn", fmt.Sprintf("failed to forget the incomplete snapshot %s: %v", result.SnapshotID, err))
+		}
+		return restic.StdinSnapshot{}, toolError(cmd, fmt.Errorf("%w: %s", waitErr, stderr.String()))
+	}
+	return restic.StdinSnapshot{
+		SnapshotID: result.SnapshotID,
+		Filename:   db.Filename,
+		Tags:       tags,
+		SizeBytes:  result.TotalBytesProcessed,
+	}, nil
+}
+
+// restoreDatabase pipes the dump stored as filename in snapshotID into the
+// restore tool of db.
+func (e *Executor) restoreDatabase(ctx context.Context, d restic.Destination, snapshotID string, db databasePayload) error {
+	cmd, cleanup, err := e.databaseCommand(ctx, db, true)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	output := &tailBuffer{limit: toolStderrLimit}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Start(); err != nil {
+		return toolError(cmd, err)
+	}
+
+	dumpErr := e.wrapper.Dump(ctx, d, snapshotID, "/"+db.Filename, stdin)
+	stdin.Close()
+	// A restore tool that gave up also fails the dump, with a broken pipe:
+	// its own error says why.
+	if err := cmd.Wait(); err != nil {
+		return toolError(cmd, fmt.Errorf("%w: %s", err, output.String()))
+	}
+	return dumpErr
+}
+
+// toolError wraps the error of a dump or restore tool, naming the tool and
+// explaining a missing one.
+func toolError(cmd *exec.Cmd, err error) error {
+	name := cmd.Args[0]
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("%s is not installed on this agent's host", name)
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+// tailBuffer is an io.Writer that keeps the last limit bytes written to it.
+type tailBuffer struct {
+	limit int
+	buf   []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.limit; over > 0 {
+		b.buf = b.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	return strings.TrimSpace(string(b.buf))
+}
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestToolCommand(t *testing.T) {
+	cases := []struct {
+		name    string
+		db      databasePayload
+		restore bool
+		want    []string // program and arguments
+		env     []string
+	}{
+		{
+			name: "postgres dump",
+			db:   databasePayload{Engine: enginePostgres, Host: "db", Port: 5433, Username: "backup", Password: "s3cret", Database: "app"},
+			want: []string{"pg_dump", "--host=db", "--port=5433", "--username=backup", "--no-password", "--dbname=app", "--clean", "--if-exists"},
+			env:  []string{"PGPASSWORD=s3cret"},
+		},
+		{
+			name:    "postgres restore",
+			db:      databasePayload{Engine: enginePostgres, Database: "app_restored"},
+			restore: true,
+			want:    []string{"psql", "--no-password", "--dbname=app_restored", "--set=ON_ERROR_STOP=1", "--quiet"},
+		},
+		{
+			name: "mysql dump",
+			db:   databasePayload{Engine: engineMySQL, Username: "root", Password: "s3cret", Database: "shop"},
+			want: []string{"mysqldump", "--user=root", "--single-transaction", "--routines", "--triggers", "shop"},
+			env:  []string{"MYSQL_PWD=s3cret"},
+		},
+		{
+			name:    "mongodb restore under another name",
+			db:      databasePayload{Engine: engineMongoDB, Host: "mongo", Database: "events_copy", SourceDatabase: "events"},
+			restore: true,
+			want:    []string{"mongorestore", "--host=mongo", "--archive", "--drop", "--nsFrom=events.*", "--nsTo=events_copy.*"},
+		},
+		{
+			name: "redis dump",
+			db:   databasePayload{Engine: engineRedis, Host: "cache", Password: "s3cret"},
+			want: []string{"redis-cli", "-h", "cache", "--rdb", "-"},
+			env:  []string{"REDISCLI_AUTH=s3cret"},
+		},
+		{
+			name: "sqlite dump",
+			db:   databasePayload{Engine: engineSQLite, Database: "/srv/app/db.sqlite3"},
+			want: []string{"sqlite3", "-bail", "-readonly", "/srv/app/db.sqlite3", ".dump"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			name, args, env, err := toolCommand(tc.db, tc.restore)
+			if err != nil {
+				t.Fatalf("toolCommand: %v", err)
+			}
+			got := append([]string{name}, args...)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("command = %v, want %v", got, tc.want)
+			}
+			if !slices.Equal(env, tc.env) {
+				t.Errorf("env = %v, want %v", env, tc.env)
+			}
+			if tc.db.Password != "" && strings.Contains(strings.Join(got, " "), tc.db.Password) {
+				t.Error("the password is on the command line")
+			}
+		})
+	}
+
+	if _, _, _, err := toolCommand(databasePayload{Engine: engineRedis}, true); err == nil {
+		t.Error("toolCommand returned a Redis restore command")
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{limit: 8}
+	b.Write([]byte("pg_dump: "))
+	b.Write([]byte("error: boom"))
+	if got := b.String(); got != "or: boom" {
+		t.Errorf("String() = %q, want the last 8 bytes", got)
+	}
+}
@@ -8,6 +8,11 @@
 // The server is aware of this constraint and does not dispatch a second job
 // to an agent that already has one running.
 //
+// Each job runs under its own context, which is cancelled when the agent
+// shuts down, when the server aborts the job (Abort) or when a backup exceeds
+// its policy's max runtime. Cancelling the context kills the restic and hook
+// processes. A job aborted while still queued is skipped when its turn comes.
+//
 // Interfaces:
 //   - LogSink: implemented by the connection manager, receives log lines
 //     produced during execution and forwards them to the server via StreamLogs.
@@ -17,18 +22,26 @@ package executor
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/arkeep-io/arkeep/agent/internal/docker"
 	"github.com/arkeep-io/arkeep/agent/internal/hooks"
+	"github.com/arkeep-io/arkeep/agent/internal/keystore"
 	"github.com/arkeep-io/arkeep/agent/internal/restic"
+	"github.com/arkeep-io/arkeep/shared/keyseal"
 	proto "github.com/arkeep-io/arkeep/shared/proto"
 )
 
@@ -42,12 +55,75 @@ type LogSink interface {
 // server. Implemented by the connection manager.
 type StatusReporter interface {
 	ReportStatus(jobID, status, message string)
+	// ReportFailure reports a failed backup together with its failure class
+	// (one of the Failure* constants), which the server uses to decide
+	// whether the policy's retry settings apply.
+	ReportFailure(jobID, failureClass, message string)
 	// ReportDestinationResult reports the outcome of a backup to a single
-	// destination. Called once per destination after it completes or fails.
-	// sizeBytes is TotalBytesProcessed from the restic summary event.
-	ReportDestinationResult(jobID, destinationID, status, snapshotID string, startedAt time.Time, sizeBytes int64, errMsg string)
+	// destination. Called once per destination after it completes or fails;
+	// status is "succeeded", "succeeded_with_warnings" or "failed"; result
+	// is the restic summary of a successful backup, nil on failure.
+	// keyFingerprint identifies the agent-held repository key of a
+	// zero-knowledge policy, "" otherwise.
+	// Returns the ID of a snapshot the server asks to hold, or "".
+	ReportDestinationResult(jobID, destinationID, status string, startedAt time.Time, result *restic.BackupResult, keyFingerprint, errMsg string) string
+	// ReportSnapshotHold reports the ID restic gave a snapshot when it was
+	// tagged restic.HoldTag.
+	ReportSnapshotHold(jobID, destinationID, oldSnapshotID, newSnapshotID string)
+	// ReportReplication reports the snapshots a replication job found copied
+	// to a destination. Failures are reported via ReportDestinationResult.
+	ReportReplication(jobID, destinationID string, startedAt time.Time, copies []restic.SnapshotCopy)
+	// ReportDryRun reports the size estimate of a dry-run job. Called once,
+	// before the final status.
+	ReportDryRun(jobID string, result *restic.DryRunResult)
+	// ReportProgress reports a restic status event of a running job.
+	// destinationID is empty for jobs without destinations. Callers
+	// throttle reports, see progressReporter.
+	ReportProgress(jobID, destinationID string, ev restic.ProgressEvent)
+	// ReportKeyEscrow sends an escrow kit of the keystore, sealed to the
+	// escrow public key with the given fingerprint, to the server.
+	ReportKeyEscrow(escrowKeyFingerprint string, kit []byte, keyCount int) error
+}
+
+// progressInterval is the minimum time between two progress reports of a
+// job. restic emits a status event every second, more often on a terminal.
+const progressInterval = 2 * time.Second
+
+// progressReporter returns a restic.ProgressFunc that reports status events
+// as job progress, at most once per progressInterval, and forwards the other
+// events (the backup summary) to the log sink.
+func progressReporter(jobID, destinationID string, sink LogSink, reporter StatusReporter) restic.ProgressFunc {
+	var last time.Time
+	return func(ev restic.ProgressEvent) error {
+		if ev.MessageType != "status" {
+			sink.SendLog(jobID, "info", ev.Raw)
+			return nil
+		}
+		if now := time.Now(); now.Sub(last) >= progressInterval {
+			last = now
+			reporter.ReportProgress(jobID, destinationID, ev)
+		}
+		return nil
+	}
 }
 
+// Failure classes attached to a failed backup report. They mirror the
+// proto.FailureClass enum.
+const (
+	FailureNetwork       = "network"
+	FailureHook          = "hook"
+	FailureWrongPassword = "wrong_password"
+	FailureOther         = "other"
+)
+
+// Fan-out modes of a backup to several destinations. They mirror the
+// server's policy fan_out values.
+const (
+	fanOutSequential = "sequential"
+	fanOutParallel   = "parallel"
+	fanOutReplicate  = "replicate"
+)
+
 // JobAssignment is the internal representation of a job received from the server.
 // Payload is the raw JSON bytes from the proto message — the executor
 // deserializes it according to the job type during execution.
@@ -62,13 +138,33 @@ type JobAssignment struct {
 // All credentials arrive already decrypted — the server handles encryption
 // at rest, the gRPC channel handles transport security.
 type backupPayload struct {
-	Sources        string               `json:"sources"`
-	RepoPassword   string               `json:"repo_password"`
-	Destinations   []destinationPayload `json:"destinations"`
-	Retention      retentionPayload     `json:"retention"`
-	HookPreBackup  string               `json:"hook_pre_backup"`
-	HookPostBackup string               `json:"hook_post_backup"`
-	Tags           []string             `json:"tags"`
+	Sources      string               `json:"sources"`
+	RepoPassword string               `json:"repo_password"`
+	Destinations []destinationPayload `json:"destinations"`
+	Retention    retentionPayload     `json:"retention"`
+	Tags         []string             `json:"tags"`
+	// Hooks maps the pre_backup, post_backup, on_success and on_failure
+	// phases to their steps, see runHookPhase.
+	Hooks map[string][]hookStep `json:"hooks"`
+	// FanOut is how the backup reaches several destinations, one of the
+	// fanOut* constants. Empty means fanOutSequential.
+	FanOut string `json:"fan_out"`
+	// Parallelism caps the destinations backed up at once with
+	// fanOutParallel. 0 means no limit.
+	Parallelism int `json:"parallelism"`
+	// MaxRuntimeSeconds bounds the whole backup, hooks included. 0 means no
+	// limit.
+	MaxRuntimeSeconds int `json:"max_runtime_seconds"`
+	// EscrowPublicKey, set for zero-knowledge policies, is the admin key
+	// the keystore's escrow kit is sealed to after the backup.
+	EscrowPublicKey string `json:"escrow_public_key"`
+	// Databases are dumped into a snapshot each, next to the snapshot of
+	// Sources: through a connection, or inside a container for docker-dump
+	// sources.
+	Databases []databasePayload `json:"databases"`
+	// Commands are the command sources, whose output is stored in a
+	// snapshot each too.
+	Commands []commandPayload `json:"commands"`
 }
 
 // restorePayload mirrors the struct serialized by the server snapshot handler.
@@ -78,6 +174,44 @@ type restorePayload struct {
 	RepoPassword     string             `json:"repo_password"`
 	TargetPath       string             `json:"target_path"`
 	Destination      destinationPayload `json:"destination"`
+	// KeyID names the keystore key of a zero-knowledge policy's repository,
+	// in place of RepoPassword. SealedKey (base64) is the key shared with
+	// this agent when another agent holds it.
+	KeyID          string `json:"key_id"`
+	KeyFingerprint string `json:"key_fingerprint"`
+	SealedKey      string `json:"sealed_key"`
+	// Database, set for the restore of a database dump, is the database
+	// the dump is loaded into, in place of TargetPath.
+	Database *databasePayload `json:"database"`
+	// Hooks maps the pre_restore and post_restore phases to their steps.
+	Hooks map[string][]hookStep `json:"hooks"`
+}
+
+// dryRunPayload mirrors the struct serialized by the server scheduler for
+// JOB_TYPE_DRY_RUN jobs. It carries no destinations: the dry run uses a
+// scratch repository.
+type dryRunPayload struct {
+	Sources           string `json:"sources"`
+	MaxRuntimeSeconds int    `json:"max_runtime_seconds"`
+}
+
+// replicatePayload mirrors the struct serialized by the server scheduler for
+// JOB_TYPE_REPLICATE jobs. Source and Destinations share RepoPassword.
+type replicatePayload struct {
+	RepoPassword string               `json:"repo_password"`
+	Source       destinationPayload   `json:"source"`
+	Destinations []destinationPayload `json:"destinations"`
+	// Tags and Host select the snapshots of Source to copy; every tag must
+	// be present. Empty selects every snapshot.
+	Tags              []string `json:"tags"`
+	Host              string   `json:"host"`
+	MaxRuntimeSeconds int      `json:"max_runtime_seconds"`
+}
+
+// scanPayload mirrors the struct serialized by the server scheduler for
+// JOB_TYPE_SCAN_REPOSITORY assignments.
+type scanPayload struct {
+	Repository destinationPayload `json:"repository"`
 }
 
 type destinationPayload struct {
@@ -88,20 +222,28 @@ type destinationPayload struct {
 	Config        string            `json:"config"`
 	Env           map[string]string `json:"env"`
 	Priority      int               `json:"priority"`
+	// RepoPassword, when set, opens this repository in place of the job's
+	// password. ChunkerFrom is the repository whose chunker parameters the
+	// repository is initialised with when it does not exist yet.
+	RepositoryID string              `json:"repository_id"`
+	RepoPassword string              `json:"repo_password"`
+	ChunkerFrom  *destinationPayload `json:"chunker_from"`
+	// KeyID, set for zero-knowledge policies, names the keystore key that
+	// opens the repository; the agent creates it on the first backup.
+	// KeyFingerprint is the fingerprint the server recorded, empty until
+	// then.
+	KeyID          string `json:"key_id"`
+	KeyFingerprint string `json:"key_fingerprint"`
 }
 
 type retentionPayload struct {
-	Daily   int `json:"daily"`
-	Weekly  int `json:"weekly"`
-	Monthly int `json:"monthly"`
-	Yearly  int `json:"yearly"`
-}
-
-type hookPayload struct {
-	Name        string   `json:"name"`
-	Command     string   `json:"command"`
-	Args    		[]string `json:"args"`
-	TimeoutSecs int      `json:"timeout_secs"`
+	Hourly   int      `json:"hourly"`
+	Daily    int      `json:"daily"`
+	Weekly   int      `json:"weekly"`
+	Monthly  int      `json:"monthly"`
+	Yearly   int      `json:"yearly"`
+	Tags     []string `json:"tags"`
+	KeepTags []string `json:"keep_tags"`
 }
 
 // queueSize is the maximum number of jobs that can be buffered in the channel
@@ -109,37 +251,66 @@ type hookPayload struct {
 // server will retry them on the next reconnect via DispatchPending.
 const queueSize = 16
 
+// Cancellation causes of a job context, see interrupted.
+var (
+	errAborted            = errors.New("aborted by server")
+	errMaxRuntimeExceeded = errors.New("max runtime exceeded")
+)
+
 // Executor receives job assignments, queues them, and executes them one at a
 // time using the restic wrapper, docker client, and hooks runner.
 type Executor struct {
 	wrapper        *restic.Wrapper
 	docker         *docker.Client // may be nil if Docker is unavailable on this host
 	hooks          *hooks.Runner
+	keys           *keystore.Store
 	queue          chan JobAssignment
 	logger         *zap.Logger
+
+	// cancels holds the cancel function of the running job's context, keyed
+	// by job ID, so that Abort can stop it. queued holds the IDs of the jobs
+	// waiting in the queue; Abort sets a job's entry to true so that the job
+	// is skipped when it is taken off the queue.
+	mu      sync.Mutex
+	cancels map[string]context.CancelCauseFunc
+	queued  map[string]bool
+
 	dockerHostRoot string // resolved by main.go: /hostfs when inside Docker, empty for native deployments, or user-supplied override
+
+	// allowedCommands are the programs command sources may run on this
+	// agent. Empty, command sources are refused.
+	allowedCommands []string
 }
 
 // New creates a new Executor. dockerClient may be nil — if it is, any job
-// that requires Docker volume discovery will fail gracefully.
+// that requires Docker volume discovery will fail gracefully. keys holds the
+// repository keys of zero-knowledge policies.
 // dockerHostRoot is resolved by main.go (defaults to /hostfs inside Docker,
 // empty for native deployments, or the user-supplied --docker-host-root value):
 // when non-empty, local destination paths and restore targets entered by the
 // user are automatically translated so they resolve inside the container.
+// allowedCommands is the agent-local allowlist of the programs command
+// sources may run, on top of the server's validation.
 func New(
 	wrapper *restic.Wrapper,
 	dockerClient *docker.Client,
 	hooksRunner *hooks.Runner,
+	keys *keystore.Store,
 	logger *zap.Logger,
 	dockerHostRoot string,
+	allowedCommands []string,
 ) *Executor {
 	return &Executor{
-		wrapper:        wrapper,
-		docker:         dockerClient,
-		hooks:          hooksRunner,
-		queue:          make(chan JobAssignment, queueSize),
-		logger:         logger.Named("executor"),
-		dockerHostRoot: dockerHostRoot,
+		wrapper:         wrapper,
+		docker:          dockerClient,
+		hooks:           hooksRunner,
+		keys:            keys,
+		queue:           make(chan JobAssignment, queueSize),
+		logger:          logger.Named("executor"),
+		cancels:         make(map[string]context.CancelCauseFunc),
+		queued:          make(map[string]bool),
+		dockerHostRoot:  dockerHostRoot,
+		allowedCommands: allowedCommands,
 	}
 }
 
@@ -203,6 +374,9 @@ func (e *Executor) Run(ctx context.Context, sink LogSink, reporter StatusReporte
 // Non-blocking — the caller should log and discard rejected jobs; the server
 // will retry via DispatchPending on the next reconnect.
 func (e *Executor) Enqueue(job JobAssignment) error {
+	e.mu.Lock()
+	e.queued[job.JobID] = false
+	e.mu.Unlock()
 	select {
 	case e.queue <- job:
 		e.logger.Info("job enqueued",
@@ -212,15 +386,81 @@ func (e *Executor) Enqueue(job JobAssignment) error {
 		)
 		return nil
 	default:
+		e.mu.Lock()
+		delete(e.queued, job.JobID)
+		e.mu.Unlock()
 		return fmt.Errorf("executor: job queue full, rejecting job %s", job.JobID)
 	}
 }
 
+// Abort stops a running job by cancelling its context, which kills restic and
+// the hooks, or marks a queued job so that it never starts. The job reports
+// no final status: the server has already recorded the outcome. Returns
+// false if the job is neither running nor queued.
+func (e *Executor) Abort(jobID string) bool {
+	e.mu.Lock()
+	cancel, running := e.cancels[jobID]
+	_, queued := e.queued[jobID]
+	if queued {
+		e.queued[jobID] = true
+	}
+	e.mu.Unlock()
+	if running {
+		cancel(errAborted)
+	}
+	return running || queued
+}
+
+// ScanRepository lists the snapshots of the repository described by a
+// JOB_TYPE_SCAN_REPOSITORY payload, typically one created outside Arkeep
+// that is being imported. It does not go through the queue: listing
+// snapshots neither locks nor writes the repository.
+func (e *Executor) ScanRepository(ctx context.Context, payload []byte) ([]restic.SnapshotInfo, error) {
+	var req scanPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, fmt.Errorf("invalid scan payload: %w", err)
+	}
+	d := restic.Destination{
+		Type:     restic.DestinationType(req.Repository.Type),
+		RepoURL:  req.Repository.RepoURL,
+		Password: req.Repository.RepoPassword,
+		Env:      req.Repository.Env,
+	}
+	if req.Repository.Type == "local" {
+		d.RepoURL = translateLocalPath(d.RepoURL, e.dockerHostRoot)
+	}
+	return e.wrapper.Snapshots(ctx, d)
+}
+
 // execute routes a job to the appropriate handler based on its type.
 func (e *Executor) execute(ctx context.Context, job JobAssignment, sink LogSink, reporter StatusReporter) {
+	// The job leaves the queue and becomes abortable as running in one step,
+	// so that an abort in between cannot be lost.
+	e.mu.Lock()
+	aborted := e.queued[job.JobID]
+	delete(e.queued, job.JobID)
+	if aborted {
+		e.mu.Unlock()
+		e.logger.Info("skipping job aborted while queued", zap.String("job_id", job.JobID))
+		return
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	e.cancels[job.JobID] = cancel
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		delete(e.cancels, job.JobID)
+		e.mu.Unlock()
+		cancel(nil)
+	}()
+
 	switch job.Type {
 	case proto.JobType_JOB_TYPE_RESTORE:
 		e.executeRestore(ctx, job, sink, reporter)
+	case proto.JobType_JOB_TYPE_DRY_RUN:
+		e.executeDryRun(ctx, job, sink, reporter)
+	case proto.JobType_JOB_TYPE_REPLICATE:
+		e.executeReplicate(ctx, job, sink, reporter)
 	default:
 		// JOB_TYPE_BACKUP and unspecified types all run the backup handler.
 		e.executeBackup(ctx, job, sink, reporter)
@@ -232,11 +472,27 @@ func (e *Executor) execute(ctx context.Context, job JobAssignment, sink LogSink,
 // Execution sequence:
 //  1. Deserialize payload
 //  2. Report status "running"
-//  3. Resolve docker-volume:// sources to host mountpoints
-//  4. Run pre-backup hook (abort on failure)
-//  5. For each destination: run restic backup, stream progress, run forget
-//  6. Run post-backup hook (non-fatal, always runs)
-//  7. Report status "succeeded" or "failed"
+//  3. Expand docker-label:// sources into the matching volumes, then
+//     resolve docker-volume:// sources to host mountpoints
+//  4. Run the pre-backup hook steps and the hooks of the matched containers
+//     (abort on failure), then stop or pause the containers of
+//     docker-volume sources that ask for it
+//  5. For each destination: run restic backup, stream progress, run forget.
+//     Destinations are visited in priority order, one at a time, all at
+//     once (fan-out "parallel") or backed up once to the first and copied
+//     to the others (fan-out "replicate")
+//  6. Restore the stopped or paused containers, then run the post-backup
+//     hook steps, whatever the outcome of the destinations
+//  7. Run the on-success hook steps, or the on-failure ones, and report
+//     status "succeeded" or "failed"
+//
+// The containers are restored even when the job fails or is cancelled.
+// A failed pre-backup or post-backup step fails the job; on-success and
+// on-failure steps only log their failures.
+//
+// When the policy sets a max runtime, the whole sequence runs under that
+// deadline; exceeding it fails the job. The on-failure steps still run
+// then, but not when the job is aborted or the agent shuts down.
 func (e *Executor) executeBackup(ctx context.Context, job JobAssignment, sink LogSink, reporter StatusReporter) {
 	log := func(level, msg string) {
 		sink.SendLog(job.JobID, level, msg)
@@ -250,206 +506,607 @@ func (e *Executor) executeBackup(ctx context.Context, job JobAssignment, sink Lo
 		}
 	}
 
-	fail := func(msg string) {
+	var payload backupPayload
+	var run *backupRun
+	hc := hookContext{jobID: job.JobID, policyID: job.PolicyID, status: hookStatusRunning}
+
+	// runOnFailure runs the on-failure steps under the job's context
+	// without its max runtime.
+	jobCtx := ctx
+	runOnFailure := func() {
+		steps := payload.Hooks[hookOnFailure]
+		if len(steps) == 0 || jobCtx.Err() != nil {
+			return
+		}
+		hc.status = hookStatusFailed
+		if run != nil {
+			hc.snapshotID = run.firstSnapshot()
+		}
+		if err := e.runHookPhase(jobCtx, hookOnFailure, steps, hc, log); err != nil {
+			log("warn", err.Error())
+		}
+	}
+
+	fail := func(failureClass, msg string) {
 		log("error", msg)
-		reporter.ReportStatus(job.JobID, "failed", msg)
+		runOnFailure()
+		reporter.ReportFailure(job.JobID, failureClass, msg)
+	}
+
+	// interrupted is e.interrupted, running the on-failure steps first when
+	// the max runtime stopped the job.
+	interrupted := func() bool {
+		if ctx.Err() != nil && context.Cause(ctx) == errMaxRuntimeExceeded {
+			runOnFailure()
+		}
+		return e.interrupted(ctx, job, "backup", payload.MaxRuntimeSeconds, log, reporter)
 	}
 
 	// --- 1. Deserialize payload ---
-	var payload backupPayload
 	if err := json.Unmarshal(job.Payload, &payload); err != nil {
-		fail(fmt.Sprintf("failed to deserialize job payload: %v", err))
+		fail(FailureOther, fmt.Sprintf("failed to deserialize job payload: %v", err))
 		return
 	}
 
+	if payload.MaxRuntimeSeconds > 0 {
+		maxRuntime := time.Duration(payload.MaxRuntimeSeconds) * time.Second
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, maxRuntime, errMaxRuntimeExceeded)
+		defer cancel()
+	}
+
+	// Parallel destinations share the job's log stream. log uses the
+	// wrapped sink too: it captures the variable.
+	if payload.FanOut == fanOutParallel {
+		sink = &lockedSink{sink: sink}
+	}
+
 	// --- 2. Report running ---
 	reporter.ReportStatus(job.JobID, "running", "starting backup")
 	log("info", "backup started")
 
 	// --- 3. Resolve sources ---
+	expanded, containerHooks, err := e.expandLabelSources(ctx, payload.Sources, log)
+	if err != nil {
+		if interrupted() {
+			return
+		}
+		fail(FailureOther, fmt.Sprintf("failed to expand docker-label sources: %v", err))
+		return
+	}
+	payload.Sources = expanded
+
 	sources, err := e.resolveSources(ctx, payload.Sources, log)
 	if err != nil {
-		fail(fmt.Sprintf("failed to resolve backup sources: %v", err))
+		if interrupted() {
+			return
+		}
+		fail(FailureOther, fmt.Sprintf("failed to resolve backup sources: %v", err))
 		return
 	}
-	if len(sources) == 0 {
-		fail("no accessible backup sources: all docker-volume mountpoints are unreachable on this host. " +
+	if len(sources) == 0 && len(payload.Databases) == 0 && len(payload.Commands) == 0 {
+		fail(FailureOther, "no accessible backup sources: all docker-volume mountpoints are unreachable on this host. " +
 			"If running a native agent on Windows, Docker volume paths are not directly accessible. " +
 			"Use the Docker-based agent deployment to back up Docker volumes.")
 		return
 	}
 	log("info", fmt.Sprintf("resolved %d source(s)", len(sources)))
+	if len(payload.Databases) > 0 {
+		log("info", fmt.Sprintf("%d database(s) to dump", len(payload.Databases)))
+	}
+	// Commands the agent refuses fail the job before anything is backed up.
+	for _, c := range payload.Commands {
+		if _, err := e.commandArgs(c.Command); err != nil {
+			fail(FailureOther, fmt.Sprintf("command source %q: %v", c.Command, err))
+			return
+		}
+	}
 
-	// --- 4. Pre-backup hook ---
-	if payload.HookPreBackup != "" {
-		log("info", fmt.Sprintf("running pre-backup hook: %s", payload.HookPreBackup))
-
-		hook, err := e.resolveHook(ctx, payload.HookPreBackup)
-		if err != nil {
-			fail(fmt.Sprintf("failed to resolve pre-backup hook: %v", err))
+	// --- 4. Pre-backup hooks ---
+	if err := e.runHookPhase(ctx, hookPreBackup, payload.Hooks[hookPreBackup], hc, log); err != nil {
+		if interrupted() {
 			return
 		}
+		fail(FailureHook, err.Error())
+		return
+	}
 
-		result, err := e.hooks.Run(ctx, hook.Command, hook.Args, time.Duration(hook.TimeoutSecs)*time.Second)
-		
-		if result.Output != "" {
-			log("info", "pre-backup hook output: "+result.Output)
+	if err := e.runContainerHooks(ctx, containerHooks, log); err != nil {
+		if interrupted() {
+			return
 		}
-		if err != nil {
-			fail(fmt.Sprintf("pre-backup hook failed (exit %d): %v", result.ExitCode, err))
+		fail(FailureHook, err.Error())
+		return
+	}
+
+	// Stop or pause the containers of the volumes that ask for it, after
+	// the hooks so that they can still reach them.
+	resume, err := e.quiesceContainers(ctx, payload.Sources, log)
+	defer resume()
+	if err != nil {
+		if interrupted() {
 			return
 		}
+		fail(FailureOther, fmt.Sprintf("failed to stop or pause containers before the backup: %v", err))
+		return
 	}
 
 	// --- 5. Backup to each destination ---
-	backupFailed := false
-	for _, dest := range payload.Destinations {
-		// Stop immediately if the agent is shutting down.
+	run = &backupRun{job: job, payload: payload, sources: sources, sink: sink, reporter: reporter, log: log}
+	dests := slices.Clone(payload.Destinations)
+	slices.SortStableFunc(dests, func(a, b destinationPayload) int { return a.Priority - b.Priority })
+	switch payload.FanOut {
+	case fanOutParallel:
+		e.backupParallel(ctx, run, dests)
+	case fanOutReplicate:
+		e.backupReplicate(ctx, run, dests)
+	default:
+		for _, dest := range dests {
+			// Stop immediately if the agent is shutting down.
+			if ctx.Err() != nil {
+				break
+			}
+			e.backupDestination(ctx, run, dest)
+		}
+	}
+
+	// The containers are brought back as soon as restic is done with their
+	// volumes, not at the end of the job.
+	resume()
+
+	// If the context was cancelled, the job was interrupted: by shutdown,
+	// by the server or by the max runtime.
+	if interrupted() {
+		return
+	}
+
+	// --- 6. Post-backup hooks (always run) ---
+	// ARKEEP_JOB_STATUS tells them whether the destinations succeeded.
+	hc.snapshotID = run.firstSnapshot()
+	hc.status = hookStatusSuccess
+	if run.failed {
+		hc.status = hookStatusFailed
+	}
+	postErr := e.runHookPhase(ctx, hookPostBackup, payload.Hooks[hookPostBackup], hc, log)
+	if postErr != nil && interrupted() {
+		return
+	}
+
+	// --- 7. Escrow kit (zero-knowledge policies) ---
+	if payload.EscrowPublicKey != "" {
+		e.exportEscrowKit(payload.EscrowPublicKey, log, reporter)
+	}
+
+	// --- 8. Final status ---
+	if run.failed {
+		if postErr != nil {
+			log("error", postErr.Error())
+		}
+		fail(run.failureClass, "one or more destinations failed")
+		return
+	}
+	if postErr != nil {
+		fail(FailureHook, postErr.Error())
+		return
+	}
+
+	hc.status = hookStatusSuccess
+	if err := e.runHookPhase(ctx, hookOnSuccess, payload.Hooks[hookOnSuccess], hc, log); err != nil {
+		log("warn", err.Error())
+	}
+
+	if run.fileErrors > 0 {
+		msg := fmt.Sprintf("backup completed with %d unreadable file(s)", run.fileErrors)
+		log("warn", msg)
+		reporter.ReportStatus(job.JobID, "success", msg)
+		return
+	}
+	log("info", "backup completed successfully")
+	reporter.ReportStatus(job.JobID, "success", "backup completed")
+}
+
+// exportEscrowKit seals the keystore to escrowPublicKey and sends the kit to
+// the server when the keys changed since the last export. Failures are
+// logged: the kit is exported after the next backup.
+func (e *Executor) exportEscrowKit(escrowPublicKey string, log func(level, msg string), reporter StatusReporter) {
+	if !e.keys.EscrowDue(escrowPublicKey) {
+		return
+	}
+	kit, n, err := e.keys.EscrowKit(escrowPublicKey)
+	if err != nil {
+		log("warn", fmt.Sprintf("failed to build escrow kit: %v", err))
+		return
+	}
+	if err := reporter.ReportKeyEscrow(keyseal.Fingerprint(escrowPublicKey), kit, n); err != nil {
+		log("warn", fmt.Sprintf("failed to send escrow kit: %v", err))
+		return
+	}
+	e.keys.MarkEscrowed(escrowPublicKey)
+	log("info", fmt.Sprintf("escrow kit with %d key(s) sent to the server", n))
+}
+
+// backupRun holds the state a backup job shares between its destinations.
+// With fan-out "parallel" destinations run concurrently: the outcome fields
+// are guarded by mu.
+type backupRun struct {
+	job      JobAssignment
+	payload  backupPayload
+	sources  []string
+	sink     LogSink
+	reporter StatusReporter
+	log      func(level, msg string)
+
+	mu sync.Mutex
+	// failureClass is the class shared by every failed destination, or
+	// FailureOther when they failed for different reasons.
+	failed       bool
+	failureClass string
+	// fileErrors counts the files left out of otherwise successful snapshots.
+	fileErrors uint64
+	// snapshotID is the first snapshot the job took, for its hooks.
+	snapshotID string
+}
+
+func (r *backupRun) markFailed(class string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failed && r.failureClass != class {
+		class = FailureOther
+	}
+	r.failed = true
+	r.failureClass = class
+}
+
+func (r *backupRun) addFileErrors(n uint64) {
+	r.mu.Lock()
+	r.fileErrors += n
+	r.mu.Unlock()
+}
+
+// recordSnapshot keeps id as the job's snapshot unless one was taken before.
+func (r *backupRun) recordSnapshot(id string) {
+	r.mu.Lock()
+	if r.snapshotID == "" {
+		r.snapshotID = id
+	}
+	r.mu.Unlock()
+}
+
+func (r *backupRun) firstSnapshot() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.snapshotID
+}
+
+// lockedSink serialises SendLog calls: the connection manager forwards log
+// lines over a single gRPC stream, which does not support concurrent sends.
+type lockedSink struct {
+	mu   sync.Mutex
+	sink LogSink
+}
+
+func (s *lockedSink) SendLog(jobID, level, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sink.SendLog(jobID, level, message)
+}
+
+// backupParallel backs up to dests concurrently, at most
+// run.payload.Parallelism at a time (all of them when 0). The caller wraps
+// the job's log sink in a lockedSink.
+func (e *Executor) backupParallel(ctx context.Context, run *backupRun, dests []destinationPayload) {
+	limit := run.payload.Parallelism
+	if limit <= 0 || limit > len(dests) {
+		limit = len(dests)
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for _, dest := range dests {
 		if ctx.Err() != nil {
 			break
 		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			e.backupDestination(ctx, run, dest)
+		}()
+	}
+	wg.Wait()
+}
 
-		if dest.RepoURL == "" {
-			log("warn", fmt.Sprintf("destination %s has empty repo_url, skipping", dest.DestinationID))
+// backupReplicate backs up to the first of dests, then copies the snapshot
+// to the others with restic copy so that every destination holds the same
+// snapshot content. When the first backup fails, the next destination takes
+// its place.
+func (e *Executor) backupReplicate(ctx context.Context, run *backupRun, dests []destinationPayload) {
+	for i, dest := range dests {
+		if ctx.Err() != nil {
+			return
+		}
+		from, result := e.backupDestination(ctx, run, dest)
+		if result == nil {
 			continue
 		}
+		for _, target := range dests[i+1:] {
+			if ctx.Err() != nil {
+				return
+			}
+			e.copyDestination(ctx, run, from, result, target)
+		}
+		return
+	}
+}
 
-		log("info", fmt.Sprintf("backing up to destination %s (type: %s)", dest.DestinationID, dest.Type))
+// backupDestination runs restic backup to dest, reports the outcome and
+// applies retention. Returns the restic destination and the backup result,
+// or a nil result when the backup failed or was skipped.
+func (e *Executor) backupDestination(ctx context.Context, run *backupRun, dest destinationPayload) (restic.Destination, *restic.BackupResult) {
+	log := run.log
+	if dest.RepoURL == "" {
+		log("warn", fmt.Sprintf("destination %s has empty repo_url, skipping", dest.DestinationID))
+		return restic.Destination{}, nil
+	}
 
-		// Record the start time before invoking restic so the server can persist
-		// an accurate started_at on the JobDestination row.
-		destStartedAt := time.Now().UTC()
+	log("info", fmt.Sprintf("backing up to destination %s (type: %s)", dest.DestinationID, dest.Type))
 
-		// For local destinations, translate the user-provided path to the
-		// container-accessible path (when ARKEEP_DOCKER_HOST_ROOT is set), then
-		// ensure the directory exists and is writable before handing off to
-		// restic. This produces a clear, actionable error instead of the
-		// cryptic "permission denied" from restic internals.
-		if dest.Type == "local" {
-			originalURL := dest.RepoURL
-			dest.RepoURL = translateLocalPath(dest.RepoURL, e.dockerHostRoot)
-			if dest.RepoURL != originalURL {
-				log("debug", fmt.Sprintf("translated local path %q → %q (ARKEEP_DOCKER_HOST_ROOT=%q)", originalURL, dest.RepoURL, e.dockerHostRoot))
-			}
-			if err := os.MkdirAll(dest.RepoURL, 0755); err != nil {
-				var errMsg string
-				if e.dockerHostRoot == "" {
-					errMsg = fmt.Sprintf(
-						"local path %q is not writable: %v — "+
-							"running without ARKEEP_DOCKER_HOST_ROOT: if this agent is inside Docker, "+
-							"set ARKEEP_DOCKER_HOST_ROOT=/hostfs and mount the backup share "+
-							"(e.g. -v /mnt/user:/hostfs/mnt/user:rw), or mount the full host root "+
-							"(-v /:/hostfs:ro on Linux); or set PUID/PGID to match the directory owner",
-						dest.RepoURL, err,
-					)
-				} else {
-					errMsg = fmt.Sprintf(
-						"local path %q is not writable (translated from %q via ARKEEP_DOCKER_HOST_ROOT=%q): %v — "+
-							"verify the share is mounted at the expected container path and is writable, "+
-							"or set PUID/PGID to match the directory owner",
-						dest.RepoURL, originalURL, e.dockerHostRoot, err,
-					)
-				}
-				log("error", fmt.Sprintf("backup to destination %s failed: %s", dest.DestinationID, errMsg))
-				reporter.ReportDestinationResult(job.JobID, dest.DestinationID, "failed", "", destStartedAt, 0, errMsg)
-				backupFailed = true
-				continue
-			}
-		}
+	// Record the start time before invoking restic so the server can persist
+	// an accurate started_at on the JobDestination row.
+	destStartedAt := time.Now().UTC()
+
+	d, ok := e.resticDestination(run, dest, destStartedAt)
+	if !ok {
+		return d, nil
+	}
 
-		d := restic.Destination{
-			Type:     restic.DestinationType(dest.Type),
-			RepoURL:  dest.RepoURL,
-			Password: payload.RepoPassword,
-			Env:      dest.Env,
+	if dest.ChunkerFrom != nil {
+		// A new repository takes the chunker parameters of another one, so
+		// that snapshots copied between them deduplicate.
+		fromURL := dest.ChunkerFrom.RepoURL
+		if dest.ChunkerFrom.Type == "local" {
+			fromURL = translateLocalPath(fromURL, e.dockerHostRoot)
+		}
+		from := restic.Destination{
+			Type:     restic.DestinationType(dest.ChunkerFrom.Type),
+			RepoURL:  fromURL,
+			Password: dest.ChunkerFrom.RepoPassword,
+			Env:      dest.ChunkerFrom.Env,
+		}
+		if err := e.wrapper.InitFrom(ctx, from, d); err != nil {
+			errMsg := fmt.Sprintf("backup to destination %s failed: %v", dest.DestinationID, err)
+			log("error", errMsg)
+			run.reporter.ReportDestinationResult(run.job.JobID, dest.DestinationID, "failed", destStartedAt, nil, "", err.Error())
+			run.markFailed(classifyResticError(err))
+			return d, nil
 		}
+	}
 
+	// A policy with database or command sources only has no file snapshot.
+	result := &restic.BackupResult{}
+	destStatus := "succeeded"
+	if len(run.sources) > 0 {
 		opts := restic.BackupOptions{
-			Sources: sources,
-			Tags:    payload.Tags,
+			Sources: run.sources,
+			Tags:    run.payload.Tags,
 		}
 
-		result, err := e.wrapper.Backup(ctx, d, opts, func(ev restic.ProgressEvent) error {
-			if data, err := json.Marshal(ev); err == nil {
-				sink.SendLog(job.JobID, "info", string(data))
-			}
-			return nil
-		})
+		var err error
+		result, err = e.wrapper.Backup(ctx, d, opts, progressReporter(run.job.JobID, dest.DestinationID, run.sink, run.reporter))
 		if err != nil {
 			errMsg := fmt.Sprintf("backup to destination %s failed: %v", dest.DestinationID, err)
 			log("error", errMsg)
-			reporter.ReportDestinationResult(job.JobID, dest.DestinationID, "failed", "", destStartedAt, 0, err.Error())
-			backupFailed = true
-			continue
+			run.reporter.ReportDestinationResult(run.job.JobID, dest.DestinationID, "failed", destStartedAt, nil, "", err.Error())
+			run.markFailed(classifyResticError(err))
+			return d, nil
 		}
 
-		log("info", fmt.Sprintf("backup to destination %s completed (snapshot: %s, size: %d bytes)",
-			dest.DestinationID, result.SnapshotID, result.TotalBytesProcessed))
-
-		reporter.ReportDestinationResult(
-			job.JobID,
-			dest.DestinationID,
-			"succeeded",
-			result.SnapshotID,
-			destStartedAt,
-			int64(result.TotalBytesProcessed),
-			"",
-		)
-
-		// Apply retention policy — non-fatal if it fails (backup data is safe).
-		retention := restic.RetentionPolicy{
-			Daily:   payload.Retention.Daily,
-			Weekly:  payload.Retention.Weekly,
-			Monthly: payload.Retention.Monthly,
-			Yearly:  payload.Retention.Yearly,
-		}
-		if err := e.wrapper.Forget(ctx, d, retention); err != nil {
-			log("warn", fmt.Sprintf("retention policy failed for destination %s: %v", dest.DestinationID, err))
+		if result.Incomplete {
+			// The snapshot exists but lacks the files restic could not read.
+			destStatus = "succeeded_with_warnings"
+			run.addFileErrors(result.FileErrorCount)
+			log("warn", fmt.Sprintf("backup to destination %s completed with %d unreadable file(s) (snapshot: %s, size: %d bytes)",
+				dest.DestinationID, result.FileErrorCount, result.SnapshotID, result.TotalBytesProcessed))
+		} else {
+			log("info", fmt.Sprintf("backup to destination %s completed (snapshot: %s, size: %d bytes)",
+				dest.DestinationID, result.SnapshotID, result.TotalBytesProcessed))
 		}
 	}
 
-	// If the context was cancelled (agent shutting down), the job was interrupted.
-	// Report it as cancelled so the UI shows the right state. The server-side
-	// orphan recovery will also mark it failed if this report doesn't reach the
-	// server (e.g. connection already closed).
-	if ctx.Err() != nil {
-		log("warn", "backup cancelled: agent shutting down")
-		reporter.ReportStatus(job.JobID, "cancelled", "agent shutting down")
+	snapshots, err := e.backupDatabases(ctx, run, d, dest)
+	if err == nil {
+		var outputs []restic.StdinSnapshot
+		outputs, err = e.backupCommands(ctx, run, d, dest)
+		snapshots = append(snapshots, outputs...)
+	}
+	if err != nil {
+		log("error", fmt.Sprintf("backup to destination %s failed: %v", dest.DestinationID, err))
+		run.reporter.ReportDestinationResult(run.job.JobID, dest.DestinationID, "failed", destStartedAt, nil, "", err.Error())
+		run.markFailed(FailureOther)
+		return d, nil
+	}
+	result.StdinSnapshots = snapshots
+	run.recordSnapshot(result.SnapshotID)
+	for _, s := range snapshots {
+		run.recordSnapshot(s.SnapshotID)
+	}
+
+	e.finishDestination(ctx, run, dest, d, destStatus, destStartedAt, result)
+	return d, result
+}
+
+// copyDestination copies the snapshot of result from the repository at from
+// to dest, reports the outcome and applies retention. The copy is reported
+// with the statistics of the original backup.
+func (e *Executor) copyDestination(ctx context.Context, run *backupRun, from restic.Destination, result *restic.BackupResult, dest destinationPayload) {
+	log := run.log
+	if dest.RepoURL == "" {
+		log("warn", fmt.Sprintf("destination %s has empty repo_url, skipping", dest.DestinationID))
 		return
 	}
 
-	// --- 6. Post-backup hook (always runs) ---
-	if payload.HookPostBackup != "" {
-		log("info", fmt.Sprintf("running post-backup hook: %s", payload.HookPostBackup))
+	destStartedAt := time.Now().UTC()
 
-		hook, err := e.resolveHook(ctx, payload.HookPostBackup)
+	d, ok := e.resticDestination(run, dest, destStartedAt)
+	if !ok {
+		return
+	}
+
+	// The snapshots of the database dumps and command outputs are copied
+	// with the one of the files, which is absent when the policy has none.
+	copied := *result
+	copied.StdinSnapshots = nil
+	ids := []string{result.SnapshotID}
+	for _, s := range result.StdinSnapshots {
+		ids = append(ids, s.SnapshotID)
+	}
+	for i, id := range ids {
+		if id == "" {
+			continue
+		}
+		log("info", fmt.Sprintf("copying snapshot %s to destination %s (type: %s)", id, dest.DestinationID, dest.Type))
+		snapshotID, err := e.wrapper.Copy(ctx, from, d, id)
 		if err != nil {
-			log("warn", fmt.Sprintf("failed to resolve post-backup hook: %v", err))
+			log("error", fmt.Sprintf("copy to destination %s failed: %v", dest.DestinationID, err))
+			run.reporter.ReportDestinationResult(run.job.JobID, dest.DestinationID, "failed", destStartedAt, nil, "", err.Error())
+			run.markFailed(classifyResticError(err))
+			return
+		}
+		log("info", fmt.Sprintf("copy to destination %s completed (snapshot: %s)", dest.DestinationID, snapshotID))
+		if i == 0 {
+			copied.SnapshotID = snapshotID
 		} else {
-			result, _ := e.hooks.Run(ctx, hook.Command, hook.Args, time.Duration(hook.TimeoutSecs)*time.Second)
-			if result.Output != "" {
-				log("info", "post-backup hook output: "+result.Output)
+			s := result.StdinSnapshots[i-1]
+			s.SnapshotID = snapshotID
+			copied.StdinSnapshots = append(copied.StdinSnapshots, s)
+		}
+	}
+
+	destStatus := "succeeded"
+	if copied.Incomplete {
+		destStatus = "succeeded_with_warnings"
+	}
+	e.finishDestination(ctx, run, dest, d, destStatus, destStartedAt, &copied)
+}
+
+// resticDestination builds the restic destination for dest. For local
+// destinations, it translates the user-provided path to the
+// container-accessible path (when ARKEEP_DOCKER_HOST_ROOT is set), then
+// ensures the directory exists and is writable before handing off to
+// restic. This produces a clear, actionable error instead of the cryptic
+// "permission denied" from restic internals. A zero-knowledge destination
+// is opened with its keystore key, created on the first backup. Returns
+// false after reporting the destination as failed.
+func (e *Executor) resticDestination(run *backupRun, dest destinationPayload, destStartedAt time.Time) (restic.Destination, bool) {
+	log := run.log
+	if dest.Type == "local" {
+		originalURL := dest.RepoURL
+		dest.RepoURL = translateLocalPath(dest.RepoURL, e.dockerHostRoot)
+		if dest.RepoURL != originalURL {
+			log("debug", fmt.Sprintf("translated local path %q → %q (ARKEEP_DOCKER_HOST_ROOT=%q)", originalURL, dest.RepoURL, e.dockerHostRoot))
+		}
+		if err := os.MkdirAll(dest.RepoURL, 0755); err != nil {
+			var errMsg string
+			if e.dockerHostRoot == "" {
+				errMsg = fmt.Sprintf(
+					"local path %q is not writable: %v — "+
+						"running without ARKEEP_DOCKER_HOST_ROOT: if this agent is inside Docker, "+
+						"set ARKEEP_DOCKER_HOST_ROOT=/hostfs and mount the backup share "+
+						"(e.g. -v /mnt/user:/hostfs/mnt/user:rw), or mount the full host root "+
+						"(-v /:/hostfs:ro on Linux); or set PUID/PGID to match the directory owner",
+					dest.RepoURL, err,
+				)
+			} else {
+				errMsg = fmt.Sprintf(
+					"local path %q is not writable (translated from %q via ARKEEP_DOCKER_HOST_ROOT=%q): %v — "+
+						"verify the share is mounted at the expected container path and is writable, "+
+						"or set PUID/PGID to match the directory owner",
+					dest.RepoURL, originalURL, e.dockerHostRoot, err,
+				)
 			}
+			log("error", fmt.Sprintf("backup to destination %s failed: %s", dest.DestinationID, errMsg))
+			run.reporter.ReportDestinationResult(run.job.JobID, dest.DestinationID, "failed", destStartedAt, nil, "", errMsg)
+			run.markFailed(FailureOther)
+			return restic.Destination{}, false
 		}
 	}
 
-	// --- 7. Final status ---
-	if backupFailed {
-		fail("one or more destinations failed")
-		return
+	password := run.payload.RepoPassword
+	if dest.RepoPassword != "" {
+		password = dest.RepoPassword
+	}
+	if dest.KeyID != "" {
+		key, err := e.keys.GetOrCreate(dest.KeyID, dest.KeyFingerprint)
+		if err != nil {
+			errMsg := fmt.Sprintf("repository key %s is not usable on this agent: %v — "+
+				"restore the keys directory from the escrow kit, or share the key from the agent that holds it", dest.KeyID, err)
+			log("error", fmt.Sprintf("backup to destination %s failed: %s", dest.DestinationID, errMsg))
+			run.reporter.ReportDestinationResult(run.job.JobID, dest.DestinationID, "failed", destStartedAt, nil, "", errMsg)
+			run.markFailed(FailureWrongPassword)
+			return restic.Destination{}, false
+		}
+		password = key
 	}
+	return restic.Destination{
+		Type:     restic.DestinationType(dest.Type),
+		RepoURL:  dest.RepoURL,
+		Password: password,
+		Env:      dest.Env,
+	}, true
+}
 
-	log("info", "backup completed successfully")
-	reporter.ReportStatus(job.JobID, "success", "backup completed")
+// finishDestination reports the snapshot of a successful backup or copy to
+// the server, holds the last known-good snapshot when the server asks for it
+// and applies the retention policy. The fingerprint of a zero-knowledge
+// destination's key goes with the report.
+func (e *Executor) finishDestination(ctx context.Context, run *backupRun, dest destinationPayload, d restic.Destination, status string, startedAt time.Time, result *restic.BackupResult) {
+	log := run.log
+	destinationID := dest.DestinationID
+	keyFingerprint := ""
+	if dest.KeyID != "" {
+		keyFingerprint = keyseal.Fingerprint(d.Password)
+	}
+	hold := run.reporter.ReportDestinationResult(run.job.JobID, destinationID, status, startedAt, result, keyFingerprint, "")
+
+	// The server flagged this run as anomalous: protect the last
+	// known-good snapshot before retention gets a chance to remove it.
+	if hold != "" {
+		log("warn", fmt.Sprintf("backup to destination %s changed unusually many files, holding snapshot %s", destinationID, hold))
+		newID, err := e.wrapper.Tag(ctx, d, hold, restic.HoldTag)
+		if err != nil {
+			// Skip retention rather than risk pruning the snapshot.
+			log("error", fmt.Sprintf("failed to hold snapshot %s on destination %s, skipping retention: %v", hold, destinationID, err))
+			return
+		}
+		if newID != "" {
+			run.reporter.ReportSnapshotHold(run.job.JobID, destinationID, hold, newID)
+		}
+	}
+
+	// Apply retention policy — non-fatal if it fails (backup data is safe).
+	retention := restic.RetentionPolicy{
+		Hourly:   run.payload.Retention.Hourly,
+		Daily:    run.payload.Retention.Daily,
+		Weekly:   run.payload.Retention.Weekly,
+		Monthly:  run.payload.Retention.Monthly,
+		Yearly:   run.payload.Retention.Yearly,
+		Tags:     run.payload.Retention.Tags,
+		KeepTags: run.payload.Retention.KeepTags,
+	}
+	if err := e.wrapper.Forget(ctx, d, retention); err != nil {
+		log("warn", fmt.Sprintf("retention policy failed for destination %s: %v", destinationID, err))
+	}
 }
 
 // executeRestore runs a single restore job to completion.
 //
 // Execution sequence:
 //  1. Deserialize payload
-//  2. Report status "running"
-//  3. Run restic restore, streaming output as log lines
-//  4. Report status "succeeded" or "failed"
+//  2. Report status "running" and run the pre-restore hook steps (abort on
+//     failure)
+//  3. Run restic restore, streaming output as log lines, or for a database
+//     dump pipe restic dump into the engine's restore tool
+//  4. Run the post-restore hook steps and report status "succeeded", or
+//     "failed" when the restore or a step failed
 func (e *Executor) executeRestore(ctx context.Context, job JobAssignment, sink LogSink, reporter StatusReporter) {
 	log := func(level, msg string) {
 		sink.SendLog(job.JobID, level, msg)
@@ -479,7 +1136,7 @@ func (e *Executor) executeRestore(ctx context.Context, job JobAssignment, sink L
 		fail("restore payload missing restic_snapshot_id")
 		return
 	}
-	if payload.TargetPath == "" {
+	if payload.TargetPath == "" && payload.Database == nil {
 		fail("restore payload missing target_path")
 		return
 	}
@@ -491,6 +1148,29 @@ func (e *Executor) executeRestore(ctx context.Context, job JobAssignment, sink L
 	// --- 2. Report running ---
 	reporter.ReportStatus(job.JobID, "running", "starting restore")
 
+	hc := hookContext{jobID: job.JobID, policyID: job.PolicyID, status: hookStatusRunning, snapshotID: payload.ResticSnapshotID}
+	if err := e.runHookPhase(ctx, hookPreRestore, payload.Hooks[hookPreRestore], hc, log); err != nil {
+		if e.interrupted(ctx, job, "restore", 0, log, reporter) {
+			return
+		}
+		fail(err.Error())
+		return
+	}
+
+	// finish runs the post-restore steps of a completed restore and
+	// reports its outcome.
+	finish := func() {
+		hc.status = hookStatusSuccess
+		if err := e.runHookPhase(ctx, hookPostRestore, payload.Hooks[hookPostRestore], hc, log); err != nil {
+			if e.interrupted(ctx, job, "restore", 0, log, reporter) {
+				return
+			}
+			fail(err.Error())
+			return
+		}
+		reporter.ReportStatus(job.JobID, "success", "restore completed")
+	}
+
 	// Translate the restore target path when ARKEEP_DOCKER_HOST_ROOT is set.
 	// Exception: "/" is the in-place sentinel — the GUI sends it to mean "restore
 	// to original location". Since the backup was already written under hostRoot
@@ -501,7 +1181,11 @@ func (e *Executor) executeRestore(ctx context.Context, job JobAssignment, sink L
 	if payload.TargetPath != "/" {
 		targetPath = translateLocalPath(payload.TargetPath, e.dockerHostRoot)
 	}
-	log("info", fmt.Sprintf("restore started: snapshot %s → %s", payload.ResticSnapshotID, targetPath))
+	if payload.Database != nil {
+		log("info", fmt.Sprintf("restore started: snapshot %s → %s", payload.ResticSnapshotID, databaseName(*payload.Database)))
+	} else {
+		log("info", fmt.Sprintf("restore started: snapshot %s → %s", payload.ResticSnapshotID, targetPath))
+	}
 
 	// --- 3. Build exclude list for in-place restore ---
 	// When restoring in-place (target "/"), Docker named-volume paths may be
@@ -520,17 +1204,40 @@ func (e *Executor) executeRestore(ctx context.Context, job JobAssignment, sink L
 		repoURL = translateLocalPath(repoURL, e.dockerHostRoot)
 	}
 
+	password := payload.RepoPassword
+	if payload.KeyID != "" {
+		key, err := e.restoreKey(payload)
+		if err != nil {
+			fail(fmt.Sprintf("repository key %s is not usable on this agent: %v", payload.KeyID, err))
+			return
+		}
+		password = key
+	}
+
 	d := restic.Destination{
 		Type:     restic.DestinationType(payload.Destination.Type),
 		RepoURL:  repoURL,
-		Password: payload.RepoPassword,
+		Password: password,
 		Env:      payload.Destination.Env,
 	}
 
+	// A database dump is piped from restic dump into the engine's restore
+	// tool instead of being written to disk.
+	if payload.Database != nil {
+		if err := e.restoreDatabase(ctx, d, payload.ResticSnapshotID, *payload.Database); err != nil {
+			if e.interrupted(ctx, job, "restore", 0, log, reporter) {
+				return
+			}
+			fail(fmt.Sprintf("restore failed: %v", err))
+			return
+		}
+		log("info", fmt.Sprintf("restore completed successfully: dump loaded into %s", databaseName(*payload.Database)))
+		finish()
+		return
+	}
+
 	if err := e.wrapper.Restore(ctx, d, payload.ResticSnapshotID, targetPath, "", excludePaths, e.dockerHostRoot); err != nil {
-		if ctx.Err() != nil {
-			log("warn", "restore cancelled: agent shutting down")
-			reporter.ReportStatus(job.JobID, "cancelled", "agent shutting down")
+		if e.interrupted(ctx, job, "restore", 0, log, reporter) {
 			return
 		}
 		if strings.Contains(err.Error(), "Access is denied") {
@@ -547,7 +1254,270 @@ func (e *Executor) executeRestore(ctx context.Context, job JobAssignment, sink L
 
 	// --- 5. Final status ---
 	log("info", fmt.Sprintf("restore completed successfully: files written to %s", targetPath))
-	reporter.ReportStatus(job.JobID, "success", "restore completed")
+	finish()
+}
+
+// restoreKey returns the keystore key a zero-knowledge restore opens the
+// repository with: the agent's own, or the one another agent shared with it,
+// which is imported into the keystore.
+func (e *Executor) restoreKey(payload restorePayload) (string, error) {
+	key, err := e.keys.Get(payload.KeyID)
+	if errors.Is(err, keystore.ErrNotFound) && payload.SealedKey != "" {
+		sealed, decodeErr := base64.StdEncoding.DecodeString(payload.SealedKey)
+		if decodeErr != nil {
+			return "", fmt.Errorf("invalid sealed key: %w", decodeErr)
+		}
+		return e.keys.Import(payload.KeyID, sealed, payload.KeyFingerprint)
+	}
+	if err != nil {
+		return "", err
+	}
+	if payload.KeyFingerprint != "" && keyseal.Fingerprint(key) != payload.KeyFingerprint {
+		return "", keystore.ErrFingerprintMismatch
+	}
+	return key, nil
+}
+
+// executeDryRun estimates a first backup of the policy's sources.
+//
+// Execution sequence:
+//  1. Deserialize payload
+//  2. Report status "running"
+//  3. Resolve sources
+//  4. Run restic backup --dry-run into a scratch repository
+//  5. Report the estimate, then status "succeeded" or "failed"
+//
+// The scratch repository is a fresh local repository in a temporary
+// directory, removed afterwards: the dry run never touches the policy's
+// destinations, and every file counts as new, as in a first backup. Hooks do
+// not run, database sources are not dumped and command sources not run.
+func (e *Executor) executeDryRun(ctx context.Context, job JobAssignment, sink LogSink, reporter StatusReporter) {
+	log := func(level, msg string) {
+		sink.SendLog(job.JobID, level, msg)
+		switch level {
+		case "error":
+			e.logger.Error(msg, zap.String("job_id", job.JobID))
+		case "warn":
+			e.logger.Warn(msg, zap.String("job_id", job.JobID))
+		default:
+			e.logger.Info(msg, zap.String("job_id", job.JobID))
+		}
+	}
+
+	fail := func(msg string) {
+		log("error", msg)
+		reporter.ReportFailure(job.JobID, FailureOther, msg)
+	}
+
+	// --- 1. Deserialize payload ---
+	var payload dryRunPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		fail(fmt.Sprintf("failed to deserialize dry-run payload: %v", err))
+		return
+	}
+
+	if payload.MaxRuntimeSeconds > 0 {
+		maxRuntime := time.Duration(payload.MaxRuntimeSeconds) * time.Second
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, maxRuntime, errMaxRuntimeExceeded)
+		defer cancel()
+	}
+
+	// --- 2. Report running ---
+	reporter.ReportStatus(job.JobID, "running", "starting dry run")
+	log("info", "dry run started")
+
+	// --- 3. Resolve sources ---
+	// Container hooks are not run: a dry run leaves the containers alone.
+	expanded, _, err := e.expandLabelSources(ctx, payload.Sources, log)
+	if err != nil {
+		if e.interrupted(ctx, job, "dry run", payload.MaxRuntimeSeconds, log, reporter) {
+			return
+		}
+		fail(fmt.Sprintf("failed to expand docker-label sources: %v", err))
+		return
+	}
+
+	sources, err := e.resolveSources(ctx, expanded, log)
+	if err != nil {
+		if e.interrupted(ctx, job, "dry run", payload.MaxRuntimeSeconds, log, reporter) {
+			return
+		}
+		fail(fmt.Sprintf("failed to resolve backup sources: %v", err))
+		return
+	}
+	if len(sources) == 0 {
+		fail("no accessible backup sources")
+		return
+	}
+	log("info", fmt.Sprintf("resolved %d source(s)", len(sources)))
+
+	// --- 4. Dry run into a scratch repository ---
+	repoDir, err := os.MkdirTemp("", "arkeep-dry-run-*")
+	if err != nil {
+		fail(fmt.Sprintf("failed to create scratch repository: %v", err))
+		return
+	}
+	defer os.RemoveAll(repoDir) //nolint:errcheck
+
+	password := make([]byte, 16)
+	if _, err := rand.Read(password); err != nil {
+		fail(fmt.Sprintf("failed to generate scratch repository password: %v", err))
+		return
+	}
+	d := restic.Destination{
+		Type:     restic.DestLocal,
+		RepoURL:  repoDir,
+		Password: hex.EncodeToString(password),
+	}
+
+	result, err := e.wrapper.DryRun(ctx, d, restic.BackupOptions{Sources: sources}, progressReporter(job.JobID, "", sink, reporter))
+	if err != nil {
+		if e.interrupted(ctx, job, "dry run", payload.MaxRuntimeSeconds, log, reporter) {
+			return
+		}
+		fail(fmt.Sprintf("dry run failed: %v", err))
+		return
+	}
+
+	// --- 5. Report ---
+	for _, u := range result.Unreadable {
+		log("warn", fmt.Sprintf("unreadable: %s: %s", u.Path, u.Error))
+	}
+	reporter.ReportDryRun(job.JobID, result)
+
+	msg := fmt.Sprintf("dry run completed: %d files, %d bytes", result.TotalFiles, result.TotalBytes)
+	if result.UnreadableCount > 0 {
+		msg += fmt.Sprintf(", %d unreadable path(s)", result.UnreadableCount)
+	}
+	log("info", msg)
+	reporter.ReportStatus(job.JobID, "success", msg)
+}
+
+// executeReplicate runs a single replication job to completion.
+//
+// Execution sequence:
+//  1. Deserialize payload
+//  2. Report status "running"
+//  3. For each destination: restic copy the matching snapshots of the
+//     source destination, report the copies
+//  4. Report status "succeeded" or "failed"
+func (e *Executor) executeReplicate(ctx context.Context, job JobAssignment, sink LogSink, reporter StatusReporter) {
+	log := func(level, msg string) {
+		sink.SendLog(job.JobID, level, msg)
+		switch level {
+		case "error":
+			e.logger.Error(msg, zap.String("job_id", job.JobID))
+		case "warn":
+			e.logger.Warn(msg, zap.String("job_id", job.JobID))
+		default:
+			e.logger.Info(msg, zap.String("job_id", job.JobID))
+		}
+	}
+
+	// --- 1. Deserialize payload ---
+	var payload replicatePayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		msg := fmt.Sprintf("failed to deserialize replication payload: %v", err)
+		log("error", msg)
+		reporter.ReportFailure(job.JobID, FailureOther, msg)
+		return
+	}
+
+	if payload.MaxRuntimeSeconds > 0 {
+		maxRuntime := time.Duration(payload.MaxRuntimeSeconds) * time.Second
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, maxRuntime, errMaxRuntimeExceeded)
+		defer cancel()
+	}
+
+	// --- 2. Report running ---
+	reporter.ReportStatus(job.JobID, "running", "starting replication")
+	log("info", fmt.Sprintf("replication from destination %s started", payload.Source.DestinationID))
+
+	from := restic.Destination{
+		Type:     restic.DestinationType(payload.Source.Type),
+		RepoURL:  payload.Source.RepoURL,
+		Password: payload.RepoPassword,
+		Env:      payload.Source.Env,
+	}
+	if payload.Source.Type == "local" {
+		from.RepoURL = translateLocalPath(from.RepoURL, e.dockerHostRoot)
+	}
+	filter := restic.SnapshotFilter{Tags: payload.Tags, Host: payload.Host}
+
+	// --- 3. Copy to each destination ---
+	// Replication shares the destination handling of backups; only the
+	// repository password of the payload is used.
+	run := &backupRun{job: job, payload: backupPayload{RepoPassword: payload.RepoPassword}, sink: sink, reporter: reporter, log: log}
+	for _, dest := range payload.Destinations {
+		if ctx.Err() != nil {
+			break
+		}
+		if dest.RepoURL == "" {
+			log("warn", fmt.Sprintf("destination %s has empty repo_url, skipping", dest.DestinationID))
+			continue
+		}
+
+		log("info", fmt.Sprintf("copying snapshots to destination %s (type: %s)", dest.DestinationID, dest.Type))
+		destStartedAt := time.Now().UTC()
+
+		d, ok := e.resticDestination(run, dest, destStartedAt)
+		if !ok {
+			continue
+		}
+
+		copies, err := e.wrapper.CopySnapshots(ctx, from, d, filter)
+		if err != nil {
+			log("error", fmt.Sprintf("replication to destination %s failed: %v", dest.DestinationID, err))
+			reporter.ReportDestinationResult(job.JobID, dest.DestinationID, "failed", destStartedAt, nil, "", err.Error())
+			run.markFailed(classifyResticError(err))
+			continue
+		}
+		log("info", fmt.Sprintf("destination %s holds %d replicated snapshot(s)", dest.DestinationID, len(copies)))
+		reporter.ReportReplication(job.JobID, dest.DestinationID, destStartedAt, copies)
+	}
+
+	if e.interrupted(ctx, job, "replication", payload.MaxRuntimeSeconds, log, reporter) {
+		return
+	}
+
+	// --- 4. Final status ---
+	if run.failed {
+		log("error", "one or more destinations failed")
+		reporter.ReportFailure(job.JobID, run.failureClass, "one or more destinations failed")
+		return
+	}
+	log("info", "replication completed successfully")
+	reporter.ReportStatus(job.JobID, "success", "replication completed")
+}
+
+// interrupted reports the outcome of a job whose context was cancelled and
+// returns true, or returns false if ctx is still live. what names the job
+// kind in messages ("backup", "restore", "dry run", "replication").
+//
+//   - Max runtime exceeded: the job fails.
+//   - Aborted by the server: nothing is reported, the server has already
+//     recorded the outcome.
+//   - Agent shutting down: the job is reported as cancelled. The server-side
+//     orphan recovery also marks it failed if this report doesn't reach the
+//     server (e.g. connection already closed).
+func (e *Executor) interrupted(ctx context.Context, job JobAssignment, what string, maxRuntimeSecs int, log func(level, msg string), reporter StatusReporter) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+	switch context.Cause(ctx) {
+	case errMaxRuntimeExceeded:
+		msg := fmt.Sprintf("%s exceeded the max runtime of %s and was stopped", what, time.Duration(maxRuntimeSecs)*time.Second)
+		log("error", msg)
+		reporter.ReportFailure(job.JobID, FailureOther, msg)
+	case errAborted:
+		log("warn", what+" aborted by server")
+	default:
+		log("warn", what+" cancelled: agent shutting down")
+		reporter.ReportStatus(job.JobID, "cancelled", "agent shutting down")
+	}
+	return true
 }
 
 // buildInPlaceExcludes returns the --exclude paths for an in-place restore.
@@ -624,8 +1594,12 @@ func (e *Executor) resolveSources(ctx context.Context, sourcesJSON string, log f
 			continue
 		}
 
-		// Docker volume source: docker-volume://<volume-name>
-		volumeName := strings.TrimPrefix(src, "docker-volume://")
+		// Docker volume source: docker-volume://<volume-name>, with an
+		// optional container mode handled by quiesceContainers.
+		volumeName, _, err := parseVolumeSource(src)
+		if err != nil {
+			return nil, err
+		}
 
 		if e.docker == nil {
 			return nil, fmt.Errorf("source %q requires Docker but Docker is unavailable on this host", src)
@@ -660,11 +1634,14 @@ func (e *Executor) resolveSources(ctx context.Context, sourcesJSON string, log f
 	return resolved, nil
 }
 
-func (e *Executor) resolveHook(ctx context.Context, hookJSON string) (*hookPayload, error) {
-	var hook hookPayload
-	if err := json.Unmarshal([]byte(hookJSON), &hook); err != nil {
-		return nil, fmt.Errorf("invalid hook JSON: %w", err)
+// classifyResticError maps a restic failure onto a failure class.
+func classifyResticError(err error) string {
+	switch {
+	case restic.IsWrongPassword(err):
+		return FailureWrongPassword
+	case restic.IsNetworkError(err):
+		return FailureNetwork
+	default:
+		return FailureOther
 	}
-
-	return &hook, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,29 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestAbortQueuedJob(t *testing.T) {
+	e := New(nil, nil, nil, nil, zap.NewNop(), "", nil)
+
+	if e.Abort("unknown") {
+		t.Error("Abort(unknown) = true, want false")
+	}
+	if err := e.Enqueue(JobAssignment{JobID: "job-1"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if !e.Abort("job-1") {
+		t.Fatal("Abort(job-1) = false, want true for a queued job")
+	}
+
+	// The job is skipped before it reaches a handler: with no wrapper, no
+	// sink and no reporter, running it would panic.
+	e.execute(context.Background(), <-e.queue, nil, nil)
+	if e.Abort("job-1") {
+		t.Error("Abort(job-1) after it left the queue = true, want false")
+	}
+}
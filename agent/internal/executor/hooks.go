@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arkeep-io/arkeep/agent/internal/hooks"
+)
+
+// Hook phases, mirroring the server scheduler's.
+const (
+	hookPreBackup   = "pre_backup"
+	hookPostBackup  = "post_backup"
+	hookOnSuccess   = "on_success"
+	hookOnFailure   = "on_failure"
+	hookPreRestore  = "pre_restore"
+	hookPostRestore = "post_restore"
+)
+
+// Values of ARKEEP_JOB_STATUS in the environment of hook steps.
+const (
+	hookStatusRunning = "running"
+	hookStatusSuccess = "success"
+	hookStatusFailed  = "failed"
+)
+
+// hookStep mirrors the struct serialized by the server scheduler for each
+// step of a hook phase.
+type hookStep struct {
+	Name            string            `json:"name"`
+	Command         string            `json:"command"`
+	Args            []string          `json:"args"`
+	Env             map[string]string `json:"env"`
+	WorkingDir      string            `json:"working_dir"`
+	RunAs           string            `json:"run_as"`
+	TimeoutSecs     int               `json:"timeout_secs"`
+	ContinueOnError bool              `json:"continue_on_error"`
+}
+
+// hookContext describes the job a hook phase runs in. Each step sees it as
+// ARKEEP_* environment variables.
+type hookContext struct {
+	jobID      string
+	policyID   string
+	status     string // one of the hookStatus* constants
+	snapshotID string // empty until the job has taken or picked a snapshot
+}
+
+// env returns the environment variables of hc for a step of phase.
+func (hc hookContext) env(phase string) []string {
+	return []string{
+		"ARKEEP_JOB_ID=" + hc.jobID,
+		"ARKEEP_POLICY_ID=" + hc.policyID,
+		"ARKEEP_HOOK_PHASE=" + phase,
+		"ARKEEP_JOB_STATUS=" + hc.status,
+		"ARKEEP_SNAPSHOT_ID=" + hc.snapshotID,
+	}
+}
+
+// runHookPhase runs the steps of phase in order. A failed step stops the
+// phase and its error is returned, unless the step continues on error, in
+// which case the failure is logged and the next step runs.
+func (e *Executor) runHookPhase(ctx context.Context, phase string, steps []hookStep, hc hookContext, log func(level, msg string)) error {
+	label := strings.ReplaceAll(phase, "_", "-")
+	for i, step := range steps {
+		name := step.Name
+		if name == "" {
+			name = step.Command
+		}
+		log("info", fmt.Sprintf("running %s hook %d/%d: %s", label, i+1, len(steps), name))
+
+		// The context variables come last so that they win over the step's.
+		var env []string
+		for _, k := range slices.Sorted(maps.Keys(step.Env)) {
+			env = append(env, k+"="+step.Env[k])
+		}
+		env = append(env, hc.env(phase)...)
+
+		result, err := e.hooks.Run(ctx, hooks.Step{
+			Command: step.Command,
+			Args:    step.Args,
+			Env:     env,
+			Dir:     step.WorkingDir,
+			User:    step.RunAs,
+			Timeout: time.Duration(step.TimeoutSecs) * time.Second,
+		})
+		if result.Output != "" {
+			log("info", fmt.Sprintf("%s hook output (%s): %s", label, name, result.Output))
+		}
+		if err == nil {
+			continue
+		}
+		if step.ContinueOnError && ctx.Err() == nil {
+			log("warn", fmt.Sprintf("%s hook %s failed (exit %d), continuing: %v", label, name, result.ExitCode, err))
+			continue
+		}
+		return fmt.Errorf("%s hook %s failed (exit %d): %w", label, name, result.ExitCode, err)
+	}
+	return nil
+}
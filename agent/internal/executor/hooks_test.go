@@ -0,0 +1,65 @@
+package executor
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/arkeep-io/arkeep/agent/internal/hooks"
+)
+
+func TestRunHookPhase(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook steps use POSIX shell syntax")
+	}
+	e := &Executor{hooks: hooks.NewRunner(0)}
+	hc := hookContext{jobID: "job-1", policyID: "policy-1", status: hookStatusSuccess, snapshotID: "abc123"}
+
+	run := func(steps []hookStep) ([]string, error) {
+		var lines []string
+		err := e.runHookPhase(context.Background(), hookOnSuccess, steps, hc, func(_, msg string) {
+			lines = append(lines, msg)
+		})
+		return lines, err
+	}
+
+	t.Run("exposes the job context and the step settings", func(t *testing.T) {
+		dir := t.TempDir()
+		lines, err := run([]hookStep{{
+			Command:    `echo "$ARKEEP_JOB_ID $ARKEEP_JOB_STATUS $ARKEEP_SNAPSHOT_ID $ARKEEP_HOOK_PHASE $GREETING $1 $(pwd)"`,
+			Args:       []string{"arg"},
+			Env:        map[string]string{"GREETING": "hello"},
+			WorkingDir: dir,
+		}})
+		if err != nil {
+			t.Fatalf("runHookPhase: %v", err)
+		}
+		want := "job-1 success abc123 on_success hello arg " + dir
+		if !strings.HasSuffix(lines[len(lines)-1], want) {
+			t.Errorf("output = %q, want suffix %q", lines[len(lines)-1], want)
+		}
+	})
+
+	t.Run("a failed step stops the phase", func(t *testing.T) {
+		lines, err := run([]hookStep{{Name: "fails", Command: "exit 3"}, {Command: "echo never"}})
+		if err == nil || !strings.Contains(err.Error(), "on-success hook fails failed (exit 3)") {
+			t.Errorf("err = %v", err)
+		}
+		for _, line := range lines {
+			if strings.Contains(line, "never") && strings.Contains(line, "output") {
+				t.Errorf("the step after the failure ran: %q", line)
+			}
+		}
+	})
+
+	t.Run("continue on error runs the next step", func(t *testing.T) {
+		lines, err := run([]hookStep{{Command: "exit 1", ContinueOnError: true}, {Command: "echo next"}})
+		if err != nil {
+			t.Fatalf("runHookPhase: %v", err)
+		}
+		if !strings.HasSuffix(lines[len(lines)-1], ": next") {
+			t.Errorf("last line = %q, want the output of the second step", lines[len(lines)-1])
+		}
+	})
+}
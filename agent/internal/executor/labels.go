@@ -0,0 +1,220 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arkeep-io/arkeep/agent/internal/docker"
+)
+
+// Container labels honoured by docker-label:// sources. They apply to the
+// volumes the labelled container mounts.
+const (
+	// labelExclude leaves the container's volumes out of the backup.
+	labelExclude = "arkeep.backup.exclude"
+	// labelStop stops the container while its volumes are backed up.
+	labelStop = "arkeep.backup.stop"
+	// labelPreHook is a shell command run inside the container before the
+	// backup, e.g. to flush a database to disk.
+	labelPreHook = "arkeep.backup.pre-hook"
+)
+
+// containerHookTimeout bounds a pre-backup hook run inside a container.
+const containerHookTimeout = 10 * time.Minute
+
+// labelDiscoverer is the subset of the Docker client used to expand
+// docker-label:// sources. Implemented by *docker.Client.
+type labelDiscoverer interface {
+	ListVolumes(ctx context.Context, labelFilter string) ([]docker.VolumeInfo, error)
+	ListContainers(ctx context.Context) ([]docker.ContainerInfo, error)
+}
+
+// containerHook is a pre-backup hook declared with labelPreHook.
+type containerHook struct {
+	container string // container ID
+	name      string // container name, for the logs
+	command   string
+}
+
+// expandLabelSources replaces the docker-label:// sources of the sources
+// JSON with a docker-volume:// source for each matching volume, so that
+// volumes created since the policy was saved are backed up too. It returns
+// the expanded sources JSON and the pre-backup hooks declared by the
+// containers of the matched volumes.
+func (e *Executor) expandLabelSources(ctx context.Context, sourcesJSON string, log func(level, msg string)) (string, []containerHook, error) {
+	var raw []string
+	if err := json.Unmarshal([]byte(sourcesJSON), &raw); err != nil {
+		return "", nil, fmt.Errorf("invalid sources JSON: %w", err)
+	}
+	if !slices.ContainsFunc(raw, isLabelSource) {
+		return sourcesJSON, nil, nil
+	}
+
+	var ctl labelDiscoverer
+	if e.docker != nil {
+		ctl = e.docker
+	}
+	expanded, hooks, err := expandLabels(ctx, ctl, raw, log)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := json.Marshal(expanded)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode sources: %w", err)
+	}
+	return string(data), hooks, nil
+}
+
+func isLabelSource(src string) bool {
+	return strings.HasPrefix(src, "docker-label://")
+}
+
+// expandLabels is the implementation of Executor.expandLabelSources over a
+// labelDiscoverer, which is nil when Docker is unavailable.
+//
+// A docker-label://<key>[=<value>] source matches the volumes carrying the
+// label and the named volumes of the containers carrying it. Volumes also
+// listed as docker-volume:// sources keep their explicit settings.
+func expandLabels(ctx context.Context, ctl labelDiscoverer, sources []string, log func(level, msg string)) ([]string, []containerHook, error) {
+	if ctl == nil {
+		return nil, nil, fmt.Errorf("docker-label sources require Docker but Docker is unavailable on this host")
+	}
+	containers, err := ctl.ListContainers(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	// Volumes already listed explicitly are not added a second time.
+	seen := make(map[string]bool)
+	for _, src := range sources {
+		if strings.HasPrefix(src, "docker-volume://") {
+			if name, _, err := parseVolumeSource(src); err == nil {
+				seen[name] = true
+			}
+		}
+	}
+
+	var expanded []string
+	var hooks []containerHook
+	hooked := make(map[string]bool)
+	for _, src := range sources {
+		if !isLabelSource(src) {
+			expanded = append(expanded, src)
+			continue
+		}
+		filter := strings.TrimPrefix(src, "docker-label://")
+
+		volumes, err := ctl.ListVolumes(ctx, filter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list volumes matching %q: %w", filter, err)
+		}
+		var names []string
+		for _, v := range volumes {
+			names = append(names, v.Name)
+		}
+		for _, c := range containers {
+			if matchesLabel(c.Labels, filter) {
+				names = append(names, c.Volumes...)
+			}
+		}
+		slices.Sort(names)
+		names = slices.Compact(names)
+
+		var matched []string
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			mounting := volumeContainers(containers, name)
+			if i := slices.IndexFunc(mounting, func(c docker.ContainerInfo) bool { return labelTrue(c.Labels, labelExclude) }); i >= 0 {
+				log("info", fmt.Sprintf("skipping volume %s: container %s has %s", name, mounting[i].Name, labelExclude))
+				continue
+			}
+
+			uri := "docker-volume://" + name
+			if slices.ContainsFunc(mounting, func(c docker.ContainerInfo) bool { return labelTrue(c.Labels, labelStop) }) {
+				uri += "?containers=" + containersStop
+			}
+			expanded = append(expanded, uri)
+			matched = append(matched, name)
+
+			for _, c := range mounting {
+				command := strings.TrimSpace(c.Labels[labelPreHook])
+				if command == "" || hooked[c.ID] {
+					continue
+				}
+				hooked[c.ID] = true
+				if c.State != "running" {
+					log("warn", fmt.Sprintf("skipping pre-backup hook of container %s: the container is %s", c.Name, c.State))
+					continue
+				}
+				hooks = append(hooks, containerHook{container: c.ID, name: c.Name, command: command})
+			}
+		}
+
+		if len(matched) == 0 {
+			log("warn", fmt.Sprintf("%s matched no volume", src))
+		} else {
+			log("info", fmt.Sprintf("%s matched %d volume(s): %s", src, len(matched), strings.Join(matched, ", ")))
+		}
+	}
+
+	if len(expanded) == 0 {
+		return nil, nil, fmt.Errorf("no Docker volume matches the policy's docker-label sources")
+	}
+	return expanded, hooks, nil
+}
+
+// volumeContainers returns the containers that mount the named volume.
+func volumeContainers(containers []docker.ContainerInfo, volume string) []docker.ContainerInfo {
+	var result []docker.ContainerInfo
+	for _, c := range containers {
+		if slices.Contains(c.Volumes, volume) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// matchesLabel reports whether labels match a Docker label filter: "key"
+// matches any value, "key=value" only that value.
+func matchesLabel(labels map[string]string, filter string) bool {
+	key, value, hasValue := strings.Cut(filter, "=")
+	v, ok := labels[key]
+	return ok && (!hasValue || v == value)
+}
+
+// labelTrue reports whether the label is set to a true boolean ("true", "1").
+func labelTrue(labels map[string]string, key string) bool {
+	v, err := strconv.ParseBool(labels[key])
+	return err == nil && v
+}
+
+// runContainerHooks runs the pre-backup hooks declared by container labels,
+// in order, and stops at the first failure.
+func (e *Executor) runContainerHooks(ctx context.Context, hooks []containerHook, log func(level, msg string)) error {
+	for _, h := range hooks {
+		log("info", fmt.Sprintf("running pre-backup hook in container %s: %s", h.name, h.command))
+
+		hookCtx, cancel := context.WithTimeout(ctx, containerHookTimeout)
+		result, err := e.docker.Exec(hookCtx, h.container, []string{"sh", "-c", h.command})
+		cancel()
+		if result.Output != "" {
+			log("info", fmt.Sprintf("pre-backup hook output (%s): %s", h.name, result.Output))
+		}
+		if err != nil {
+			return fmt.Errorf("pre-backup hook of container %s: %w", h.name, err)
+		}
+		if result.ExitCode != 0 {
+			return fmt.Errorf("pre-backup hook of container %s failed (exit %d)", h.name, result.ExitCode)
+		}
+	}
+	return nil
+}
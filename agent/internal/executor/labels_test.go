@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/arkeep-io/arkeep/agent/internal/docker"
+)
+
+// fakeDiscoverer is a labelDiscoverer over fixed volumes and containers.
+type fakeDiscoverer struct {
+	volumes    []docker.VolumeInfo
+	containers []docker.ContainerInfo
+}
+
+func (f *fakeDiscoverer) ListVolumes(_ context.Context, labelFilter string) ([]docker.VolumeInfo, error) {
+	var result []docker.VolumeInfo
+	for _, v := range f.volumes {
+		if matchesLabel(v.Labels, labelFilter) {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeDiscoverer) ListContainers(context.Context) ([]docker.ContainerInfo, error) {
+	return f.containers, nil
+}
+
+func TestExpandLabels(t *testing.T) {
+	log := func(level, msg string) {}
+	ctl := &fakeDiscoverer{
+		volumes: []docker.VolumeInfo{
+			{Name: "uploads", Labels: map[string]string{"arkeep.backup": "true"}},
+			{Name: "scratch", Labels: map[string]string{"arkeep.backup": "false"}},
+		},
+		containers: []docker.ContainerInfo{
+			{ID: "db", Name: "postgres", State: "running", Volumes: []string{"pgdata"}, Labels: map[string]string{
+				"arkeep.backup":          "true",
+				"arkeep.backup.stop":     "true",
+				"arkeep.backup.pre-hook": "psql -c CHECKPOINT",
+			}},
+			{ID: "cache", Name: "redis", State: "running", Volumes: []string{"redis"}, Labels: map[string]string{
+				"arkeep.backup":         "true",
+				"arkeep.backup.exclude": "true",
+			}},
+			{ID: "app", Name: "web", State: "exited", Volumes: []string{"uploads", "config"}, Labels: map[string]string{
+				"arkeep.backup.pre-hook": "sync",
+			}},
+		},
+	}
+
+	t.Run("expands to the labelled volumes and containers", func(t *testing.T) {
+		sources := []string{"/etc", "docker-volume://config?containers=pause", "docker-label://arkeep.backup=true"}
+		got, hooks, err := expandLabels(context.Background(), ctl, sources, log)
+		if err != nil {
+			t.Fatalf("expandLabels: %v", err)
+		}
+		want := []string{"/etc", "docker-volume://config?containers=pause", "docker-volume://pgdata?containers=stop", "docker-volume://uploads"}
+		if !slices.Equal(got, want) {
+			t.Errorf("sources = %v, want %v", got, want)
+		}
+		// The hook of the stopped web container is skipped.
+		if len(hooks) != 1 || hooks[0].container != "db" || hooks[0].command != "psql -c CHECKPOINT" {
+			t.Errorf("hooks = %+v, want the postgres hook", hooks)
+		}
+	})
+
+	t.Run("fails when nothing matches", func(t *testing.T) {
+		if _, _, err := expandLabels(context.Background(), ctl, []string{"docker-label://com.example.none"}, log); err == nil {
+			t.Error("expandLabels succeeded, want an error")
+		}
+	})
+
+	t.Run("fails without Docker", func(t *testing.T) {
+		if _, _, err := expandLabels(context.Background(), nil, []string{"docker-label://arkeep.backup"}, log); err == nil {
+			t.Error("expandLabels succeeded without Docker")
+		}
+	})
+}
@@ -0,0 +1,12 @@
+package hooks
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// runAs is not supported on Windows: switching users there needs the
+// user's password.
+func runAs(*exec.Cmd, string) error {
+	return errors.New("running hooks as another user is not supported on Windows")
+}
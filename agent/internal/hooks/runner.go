@@ -1,12 +1,16 @@
 // Package hooks handles the execution of user-defined shell commands that run
-// before and after a backup job. Hooks are configured per-policy in the
-// HookPreBackup and HookPostBackup fields (see server/internal/db/models.go).
+// around backup and restore jobs. Hooks are configured per-policy as ordered
+// steps per phase (see server/internal/scheduler/hooks.go); the executor runs
+// each step with Runner.Run and decides what a failure means for the job.
 //
 // Hooks run as blocking subprocesses with a configurable timeout. stdout and
 // stderr are captured and returned to the caller so they can be included in
 // the job log stream. A non-zero exit code causes the hook to be considered
-// failed — for pre-backup hooks this aborts the job; for post-backup hooks
-// the failure is logged but does not change the job outcome.
+// failed.
+//
+// Hook processes inherit the agent's environment without its ARKEEP_*,
+// RESTIC_* and RCLONE_* variables, which hold the agent's credentials, plus
+// the variables of the step.
 //
 // The shell used depends on the host OS:
 //   - Linux / macOS: /bin/sh -c "<command>"
@@ -18,6 +22,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
@@ -44,7 +49,24 @@ type Result struct {
 	Duration time.Duration
 }
 
-// Runner executes pre/post backup hooks.
+// Step is a single hook command and the settings it runs with.
+type Step struct {
+	// Command is the shell command string, see buildShellCmd.
+	Command string
+	// Args are passed to Command as positional parameters.
+	Args []string
+	// Env holds KEY=VALUE pairs added to the hook's environment.
+	Env []string
+	// Dir is the working directory. Empty means the agent's.
+	Dir string
+	// User is the user name or uid the hook runs as. Empty means the
+	// agent's user. Not supported on Windows.
+	User string
+	// Timeout overrides the Runner's timeout when non-zero.
+	Timeout time.Duration
+}
+
+// Runner executes hook steps.
 // The zero value is usable — create with NewRunner or use directly.
 type Runner struct {
 	// Timeout overrides DefaultTimeout when non-zero.
@@ -60,7 +82,7 @@ func NewRunner(timeout time.Duration) *Runner {
 	return &Runner{Timeout: timeout}
 }
 
-// Run executes the given shell command string and returns its result.
+// Run executes the shell command of step and returns its result.
 // The command is run inside a shell (sh or cmd) so pipes, redirects,
 // and shell builtins work as expected.
 //
@@ -70,16 +92,18 @@ func NewRunner(timeout time.Duration) *Runner {
 // A non-zero exit code returns ErrHookFailed wrapping the underlying
 // exec.ExitError — the Result is still populated so the caller can log
 // the output regardless.
-func (r *Runner) Run(ctx context.Context, command string, args []string, timeout time.Duration) (*Result, error) {
-	if command == "" {
+func (r *Runner) Run(ctx context.Context, step Step) (*Result, error) {
+	if step.Command == "" {
 		// No hook configured — treat as success with no output.
 		return &Result{}, nil
 	}
 
 	timeoutToUse := r.Timeout
-
-	if timeout > 0 {
-		timeoutToUse = timeout
+	if timeoutToUse == 0 {
+		timeoutToUse = DefaultTimeout
+	}
+	if step.Timeout > 0 {
+		timeoutToUse = step.Timeout
 	}
 
 	// Apply the command timeout (or the runner if it's zero) on top of any deadline already in ctx.
@@ -87,7 +111,14 @@ func (r *Runner) Run(ctx context.Context, command string, args []string, timeout
 	ctx, cancel := context.WithTimeout(ctx, timeoutToUse)
 	defer cancel()
 
-	cmd := buildShellCmd(ctx, command, args, timeout)
+	cmd := buildShellCmd(ctx, step.Command, step.Args)
+	cmd.Dir = step.Dir
+	cmd.Env = append(inheritedEnv(), step.Env...)
+	if step.User != "" {
+		if err := runAs(cmd, step.User); err != nil {
+			return &Result{ExitCode: -1}, fmt.Errorf("%w: %w", ErrHookFailed, err)
+		}
+	}
 
 	var buf bytes.Buffer
 	cmd.Stdout = &buf
@@ -97,7 +128,7 @@ func (r *Runner) Run(ctx context.Context, command string, args []string, timeout
 	err := cmd.Run()
 	duration := time.Since(start)
 
-	output := buf.String()
+	output := strings.TrimSpace(buf.String())
 
 	if err != nil {
 		exitCode := 1
@@ -145,7 +176,7 @@ func (r *Runner) Run(ctx context.Context, command string, args []string, timeout
 //
 // On Windows cmd /C is still used with a joined string (no equivalent
 // positional-parameter mechanism exists in cmd).
-func buildShellCmd(ctx context.Context, command string, args []string, timeout time.Duration) *exec.Cmd {
+func buildShellCmd(ctx context.Context, command string, args []string) *exec.Cmd {
 	if runtime.GOOS == "windows" {
 		shellCmd := command
 		if len(args) > 0 {
@@ -155,4 +186,20 @@ func buildShellCmd(ctx context.Context, command string, args []string, timeout t
 	}
 	cmdArgs := append([]string{"-c", command, "--"}, args...)
 	return exec.CommandContext(ctx, "/bin/sh", cmdArgs...)
-}
\ No newline at end of file
+}
+
+// inheritedEnv returns the agent's environment without the variables that
+// carry its credentials: the agent token and settings (ARKEEP_*), and any
+// restic or rclone configuration (RESTIC_*, RCLONE_*).
+func inheritedEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		upper := strings.ToUpper(name)
+		if strings.HasPrefix(upper, "ARKEEP_") || strings.HasPrefix(upper, "RESTIC_") || strings.HasPrefix(upper, "RCLONE_") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
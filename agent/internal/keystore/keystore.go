@@ -0,0 +1,376 @@
+// Package keystore keeps the repository keys of zero-knowledge policies on
+// the agent. The server of such a policy never sees the password of its
+// repositories: the agent generates one per policy destination on the first
+// backup and reports only its fingerprint.
+//
+// Everything lives in <state-dir>/keys:
+//   - sealing.key: a random AES-256 key, the per-agent key every repository
+//     key is sealed with at rest.
+//   - identity.key: an X25519 private key. Its public half is announced to
+//     the server at registration so that other agents can share repository
+//     keys with this one (see Share and Import).
+//   - keystore.json: the sealed repository keys, by key ID.
+//
+// Losing the directory loses the keys: an escrow kit (see EscrowKit) sealed
+// to an admin-held public key is the way back.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arkeep-io/arkeep/shared/keyseal"
+)
+
+// ErrNotFound is returned when the store holds no key with the given ID.
+var ErrNotFound = errors.New("keystore: key not found")
+
+// ErrFingerprintMismatch is returned when a key does not match the
+// fingerprint the server recorded for it.
+var ErrFingerprintMismatch = errors.New("keystore: key does not match the expected fingerprint")
+
+// keyBytes is the size of a generated repository key before hex encoding.
+const keyBytes = 32
+
+// file is the JSON layout of keystore.json.
+type file struct {
+	Keys map[string]string `json:"keys"` // key ID → base64(nonce || AES-GCM ciphertext)
+}
+
+// Store holds the repository keys of this agent. It is safe for concurrent
+// use.
+type Store struct {
+	dir      string
+	aead     cipher.AEAD
+	identity *ecdh.PrivateKey
+
+	mu   sync.Mutex
+	keys map[string]string // key ID → sealed key, as in keystore.json
+	// escrowedTo is the fingerprint of the escrow key the current keys were
+	// last exported to, empty when an export is due.
+	escrowedTo string
+}
+
+// Open loads the store from <stateDir>/keys, creating the directory, the
+// sealing key and the identity key on first use.
+func Open(stateDir string) (*Store, error) {
+	dir := filepath.Join(stateDir, "keys")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("keystore: failed to create key dir: %w", err)
+	}
+
+	sealingKey, err := loadOrCreate(filepath.Join(dir, "sealing.key"), func() ([]byte, error) {
+		key := make([]byte, 32)
+		_, err := rand.Read(key)
+		return key, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sealingKey)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid sealing key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %w", err)
+	}
+
+	identityKey, err := loadOrCreate(filepath.Join(dir, "identity.key"), func() ([]byte, error) {
+		priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return priv.Bytes(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	identity, err := ecdh.X25519().NewPrivateKey(identityKey)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid identity key: %w", err)
+	}
+
+	s := &Store{dir: dir, aead: aead, identity: identity, keys: map[string]string{}}
+	data, err := os.ReadFile(s.path())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("keystore: failed to read keys: %w", err)
+	}
+	if err == nil {
+		var f file
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("keystore: corrupted key file: %w", err)
+		}
+		if f.Keys != nil {
+			s.keys = f.Keys
+		}
+	}
+	return s, nil
+}
+
+// PublicKey returns the base64-encoded public half of the agent's identity
+// key, which other agents seal shared keys to.
+func (s *Store) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.identity.PublicKey().Bytes())
+}
+
+// Get returns the key with the given ID, or ErrNotFound.
+func (s *Store) Get(keyID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(keyID)
+}
+
+// GetOrCreate returns the key with the given ID, generating and storing a
+// new one when the store has none. fingerprint is the fingerprint the server
+// recorded for the key: a new key is only generated when it is empty, and an
+// existing key must match it.
+func (s *Store) GetOrCreate(keyID, fingerprint string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, err := s.get(keyID)
+	if err == nil {
+		if fingerprint != "" && keyseal.Fingerprint(key) != fingerprint {
+			return "", ErrFingerprintMismatch
+		}
+		return key, nil
+	}
+	if !errors.Is(err, ErrNotFound) || fingerprint != "" {
+		return "", err
+	}
+
+	raw := make([]byte, keyBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("keystore: failed to generate key: %w", err)
+	}
+	key = hex.EncodeToString(raw)
+	if err := s.put(keyID, key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Share seals the key with the given ID to another agent's public key, for
+// that agent to Import.
+func (s *Store) Share(keyID, recipientPublicKey string) ([]byte, error) {
+	key, err := s.Get(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return keyseal.Seal(recipientPublicKey, keyseal.PurposeKeyShare+"\x00"+keyID, []byte(key))
+}
+
+// Import opens a key another agent sealed to this agent's identity key,
+// checks it against fingerprint and stores it.
+func (s *Store) Import(keyID string, sealed []byte, fingerprint string) (string, error) {
+	plaintext, err := keyseal.Open(s.identity, keyseal.PurposeKeyShare+"\x00"+keyID, sealed)
+	if err != nil {
+		return "", err
+	}
+	key := string(plaintext)
+	if keyseal.Fingerprint(key) != fingerprint {
+		return "", ErrFingerprintMismatch
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.put(keyID, key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// EscrowKit is the plaintext of an escrow kit: every key of the agent.
+type EscrowKit struct {
+	Hostname  string      `json:"hostname"`
+	CreatedAt time.Time   `json:"created_at"`
+	Keys      []EscrowKey `json:"keys"`
+}
+
+// EscrowKey is one repository key in an escrow kit. The key ID is
+// "<policy ID>/<destination ID>".
+type EscrowKey struct {
+	KeyID       string `json:"key_id"`
+	Fingerprint string `json:"fingerprint"`
+	Password    string `json:"password"`
+}
+
+// EscrowDue reports whether the keys changed since they were last exported
+// to escrowPublicKey, or were never exported to it.
+func (s *Store) EscrowDue(escrowPublicKey string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.keys) > 0 && s.escrowedTo != keyseal.Fingerprint(escrowPublicKey)
+}
+
+// EscrowKit seals every key of the store to escrowPublicKey. Returns the
+// sealed kit and the number of keys in it. The admin opens it with the
+// private half of the escrow key, e.g. with arkeep-agent escrow open.
+func (s *Store) EscrowKit(escrowPublicKey string) ([]byte, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hostname, _ := os.Hostname()
+	kit := EscrowKit{Hostname: hostname, CreatedAt: time.Now().UTC(), Keys: []EscrowKey{}}
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		key, err := s.get(id)
+		if err != nil {
+			return nil, 0, err
+		}
+		kit.Keys = append(kit.Keys, EscrowKey{KeyID: id, Fingerprint: keyseal.Fingerprint(key), Password: key})
+	}
+
+	data, err := json.Marshal(kit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("keystore: failed to encode escrow kit: %w", err)
+	}
+	sealed, err := keyseal.Seal(escrowPublicKey, keyseal.PurposeEscrowKit, data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return sealed, len(kit.Keys), nil
+}
+
+// MarkEscrowed records that the current keys were exported to
+// escrowPublicKey.
+func (s *Store) MarkEscrowed(escrowPublicKey string) {
+	s.mu.Lock()
+	s.escrowedTo = keyseal.Fingerprint(escrowPublicKey)
+	s.mu.Unlock()
+}
+
+// OpenEscrowKit opens a sealed escrow kit with the base64-encoded private
+// half of the escrow key.
+func OpenEscrowKit(privateKey string, sealed []byte) (*EscrowKit, error) {
+	priv, err := keyseal.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := keyseal.Open(priv, keyseal.PurposeEscrowKit, sealed)
+	if err != nil {
+		return nil, err
+	}
+	var kit EscrowKit
+	if err := json.Unmarshal(data, &kit); err != nil {
+		return nil, fmt.Errorf("keystore: corrupted escrow kit: %w", err)
+	}
+	return &kit, nil
+}
+
+// get unseals a key. The caller holds s.mu.
+func (s *Store) get(keyID string) (string, error) {
+	sealed, ok := s.keys[keyID]
+	if !ok {
+		return "", ErrNotFound
+	}
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < s.aead.NonceSize() {
+		return "", fmt.Errorf("keystore: corrupted key %s", keyID)
+	}
+	nonce := raw[:s.aead.NonceSize()]
+	key, err := s.aead.Open(nil, nonce, raw[s.aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("keystore: key %s cannot be unsealed: %w", keyID, err)
+	}
+	return string(key), nil
+}
+
+// put seals and stores a key, then persists the store. The caller holds
+// s.mu.
+func (s *Store) put(keyID, key string) error {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("keystore: failed to generate nonce: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(key), []byte(keyID))
+
+	keys := make(map[string]string, len(s.keys)+1)
+	for id, v := range s.keys {
+		keys[id] = v
+	}
+	keys[keyID] = base64.StdEncoding.EncodeToString(sealed)
+	data, err := json.Marshal(file{Keys: keys})
+	if err != nil {
+		return fmt.Errorf("keystore: failed to encode keys: %w", err)
+	}
+	if err := writeFile(s.path(), data); err != nil {
+		return err
+	}
+	s.keys = keys
+	s.escrowedTo = ""
+	return nil
+}
+
+func (s *Store) path() string {
+	return filepath.Join(s.dir, "keystore.json")
+}
+
+// loadOrCreate reads the key file at path, or writes the key returned by
+// generate to it when it does not exist.
+func loadOrCreate(path string, generate func() ([]byte, error)) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return data, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("keystore: failed to read %s: %w", filepath.Base(path), err)
+	}
+	data, err = generate()
+	if err != nil {
+		return nil, fmt.Errorf("keystore: failed to generate %s: %w", filepath.Base(path), err)
+	}
+	if err := writeFile(path, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// writeFile writes data to path atomically via temp file + rename, readable
+// by the agent's user only.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("keystore: failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	ok := false
+	defer func() {
+		if !ok {
+			_ = os.Remove(tmpPath) // best-effort cleanup on failure
+		}
+	}()
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("keystore: failed to restrict %s: %w", filepath.Base(path), err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("keystore: failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("keystore: failed to close %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("keystore: failed to rename %s: %w", filepath.Base(path), err)
+	}
+	ok = true
+	return nil
+}
@@ -0,0 +1,124 @@
+package keystore
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/arkeep-io/arkeep/shared/keyseal"
+)
+
+func TestGetOrCreate(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	key, err := s.GetOrCreate("policy/dest", "")
+	if err != nil {
+		t.Fatalf("GetOrCreate: %v", err)
+	}
+	if len(key) != 2*keyBytes {
+		t.Errorf("key length = %d, want %d", len(key), 2*keyBytes)
+	}
+
+	// The key survives a restart and matches its fingerprint.
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	again, err := reopened.GetOrCreate("policy/dest", keyseal.Fingerprint(key))
+	if err != nil || again != key {
+		t.Errorf("GetOrCreate after reopen = %q, %v; want the same key", again, err)
+	}
+	if reopened.PublicKey() != s.PublicKey() {
+		t.Error("identity key changed after reopen")
+	}
+
+	if _, err := reopened.GetOrCreate("policy/dest", "other"); !errors.Is(err, ErrFingerprintMismatch) {
+		t.Errorf("GetOrCreate with wrong fingerprint: err = %v, want ErrFingerprintMismatch", err)
+	}
+	// A key the server knows about is never regenerated.
+	if _, err := reopened.GetOrCreate("policy/lost", "abc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetOrCreate of a missing known key: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestShareAndImport(t *testing.T) {
+	from, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	to, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	key, err := from.GetOrCreate("policy/dest", "")
+	if err != nil {
+		t.Fatalf("GetOrCreate: %v", err)
+	}
+
+	sealed, err := from.Share("policy/dest", to.PublicKey())
+	if err != nil {
+		t.Fatalf("Share: %v", err)
+	}
+	if _, err := from.Import("policy/dest", sealed, keyseal.Fingerprint(key)); err == nil {
+		t.Error("Import by an agent the key was not shared with succeeded")
+	}
+	if _, err := to.Import("policy/other", sealed, keyseal.Fingerprint(key)); err == nil {
+		t.Error("Import under another key ID succeeded")
+	}
+	got, err := to.Import("policy/dest", sealed, keyseal.Fingerprint(key))
+	if err != nil || got != key {
+		t.Fatalf("Import = %q, %v; want the shared key", got, err)
+	}
+	if stored, err := to.Get("policy/dest"); err != nil || stored != key {
+		t.Errorf("Get after Import = %q, %v; want the shared key", stored, err)
+	}
+}
+
+func TestEscrowKit(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	priv, pub, err := keyseal.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	if s.EscrowDue(pub) {
+		t.Error("EscrowDue = true for an empty store")
+	}
+	key, err := s.GetOrCreate("policy/dest", "")
+	if err != nil {
+		t.Fatalf("GetOrCreate: %v", err)
+	}
+	if !s.EscrowDue(pub) {
+		t.Error("EscrowDue = false after a key was created")
+	}
+
+	sealed, n, err := s.EscrowKit(pub)
+	if err != nil || n != 1 {
+		t.Fatalf("EscrowKit = %d keys, %v; want 1", n, err)
+	}
+	s.MarkEscrowed(pub)
+	if s.EscrowDue(pub) {
+		t.Error("EscrowDue = true right after the kit was exported")
+	}
+
+	kit, err := OpenEscrowKit(priv, sealed)
+	if err != nil {
+		t.Fatalf("OpenEscrowKit: %v", err)
+	}
+	if len(kit.Keys) != 1 || kit.Keys[0].KeyID != "policy/dest" || kit.Keys[0].Password != key || kit.Keys[0].Fingerprint != keyseal.Fingerprint(key) {
+		t.Errorf("kit keys = %+v, want the created key", kit.Keys)
+	}
+
+	_, otherPub, err := keyseal.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	if !s.EscrowDue(otherPub) {
+		t.Error("EscrowDue = false for a new escrow key")
+	}
+}
@@ -0,0 +1,62 @@
+// errors.go classifies restic failures so that the server can decide whether
+// a failed backup is worth retrying.
+package restic
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// exitCodeWrongPassword is returned by restic >= 0.17 when the repository
+// cannot be opened with the given password.
+const exitCodeWrongPassword = 12
+
+// networkErrorMarkers are substrings of restic/Go error messages that
+// indicate the repository backend was unreachable rather than broken.
+var networkErrorMarkers = []string{
+	"connection refused",
+	"connection reset",
+	"no such host",
+	"i/o timeout",
+	"network is unreachable",
+	"no route to host",
+	"tls handshake timeout",
+	"temporary failure in name resolution",
+	"server misbehaving",
+	"broken pipe",
+	"unexpected eof",
+	"503 service unavailable",
+	"502 bad gateway",
+	"504 gateway timeout",
+}
+
+// IsWrongPassword reports whether err was caused by restic failing to open
+// the repository with the configured password.
+func IsWrongPassword(err error) bool {
+	if err == nil {
+		return false
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == exitCodeWrongPassword {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "wrong password")
+}
+
+// IsNetworkError reports whether err looks like a transient failure to reach
+// the repository backend. The check is heuristic: restic does not expose a
+// dedicated exit code for network errors, so the captured stderr is matched
+// against well-known messages.
+func IsNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range networkErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package restic
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsWrongPassword(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("restic: command failed: exit status 1\nFatal: wrong password or no key found"), true},
+		{errors.New("restic: command failed: exit status 1\nFatal: unable to open config file"), false},
+		{nil, false},
+	}
+	for _, c := range cases {
+		if got := IsWrongPassword(c.err); got != c.want {
+			t.Errorf("IsWrongPassword(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestIsNetworkError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("Fatal: unable to open config file: Stat: Get \"https://minio:9000/bucket/config\": dial tcp 10.0.0.5:9000: connect: connection refused"), true},
+		{errors.New("dial tcp: lookup minio on 127.0.0.11:53: no such host"), true},
+		{errors.New("Fatal: wrong password or no key found"), false},
+		{nil, false},
+	}
+	for _, c := range cases {
+		if got := IsNetworkError(c.err); got != c.want {
+			t.Errorf("IsNetworkError(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
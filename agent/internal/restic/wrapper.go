@@ -18,8 +18,12 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os/exec"
+	"path"
+	"sort"
 	"strings"
 )
 
@@ -60,6 +64,15 @@ type BackupOptions struct {
 	Tags     []string
 	// ExcludePatterns are passed to restic as --exclude flags.
 	ExcludePatterns []string
+	// Stdin, when set, is backed up in place of Sources: restic reads it to
+	// EOF and stores it as a single file named StdinFilename.
+	Stdin         io.Reader
+	StdinFilename string
+	// StdinCommand, when set, is backed up in place of Sources: restic runs
+	// the program and arguments itself and stores their stdout as a single
+	// file named StdinFilename. A non-zero exit status fails the backup
+	// without creating a snapshot.
+	StdinCommand []string
 }
 
 // SnapshotInfo holds the metadata of a single snapshot returned by restic.
@@ -72,16 +85,46 @@ type SnapshotInfo struct {
 	Username string   `json:"username"`
 	// ShortID is the 8-character abbreviated snapshot ID.
 	ShortID  string   `json:"short_id"`
+	// Original is the ID of the snapshot this one was copied or rewritten
+	// from. Empty for snapshots created by restic backup.
+	Original string   `json:"original"`
+	// Summary holds the backup statistics restic records in the snapshot
+	// since 0.17. nil for older snapshots.
+	Summary *SnapshotSummary `json:"summary"`
 }
 
-// RetentionPolicy mirrors the keep_* fields from db.Policy.
+// SnapshotSummary is the part of a snapshot's backup summary Arkeep uses.
+type SnapshotSummary struct {
+	TotalFilesProcessed int64 `json:"total_files_processed"`
+	TotalBytesProcessed int64 `json:"total_bytes_processed"`
+}
+
+// RetentionPolicy mirrors the keep_* fields from db.Policy, or from a
+// schedule entry with its own retention.
 type RetentionPolicy struct {
+	Hourly  int
 	Daily   int
 	Weekly  int
 	Monthly int
 	Yearly  int
+	// Tags limits forget to the snapshots carrying all of these tags.
+	Tags []string
+	// KeepTags lists comma-separated tag sets whose snapshots are always
+	// kept, because a different retention policy owns them.
+	KeepTags []string
+}
+
+// empty reports whether p keeps no snapshots at all. restic refuses to forget
+// with an empty policy.
+func (p RetentionPolicy) empty() bool {
+	return p.Hourly <= 0 && p.Daily <= 0 && p.Weekly <= 0 && p.Monthly <= 0 && p.Yearly <= 0 && len(p.KeepTags) == 0
 }
 
+// HoldTag marks a snapshot that retention must never remove. The server asks
+// for it on the last known-good snapshot when a backup run looks anomalous;
+// it is removed by hand (restic tag --remove arkeep:hold) once cleared.
+const HoldTag = "arkeep:hold"
+
 // ProgressEvent represents a single JSON event emitted by restic --json.
 // Only the fields relevant to progress reporting are decoded; the rest are
 // ignored. The raw JSON line is also preserved so callers can forward it
@@ -96,6 +139,11 @@ type ProgressEvent struct {
 	BytesDone    uint64  `json:"bytes_done"`
 	TotalFiles   uint64  `json:"total_files"`
 	TotalBytes   uint64  `json:"total_bytes"`
+	// Status-only fields: restic's ETA, the elapsed time and the files it
+	// is reading right now (empty between files and while scanning).
+	SecondsElapsed   uint64   `json:"seconds_elapsed"`
+	SecondsRemaining uint64   `json:"seconds_remaining"`
+	CurrentFiles     []string `json:"current_files"`
 
 	// Summary-only fields — only present when MessageType == "summary".
 	// SnapshotID is the full SHA256 ID of the snapshot created by this backup run.
@@ -104,6 +152,19 @@ type ProgressEvent struct {
 	TotalBytesProcessed uint64 `json:"total_bytes_processed"`
 	// DataAdded is the number of new bytes added to the repository (deduplicated).
 	DataAdded           uint64 `json:"data_added"`
+	// FilesChanged and FilesUnmodified complete FilesNew in the summary;
+	// TotalFilesProcessed is their sum.
+	FilesChanged        uint64 `json:"files_changed"`
+	FilesUnmodified     uint64 `json:"files_unmodified"`
+	TotalFilesProcessed uint64 `json:"total_files_processed"`
+
+	// Error-only fields — the file restic could not back up and why. restic
+	// writes these events to stderr; they are forwarded once it exits.
+	Item   string `json:"item"`
+	During string `json:"during"`
+	Error  struct {
+		Message string `json:"message"`
+	} `json:"error"`
 
 	// Raw is the original JSON line, forwarded as-is to the log stream.
 	Raw string `json:"-"`
@@ -120,8 +181,84 @@ type BackupResult struct {
 	TotalBytesProcessed uint64
 	// DataAdded is the net bytes added to the repository after deduplication.
 	DataAdded uint64
+	// Change statistics: how many of the TotalFiles processed files were
+	// new, changed or unmodified since the parent snapshot.
+	FilesNew        uint64
+	FilesChanged    uint64
+	FilesUnmodified uint64
+	TotalFiles      uint64
+	// Incomplete is set when restic created the snapshot but could not read
+	// some source files (exit code 3). FileErrors lists the errors, at most
+	// maxFileErrors entries; FileErrorCount is the full count.
+	Incomplete     bool
+	FileErrors     []FileError
+	FileErrorCount uint64
+	// StdinSnapshots lists the snapshots of streams backed up on stdin as
+	// part of the same logical backup, e.g. database dumps taken alongside
+	// the file sources. The wrapper never sets it: callers combining several
+	// runs into one result do.
+	StdinSnapshots []StdinSnapshot
 }
 
+// StdinSnapshot is a snapshot of a stream backed up on stdin.
+type StdinSnapshot struct {
+	SnapshotID string
+	Filename   string
+	Tags       []string
+	SizeBytes  uint64
+}
+
+// FileError is a source file restic reported an error for during a backup.
+type FileError struct {
+	Path    string
+	During  string
+	Message string
+}
+
+// DryRunResult holds the outcome of a backup dry run: what a backup of the
+// sources would pick up.
+type DryRunResult struct {
+	// TotalFiles and TotalBytes count the files the backup would read.
+	TotalFiles uint64
+	TotalBytes uint64
+	// DataAdded is the size the backup would add to the repository.
+	DataAdded uint64
+	// LargestDirs lists the directories holding the most data, largest
+	// first, at most dryRunTopDirs entries.
+	LargestDirs []DirectorySize
+	// Unreadable lists paths restic could not read, at most
+	// dryRunMaxUnreadable entries; UnreadableCount is the full count.
+	Unreadable      []UnreadablePath
+	UnreadableCount uint64
+}
+
+// DirectorySize is the size of the files directly inside a directory.
+// Subdirectories are counted separately, so a directory full of small
+// subdirectories does not hide the ones that actually hold the data.
+type DirectorySize struct {
+	Path  string
+	Bytes uint64
+	Files uint64
+}
+
+// UnreadablePath is a path restic reported an error for during a dry run.
+type UnreadablePath struct {
+	Path  string
+	Error string
+}
+
+const (
+	// dryRunTopDirs bounds DryRunResult.LargestDirs.
+	dryRunTopDirs = 10
+	// dryRunMaxUnreadable bounds DryRunResult.Unreadable.
+	dryRunMaxUnreadable = 100
+	// maxFileErrors bounds BackupResult.FileErrors.
+	maxFileErrors = 100
+	// exitCodeIncomplete is restic's exit code for a backup that could not
+	// read some source files.
+	exitCodeIncomplete = 3
+)
+
 // ProgressFunc is called for each progress event emitted during a long-running
 // operation. Returning an error from ProgressFunc cancels the operation.
 // It is always called from the same goroutine that reads restic's stdout, so
@@ -170,7 +307,9 @@ func (w *Wrapper) Init(ctx context.Context, dest Destination) error {
 //
 // Returns a BackupResult with snapshot metadata extracted from the restic
 // summary event, and an error if the backup fails. A non-zero restic exit
-// code is always wrapped in the returned error with stderr included.
+// code is wrapped in the returned error with stderr included, except exit
+// code 3 after a snapshot was created: the result is then Incomplete and
+// lists the files restic could not read.
 func (w *Wrapper) Backup(ctx context.Context, dest Destination, opts BackupOptions, onProgress ProgressFunc) (*BackupResult, error) {
 	if err := w.Init(ctx, dest); err != nil {
 		return nil, fmt.Errorf("restic: failed to init repository: %w", err)
@@ -184,18 +323,36 @@ func (w *Wrapper) Backup(ctx context.Context, dest Destination, opts BackupOptio
 	for _, ex := range opts.ExcludePatterns {
 		args = append(args, "--exclude", ex)
 	}
-	args = append(args, opts.Sources...)
+	if len(opts.StdinCommand) > 0 {
+		args = append(args, "--stdin-filename", opts.StdinFilename, "--stdin-from-command", "--")
+		args = append(args, opts.StdinCommand...)
+	} else if opts.Stdin != nil {
+		args = append(args, "--stdin", "--stdin-filename", opts.StdinFilename)
+	} else {
+		args = append(args, opts.Sources...)
+	}
 
 	var result BackupResult
 
 	// Wrap the caller's onProgress to intercept the summary event and extract
 	// snapshot metadata. The summary event is the last event emitted by restic
 	// on successful completion — it carries snapshot_id and byte counts.
+	// Error events are collected as per-file errors.
 	intercepted := func(ev ProgressEvent) error {
-		if ev.MessageType == "summary" {
+		switch ev.MessageType {
+		case "error":
+			result.FileErrorCount++
+			if len(result.FileErrors) < maxFileErrors {
+				result.FileErrors = append(result.FileErrors, FileError{Path: ev.Item, During: ev.During, Message: ev.Error.Message})
+			}
+		case "summary":
 			result.SnapshotID = ev.SnapshotID
 			result.TotalBytesProcessed = ev.TotalBytesProcessed
 			result.DataAdded = ev.DataAdded
+			result.FilesNew = ev.FilesNew
+			result.FilesChanged = ev.FilesChanged
+			result.FilesUnmodified = ev.FilesUnmodified
+			result.TotalFiles = ev.TotalFilesProcessed
 		}
 		if onProgress != nil {
 			return onProgress(ev)
@@ -203,23 +360,253 @@ func (w *Wrapper) Backup(ctx context.Context, dest Destination, opts BackupOptio
 		return nil
 	}
 
-	if err := w.runWithProgress(ctx, dest, args, intercepted); err != nil {
+	// Exit code 3 means the snapshot was created without some source files:
+	// a result with warnings, not a failure.
+	cmd := w.buildCmd(ctx, dest, args)
+	cmd.Stdin = opts.Stdin
+	err := w.runCmdWithProgress(cmd, intercepted)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == exitCodeIncomplete && result.SnapshotID != "" {
+		result.Incomplete = true
+		err = nil
+	}
+	if err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
+// DryRun runs restic backup --dry-run for the given destination and sources:
+// the sources are scanned and chunked, but nothing is written. Status events
+// are forwarded to onProgress, which may be nil.
+//
+// Unreadable source files make restic exit with code 3; for a dry run that is
+// part of the result, not an error.
+func (w *Wrapper) DryRun(ctx context.Context, dest Destination, opts BackupOptions, onProgress ProgressFunc) (*DryRunResult, error) {
+	if err := w.Init(ctx, dest); err != nil {
+		return nil, fmt.Errorf("restic: failed to init repository: %w", err)
+	}
+
+	// --verbose=2 makes restic report every file as a verbose_status event,
+	// which is where the per-directory sizes come from.
+	// --no-cache keeps one-off scratch repositories from leaving cache
+	// directories behind.
+	args := []string{"backup", "--dry-run", "--json", "--verbose=2", "--no-cache"}
+	for _, tag := range opts.Tags {
+		args = append(args, "--tag", tag)
+	}
+	for _, ex := range opts.ExcludePatterns {
+		args = append(args, "--exclude", ex)
+	}
+	args = append(args, opts.Sources...)
+
+	cmd := w.buildCmd(ctx, dest, args)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("restic: failed to open stdout pipe: %w", err)
+	}
+	var stderrBuf strings.Builder
+	cmd.Stderr = &stderrBuf
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("restic: failed to start: %w", err)
+	}
+
+	scan := newDryRunScan()
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // long paths in verbose_status
+	for scanner.Scan() {
+		ev, ok := scan.stdoutLine(scanner.Text())
+		if ok && onProgress != nil {
+			if err := onProgress(ev); err != nil {
+				_ = cmd.Process.Kill()
+				return nil, fmt.Errorf("restic: progress callback cancelled: %w", err)
+			}
+		}
+	}
+
+	waitErr := cmd.Wait()
+	stderr := strings.TrimSpace(stderrBuf.String())
+	for _, line := range strings.Split(stderr, "\n") {
+		scan.stderrLine(line)
+	}
+	var exitErr *exec.ExitError
+	if waitErr != nil && !(errors.As(waitErr, &exitErr) && exitErr.ExitCode() == exitCodeIncomplete) {
+		return nil, fmt.Errorf("restic: command failed: %w\n%s", waitErr, stderr)
+	}
+	return scan.result(), nil
+}
+
+// dryRunScan accumulates the JSON events of restic backup --dry-run.
+type dryRunScan struct {
+	res  DryRunResult
+	dirs map[string]*DirectorySize
+}
+
+func newDryRunScan() *dryRunScan {
+	return &dryRunScan{dirs: make(map[string]*DirectorySize)}
+}
+
+// dryRunEvent holds the fields of the backup events a dry run needs beyond
+// ProgressEvent.
+type dryRunEvent struct {
+	MessageType string `json:"message_type"`
+	// verbose_status
+	Action   string `json:"action"`
+	Item     string `json:"item"`
+	DataSize uint64 `json:"data_size"`
+	// summary
+	TotalFilesProcessed uint64 `json:"total_files_processed"`
+	TotalBytesProcessed uint64 `json:"total_bytes_processed"`
+	DataAdded           uint64 `json:"data_added"`
+	// error
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// stdoutLine records one stdout line. It returns the line as a ProgressEvent
+// when it is a status event.
+func (d *dryRunScan) stdoutLine(line string) (ProgressEvent, bool) {
+	var ev dryRunEvent
+	if line == "" || json.Unmarshal([]byte(line), &ev) != nil {
+		return ProgressEvent{}, false
+	}
+	switch ev.MessageType {
+	case "verbose_status":
+		// Directories are reported with a trailing slash and no data of
+		// their own; only files are attributed to their parent.
+		if ev.Action == "scan_finished" || ev.Item == "" || strings.HasSuffix(ev.Item, "/") {
+			break
+		}
+		dir := path.Dir(ev.Item)
+		ds, ok := d.dirs[dir]
+		if !ok {
+			ds = &DirectorySize{Path: dir}
+			d.dirs[dir] = ds
+		}
+		ds.Bytes += ev.DataSize
+		ds.Files++
+	case "summary":
+		d.res.TotalFiles = ev.TotalFilesProcessed
+		d.res.TotalBytes = ev.TotalBytesProcessed
+		d.res.DataAdded = ev.DataAdded
+	case "error":
+		d.addError(ev)
+	case "status":
+		var pe ProgressEvent
+		_ = json.Unmarshal([]byte(line), &pe)
+		pe.Raw = line
+		return pe, true
+	}
+	return ProgressEvent{}, false
+}
+
+// stderrLine records one stderr line: restic --json reports per-file errors
+// there.
+func (d *dryRunScan) stderrLine(line string) {
+	var ev dryRunEvent
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &ev); err != nil || ev.MessageType != "error" {
+		return
+	}
+	d.addError(ev)
+}
+
+func (d *dryRunScan) addError(ev dryRunEvent) {
+	d.res.UnreadableCount++
+	if len(d.res.Unreadable) < dryRunMaxUnreadable {
+		d.res.Unreadable = append(d.res.Unreadable, UnreadablePath{Path: ev.Item, Error: ev.Error.Message})
+	}
+}
+
+// result returns the accumulated result with the largest directories.
+func (d *dryRunScan) result() *DryRunResult {
+	dirs := make([]DirectorySize, 0, len(d.dirs))
+	for _, ds := range d.dirs {
+		dirs = append(dirs, *ds)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if dirs[i].Bytes != dirs[j].Bytes {
+			return dirs[i].Bytes > dirs[j].Bytes
+		}
+		return dirs[i].Path < dirs[j].Path
+	})
+	if len(dirs) > dryRunTopDirs {
+		dirs = dirs[:dryRunTopDirs]
+	}
+	res := d.res
+	res.LargestDirs = dirs
+	return &res
+}
+
 // Forget runs restic forget --prune to apply the retention policy.
-// It removes snapshot metadata and frees storage in a single pass.
+// It removes snapshot metadata and frees storage in a single pass. Snapshots
+// tagged HoldTag are always kept.
 func (w *Wrapper) Forget(ctx context.Context, dest Destination, policy RetentionPolicy) error {
-	args := []string{
-		"forget", "--prune", "--json",
+	return w.run(ctx, dest, forgetArgs(policy))
+}
+
+// forgetArgs builds the restic forget arguments for policy. Snapshots are
+// grouped by tags as well when some tag sets are kept, so that the kept
+// snapshots do not count towards the keep-* limits of the others.
+func forgetArgs(policy RetentionPolicy) []string {
+	args := []string{"forget", "--prune", "--json"}
+	if policy.Hourly > 0 {
+		args = append(args, "--keep-hourly", fmt.Sprintf("%d", policy.Hourly))
+	}
+	args = append(args,
 		"--keep-daily", fmt.Sprintf("%d", policy.Daily),
 		"--keep-weekly", fmt.Sprintf("%d", policy.Weekly),
 		"--keep-monthly", fmt.Sprintf("%d", policy.Monthly),
 		"--keep-yearly", fmt.Sprintf("%d", policy.Yearly),
+	)
+	if len(policy.Tags) > 0 {
+		args = append(args, "--tag", strings.Join(policy.Tags, ","))
 	}
-	return w.run(ctx, dest, args)
+	for _, tags := range policy.KeepTags {
+		args = append(args, "--keep-tag", tags)
+	}
+	// Alone, the hold tag would be a policy of its own that forgets every
+	// snapshot not held. An empty policy is left for restic to reject.
+	if !policy.empty() {
+		args = append(args, "--keep-tag", HoldTag)
+	}
+	if len(policy.KeepTags) > 0 {
+		args = append(args, "--group-by", "host,paths,tags")
+	}
+	return args
+}
+
+// ForgetSnapshot removes a single snapshot, e.g. one taken of an incomplete
+// stream. Its data is pruned by the next retention run.
+func (w *Wrapper) ForgetSnapshot(ctx context.Context, dest Destination, snapshotID string) error {
+	return w.run(ctx, dest, []string{"forget", snapshotID})
+}
+
+// Tag adds tag to a snapshot and returns the ID of the rewritten snapshot:
+// restic replaces a snapshot when its tags change. Returns "" when the
+// snapshot already carried the tag.
+func (w *Wrapper) Tag(ctx context.Context, dest Destination, snapshotID, tag string) (string, error) {
+	out, err := w.output(ctx, dest, []string{"tag", "--json", "--add", tag, snapshotID})
+	if err != nil {
+		return "", err
+	}
+	return parseTagOutput(out), nil
+}
+
+// parseTagOutput extracts the new snapshot ID from the JSON lines printed by
+// restic tag --json.
+func parseTagOutput(out []byte) string {
+	for _, line := range strings.Split(string(out), "\n") {
+		var ev struct {
+			MessageType   string `json:"message_type"`
+			NewSnapshotID string `json:"new_snapshot_id"`
+		}
+		if json.Unmarshal([]byte(line), &ev) == nil && ev.MessageType == "changed" {
+			return ev.NewSnapshotID
+		}
+	}
+	return ""
 }
 
 // Check verifies the integrity of the repository. Progress events (one per
@@ -231,7 +618,13 @@ func (w *Wrapper) Check(ctx context.Context, dest Destination, onProgress Progre
 
 // Snapshots returns the list of snapshots stored in the repository.
 func (w *Wrapper) Snapshots(ctx context.Context, dest Destination) ([]SnapshotInfo, error) {
-	args := []string{"snapshots", "--json", "--no-lock"}
+	return w.snapshots(ctx, dest, nil)
+}
+
+// snapshots returns the snapshots of the repository that match the restic
+// filter flags in filterArgs.
+func (w *Wrapper) snapshots(ctx context.Context, dest Destination, filterArgs []string) ([]SnapshotInfo, error) {
+	args := append([]string{"snapshots", "--json", "--no-lock"}, filterArgs...)
 
 	out, err := w.output(ctx, dest, args)
 	if err != nil {
@@ -245,6 +638,127 @@ func (w *Wrapper) Snapshots(ctx context.Context, dest Destination) ([]SnapshotIn
 	return snapshots, nil
 }
 
+// SnapshotFilter selects snapshots by tag and host, like restic's
+// --tag and --host flags. The zero value selects every snapshot.
+type SnapshotFilter struct {
+	// Tags must all be present on a snapshot.
+	Tags []string
+	Host string
+}
+
+// args returns the restic flags of the filter.
+func (f SnapshotFilter) args() []string {
+	var args []string
+	if len(f.Tags) > 0 {
+		args = append(args, "--tag", strings.Join(f.Tags, ","))
+	}
+	if f.Host != "" {
+		args = append(args, "--host", f.Host)
+	}
+	return args
+}
+
+// SnapshotCopy is a snapshot restic copy wrote to a repository.
+type SnapshotCopy struct {
+	// SnapshotInfo describes the copy.
+	SnapshotInfo
+	// OriginalID is the ID of the copied snapshot in the source repository.
+	OriginalID string
+}
+
+// Copy copies a snapshot from the repository at from to the repository at
+// dest and returns the ID of the copy. Copying a snapshot that dest already
+// holds a copy of is a no-op that returns the existing copy. See
+// CopySnapshots for the initialisation of dest and the environment.
+func (w *Wrapper) Copy(ctx context.Context, from, dest Destination, snapshotID string) (string, error) {
+	if err := w.InitFrom(ctx, from, dest); err != nil {
+		return "", err
+	}
+	if err := w.runCopy(ctx, from, dest, []string{"copy", snapshotID}); err != nil {
+		return "", err
+	}
+
+	snapshots, err := w.Snapshots(ctx, dest)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range snapshots {
+		if s.Original == snapshotID || s.ID == snapshotID {
+			return s.ID, nil
+		}
+	}
+	return "", fmt.Errorf("restic: copy of snapshot %s not found in destination", snapshotID)
+}
+
+// CopySnapshots copies the snapshots of the repository at from that match
+// filter to the repository at dest, and returns the copies dest holds of
+// them, including those copied by earlier runs. dest is initialised with
+// the chunker parameters of from when it does not exist yet, so that both
+// repositories deduplicate the same content alike.
+//
+// restic reads both backends from the same environment: dest.Env takes
+// precedence over from.Env when they set the same variable.
+func (w *Wrapper) CopySnapshots(ctx context.Context, from, dest Destination, filter SnapshotFilter) ([]SnapshotCopy, error) {
+	originals, err := w.snapshots(ctx, from, filter.args())
+	if err != nil {
+		return nil, err
+	}
+	if err := w.InitFrom(ctx, from, dest); err != nil {
+		return nil, err
+	}
+	if err := w.runCopy(ctx, from, dest, append([]string{"copy"}, filter.args()...)); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := w.Snapshots(ctx, dest)
+	if err != nil {
+		return nil, err
+	}
+	return matchCopies(originals, snapshots), nil
+}
+
+// matchCopies returns the snapshots that are copies of one of originals.
+// restic records the snapshot a copy was made from in its "original" field,
+// and carries that field over when a copy is copied again.
+func matchCopies(originals, snapshots []SnapshotInfo) []SnapshotCopy {
+	byOriginal := make(map[string]string, len(originals))
+	for _, o := range originals {
+		byOriginal[o.ID] = o.ID
+		if o.Original != "" {
+			byOriginal[o.Original] = o.ID
+		}
+	}
+	var copies []SnapshotCopy
+	for _, s := range snapshots {
+		if id, ok := byOriginal[s.Original]; ok && s.Original != "" {
+			copies = append(copies, SnapshotCopy{SnapshotInfo: s, OriginalID: id})
+		}
+	}
+	return copies
+}
+
+// InitFrom initialises the repository at dest with the chunker parameters
+// of the repository at from, so that snapshots copied between the two
+// deduplicate. Idempotent, like Init.
+func (w *Wrapper) InitFrom(ctx context.Context, from, dest Destination) error {
+	err := w.runCopy(ctx, from, dest, []string{"init", "--copy-chunker-params"})
+	if err != nil && !strings.Contains(err.Error(), "already") {
+		return fmt.Errorf("restic: failed to init repository: %w", err)
+	}
+	return nil
+}
+
+// runCopy executes a restic command that reads from a second repository,
+// like run.
+func (w *Wrapper) runCopy(ctx context.Context, from, dest Destination, args []string) error {
+	cmd := w.buildCopyCmd(ctx, from, dest, args)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("restic: command failed: %w\n%s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
 // Restore restores a snapshot (or a path within it) to targetDir.
 // snapshotID may be "latest" to restore the most recent snapshot.
 // includePath, if non-empty, limits restoration to a sub-path inside the snapshot.
@@ -266,6 +780,19 @@ func (w *Wrapper) Restore(ctx context.Context, dest Destination, snapshotID, tar
 	return w.runRestoreJSON(ctx, dest, args, hostRoot)
 }
 
+// Dump writes the file at path in a snapshot to out, e.g. to pipe a
+// database dump taken on stdin into its restore tool.
+func (w *Wrapper) Dump(ctx context.Context, dest Destination, snapshotID, path string, out io.Writer) error {
+	cmd := w.buildCmd(ctx, dest, []string{"dump", snapshotID, path})
+	cmd.Stdout = out
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("restic: command failed: %w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
 // runRestoreJSON runs restic restore --json, consuming stdout as a JSON event
 // stream (to prevent pipe stalls) and capturing stderr separately.
 // On exit error, only stderr is inspected for lchown failures — mixing JSON
@@ -402,14 +929,19 @@ func (w *Wrapper) output(ctx context.Context, dest Destination, args []string) (
 // runWithProgress executes a restic command, reading stdout line by line and
 // parsing each line as a JSON progress event. Each event is forwarded to
 // onProgress if non-nil. Stderr is collected and included in the error on
-// failure.
+// failure; the JSON error events in it are forwarded to onProgress once the
+// command exited.
 //
 // restic --json emits newline-delimited JSON objects on stdout. Each object
 // has a "message_type" field that identifies the event kind. Non-JSON lines
 // (e.g. deprecation warnings) are logged at debug level and skipped.
 func (w *Wrapper) runWithProgress(ctx context.Context, dest Destination, args []string, onProgress ProgressFunc) error {
-	cmd := w.buildCmd(ctx, dest, args)
+	return w.runCmdWithProgress(w.buildCmd(ctx, dest, args), onProgress)
+}
 
+// runCmdWithProgress is runWithProgress for a command the caller built, e.g.
+// to connect its stdin.
+func (w *Wrapper) runCmdWithProgress(cmd *exec.Cmd, onProgress ProgressFunc) error {
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("restic: failed to open stdout pipe: %w", err)
@@ -449,9 +981,24 @@ func (w *Wrapper) runWithProgress(ctx context.Context, dest Destination, args []
 		}
 	}
 
-	if err := cmd.Wait(); err != nil {
-		stderr := strings.TrimSpace(stderrBuf.String())
-		return fmt.Errorf("restic: command failed: %w\n%s", err, stderr)
+	waitErr := cmd.Wait()
+	stderr := strings.TrimSpace(stderrBuf.String())
+
+	// restic --json writes error events to stderr. Forward them after the
+	// fact; the process has exited, so the callback can no longer cancel it.
+	if onProgress != nil {
+		for _, line := range strings.Split(stderr, "\n") {
+			var event ProgressEvent
+			if json.Unmarshal([]byte(line), &event) != nil || event.MessageType != "error" {
+				continue
+			}
+			event.Raw = line
+			_ = onProgress(event)
+		}
+	}
+
+	if waitErr != nil {
+		return fmt.Errorf("restic: command failed: %w\n%s", waitErr, stderr)
 	}
 	return nil
 }
@@ -484,4 +1031,26 @@ func (w *Wrapper) buildCmd(ctx context.Context, dest Destination, args []string)
 
 	cmd.Env = env
 	return cmd
+}
+
+// buildCopyCmd constructs the exec.Cmd for a restic invocation that reads
+// from a second repository (copy, init --copy-chunker-params). from is
+// passed via RESTIC_FROM_REPOSITORY and RESTIC_FROM_PASSWORD; its backend
+// variables come first so that those of dest win.
+func (w *Wrapper) buildCopyCmd(ctx context.Context, from, dest Destination, args []string) *exec.Cmd {
+	cmd := w.buildCmd(ctx, dest, args)
+
+	env := []string{
+		"RESTIC_FROM_REPOSITORY=" + from.RepoURL,
+		"RESTIC_FROM_PASSWORD=" + from.Password,
+	}
+	if from.Type == DestRclone {
+		env = append(env, "RCLONE_BINARY="+w.rcloneBin)
+	}
+	for k, v := range from.Env {
+		env = append(env, k+"="+v)
+	}
+
+	cmd.Env = append(env, cmd.Env...)
+	return cmd
 }
\ No newline at end of file
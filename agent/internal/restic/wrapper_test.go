@@ -2,6 +2,9 @@ package restic
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -59,3 +62,242 @@ func TestBuildCmd_SFTPRepository(t *testing.T) {
 		t.Errorf("RESTIC_REPOSITORY=%q, want prefix 'sftp:'", repoURL)
 	}
 }
+
+func TestForgetArgs(t *testing.T) {
+	t.Run("whole repository", func(t *testing.T) {
+		got := strings.Join(forgetArgs(RetentionPolicy{Daily: 7, Weekly: 4}), " ")
+		want := "forget --prune --json --keep-daily 7 --keep-weekly 4 --keep-monthly 0 --keep-yearly 0 --keep-tag arkeep:hold"
+		if got != want {
+			t.Errorf("args = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("scoped to tags", func(t *testing.T) {
+		got := strings.Join(forgetArgs(RetentionPolicy{Hourly: 24, Tags: []string{"policy:p", "hourly"}}), " ")
+		if !strings.Contains(got, "--keep-hourly 24") || !strings.Contains(got, "--tag policy:p,hourly") {
+			t.Errorf("args = %q, want keep-hourly and tag filter", got)
+		}
+		if strings.Contains(got, "--group-by") {
+			t.Errorf("args = %q, want default grouping", got)
+		}
+	})
+
+	t.Run("empty retention keeps nothing held", func(t *testing.T) {
+		// restic rejects a forget without any keep-* option instead of
+		// forgetting every snapshot that is not held.
+		got := strings.Join(forgetArgs(RetentionPolicy{}), " ")
+		if strings.Contains(got, HoldTag) {
+			t.Errorf("args = %q, want no hold tag for an empty policy", got)
+		}
+	})
+
+	t.Run("keeps other tag sets", func(t *testing.T) {
+		got := strings.Join(forgetArgs(RetentionPolicy{Daily: 7, KeepTags: []string{"policy:p,hourly"}}), " ")
+		if !strings.Contains(got, "--keep-tag policy:p,hourly") || !strings.Contains(got, "--group-by host,paths,tags") {
+			t.Errorf("args = %q, want keep-tag and tag grouping", got)
+		}
+	})
+}
+
+func TestParseTagOutput(t *testing.T) {
+	out := []byte(`{"message_type":"changed","old_snapshot_id":"aaa","new_snapshot_id":"bbb"}
+{"message_type":"summary","changed_snapshots":1}
+`)
+	if got := parseTagOutput(out); got != "bbb" {
+		t.Errorf("parseTagOutput() = %q, want bbb", got)
+	}
+	if got := parseTagOutput([]byte(`{"message_type":"summary","changed_snapshots":0}`)); got != "" {
+		t.Errorf("parseTagOutput(unchanged) = %q, want empty", got)
+	}
+}
+
+func TestDryRunScan(t *testing.T) {
+	scan := newDryRunScan()
+	for _, line := range []string{
+		`{"message_type":"status","percent_done":0.5}`,
+		`{"message_type":"verbose_status","action":"new","item":"/data/media/","data_size":0}`,
+		`{"message_type":"verbose_status","action":"new","item":"/data/media/a.mp4","data_size":3000}`,
+		`{"message_type":"verbose_status","action":"new","item":"/data/media/b.mp4","data_size":2000}`,
+		`{"message_type":"verbose_status","action":"new","item":"/data/docs/c.txt","data_size":10}`,
+		`{"message_type":"verbose_status","action":"scan_finished"}`,
+		`{"message_type":"summary","total_files_processed":3,"total_bytes_processed":5010,"data_added":4000}`,
+		`not json`,
+	} {
+		ev, ok := scan.stdoutLine(line)
+		if ok != (ev.MessageType == "status") || (ok && ev.Raw != line) {
+			t.Errorf("stdoutLine(%s) = %+v, %v; want only status events forwarded", line, ev, ok)
+		}
+	}
+	scan.stderrLine(`{"message_type":"error","error":{"message":"open /data/secret: permission denied"},"during":"archival","item":"/data/secret"}`)
+	scan.stderrLine(`Fatal: unable to save snapshot: snapshot is incomplete`)
+
+	res := scan.result()
+	if res.TotalFiles != 3 || res.TotalBytes != 5010 || res.DataAdded != 4000 {
+		t.Errorf("totals = %d files, %d bytes, %d added; want 3, 5010, 4000", res.TotalFiles, res.TotalBytes, res.DataAdded)
+	}
+	want := []DirectorySize{{Path: "/data/media", Bytes: 5000, Files: 2}, {Path: "/data/docs", Bytes: 10, Files: 1}}
+	if len(res.LargestDirs) != len(want) || res.LargestDirs[0] != want[0] || res.LargestDirs[1] != want[1] {
+		t.Errorf("LargestDirs = %+v, want %+v", res.LargestDirs, want)
+	}
+	if res.UnreadableCount != 1 || len(res.Unreadable) != 1 || res.Unreadable[0].Path != "/data/secret" {
+		t.Errorf("Unreadable = %+v (count %d), want /data/secret", res.Unreadable, res.UnreadableCount)
+	}
+}
+
+// fakeRestic writes a shell script standing in for the restic binary.
+func fakeRestic(t *testing.T, script string) *Wrapper {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake restic binary is a shell script")
+	}
+	bin := filepath.Join(t.TempDir(), "restic")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatalf("write fake restic: %v", err)
+	}
+	return &Wrapper{resticBin: bin}
+}
+
+func TestBackupIncomplete(t *testing.T) {
+	w := fakeRestic(t, `[ "$1" = init ] && exit 0
+echo '{"message_type":"status","percent_done":0.5}'
+echo '{"message_type":"error","error":{"message":"open /data/locked: permission denied"},"during":"archival","item":"/data/locked"}' >&2
+echo '{"message_type":"summary","snapshot_id":"abc123","total_files_processed":9}'
+exit 3
+`)
+	var events []string
+	res, err := w.Backup(context.Background(), Destination{RepoURL: "/repo"}, BackupOptions{Sources: []string{"/data"}}, func(ev ProgressEvent) error {
+		events = append(events, ev.MessageType)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Backup() error = %v, want nil for exit code 3", err)
+	}
+	if !res.Incomplete || res.SnapshotID != "abc123" || res.TotalFiles != 9 {
+		t.Errorf("Backup() = %+v, want incomplete snapshot abc123", res)
+	}
+	want := FileError{Path: "/data/locked", During: "archival", Message: "open /data/locked: permission denied"}
+	if res.FileErrorCount != 1 || len(res.FileErrors) != 1 || res.FileErrors[0] != want {
+		t.Errorf("FileErrors = %d %+v, want [%+v]", res.FileErrorCount, res.FileErrors, want)
+	}
+	if strings.Join(events, ",") != "status,summary,error" {
+		t.Errorf("forwarded events = %v", events)
+	}
+
+	// Without a snapshot, exit code 3 is still a failure.
+	w = fakeRestic(t, `[ "$1" = init ] && exit 0
+exit 3
+`)
+	if _, err := w.Backup(context.Background(), Destination{RepoURL: "/repo"}, BackupOptions{}, nil); err == nil {
+		t.Error("Backup() without snapshot error = nil, want failure")
+	}
+}
+
+func TestBackupStdin(t *testing.T) {
+	w := fakeRestic(t, `[ "$1" = init ] && exit 0
+[ "$3" = --stdin ] && [ "$4" = --stdin-filename ] && [ "$5" = postgres-app.sql ] || exit 1
+[ "$(cat)" = "CREATE TABLE t;" ] || exit 1
+echo '{"message_type":"summary","snapshot_id":"dump1","total_bytes_processed":15}'
+`)
+	opts := BackupOptions{Stdin: strings.NewReader("CREATE TABLE t;"), StdinFilename: "postgres-app.sql", Sources: []string{"/ignored"}}
+	res, err := w.Backup(context.Background(), Destination{RepoURL: "/repo"}, opts, nil)
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	if res.SnapshotID != "dump1" || res.TotalBytesProcessed != 15 {
+		t.Errorf("Backup() = %+v, want snapshot dump1 of 15 bytes", res)
+	}
+}
+
+func TestBackupStdinFromCommand(t *testing.T) {
+	w := fakeRestic(t, `[ "$1" = init ] && exit 0
+[ "$4" = etcd.db ] && [ "$5" = --stdin-from-command ] && [ "$6" = -- ] || exit 1
+[ "$7" = etcdctl ] && [ "$8" = "snapshot save" ] || exit 1
+echo '{"message_type":"summary","snapshot_id":"etcd1","total_bytes_processed":42}'
+`)
+	opts := BackupOptions{StdinCommand: []string{"etcdctl", "snapshot save"}, StdinFilename: "etcd.db"}
+	res, err := w.Backup(context.Background(), Destination{RepoURL: "/repo"}, opts, nil)
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	if res.SnapshotID != "etcd1" {
+		t.Errorf("Backup() = %+v, want snapshot etcd1", res)
+	}
+}
+
+func TestDump(t *testing.T) {
+	w := fakeRestic(t, `[ "$1" = dump ] && [ "$2" = dump1 ] && [ "$3" = /postgres-app.sql ] || exit 1
+printf 'CREATE TABLE t;'
+`)
+	var out strings.Builder
+	if err := w.Dump(context.Background(), Destination{RepoURL: "/repo"}, "dump1", "/postgres-app.sql", &out); err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	if out.String() != "CREATE TABLE t;" {
+		t.Errorf("Dump() wrote %q", out.String())
+	}
+}
+
+func TestCopy(t *testing.T) {
+	w := fakeRestic(t, `case "$1" in
+init) [ "$2" = --copy-chunker-params ] || exit 1; echo "config file already exists" >&2; exit 1 ;;
+copy) [ "$RESTIC_FROM_REPOSITORY" = /src ] && [ "$RESTIC_FROM_PASSWORD" = from-pw ] && [ "$S3_KEY" = dest ] || exit 1 ;;
+snapshots) echo '[{"id":"older"},{"id":"copy456","original":"abc123"}]' ;;
+esac
+`)
+	from := Destination{RepoURL: "/src", Password: "from-pw", Env: map[string]string{"S3_KEY": "from"}}
+	dest := Destination{RepoURL: "/dst", Password: "pw", Env: map[string]string{"S3_KEY": "dest"}}
+
+	id, err := w.Copy(context.Background(), from, dest, "abc123")
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if id != "copy456" {
+		t.Errorf("Copy() = %q, want copy456", id)
+	}
+
+	if _, err := w.Copy(context.Background(), from, dest, "missing"); err == nil {
+		t.Error("Copy() of a snapshot without copy error = nil, want failure")
+	}
+}
+
+func TestCopySnapshots(t *testing.T) {
+	w := fakeRestic(t, `case "$1" in
+init) exit 0 ;;
+copy) [ "$2" = --tag ] && [ "$3" = policy:p1,nightly ] && [ "$4" = --host ] && [ "$5" = nas ] || exit 1 ;;
+snapshots)
+	if [ "$RESTIC_REPOSITORY" = /src ]; then
+		[ "$4" = --tag ] || exit 1
+		echo '[{"id":"a1"},{"id":"b2","original":"orig-b"}]'
+	else
+		echo '[{"id":"c1","original":"a1"},{"id":"c2","original":"orig-b"},{"id":"c3","original":"other"},{"id":"c4"}]'
+	fi ;;
+esac
+`)
+	copies, err := w.CopySnapshots(context.Background(), Destination{RepoURL: "/src"}, Destination{RepoURL: "/dst"}, SnapshotFilter{
+		Tags: []string{"policy:p1", "nightly"},
+		Host: "nas",
+	})
+	if err != nil {
+		t.Fatalf("CopySnapshots() error = %v", err)
+	}
+	// c2 is a copy of b2, itself a copy: restic keeps the first original.
+	if len(copies) != 2 || copies[0].ID != "c1" || copies[0].OriginalID != "a1" || copies[1].ID != "c2" || copies[1].OriginalID != "b2" {
+		t.Errorf("CopySnapshots() = %+v, want c1 of a1 and c2 of b2", copies)
+	}
+}
+
+func TestSnapshots_Summary(t *testing.T) {
+	w := fakeRestic(t, `[ "$1" = snapshots ] && [ "$RESTIC_PASSWORD" = pw ] || exit 1
+echo '[{"id":"old","hostname":"web01","paths":["/etc"]},{"id":"new","hostname":"web01","paths":["/etc"],"summary":{"total_files_processed":12,"total_bytes_processed":3400}}]'
+`)
+	snapshots, err := w.Snapshots(context.Background(), Destination{RepoURL: "/repo", Password: "pw"})
+	if err != nil {
+		t.Fatalf("Snapshots() error = %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Summary != nil {
+		t.Fatalf("Snapshots() = %+v, want a snapshot without summary first", snapshots)
+	}
+	if s := snapshots[1].Summary; s == nil || s.TotalFilesProcessed != 12 || s.TotalBytesProcessed != 3400 {
+		t.Errorf("summary = %+v, want 12 files and 3400 bytes", s)
+	}
+}
@@ -4,7 +4,9 @@
  */
 
 import {
+    AlertTriangle,
     Ban,
+    CalendarX,
     CheckCircle,
     Clock,
     Loader,
@@ -16,10 +18,13 @@ import {
 export function statusVariant(status: string): 'default' | 'secondary' | 'destructive' | 'outline' {
     switch (status) {
         case 'succeeded': return 'outline'
+        case 'succeeded_with_warnings': return 'outline'
         case 'running': return 'outline'
         case 'failed': return 'destructive'
+        case 'partial': return 'outline'
         case 'pending': return 'outline'
         case 'cancelled': return 'outline'
+        case 'missed': return 'outline'
         default: return 'secondary'
     }
 }
@@ -27,14 +32,18 @@ export function statusVariant(status: string): 'default' | 'secondary' | 'destru
 export function statusClass(status: string): string {
     switch (status) {
         case 'succeeded': return 'bg-green-500/10 text-green-700 dark:text-green-400 border-green-500/20'
+        case 'succeeded_with_warnings': return 'bg-yellow-500/10 text-yellow-700 dark:text-yellow-400 border-yellow-500/20'
         case 'running': return 'bg-blue-500/10 text-blue-700 dark:text-blue-400 border-blue-500/20'
         case 'pending': return 'bg-amber-500/10 text-amber-700 dark:text-amber-400 border-amber-500/20'
         case 'cancelled': return 'bg-slate-500/10 text-slate-600 dark:text-slate-400 border-slate-500/20'
+        case 'partial': return 'bg-orange-500/10 text-orange-700 dark:text-orange-400 border-orange-500/20'
+        case 'missed': return 'bg-orange-500/10 text-orange-700 dark:text-orange-400 border-orange-500/20'
         default: return ''
     }
 }
 
 export function statusLabel(status: string): string {
+    if (status === 'succeeded_with_warnings') return 'Warnings'
     return status.charAt(0).toUpperCase() + status.slice(1)
 }
 
@@ -42,9 +51,12 @@ export function statusLabel(status: string): string {
 export function statusIcon(status: string) {
     switch (status) {
         case 'succeeded': return CheckCircle
+        case 'succeeded_with_warnings': return AlertTriangle
         case 'running': return Loader
         case 'failed': return XCircle
+        case 'partial': return AlertTriangle
         case 'cancelled': return Ban
+        case 'missed': return CalendarX
         case 'pending':
         default: return Clock
     }
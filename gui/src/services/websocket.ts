@@ -11,6 +11,7 @@ import { useAuthStore } from '@/stores/auth'
 export type MessageType =
   | 'job.status'
   | 'job.log'
+  | 'job.progress'
   | 'agent.status'
   | 'agent.metrics'
   | 'notification'
@@ -29,12 +29,18 @@ export const JobStatus = {
   Running: 'running',
   Succeeded: 'succeeded',
   Failed: 'failed',
+  Partial: 'partial',           // some destinations failed, others got a snapshot
   Cancelled: 'cancelled',
+  Missed: 'missed',
+  SucceededWithWarnings: 'succeeded_with_warnings', // job destinations only
 } as const
 export type JobStatus = (typeof JobStatus)[keyof typeof JobStatus]
 export const JobType = {
   Backup: 'backup',
   Restore: 'restore',
+  DryRun: 'dry_run',
+  Replication: 'replication',
+  Import: 'import', // records the snapshots adopted from an imported repository
 } as const
 export type JobType = (typeof JobType)[keyof typeof JobType]
 
@@ -50,9 +56,42 @@ export type DestinationType = (typeof DestinationType)[keyof typeof DestinationT
 export const SourceType = {
   Path: 'path',
   DockerVolume: 'docker-volume',
+  DockerLabel: 'docker-label',
+  Database: 'database',
+  DockerDump: 'docker-dump',
+  Command: 'command',
 } as const
 export type SourceType = (typeof SourceType)[keyof typeof SourceType]
 
+// What the agent does to the containers mounting a docker-volume source while
+// it is backed up. Omitted, the containers keep running.
+export const ContainerMode = {
+  Stop: 'stop',
+  Pause: 'pause',
+} as const
+export type ContainerMode = (typeof ContainerMode)[keyof typeof ContainerMode]
+
+// Engine of a database connection. MySQL covers MariaDB too.
+export const DatabaseEngine = {
+  Postgres: 'postgres',
+  MySQL: 'mysql',
+  MongoDB: 'mongodb',
+  Redis: 'redis',
+  SQLite: 'sqlite',
+} as const
+export type DatabaseEngine = (typeof DatabaseEngine)[keyof typeof DatabaseEngine]
+
+// Phases of a policy's hooks, in the order a job runs them.
+export const HookPhase = {
+  PreBackup: 'pre_backup',
+  PostBackup: 'post_backup',   // runs whatever the outcome of the destinations
+  OnSuccess: 'on_success',
+  OnFailure: 'on_failure',
+  PreRestore: 'pre_restore',
+  PostRestore: 'post_restore',
+} as const
+export type HookPhase = (typeof HookPhase)[keyof typeof HookPhase]
+
 export const NotificationChannel = {
   InApp: 'in_app',
   Email: 'email',
@@ -124,15 +163,98 @@ export interface Destination {
   // repository_password is always masked ("***") on read
   repository_password: string
   enabled: boolean
+  max_concurrent_jobs: number  // 0 = unlimited
+  created_at: string
+  updated_at: string
+}
+
+// Repository is a restic repository below the root of a destination
+// (GET /repositories). Policies of any agent may write into the same
+// repository. The password is write-only.
+export interface Repository {
+  id: string
+  name: string
+  destination_id: string
+  path: string                   // relative to the destination root, empty = the root
+  chunker_from_id: string | null // repository whose chunker parameters it is created with
+  initialized_at: string | null  // null until a job has written to it
+  policy_count: number
+  created_at: string
+  updated_at: string
+}
+
+// DatabaseConnection is how agents reach a database server to dump it
+// (GET /database-connections). The password is write-only.
+export interface DatabaseConnection {
+  id: string
+  name: string
+  engine: DatabaseEngine
+  host: string     // empty = the engine's default on the agent's host
+  port: number     // 0 = the engine's default
+  username: string
+  has_password: boolean
+  policy_count: number
+  created_at: string
+  updated_at: string
+}
+
+export type RepositoryImportStatus = 'scanning' | 'scanned' | 'failed' | 'applying' | 'applied'
+
+// RepositoryImportGroup is the set of snapshots of one host and set of paths
+// found by a scan; applying the import creates one draft policy per group.
+export interface RepositoryImportGroup {
+  hostname: string
+  paths: string[]
+  tags: string[]
+  snapshot_count: number
+  first_snapshot_at: string
+  last_snapshot_at: string
+  agent_id: string | null // existing agent with this hostname, null = one is created
+}
+
+// RepositoryImport adopts a restic repository created outside Arkeep.
+export interface RepositoryImport {
+  id: string
+  repository_id: string
+  agent_id: string // the agent that scans the repository
+  status: RepositoryImportStatus
+  error: string
+  snapshot_count: number
+  groups?: RepositoryImportGroup[] // detail only, once scanned
+  applied_at: string | null
   created_at: string
   updated_at: string
 }
 
+export interface ApplyRepositoryImportRequest {
+  agents?: Record<string, string> // hostname → agent ID; unmapped hosts use the agent with that hostname
+}
+
+export interface ApplyRepositoryImportResult {
+  import: RepositoryImport
+  policies: { policy_id: string; agent_id: string; hostname: string; paths: string[]; snapshots: number }[]
+  agents_created: number
+  snapshots_imported: number
+  snapshots_skipped: number // already in the catalog
+}
+
 // ─── Policy ───────────────────────────────────────────────────────────────────
 
 export interface PolicySource {
   type: SourceType
-  path: string // filesystem path or docker volume name
+  path: string // filesystem path, docker volume name or docker label (key or key=value)
+  containers?: ContainerMode // docker-volume sources only
+  connection_id?: string // database sources only
+  database?: string      // database sources: database name, or file path for SQLite; unused for Redis
+  // docker-dump sources: a dump run inside the container, with the engine's
+  // preset (postgres, mysql, mongodb) or a custom command. Without database,
+  // a preset dumps the whole server.
+  container?: string
+  engine?: DatabaseEngine
+  command?: string  // docker-dump and command sources
+  username?: string
+  container_credentials?: boolean // read the user and password from the container's environment
+  filename?: string // command sources: name of the output in the snapshot
 }
 
 export interface RetentionConfig {
@@ -144,33 +266,99 @@ export interface RetentionConfig {
   keep_yearly: number
 }
 
-export interface HookConfig {
-  pre_backup: string[] // shell commands to run before backup
-  post_backup: string[] // shell commands to run after backup (regardless of outcome)
-  timeout_seconds: number
+// HookStep is one shell command of a hook phase. The agent exposes the job to
+// it as ARKEEP_JOB_ID, ARKEEP_POLICY_ID, ARKEEP_HOOK_PHASE, ARKEEP_JOB_STATUS
+// and ARKEEP_SNAPSHOT_ID environment variables.
+export interface HookStep {
+  name?: string
+  command: string
+  args?: string[]                // positional parameters ($1, $2, …)
+  env?: Record<string, string>   // RESTIC_*, RCLONE_* and ARKEEP_* are reserved
+  working_dir?: string           // absolute path, empty = the agent's
+  run_as?: string                // user name or uid, not supported on Windows agents
+  timeout_secs?: number          // 0 or unset = the agent's default (5 minutes)
+  continue_on_error?: boolean    // run the next step when this one fails
 }
 
+// PolicyHooks maps each hook phase to its ordered steps. Only admins may change it.
+export type PolicyHooks = Partial<Record<HookPhase, HookStep[]>>
+
 export interface PolicyDestination {
   destination_id: string
   destination_name: string // denormalized for display; populated by server join
   priority: number // lower = higher priority; used for 3-2-1 ordering
+  repository_id: string | null // null = the destination root with its own or the policy's password
+  key_fingerprint?: string // zero-knowledge: fingerprint of the agent-held key, once created
+}
+
+// Outcome of the upstream job that starts a dependent policy.
+export type DependencyCondition = 'success' | 'failure' | 'always'
+
+// FanOutMode is how a backup reaches a policy's destinations: one after the
+// other, concurrently, or backed up once and copied to the others.
+export type FanOutMode = 'sequential' | 'parallel' | 'replicate'
+
+// PolicyType distinguishes backup policies from replication policies, which
+// copy existing snapshots from one destination to the policy's destinations.
+export type PolicyType = 'backup' | 'replication'
+
+export interface PolicyDependency {
+  policy_id: string // the upstream policy this policy runs after
+  condition: DependencyCondition
+}
+
+// ScheduleRetention is the keep-* set of a schedule entry with its own retention.
+export interface ScheduleRetention {
+  hourly: number
+  daily: number
+  weekly: number
+  monthly: number
+  yearly: number
+}
+
+// PolicySchedule is an additional schedule entry of a policy. Its snapshots
+// carry the extra tags; a non-null retention is applied to them alone.
+export interface PolicySchedule {
+  id: string
+  schedule: string
+  tags: string[]
+  retention: ScheduleRetention | null
 }
 
 export interface Policy {
   id: string
   name: string
+  type: PolicyType
   agent_id: string
   agent_name: string
-  sources: string           // JSON string — parse client-side when needed
-  schedule: string
+  sources: string           // JSON string — parse client-side when needed; "[]" for replication
+  schedule: string          // empty = only run after the policies in run_after or on schedules
+  timezone: string          // IANA zone for schedule; empty = server local time
   retention_daily: number
   retention_weekly: number
   retention_monthly: number
   retention_yearly: number
-  hook_pre_backup: string   // JSON string or empty
-  hook_post_backup: string  // JSON string or empty
+  hooks: PolicyHooks
+  catch_up: boolean         // run once at startup when a scheduled run was missed
+  start_jitter_seconds: number // upper bound of the stable start delay, 0 = none
+  retry_max_attempts: number   // total attempts per run, 1 = no retries
+  retry_backoff_seconds: number // base delay before a retry, doubled for each further retry
+  retry_on: FailureClass[]
+  max_runtime_seconds: number  // the agent stops the backup after this long, 0 = no limit
+  anomaly_detection: boolean   // flag runs whose change rate spikes against the baseline
+  anomaly_hold: boolean        // on an anomaly, tag the last known-good snapshot arkeep:hold
+  warnings_as_failure: boolean // notify runs with unreadable files as failures
+  fan_out: FanOutMode          // how a backup reaches several destinations, in priority order
+  fan_out_parallelism: number  // parallel backups at once with fan_out "parallel", 0 = all
+  replicate_from_id: string | null   // replication: destination the snapshots are copied from
+  replicate_tag: string              // replication: only snapshots with this tag, empty = all
+  replicate_host: string             // replication: only snapshots of this host, empty = all
+  replicate_policy_id: string | null // replication: only snapshots of this policy, null = all
+  zero_knowledge: boolean            // repository keys stay on the agents, fixed at creation
   enabled: boolean
   destinations: PolicyDestination[]
+  run_after: PolicyDependency[]
+  schedules: PolicySchedule[]
   last_run_at: string | null
   next_run_at: string | null
   created_at: string
@@ -178,8 +366,77 @@ export interface Policy {
 }
 
 // PolicyListItem is the leaner shape returned by the list endpoint.
-// Destinations are NOT included (too costly — N extra queries per policy).
-export type PolicyListItem = Omit<Policy, 'destinations'>
+// Destinations, dependencies and schedule entries are NOT included (too costly — N extra queries per policy).
+export type PolicyListItem = Omit<Policy, 'destinations' | 'run_after' | 'schedules'>
+
+// TriggerToken authorizes POST /api/v1/hooks/trigger/{token} for one policy
+// (GET /policies/{id}/trigger-tokens). The raw token is never listed.
+export interface TriggerToken {
+  id: string
+  policy_id: string
+  name: string
+  token_prefix: string      // first characters of the token, for identification
+  signed: boolean           // requests must carry an X-Arkeep-Signature HMAC
+  last_used_at: string | null
+  revoked_at: string | null
+  created_at: string
+}
+
+// TriggerTokenSecret is returned once, on create and rotate.
+export interface TriggerTokenSecret extends TriggerToken {
+  token: string
+  url: string               // webhook path relative to the server root
+  signing_secret?: string
+}
+
+// UpcomingRun is a single projected run from GET /schedule/upcoming.
+export interface UpcomingRun {
+  policy_id: string
+  schedule_id: string | null // schedule entry, null for the policy's main schedule
+  policy_name: string
+  agent_id: string
+  agent_name: string
+  destination_ids: string[]
+  scheduled_at: string
+}
+
+// ScheduleCollision lists policies hitting the same destination at once.
+export interface ScheduleCollision {
+  destination_id: string
+  scheduled_at: string
+  policy_ids: string[]
+}
+
+export interface UpcomingRunsResponse {
+  from: string
+  to: string
+  items: UpcomingRun[]
+  collisions: ScheduleCollision[]
+  truncated: boolean
+}
+
+// QueuedJob is a job waiting for a destination slot (GET /schedule/queue).
+export interface QueuedJob {
+  job_id: string
+  policy_id: string
+  policy_name: string
+  agent_id: string
+  destination_ids: string[]
+  queued_at: string
+}
+
+export interface DestinationLoad {
+  destination_id: string
+  destination_name: string
+  max_concurrent_jobs: number
+  running: number
+  queued: number
+}
+
+export interface ScheduleQueueResponse {
+  destinations: DestinationLoad[]
+  items: QueuedJob[]
+}
 
 // ─── Job ──────────────────────────────────────────────────────────────────────
 
@@ -193,6 +450,23 @@ export interface JobDestination {
   started_at: string | null
   ended_at: string | null
   error: string
+  // Change statistics from the restic summary, zero for failed runs
+  files_new: number
+  files_changed: number
+  files_unmodified: number
+  total_files: number
+  data_added: number
+  anomaly: boolean          // change rate spiked against the previous runs
+  anomaly_reason: string
+  file_errors: FileError[]  // files restic could not read, truncated to 100
+  file_error_count: number  // full count, set with status succeeded_with_warnings
+}
+
+// FileError is a source file restic could not back up.
+export interface FileError {
+  path: string
+  during: string            // backup phase, e.g. "archival"
+  message: string
 }
 
 export interface JobLog {
@@ -213,13 +487,74 @@ export interface Job {
   error: string
   started_at: string | null
   ended_at: string | null
+  attempt: number               // 1 for the first run, incremented on each retry
+  retry_of_id: string | null    // the failed attempt this job retries
+  triggered_by_job_id: string | null // upstream job that started this run via run_after
+  schedule_id: string | null    // schedule entry that started this run
+  failure_class: FailureClass | ''
+  not_before: string | null     // retries are not dispatched before this time
+  last_activity_at: string | null // last status or log report from the agent
   created_at: string
   // Populated only on GetByID (detail endpoint)
   destinations?: JobDestination[]
+  retry_chain?: JobAttempt[]    // every attempt of the run, only when retried
+  dry_run?: DryRunResult        // dry_run jobs only, once the agent reported it
+  progress?: JobProgress        // latest progress snapshot, once the agent reported one
+  file_error_count?: number     // detail only: file errors summed over destinations
+}
+
+// JobProgress is the latest restic status of a job, also pushed live as a
+// 'job.progress' WebSocket message (with job_id, without updated_at).
+export interface JobProgress {
+  job_id?: string
+  destination_id: string        // empty for jobs without destinations (dry run)
+  percent_done: number          // 0..1
+  files_done: number
+  total_files: number
+  bytes_done: number
+  total_bytes: number
+  current_file: string
+  seconds_remaining: number
+  seconds_elapsed: number
+  updated_at?: string
+}
+
+// DryRunResult is the size estimate of a first backup of a policy's sources.
+export interface DryRunResult {
+  total_files: number
+  total_bytes: number
+  data_added: number             // bytes restic would upload, after dedup and compression
+  largest_directories: DirectorySize[]
+  unreadable_paths: UnreadablePath[] // capped; see unreadable_count for the total
+  unreadable_count: number
+}
+
+// DirectorySize counts only the files directly inside the directory.
+export interface DirectorySize {
+  path: string
+  bytes: number
+  files: number
+}
+
+export interface UnreadablePath {
+  path: string
+  error: string
+}
+
+export type FailureClass = 'network' | 'hook' | 'wrong_password' | 'other'
+
+export interface JobAttempt {
+  id: string
+  attempt: number
+  status: JobStatus
+  failure_class: FailureClass | ''
+  error: string
+  created_at: string
+  ended_at: string | null
 }
 
 // JobListItem is the leaner shape returned by the list endpoint.
-export type JobListItem = Omit<Job, 'destinations'>
+export type JobListItem = Omit<Job, 'destinations' | 'retry_chain' | 'dry_run' | 'progress' | 'file_error_count'>
 
 // ─── Snapshot ─────────────────────────────────────────────────────────────────
 
@@ -230,6 +565,7 @@ export interface Snapshot {
   destination_id: string
   destination_name: string // denormalized for display
   restic_snapshot_id: string // the actual Restic snapshot hash
+  original_id: string | null // for replicated snapshots, the catalog snapshot it was copied from
   hostname: string
   paths: string[]
   tags: string[]
@@ -243,7 +579,7 @@ export interface Snapshot {
 // GET /api/v1/notifications.
 export interface Notification {
   id: string
-  type: string       // "job_success" | "job_failure" | "agent_offline"
+  type: string       // "job_success" | "job_failure" | "agent_offline" | "backup_anomaly"
   title: string
   body: string
   payload: string    // JSON string with extra event context
@@ -253,6 +589,38 @@ export interface Notification {
 
 // ─── Settings ─────────────────────────────────────────────────────────────────
 
+// KeyShare hands the key of a zero-knowledge policy on one destination to
+// another agent so it can restore there.
+export interface KeyShare {
+  id: string
+  policy_id: string
+  destination_id: string
+  from_agent_id: string
+  to_agent_id: string
+  key_fingerprint: string
+  recipient_key_fingerprint: string // compare with the fingerprint the recipient agent logs at start
+  status: 'pending' | 'sealed' | 'failed'
+  error: string
+  created_at: string
+  updated_at: string
+}
+
+// EscrowSettings is the admin's escrow public key, to which agents seal
+// their zero-knowledge repository keys.
+export interface EscrowSettings {
+  public_key: string
+  fingerprint: string
+}
+
+// EscrowKit is an agent's latest escrow kit, sealed to the escrow key.
+export interface EscrowKit {
+  agent_id: string
+  escrow_key_fingerprint: string
+  key_count: number
+  kit: string // base64; open offline with `arkeep-agent escrow open`
+  updated_at: string
+}
+
 // SMTPSettings maps to the smtp.* keys in the settings table.
 export interface SMTPSettings {
   host: string
@@ -338,24 +706,61 @@ export interface CreateDestinationRequest {
   type: DestinationType
   config: string
   repository_password: string
+  max_concurrent_jobs?: number
 }
 
 export type UpdateDestinationRequest = Partial<CreateDestinationRequest>
 
+// Repositories
+export interface CreateRepositoryRequest {
+  name: string
+  destination_id: string
+  path?: string
+  password: string
+  chunker_from_id?: string
+}
+
+// Only the name and the password Arkeep opens the repository with can change.
+export type UpdateRepositoryRequest = Partial<Pick<CreateRepositoryRequest, 'name' | 'password'>>
+
 // Policies
 export interface CreatePolicyRequest {
   name: string
+  type?: PolicyType
   agent_id: string
   sources: PolicySource[]
   schedule: string
+  timezone?: string
+  start_jitter_seconds?: number
+  retry_max_attempts?: number
+  retry_backoff_seconds?: number
+  retry_on?: FailureClass[]
+  max_runtime_seconds?: number
+  anomaly_detection?: boolean
+  anomaly_hold?: boolean
+  warnings_as_failure?: boolean
+  fan_out?: FanOutMode
+  fan_out_parallelism?: number
+  replicate_from_id?: string
+  replicate_tag?: string
+  replicate_host?: string
+  replicate_policy_id?: string
+  zero_knowledge?: boolean
+  run_after?: { policy_id: string; condition?: DependencyCondition }[]
+  schedules?: { id?: string; schedule: string; tags?: string[]; retention?: ScheduleRetention | null }[]
   retention: RetentionConfig
-  hooks?: HookConfig
+  hooks?: PolicyHooks
   enabled: boolean
-  destination_ids: { destination_id: string; priority: number }[]
+  destination_ids: { destination_id: string; priority: number; repository_id?: string; repo_password?: string }[]
 }
 
 export type UpdatePolicyRequest = Partial<CreatePolicyRequest>
 
+export interface CreateTriggerTokenRequest {
+  name: string
+  signed: boolean
+}
+
 // Users
 export interface CreateUserRequest {
   email: string
@@ -379,9 +784,12 @@ export interface UpdateMeRequest {
 }
 
 // Snapshots
+// A database dump is loaded into a database when target_path is empty or
+// database is set; empty database fields default to the dumped database.
 export interface RestoreRequest {
   agent_id: string
   target_path: string
+  database?: { connection_id?: string; name?: string }
 }
 
 export interface RestoreResponse {
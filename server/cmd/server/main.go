@@ -51,6 +51,8 @@ type config struct {
 	secureCookies bool
 	telemetry     bool
 	grpcInsecure  bool
+
+	stuckJobTimeout time.Duration
 }
 
 func main() {
@@ -89,6 +91,7 @@ and manages scheduling, policies, and notifications.`,
 root.PersistentFlags().BoolVar(&cfg.secureCookies, "secure-cookies", envOrDefault("ARKEEP_SECURE_COOKIES", "false") == "true", "Set Secure flag on auth cookies (enable in production over HTTPS)")
 	root.PersistentFlags().BoolVar(&cfg.telemetry, "telemetry", envOrDefault("ARKEEP_TELEMETRY", "true") != "false", "Send anonymous usage stats (opt-out)")
 	root.PersistentFlags().BoolVar(&cfg.grpcInsecure, "grpc-insecure", envOrDefault("ARKEEP_GRPC_INSECURE", "false") == "true", "Disable TLS for gRPC transport (development only — never use in production)")
+	root.PersistentFlags().DurationVar(&cfg.stuckJobTimeout, "stuck-job-timeout", envDurationOrDefault("ARKEEP_STUCK_JOB_TIMEOUT", 2*time.Hour), "Fail running jobs with no status or log activity for this long")
 
 	return root
 }
@@ -177,6 +180,11 @@ func run(ctx context.Context, cfg *config) error {
 	settingsRepo := repositories.NewSettingsRepository(gormDB)
 	dashboardRepo := repositories.NewDashboardRepository(gormDB)
 	auditRepo := repositories.NewAuditRepository(gormDB)
+	triggerTokenRepo := repositories.NewTriggerTokenRepository(gormDB)
+	repositoryRepo := repositories.NewRepositoryRepository(gormDB)
+	keyRepo := repositories.NewKeyRepository(gormDB)
+	importRepo := repositories.NewImportRepository(gormDB)
+	connectionRepo := repositories.NewDatabaseConnectionRepository(gormDB)
 
 	// --- Auth ---
 	// In development (no data dir or missing key files), ephemeral keys are
@@ -217,20 +225,6 @@ func run(ctx context.Context, cfg *config) error {
 	m := metrics.New(prometheus.DefaultRegisterer)
 	metrics.RegisterAgentsGauge(prometheus.DefaultRegisterer, agentMgr.ConnectedAgentsCount)
 
-	// --- Scheduler ---
-	sched, err := scheduler.New(policyRepo, jobRepo, destinationRepo, agentMgr, logger)
-	if err != nil {
-		return fmt.Errorf("failed to create scheduler: %w", err)
-	}
-	if err := sched.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start scheduler: %w", err)
-	}
-	defer func() {
-		if err := sched.Stop(); err != nil {
-			logger.Warn("scheduler shutdown error", zap.Error(err))
-		}
-	}()
-
 	// --- WebSocket Hub ---
 	// The hub must start before the HTTP server so clients can connect
 	// immediately after the server is ready.
@@ -253,6 +247,37 @@ func run(ctx context.Context, cfg *config) error {
 	// (max 3 attempts: +5 min → +30 min → exhausted).
 	go notifService.Start(ctx)
 
+	// --- Scheduler ---
+	// Created after the notification service so that runs missed while the
+	// server was down can be notified from Start.
+	sched, err := scheduler.New(
+		scheduler.Config{
+			NotifService:        notifService,
+			StuckJobTimeout:     cfg.stuckJobTimeout,
+			Repositories:        repositoryRepo,
+			Settings:            settingsRepo,
+			Keys:                keyRepo,
+			Imports:             importRepo,
+			DatabaseConnections: connectionRepo,
+		},
+		policyRepo,
+		jobRepo,
+		destinationRepo,
+		agentMgr,
+		logger,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create scheduler: %w", err)
+	}
+	if err := sched.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start scheduler: %w", err)
+	}
+	defer func() {
+		if err := sched.Stop(); err != nil {
+			logger.Warn("scheduler shutdown error", zap.Error(err))
+		}
+	}()
+
 	// --- gRPC server ---
 	grpcSrv := grpcserver.New(
 		grpcserver.Config{
@@ -262,6 +287,11 @@ func run(ctx context.Context, cfg *config) error {
 			AutoCerts:    autoCerts,
 			NotifService: notifService,
 			Metrics:      m,
+			Scheduler:    sched,
+			Policies:     policyRepo,
+			Repositories: repositoryRepo,
+			Keys:         keyRepo,
+			Imports:      importRepo,
 		},
 		agentMgr,
 		agentRepo,
@@ -280,28 +310,33 @@ func run(ctx context.Context, cfg *config) error {
 
 	// --- HTTP router ---
 	router := api.NewRouter(api.RouterConfig{
-		Metrics: m,
-		DB:      sqlDB,
-		AuthService:   authService,
-		Scheduler:     sched,
-		AgentManager:  agentMgr,
-		Logger:        logger,
-		Hub:           wsHub,
-		Users:         userRepo,
-		Agents:        agentRepo,
-		Destinations:  destinationRepo,
-		Policies:      policyRepo,
-		Jobs:          jobRepo,
-		Snapshots:     snapshotRepo,
-		Notifications: notificationRepo,
-		OIDCProviders: oidcProviderRepo,
-		Settings:      settingsRepo,
-		Secure:        cfg.secureCookies,
-		Dashboard:     dashboardRepo,
-		Audit:         auditRepo,
-		AutoCerts:     autoCerts,
-		AgentSecret:   cfg.agentSecret,
-		ServerVersion: version,
+		Metrics:             m,
+		DB:                  sqlDB,
+		AuthService:         authService,
+		Scheduler:           sched,
+		AgentManager:        agentMgr,
+		Logger:              logger,
+		Hub:                 wsHub,
+		Users:               userRepo,
+		Agents:              agentRepo,
+		Destinations:        destinationRepo,
+		Policies:            policyRepo,
+		Jobs:                jobRepo,
+		Snapshots:           snapshotRepo,
+		Notifications:       notificationRepo,
+		OIDCProviders:       oidcProviderRepo,
+		Settings:            settingsRepo,
+		Secure:              cfg.secureCookies,
+		Dashboard:           dashboardRepo,
+		Audit:               auditRepo,
+		TriggerTokens:       triggerTokenRepo,
+		Repositories:        repositoryRepo,
+		Keys:                keyRepo,
+		Imports:             importRepo,
+		DatabaseConnections: connectionRepo,
+		AutoCerts:           autoCerts,
+		AgentSecret:         cfg.agentSecret,
+		ServerVersion:       version,
 	})
 	api.MountGUI(router, guiFS())
 
@@ -435,4 +470,13 @@ func envOrDefault(key, defaultVal string) string {
 		return v
 	}
 	return defaultVal
-}
\ No newline at end of file
+}
+
+// envDurationOrDefault is like envOrDefault for time.Duration values such as
+// "90m". An unparsable value falls back to the default.
+func envDurationOrDefault(key string, defaultVal time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return d
+	}
+	return defaultVal
+}
@@ -30,6 +30,10 @@ var ErrAgentNotConnected = errors.New("agent not connected")
 // LIST_VOLUMES request within the deadline.
 var ErrVolumeListTimeout = errors.New("volume list request timed out")
 
+// ErrDestinationBusy is returned by DispatchLimited when at least one of the
+// job's destinations is already running its maximum number of jobs.
+var ErrDestinationBusy = errors.New("destination at concurrency limit")
+
 // volumeListTimeout is how long RequestVolumeList waits for the agent to reply.
 const volumeListTimeout = 10 * time.Second
 
@@ -81,6 +85,19 @@ type Manager struct {
 	// the result on the matching channel and removes the entry.
 	pendingMu          sync.Mutex
 	pendingVolumeLists map[string]chan VolumeListResult // keyed by correlation ID
+
+	// inFlight tracks backup jobs dispatched via DispatchLimited until they
+	// are released, so that per-destination concurrency limits can be
+	// enforced. destRunning is the derived count per destination ID.
+	slotsMu     sync.Mutex
+	inFlight    map[string]inFlightJob // keyed by job ID
+	destRunning map[string]int         // keyed by destination ID
+}
+
+// inFlightJob records the agent and destinations occupied by a dispatched job.
+type inFlightJob struct {
+	agentID      string
+	destinations []string
 }
 
 // New creates a new Manager instance.
@@ -88,6 +105,8 @@ func New(logger *zap.Logger) *Manager {
 	return &Manager{
 		agents:             make(map[string]*ConnectedAgent),
 		pendingVolumeLists: make(map[string]chan VolumeListResult),
+		inFlight:           make(map[string]inFlightJob),
+		destRunning:        make(map[string]int),
 		logger:             logger.Named("agentmanager"),
 	}
 }
@@ -138,16 +157,22 @@ func (m *Manager) Deregister(agentID string) {
 
 	delete(m.agents, agentID)
 
+	// Jobs in flight on this agent will never report a terminal status
+	// (the gRPC server fails them as orphans), so free their slots now.
+	released := m.releaseAgent(agentID)
+
 	m.logger.Info("agent disconnected",
 		zap.String("agent_id", agentID),
 		zap.String("hostname", agent.Hostname),
 		zap.Duration("session_duration", time.Since(agent.ConnectedAt)),
+		zap.Int("released_jobs", released),
 		zap.Int("total_connected", len(m.agents)),
 	)
 }
 
 // Dispatch sends a JobAssignment to a specific agent via its open stream.
-// Returns an error if the agent is not connected or if the send fails.
+// Returns an error wrapping ErrAgentNotConnected if the agent is offline, or
+// an error if the send fails.
 //
 // Called by the scheduler when it decides a job should run on this agent.
 func (m *Manager) Dispatch(agentID string, job *proto.JobAssignment) error {
@@ -156,7 +181,7 @@ func (m *Manager) Dispatch(agentID string, job *proto.JobAssignment) error {
 	m.mu.RUnlock()
 
 	if !exists {
-		return fmt.Errorf("agent %s is not connected", agentID)
+		return fmt.Errorf("agent %s: %w", agentID, ErrAgentNotConnected)
 	}
 
 	if err := agent.stream.Send(job); err != nil {
@@ -172,6 +197,124 @@ func (m *Manager) Dispatch(agentID string, job *proto.JobAssignment) error {
 	return nil
 }
 
+// Abort asks the agent to stop a running job by sending a JOB_TYPE_ABORT
+// assignment for it on the job stream. The agent kills restic and the hooks;
+// recording the job's outcome is up to the caller.
+func (m *Manager) Abort(agentID, jobID string) error {
+	m.mu.RLock()
+	agent, exists := m.agents[agentID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return ErrAgentNotConnected
+	}
+
+	if err := agent.stream.Send(&proto.JobAssignment{
+		JobId: jobID,
+		Type:  proto.JobType_JOB_TYPE_ABORT,
+	}); err != nil {
+		return fmt.Errorf("failed to send abort for job %s to agent %s: %w", jobID, agentID, err)
+	}
+
+	m.logger.Info("job abort sent to agent",
+		zap.String("job_id", jobID),
+		zap.String("agent_id", agentID),
+	)
+	return nil
+}
+
+// DispatchLimited is like Dispatch but enforces per-destination concurrency
+// limits. limits maps every destination the job writes to onto its maximum
+// number of concurrent jobs (0 = unlimited). If any destination is full,
+// nothing is sent and ErrDestinationBusy is returned; otherwise a slot is
+// reserved on every destination until Release is called for the job.
+//
+// Called by the scheduler for backup jobs. Dispatching a job that already
+// holds slots is a no-op, which protects against double dispatch when the
+// same pending job is picked up by two paths at once.
+func (m *Manager) DispatchLimited(agentID string, job *proto.JobAssignment, limits map[string]int) error {
+	if !m.IsConnected(agentID) {
+		return fmt.Errorf("agent %s: %w", agentID, ErrAgentNotConnected)
+	}
+
+	m.slotsMu.Lock()
+	if _, ok := m.inFlight[job.JobId]; ok {
+		m.slotsMu.Unlock()
+		return nil
+	}
+	for destID, limit := range limits {
+		if limit > 0 && m.destRunning[destID] >= limit {
+			m.slotsMu.Unlock()
+			return fmt.Errorf("destination %s: %w", destID, ErrDestinationBusy)
+		}
+	}
+	destinations := make([]string, 0, len(limits))
+	for destID := range limits {
+		destinations = append(destinations, destID)
+		m.destRunning[destID]++
+	}
+	m.inFlight[job.JobId] = inFlightJob{agentID: agentID, destinations: destinations}
+	m.slotsMu.Unlock()
+
+	if err := m.Dispatch(agentID, job); err != nil {
+		m.Release(job.JobId)
+		return err
+	}
+	return nil
+}
+
+// Release frees the destination slots held by a job dispatched with
+// DispatchLimited. It reports whether the job was in flight, so callers can
+// skip rescheduling work for jobs that never held a slot.
+func (m *Manager) Release(jobID string) bool {
+	m.slotsMu.Lock()
+	defer m.slotsMu.Unlock()
+	return m.releaseLocked(jobID)
+}
+
+// RunningByDestination returns the number of in-flight jobs per destination
+// ID. Destinations without running jobs are omitted.
+func (m *Manager) RunningByDestination() map[string]int {
+	m.slotsMu.Lock()
+	defer m.slotsMu.Unlock()
+
+	result := make(map[string]int, len(m.destRunning))
+	for destID, n := range m.destRunning {
+		result[destID] = n
+	}
+	return result
+}
+
+// releaseAgent frees the slots of every job in flight on agentID and returns
+// how many jobs were released.
+func (m *Manager) releaseAgent(agentID string) int {
+	m.slotsMu.Lock()
+	defer m.slotsMu.Unlock()
+
+	released := 0
+	for jobID, j := range m.inFlight {
+		if j.agentID == agentID && m.releaseLocked(jobID) {
+			released++
+		}
+	}
+	return released
+}
+
+// releaseLocked frees the slots of jobID. slotsMu must be held.
+func (m *Manager) releaseLocked(jobID string) bool {
+	j, ok := m.inFlight[jobID]
+	if !ok {
+		return false
+	}
+	delete(m.inFlight, jobID)
+	for _, destID := range j.destinations {
+		if m.destRunning[destID]--; m.destRunning[destID] <= 0 {
+			delete(m.destRunning, destID)
+		}
+	}
+	return true
+}
+
 // IsConnected reports whether an agent with the given ID currently has
 // an active connection.
 func (m *Manager) IsConnected(agentID string) bool {
@@ -2,6 +2,7 @@ package agentmanager
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"go.uber.org/zap"
@@ -78,3 +79,103 @@ func TestConnectedAgents_ReturnsSnapshot(t *testing.T) {
 		t.Errorf("mutating the snapshot changed registry count to %d, want 2", got)
 	}
 }
+
+// countingStream records the IDs of the jobs sent through it.
+type countingStream struct {
+	mockStream
+	sent []string
+}
+
+func (c *countingStream) Send(job *proto.JobAssignment) error {
+	c.sent = append(c.sent, job.JobId)
+	return nil
+}
+
+func TestDispatch_AgentNotConnected(t *testing.T) {
+	mgr := newTestManager()
+	job := &proto.JobAssignment{JobId: "job-1"}
+
+	if err := mgr.Dispatch("agent-1", job); !errors.Is(err, ErrAgentNotConnected) {
+		t.Errorf("Dispatch on unknown agent error = %v, want ErrAgentNotConnected", err)
+	}
+	if err := mgr.DispatchLimited("agent-1", job, nil); !errors.Is(err, ErrAgentNotConnected) {
+		t.Errorf("DispatchLimited on unknown agent error = %v, want ErrAgentNotConnected", err)
+	}
+}
+
+func TestDispatchLimited_EnforcesDestinationLimit(t *testing.T) {
+	mgr := newTestManager()
+	stream := &countingStream{}
+	mgr.Register("agent-1", "host1", false, stream)
+
+	limits := map[string]int{"dest-1": 1, "dest-2": 0}
+	if err := mgr.DispatchLimited("agent-1", &proto.JobAssignment{JobId: "job-1"}, limits); err != nil {
+		t.Fatalf("DispatchLimited(job-1): %v", err)
+	}
+	err := mgr.DispatchLimited("agent-1", &proto.JobAssignment{JobId: "job-2"}, limits)
+	if !errors.Is(err, ErrDestinationBusy) {
+		t.Fatalf("DispatchLimited(job-2) error = %v, want ErrDestinationBusy", err)
+	}
+	if got := mgr.RunningByDestination(); got["dest-1"] != 1 || got["dest-2"] != 1 {
+		t.Errorf("RunningByDestination() = %v, want one job on each destination", got)
+	}
+
+	if !mgr.Release("job-1") {
+		t.Fatal("Release(job-1) = false, want true")
+	}
+	if err := mgr.DispatchLimited("agent-1", &proto.JobAssignment{JobId: "job-2"}, limits); err != nil {
+		t.Fatalf("DispatchLimited(job-2) after release: %v", err)
+	}
+	if len(stream.sent) != 2 {
+		t.Errorf("sent %d jobs, want 2", len(stream.sent))
+	}
+}
+
+func TestDispatchLimited_IgnoresDuplicateDispatch(t *testing.T) {
+	mgr := newTestManager()
+	stream := &countingStream{}
+	mgr.Register("agent-1", "host1", false, stream)
+
+	limits := map[string]int{"dest-1": 1}
+	for range 2 {
+		if err := mgr.DispatchLimited("agent-1", &proto.JobAssignment{JobId: "job-1"}, limits); err != nil {
+			t.Fatalf("DispatchLimited: %v", err)
+		}
+	}
+	if len(stream.sent) != 1 {
+		t.Errorf("sent %d jobs, want 1", len(stream.sent))
+	}
+}
+
+func TestDeregister_ReleasesSlots(t *testing.T) {
+	mgr := newTestManager()
+	mgr.Register("agent-1", "host1", false, &countingStream{})
+
+	if err := mgr.DispatchLimited("agent-1", &proto.JobAssignment{JobId: "job-1"}, map[string]int{"dest-1": 1}); err != nil {
+		t.Fatalf("DispatchLimited: %v", err)
+	}
+	mgr.Deregister("agent-1")
+
+	if got := mgr.RunningByDestination(); len(got) != 0 {
+		t.Errorf("RunningByDestination() = %v after Deregister, want empty", got)
+	}
+	if mgr.Release("job-1") {
+		t.Error("Release(job-1) = true after Deregister, want false")
+	}
+}
+
+func TestAbort(t *testing.T) {
+	mgr := newTestManager()
+	if err := mgr.Abort("agent-1", "job-1"); !errors.Is(err, ErrAgentNotConnected) {
+		t.Fatalf("Abort on unknown agent error = %v, want ErrAgentNotConnected", err)
+	}
+
+	stream := &countingStream{}
+	mgr.Register("agent-1", "host1", false, stream)
+	if err := mgr.Abort("agent-1", "job-1"); err != nil {
+		t.Fatalf("Abort: %v", err)
+	}
+	if len(stream.sent) != 1 || stream.sent[0] != "job-1" {
+		t.Errorf("sent = %v, want the aborted job ID", stream.sent)
+	}
+}
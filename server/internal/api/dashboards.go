@@ -33,6 +33,7 @@ type dayJobActivityResponse struct {
 	Date      string `json:"date"`       // "YYYY-MM-DD"
 	Succeeded int64  `json:"succeeded"`
 	Failed    int64  `json:"failed"`
+	Partial   int64  `json:"partial"`
 }
 
 // daySizeActivityResponse is the per-day backed-up size for the size chart.
@@ -57,6 +58,7 @@ type dashboardResponse struct {
 	JobsTodayTotal     int64 `json:"jobs_today_total"`
 	JobsTodaySucceeded int64 `json:"jobs_today_succeeded"`
 	JobsTodayFailed    int64 `json:"jobs_today_failed"`
+	JobsTodayPartial   int64 `json:"jobs_today_partial"`
 
 	// Snapshots (all time)
 	SnapshotsTotal     int64 `json:"snapshots_total"`
@@ -88,6 +90,7 @@ func (h *DashboardHandler) Get(w http.ResponseWriter, r *http.Request) {
 			Date:      d.Date,
 			Succeeded: d.Succeeded,
 			Failed:    d.Failed,
+			Partial:   d.Partial,
 		}
 	}
 
@@ -107,6 +110,7 @@ func (h *DashboardHandler) Get(w http.ResponseWriter, r *http.Request) {
 		JobsTodayTotal:     stats.JobsTodayTotal,
 		JobsTodaySucceeded: stats.JobsTodaySucceeded,
 		JobsTodayFailed:    stats.JobsTodayFailed,
+		JobsTodayPartial:   stats.JobsTodayPartial,
 		SnapshotsTotal:     stats.SnapshotsTotal,
 		SnapshotsTotalSize: stats.SnapshotsTotalSize,
 		JobActivity:        jobActivity,
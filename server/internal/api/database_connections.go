@@ -0,0 +1,305 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+	"github.com/arkeep-io/arkeep/server/internal/repositories"
+	"github.com/arkeep-io/arkeep/server/internal/scheduler"
+)
+
+// DatabaseConnectionHandler groups all database connection HTTP handlers. A
+// database connection holds what the agent needs to reach a database server
+// for the database sources that dump it.
+type DatabaseConnectionHandler struct {
+	repo      repositories.DatabaseConnectionRepository
+	auditRepo repositories.AuditRepository
+	logger    *zap.Logger
+}
+
+// NewDatabaseConnectionHandler creates a new DatabaseConnectionHandler.
+func NewDatabaseConnectionHandler(repo repositories.DatabaseConnectionRepository, auditRepo repositories.AuditRepository, logger *zap.Logger) *DatabaseConnectionHandler {
+	return &DatabaseConnectionHandler{
+		repo:      repo,
+		auditRepo: auditRepo,
+		logger:    logger.Named("database_connection_handler"),
+	}
+}
+
+// databaseConnectionResponse is the JSON representation of a database
+// connection. The password is write-only and never returned; HasPassword
+// tells whether one is set.
+type databaseConnectionResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Engine      string `json:"engine"`
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	Username    string `json:"username"`
+	HasPassword bool   `json:"has_password"`
+	PolicyCount int64  `json:"policy_count"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// databaseConnectionToResponse converts a db.DatabaseConnection to a
+// databaseConnectionResponse.
+func databaseConnectionToResponse(c *db.DatabaseConnection, policyCount int64) databaseConnectionResponse {
+	return databaseConnectionResponse{
+		ID:          c.ID.String(),
+		Name:        c.Name,
+		Engine:      c.Engine,
+		Host:        c.Host,
+		Port:        c.Port,
+		Username:    c.Username,
+		HasPassword: c.Password != "",
+		PolicyCount: policyCount,
+		CreatedAt:   c.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   c.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// listDatabaseConnectionsResponse wraps a paginated list of connections.
+type listDatabaseConnectionsResponse struct {
+	Items []databaseConnectionResponse `json:"items"`
+	Total int64                        `json:"total"`
+}
+
+// List handles GET /api/v1/database-connections.
+func (h *DatabaseConnectionHandler) List(w http.ResponseWriter, r *http.Request) {
+	opts := paginationOpts(r)
+
+	conns, total, err := h.repo.List(r.Context(), opts)
+	if err != nil {
+		h.logger.Error("failed to list database connections", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	items := make([]databaseConnectionResponse, len(conns))
+	for i := range conns {
+		count, err := h.repo.CountPolicies(r.Context(), conns[i].ID)
+		if err != nil {
+			h.logger.Error("failed to count database connection policies", zap.String("id", conns[i].ID.String()), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		items[i] = databaseConnectionToResponse(&conns[i], count)
+	}
+
+	Ok(w, listDatabaseConnectionsResponse{Items: items, Total: total})
+}
+
+// createDatabaseConnectionRequest is the JSON body expected by POST
+// /api/v1/database-connections. For SQLite, whose databases are files on
+// the agent, only the name and engine are used. Host and port default to
+// the engine's on the agent's host.
+type createDatabaseConnectionRequest struct {
+	Name     string `json:"name"`
+	Engine   string `json:"engine"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"` // stored encrypted
+}
+
+// Create handles POST /api/v1/database-connections.
+func (h *DatabaseConnectionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req createDatabaseConnectionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Name == "" {
+		ErrBadRequest(w, "name is required")
+		return
+	}
+	if !slices.Contains(scheduler.DatabaseEngines, req.Engine) {
+		ErrBadRequest(w, "engine must be one of: "+strings.Join(scheduler.DatabaseEngines, ", "))
+		return
+	}
+
+	conn := &db.DatabaseConnection{
+		Name:     req.Name,
+		Engine:   req.Engine,
+		Host:     strings.TrimSpace(req.Host),
+		Port:     req.Port,
+		Username: req.Username,
+		Password: db.EncryptedString(req.Password),
+	}
+	if conn.Engine == scheduler.EngineSQLite {
+		conn.Host, conn.Port, conn.Username, conn.Password = "", 0, "", ""
+	}
+	if msg := validateDatabaseConnection(conn); msg != "" {
+		ErrBadRequest(w, msg)
+		return
+	}
+
+	if err := h.repo.Create(r.Context(), conn); err != nil {
+		h.logger.Error("failed to create database connection", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	logAudit(r, h.auditRepo, h.logger, "database_connection.create", "database_connection", conn.ID.String(), map[string]any{"name": conn.Name, "engine": conn.Engine, "host": conn.Host})
+	Created(w, databaseConnectionToResponse(conn, 0))
+}
+
+// validateDatabaseConnection checks the fields of a connection that end up
+// on the command line of the dump tools. It returns the error message, or
+// "" when the connection is valid.
+func validateDatabaseConnection(c *db.DatabaseConnection) string {
+	// A leading dash would be parsed as an option by the dump tools.
+	if strings.HasPrefix(c.Host, "-") || strings.ContainsAny(c.Host, " \t\r\n") {
+		return "host is not a valid host name"
+	}
+	if strings.HasPrefix(c.Username, "-") {
+		return "username cannot start with '-'"
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return "port must be between 0 and 65535"
+	}
+	return ""
+}
+
+// GetByID handles GET /api/v1/database-connections/{id}.
+func (h *DatabaseConnectionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	conn, ok := h.connectionFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := h.repo.CountPolicies(r.Context(), conn.ID)
+	if err != nil {
+		h.logger.Error("failed to count database connection policies", zap.String("id", conn.ID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	Ok(w, databaseConnectionToResponse(conn, count))
+}
+
+// updateDatabaseConnectionRequest is the JSON body for PATCH
+// /api/v1/database-connections/{id}. All fields are optional — only non-nil
+// values are applied. The engine cannot change: the snapshots taken through
+// the connection are tagged with it.
+type updateDatabaseConnectionRequest struct {
+	Name     *string `json:"name"`
+	Host     *string `json:"host"`
+	Port     *int    `json:"port"`
+	Username *string `json:"username"`
+	Password *string `json:"password"`
+}
+
+// Update handles PATCH /api/v1/database-connections/{id}.
+func (h *DatabaseConnectionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req updateDatabaseConnectionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	conn, ok := h.connectionFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			ErrBadRequest(w, "name cannot be empty")
+			return
+		}
+		conn.Name = *req.Name
+	}
+	if conn.Engine != scheduler.EngineSQLite {
+		if req.Host != nil {
+			conn.Host = strings.TrimSpace(*req.Host)
+		}
+		if req.Port != nil {
+			conn.Port = *req.Port
+		}
+		if req.Username != nil {
+			conn.Username = *req.Username
+		}
+		if req.Password != nil {
+			conn.Password = db.EncryptedString(*req.Password)
+		}
+	}
+	if msg := validateDatabaseConnection(conn); msg != "" {
+		ErrBadRequest(w, msg)
+		return
+	}
+
+	if err := h.repo.Update(r.Context(), conn); err != nil {
+		h.logger.Error("failed to update database connection", zap.String("id", conn.ID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	count, err := h.repo.CountPolicies(r.Context(), conn.ID)
+	if err != nil {
+		h.logger.Error("failed to count database connection policies", zap.String("id", conn.ID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	logAudit(r, h.auditRepo, h.logger, "database_connection.update", "database_connection", conn.ID.String(), map[string]any{"name": conn.Name, "password_changed": req.Password != nil})
+	Ok(w, databaseConnectionToResponse(conn, count))
+}
+
+// Delete handles DELETE /api/v1/database-connections/{id}.
+// Returns 409 if a policy still dumps a database through the connection.
+func (h *DatabaseConnectionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	count, err := h.repo.CountPolicies(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to count database connection policies", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if count > 0 {
+		ErrConflict(w, "database connection is still used by one or more policies")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return
+		}
+		h.logger.Error("failed to delete database connection", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	logAudit(r, h.auditRepo, h.logger, "database_connection.delete", "database_connection", id.String(), map[string]any{})
+	NoContent(w)
+}
+
+// connectionFromPath loads the connection named by the {id} URL parameter,
+// writing the error response and returning false when it cannot.
+func (h *DatabaseConnectionHandler) connectionFromPath(w http.ResponseWriter, r *http.Request) (*db.DatabaseConnection, bool) {
+	id, ok := parseUUID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+	conn, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return nil, false
+		}
+		h.logger.Error("failed to get database connection", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return nil, false
+	}
+	return conn, true
+}
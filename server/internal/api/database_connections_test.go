@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+// createDBConnection inserts a database connection record directly.
+func createDBConnection(t *testing.T, deps *testDeps, engine string) *db.DatabaseConnection {
+	t.Helper()
+	c := &db.DatabaseConnection{Name: engine + "-prod", Engine: engine, Host: "db.internal", Username: "backup", Password: "db-secret"}
+	if err := deps.conns.Create(context.Background(), c); err != nil {
+		t.Fatalf("createDBConnection: %v", err)
+	}
+	return c
+}
+
+func TestDatabaseConnectionHandler_Create(t *testing.T) {
+	t.Run("creates connection and returns 201", func(t *testing.T) {
+		e := newTestEnv(t)
+		resp := e.post(t, "/api/v1/database-connections", e.adminToken(t), map[string]any{
+			"name":     "billing",
+			"engine":   "postgres",
+			"host":     "pg.internal",
+			"port":     5433,
+			"username": "backup",
+			"password": "supersecret",
+		})
+		assertStatus(t, resp, http.StatusCreated)
+
+		var data struct {
+			ID          string  `json:"id"`
+			Host        string  `json:"host"`
+			Port        int     `json:"port"`
+			HasPassword bool    `json:"has_password"`
+			Password    *string `json:"password"`
+		}
+		decodeData(t, resp, &data)
+		if data.Host != "pg.internal" || data.Port != 5433 || !data.HasPassword {
+			t.Errorf("connection = %+v", data)
+		}
+		if data.Password != nil {
+			t.Error("password must never be returned")
+		}
+
+		id, _ := uuid.Parse(data.ID)
+		stored, err := e.deps.conns.GetByID(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if string(stored.Password) != "supersecret" {
+			t.Errorf("stored password = %q, want the decrypted password", stored.Password)
+		}
+	})
+
+	t.Run("returns 400 for invalid input", func(t *testing.T) {
+		e := newTestEnv(t)
+		cases := map[string]map[string]any{
+			"missing name":    {"engine": "postgres"},
+			"unknown engine":  {"name": "db", "engine": "oracle"},
+			"option host":     {"name": "db", "engine": "mysql", "host": "--help"},
+			"option username": {"name": "db", "engine": "mysql", "username": "-uroot"},
+			"port range":      {"name": "db", "engine": "redis", "port": 70000},
+		}
+		for name, body := range cases {
+			t.Run(name, func(t *testing.T) {
+				resp := e.post(t, "/api/v1/database-connections", e.adminToken(t), body)
+				assertStatus(t, resp, http.StatusBadRequest)
+			})
+		}
+	})
+}
+
+func TestDatabaseConnectionHandler_Update(t *testing.T) {
+	e := newTestEnv(t)
+	conn := createDBConnection(t, e.deps, "mysql")
+
+	resp := e.patch(t, "/api/v1/database-connections/"+conn.ID.String(), e.adminToken(t), map[string]any{
+		"host":     "mariadb.internal",
+		"password": "rotated",
+	})
+	assertStatus(t, resp, http.StatusOK)
+
+	stored, err := e.deps.conns.GetByID(context.Background(), conn.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Host != "mariadb.internal" || string(stored.Password) != "rotated" || stored.Username != "backup" {
+		t.Errorf("connection = %+v", stored)
+	}
+}
+
+func TestDatabaseConnectionHandler_Delete(t *testing.T) {
+	t.Run("returns 409 while a policy dumps through the connection", func(t *testing.T) {
+		e := newTestEnv(t)
+		conn := createDBConnection(t, e.deps, "postgres")
+		p := createDBPolicy(t, e.deps, "db", uuid.New())
+		p.Sources = `[{"type":"database","connection_id":"` + conn.ID.String() + `","database":"app"}]`
+		if err := e.deps.policies.Update(context.Background(), p); err != nil {
+			t.Fatalf("Update policy: %v", err)
+		}
+
+		resp := e.del(t, "/api/v1/database-connections/"+conn.ID.String(), e.adminToken(t))
+		assertStatus(t, resp, http.StatusConflict)
+	})
+
+	t.Run("deletes an unused connection", func(t *testing.T) {
+		e := newTestEnv(t)
+		conn := createDBConnection(t, e.deps, "postgres")
+
+		resp := e.del(t, "/api/v1/database-connections/"+conn.ID.String(), e.adminToken(t))
+		assertStatus(t, resp, http.StatusNoContent)
+		assertStatus(t, e.get(t, "/api/v1/database-connections/"+conn.ID.String(), e.adminToken(t)), http.StatusNotFound)
+	})
+}
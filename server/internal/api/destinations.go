@@ -31,25 +31,27 @@ func NewDestinationHandler(repo repositories.DestinationRepository, auditRepo re
 // Credentials are intentionally omitted from all responses — they are
 // write-only and never returned to the client after creation.
 type destinationResponse struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Type      string `json:"type"`
-	Config    string `json:"config"`
-	Enabled   bool   `json:"enabled"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	Type              string `json:"type"`
+	Config            string `json:"config"`
+	Enabled           bool   `json:"enabled"`
+	MaxConcurrentJobs int    `json:"max_concurrent_jobs"` // 0 = unlimited
+	CreatedAt         string `json:"created_at"`
+	UpdatedAt         string `json:"updated_at"`
 }
 
 // destinationToResponse converts a db.Destination to a destinationResponse.
 func destinationToResponse(d *db.Destination) destinationResponse {
 	return destinationResponse{
-		ID:        d.ID.String(),
-		Name:      d.Name,
-		Type:      d.Type,
-		Config:    d.Config,
-		Enabled:   d.Enabled,
-		CreatedAt: d.CreatedAt.UTC().Format(time.RFC3339),
-		UpdatedAt: d.UpdatedAt.UTC().Format(time.RFC3339),
+		ID:                d.ID.String(),
+		Name:              d.Name,
+		Type:              d.Type,
+		Config:            d.Config,
+		Enabled:           d.Enabled,
+		MaxConcurrentJobs: d.MaxConcurrentJobs,
+		CreatedAt:         d.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:         d.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 }
 
@@ -93,10 +95,11 @@ func (h *DestinationHandler) List(w http.ResponseWriter, r *http.Request) {
 // by EncryptedString — the handler stores it as plain text and the DB layer
 // handles encryption transparently.
 type createDestinationRequest struct {
-	Name        string `json:"name"`
-	Type        string `json:"type"`
-	Credentials string `json:"credentials"` // JSON, stored encrypted
-	Config      string `json:"config"`      // JSON, not sensitive
+	Name              string `json:"name"`
+	Type              string `json:"type"`
+	Credentials       string `json:"credentials"`         // JSON, stored encrypted
+	Config            string `json:"config"`              // JSON, not sensitive
+	MaxConcurrentJobs int    `json:"max_concurrent_jobs"` // 0 = unlimited
 }
 
 // Create handles POST /api/v1/destinations.
@@ -117,13 +120,18 @@ func (h *DestinationHandler) Create(w http.ResponseWriter, r *http.Request) {
 	if req.Config == "" {
 		req.Config = "{}"
 	}
+	if req.MaxConcurrentJobs < 0 {
+		ErrBadRequest(w, "max_concurrent_jobs cannot be negative")
+		return
+	}
 
 	dest := &db.Destination{
-		Name:        req.Name,
-		Type:        req.Type,
-		Credentials: db.EncryptedString(req.Credentials),
-		Config:      req.Config,
-		Enabled:     true,
+		Name:              req.Name,
+		Type:              req.Type,
+		Credentials:       db.EncryptedString(req.Credentials),
+		Config:            req.Config,
+		Enabled:           true,
+		MaxConcurrentJobs: req.MaxConcurrentJobs,
 	}
 
 	if err := h.repo.Create(r.Context(), dest); err != nil {
@@ -160,10 +168,11 @@ func (h *DestinationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 // updateDestinationRequest is the JSON body for PATCH /api/v1/destinations/{id}.
 // All fields are optional — only non-nil values are applied.
 type updateDestinationRequest struct {
-	Name        *string `json:"name"`
-	Credentials *string `json:"credentials"`
-	Config      *string `json:"config"`
-	Enabled     *bool   `json:"enabled"`
+	Name              *string `json:"name"`
+	Credentials       *string `json:"credentials"`
+	Config            *string `json:"config"`
+	Enabled           *bool   `json:"enabled"`
+	MaxConcurrentJobs *int    `json:"max_concurrent_jobs"`
 }
 
 // Update handles PATCH /api/v1/destinations/{id}.
@@ -205,6 +214,13 @@ func (h *DestinationHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.Enabled != nil {
 		dest.Enabled = *req.Enabled
 	}
+	if req.MaxConcurrentJobs != nil {
+		if *req.MaxConcurrentJobs < 0 {
+			ErrBadRequest(w, "max_concurrent_jobs cannot be negative")
+			return
+		}
+		dest.MaxConcurrentJobs = *req.MaxConcurrentJobs
+	}
 
 	if err := h.repo.Update(r.Context(), dest); err != nil {
 		h.logger.Error("failed to update destination", zap.String("id", id.String()), zap.Error(err))
@@ -238,4 +254,4 @@ func (h *DestinationHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	logAudit(r, h.auditRepo, h.logger, "destination.delete", "destination", id.String(), map[string]any{})
 	NoContent(w)
-}
\ No newline at end of file
+}
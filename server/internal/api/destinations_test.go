@@ -185,6 +185,34 @@ func TestDestinationHandler_Update(t *testing.T) {
 		}
 	})
 
+	t.Run("sets max concurrent jobs", func(t *testing.T) {
+		e := newTestEnv(t)
+		dest := createDBDestination(t, e.deps, "minio", "s3")
+
+		resp := e.patch(t, "/api/v1/destinations/"+dest.ID.String(), e.adminToken(t), map[string]any{
+			"max_concurrent_jobs": 3,
+		})
+		assertStatus(t, resp, http.StatusOK)
+
+		var data struct {
+			MaxConcurrentJobs int `json:"max_concurrent_jobs"`
+		}
+		decodeData(t, resp, &data)
+		if data.MaxConcurrentJobs != 3 {
+			t.Errorf("max_concurrent_jobs = %d, want 3", data.MaxConcurrentJobs)
+		}
+	})
+
+	t.Run("returns 400 for negative max concurrent jobs", func(t *testing.T) {
+		e := newTestEnv(t)
+		dest := createDBDestination(t, e.deps, "minio", "s3")
+
+		resp := e.patch(t, "/api/v1/destinations/"+dest.ID.String(), e.adminToken(t), map[string]any{
+			"max_concurrent_jobs": -1,
+		})
+		assertStatus(t, resp, http.StatusBadRequest)
+	})
+
 	t.Run("returns 404 for non-existent destination", func(t *testing.T) {
 		e := newTestEnv(t)
 		name := "x"
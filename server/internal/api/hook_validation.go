@@ -2,10 +2,37 @@ package api
 
 import (
 	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/arkeep-io/arkeep/server/internal/scheduler"
+)
+
+const (
+	hookMaxLen = 1024
+
+	// hookMaxSteps caps the number of steps of one hook phase.
+	hookMaxSteps = 20
+
+	// hookMaxTimeout is the longest timeout a hook step may set: one day.
+	hookMaxTimeout = 86400
 )
 
-const hookMaxLen = 1024
+var (
+	// hookEnvNameRe matches the names of the environment variables a hook
+	// step may set.
+	hookEnvNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	// hookRunAsRe matches a user name or numeric uid for run_as.
+	hookRunAsRe = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+
+	// hookContextVarRe matches whole references to the job context
+	// variables, so that $ARKEEP_JOB_ID_SECRET is not taken for one.
+	hookContextVarRe = regexp.MustCompile(`\$(\{(` + strings.Join(scheduler.HookContextVars, "|") + `)\}|(` + strings.Join(scheduler.HookContextVars, "|") + `)\b)`)
+)
 
 // validateHookCommand validates a hook shell command before it is persisted.
 // Hooks are executed on the agent via /bin/sh -c "<command>" (or cmd /C on
@@ -15,7 +42,8 @@ const hookMaxLen = 1024
 // Blocked patterns:
 //   - Sensitive environment variable references ($RESTIC_*, $RCLONE_*, $ARKEEP_*)
 //     which would expose encrypted repository passwords or cloud credentials
-//     to the hook process.
+//     to the hook process. The job context variables the agent sets for hooks
+//     (scheduler.HookContextVars, e.g. $ARKEEP_JOB_ID) are allowed.
 //   - Command substitution ($(...) and backticks), which can capture and
 //     exfiltrate the values of those environment variables.
 //   - Path traversal sequences (..) to prevent hooks from referencing files
@@ -47,6 +75,7 @@ func validateHookCommand(cmd string) error {
 	// Checked against the uppercase form to match both $RESTIC_PASSWORD and
 	// the unlikely but possible ${restic_password} variant.
 	upper := strings.ToUpper(cmd)
+	upper = hookContextVarRe.ReplaceAllString(upper, "")
 	for _, prefix := range []string{"$RESTIC_", "${RESTIC_", "$RCLONE_", "${RCLONE_", "$ARKEEP_", "${ARKEEP_"} {
 		if strings.Contains(upper, prefix) {
 			return errors.New("hook command must not reference internal environment variables ($RESTIC_*, $RCLONE_*, $ARKEEP_*)")
@@ -54,3 +83,74 @@ func validateHookCommand(cmd string) error {
 	}
 	return nil
 }
+
+// validateHooks validates the hook steps of a policy before they are
+// persisted. Each command and argument is held to validateHookCommand, since
+// the agent runs them through the shell. Step environments must not override
+// the variables of restic, rclone or the agent, which would redirect the
+// backup or leak its credentials.
+func validateHooks(hooks scheduler.Hooks) error {
+	for phase, steps := range hooks {
+		if !slices.Contains(scheduler.HookPhases, phase) {
+			return fmt.Errorf("unknown hook phase %q (valid: %s)", phase, strings.Join(scheduler.HookPhases, ", "))
+		}
+		if len(steps) > hookMaxSteps {
+			return fmt.Errorf("%s: at most %d steps are allowed", phase, hookMaxSteps)
+		}
+		for i, step := range steps {
+			if err := validateHookStep(step); err != nil {
+				return fmt.Errorf("%s[%d]: %w", phase, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateHookStep(step scheduler.HookStep) error {
+	if strings.TrimSpace(step.Command) == "" {
+		return errors.New("command is required")
+	}
+	if len(step.Name) > 100 {
+		return errors.New("name must not exceed 100 characters")
+	}
+	if err := validateHookCommand(step.Command); err != nil {
+		return err
+	}
+	for _, arg := range step.Args {
+		if err := validateHookCommand(arg); err != nil {
+			return fmt.Errorf("args: %w", err)
+		}
+	}
+	for name, value := range step.Env {
+		if !hookEnvNameRe.MatchString(name) {
+			return fmt.Errorf("env: invalid variable name %q", name)
+		}
+		upper := strings.ToUpper(name)
+		for _, prefix := range []string{"RESTIC_", "RCLONE_", "ARKEEP_"} {
+			if strings.HasPrefix(upper, prefix) {
+				return fmt.Errorf("env: %s must not set internal environment variables (RESTIC_*, RCLONE_*, ARKEEP_*)", name)
+			}
+		}
+		if err := validateHookCommand(value); err != nil {
+			return fmt.Errorf("env %s: %w", name, err)
+		}
+	}
+	if step.WorkingDir != "" {
+		// Accept Windows drive paths as well as Unix ones, the agent's
+		// platform is not known here.
+		isWindowsAbs := len(step.WorkingDir) >= 3 && step.WorkingDir[1] == ':' && (step.WorkingDir[2] == '\\' || step.WorkingDir[2] == '/')
+		if !path.IsAbs(step.WorkingDir) && !isWindowsAbs {
+			return errors.New("working_dir must be an absolute path")
+		}
+		if strings.Contains(step.WorkingDir, "..") {
+			return errors.New("working_dir must not contain path traversal sequences (..)")
+		}
+	}
+	if step.RunAs != "" && (len(step.RunAs) > 64 || !hookRunAsRe.MatchString(step.RunAs)) {
+		return errors.New("run_as must be a user name or numeric uid")
+	}
+	if step.TimeoutSecs < 0 || step.TimeoutSecs > hookMaxTimeout {
+		return fmt.Errorf("timeout_secs must be between 0 and %d", hookMaxTimeout)
+	}
+	return nil
+}
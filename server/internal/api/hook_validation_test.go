@@ -3,6 +3,8 @@ package api
 import (
 	"strings"
 	"testing"
+
+	"github.com/arkeep-io/arkeep/server/internal/scheduler"
 )
 
 func TestValidateHookCommand(t *testing.T) {
@@ -122,6 +124,24 @@ func TestValidateHookCommand(t *testing.T) {
 		}
 	})
 
+	t.Run("allows the job context variables", func(t *testing.T) {
+		cmds := []string{
+			"/opt/notify.sh $ARKEEP_JOB_ID $ARKEEP_JOB_STATUS",
+			"echo ${ARKEEP_SNAPSHOT_ID} >> /var/log/backups.log",
+			"test \"$ARKEEP_HOOK_PHASE\" = on_failure && /opt/page.sh",
+		}
+		for _, cmd := range cmds {
+			if err := validateHookCommand(cmd); err != nil {
+				t.Errorf("command %q rejected: %v", cmd, err)
+			}
+		}
+		for _, cmd := range []string{"echo $ARKEEP_JOB_ID_SECRET", "echo $ARKEEP_JOB_ID$ARKEEP_AGENT_TOKEN"} {
+			if err := validateHookCommand(cmd); err == nil {
+				t.Errorf("ARKEEP_ env var reference not blocked: %q", cmd)
+			}
+		}
+	})
+
 	t.Run("error messages identify the blocked pattern", func(t *testing.T) {
 		errCases := []struct {
 			cmd     string
@@ -143,3 +163,36 @@ func TestValidateHookCommand(t *testing.T) {
 		}
 	})
 }
+
+func TestValidateHooks(t *testing.T) {
+	valid := scheduler.Hooks{
+		scheduler.HookPreBackup: {
+			{Name: "stop app", Command: "systemctl stop myapp", TimeoutSecs: 120},
+			{Command: "/opt/flush.sh", Args: []string{"--fast"}, Env: map[string]string{"PGHOST": "localhost"}, WorkingDir: "/opt", RunAs: "postgres", ContinueOnError: true},
+		},
+		scheduler.HookOnFailure: {{Command: "/opt/notify.sh", WorkingDir: `C:\scripts`, RunAs: "1001"}},
+	}
+	if err := validateHooks(valid); err != nil {
+		t.Fatalf("valid hooks rejected: %v", err)
+	}
+
+	cases := map[string]scheduler.Hooks{
+		"unknown phase":       {"before_backup": {{Command: "sync"}}},
+		"empty command":       {scheduler.HookPreBackup: {{Command: "  "}}},
+		"substitution in arg": {scheduler.HookPreBackup: {{Command: "sync", Args: []string{"$(id)"}}}},
+		"invalid env name":    {scheduler.HookPreBackup: {{Command: "sync", Env: map[string]string{"MY-VAR": "x"}}}},
+		"restic env":          {scheduler.HookPreBackup: {{Command: "sync", Env: map[string]string{"RESTIC_REPOSITORY": "/tmp/repo"}}}},
+		"arkeep env":          {scheduler.HookPreBackup: {{Command: "sync", Env: map[string]string{"ARKEEP_JOB_STATUS": "success"}}}},
+		"relative dir":        {scheduler.HookPreBackup: {{Command: "sync", WorkingDir: "scripts"}}},
+		"traversal in dir":    {scheduler.HookPreBackup: {{Command: "sync", WorkingDir: "/opt/../etc"}}},
+		"invalid run_as":      {scheduler.HookPreBackup: {{Command: "sync", RunAs: "root; id"}}},
+		"negative timeout":    {scheduler.HookPreBackup: {{Command: "sync", TimeoutSecs: -1}}},
+		"timeout too long":    {scheduler.HookPreBackup: {{Command: "sync", TimeoutSecs: hookMaxTimeout + 1}}},
+		"too many steps":      {scheduler.HookPreBackup: make([]scheduler.HookStep, hookMaxSteps+1)},
+	}
+	for name, hooks := range cases {
+		if err := validateHooks(hooks); err == nil {
+			t.Errorf("%s: validateHooks accepted %+v", name, hooks)
+		}
+	}
+}
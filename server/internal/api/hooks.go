@@ -0,0 +1,437 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+	"github.com/arkeep-io/arkeep/server/internal/repositories"
+	"github.com/arkeep-io/arkeep/server/internal/scheduler"
+)
+
+const (
+	// triggerTokenBytes is the number of random bytes in a trigger token and
+	// in a signing secret (hex-encoded, so 64 characters).
+	triggerTokenBytes = 32
+
+	// triggerTokenPrefixLen is the number of leading token characters kept in
+	// clear for display, so admins can tell tokens apart.
+	triggerTokenPrefixLen = 8
+
+	// triggerSignatureHeader carries the HMAC-SHA256 signature of the request
+	// body as "sha256=<hex>", the same format used for outbound webhooks.
+	triggerSignatureHeader = "X-Arkeep-Signature"
+
+	// maxTriggerBodyBytes bounds the body read to verify a signature.
+	maxTriggerBodyBytes = 64 << 10
+)
+
+// TriggerTokenHandler groups the trigger token management endpoints and the
+// public inbound webhook that starts a policy with a token.
+type TriggerTokenHandler struct {
+	repo       repositories.TriggerTokenRepository
+	policyRepo repositories.PolicyRepository
+	scheduler  *scheduler.Scheduler
+	auditRepo  repositories.AuditRepository
+	logger     *zap.Logger
+	// tokenLimiter stops a misbehaving pipeline from flooding the scheduler
+	// with jobs. Token guessing is limited per IP by the router.
+	tokenLimiter *RateLimiter
+}
+
+// NewTriggerTokenHandler creates a new TriggerTokenHandler.
+func NewTriggerTokenHandler(repo repositories.TriggerTokenRepository, policyRepo repositories.PolicyRepository, sched *scheduler.Scheduler, auditRepo repositories.AuditRepository, logger *zap.Logger) *TriggerTokenHandler {
+	return &TriggerTokenHandler{
+		repo:         repo,
+		policyRepo:   policyRepo,
+		scheduler:    sched,
+		auditRepo:    auditRepo,
+		logger:       logger.Named("trigger_token_handler"),
+		tokenLimiter: NewRateLimiter(5, time.Minute),
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Response types
+// -----------------------------------------------------------------------------
+
+// triggerTokenResponse is the JSON representation of a trigger token. The raw
+// token and signing secret are never returned here — only once, on creation
+// and rotation, via triggerTokenSecretResponse.
+type triggerTokenResponse struct {
+	ID          string  `json:"id"`
+	PolicyID    string  `json:"policy_id"`
+	Name        string  `json:"name"`
+	TokenPrefix string  `json:"token_prefix"`
+	Signed      bool    `json:"signed"`
+	LastUsedAt  *string `json:"last_used_at"`
+	RevokedAt   *string `json:"revoked_at"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+// triggerTokenSecretResponse is returned when a token is created or rotated.
+// It is the only time the raw token and signing secret are shown.
+type triggerTokenSecretResponse struct {
+	triggerTokenResponse
+	Token         string `json:"token"`
+	URL           string `json:"url"` // path of the webhook, relative to the server root
+	SigningSecret string `json:"signing_secret,omitempty"`
+}
+
+func triggerTokenToResponse(t *db.TriggerToken) triggerTokenResponse {
+	resp := triggerTokenResponse{
+		ID:          t.ID.String(),
+		PolicyID:    t.PolicyID.String(),
+		Name:        t.Name,
+		TokenPrefix: t.TokenPrefix,
+		Signed:      t.SigningSecret != "",
+		CreatedAt:   t.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if t.LastUsedAt != nil {
+		s := t.LastUsedAt.UTC().Format(time.RFC3339)
+		resp.LastUsedAt = &s
+	}
+	if t.RevokedAt != nil {
+		s := t.RevokedAt.UTC().Format(time.RFC3339)
+		resp.RevokedAt = &s
+	}
+	return resp
+}
+
+// -----------------------------------------------------------------------------
+// Management handlers
+// -----------------------------------------------------------------------------
+
+// List handles GET /api/v1/policies/{id}/trigger-tokens.
+// Revoked tokens are included so their history stays visible.
+func (h *TriggerTokenHandler) List(w http.ResponseWriter, r *http.Request) {
+	policyID, ok := parseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if _, err := h.policyRepo.GetByID(r.Context(), policyID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return
+		}
+		h.logger.Error("failed to get policy", zap.String("policy_id", policyID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	tokens, err := h.repo.ListByPolicy(r.Context(), policyID)
+	if err != nil {
+		h.logger.Error("failed to list trigger tokens", zap.String("policy_id", policyID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	items := make([]triggerTokenResponse, len(tokens))
+	for i := range tokens {
+		items[i] = triggerTokenToResponse(&tokens[i])
+	}
+	Ok(w, items)
+}
+
+// createTriggerTokenRequest is the JSON body for
+// POST /api/v1/policies/{id}/trigger-tokens.
+type createTriggerTokenRequest struct {
+	Name   string `json:"name"`
+	Signed bool   `json:"signed"` // generate a signing secret and require signed requests
+}
+
+// Create handles POST /api/v1/policies/{id}/trigger-tokens.
+// The raw token (and signing secret, if requested) is returned only once.
+func (h *TriggerTokenHandler) Create(w http.ResponseWriter, r *http.Request) {
+	policyID, ok := parseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req createTriggerTokenRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		ErrBadRequest(w, "name is required")
+		return
+	}
+
+	if _, err := h.policyRepo.GetByID(r.Context(), policyID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return
+		}
+		h.logger.Error("failed to get policy", zap.String("policy_id", policyID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	createdBy := uuid.Nil
+	if claims := claimsFromCtx(r.Context()); claims != nil {
+		createdBy, _ = uuid.Parse(claims.UserID)
+	}
+
+	raw, secret, err := generateTriggerSecrets(req.Signed)
+	if err != nil {
+		h.logger.Error("failed to generate trigger token", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	token := &db.TriggerToken{
+		PolicyID:      policyID,
+		Name:          req.Name,
+		TokenHash:     hashTriggerToken(raw),
+		TokenPrefix:   raw[:triggerTokenPrefixLen],
+		SigningSecret: db.EncryptedString(secret),
+		CreatedBy:     createdBy,
+	}
+	if err := h.repo.Create(r.Context(), token); err != nil {
+		h.logger.Error("failed to create trigger token", zap.String("policy_id", policyID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	logAudit(r, h.auditRepo, h.logger, "trigger_token.create", "policy", policyID.String(), map[string]any{"token_id": token.ID.String(), "name": token.Name, "signed": req.Signed})
+	Created(w, triggerTokenSecretResponse{
+		triggerTokenResponse: triggerTokenToResponse(token),
+		Token:                raw,
+		URL:                  "/api/v1/hooks/trigger/" + raw,
+		SigningSecret:        secret,
+	})
+}
+
+// Rotate handles POST /api/v1/policies/{id}/trigger-tokens/{tokenID}/rotate.
+// The previous token value stops working immediately. A signed token also
+// gets a new signing secret.
+func (h *TriggerTokenHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	token, ok := h.tokenFromPath(w, r)
+	if !ok {
+		return
+	}
+	if token.RevokedAt != nil {
+		ErrConflict(w, "trigger token is revoked")
+		return
+	}
+
+	raw, secret, err := generateTriggerSecrets(token.SigningSecret != "")
+	if err != nil {
+		h.logger.Error("failed to generate trigger token", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if err := h.repo.Rotate(r.Context(), token.ID, hashTriggerToken(raw), raw[:triggerTokenPrefixLen], db.EncryptedString(secret)); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrConflict(w, "trigger token is revoked")
+			return
+		}
+		h.logger.Error("failed to rotate trigger token", zap.String("token_id", token.ID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	token.TokenPrefix = raw[:triggerTokenPrefixLen]
+	token.SigningSecret = db.EncryptedString(secret)
+
+	logAudit(r, h.auditRepo, h.logger, "trigger_token.rotate", "policy", token.PolicyID.String(), map[string]any{"token_id": token.ID.String(), "name": token.Name})
+	Ok(w, triggerTokenSecretResponse{
+		triggerTokenResponse: triggerTokenToResponse(token),
+		Token:                raw,
+		URL:                  "/api/v1/hooks/trigger/" + raw,
+		SigningSecret:        secret,
+	})
+}
+
+// Revoke handles DELETE /api/v1/policies/{id}/trigger-tokens/{tokenID}.
+// The token is kept (revoked) so that audit entries attributed to it still
+// resolve to a name.
+func (h *TriggerTokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	token, ok := h.tokenFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Revoke(r.Context(), token.ID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			// Already revoked — the desired state is met.
+			NoContent(w)
+			return
+		}
+		h.logger.Error("failed to revoke trigger token", zap.String("token_id", token.ID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	logAudit(r, h.auditRepo, h.logger, "trigger_token.revoke", "policy", token.PolicyID.String(), map[string]any{"token_id": token.ID.String(), "name": token.Name})
+	NoContent(w)
+}
+
+// tokenFromPath loads the token identified by {tokenID} and checks that it
+// belongs to the policy {id}. Writes the error response and returns false on
+// failure.
+func (h *TriggerTokenHandler) tokenFromPath(w http.ResponseWriter, r *http.Request) (*db.TriggerToken, bool) {
+	policyID, ok := parseUUID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+	tokenID, ok := parseUUID(w, r, "tokenID")
+	if !ok {
+		return nil, false
+	}
+
+	token, err := h.repo.GetByID(r.Context(), tokenID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return nil, false
+		}
+		h.logger.Error("failed to get trigger token", zap.String("token_id", tokenID.String()), zap.Error(err))
+		ErrInternal(w)
+		return nil, false
+	}
+	if token.PolicyID != policyID {
+		ErrNotFound(w)
+		return nil, false
+	}
+	return token, true
+}
+
+// -----------------------------------------------------------------------------
+// Inbound webhook
+// -----------------------------------------------------------------------------
+
+// Trigger handles POST /api/v1/hooks/trigger/{token}.
+// Starts an immediate backup of the token's policy without a user session.
+// Unknown and revoked tokens both return 404 so that the response does not
+// reveal which tokens ever existed. When the token has a signing secret the
+// body must be signed with HMAC-SHA256 in the X-Arkeep-Signature header.
+// The audit entry is attributed to the token rather than to a user.
+func (h *TriggerTokenHandler) Trigger(w http.ResponseWriter, r *http.Request) {
+	token, err := h.repo.GetByHash(r.Context(), hashTriggerToken(chi.URLParam(r, "token")))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return
+		}
+		h.logger.Error("failed to look up trigger token", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if token.RevokedAt != nil {
+		ErrNotFound(w)
+		return
+	}
+
+	if token.SigningSecret != "" {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxTriggerBodyBytes))
+		if err != nil {
+			ErrBadRequest(w, "failed to read request body")
+			return
+		}
+		if !validTriggerSignature(body, r.Header.Get(triggerSignatureHeader), string(token.SigningSecret)) {
+			h.logger.Warn("trigger rejected: invalid signature",
+				zap.String("token_id", token.ID.String()),
+				zap.String("ip", clientIP(r)),
+			)
+			ErrUnauthorized(w)
+			return
+		}
+	}
+
+	if !h.tokenLimiter.Allow(token.ID.String()) {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	job, err := h.scheduler.TriggerNow(r.Context(), token.PolicyID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return
+		}
+		if errors.Is(err, scheduler.ErrPolicyDisabled) {
+			ErrConflict(w, "policy is disabled")
+			return
+		}
+		h.logger.Error("failed to trigger policy",
+			zap.String("policy_id", token.PolicyID.String()),
+			zap.String("token_id", token.ID.String()),
+			zap.Error(err),
+		)
+		ErrInternal(w)
+		return
+	}
+
+	if err := h.repo.TouchLastUsed(r.Context(), token.ID, time.Now().UTC()); err != nil {
+		h.logger.Warn("failed to record trigger token use", zap.String("token_id", token.ID.String()), zap.Error(err))
+	}
+
+	logAuditDirect(r, h.auditRepo, h.logger, token.ID, "trigger-token:"+token.Name, "policy.trigger", "policy", token.PolicyID.String(), map[string]any{
+		"job_id":             job.ID.String(),
+		"trigger_token_id":   token.ID.String(),
+		"trigger_token_name": token.Name,
+	})
+	Ok(w, map[string]string{"job_id": job.ID.String()})
+}
+
+// -----------------------------------------------------------------------------
+// Helpers
+// -----------------------------------------------------------------------------
+
+// generateTriggerSecrets returns a new random token and, when signed is true,
+// a new random signing secret (empty otherwise).
+func generateTriggerSecrets(signed bool) (token, secret string, err error) {
+	if token, err = randomHex(triggerTokenBytes); err != nil {
+		return "", "", err
+	}
+	if signed {
+		if secret, err = randomHex(triggerTokenBytes); err != nil {
+			return "", "", err
+		}
+	}
+	return token, secret, nil
+}
+
+// randomHex returns n cryptographically random bytes, hex-encoded.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashTriggerToken returns the SHA-256 hex digest of a raw trigger token.
+// Only the hash is stored in the database.
+func hashTriggerToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// validTriggerSignature reports whether header is "sha256=<hex>" with the
+// HMAC-SHA256 of body under secret. The comparison is constant-time.
+func validTriggerSignature(body []byte, header, secret string) bool {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/arkeep-io/arkeep/server/internal/repositories"
+)
+
+// triggerTokenCreated mirrors the create/rotate response.
+type triggerTokenCreated struct {
+	ID            string `json:"id"`
+	Token         string `json:"token"`
+	URL           string `json:"url"`
+	SigningSecret string `json:"signing_secret"`
+	Signed        bool   `json:"signed"`
+}
+
+func createTriggerToken(t *testing.T, e *testEnv, policyID uuid.UUID, signed bool) triggerTokenCreated {
+	t.Helper()
+	resp := e.post(t, "/api/v1/policies/"+policyID.String()+"/trigger-tokens", e.adminToken(t), map[string]any{
+		"name":   "ci",
+		"signed": signed,
+	})
+	assertStatus(t, resp, http.StatusCreated)
+	var data triggerTokenCreated
+	decodeData(t, resp, &data)
+	return data
+}
+
+// postHook sends body to the trigger webhook, signed with secret if non-empty.
+func postHook(t *testing.T, e *testEnv, url string, body []byte, secret string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, e.URL+url, bytes.NewReader(body))
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		req.Header.Set("X-Arkeep-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	return resp
+}
+
+func TestTriggerTokenHandler_Trigger(t *testing.T) {
+	t.Run("starts the policy and audits the token", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+		tok := createTriggerToken(t, e, policy.ID, false)
+		if tok.URL != "/api/v1/hooks/trigger/"+tok.Token {
+			t.Errorf("url = %q, want the webhook path", tok.URL)
+		}
+
+		resp := postHook(t, e, tok.URL, nil, "")
+		assertStatus(t, resp, http.StatusOK)
+		var data struct {
+			JobID string `json:"job_id"`
+		}
+		decodeData(t, resp, &data)
+		jobID, err := uuid.Parse(data.JobID)
+		if err != nil {
+			t.Fatalf("job_id = %q, want a UUID", data.JobID)
+		}
+		if _, err := e.deps.jobs.GetByID(context.Background(), jobID); err != nil {
+			t.Errorf("job %s not created: %v", jobID, err)
+		}
+
+		tokenID := uuid.MustParse(tok.ID)
+		entries, _, err := e.deps.audit.List(context.Background(), repositories.AuditFilter{UserID: &tokenID}, repositories.ListOptions{Limit: 10})
+		if err != nil {
+			t.Fatalf("audit List: %v", err)
+		}
+		if len(entries) != 1 || entries[0].Action != "policy.trigger" || entries[0].UserEmail != "trigger-token:ci" {
+			t.Errorf("audit entries = %+v, want one policy.trigger by trigger-token:ci", entries)
+		}
+	})
+
+	t.Run("returns 404 for unknown token", func(t *testing.T) {
+		e := newTestEnv(t)
+		resp := postHook(t, e, "/api/v1/hooks/trigger/not-a-token", nil, "")
+		assertStatus(t, resp, http.StatusNotFound)
+	})
+
+	t.Run("verifies the signature of signed tokens", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+		tok := createTriggerToken(t, e, policy.ID, true)
+		if !tok.Signed || tok.SigningSecret == "" {
+			t.Fatalf("signed token = %+v, want a signing secret", tok)
+		}
+		body := []byte(`{"ref":"main"}`)
+
+		resp := postHook(t, e, tok.URL, body, "")
+		assertStatus(t, resp, http.StatusUnauthorized)
+		resp = postHook(t, e, tok.URL, body, "wrong-secret")
+		assertStatus(t, resp, http.StatusUnauthorized)
+		resp = postHook(t, e, tok.URL, body, tok.SigningSecret)
+		assertStatus(t, resp, http.StatusOK)
+	})
+
+	t.Run("rotation invalidates the previous token", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+		tok := createTriggerToken(t, e, policy.ID, false)
+
+		resp := e.post(t, "/api/v1/policies/"+policy.ID.String()+"/trigger-tokens/"+tok.ID+"/rotate", e.adminToken(t), nil)
+		assertStatus(t, resp, http.StatusOK)
+		var rotated triggerTokenCreated
+		decodeData(t, resp, &rotated)
+		if rotated.ID != tok.ID || rotated.Token == tok.Token {
+			t.Fatalf("rotated = %+v, want same id with a new token", rotated)
+		}
+
+		resp = postHook(t, e, tok.URL, nil, "")
+		assertStatus(t, resp, http.StatusNotFound)
+		resp = postHook(t, e, rotated.URL, nil, "")
+		assertStatus(t, resp, http.StatusOK)
+	})
+
+	t.Run("revoked token returns 404", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+		tok := createTriggerToken(t, e, policy.ID, false)
+
+		resp := e.del(t, "/api/v1/policies/"+policy.ID.String()+"/trigger-tokens/"+tok.ID, e.adminToken(t))
+		assertStatus(t, resp, http.StatusNoContent)
+
+		resp = postHook(t, e, tok.URL, nil, "")
+		assertStatus(t, resp, http.StatusNotFound)
+
+		resp = e.get(t, "/api/v1/policies/"+policy.ID.String()+"/trigger-tokens", e.adminToken(t))
+		assertStatus(t, resp, http.StatusOK)
+		var list []struct {
+			RevokedAt *string `json:"revoked_at"`
+		}
+		decodeData(t, resp, &list)
+		if len(list) != 1 || list[0].RevokedAt == nil {
+			t.Errorf("tokens = %+v, want one revoked token", list)
+		}
+	})
+
+	t.Run("rate limits each token", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+		tok := createTriggerToken(t, e, policy.ID, false)
+
+		for i := 0; i < 5; i++ {
+			resp := postHook(t, e, tok.URL, nil, "")
+			assertStatus(t, resp, http.StatusOK)
+		}
+		resp := postHook(t, e, tok.URL, nil, "")
+		assertStatus(t, resp, http.StatusTooManyRequests)
+	})
+}
+
+func TestTriggerTokenHandler_RequiresAdmin(t *testing.T) {
+	e := newTestEnv(t)
+	policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+	resp := e.post(t, "/api/v1/policies/"+policy.ID.String()+"/trigger-tokens", e.userToken(t), map[string]any{"name": "ci"})
+	assertStatus(t, resp, http.StatusForbidden)
+}
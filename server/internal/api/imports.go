@@ -0,0 +1,629 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/arkeep-io/arkeep/server/internal/agentmanager"
+	"github.com/arkeep-io/arkeep/server/internal/db"
+	"github.com/arkeep-io/arkeep/server/internal/repositories"
+	"github.com/arkeep-io/arkeep/server/internal/scheduler"
+)
+
+// ImportHandler groups the HTTP handlers that adopt restic repositories
+// created outside Arkeep. An import scans a repository entity from an
+// agent, then maps the hosts found to agents and records the snapshots in
+// the catalog under draft policies, without re-uploading anything.
+type ImportHandler struct {
+	repo      repositories.ImportRepository
+	repos     repositories.RepositoryRepository
+	agents    repositories.AgentRepository
+	policies  repositories.PolicyRepository
+	jobs      repositories.JobRepository
+	snapshots repositories.SnapshotRepository
+	scheduler *scheduler.Scheduler
+	auditRepo repositories.AuditRepository
+	logger    *zap.Logger
+}
+
+// NewImportHandler creates a new ImportHandler.
+func NewImportHandler(
+	repo repositories.ImportRepository,
+	repos repositories.RepositoryRepository,
+	agents repositories.AgentRepository,
+	policies repositories.PolicyRepository,
+	jobs repositories.JobRepository,
+	snapshots repositories.SnapshotRepository,
+	sched *scheduler.Scheduler,
+	auditRepo repositories.AuditRepository,
+	logger *zap.Logger,
+) *ImportHandler {
+	return &ImportHandler{
+		repo:      repo,
+		repos:     repos,
+		agents:    agents,
+		policies:  policies,
+		jobs:      jobs,
+		snapshots: snapshots,
+		scheduler: sched,
+		auditRepo: auditRepo,
+		logger:    logger.Named("import_handler"),
+	}
+}
+
+// importSnapshot is the JSON shape of one snapshot in
+// RepositoryImport.Snapshots, as stored by the gRPC server.
+type importSnapshot struct {
+	SnapshotID string    `json:"snapshot_id"`
+	Time       time.Time `json:"time"`
+	Hostname   string    `json:"hostname"`
+	Paths      []string  `json:"paths"`
+	Tags       []string  `json:"tags"`
+	SizeBytes  int64     `json:"size_bytes"`
+	FileCount  int64     `json:"file_count"`
+}
+
+// importGroup is the set of snapshots taken on one host of one set of
+// paths. Applying an import creates one draft policy per group.
+type importGroup struct {
+	Hostname        string   `json:"hostname"`
+	Paths           []string `json:"paths"`
+	Tags            []string `json:"tags"` // every tag found on the group's snapshots
+	SnapshotCount   int      `json:"snapshot_count"`
+	FirstSnapshotAt string   `json:"first_snapshot_at"`
+	LastSnapshotAt  string   `json:"last_snapshot_at"`
+	// AgentID is the existing agent with the group's hostname, nil when
+	// applying the import would create one.
+	AgentID *string `json:"agent_id"`
+
+	snapshots []importSnapshot
+}
+
+// importResponse is the JSON representation of a repository import. Groups
+// is only set on the import detail once the scan completed.
+type importResponse struct {
+	ID            string        `json:"id"`
+	RepositoryID  string        `json:"repository_id"`
+	AgentID       string        `json:"agent_id"` // the agent that scans the repository
+	Status        string        `json:"status"`   // "scanning", "scanned", "failed", "applying" or "applied"
+	Error         string        `json:"error"`
+	SnapshotCount int           `json:"snapshot_count"`
+	Groups        []importGroup `json:"groups,omitempty"`
+	AppliedAt     *string       `json:"applied_at"`
+	CreatedAt     string        `json:"created_at"`
+	UpdatedAt     string        `json:"updated_at"`
+}
+
+// importToResponse converts a db.RepositoryImport to an importResponse
+// without groups.
+func importToResponse(imp *db.RepositoryImport, snapshotCount int) importResponse {
+	resp := importResponse{
+		ID:            imp.ID.String(),
+		RepositoryID:  imp.RepositoryID.String(),
+		AgentID:       imp.AgentID.String(),
+		Status:        imp.Status,
+		Error:         imp.Error,
+		SnapshotCount: snapshotCount,
+		CreatedAt:     imp.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:     imp.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if imp.AppliedAt != nil {
+		s := imp.AppliedAt.UTC().Format(time.RFC3339)
+		resp.AppliedAt = &s
+	}
+	return resp
+}
+
+// decodeImportSnapshots parses RepositoryImport.Snapshots.
+func decodeImportSnapshots(imp *db.RepositoryImport) ([]importSnapshot, error) {
+	var snapshots []importSnapshot
+	if err := json.Unmarshal([]byte(imp.Snapshots), &snapshots); err != nil {
+		return nil, fmt.Errorf("invalid snapshots of import %s: %w", imp.ID, err)
+	}
+	return snapshots, nil
+}
+
+// groupImportSnapshots groups snapshots by hostname and set of paths,
+// ordered by hostname then paths. Snapshots within a group are oldest
+// first.
+func groupImportSnapshots(snapshots []importSnapshot) []importGroup {
+	byKey := make(map[string]*importGroup)
+	var keys []string
+	for _, snap := range snapshots {
+		paths := slices.Clone(snap.Paths)
+		sort.Strings(paths)
+		key := snap.Hostname + "\x00" + strings.Join(paths, "\x00")
+		g, ok := byKey[key]
+		if !ok {
+			g = &importGroup{Hostname: snap.Hostname, Paths: paths, Tags: []string{}}
+			byKey[key] = g
+			keys = append(keys, key)
+		}
+		g.snapshots = append(g.snapshots, snap)
+		for _, tag := range snap.Tags {
+			if !slices.Contains(g.Tags, tag) {
+				g.Tags = append(g.Tags, tag)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	groups := make([]importGroup, len(keys))
+	for i, key := range keys {
+		g := byKey[key]
+		sort.Slice(g.snapshots, func(a, b int) bool { return g.snapshots[a].Time.Before(g.snapshots[b].Time) })
+		sort.Strings(g.Tags)
+		g.SnapshotCount = len(g.snapshots)
+		g.FirstSnapshotAt = g.snapshots[0].Time.UTC().Format(time.RFC3339)
+		g.LastSnapshotAt = g.snapshots[len(g.snapshots)-1].Time.UTC().Format(time.RFC3339)
+		groups[i] = *g
+	}
+	return groups
+}
+
+// loadImport returns the import importID of repository repositoryID,
+// writing the error response itself when it returns false.
+func (h *ImportHandler) loadImport(w http.ResponseWriter, r *http.Request) (*db.RepositoryImport, bool) {
+	repoID, ok := parseUUID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+	importID, ok := parseUUID(w, r, "importID")
+	if !ok {
+		return nil, false
+	}
+	imp, err := h.repo.GetByID(r.Context(), importID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return nil, false
+		}
+		h.logger.Error("failed to get repository import", zap.String("id", importID.String()), zap.Error(err))
+		ErrInternal(w)
+		return nil, false
+	}
+	if imp.RepositoryID != repoID {
+		ErrNotFound(w)
+		return nil, false
+	}
+	return imp, true
+}
+
+// createImportRequest is the body of POST /api/v1/repositories/{id}/imports.
+type createImportRequest struct {
+	AgentID string `json:"agent_id"` // the agent that scans the repository
+}
+
+// Create handles POST /api/v1/repositories/{id}/imports (admin only). It
+// asks an agent to list the snapshots of the repository, which usually
+// holds backups made outside Arkeep: register it first with its
+// destination, path and password. The scan is sent right away, or when the
+// agent reconnects; poll the import until it is "scanned" or "failed".
+func (h *ImportHandler) Create(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req createImportRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	agentID, err := uuid.Parse(req.AgentID)
+	if err != nil {
+		ErrBadRequest(w, "agent_id must be a valid UUID")
+		return
+	}
+
+	ctx := r.Context()
+	if _, err := h.repos.GetByID(ctx, id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return
+		}
+		h.logger.Error("failed to get repository for import", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if _, err := h.agents.GetByID(ctx, agentID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrBadRequest(w, "agent_id: agent not found")
+			return
+		}
+		h.logger.Error("failed to get agent for import", zap.String("agent_id", agentID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	requestedBy := uuid.Nil
+	if claims := claimsFromCtx(ctx); claims != nil {
+		requestedBy, _ = uuid.Parse(claims.UserID)
+	}
+	imp := &db.RepositoryImport{
+		RepositoryID: id,
+		AgentID:      agentID,
+		Status:       "scanning",
+		Snapshots:    "[]",
+		RequestedBy:  requestedBy,
+	}
+	if err := h.repo.Create(ctx, imp); err != nil {
+		h.logger.Error("failed to create repository import", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	if err := h.scheduler.DispatchRepositoryScan(ctx, imp); err != nil {
+		if !errors.Is(err, agentmanager.ErrAgentNotConnected) {
+			h.logger.Error("failed to dispatch repository scan", zap.String("import_id", imp.ID.String()), zap.Error(err))
+		}
+	}
+
+	logAudit(r, h.auditRepo, h.logger, "repository.import.create", "repository", id.String(), map[string]any{
+		"import_id": imp.ID.String(),
+		"agent_id":  agentID.String(),
+	})
+	Created(w, importToResponse(imp, 0))
+}
+
+// List handles GET /api/v1/repositories/{id}/imports (admin only), newest
+// first.
+func (h *ImportHandler) List(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	imports, err := h.repo.ListByRepository(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to list repository imports", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	items := make([]importResponse, len(imports))
+	for i := range imports {
+		snapshots, err := decodeImportSnapshots(&imports[i])
+		if err != nil {
+			h.logger.Error("failed to decode repository import", zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		items[i] = importToResponse(&imports[i], len(snapshots))
+	}
+	Ok(w, items)
+}
+
+// GetByID handles GET /api/v1/repositories/{id}/imports/{importID} (admin
+// only). A scanned import lists the groups of snapshots it would adopt.
+func (h *ImportHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	imp, ok := h.loadImport(w, r)
+	if !ok {
+		return
+	}
+	snapshots, err := decodeImportSnapshots(imp)
+	if err != nil {
+		h.logger.Error("failed to decode repository import", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	resp := importToResponse(imp, len(snapshots))
+	resp.Groups = groupImportSnapshots(snapshots)
+	for i := range resp.Groups {
+		agent, err := h.agents.GetByHostname(r.Context(), resp.Groups[i].Hostname)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				continue
+			}
+			h.logger.Error("failed to look up agent by hostname", zap.String("hostname", resp.Groups[i].Hostname), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		agentID := agent.ID.String()
+		resp.Groups[i].AgentID = &agentID
+	}
+	Ok(w, resp)
+}
+
+// applyImportRequest is the body of POST
+// /api/v1/repositories/{id}/imports/{importID}/apply. Agents maps
+// hostnames found in the repository to existing agents. A host left out
+// maps to the agent with the same hostname, or to a new agent that the
+// first agent registering with that hostname takes over.
+type applyImportRequest struct {
+	Agents map[string]string `json:"agents"`
+}
+
+// appliedPolicy is a draft policy created by applying an import.
+type appliedPolicy struct {
+	PolicyID  string   `json:"policy_id"`
+	AgentID   string   `json:"agent_id"`
+	Hostname  string   `json:"hostname"`
+	Paths     []string `json:"paths"`
+	Snapshots int      `json:"snapshots"` // snapshots added to the catalog
+}
+
+// applyImportResponse is the outcome of applying an import.
+type applyImportResponse struct {
+	Import            importResponse  `json:"import"`
+	Policies          []appliedPolicy `json:"policies"`
+	AgentsCreated     int             `json:"agents_created"`
+	SnapshotsImported int             `json:"snapshots_imported"`
+	// SnapshotsSkipped counts the snapshots the catalog already had, e.g.
+	// those of Arkeep policies writing into the same repository.
+	SnapshotsSkipped int `json:"snapshots_skipped"`
+}
+
+// Apply handles POST /api/v1/repositories/{id}/imports/{importID}/apply
+// (admin only). For each group of snapshots not in the catalog yet it
+// creates a disabled policy of the group's agent that backs up the group's
+// paths into the repository, and records the snapshots under an "import"
+// job of that policy, so that they can be browsed and restored. Enabling
+// the policy continues the history in place.
+func (h *ImportHandler) Apply(w http.ResponseWriter, r *http.Request) {
+	imp, ok := h.loadImport(w, r)
+	if !ok {
+		return
+	}
+	var req applyImportRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if imp.Status != "scanned" {
+		ErrConflict(w, fmt.Sprintf("the import is %s, only a scanned import can be applied", imp.Status))
+		return
+	}
+
+	ctx := r.Context()
+	repo, err := h.repos.GetByID(ctx, imp.RepositoryID)
+	if err != nil {
+		h.logger.Error("failed to get repository of import", zap.String("import_id", imp.ID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	snapshots, err := decodeImportSnapshots(imp)
+	if err != nil {
+		h.logger.Error("failed to decode repository import", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	groups := groupImportSnapshots(snapshots)
+
+	// Resolve the agent of every host before writing anything.
+	agentIDs := make(map[string]uuid.UUID)
+	for _, g := range groups {
+		if _, ok := agentIDs[g.Hostname]; ok {
+			continue
+		}
+		mapped, ok := req.Agents[g.Hostname]
+		if !ok {
+			continue
+		}
+		agentID, err := uuid.Parse(mapped)
+		if err != nil {
+			ErrBadRequest(w, fmt.Sprintf("agents[%s] must be a valid UUID", g.Hostname))
+			return
+		}
+		if _, err := h.agents.GetByID(ctx, agentID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				ErrBadRequest(w, fmt.Sprintf("agents[%s]: agent not found", g.Hostname))
+				return
+			}
+			h.logger.Error("failed to get agent for import", zap.String("agent_id", mapped), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		agentIDs[g.Hostname] = agentID
+	}
+	for hostname := range req.Agents {
+		if _, ok := agentIDs[hostname]; !ok {
+			ErrBadRequest(w, fmt.Sprintf("agents[%s]: no snapshot of this host in the repository", hostname))
+			return
+		}
+	}
+
+	// Claim the import before the first write so that two applies of the
+	// same import cannot both create policies. A failed apply hands the
+	// import back; applying it again skips the snapshots already cataloged.
+	if err := h.repo.StartApply(ctx, imp.ID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrConflict(w, "the import is being applied concurrently")
+			return
+		}
+		h.logger.Error("failed to claim repository import", zap.String("import_id", imp.ID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	applied := false
+	defer func() {
+		if applied {
+			return
+		}
+		if err := h.repo.ReleaseApply(context.WithoutCancel(ctx), imp.ID); err != nil {
+			h.logger.Error("failed to release repository import", zap.String("import_id", imp.ID.String()), zap.Error(err))
+		}
+	}()
+
+	resp := applyImportResponse{Policies: []appliedPolicy{}}
+	for _, g := range groups {
+		// Leave out the snapshots the catalog already has.
+		var fresh []importSnapshot
+		for _, snap := range g.snapshots {
+			if _, err := h.snapshots.GetBySnapshotID(ctx, repo.DestinationID, snap.SnapshotID); err == nil {
+				resp.SnapshotsSkipped++
+				continue
+			} else if !errors.Is(err, repositories.ErrNotFound) {
+				h.logger.Error("failed to look up snapshot", zap.String("snapshot_id", snap.SnapshotID), zap.Error(err))
+				ErrInternal(w)
+				return
+			}
+			fresh = append(fresh, snap)
+		}
+		if len(fresh) == 0 {
+			continue
+		}
+
+		agentID, ok := agentIDs[g.Hostname]
+		if !ok {
+			var created bool
+			agentID, created, err = h.agentForHost(r, g.Hostname)
+			if err != nil {
+				h.logger.Error("failed to resolve agent of imported host", zap.String("hostname", g.Hostname), zap.Error(err))
+				ErrInternal(w)
+				return
+			}
+			if created {
+				resp.AgentsCreated++
+			}
+			agentIDs[g.Hostname] = agentID
+		}
+
+		policy, err := h.createDraftPolicy(r, repo, agentID, g)
+		if err != nil {
+			h.logger.Error("failed to create draft policy of import", zap.String("import_id", imp.ID.String()), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		if err := h.catalogSnapshots(r, repo, policy, fresh); err != nil {
+			h.logger.Error("failed to catalog imported snapshots", zap.String("policy_id", policy.ID.String()), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		resp.Policies = append(resp.Policies, appliedPolicy{
+			PolicyID:  policy.ID.String(),
+			AgentID:   agentID.String(),
+			Hostname:  g.Hostname,
+			Paths:     g.Paths,
+			Snapshots: len(fresh),
+		})
+		resp.SnapshotsImported += len(fresh)
+	}
+
+	now := time.Now().UTC()
+	if err := h.repo.MarkApplied(ctx, imp.ID, now); err != nil {
+		h.logger.Error("failed to mark repository import applied", zap.String("import_id", imp.ID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	applied = true
+	imp.Status = "applied"
+	imp.AppliedAt = &now
+
+	logAudit(r, h.auditRepo, h.logger, "repository.import.apply", "repository", repo.ID.String(), map[string]any{
+		"import_id":          imp.ID.String(),
+		"policies":           len(resp.Policies),
+		"agents_created":     resp.AgentsCreated,
+		"snapshots_imported": resp.SnapshotsImported,
+	})
+	resp.Import = importToResponse(imp, len(snapshots))
+	Ok(w, resp)
+}
+
+// agentForHost returns the agent with the given hostname, creating an
+// offline placeholder when there is none. created reports whether it did.
+func (h *ImportHandler) agentForHost(r *http.Request, hostname string) (uuid.UUID, bool, error) {
+	agent, err := h.agents.GetByHostname(r.Context(), hostname)
+	if err == nil {
+		return agent.ID, false, nil
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return uuid.Nil, false, err
+	}
+	agent = &db.Agent{Name: hostname, Hostname: hostname, Status: "offline", Labels: "{}"}
+	if err := h.agents.Create(r.Context(), agent); err != nil {
+		return uuid.Nil, false, err
+	}
+	logAudit(r, h.auditRepo, h.logger, "agent.create", "agent", agent.ID.String(), map[string]any{"hostname": hostname, "source": "repository_import"})
+	return agent.ID, true, nil
+}
+
+// createDraftPolicy creates the disabled policy of an import group: the
+// group's paths backed up by agentID into repo, with the default schedule
+// and retention.
+func (h *ImportHandler) createDraftPolicy(r *http.Request, repo *db.Repository, agentID uuid.UUID, g importGroup) (*db.Policy, error) {
+	sources := make([]map[string]string, len(g.Paths))
+	for i, path := range g.Paths {
+		sources[i] = map[string]string{"type": "path", "path": path}
+	}
+	sourcesJSON, err := json.Marshal(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &db.Policy{
+		Name:                fmt.Sprintf("%s: %s", g.Hostname, strings.Join(g.Paths, ", ")),
+		AgentID:             agentID,
+		Schedule:            "@daily",
+		Sources:             string(sourcesJSON),
+		RetentionDaily:      7,
+		RetentionWeekly:     4,
+		RetentionMonthly:    6,
+		RetentionYearly:     1,
+		RetryMaxAttempts:    1,
+		RetryBackoffSeconds: 300,
+		RetryOn:             "network",
+		AnomalyDetection:    true,
+		FanOut:              scheduler.FanOutSequential,
+		Type:                scheduler.PolicyTypeBackup,
+	}
+	if err := h.policies.CreateDisabled(r.Context(), policy); err != nil {
+		return nil, err
+	}
+	if err := h.policies.AddDestination(r.Context(), &db.PolicyDestination{
+		PolicyID:      policy.ID,
+		DestinationID: repo.DestinationID,
+		RepositoryID:  &repo.ID,
+	}); err != nil {
+		return nil, err
+	}
+	logAudit(r, h.auditRepo, h.logger, "policy.create", "policy", policy.ID.String(), map[string]any{"name": policy.Name, "schedule": policy.Schedule, "enabled": policy.Enabled, "source": "repository_import"})
+	return policy, nil
+}
+
+// catalogSnapshots records snapshots under a new succeeded "import" job of
+// policy, which spans the snapshots' times.
+func (h *ImportHandler) catalogSnapshots(r *http.Request, repo *db.Repository, policy *db.Policy, snapshots []importSnapshot) error {
+	ctx := r.Context()
+	startedAt := snapshots[0].Time.UTC()
+	endedAt := snapshots[len(snapshots)-1].Time.UTC()
+	job := &db.Job{
+		PolicyID:  policy.ID,
+		AgentID:   policy.AgentID,
+		Type:      "import",
+		Status:    "succeeded",
+		StartedAt: &startedAt,
+		EndedAt:   &endedAt,
+	}
+	if err := h.jobs.Create(ctx, job); err != nil {
+		return err
+	}
+	for _, snap := range snapshots {
+		tags := snap.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		tagsJSON, err := json.Marshal(tags)
+		if err != nil {
+			return err
+		}
+		if err := h.snapshots.Create(ctx, &db.Snapshot{
+			PolicyID:      policy.ID,
+			DestinationID: repo.DestinationID,
+			JobID:         job.ID,
+			SnapshotID:    snap.SnapshotID,
+			SizeBytes:     snap.SizeBytes,
+			FileCount:     snap.FileCount,
+			Tags:          string(tagsJSON),
+			SnapshotAt:    snap.Time.UTC(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
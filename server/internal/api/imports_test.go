@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+	"github.com/arkeep-io/arkeep/server/internal/repositories"
+)
+
+// scanImport completes the scan of an import as the agent would, with the
+// given snapshots.
+func scanImport(t *testing.T, deps *testDeps, importID string, snapshots []map[string]any) {
+	t.Helper()
+	data, err := json.Marshal(snapshots)
+	if err != nil {
+		t.Fatalf("marshal snapshots: %v", err)
+	}
+	if err := deps.imports.CompleteScan(context.Background(), uuid.MustParse(importID), string(data), ""); err != nil {
+		t.Fatalf("CompleteScan: %v", err)
+	}
+}
+
+func TestImportHandler_Create(t *testing.T) {
+	e := newTestEnv(t)
+	dest := createDBDestination(t, e.deps, "nas", "local")
+	repo := createDBRepository(t, e.deps, dest.ID, "legacy")
+	agent := createDBAgent(t, e.deps, "scanner")
+	url := "/api/v1/repositories/" + repo.ID.String() + "/imports"
+
+	t.Run("returns 403 for non-admin", func(t *testing.T) {
+		assertStatus(t, e.post(t, url, e.userToken(t), map[string]any{"agent_id": agent.ID.String()}), http.StatusForbidden)
+	})
+
+	t.Run("returns 400 for an unknown agent", func(t *testing.T) {
+		assertStatus(t, e.post(t, url, e.adminToken(t), map[string]any{"agent_id": uuid.New().String()}), http.StatusBadRequest)
+	})
+
+	t.Run("returns 404 for an unknown repository", func(t *testing.T) {
+		resp := e.post(t, "/api/v1/repositories/"+uuid.New().String()+"/imports", e.adminToken(t), map[string]any{"agent_id": agent.ID.String()})
+		assertStatus(t, resp, http.StatusNotFound)
+	})
+
+	t.Run("creates a scanning import", func(t *testing.T) {
+		resp := e.post(t, url, e.adminToken(t), map[string]any{"agent_id": agent.ID.String()})
+		assertStatus(t, resp, http.StatusCreated)
+		var created importResponse
+		decodeData(t, resp, &created)
+		if created.Status != "scanning" || created.AgentID != agent.ID.String() {
+			t.Errorf("import = %+v, want scanning by the agent", created)
+		}
+
+		// The agent is offline: the scan waits for it to reconnect.
+		pending, err := e.deps.imports.ListPendingScans(context.Background(), agent.ID)
+		if err != nil {
+			t.Fatalf("ListPendingScans: %v", err)
+		}
+		if len(pending) != 1 || pending[0].ID.String() != created.ID {
+			t.Errorf("pending scans = %+v, want the import", pending)
+		}
+	})
+}
+
+func TestImportHandler_Apply(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	dest := createDBDestination(t, e.deps, "nas", "local")
+	repo := createDBRepository(t, e.deps, dest.ID, "legacy")
+	scanner := createDBAgent(t, e.deps, "scanner")
+	web := &db.Agent{Name: "web", Hostname: "web01", Status: "online", Labels: "{}", Version: "1.0.0"}
+	if err := e.deps.agents.Create(ctx, web); err != nil {
+		t.Fatalf("Create agent: %v", err)
+	}
+	url := "/api/v1/repositories/" + repo.ID.String() + "/imports"
+
+	// A snapshot an Arkeep policy already recorded in the repository.
+	known := createDBPolicy(t, e.deps, "known", web.ID)
+	if err := e.deps.snaps.Create(ctx, &db.Snapshot{PolicyID: known.ID, DestinationID: dest.ID, JobID: uuid.New(), SnapshotID: "known", Tags: "[]"}); err != nil {
+		t.Fatalf("Create snapshot: %v", err)
+	}
+	snapshots := []map[string]any{
+		{"snapshot_id": "w2", "time": "2026-02-01T02:00:00Z", "hostname": "web01", "paths": []string{"/var/www", "/etc"}, "tags": []string{"cron"}, "size_bytes": 200},
+		{"snapshot_id": "w1", "time": "2026-01-01T02:00:00Z", "hostname": "web01", "paths": []string{"/etc", "/var/www"}, "size_bytes": 100},
+		{"snapshot_id": "d1", "time": "2026-01-02T03:00:00Z", "hostname": "db01", "paths": []string{"/var/lib/postgresql"}},
+		{"snapshot_id": "known", "time": "2026-03-01T02:00:00Z", "hostname": "web01", "paths": []string{"/srv"}},
+	}
+
+	newImport := func() importResponse {
+		resp := e.post(t, url, e.adminToken(t), map[string]any{"agent_id": scanner.ID.String()})
+		assertStatus(t, resp, http.StatusCreated)
+		var created importResponse
+		decodeData(t, resp, &created)
+		return created
+	}
+
+	t.Run("returns 409 before the scan completed", func(t *testing.T) {
+		imp := newImport()
+		assertStatus(t, e.post(t, url+"/"+imp.ID+"/apply", e.adminToken(t), map[string]any{}), http.StatusConflict)
+	})
+
+	t.Run("returns 400 for a host without snapshots", func(t *testing.T) {
+		imp := newImport()
+		scanImport(t, e.deps, imp.ID, snapshots)
+		resp := e.post(t, url+"/"+imp.ID+"/apply", e.adminToken(t), map[string]any{"agents": map[string]string{"mail01": web.ID.String()}})
+		assertStatus(t, resp, http.StatusBadRequest)
+	})
+
+	t.Run("returns 409 while another apply runs", func(t *testing.T) {
+		imp := newImport()
+		scanImport(t, e.deps, imp.ID, snapshots)
+		if err := e.deps.imports.StartApply(ctx, uuid.MustParse(imp.ID)); err != nil {
+			t.Fatalf("StartApply: %v", err)
+		}
+		assertStatus(t, e.post(t, url+"/"+imp.ID+"/apply", e.adminToken(t), map[string]any{}), http.StatusConflict)
+		if _, total, _ := e.deps.policies.List(ctx, repositories.ListOptions{Limit: 10}); total != 1 {
+			t.Errorf("policies = %d, want only the known one", total)
+		}
+	})
+
+	imp := newImport()
+	scanImport(t, e.deps, imp.ID, snapshots)
+
+	t.Run("groups the snapshots by host and paths", func(t *testing.T) {
+		resp := e.get(t, url+"/"+imp.ID, e.adminToken(t))
+		assertStatus(t, resp, http.StatusOK)
+		var got importResponse
+		decodeData(t, resp, &got)
+		if got.Status != "scanned" || got.SnapshotCount != 4 || len(got.Groups) != 3 {
+			t.Fatalf("import = %+v, want 4 snapshots in 3 groups", got)
+		}
+		g := got.Groups[1] // web01 /etc /var/www, before web01 /srv
+		if g.Hostname != "web01" || g.SnapshotCount != 2 || g.FirstSnapshotAt != "2026-01-01T02:00:00Z" || len(g.Tags) != 1 {
+			t.Errorf("group = %+v, want both web01 snapshots of /etc and /var/www", g)
+		}
+		if g.AgentID == nil || *g.AgentID != web.ID.String() {
+			t.Errorf("agent_id = %v, want the agent with hostname web01", g.AgentID)
+		}
+		if got.Groups[0].Hostname != "db01" || got.Groups[0].AgentID != nil {
+			t.Errorf("group = %+v, want db01 without an agent", got.Groups[0])
+		}
+	})
+
+	t.Run("creates agents, draft policies and catalog entries", func(t *testing.T) {
+		resp := e.post(t, url+"/"+imp.ID+"/apply", e.adminToken(t), map[string]any{})
+		assertStatus(t, resp, http.StatusOK)
+		var got applyImportResponse
+		decodeData(t, resp, &got)
+		if got.Import.Status != "applied" || got.AgentsCreated != 1 || got.SnapshotsImported != 3 || got.SnapshotsSkipped != 1 {
+			t.Fatalf("apply = %+v, want 1 agent created, 3 snapshots imported, 1 skipped", got)
+		}
+		if len(got.Policies) != 2 {
+			t.Fatalf("policies = %+v, want one per group with new snapshots", got.Policies)
+		}
+
+		created, err := e.deps.agents.GetByHostname(ctx, "db01")
+		if err != nil {
+			t.Fatalf("GetByHostname: %v", err)
+		}
+		for _, p := range got.Policies {
+			policy, pds, err := e.deps.policies.GetByIDWithDestinations(ctx, uuid.MustParse(p.PolicyID))
+			if err != nil {
+				t.Fatalf("GetByIDWithDestinations: %v", err)
+			}
+			if policy.Enabled {
+				t.Errorf("policy %q is enabled, want a draft", policy.Name)
+			}
+			if len(pds) != 1 || pds[0].RepositoryID == nil || *pds[0].RepositoryID != repo.ID {
+				t.Errorf("destinations = %+v, want the imported repository", pds)
+			}
+			wantAgent := web.ID
+			if p.Hostname == "db01" {
+				wantAgent = created.ID
+			}
+			if policy.AgentID != wantAgent {
+				t.Errorf("policy %q agent = %s, want %s", policy.Name, policy.AgentID, wantAgent)
+			}
+
+			_, total, err := e.deps.snaps.ListByPolicy(ctx, policy.ID, repositories.ListOptions{Limit: 10})
+			if err != nil {
+				t.Fatalf("ListByPolicy: %v", err)
+			}
+			if int(total) != p.Snapshots {
+				t.Errorf("policy %q has %d snapshots, want %d", policy.Name, total, p.Snapshots)
+			}
+		}
+
+		snap, err := e.deps.snaps.GetBySnapshotID(ctx, dest.ID, "w2")
+		if err != nil {
+			t.Fatalf("GetBySnapshotID: %v", err)
+		}
+		if snap.SizeBytes != 200 || snap.Tags != `["cron"]` {
+			t.Errorf("snapshot = %+v, want the scanned size and tags", snap)
+		}
+	})
+
+	t.Run("returns 409 when applied twice", func(t *testing.T) {
+		assertStatus(t, e.post(t, url+"/"+imp.ID+"/apply", e.adminToken(t), map[string]any{}), http.StatusConflict)
+	})
+}
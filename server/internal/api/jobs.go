@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"time"
@@ -44,6 +45,18 @@ type jobDestinationResponse struct {
 	StartedAt     *string `json:"started_at"`
 	EndedAt       *string `json:"ended_at"`
 	Error         string  `json:"error"`
+	FilesNew        int64  `json:"files_new"`
+	FilesChanged    int64  `json:"files_changed"`
+	FilesUnmodified int64  `json:"files_unmodified"`
+	TotalFiles      int64  `json:"total_files"`
+	DataAdded       int64  `json:"data_added"`
+	Anomaly         bool   `json:"anomaly"`
+	AnomalyReason   string `json:"anomaly_reason"`
+	// FileErrors lists the files restic could not read in a
+	// "succeeded_with_warnings" run, as stored (truncated by the agent);
+	// FileErrorCount is the full count.
+	FileErrors     json.RawMessage `json:"file_errors"`
+	FileErrorCount int64           `json:"file_error_count"`
 }
 
 // jobResponse is the JSON representation of a job.
@@ -58,8 +71,58 @@ type jobResponse struct {
 	Error        string                   `json:"error"`
 	StartedAt    *string                  `json:"started_at"`
 	EndedAt      *string                  `json:"ended_at"`
+	Attempt      int                      `json:"attempt"`
+	RetryOfID    *string                  `json:"retry_of_id"`
+	TriggeredBy  *string                  `json:"triggered_by_job_id"`
+	ScheduleID   *string                  `json:"schedule_id"`
+	FailureClass string                   `json:"failure_class"`
+	NotBefore    *string                  `json:"not_before"`
+	LastActivity *string                  `json:"last_activity_at"`
 	Destinations []jobDestinationResponse `json:"destinations,omitempty"`
-	CreatedAt    string                   `json:"created_at"`
+	RetryChain   []jobAttemptResponse     `json:"retry_chain,omitempty"`
+	DryRun       *dryRunResponse          `json:"dry_run,omitempty"`
+	Progress     *jobProgressResponse     `json:"progress,omitempty"`
+	// FileErrorCount sums the file errors of the destinations; detail only.
+	FileErrorCount int64  `json:"file_error_count,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// dryRunResponse is the result of a "dry_run" job on the job detail.
+// LargestDirectories and UnreadablePaths are passed through as stored.
+type dryRunResponse struct {
+	TotalFiles         int64           `json:"total_files"`
+	TotalBytes         int64           `json:"total_bytes"`
+	DataAdded          int64           `json:"data_added"`
+	LargestDirectories json.RawMessage `json:"largest_directories"`
+	UnreadablePaths    json.RawMessage `json:"unreadable_paths"`
+	UnreadableCount    int64           `json:"unreadable_count"`
+}
+
+// jobProgressResponse is the latest progress snapshot reported for a job,
+// in the shape of the job.progress WebSocket payload.
+type jobProgressResponse struct {
+	DestinationID    string  `json:"destination_id"`
+	PercentDone      float64 `json:"percent_done"`
+	FilesDone        int64   `json:"files_done"`
+	TotalFiles       int64   `json:"total_files"`
+	BytesDone        int64   `json:"bytes_done"`
+	TotalBytes       int64   `json:"total_bytes"`
+	CurrentFile      string  `json:"current_file"`
+	SecondsRemaining int64   `json:"seconds_remaining"`
+	SecondsElapsed   int64   `json:"seconds_elapsed"`
+	UpdatedAt        string  `json:"updated_at"`
+}
+
+// jobAttemptResponse summarizes one attempt of a retry chain on the job
+// detail.
+type jobAttemptResponse struct {
+	ID           string  `json:"id"`
+	Attempt      int     `json:"attempt"`
+	Status       string  `json:"status"`
+	FailureClass string  `json:"failure_class"`
+	Error        string  `json:"error"`
+	CreatedAt    string  `json:"created_at"`
+	EndedAt      *string `json:"ended_at"`
 }
 
 // jobLogResponse represents a single log line from a job execution.
@@ -84,6 +147,8 @@ func jobToResponse(j *repositories.JobWithNames, destinations []repositories.Job
 		Type:         j.Type,
 		Status:       j.Status,
 		Error:        j.Error,
+		Attempt:      j.Attempt,
+		FailureClass: j.FailureClass,
 		Destinations: make([]jobDestinationResponse, len(destinations)),
 		CreatedAt:    j.CreatedAt.UTC().Format(time.RFC3339),
 	}
@@ -96,6 +161,26 @@ func jobToResponse(j *repositories.JobWithNames, destinations []repositories.Job
 		s := j.EndedAt.UTC().Format(time.RFC3339)
 		resp.EndedAt = &s
 	}
+	if j.RetryOfID != nil {
+		s := j.RetryOfID.String()
+		resp.RetryOfID = &s
+	}
+	if j.TriggeredByID != nil {
+		s := j.TriggeredByID.String()
+		resp.TriggeredBy = &s
+	}
+	if j.ScheduleID != nil {
+		s := j.ScheduleID.String()
+		resp.ScheduleID = &s
+	}
+	if j.NotBefore != nil {
+		s := j.NotBefore.UTC().Format(time.RFC3339)
+		resp.NotBefore = &s
+	}
+	if j.LastActivityAt != nil {
+		s := j.LastActivityAt.UTC().Format(time.RFC3339)
+		resp.LastActivity = &s
+	}
 
 	for i, jd := range destinations {
 		d := jobDestinationResponse{
@@ -106,7 +191,20 @@ func jobToResponse(j *repositories.JobWithNames, destinations []repositories.Job
 			SnapshotID:      jd.SnapshotID,
 			SizeBytes:       jd.SizeBytes,
 			Error:           jd.Error,
+			FilesNew:        jd.FilesNew,
+			FilesChanged:    jd.FilesChanged,
+			FilesUnmodified: jd.FilesUnmodified,
+			TotalFiles:      jd.TotalFiles,
+			DataAdded:       jd.DataAdded,
+			Anomaly:         jd.Anomaly,
+			AnomalyReason:   jd.AnomalyReason,
+			FileErrors:      json.RawMessage(jd.FileErrors),
+			FileErrorCount:  jd.FileErrorCount,
+		}
+		if jd.FileErrors == "" {
+			d.FileErrors = json.RawMessage("[]")
 		}
+		resp.FileErrorCount += jd.FileErrorCount
 		if jd.StartedAt != nil {
 			s := jd.StartedAt.UTC().Format(time.RFC3339)
 			d.StartedAt = &s
@@ -204,7 +302,77 @@ func (h *JobHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	Ok(w, jobToResponse(job, destinations, logs))
+	resp := jobToResponse(job, destinations, logs)
+
+	// Only jobs that are part of a retry chain get the chain in the response.
+	chain, err := h.repo.ListRetryChain(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to get job retry chain", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if len(chain) > 1 {
+		resp.RetryChain = make([]jobAttemptResponse, len(chain))
+		for i, c := range chain {
+			a := jobAttemptResponse{
+				ID:           c.ID.String(),
+				Attempt:      c.Attempt,
+				Status:       c.Status,
+				FailureClass: c.FailureClass,
+				Error:        c.Error,
+				CreatedAt:    c.CreatedAt.UTC().Format(time.RFC3339),
+			}
+			if c.EndedAt != nil {
+				s := c.EndedAt.UTC().Format(time.RFC3339)
+				a.EndedAt = &s
+			}
+			resp.RetryChain[i] = a
+		}
+	}
+
+	// Progress is absent until the agent has reported some.
+	progress, err := h.repo.GetProgress(r.Context(), id)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		h.logger.Error("failed to get job progress", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if progress != nil {
+		resp.Progress = &jobProgressResponse{
+			DestinationID:    progress.DestinationID,
+			PercentDone:      progress.PercentDone,
+			FilesDone:        progress.FilesDone,
+			TotalFiles:       progress.TotalFiles,
+			BytesDone:        progress.BytesDone,
+			TotalBytes:       progress.TotalBytes,
+			CurrentFile:      progress.CurrentFile,
+			SecondsRemaining: progress.SecondsRemaining,
+			SecondsElapsed:   progress.SecondsElapsed,
+			UpdatedAt:        progress.UpdatedAt.UTC().Format(time.RFC3339),
+		}
+	}
+
+	// The dry-run result is absent until the agent has reported it.
+	if job.Type == "dry_run" {
+		result, err := h.repo.GetDryRunResult(r.Context(), id)
+		if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			h.logger.Error("failed to get dry-run result", zap.String("id", id.String()), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		if result != nil {
+			resp.DryRun = &dryRunResponse{
+				TotalFiles:         result.TotalFiles,
+				TotalBytes:         result.TotalBytes,
+				DataAdded:          result.DataAdded,
+				LargestDirectories: json.RawMessage(result.LargestDirectories),
+				UnreadablePaths:    json.RawMessage(result.UnreadablePaths),
+				UnreadableCount:    result.UnreadableCount,
+			}
+		}
+	}
+
+	Ok(w, resp)
 }
 
 // GetLogs handles GET /api/v1/jobs/{id}/logs.
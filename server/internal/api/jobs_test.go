@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -126,6 +127,69 @@ func TestJobHandler_GetByID(t *testing.T) {
 		}
 	})
 
+	t.Run("includes the retry chain", func(t *testing.T) {
+		e := newTestEnv(t)
+		ctx := context.Background()
+		first := createDBJob(t, e.deps)
+		if err := e.deps.jobs.SetFailureClass(ctx, first.ID, "network"); err != nil {
+			t.Fatalf("SetFailureClass: %v", err)
+		}
+		retry := &db.Job{
+			PolicyID:  first.PolicyID,
+			AgentID:   first.AgentID,
+			Type:      "backup",
+			Status:    "pending",
+			Attempt:   2,
+			RetryOfID: &first.ID,
+		}
+		if err := e.deps.jobs.Create(ctx, retry); err != nil {
+			t.Fatalf("Create retry: %v", err)
+		}
+
+		resp := e.get(t, "/api/v1/jobs/"+retry.ID.String(), e.adminToken(t))
+		assertStatus(t, resp, http.StatusOK)
+
+		var data struct {
+			Attempt    int    `json:"attempt"`
+			RetryOfID  string `json:"retry_of_id"`
+			RetryChain []struct {
+				ID           string `json:"id"`
+				Attempt      int    `json:"attempt"`
+				FailureClass string `json:"failure_class"`
+			} `json:"retry_chain"`
+		}
+		decodeData(t, resp, &data)
+		if data.Attempt != 2 || data.RetryOfID != first.ID.String() {
+			t.Errorf("attempt %d retry of %q, want attempt 2 retry of %s", data.Attempt, data.RetryOfID, first.ID)
+		}
+		if len(data.RetryChain) != 2 {
+			t.Fatalf("retry_chain has %d entries, want 2", len(data.RetryChain))
+		}
+		if data.RetryChain[0].ID != first.ID.String() || data.RetryChain[0].FailureClass != "network" {
+			t.Errorf("retry_chain[0] = %+v, want the failed first attempt", data.RetryChain[0])
+		}
+	})
+
+	t.Run("includes the last activity", func(t *testing.T) {
+		e := newTestEnv(t)
+		job := createDBJob(t, e.deps)
+		at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		if err := e.deps.jobs.TouchActivity(context.Background(), job.ID, at); err != nil {
+			t.Fatalf("TouchActivity: %v", err)
+		}
+
+		resp := e.get(t, "/api/v1/jobs/"+job.ID.String(), e.adminToken(t))
+		assertStatus(t, resp, http.StatusOK)
+
+		var data struct {
+			LastActivityAt *string `json:"last_activity_at"`
+		}
+		decodeData(t, resp, &data)
+		if data.LastActivityAt == nil || *data.LastActivityAt != "2026-03-01T12:00:00Z" {
+			t.Errorf("last_activity_at = %v, want 2026-03-01T12:00:00Z", data.LastActivityAt)
+		}
+	})
+
 	t.Run("returns 404 for non-existent job", func(t *testing.T) {
 		e := newTestEnv(t)
 		resp := e.get(t, "/api/v1/jobs/00000000-0000-0000-0000-000000000001", e.adminToken(t))
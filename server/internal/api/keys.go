@@ -0,0 +1,248 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/arkeep-io/arkeep/server/internal/agentmanager"
+	"github.com/arkeep-io/arkeep/server/internal/db"
+	"github.com/arkeep-io/arkeep/server/internal/repositories"
+	"github.com/arkeep-io/arkeep/server/internal/scheduler"
+	"github.com/arkeep-io/arkeep/shared/keyseal"
+)
+
+// KeyHandler groups the HTTP handlers of zero-knowledge policies' key
+// material: key shares between agents and escrow kits. The server only
+// relays sealed keys; it can open neither.
+type KeyHandler struct {
+	repo      repositories.KeyRepository
+	policies  repositories.PolicyRepository
+	agents    repositories.AgentRepository
+	scheduler *scheduler.Scheduler
+	auditRepo repositories.AuditRepository
+	logger    *zap.Logger
+}
+
+// NewKeyHandler creates a new KeyHandler.
+func NewKeyHandler(repo repositories.KeyRepository, policies repositories.PolicyRepository, agents repositories.AgentRepository, sched *scheduler.Scheduler, auditRepo repositories.AuditRepository, logger *zap.Logger) *KeyHandler {
+	return &KeyHandler{
+		repo:      repo,
+		policies:  policies,
+		agents:    agents,
+		scheduler: sched,
+		auditRepo: auditRepo,
+		logger:    logger.Named("key_handler"),
+	}
+}
+
+// keyShareResponse is the JSON representation of a key share. The sealed
+// key is not returned: it is only useful to the recipient agent, which gets
+// it in the restore payload.
+type keyShareResponse struct {
+	ID                      string `json:"id"`
+	PolicyID                string `json:"policy_id"`
+	DestinationID           string `json:"destination_id"`
+	FromAgentID             string `json:"from_agent_id"`
+	ToAgentID               string `json:"to_agent_id"`
+	KeyFingerprint          string `json:"key_fingerprint"`
+	RecipientKeyFingerprint string `json:"recipient_key_fingerprint"`
+	Status                  string `json:"status"` // "pending", "sealed" or "failed"
+	Error                   string `json:"error"`
+	CreatedAt               string `json:"created_at"`
+	UpdatedAt               string `json:"updated_at"`
+}
+
+// keyShareToResponse converts a db.KeyShare to a keyShareResponse.
+func keyShareToResponse(s *db.KeyShare) keyShareResponse {
+	return keyShareResponse{
+		ID:                      s.ID.String(),
+		PolicyID:                s.PolicyID.String(),
+		DestinationID:           s.DestinationID.String(),
+		FromAgentID:             s.FromAgentID.String(),
+		ToAgentID:               s.ToAgentID.String(),
+		KeyFingerprint:          s.KeyFingerprint,
+		RecipientKeyFingerprint: keyseal.Fingerprint(s.RecipientPublicKey),
+		Status:                  s.Status,
+		Error:                   s.Error,
+		CreatedAt:               s.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:               s.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// createKeyShareRequest is the body of POST /api/v1/policies/{id}/key-shares.
+type createKeyShareRequest struct {
+	DestinationID string `json:"destination_id"`
+	AgentID       string `json:"agent_id"` // the agent to restore on
+}
+
+// CreateShare handles POST /api/v1/policies/{id}/key-shares (admin only).
+// It starts the key-sharing handshake that lets another agent restore the
+// snapshots of a zero-knowledge policy on one destination: the policy's
+// agent seals the repository key to the public key the recipient announced
+// when it registered. The admin should compare the returned
+// recipient_key_fingerprint with the one the recipient agent logs at start.
+// The share is sent right away, or when the policy's agent reconnects.
+func (h *KeyHandler) CreateShare(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req createKeyShareRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	destID, err := uuid.Parse(req.DestinationID)
+	if err != nil {
+		ErrBadRequest(w, "destination_id must be a valid UUID")
+		return
+	}
+	agentID, err := uuid.Parse(req.AgentID)
+	if err != nil {
+		ErrBadRequest(w, "agent_id must be a valid UUID")
+		return
+	}
+
+	ctx := r.Context()
+	policy, destinations, err := h.policies.GetByIDWithDestinations(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return
+		}
+		h.logger.Error("failed to get policy for key share", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if !policy.ZeroKnowledge {
+		ErrBadRequest(w, "key shares only apply to zero-knowledge policies, any agent can restore this policy")
+		return
+	}
+	var pd *db.PolicyDestination
+	for i := range destinations {
+		if destinations[i].DestinationID == destID {
+			pd = &destinations[i]
+		}
+	}
+	if pd == nil {
+		ErrBadRequest(w, "destination_id: not a destination of the policy")
+		return
+	}
+	if pd.KeyFingerprint == "" {
+		ErrConflict(w, "the agent has not created the repository key yet, run a backup first")
+		return
+	}
+	if agentID == policy.AgentID {
+		ErrBadRequest(w, "agent_id: the policy's agent already holds the key")
+		return
+	}
+	agent, err := h.agents.GetByID(ctx, agentID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrBadRequest(w, "agent_id: agent not found")
+			return
+		}
+		h.logger.Error("failed to get agent for key share", zap.String("agent_id", agentID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if agent.KeySharePublicKey == "" {
+		ErrConflict(w, "the agent has not announced a key share public key, upgrade it and let it reconnect")
+		return
+	}
+
+	requestedBy := uuid.Nil
+	if claims := claimsFromCtx(ctx); claims != nil {
+		requestedBy, _ = uuid.Parse(claims.UserID)
+	}
+	share := &db.KeyShare{
+		PolicyID:           policy.ID,
+		DestinationID:      destID,
+		KeyID:              pd.KeyID(),
+		FromAgentID:        policy.AgentID,
+		ToAgentID:          agentID,
+		RecipientPublicKey: agent.KeySharePublicKey,
+		KeyFingerprint:     pd.KeyFingerprint,
+		Status:             "pending",
+		RequestedBy:        requestedBy,
+	}
+	if err := h.repo.CreateShare(ctx, share); err != nil {
+		h.logger.Error("failed to create key share", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	if err := h.scheduler.DispatchKeyShare(share); err != nil {
+		if !errors.Is(err, agentmanager.ErrAgentNotConnected) {
+			h.logger.Error("failed to dispatch key share", zap.String("share_id", share.ID.String()), zap.Error(err))
+		}
+	}
+
+	logAudit(r, h.auditRepo, h.logger, "policy.key_share.create", "policy", id.String(), map[string]any{
+		"destination_id":            destID.String(),
+		"agent_id":                  agentID.String(),
+		"recipient_key_fingerprint": keyseal.Fingerprint(agent.KeySharePublicKey),
+	})
+	Created(w, keyShareToResponse(share))
+}
+
+// ListShares handles GET /api/v1/policies/{id}/key-shares (admin only),
+// newest first.
+func (h *KeyHandler) ListShares(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	shares, err := h.repo.ListSharesByPolicy(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to list key shares", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	items := make([]keyShareResponse, len(shares))
+	for i := range shares {
+		items[i] = keyShareToResponse(&shares[i])
+	}
+	Ok(w, items)
+}
+
+// escrowKitResponse is the latest escrow kit of an agent. Kit is sealed to
+// the escrow public key whose fingerprint is given; open it offline with
+// `arkeep-agent escrow open`.
+type escrowKitResponse struct {
+	AgentID              string `json:"agent_id"`
+	EscrowKeyFingerprint string `json:"escrow_key_fingerprint"`
+	KeyCount             int    `json:"key_count"`
+	Kit                  string `json:"kit"` // base64
+	UpdatedAt            string `json:"updated_at"`
+}
+
+// EscrowKit handles GET /api/v1/agents/{id}/escrow-kit (admin only,
+// audited).
+func (h *KeyHandler) EscrowKit(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	kit, err := h.repo.GetEscrowKit(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return
+		}
+		h.logger.Error("failed to get escrow kit", zap.String("agent_id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	logAudit(r, h.auditRepo, h.logger, "agent.escrow_kit.export", "agent", id.String(), map[string]any{"escrow_key_fingerprint": kit.EscrowKeyFingerprint})
+	Ok(w, escrowKitResponse{
+		AgentID:              kit.AgentID.String(),
+		EscrowKeyFingerprint: kit.EscrowKeyFingerprint,
+		KeyCount:             kit.KeyCount,
+		Kit:                  kit.Kit,
+		UpdatedAt:            kit.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+	"github.com/arkeep-io/arkeep/shared/keyseal"
+)
+
+// createZKPolicy creates a zero-knowledge policy with one destination and
+// returns its ID and the destination ID.
+func createZKPolicy(t *testing.T, e *testEnv, agentID uuid.UUID) (string, uuid.UUID) {
+	t.Helper()
+	destID := uuid.New()
+	resp := e.post(t, "/api/v1/policies", e.adminToken(t), map[string]any{
+		"name":           "zk-policy",
+		"agent_id":       agentID.String(),
+		"schedule":       "@daily",
+		"sources":        `["/data"]`,
+		"zero_knowledge": true,
+		"destinations":   []map[string]any{{"destination_id": destID.String()}},
+	})
+	assertStatus(t, resp, http.StatusCreated)
+	var created struct {
+		ID string `json:"id"`
+	}
+	decodeData(t, resp, &created)
+	return created.ID, destID
+}
+
+func TestKeyHandler_CreateShare(t *testing.T) {
+	e := newTestEnv(t)
+	owner := createDBAgent(t, e.deps, "owner")
+	recipient := createDBAgent(t, e.deps, "recipient")
+	policyID, destID := createZKPolicy(t, e, owner.ID)
+	url := "/api/v1/policies/" + policyID + "/key-shares"
+	body := map[string]any{"destination_id": destID.String(), "agent_id": recipient.ID.String()}
+
+	t.Run("returns 403 for non-admin", func(t *testing.T) {
+		assertStatus(t, e.post(t, url, e.userToken(t), body), http.StatusForbidden)
+	})
+
+	t.Run("returns 409 before the agent created the key", func(t *testing.T) {
+		assertStatus(t, e.post(t, url, e.adminToken(t), body), http.StatusConflict)
+	})
+
+	if err := e.deps.policies.SetDestinationKeyFingerprint(context.Background(), uuid.MustParse(policyID), destID, "fp-repo"); err != nil {
+		t.Fatalf("SetDestinationKeyFingerprint: %v", err)
+	}
+
+	t.Run("returns 400 for the policy's own agent", func(t *testing.T) {
+		assertStatus(t, e.post(t, url, e.adminToken(t), map[string]any{
+			"destination_id": destID.String(),
+			"agent_id":       owner.ID.String(),
+		}), http.StatusBadRequest)
+	})
+
+	t.Run("returns 409 when the recipient has no public key", func(t *testing.T) {
+		assertStatus(t, e.post(t, url, e.adminToken(t), body), http.StatusConflict)
+	})
+
+	_, publicKey, err := keyseal.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	recipient.KeySharePublicKey = publicKey
+	if err := e.deps.agents.Update(context.Background(), recipient); err != nil {
+		t.Fatalf("update agent: %v", err)
+	}
+
+	t.Run("creates a pending share", func(t *testing.T) {
+		resp := e.post(t, url, e.adminToken(t), body)
+		assertStatus(t, resp, http.StatusCreated)
+		var share keyShareResponse
+		decodeData(t, resp, &share)
+		if share.Status != "pending" || share.KeyFingerprint != "fp-repo" {
+			t.Errorf("share = %+v, want a pending share of fp-repo", share)
+		}
+		if share.RecipientKeyFingerprint != keyseal.Fingerprint(publicKey) {
+			t.Errorf("recipient_key_fingerprint = %q, want %q", share.RecipientKeyFingerprint, keyseal.Fingerprint(publicKey))
+		}
+
+		resp = e.get(t, url, e.adminToken(t))
+		assertStatus(t, resp, http.StatusOK)
+		var shares []keyShareResponse
+		decodeData(t, resp, &shares)
+		if len(shares) != 1 || shares[0].ID != share.ID {
+			t.Errorf("shares = %+v, want the created share", shares)
+		}
+	})
+
+	t.Run("returns 400 for a regular policy", func(t *testing.T) {
+		p := createDBPolicy(t, e.deps, "regular", owner.ID)
+		assertStatus(t, e.post(t, "/api/v1/policies/"+p.ID.String()+"/key-shares", e.adminToken(t), body), http.StatusBadRequest)
+	})
+}
+
+func TestKeyHandler_EscrowKit(t *testing.T) {
+	e := newTestEnv(t)
+	agent := createDBAgent(t, e.deps, "agent")
+	url := "/api/v1/agents/" + agent.ID.String() + "/escrow-kit"
+
+	t.Run("returns 404 when the agent has not exported a kit", func(t *testing.T) {
+		assertStatus(t, e.get(t, url, e.adminToken(t)), http.StatusNotFound)
+	})
+
+	if err := e.deps.keys.SaveEscrowKit(context.Background(), &db.KeyEscrowKit{
+		AgentID:              agent.ID,
+		EscrowKeyFingerprint: "fp-escrow",
+		Kit:                  "c2VhbGVk",
+		KeyCount:             2,
+	}); err != nil {
+		t.Fatalf("SaveEscrowKit: %v", err)
+	}
+
+	t.Run("returns 403 for non-admin", func(t *testing.T) {
+		assertStatus(t, e.get(t, url, e.userToken(t)), http.StatusForbidden)
+	})
+
+	t.Run("returns the latest kit", func(t *testing.T) {
+		resp := e.get(t, url, e.adminToken(t))
+		assertStatus(t, resp, http.StatusOK)
+		var kit escrowKitResponse
+		decodeData(t, resp, &kit)
+		if kit.Kit != "c2VhbGVk" || kit.KeyCount != 2 || kit.EscrowKeyFingerprint != "fp-escrow" {
+			t.Errorf("kit = %+v", kit)
+		}
+	})
+}
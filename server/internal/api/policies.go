@@ -1,8 +1,13 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,16 +23,18 @@ import (
 type PolicyHandler struct {
 	repo      repositories.PolicyRepository
 	agentRepo repositories.AgentRepository
+	repoRepo  repositories.RepositoryRepository
 	scheduler *scheduler.Scheduler
 	auditRepo repositories.AuditRepository
 	logger    *zap.Logger
 }
 
 // NewPolicyHandler creates a new PolicyHandler.
-func NewPolicyHandler(repo repositories.PolicyRepository, agentRepo repositories.AgentRepository, sched *scheduler.Scheduler, auditRepo repositories.AuditRepository, logger *zap.Logger) *PolicyHandler {
+func NewPolicyHandler(repo repositories.PolicyRepository, agentRepo repositories.AgentRepository, repoRepo repositories.RepositoryRepository, sched *scheduler.Scheduler, auditRepo repositories.AuditRepository, logger *zap.Logger) *PolicyHandler {
 	return &PolicyHandler{
 		repo:      repo,
 		agentRepo: agentRepo,
+		repoRepo:  repoRepo,
 		scheduler: sched,
 		auditRepo: auditRepo,
 		logger:    logger.Named("policy_handler"),
@@ -40,61 +47,167 @@ func NewPolicyHandler(repo repositories.PolicyRepository, agentRepo repositories
 
 // policyDestinationResponse represents a single destination entry in a policy.
 type policyDestinationResponse struct {
-	ID            string `json:"id"`
-	DestinationID string `json:"destination_id"`
-	Priority      int    `json:"priority"`
+	ID            string  `json:"id"`
+	DestinationID string  `json:"destination_id"`
+	Priority      int     `json:"priority"`
+	RepositoryID  *string `json:"repository_id"` // nil = the destination root with the policy's password
+	// KeyFingerprint identifies the agent-held key of a zero-knowledge
+	// policy's repository; empty until the agent reports it.
+	KeyFingerprint string `json:"key_fingerprint,omitempty"`
+}
+
+// policyDependencyResponse is a single "run after" entry in a policy response.
+type policyDependencyResponse struct {
+	PolicyID  string `json:"policy_id"`
+	Condition string `json:"condition"`
+}
+
+// scheduleRetention holds the keep-* values of a schedule entry with its
+// own retention.
+type scheduleRetention struct {
+	Hourly  int `json:"hourly"`
+	Daily   int `json:"daily"`
+	Weekly  int `json:"weekly"`
+	Monthly int `json:"monthly"`
+	Yearly  int `json:"yearly"`
+}
+
+// policyScheduleResponse is a single additional schedule entry in a policy
+// response. Retention is null when the entry uses the policy's retention.
+type policyScheduleResponse struct {
+	ID        string             `json:"id"`
+	Schedule  string             `json:"schedule"`
+	Tags      []string           `json:"tags"`
+	Retention *scheduleRetention `json:"retention"`
 }
 
 // policyResponse is the JSON representation of a policy.
 // RepoPassword is intentionally omitted — it is write-only.
 type policyResponse struct {
-	ID               string                      `json:"id"`
-	Name             string                      `json:"name"`
-	AgentID          string                      `json:"agent_id"`
-	AgentName        string                      `json:"agent_name"`
-	Schedule         string                      `json:"schedule"`
-	Enabled          bool                        `json:"enabled"`
-	Sources          string                      `json:"sources"`
-	RetentionDaily   int                         `json:"retention_daily"`
-	RetentionWeekly  int                         `json:"retention_weekly"`
-	RetentionMonthly int                         `json:"retention_monthly"`
-	RetentionYearly  int                         `json:"retention_yearly"`
-	HookPreBackup    string                      `json:"hook_pre_backup"`
-	HookPostBackup   string                      `json:"hook_post_backup"`
-	Destinations     []policyDestinationResponse `json:"destinations"`
-	LastRunAt        *string                     `json:"last_run_at"`
-	NextRunAt        *string                     `json:"next_run_at"`
-	CreatedAt        string                      `json:"created_at"`
+	ID                string                      `json:"id"`
+	Name              string                      `json:"name"`
+	AgentID           string                      `json:"agent_id"`
+	AgentName         string                      `json:"agent_name"`
+	Schedule          string                      `json:"schedule"`
+	Timezone          string                      `json:"timezone"`
+	Enabled           bool                        `json:"enabled"`
+	Sources           string                      `json:"sources"`
+	RetentionDaily    int                         `json:"retention_daily"`
+	RetentionWeekly   int                         `json:"retention_weekly"`
+	RetentionMonthly  int                         `json:"retention_monthly"`
+	RetentionYearly   int                         `json:"retention_yearly"`
+	Hooks             scheduler.Hooks             `json:"hooks"`
+	CatchUp           bool                        `json:"catch_up"`
+	StartJitter       int                         `json:"start_jitter_seconds"`
+	RetryMaxAttempts  int                         `json:"retry_max_attempts"`
+	RetryBackoff      int                         `json:"retry_backoff_seconds"`
+	RetryOn           []string                    `json:"retry_on"`
+	MaxRuntime        int                         `json:"max_runtime_seconds"`
+	AnomalyDetection  bool                        `json:"anomaly_detection"`
+	AnomalyHold       bool                        `json:"anomaly_hold"`
+	WarningsAsFailure bool                        `json:"warnings_as_failure"`
+	FanOut            string                      `json:"fan_out"`
+	FanOutParallelism int                         `json:"fan_out_parallelism"`
+	Type              string                      `json:"type"`
+	ReplicateFromID   *string                     `json:"replicate_from_id"`
+	ReplicateTag      string                      `json:"replicate_tag"`
+	ReplicateHost     string                      `json:"replicate_host"`
+	ReplicatePolicyID *string                     `json:"replicate_policy_id"`
+	ZeroKnowledge     bool                        `json:"zero_knowledge"`
+	Destinations      []policyDestinationResponse `json:"destinations"`
+	RunAfter          []policyDependencyResponse  `json:"run_after"`
+	Schedules         []policyScheduleResponse    `json:"schedules"`
+	LastRunAt         *string                     `json:"last_run_at"`
+	NextRunAt         *string                     `json:"next_run_at"`
+	CreatedAt         string                      `json:"created_at"`
 }
 
 // policyToResponse converts a db.Policy and its associated PolicyDestination
 // slice to a policyResponse. The destinations are passed separately because
 // they are no longer embedded in the Policy struct (see db/models.go).
+// dependencies are the policies p runs after and schedules its additional
+// schedule entries; the list endpoint passes nil for both.
 // agentName is passed in from the caller to avoid an extra DB lookup per policy.
-func policyToResponse(p *db.Policy, destinations []db.PolicyDestination, agentName string) policyResponse {
+func policyToResponse(p *db.Policy, destinations []db.PolicyDestination, dependencies []db.PolicyDependency, schedules []db.PolicySchedule, agentName string) policyResponse {
+	hooks := policyHooks(p.Hooks)
 	resp := policyResponse{
-		ID:               p.ID.String(),
-		Name:             p.Name,
-		AgentID:          p.AgentID.String(),
-		AgentName:        agentName,
-		Schedule:         p.Schedule,
-		Enabled:          p.Enabled,
-		Sources:          p.Sources,
-		RetentionDaily:   p.RetentionDaily,
-		RetentionWeekly:  p.RetentionWeekly,
-		RetentionMonthly: p.RetentionMonthly,
-		RetentionYearly:  p.RetentionYearly,
-		HookPreBackup:    p.HookPreBackup,
-		HookPostBackup:   p.HookPostBackup,
-		Destinations:     make([]policyDestinationResponse, len(destinations)),
-		CreatedAt:        p.CreatedAt.UTC().Format(time.RFC3339),
+		ID:                p.ID.String(),
+		Name:              p.Name,
+		AgentID:           p.AgentID.String(),
+		AgentName:         agentName,
+		Schedule:          p.Schedule,
+		Timezone:          p.Timezone,
+		Enabled:           p.Enabled,
+		Sources:           p.Sources,
+		RetentionDaily:    p.RetentionDaily,
+		RetentionWeekly:   p.RetentionWeekly,
+		RetentionMonthly:  p.RetentionMonthly,
+		RetentionYearly:   p.RetentionYearly,
+		Hooks:             hooks,
+		CatchUp:           p.CatchUp,
+		StartJitter:       p.StartJitterSeconds,
+		RetryMaxAttempts:  p.RetryMaxAttempts,
+		RetryBackoff:      p.RetryBackoffSeconds,
+		RetryOn:           splitRetryOn(p.RetryOn),
+		MaxRuntime:        p.MaxRuntimeSeconds,
+		AnomalyDetection:  p.AnomalyDetection,
+		AnomalyHold:       p.AnomalyHold,
+		WarningsAsFailure: p.WarningsAsFailure,
+		FanOut:            p.FanOut,
+		FanOutParallelism: p.FanOutParallelism,
+		Type:              p.Type,
+		ReplicateTag:      p.ReplicateTag,
+		ReplicateHost:     p.ReplicateHost,
+		ZeroKnowledge:     p.ZeroKnowledge,
+		Destinations:      make([]policyDestinationResponse, len(destinations)),
+		RunAfter:          make([]policyDependencyResponse, len(dependencies)),
+		Schedules:         make([]policyScheduleResponse, len(schedules)),
+		CreatedAt:         p.CreatedAt.UTC().Format(time.RFC3339),
 	}
 
+	if p.ReplicateFromID != nil {
+		s := p.ReplicateFromID.String()
+		resp.ReplicateFromID = &s
+	}
+	if p.ReplicatePolicyID != nil {
+		s := p.ReplicatePolicyID.String()
+		resp.ReplicatePolicyID = &s
+	}
 	for i, pd := range destinations {
 		resp.Destinations[i] = policyDestinationResponse{
-			ID:            pd.ID.String(),
-			DestinationID: pd.DestinationID.String(),
-			Priority:      pd.Priority,
+			ID:             pd.ID.String(),
+			DestinationID:  pd.DestinationID.String(),
+			Priority:       pd.Priority,
+			KeyFingerprint: pd.KeyFingerprint,
+		}
+		if pd.RepositoryID != nil {
+			s := pd.RepositoryID.String()
+			resp.Destinations[i].RepositoryID = &s
+		}
+	}
+	for i, dep := range dependencies {
+		resp.RunAfter[i] = policyDependencyResponse{
+			PolicyID:  dep.DependsOnID.String(),
+			Condition: dep.Condition,
+		}
+	}
+	for i, ps := range schedules {
+		resp.Schedules[i] = policyScheduleResponse{
+			ID:       ps.ID.String(),
+			Schedule: ps.Schedule,
+			Tags:     strings.Split(ps.Tags, ","),
+		}
+		if ps.Tags == "" {
+			resp.Schedules[i].Tags = []string{}
+		}
+		if ps.CustomRetention {
+			resp.Schedules[i].Retention = &scheduleRetention{
+				Hourly:  ps.RetentionHourly,
+				Daily:   ps.RetentionDaily,
+				Weekly:  ps.RetentionWeekly,
+				Monthly: ps.RetentionMonthly,
+				Yearly:  ps.RetentionYearly,
+			}
 		}
 	}
 
@@ -151,7 +264,7 @@ func (h *PolicyHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	items := make([]policyResponse, len(policies))
 	for i := range policies {
-		items[i] = policyToResponse(&policies[i], nil, agentNameByID[policies[i].AgentID.String()])
+		items[i] = policyToResponse(&policies[i], nil, nil, nil, agentNameByID[policies[i].AgentID.String()])
 	}
 
 	Ok(w, listPoliciesResponse{Items: items, Total: total})
@@ -159,24 +272,63 @@ func (h *PolicyHandler) List(w http.ResponseWriter, r *http.Request) {
 
 // createPolicyRequest is the JSON body expected by POST /api/v1/policies.
 type createPolicyRequest struct {
-	Name             string                    `json:"name"`
-	AgentID          string                    `json:"agent_id"`
-	Schedule         string                    `json:"schedule"`
-	Sources          string                    `json:"sources"` // JSON array
-	RepoPassword     string                    `json:"repo_password"`
-	RetentionDaily   int                       `json:"retention_daily"`
-	RetentionWeekly  int                       `json:"retention_weekly"`
-	RetentionMonthly int                       `json:"retention_monthly"`
-	RetentionYearly  int                       `json:"retention_yearly"`
-	HookPreBackup    string                    `json:"hook_pre_backup"`
-	HookPostBackup   string                    `json:"hook_post_backup"`
-	Destinations     []destinationEntryRequest `json:"destinations"`
+	Name              string                    `json:"name"`
+	AgentID           string                    `json:"agent_id"`
+	Schedule          string                    `json:"schedule"`
+	Timezone          string                    `json:"timezone"` // IANA zone, empty = server local time
+	Sources           string                    `json:"sources"`  // JSON array
+	RepoPassword      string                    `json:"repo_password"`
+	RetentionDaily    int                       `json:"retention_daily"`
+	RetentionWeekly   int                       `json:"retention_weekly"`
+	RetentionMonthly  int                       `json:"retention_monthly"`
+	RetentionYearly   int                       `json:"retention_yearly"`
+	Hooks             scheduler.Hooks           `json:"hooks"` // phase → ordered steps
+	CatchUp           bool                      `json:"catch_up"`
+	StartJitter       int                       `json:"start_jitter_seconds"`  // max start delay, 0 = none
+	RetryMaxAttempts  int                       `json:"retry_max_attempts"`    // total attempts per run, 0 = default (1)
+	RetryBackoff      int                       `json:"retry_backoff_seconds"` // 0 = default (300)
+	RetryOn           []string                  `json:"retry_on"`              // failure classes, nil = default (network)
+	MaxRuntime        int                       `json:"max_runtime_seconds"`   // 0 = no limit
+	AnomalyDetection  *bool                     `json:"anomaly_detection"`     // nil = default (true)
+	AnomalyHold       bool                      `json:"anomaly_hold"`
+	WarningsAsFailure bool                      `json:"warnings_as_failure"` // notify runs with unreadable files as failures
+	FanOut            string                    `json:"fan_out"`             // "", "sequential", "parallel" or "replicate"
+	FanOutParallelism int                       `json:"fan_out_parallelism"` // parallel backups at once, 0 = all
+	Type              string                    `json:"type"`                // "backup" (default) or "replication"
+	ReplicateFromID   string                    `json:"replicate_from_id"`   // replication: source destination
+	ReplicateTag      string                    `json:"replicate_tag"`       // replication: only snapshots with this tag
+	ReplicateHost     string                    `json:"replicate_host"`      // replication: only snapshots of this host
+	ReplicatePolicyID string                    `json:"replicate_policy_id"` // replication: only snapshots of this policy
+	ZeroKnowledge     bool                      `json:"zero_knowledge"`      // repository keys stay on the agent, set at creation only
+	Destinations      []destinationEntryRequest `json:"destinations"`
+	RunAfter          []dependencyEntryRequest  `json:"run_after"` // upstream policies, schedule may be empty if set
+	Schedules         []scheduleEntryRequest    `json:"schedules"` // additional schedules, schedule may be empty if set
 }
 
 // destinationEntryRequest represents a single destination entry in a create/update request.
 type destinationEntryRequest struct {
 	DestinationID string `json:"destination_id"`
 	Priority      int    `json:"priority"`
+	RepositoryID  string `json:"repository_id"` // optional, a repository on the destination
+	RepoPassword  string `json:"repo_password"` // optional, stored encrypted; see Create
+}
+
+// dependencyEntryRequest represents a single "run after" entry in a
+// create/update request. Condition defaults to "success".
+type dependencyEntryRequest struct {
+	PolicyID  string `json:"policy_id"`
+	Condition string `json:"condition"`
+}
+
+// scheduleEntryRequest represents a single additional schedule entry in a
+// create/update request. ID identifies an existing entry to update in place;
+// entries without one are created. A nil Retention applies the policy's
+// retention to the entry's snapshots.
+type scheduleEntryRequest struct {
+	ID        string             `json:"id"`
+	Schedule  string             `json:"schedule"`
+	Tags      []string           `json:"tags"`
+	Retention *scheduleRetention `json:"retention"`
 }
 
 // Create handles POST /api/v1/policies.
@@ -194,7 +346,7 @@ func (h *PolicyHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Hook commands execute with agent privileges — only admins may set them.
-	if (req.HookPreBackup != "" || req.HookPostBackup != "") && !isAdmin(r) {
+	if req.Hooks.Phases(scheduler.HookPhases...) != nil && !isAdmin(r) {
 		ErrForbidden(w)
 		return
 	}
@@ -205,6 +357,61 @@ func (h *PolicyHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A new policy has no dependents yet, so its dependencies cannot form a
+	// cycle; only the upstream policies need to be checked.
+	dependencies, msg, err := h.parseDependencies(r.Context(), uuid.Nil, req.RunAfter)
+	if err != nil {
+		h.logger.Error("failed to validate policy dependencies", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if msg != "" {
+		ErrBadRequest(w, msg)
+		return
+	}
+
+	schedules, err := parseSchedules(req.Schedules)
+	if err != nil {
+		ErrBadRequest(w, err.Error())
+		return
+	}
+
+	repositoryIDs, msg, err := h.parseRepositories(r.Context(), req.Destinations)
+	if err != nil {
+		h.logger.Error("failed to validate policy repositories", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if msg != "" {
+		ErrBadRequest(w, msg)
+		return
+	}
+	// Zero-knowledge destinations have no server-side password: the agent
+	// generates the key on the first backup.
+	passwords := make([]string, len(req.Destinations))
+	if !req.ZeroKnowledge {
+		passwords, err = destinationPasswords(req.RepoPassword, req.Destinations, repositoryIDs)
+	}
+	if err != nil {
+		h.logger.Error("failed to generate repository passwords", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	var replicateFromID, replicatePolicyID *uuid.UUID
+	if req.Type == scheduler.PolicyTypeReplication {
+		replicateFromID, replicatePolicyID, msg, err = h.parseReplication(r.Context(), req.ReplicateFromID, req.ReplicatePolicyID)
+		if err != nil {
+			h.logger.Error("failed to validate replication settings", zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		if msg != "" {
+			ErrBadRequest(w, msg)
+			return
+		}
+	}
+
 	// Apply retention defaults for zero values.
 	if req.RetentionDaily == 0 {
 		req.RetentionDaily = 7
@@ -219,19 +426,60 @@ func (h *PolicyHandler) Create(w http.ResponseWriter, r *http.Request) {
 		req.RetentionYearly = 1
 	}
 
+	// Apply retry defaults: a single attempt, so retries are opt-in.
+	if req.RetryMaxAttempts == 0 {
+		req.RetryMaxAttempts = 1
+	}
+	if req.RetryBackoff == 0 {
+		req.RetryBackoff = 300
+	}
+	if req.RetryOn == nil {
+		req.RetryOn = []string{scheduler.FailureNetwork}
+	}
+	if req.FanOut == "" {
+		req.FanOut = scheduler.FanOutSequential
+	}
+	if req.Type == "" {
+		req.Type = scheduler.PolicyTypeBackup
+	}
+	if req.Sources == "" {
+		// Replication policies have no sources.
+		req.Sources = "[]"
+	}
+
+	// Anomaly detection is on by default; holding snapshots is opt-in.
+	anomalyDetection := req.AnomalyDetection == nil || *req.AnomalyDetection
+
 	policy := &db.Policy{
-		Name:             req.Name,
-		AgentID:          agentID,
-		Schedule:         req.Schedule,
-		Enabled:          true,
-		Sources:          req.Sources,
-		RepoPassword:     db.EncryptedString(req.RepoPassword),
-		RetentionDaily:   req.RetentionDaily,
-		RetentionWeekly:  req.RetentionWeekly,
-		RetentionMonthly: req.RetentionMonthly,
-		RetentionYearly:  req.RetentionYearly,
-		HookPreBackup:    req.HookPreBackup,
-		HookPostBackup:   req.HookPostBackup,
+		Name:                req.Name,
+		AgentID:             agentID,
+		Schedule:            req.Schedule,
+		Timezone:            req.Timezone,
+		Enabled:             true,
+		Sources:             req.Sources,
+		RepoPassword:        db.EncryptedString(req.RepoPassword),
+		RetentionDaily:      req.RetentionDaily,
+		RetentionWeekly:     req.RetentionWeekly,
+		RetentionMonthly:    req.RetentionMonthly,
+		RetentionYearly:     req.RetentionYearly,
+		Hooks:               hooksJSON(req.Hooks),
+		CatchUp:             req.CatchUp,
+		StartJitterSeconds:  req.StartJitter,
+		RetryMaxAttempts:    req.RetryMaxAttempts,
+		RetryBackoffSeconds: req.RetryBackoff,
+		RetryOn:             strings.Join(req.RetryOn, ","),
+		MaxRuntimeSeconds:   req.MaxRuntime,
+		AnomalyDetection:    anomalyDetection,
+		AnomalyHold:         req.AnomalyHold,
+		WarningsAsFailure:   req.WarningsAsFailure,
+		FanOut:              req.FanOut,
+		FanOutParallelism:   req.FanOutParallelism,
+		Type:                req.Type,
+		ReplicateFromID:     replicateFromID,
+		ReplicateTag:        req.ReplicateTag,
+		ReplicateHost:       req.ReplicateHost,
+		ReplicatePolicyID:   replicatePolicyID,
+		ZeroKnowledge:       req.ZeroKnowledge,
 	}
 
 	if err := h.repo.Create(r.Context(), policy); err != nil {
@@ -239,9 +487,22 @@ func (h *PolicyHandler) Create(w http.ResponseWriter, r *http.Request) {
 		ErrInternal(w)
 		return
 	}
+	// GORM's Create skips zero-value booleans and falls back to the column
+	// default (true), so a disabled anomaly detection needs an explicit save.
+	if !anomalyDetection {
+		policy.AnomalyDetection = false
+		if err := h.repo.Update(r.Context(), policy); err != nil {
+			h.logger.Error("failed to disable anomaly detection on new policy",
+				zap.String("policy_id", policy.ID.String()),
+				zap.Error(err),
+			)
+			ErrInternal(w)
+			return
+		}
+	}
 
 	// Add destination associations.
-	for _, d := range req.Destinations {
+	for i, d := range req.Destinations {
 		destID, err := uuid.Parse(d.DestinationID)
 		if err != nil {
 			h.logger.Warn("skipping invalid destination_id in policy create",
@@ -253,6 +514,8 @@ func (h *PolicyHandler) Create(w http.ResponseWriter, r *http.Request) {
 			PolicyID:      policy.ID,
 			DestinationID: destID,
 			Priority:      d.Priority,
+			RepositoryID:  repositoryIDs[i],
+			RepoPassword:  db.EncryptedString(passwords[i]),
 		}
 		if err := h.repo.AddDestination(r.Context(), pd); err != nil {
 			h.logger.Error("failed to add destination to policy",
@@ -263,6 +526,24 @@ func (h *PolicyHandler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if len(dependencies) > 0 {
+		if err := h.repo.SetDependencies(r.Context(), policy.ID, dependencies); err != nil {
+			h.logger.Error("failed to set policy dependencies",
+				zap.String("policy_id", policy.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	if len(schedules) > 0 {
+		if err := h.repo.SetSchedules(r.Context(), policy.ID, schedules); err != nil {
+			h.logger.Error("failed to set policy schedules",
+				zap.String("policy_id", policy.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
 	// Reload with destinations to return the full representation.
 	full, destinations, err := h.repo.GetByIDWithDestinations(r.Context(), policy.ID)
 	if err != nil {
@@ -287,7 +568,15 @@ func (h *PolicyHandler) Create(w http.ResponseWriter, r *http.Request) {
 		agentName = agent.Name
 	}
 	logAudit(r, h.auditRepo, h.logger, "policy.create", "policy", policy.ID.String(), map[string]any{"name": policy.Name, "schedule": policy.Schedule, "enabled": policy.Enabled})
-	Created(w, policyToResponse(full, destinations, agentName))
+	dependencies, err = h.repo.ListDependencies(r.Context(), policy.ID)
+	if err != nil {
+		h.logger.Error("failed to list policy dependencies", zap.String("policy_id", policy.ID.String()), zap.Error(err))
+	}
+	schedules, err = h.repo.ListSchedules(r.Context(), policy.ID)
+	if err != nil {
+		h.logger.Error("failed to list policy schedules", zap.String("policy_id", policy.ID.String()), zap.Error(err))
+	}
+	Created(w, policyToResponse(full, destinations, dependencies, schedules, agentName))
 }
 
 // GetByID handles GET /api/v1/policies/{id}.
@@ -314,23 +603,55 @@ func (h *PolicyHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		agentName = agent.Name
 	}
 
-	Ok(w, policyToResponse(policy, destinations, agentName))
+	dependencies, err := h.repo.ListDependencies(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to list policy dependencies", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	schedules, err := h.repo.ListSchedules(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to list policy schedules", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	Ok(w, policyToResponse(policy, destinations, dependencies, schedules, agentName))
 }
 
 // updatePolicyRequest is the JSON body for PATCH /api/v1/policies/{id}.
 // All fields are optional — only non-nil values are applied.
 type updatePolicyRequest struct {
-	Name             *string `json:"name"`
-	Schedule         *string `json:"schedule"`
-	Enabled          *bool   `json:"enabled"`
-	Sources          *string `json:"sources"`
-	RepoPassword     *string `json:"repo_password"`
-	RetentionDaily   *int    `json:"retention_daily"`
-	RetentionWeekly  *int    `json:"retention_weekly"`
-	RetentionMonthly *int    `json:"retention_monthly"`
-	RetentionYearly  *int    `json:"retention_yearly"`
-	HookPreBackup    *string `json:"hook_pre_backup"`
-	HookPostBackup   *string `json:"hook_post_backup"`
+	Name              *string                   `json:"name"`
+	Schedule          *string                   `json:"schedule"`
+	Timezone          *string                   `json:"timezone"`
+	Enabled           *bool                     `json:"enabled"`
+	Sources           *string                   `json:"sources"`
+	RepoPassword      *string                   `json:"repo_password"`
+	RetentionDaily    *int                      `json:"retention_daily"`
+	RetentionWeekly   *int                      `json:"retention_weekly"`
+	RetentionMonthly  *int                      `json:"retention_monthly"`
+	RetentionYearly   *int                      `json:"retention_yearly"`
+	Hooks             *scheduler.Hooks          `json:"hooks"`
+	CatchUp           *bool                     `json:"catch_up"`
+	StartJitter       *int                      `json:"start_jitter_seconds"`
+	RetryMaxAttempts  *int                      `json:"retry_max_attempts"`
+	RetryBackoff      *int                      `json:"retry_backoff_seconds"`
+	RetryOn           *[]string                 `json:"retry_on"`
+	MaxRuntime        *int                      `json:"max_runtime_seconds"`
+	AnomalyDetection  *bool                     `json:"anomaly_detection"`
+	AnomalyHold       *bool                     `json:"anomaly_hold"`
+	WarningsAsFailure *bool                     `json:"warnings_as_failure"`
+	FanOut            *string                   `json:"fan_out"`
+	FanOutParallelism *int                      `json:"fan_out_parallelism"`
+	ReplicateFromID   *string                   `json:"replicate_from_id"`
+	ReplicateTag      *string                   `json:"replicate_tag"`
+	ReplicateHost     *string                   `json:"replicate_host"`
+	ReplicatePolicyID *string                   `json:"replicate_policy_id"` // "" = all policies
+	ZeroKnowledge     *bool                     `json:"zero_knowledge"`      // rejected if changed
+	RunAfter          *[]dependencyEntryRequest `json:"run_after"`
+	Schedules         *[]scheduleEntryRequest   `json:"schedules"`
 }
 
 // Update handles PATCH /api/v1/policies/{id}.
@@ -367,23 +688,42 @@ func (h *PolicyHandler) Update(w http.ResponseWriter, r *http.Request) {
 		policy.Name = *req.Name
 	}
 	if req.Schedule != nil {
-		if *req.Schedule == "" {
-			ErrBadRequest(w, "schedule cannot be empty")
-			return
+		if *req.Schedule != "" {
+			if err := validateSchedule(*req.Schedule); err != nil {
+				ErrBadRequest(w, err.Error())
+				return
+			}
 		}
-		if err := validateSchedule(*req.Schedule); err != nil {
+		policy.Schedule = *req.Schedule
+	}
+	if req.Timezone != nil {
+		if err := validateTimezone(*req.Timezone); err != nil {
 			ErrBadRequest(w, err.Error())
 			return
 		}
-		policy.Schedule = *req.Schedule
+		policy.Timezone = *req.Timezone
 	}
 	if req.Enabled != nil {
 		policy.Enabled = *req.Enabled
 	}
 	if req.Sources != nil {
+		if err := validateSources(*req.Sources); err != nil {
+			ErrBadRequest(w, err.Error())
+			return
+		}
 		policy.Sources = *req.Sources
 	}
+	// Switching modes would strand the existing repositories: their keys are
+	// either on the server or on the agent, never both.
+	if req.ZeroKnowledge != nil && *req.ZeroKnowledge != policy.ZeroKnowledge {
+		ErrBadRequest(w, "zero_knowledge cannot be changed after creation")
+		return
+	}
 	if req.RepoPassword != nil {
+		if policy.ZeroKnowledge && *req.RepoPassword != "" {
+			ErrBadRequest(w, "repo_password cannot be set on a zero-knowledge policy")
+			return
+		}
 		policy.RepoPassword = db.EncryptedString(*req.RepoPassword)
 	}
 	if req.RetentionDaily != nil {
@@ -398,29 +738,175 @@ func (h *PolicyHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.RetentionYearly != nil {
 		policy.RetentionYearly = *req.RetentionYearly
 	}
-	if req.HookPreBackup != nil {
-		if err := validateHookCommand(*req.HookPreBackup); err != nil {
-			ErrBadRequest(w, "hook_pre_backup: "+err.Error())
+	if req.RetentionDaily != nil || req.RetentionWeekly != nil || req.RetentionMonthly != nil || req.RetentionYearly != nil {
+		if policy.RetentionDaily < 0 || policy.RetentionWeekly < 0 || policy.RetentionMonthly < 0 || policy.RetentionYearly < 0 {
+			ErrBadRequest(w, "retention values cannot be negative")
 			return
 		}
-		// Hook commands execute with agent privileges — only admins may change them.
-		if *req.HookPreBackup != policy.HookPreBackup && !isAdmin(r) {
-			ErrForbidden(w)
+		if policy.RetentionDaily == 0 && policy.RetentionWeekly == 0 && policy.RetentionMonthly == 0 && policy.RetentionYearly == 0 {
+			ErrBadRequest(w, "retention must keep at least one snapshot")
 			return
 		}
-		policy.HookPreBackup = *req.HookPreBackup
 	}
-	if req.HookPostBackup != nil {
-		if err := validateHookCommand(*req.HookPostBackup); err != nil {
-			ErrBadRequest(w, "hook_post_backup: "+err.Error())
+	if req.Hooks != nil {
+		if err := validateHooks(*req.Hooks); err != nil {
+			ErrBadRequest(w, "hooks: "+err.Error())
 			return
 		}
 		// Hook commands execute with agent privileges — only admins may change them.
-		if *req.HookPostBackup != policy.HookPostBackup && !isAdmin(r) {
+		hooks := hooksJSON(*req.Hooks)
+		if hooks != hooksJSON(policyHooks(policy.Hooks)) && !isAdmin(r) {
 			ErrForbidden(w)
 			return
 		}
-		policy.HookPostBackup = *req.HookPostBackup
+		policy.Hooks = hooks
+	}
+	if req.CatchUp != nil {
+		policy.CatchUp = *req.CatchUp
+	}
+	if req.AnomalyDetection != nil {
+		policy.AnomalyDetection = *req.AnomalyDetection
+	}
+	if req.AnomalyHold != nil {
+		policy.AnomalyHold = *req.AnomalyHold
+	}
+	if req.WarningsAsFailure != nil {
+		policy.WarningsAsFailure = *req.WarningsAsFailure
+	}
+	if req.StartJitter != nil {
+		if err := validateStartJitter(*req.StartJitter); err != nil {
+			ErrBadRequest(w, err.Error())
+			return
+		}
+		policy.StartJitterSeconds = *req.StartJitter
+	}
+	if req.RetryMaxAttempts != nil {
+		if err := validateRetryMaxAttempts(*req.RetryMaxAttempts); err != nil {
+			ErrBadRequest(w, err.Error())
+			return
+		}
+		policy.RetryMaxAttempts = *req.RetryMaxAttempts
+	}
+	if req.RetryBackoff != nil {
+		if err := validateRetryBackoff(*req.RetryBackoff); err != nil {
+			ErrBadRequest(w, err.Error())
+			return
+		}
+		policy.RetryBackoffSeconds = *req.RetryBackoff
+	}
+	if req.RetryOn != nil {
+		if err := validateRetryOn(*req.RetryOn); err != nil {
+			ErrBadRequest(w, err.Error())
+			return
+		}
+		policy.RetryOn = strings.Join(*req.RetryOn, ",")
+	}
+	if req.MaxRuntime != nil {
+		if err := validateMaxRuntime(*req.MaxRuntime); err != nil {
+			ErrBadRequest(w, err.Error())
+			return
+		}
+		policy.MaxRuntimeSeconds = *req.MaxRuntime
+	}
+	if req.FanOut != nil {
+		if err := validateFanOut(*req.FanOut); err != nil {
+			ErrBadRequest(w, err.Error())
+			return
+		}
+		policy.FanOut = *req.FanOut
+	}
+	if req.FanOutParallelism != nil {
+		if err := validateFanOutParallelism(*req.FanOutParallelism); err != nil {
+			ErrBadRequest(w, err.Error())
+			return
+		}
+		policy.FanOutParallelism = *req.FanOutParallelism
+	}
+	if req.ReplicateFromID != nil || req.ReplicateTag != nil || req.ReplicateHost != nil || req.ReplicatePolicyID != nil {
+		if policy.Type != scheduler.PolicyTypeReplication {
+			ErrBadRequest(w, "replicate_* settings only apply to replication policies")
+			return
+		}
+		from, replicated := "", ""
+		if policy.ReplicateFromID != nil {
+			from = policy.ReplicateFromID.String()
+		}
+		if policy.ReplicatePolicyID != nil {
+			replicated = policy.ReplicatePolicyID.String()
+		}
+		if req.ReplicateFromID != nil {
+			from = *req.ReplicateFromID
+		}
+		if req.ReplicatePolicyID != nil {
+			replicated = *req.ReplicatePolicyID
+		}
+		if req.ReplicateTag != nil {
+			if err := validateReplicateTag(*req.ReplicateTag); err != nil {
+				ErrBadRequest(w, err.Error())
+				return
+			}
+			policy.ReplicateTag = *req.ReplicateTag
+		}
+		if req.ReplicateHost != nil {
+			policy.ReplicateHost = *req.ReplicateHost
+		}
+		fromID, replicatedID, msg, err := h.parseReplication(r.Context(), from, replicated)
+		if err != nil {
+			h.logger.Error("failed to validate replication settings", zap.String("id", id.String()), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		if msg != "" {
+			ErrBadRequest(w, msg)
+			return
+		}
+		for _, d := range destinations {
+			if d.DestinationID == *fromID {
+				ErrBadRequest(w, "replicate_from_id: the source cannot be one of the policy's destinations")
+				return
+			}
+		}
+		policy.ReplicateFromID, policy.ReplicatePolicyID = fromID, replicatedID
+	}
+
+	dependencies, err := h.repo.ListDependencies(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to list policy dependencies", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if req.RunAfter != nil {
+		var msg string
+		dependencies, msg, err = h.parseDependencies(r.Context(), id, *req.RunAfter)
+		if err != nil {
+			h.logger.Error("failed to validate policy dependencies", zap.String("id", id.String()), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		if msg != "" {
+			ErrBadRequest(w, msg)
+			return
+		}
+	}
+
+	schedules, err := h.repo.ListSchedules(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to list policy schedules", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if req.Schedules != nil {
+		if schedules, err = parseSchedules(*req.Schedules); err != nil {
+			ErrBadRequest(w, err.Error())
+			return
+		}
+	}
+
+	// Without a schedule the policy only runs after its dependencies or on
+	// its schedule entries.
+	if policy.Schedule == "" && len(dependencies) == 0 && len(schedules) == 0 {
+		ErrBadRequest(w, "schedule cannot be empty unless run_after or schedules is set")
+		return
 	}
 
 	if err := h.repo.Update(r.Context(), policy); err != nil {
@@ -429,6 +915,28 @@ func (h *PolicyHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.RunAfter != nil {
+		if err := h.repo.SetDependencies(r.Context(), id, dependencies); err != nil {
+			h.logger.Error("failed to set policy dependencies", zap.String("id", id.String()), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+	}
+
+	if req.Schedules != nil {
+		if err := h.repo.SetSchedules(r.Context(), id, schedules); err != nil {
+			h.logger.Error("failed to set policy schedules", zap.String("id", id.String()), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		// Reload to return the IDs of newly created entries.
+		if schedules, err = h.repo.ListSchedules(r.Context(), id); err != nil {
+			h.logger.Error("failed to list policy schedules", zap.String("id", id.String()), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+	}
+
 	// Sync scheduler: handles enable/disable and schedule changes.
 	if err := h.scheduler.UpdatePolicy(policy); err != nil {
 		h.logger.Error("failed to sync scheduler after policy update",
@@ -438,7 +946,7 @@ func (h *PolicyHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logAudit(r, h.auditRepo, h.logger, "policy.update", "policy", id.String(), map[string]any{"name": policy.Name, "enabled": policy.Enabled})
-	Ok(w, policyToResponse(policy, destinations, ""))
+	Ok(w, policyToResponse(policy, destinations, dependencies, schedules, ""))
 }
 
 // Delete handles DELETE /api/v1/policies/{id}.
@@ -466,10 +974,71 @@ func (h *PolicyHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		)
 	}
 
+	// Policies that ran after this one keep their other dependencies and
+	// schedule; this policy's own edges are dropped.
+	if err := h.repo.SetDependencies(r.Context(), id, nil); err != nil {
+		h.logger.Warn("failed to remove policy dependencies",
+			zap.String("policy_id", id.String()),
+			zap.Error(err),
+		)
+	}
+
 	logAudit(r, h.auditRepo, h.logger, "policy.delete", "policy", id.String(), map[string]any{})
 	NoContent(w)
 }
 
+// destinationPasswordResponse is returned by GET
+// /api/v1/policies/{id}/destinations/{destinationID}/password.
+type destinationPasswordResponse struct {
+	RepoPassword string `json:"repo_password"`
+}
+
+// DestinationPassword handles GET
+// /api/v1/policies/{id}/destinations/{destinationID}/password. It returns
+// the password the policy opens its repository on the destination with, so
+// that the repository can be restored without Arkeep (e.g. for disaster
+// recovery with plain restic). Admin-only and audited.
+func (h *PolicyHandler) DestinationPassword(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	destID, ok := parseUUID(w, r, "destinationID")
+	if !ok {
+		return
+	}
+
+	policy, destinations, err := h.repo.GetByIDWithDestinations(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return
+		}
+		h.logger.Error("failed to get policy for destination password", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if policy.ZeroKnowledge {
+		ErrConflict(w, "the repository keys of a zero-knowledge policy are held by its agent; use the agent's escrow kit")
+		return
+	}
+	for i := range destinations {
+		if destinations[i].DestinationID != destID {
+			continue
+		}
+		_, password, err := policyRepository(r.Context(), h.repoRepo, policy, &destinations[i])
+		if err != nil {
+			h.logger.Error("failed to resolve repository password", zap.String("id", id.String()), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		logAudit(r, h.auditRepo, h.logger, "policy.reveal_password", "policy", id.String(), map[string]any{"destination_id": destID.String()})
+		Ok(w, destinationPasswordResponse{RepoPassword: password})
+		return
+	}
+	ErrNotFound(w)
+}
+
 // Trigger handles POST /api/v1/policies/{id}/trigger.
 // Manually triggers an immediate backup job for the policy, bypassing the
 // cron schedule.
@@ -501,6 +1070,38 @@ func (h *PolicyHandler) Trigger(w http.ResponseWriter, r *http.Request) {
 	Ok(w, map[string]string{"job_id": job.ID.String()})
 }
 
+// DryRun handles POST /api/v1/policies/{id}/dry-run.
+// Starts a "dry_run" job that estimates the size of a first backup of the
+// policy's sources without writing to any destination. The result is
+// returned with the job detail once the agent reports it.
+func (h *PolicyHandler) DryRun(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.scheduler.DryRun(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return
+		}
+		if errors.Is(err, scheduler.ErrReplicationDryRun) {
+			ErrBadRequest(w, err.Error())
+			return
+		}
+		h.logger.Error("failed to start policy dry run",
+			zap.String("policy_id", id.String()),
+			zap.Error(err),
+		)
+		ErrInternal(w)
+		return
+	}
+
+	logAudit(r, h.auditRepo, h.logger, "policy.dry_run", "policy", id.String(), map[string]any{"job_id": job.ID.String()})
+	Ok(w, map[string]string{"job_id": job.ID.String()})
+}
+
 // -----------------------------------------------------------------------------
 // Validation
 // -----------------------------------------------------------------------------
@@ -513,27 +1114,472 @@ func validateCreatePolicy(req *createPolicyRequest) error {
 	if req.AgentID == "" {
 		return errors.New("agent_id is required")
 	}
-	if req.Schedule == "" {
-		return errors.New("schedule is required")
+	if req.Schedule == "" && len(req.RunAfter) == 0 && len(req.Schedules) == 0 {
+		return errors.New("schedule is required unless run_after or schedules is set")
+	}
+	if req.Type != "" && !slices.Contains(scheduler.PolicyTypes, req.Type) {
+		return fmt.Errorf("type: unknown policy type %q (valid: %s)", req.Type, strings.Join(scheduler.PolicyTypes, ", "))
 	}
+	if req.ZeroKnowledge && req.Type == scheduler.PolicyTypeReplication {
+		return errors.New("zero_knowledge only applies to backup policies")
+	}
+	if req.Type == scheduler.PolicyTypeReplication {
+		if err := validateReplicationPolicy(req); err != nil {
+			return err
+		}
+	} else if req.ZeroKnowledge {
+		if err := validateZeroKnowledgePolicy(req); err != nil {
+			return err
+		}
+	} else {
+		if req.Sources == "" {
+			return errors.New("sources is required")
+		}
+		if err := validateSources(req.Sources); err != nil {
+			return err
+		}
+		// Destinations get a generated password when the policy has none.
+		if req.RepoPassword == "" && len(req.Destinations) == 0 {
+			return errors.New("repo_password is required unless destinations are set")
+		}
+	}
+	if req.Schedule != "" {
+		if err := validateSchedule(req.Schedule); err != nil {
+			return err
+		}
+	}
+	if err := validateTimezone(req.Timezone); err != nil {
+		return err
+	}
+	if err := validateStartJitter(req.StartJitter); err != nil {
+		return err
+	}
+	if req.RetryMaxAttempts != 0 {
+		if err := validateRetryMaxAttempts(req.RetryMaxAttempts); err != nil {
+			return err
+		}
+	}
+	if err := validateRetryBackoff(req.RetryBackoff); err != nil {
+		return err
+	}
+	if err := validateRetryOn(req.RetryOn); err != nil {
+		return err
+	}
+	if err := validateMaxRuntime(req.MaxRuntime); err != nil {
+		return err
+	}
+	if req.FanOut != "" {
+		if err := validateFanOut(req.FanOut); err != nil {
+			return err
+		}
+	}
+	if err := validateFanOutParallelism(req.FanOutParallelism); err != nil {
+		return err
+	}
+	if err := validateHooks(req.Hooks); err != nil {
+		return errors.New("hooks: " + err.Error())
+	}
+	return nil
+}
+
+// validateReplicationPolicy checks the fields of a replication policy, in
+// place of the sources of a backup policy. The repository password may be
+// left empty when the policy replicates the snapshots of another policy,
+// whose password is then used.
+func validateReplicationPolicy(req *createPolicyRequest) error {
+	if req.Sources != "" && req.Sources != "[]" {
+		return errors.New("sources: replication policies have no sources")
+	}
+	if req.ReplicateFromID == "" {
+		return errors.New("replicate_from_id is required for replication policies")
+	}
+	if req.RepoPassword == "" && req.ReplicatePolicyID == "" {
+		return errors.New("repo_password is required unless replicate_policy_id is set")
+	}
+	for _, d := range req.Destinations {
+		if d.DestinationID == req.ReplicateFromID {
+			return errors.New("replicate_from_id: the source cannot be one of the policy's destinations")
+		}
+	}
+	return validateReplicateTag(req.ReplicateTag)
+}
+
+// validateZeroKnowledgePolicy checks a zero-knowledge backup policy: its
+// agent generates and keeps the repository keys, so no password may be given
+// and no repository entity, whose password the server holds, may be used.
+func validateZeroKnowledgePolicy(req *createPolicyRequest) error {
 	if req.Sources == "" {
 		return errors.New("sources is required")
 	}
-	if req.RepoPassword == "" {
-		return errors.New("repo_password is required")
-	}
-	if err := validateSchedule(req.Schedule); err != nil {
+	if err := validateSources(req.Sources); err != nil {
 		return err
 	}
-	if err := validateHookCommand(req.HookPreBackup); err != nil {
-		return errors.New("hook_pre_backup: " + err.Error())
+	if len(req.Destinations) == 0 {
+		return errors.New("destinations are required for zero-knowledge policies")
+	}
+	if req.RepoPassword != "" {
+		return errors.New("repo_password cannot be set on a zero-knowledge policy, the agent generates the keys")
+	}
+	for _, d := range req.Destinations {
+		if d.RepoPassword != "" || d.RepositoryID != "" {
+			return errors.New("destinations: repo_password and repository_id cannot be set on a zero-knowledge policy")
+		}
+	}
+	return nil
+}
+
+// validateSources checks the source objects in a policy's sources JSON: the
+// container mode only applies to docker-volume sources, docker-label
+// sources need a label and database sources a connection and a database
+// name the dump tools cannot mistake for an option. Plain string entries, as
+// written by older clients, are left to the agent.
+func validateSources(sourcesJSON string) error {
+	var entries []json.RawMessage
+	if err := json.Unmarshal([]byte(sourcesJSON), &entries); err != nil {
+		return errors.New("sources: must be a JSON array")
+	}
+	for i, raw := range entries {
+		var src struct {
+			Type       string `json:"type"`
+			Path       string `json:"path"`
+			Containers string `json:"containers"`
+
+			ConnectionID string `json:"connection_id"`
+			Database     string `json:"database"`
+
+			Container            string `json:"container"`
+			Engine               string `json:"engine"`
+			Command              string `json:"command"`
+			Username             string `json:"username"`
+			ContainerCredentials bool   `json:"container_credentials"`
+
+			Filename string `json:"filename"`
+		}
+		if json.Unmarshal(raw, &src) != nil {
+			continue
+		}
+		if src.Type == "command" {
+			if err := validateCommandSource(src.Command, src.Filename); err != nil {
+				return fmt.Errorf("sources[%d]: %w", i, err)
+			}
+		}
+		if src.Type == "docker-dump" {
+			if err := validateContainerDump(src.Container, src.Engine, src.Command, src.Username, src.ContainerCredentials); err != nil {
+				return fmt.Errorf("sources[%d]: %w", i, err)
+			}
+		}
+		if src.Type == "database" {
+			if _, err := uuid.Parse(src.ConnectionID); err != nil {
+				return fmt.Errorf("sources[%d]: connection_id must be a valid UUID", i)
+			}
+		}
+		if src.Type == "database" || src.Type == "docker-dump" {
+			// The name ends up in a restic tag, where commas separate tags.
+			if strings.HasPrefix(src.Database, "-") || strings.ContainsAny(src.Database, ",\t\r\n") {
+				return fmt.Errorf("sources[%d]: invalid database name %q", i, src.Database)
+			}
+		}
+		if src.Type == "docker-label" && (src.Path == "" || strings.HasPrefix(src.Path, "=")) {
+			return fmt.Errorf("sources[%d]: docker-label sources need a label, e.g. arkeep.backup=true", i)
+		}
+		if src.Containers == "" {
+			continue
+		}
+		if src.Type != "docker-volume" {
+			return fmt.Errorf("sources[%d]: containers is only valid on docker-volume sources", i)
+		}
+		if !slices.Contains(scheduler.ContainerModes, src.Containers) {
+			return fmt.Errorf("sources[%d]: containers must be one of %s", i, strings.Join(scheduler.ContainerModes, ", "))
+		}
+	}
+	return nil
+}
+
+// validateContainerDump checks the fields of a docker-dump source: a
+// container and either an engine preset or a custom dump command, which
+// runs through sh -c inside the container and is held to the same rules as
+// a hook.
+func validateContainerDump(container, engine, command, username string, containerCredentials bool) error {
+	// The container name ends up in a restic tag.
+	if container == "" || strings.ContainsAny(container, ", \t\r\n") {
+		return errors.New("docker-dump sources need a container name")
 	}
-	if err := validateHookCommand(req.HookPostBackup); err != nil {
-		return errors.New("hook_post_backup: " + err.Error())
+	if engine == "" {
+		if command == "" {
+			return errors.New("docker-dump sources need an engine or a command")
+		}
+		if err := validateHookCommand(command); err != nil {
+			return fmt.Errorf("command: %w", err)
+		}
+		return nil
+	}
+	if !slices.Contains(scheduler.ContainerDumpEngines, engine) {
+		return fmt.Errorf("engine must be one of %s, or empty with a command", strings.Join(scheduler.ContainerDumpEngines, ", "))
+	}
+	if command != "" {
+		return errors.New("command cannot be set with an engine preset")
+	}
+	if strings.HasPrefix(username, "-") {
+		return errors.New("username cannot start with '-'")
+	}
+	if username != "" && containerCredentials {
+		return errors.New("username cannot be set with container_credentials, the container's user is used")
 	}
 	return nil
 }
 
+// validateCommandSource checks the fields of a command source. The command
+// is held to the same rules as a hook; the agent also checks it against its
+// own allowlist before running it.
+func validateCommandSource(command, filename string) error {
+	if command == "" {
+		return errors.New("command sources need a command")
+	}
+	if err := validateHookCommand(command); err != nil {
+		return fmt.Errorf("command: %w", err)
+	}
+	// The file name ends up in a restic tag, and is the whole path of the
+	// output in the snapshot.
+	if filename == "" || filename == "." || filename == ".." || strings.ContainsAny(filename, "/\\, \t\r\n") {
+		return errors.New("command sources need a file name without slashes, commas or spaces")
+	}
+	return nil
+}
+
+// validateReplicateTag checks that the replication tag filter is a single
+// restic tag: restic takes tag sets comma-separated.
+func validateReplicateTag(tag string) error {
+	if strings.ContainsAny(tag, ", \t\n") {
+		return fmt.Errorf("replicate_tag: invalid tag %q", tag)
+	}
+	return nil
+}
+
+// destinationPasswordBytes is the number of random bytes in a generated
+// repository password (hex-encoded, so 64 characters).
+const destinationPasswordBytes = 32
+
+// destinationPasswords returns the repository password of each destination
+// entry, in entry order: the entry's own or, when neither the entry nor the
+// policy has one, a distinct generated password, so that a compromised
+// destination does not expose the key of the others. Entries writing into a
+// repository entity use its password and entries left empty fall back to
+// the policy's.
+func destinationPasswords(policyPassword string, entries []destinationEntryRequest, repositoryIDs []*uuid.UUID) ([]string, error) {
+	passwords := make([]string, len(entries))
+	for i, e := range entries {
+		switch {
+		case repositoryIDs[i] != nil:
+		case e.RepoPassword != "":
+			passwords[i] = e.RepoPassword
+		case policyPassword == "":
+			password, err := randomHex(destinationPasswordBytes)
+			if err != nil {
+				return nil, err
+			}
+			passwords[i] = password
+		}
+	}
+	return passwords, nil
+}
+
+// parseRepositories validates the repository_id of each destination entry
+// and returns the parsed IDs in entry order, nil for entries without one.
+// A repository must exist and live on the entry's destination. A non-empty
+// message is a validation failure for the client; err is an internal error.
+func (h *PolicyHandler) parseRepositories(ctx context.Context, entries []destinationEntryRequest) ([]*uuid.UUID, string, error) {
+	ids := make([]*uuid.UUID, len(entries))
+	for i, e := range entries {
+		if e.RepositoryID == "" {
+			continue
+		}
+		if e.RepoPassword != "" {
+			return nil, "destinations: repo_password cannot be set with repository_id, the repository's password is used", nil
+		}
+		id, err := uuid.Parse(e.RepositoryID)
+		if err != nil {
+			return nil, "destinations: repository_id must be a valid UUID", nil
+		}
+		repo, err := h.repoRepo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return nil, fmt.Sprintf("destinations: repository %s not found", id), nil
+			}
+			return nil, "", err
+		}
+		if destID, err := uuid.Parse(e.DestinationID); err != nil || repo.DestinationID != destID {
+			return nil, fmt.Sprintf("destinations: repository %s is not on destination %s", id, e.DestinationID), nil
+		}
+		ids[i] = &id
+	}
+	return ids, "", nil
+}
+
+// parseReplication validates the source destination and the replicated
+// policy of a replication policy. The replicated policy must exist and be a
+// backup policy; an empty replicatedID replicates the snapshots of every
+// policy. A non-empty message is a validation failure for the client; err
+// is an internal error.
+func (h *PolicyHandler) parseReplication(ctx context.Context, fromID, replicatedID string) (*uuid.UUID, *uuid.UUID, string, error) {
+	from, err := uuid.Parse(fromID)
+	if err != nil {
+		return nil, nil, "replicate_from_id must be a valid UUID", nil
+	}
+	if replicatedID == "" {
+		return &from, nil, "", nil
+	}
+	replicated, err := uuid.Parse(replicatedID)
+	if err != nil {
+		return nil, nil, "replicate_policy_id must be a valid UUID", nil
+	}
+	policy, err := h.repo.GetByID(ctx, replicated)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, nil, fmt.Sprintf("replicate_policy_id: policy %s not found", replicated), nil
+		}
+		return nil, nil, "", err
+	}
+	if policy.Type == scheduler.PolicyTypeReplication {
+		return nil, nil, "replicate_policy_id: cannot replicate the snapshots of a replication policy", nil
+	}
+	// Replication runs with a password on the server, which a
+	// zero-knowledge policy does not have.
+	if policy.ZeroKnowledge {
+		return nil, nil, "replicate_policy_id: cannot replicate the snapshots of a zero-knowledge policy", nil
+	}
+	return &from, &replicated, "", nil
+}
+
+// parseDependencies validates a run_after list and converts it into
+// dependencies of policyID. Every upstream policy must exist, appear once and
+// differ from policyID, and the resulting graph must stay acyclic. A non-empty
+// message is a validation failure for the client; err is an internal error.
+// policyID is uuid.Nil for a policy that does not exist yet.
+func (h *PolicyHandler) parseDependencies(ctx context.Context, policyID uuid.UUID, entries []dependencyEntryRequest) ([]db.PolicyDependency, string, error) {
+	deps := make([]db.PolicyDependency, 0, len(entries))
+	upstream := make([]uuid.UUID, 0, len(entries))
+	for _, e := range entries {
+		dependsOn, err := uuid.Parse(e.PolicyID)
+		if err != nil {
+			return nil, "run_after: policy_id must be a valid UUID", nil
+		}
+		if dependsOn == policyID {
+			return nil, "run_after: a policy cannot run after itself", nil
+		}
+		if slices.Contains(upstream, dependsOn) {
+			return nil, fmt.Sprintf("run_after: policy %s is listed more than once", dependsOn), nil
+		}
+		condition := e.Condition
+		if condition == "" {
+			condition = scheduler.DependencyOnSuccess
+		}
+		if !slices.Contains(scheduler.DependencyConditions, condition) {
+			return nil, fmt.Sprintf("run_after: unknown condition %q (valid: %s)", condition, strings.Join(scheduler.DependencyConditions, ", ")), nil
+		}
+		if _, err := h.repo.GetByID(ctx, dependsOn); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return nil, fmt.Sprintf("run_after: policy %s not found", dependsOn), nil
+			}
+			return nil, "", err
+		}
+		upstream = append(upstream, dependsOn)
+		deps = append(deps, db.PolicyDependency{PolicyID: policyID, DependsOnID: dependsOn, Condition: condition})
+	}
+
+	if policyID == uuid.Nil || len(upstream) == 0 {
+		return deps, "", nil
+	}
+	edges, err := h.repo.ListAllDependencies(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if cycle := scheduler.FindDependencyCycle(edges, policyID, upstream); cycle != nil {
+		path := make([]string, len(cycle))
+		for i, id := range cycle {
+			path[i] = id.String()
+		}
+		return nil, "run_after: dependency cycle: " + strings.Join(path, " -> "), nil
+	}
+	return deps, "", nil
+}
+
+// parseSchedules validates a schedules list and converts it into schedule
+// entries. Tags may not contain commas or whitespace, as restic takes tag
+// sets comma-separated. An entry with its own retention needs tags, and its
+// tags must not all be carried by another entry: its tag-scoped forget
+// would otherwise also prune that entry's snapshots.
+func parseSchedules(entries []scheduleEntryRequest) ([]db.PolicySchedule, error) {
+	schedules := make([]db.PolicySchedule, 0, len(entries))
+	for i, e := range entries {
+		prefix := fmt.Sprintf("schedules[%d]", i)
+		var ps db.PolicySchedule
+		if e.ID != "" {
+			id, err := uuid.Parse(e.ID)
+			if err != nil {
+				return nil, fmt.Errorf("%s: id must be a valid UUID", prefix)
+			}
+			ps.ID = id
+		}
+		if e.Schedule == "" {
+			return nil, fmt.Errorf("%s: schedule is required", prefix)
+		}
+		if err := validateSchedule(e.Schedule); err != nil {
+			return nil, fmt.Errorf("%s: %w", prefix, err)
+		}
+		ps.Schedule = e.Schedule
+		for _, tag := range e.Tags {
+			if tag == "" || strings.ContainsAny(tag, ", \t\n") {
+				return nil, fmt.Errorf("%s: invalid tag %q", prefix, tag)
+			}
+			if strings.HasPrefix(tag, "policy:") {
+				return nil, fmt.Errorf("%s: tag %q is reserved", prefix, tag)
+			}
+		}
+		ps.Tags = strings.Join(e.Tags, ",")
+		if e.Retention != nil {
+			ret := e.Retention
+			if ret.Hourly < 0 || ret.Daily < 0 || ret.Weekly < 0 || ret.Monthly < 0 || ret.Yearly < 0 {
+				return nil, fmt.Errorf("%s: retention values cannot be negative", prefix)
+			}
+			if ret.Hourly+ret.Daily+ret.Weekly+ret.Monthly+ret.Yearly == 0 {
+				return nil, fmt.Errorf("%s: retention must keep at least one snapshot", prefix)
+			}
+			if len(e.Tags) == 0 {
+				return nil, fmt.Errorf("%s: tags are required with a custom retention", prefix)
+			}
+			ps.CustomRetention = true
+			ps.RetentionHourly = ret.Hourly
+			ps.RetentionDaily = ret.Daily
+			ps.RetentionWeekly = ret.Weekly
+			ps.RetentionMonthly = ret.Monthly
+			ps.RetentionYearly = ret.Yearly
+		}
+		schedules = append(schedules, ps)
+	}
+
+	for i, e := range entries {
+		if e.Retention == nil {
+			continue
+		}
+		for j, other := range entries {
+			if i == j {
+				continue
+			}
+			covered := true
+			for _, tag := range e.Tags {
+				if !slices.Contains(other.Tags, tag) {
+					covered = false
+					break
+				}
+			}
+			if covered {
+				return nil, fmt.Errorf("schedules[%d]: tags overlap with schedules[%d]; an entry with its own retention needs a tag the others do not carry", i, j)
+			}
+		}
+	}
+	return schedules, nil
+}
+
 // validateSchedule parses a cron expression to ensure it's valid.
 func validateSchedule(schedule string) error {
 	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
@@ -541,4 +1587,124 @@ func validateSchedule(schedule string) error {
 		return errors.New("invalid schedule: " + err.Error())
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// validateTimezone checks that tz is empty (server local time) or a zone
+// name known to the IANA time zone database, e.g. "Europe/Rome".
+func validateTimezone(tz string) error {
+	if tz == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return errors.New("invalid timezone: " + err.Error())
+	}
+	return nil
+}
+
+// maxStartJitter bounds Policy.StartJitterSeconds. A delay longer than a day
+// would push runs of daily policies into the next scheduled tick.
+const maxStartJitter = 24 * 60 * 60
+
+// validateStartJitter checks that the start delay bound is within
+// [0, maxStartJitter] seconds.
+func validateStartJitter(seconds int) error {
+	if seconds < 0 || seconds > maxStartJitter {
+		return fmt.Errorf("start_jitter_seconds must be between 0 and %d", maxStartJitter)
+	}
+	return nil
+}
+
+// maxRetryAttempts bounds Policy.RetryMaxAttempts.
+const maxRetryAttempts = 10
+
+// validateRetryMaxAttempts checks that the total number of attempts per run
+// is within [1, maxRetryAttempts].
+func validateRetryMaxAttempts(attempts int) error {
+	if attempts < 1 || attempts > maxRetryAttempts {
+		return fmt.Errorf("retry_max_attempts must be between 1 and %d", maxRetryAttempts)
+	}
+	return nil
+}
+
+// validateRetryBackoff checks that the base retry backoff is within
+// [0, 24h] seconds.
+func validateRetryBackoff(seconds int) error {
+	if seconds < 0 || seconds > 24*60*60 {
+		return fmt.Errorf("retry_backoff_seconds must be between 0 and %d", 24*60*60)
+	}
+	return nil
+}
+
+// validateRetryOn checks that every entry is a known failure class.
+func validateRetryOn(classes []string) error {
+	for _, c := range classes {
+		if !slices.Contains(scheduler.FailureClasses, c) {
+			return fmt.Errorf("retry_on: unknown failure class %q (valid: %s)", c, strings.Join(scheduler.FailureClasses, ", "))
+		}
+	}
+	return nil
+}
+
+// maxMaxRuntime bounds Policy.MaxRuntimeSeconds.
+const maxMaxRuntime = 7 * 24 * 60 * 60
+
+// validateMaxRuntime checks that the runtime limit is within
+// [0, maxMaxRuntime] seconds, 0 meaning no limit.
+func validateMaxRuntime(seconds int) error {
+	if seconds < 0 || seconds > maxMaxRuntime {
+		return fmt.Errorf("max_runtime_seconds must be between 0 and %d", maxMaxRuntime)
+	}
+	return nil
+}
+
+// validateFanOut checks that mode is a known fan-out mode.
+func validateFanOut(mode string) error {
+	if !slices.Contains(scheduler.FanOutModes, mode) {
+		return fmt.Errorf("fan_out: unknown mode %q (valid: %s)", mode, strings.Join(scheduler.FanOutModes, ", "))
+	}
+	return nil
+}
+
+// validateFanOutParallelism checks that the parallel backup limit is not
+// negative, 0 meaning no limit.
+func validateFanOutParallelism(n int) error {
+	if n < 0 {
+		return errors.New("fan_out_parallelism must not be negative")
+	}
+	return nil
+}
+
+// splitRetryOn converts the comma-separated Policy.RetryOn column into a
+// list. Always returns a non-nil slice so the JSON response has [] rather
+// than null.
+func splitRetryOn(retryOn string) []string {
+	classes := []string{}
+	for _, c := range strings.Split(retryOn, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			classes = append(classes, c)
+		}
+	}
+	return classes
+}
+
+// policyHooks decodes the Policy.Hooks column. Always returns a non-nil map
+// so the JSON response has {} rather than null. The column is only written
+// by hooksJSON, so a value that fails to parse is shown as no hooks.
+func policyHooks(hooksJSON string) scheduler.Hooks {
+	hooks, err := scheduler.ParseHooks(hooksJSON)
+	if err != nil {
+		return scheduler.Hooks{}
+	}
+	return hooks
+}
+
+// hooksJSON encodes hooks for the Policy.Hooks column. Phases without steps
+// are dropped and map keys are sorted, so equal hooks encode equally.
+func hooksJSON(hooks scheduler.Hooks) string {
+	hooks = hooks.Phases(scheduler.HookPhases...)
+	if hooks == nil {
+		return "{}"
+	}
+	b, _ := json.Marshal(hooks)
+	return string(b)
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/arkeep-io/arkeep/server/internal/db"
+	"github.com/arkeep-io/arkeep/server/internal/scheduler"
 )
 
 // createDBPolicy inserts a policy record directly and returns it.
@@ -145,6 +147,74 @@ func TestPolicyHandler_Create(t *testing.T) {
 		}
 	})
 
+	t.Run("validates docker-volume, docker-label, database, docker-dump and command sources", func(t *testing.T) {
+		e := newTestEnv(t)
+		cases := map[string]struct {
+			sources string
+			want    int
+		}{
+			"stop":                 {`[{"type":"docker-volume","path":"pgdata","containers":"stop"}]`, http.StatusCreated},
+			"pause":                {`[{"type":"docker-volume","path":"pgdata","containers":"pause"}]`, http.StatusCreated},
+			"unknown mode":         {`[{"type":"docker-volume","path":"pgdata","containers":"kill"}]`, http.StatusBadRequest},
+			"directory source":     {`[{"type":"directory","path":"/srv","containers":"stop"}]`, http.StatusBadRequest},
+			"docker label":         {`[{"type":"docker-label","path":"arkeep.backup=true"}]`, http.StatusCreated},
+			"empty label":          {`[{"type":"docker-label","path":""}]`, http.StatusBadRequest},
+			"database":             {`[{"type":"database","connection_id":"0b9f5bb5-3d1c-4c1e-9a51-0f2d1c3b4a5e","database":"app"}]`, http.StatusCreated},
+			"no connection":        {`[{"type":"database","database":"app"}]`, http.StatusBadRequest},
+			"option database":      {`[{"type":"database","connection_id":"0b9f5bb5-3d1c-4c1e-9a51-0f2d1c3b4a5e","database":"--help"}]`, http.StatusBadRequest},
+			"container dump":       {`[{"type":"docker-dump","container":"pg","engine":"postgres","database":"app","container_credentials":true}]`, http.StatusCreated},
+			"container command":    {`[{"type":"docker-dump","container":"influx","command":"influx backup -"}]`, http.StatusCreated},
+			"no container":         {`[{"type":"docker-dump","engine":"mysql"}]`, http.StatusBadRequest},
+			"no command":           {`[{"type":"docker-dump","container":"pg"}]`, http.StatusBadRequest},
+			"redis preset":         {`[{"type":"docker-dump","container":"cache","engine":"redis"}]`, http.StatusBadRequest},
+			"command substitution": {`[{"type":"docker-dump","container":"pg","command":"echo $(cat /etc/shadow)"}]`, http.StatusBadRequest},
+			"user and env":         {`[{"type":"docker-dump","container":"pg","engine":"postgres","username":"app","container_credentials":true}]`, http.StatusBadRequest},
+			"command":              {`[{"type":"command","command":"etcdctl snapshot save -","filename":"etcd.db"}]`, http.StatusCreated},
+			"command no filename":  {`[{"type":"command","command":"etcdctl snapshot save -"}]`, http.StatusBadRequest},
+			"command path":         {`[{"type":"command","command":"ldapsearch -x","filename":"../ldap.ldif"}]`, http.StatusBadRequest},
+			"command env":          {`[{"type":"command","command":"echo $RESTIC_PASSWORD","filename":"x"}]`, http.StatusBadRequest},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				body := validPolicy(uuid.New().String())
+				body["sources"] = tc.sources
+				assertStatus(t, e.post(t, "/api/v1/policies", e.adminToken(t), body), tc.want)
+			})
+		}
+	})
+
+	t.Run("anomaly detection is on by default and can be turned off", func(t *testing.T) {
+		e := newTestEnv(t)
+		type anomalySettings struct {
+			ID               string `json:"id"`
+			AnomalyDetection bool   `json:"anomaly_detection"`
+			AnomalyHold      bool   `json:"anomaly_hold"`
+		}
+
+		resp := e.post(t, "/api/v1/policies", e.adminToken(t), validPolicy(uuid.New().String()))
+		assertStatus(t, resp, http.StatusCreated)
+		var def anomalySettings
+		decodeData(t, resp, &def)
+		if !def.AnomalyDetection || def.AnomalyHold {
+			t.Errorf("defaults = %+v, want detection on and hold off", def)
+		}
+
+		body := validPolicy(uuid.New().String())
+		body["anomaly_detection"] = false
+		resp = e.post(t, "/api/v1/policies", e.adminToken(t), body)
+		assertStatus(t, resp, http.StatusCreated)
+		var off anomalySettings
+		decodeData(t, resp, &off)
+		id, _ := uuid.Parse(off.ID)
+		stored, err := e.deps.policies.GetByID(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if off.AnomalyDetection || stored.AnomalyDetection {
+			t.Errorf("anomaly_detection = %v (stored %v), want false", off.AnomalyDetection, stored.AnomalyDetection)
+		}
+	})
+
 	t.Run("returns 400 when name is missing", func(t *testing.T) {
 		e := newTestEnv(t)
 		body := validPolicy(uuid.New().String())
@@ -185,7 +255,7 @@ func TestPolicyHandler_Create(t *testing.T) {
 		assertStatus(t, resp, http.StatusBadRequest)
 	})
 
-	t.Run("returns 400 when repo_password is missing", func(t *testing.T) {
+	t.Run("returns 400 when repo_password is missing without destinations", func(t *testing.T) {
 		e := newTestEnv(t)
 		body := validPolicy(uuid.New().String())
 		delete(body, "repo_password")
@@ -193,22 +263,124 @@ func TestPolicyHandler_Create(t *testing.T) {
 		assertStatus(t, resp, http.StatusBadRequest)
 	})
 
-	t.Run("returns 403 when non-admin sets hook_pre_backup", func(t *testing.T) {
+	t.Run("returns 403 when non-admin sets hooks", func(t *testing.T) {
 		e := newTestEnv(t)
 		body := validPolicy(uuid.New().String())
-		body["hook_pre_backup"] = "/usr/local/bin/pre-backup.sh"
+		body["hooks"] = map[string]any{"pre_backup": []map[string]any{{"command": "/usr/local/bin/pre-backup.sh"}}}
 		resp := e.post(t, "/api/v1/policies", e.userToken(t), body)
 		assertStatus(t, resp, http.StatusForbidden)
 	})
 
-	t.Run("returns 400 when hook_pre_backup contains shell injection", func(t *testing.T) {
+	t.Run("returns 400 when a hook contains shell injection", func(t *testing.T) {
 		e := newTestEnv(t)
 		body := validPolicy(uuid.New().String())
-		body["hook_pre_backup"] = "echo $(cat /etc/passwd)"
+		body["hooks"] = map[string]any{"pre_backup": []map[string]any{{"command": "echo $(cat /etc/passwd)"}}}
 		resp := e.post(t, "/api/v1/policies", e.adminToken(t), body)
 		assertStatus(t, resp, http.StatusBadRequest)
 	})
 
+	t.Run("stores hook steps by phase", func(t *testing.T) {
+		e := newTestEnv(t)
+		body := validPolicy(uuid.New().String())
+		body["hooks"] = map[string]any{
+			"pre_backup": []map[string]any{
+				{"name": "stop app", "command": "systemctl stop myapp", "timeout_secs": 60},
+				{"command": "sync", "continue_on_error": true},
+			},
+			"on_failure": []map[string]any{
+				{"command": "/opt/notify.sh $ARKEEP_JOB_ID", "env": map[string]string{"CHANNEL": "ops"}, "working_dir": "/opt", "run_as": "backup"},
+			},
+			"post_restore": []map[string]any{},
+		}
+		resp := e.post(t, "/api/v1/policies", e.adminToken(t), body)
+		assertStatus(t, resp, http.StatusCreated)
+
+		var data struct {
+			Hooks scheduler.Hooks `json:"hooks"`
+		}
+		decodeData(t, resp, &data)
+		if len(data.Hooks) != 2 || len(data.Hooks[scheduler.HookPreBackup]) != 2 {
+			t.Fatalf("hooks = %+v, want pre_backup and on_failure", data.Hooks)
+		}
+		if step := data.Hooks[scheduler.HookOnFailure][0]; step.Env["CHANNEL"] != "ops" || step.WorkingDir != "/opt" || step.RunAs != "backup" {
+			t.Errorf("on_failure step = %+v", step)
+		}
+	})
+
+	t.Run("returns 400 for an unknown hook phase", func(t *testing.T) {
+		e := newTestEnv(t)
+		body := validPolicy(uuid.New().String())
+		body["hooks"] = map[string]any{"before_backup": []map[string]any{{"command": "sync"}}}
+		resp := e.post(t, "/api/v1/policies", e.adminToken(t), body)
+		assertStatus(t, resp, http.StatusBadRequest)
+	})
+
+	t.Run("creates a replication policy without sources", func(t *testing.T) {
+		e := newTestEnv(t)
+		replicated := createDBPolicy(t, e.deps, "nas-backup", uuid.New())
+		source, target := uuid.New().String(), uuid.New().String()
+
+		resp := e.post(t, "/api/v1/policies", e.adminToken(t), map[string]any{
+			"name":                "nas-to-b2",
+			"agent_id":            uuid.New().String(),
+			"schedule":            "@daily",
+			"type":                "replication",
+			"replicate_from_id":   source,
+			"replicate_policy_id": replicated.ID.String(),
+			"replicate_host":      "nas",
+			"destinations":        []map[string]any{{"destination_id": target}},
+		})
+		assertStatus(t, resp, http.StatusCreated)
+
+		var data struct {
+			Type              string  `json:"type"`
+			Sources           string  `json:"sources"`
+			ReplicateFromID   *string `json:"replicate_from_id"`
+			ReplicatePolicyID *string `json:"replicate_policy_id"`
+			ReplicateHost     string  `json:"replicate_host"`
+		}
+		decodeData(t, resp, &data)
+		if data.Type != "replication" || data.Sources != "[]" || data.ReplicateHost != "nas" {
+			t.Errorf("policy = %+v, want a replication policy without sources", data)
+		}
+		if data.ReplicateFromID == nil || *data.ReplicateFromID != source || data.ReplicatePolicyID == nil || *data.ReplicatePolicyID != replicated.ID.String() {
+			t.Errorf("replicate_from_id = %v, replicate_policy_id = %v, want %s and %s", data.ReplicateFromID, data.ReplicatePolicyID, source, replicated.ID)
+		}
+	})
+
+	t.Run("returns 400 for invalid replication settings", func(t *testing.T) {
+		e := newTestEnv(t)
+		source := uuid.New().String()
+		base := func() map[string]any {
+			return map[string]any{
+				"name":              "replication",
+				"agent_id":          uuid.New().String(),
+				"schedule":          "@daily",
+				"type":              "replication",
+				"replicate_from_id": source,
+				"repo_password":     "supersecret",
+			}
+		}
+
+		for name, mutate := range map[string]func(map[string]any){
+			"unknown type":       func(b map[string]any) { b["type"] = "mirror" },
+			"missing source":     func(b map[string]any) { delete(b, "replicate_from_id") },
+			"invalid source":     func(b map[string]any) { b["replicate_from_id"] = "nas" },
+			"no password":        func(b map[string]any) { delete(b, "repo_password") },
+			"unknown policy":     func(b map[string]any) { b["replicate_policy_id"] = uuid.New().String() },
+			"tag list":           func(b map[string]any) { b["replicate_tag"] = "a,b" },
+			"with sources":       func(b map[string]any) { b["sources"] = `["/data"]` },
+			"source as a target": func(b map[string]any) { b["destinations"] = []map[string]any{{"destination_id": source}} },
+		} {
+			t.Run(name, func(t *testing.T) {
+				body := base()
+				mutate(body)
+				resp := e.post(t, "/api/v1/policies", e.adminToken(t), body)
+				assertStatus(t, resp, http.StatusBadRequest)
+			})
+		}
+	})
+
 	t.Run("returns 401 without token", func(t *testing.T) {
 		e := newTestEnv(t)
 		resp := e.post(t, "/api/v1/policies", "", validPolicy(uuid.New().String()))
@@ -216,6 +388,66 @@ func TestPolicyHandler_Create(t *testing.T) {
 	})
 }
 
+func TestPolicyHandler_DestinationPassword(t *testing.T) {
+	e := newTestEnv(t)
+	first, second := uuid.New(), uuid.New()
+
+	// Without a policy password each destination gets a generated one.
+	body := map[string]any{
+		"name":     "backup-policy",
+		"agent_id": uuid.New().String(),
+		"schedule": "@daily",
+		"sources":  `["/data"]`,
+		"destinations": []map[string]any{
+			{"destination_id": first.String()},
+			{"destination_id": second.String()},
+		},
+	}
+	resp := e.post(t, "/api/v1/policies", e.adminToken(t), body)
+	assertStatus(t, resp, http.StatusCreated)
+	var created struct {
+		ID string `json:"id"`
+	}
+	decodeData(t, resp, &created)
+
+	_, pds, err := e.deps.policies.GetByIDWithDestinations(context.Background(), uuid.MustParse(created.ID))
+	if err != nil {
+		t.Fatalf("GetByIDWithDestinations: %v", err)
+	}
+	if len(pds) != 2 || pds[0].RepoPassword == "" || pds[0].RepoPassword == pds[1].RepoPassword {
+		t.Fatalf("destination passwords = %+v, want two distinct generated passwords", pds)
+	}
+
+	url := "/api/v1/policies/" + created.ID + "/destinations/" + pds[0].DestinationID.String() + "/password"
+	resp = e.get(t, url, e.adminToken(t))
+	assertStatus(t, resp, http.StatusOK)
+	var got struct {
+		RepoPassword string `json:"repo_password"`
+	}
+	decodeData(t, resp, &got)
+	if got.RepoPassword != string(pds[0].RepoPassword) {
+		t.Errorf("repo_password = %q, want the destination's password", got.RepoPassword)
+	}
+
+	resp = e.get(t, url, e.userToken(t))
+	assertStatus(t, resp, http.StatusForbidden)
+
+	resp = e.get(t, "/api/v1/policies/"+created.ID+"/destinations/"+uuid.New().String()+"/password", e.adminToken(t))
+	assertStatus(t, resp, http.StatusNotFound)
+
+	// Destinations of a policy with its own password keep using it.
+	policy := createDBPolicy(t, e.deps, "legacy", uuid.New())
+	if err := e.deps.policies.AddDestination(context.Background(), &db.PolicyDestination{PolicyID: policy.ID, DestinationID: first, Priority: 1}); err != nil {
+		t.Fatalf("AddDestination: %v", err)
+	}
+	resp = e.get(t, "/api/v1/policies/"+policy.ID.String()+"/destinations/"+first.String()+"/password", e.adminToken(t))
+	assertStatus(t, resp, http.StatusOK)
+	decodeData(t, resp, &got)
+	if got.RepoPassword != "secret" {
+		t.Errorf("repo_password = %q, want the policy's password", got.RepoPassword)
+	}
+}
+
 func TestPolicyHandler_Update(t *testing.T) {
 	t.Run("updates policy name", func(t *testing.T) {
 		e := newTestEnv(t)
@@ -273,12 +505,340 @@ func TestPolicyHandler_Update(t *testing.T) {
 		agentID := uuid.New()
 		policy := createDBPolicy(t, e.deps, "policy", agentID)
 
-		hook := "cat ../../etc/passwd"
 		resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
-			"hook_pre_backup": &hook,
+			"hooks": map[string]any{"pre_backup": []map[string]any{{"command": "cat ../../etc/passwd"}}},
+		})
+		assertStatus(t, resp, http.StatusBadRequest)
+	})
+
+	t.Run("returns 403 when non-admin changes hooks", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.userToken(t), map[string]any{
+			"hooks": map[string]any{"post_backup": []map[string]any{{"command": "sync"}}},
+		})
+		assertStatus(t, resp, http.StatusForbidden)
+
+		// Sending the unchanged hooks back, as the GUI does, is allowed.
+		resp = e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.userToken(t), map[string]any{
+			"hooks": map[string]any{"post_backup": []map[string]any{}},
+		})
+		assertStatus(t, resp, http.StatusOK)
+	})
+
+	t.Run("returns 400 for unknown timezone", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		tz := "Mars/Olympus_Mons"
+		resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+			"timezone": &tz,
+		})
+		assertStatus(t, resp, http.StatusBadRequest)
+	})
+
+	t.Run("sets timezone and next run", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		tz := "Europe/Rome"
+		resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+			"timezone": &tz,
+		})
+		assertStatus(t, resp, http.StatusOK)
+
+		var data struct {
+			Timezone  string  `json:"timezone"`
+			NextRunAt *string `json:"next_run_at"`
+		}
+		decodeData(t, resp, &data)
+		if data.Timezone != tz {
+			t.Errorf("timezone = %q, want %q", data.Timezone, tz)
+		}
+		if data.NextRunAt == nil {
+			t.Fatal("next_run_at = nil, want the next @daily fire time")
+		}
+		next, err := time.Parse(time.RFC3339, *data.NextRunAt)
+		if err != nil {
+			t.Fatalf("parse next_run_at: %v", err)
+		}
+		rome, _ := time.LoadLocation(tz)
+		if local := next.In(rome); local.Hour() != 0 || local.Minute() != 0 {
+			t.Errorf("next_run_at = %s, want midnight in %s", local, tz)
+		}
+	})
+
+	t.Run("returns 400 for start jitter above one day", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+			"start_jitter_seconds": 2 * 24 * 60 * 60,
+		})
+		assertStatus(t, resp, http.StatusBadRequest)
+	})
+
+	t.Run("sets retry settings", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+			"retry_max_attempts":    3,
+			"retry_backoff_seconds": 120,
+			"retry_on":              []string{"network", "hook"},
+		})
+		assertStatus(t, resp, http.StatusOK)
+
+		var data struct {
+			RetryMaxAttempts int      `json:"retry_max_attempts"`
+			RetryBackoff     int      `json:"retry_backoff_seconds"`
+			RetryOn          []string `json:"retry_on"`
+		}
+		decodeData(t, resp, &data)
+		if data.RetryMaxAttempts != 3 || data.RetryBackoff != 120 {
+			t.Errorf("retry = %d attempts every %ds, want 3 every 120s", data.RetryMaxAttempts, data.RetryBackoff)
+		}
+		if len(data.RetryOn) != 2 || data.RetryOn[0] != "network" || data.RetryOn[1] != "hook" {
+			t.Errorf("retry_on = %v, want [network hook]", data.RetryOn)
+		}
+	})
+
+	t.Run("returns 400 for invalid retry settings", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		for _, body := range []map[string]any{
+			{"retry_max_attempts": 0},
+			{"retry_max_attempts": 11},
+			{"retry_backoff_seconds": -1},
+			{"retry_on": []string{"disk_full"}},
+		} {
+			resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), body)
+			assertStatus(t, resp, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("returns 400 for retention that keeps nothing", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		for _, body := range []map[string]any{
+			{"retention_daily": 0, "retention_weekly": 0, "retention_monthly": 0, "retention_yearly": 0},
+			{"retention_daily": -1},
+		} {
+			resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), body)
+			assertStatus(t, resp, http.StatusBadRequest)
+		}
+
+		resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{"retention_daily": 0})
+		assertStatus(t, resp, http.StatusOK)
+	})
+
+	t.Run("returns 400 for negative max runtime", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+			"max_runtime_seconds": -1,
 		})
 		assertStatus(t, resp, http.StatusBadRequest)
 	})
+
+	t.Run("sets fan-out settings", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+			"fan_out":             "parallel",
+			"fan_out_parallelism": 2,
+		})
+		assertStatus(t, resp, http.StatusOK)
+
+		var data struct {
+			FanOut            string `json:"fan_out"`
+			FanOutParallelism int    `json:"fan_out_parallelism"`
+		}
+		decodeData(t, resp, &data)
+		if data.FanOut != "parallel" || data.FanOutParallelism != 2 {
+			t.Errorf("fan-out = %q x%d, want parallel x2", data.FanOut, data.FanOutParallelism)
+		}
+
+		for _, body := range []map[string]any{
+			{"fan_out": "broadcast"},
+			{"fan_out_parallelism": -1},
+		} {
+			resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), body)
+			assertStatus(t, resp, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("sets run_after and allows an empty schedule", func(t *testing.T) {
+		e := newTestEnv(t)
+		upstream := createDBPolicy(t, e.deps, "upstream", uuid.New())
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+			"schedule":  "",
+			"run_after": []map[string]string{{"policy_id": upstream.ID.String()}},
+		})
+		assertStatus(t, resp, http.StatusOK)
+
+		var data struct {
+			Schedule string `json:"schedule"`
+			RunAfter []struct {
+				PolicyID  string `json:"policy_id"`
+				Condition string `json:"condition"`
+			} `json:"run_after"`
+		}
+		decodeData(t, resp, &data)
+		if data.Schedule != "" {
+			t.Errorf("schedule = %q, want empty", data.Schedule)
+		}
+		if len(data.RunAfter) != 1 || data.RunAfter[0].PolicyID != upstream.ID.String() || data.RunAfter[0].Condition != "success" {
+			t.Errorf("run_after = %+v, want %s on success", data.RunAfter, upstream.ID)
+		}
+
+		// Clearing run_after would leave the policy without any trigger.
+		resp = e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+			"run_after": []map[string]string{},
+		})
+		assertStatus(t, resp, http.StatusBadRequest)
+	})
+
+	t.Run("returns 400 for invalid run_after", func(t *testing.T) {
+		e := newTestEnv(t)
+		upstream := createDBPolicy(t, e.deps, "upstream", uuid.New())
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		for _, entry := range []map[string]string{
+			{"policy_id": policy.ID.String()},
+			{"policy_id": uuid.New().String()},
+			{"policy_id": "not-a-uuid"},
+			{"policy_id": upstream.ID.String(), "condition": "sometimes"},
+		} {
+			resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+				"run_after": []map[string]string{entry},
+			})
+			assertStatus(t, resp, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("returns 400 for a dependency cycle", func(t *testing.T) {
+		e := newTestEnv(t)
+		a := createDBPolicy(t, e.deps, "a", uuid.New())
+		b := createDBPolicy(t, e.deps, "b", uuid.New())
+
+		resp := e.patch(t, "/api/v1/policies/"+b.ID.String(), e.adminToken(t), map[string]any{
+			"run_after": []map[string]string{{"policy_id": a.ID.String()}},
+		})
+		assertStatus(t, resp, http.StatusOK)
+
+		resp = e.patch(t, "/api/v1/policies/"+a.ID.String(), e.adminToken(t), map[string]any{
+			"run_after": []map[string]string{{"policy_id": b.ID.String(), "condition": "always"}},
+		})
+		assertStatus(t, resp, http.StatusBadRequest)
+	})
+
+	t.Run("sets schedule entries and keeps their ids", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+			"schedules": []map[string]any{
+				{"schedule": "0 * * * *", "tags": []string{"schedule:hourly"}, "retention": map[string]int{"hourly": 48}},
+				{"schedule": "0 3 * * 0", "tags": []string{"schedule:weekly"}},
+			},
+		})
+		assertStatus(t, resp, http.StatusOK)
+
+		type scheduleEntry struct {
+			ID        string   `json:"id"`
+			Schedule  string   `json:"schedule"`
+			Tags      []string `json:"tags"`
+			Retention *struct {
+				Hourly int `json:"hourly"`
+			} `json:"retention"`
+		}
+		var data struct {
+			Schedules []scheduleEntry `json:"schedules"`
+		}
+		decodeData(t, resp, &data)
+		if len(data.Schedules) != 2 {
+			t.Fatalf("schedules = %+v, want 2 entries", data.Schedules)
+		}
+		var hourly scheduleEntry
+		for _, entry := range data.Schedules {
+			if entry.Schedule == "0 * * * *" {
+				hourly = entry
+			}
+		}
+		if hourly.Retention == nil || hourly.Retention.Hourly != 48 || len(hourly.Tags) != 1 {
+			t.Fatalf("hourly entry = %+v, want its tag and retention", hourly)
+		}
+
+		// Resending an entry by id updates it in place; omitted entries are dropped.
+		resp = e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+			"schedules": []map[string]any{
+				{"id": hourly.ID, "schedule": "30 * * * *", "tags": []string{"schedule:hourly"}, "retention": map[string]int{"hourly": 24}},
+			},
+		})
+		assertStatus(t, resp, http.StatusOK)
+		resp = e.get(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t))
+		assertStatus(t, resp, http.StatusOK)
+		decodeData(t, resp, &data)
+		if len(data.Schedules) != 1 || data.Schedules[0].ID != hourly.ID || data.Schedules[0].Schedule != "30 * * * *" {
+			t.Errorf("schedules = %+v, want the updated hourly entry only", data.Schedules)
+		}
+	})
+
+	t.Run("returns 400 for invalid schedule entries", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		for _, entries := range [][]map[string]any{
+			{{"schedule": "not a cron"}},
+			{{"schedule": "0 * * * *", "tags": []string{"two words"}}},
+			{{"schedule": "0 * * * *", "tags": []string{"policy:other"}}},
+			{{"schedule": "0 * * * *", "retention": map[string]int{"hourly": 48}}},
+			{{"schedule": "0 * * * *", "tags": []string{"hourly"}, "retention": map[string]int{}}},
+			// The hourly forget would also match the snapshots of the second entry.
+			{
+				{"schedule": "0 * * * *", "tags": []string{"hourly"}, "retention": map[string]int{"hourly": 48}},
+				{"schedule": "0 3 * * *", "tags": []string{"hourly", "nightly"}},
+			},
+		} {
+			resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+				"schedules": entries,
+			})
+			assertStatus(t, resp, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("enables catch-up", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "policy", uuid.New())
+
+		catchUp := true
+		resp := e.patch(t, "/api/v1/policies/"+policy.ID.String(), e.adminToken(t), map[string]any{
+			"catch_up": &catchUp,
+		})
+		assertStatus(t, resp, http.StatusOK)
+
+		var data struct{ CatchUp bool `json:"catch_up"` }
+		decodeData(t, resp, &data)
+		if !data.CatchUp {
+			t.Error("catch_up = false, want true")
+		}
+
+		stored, err := e.deps.policies.GetByID(context.Background(), policy.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !stored.CatchUp {
+			t.Error("stored CatchUp = false, want true")
+		}
+	})
 }
 
 func TestPolicyHandler_Delete(t *testing.T) {
@@ -310,3 +870,99 @@ func TestPolicyHandler_Delete(t *testing.T) {
 		assertStatus(t, resp, http.StatusUnauthorized)
 	})
 }
+
+func TestPolicyHandler_DryRun(t *testing.T) {
+	t.Run("admin can start a dry run", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "to-size", uuid.New())
+
+		resp := e.post(t, "/api/v1/policies/"+policy.ID.String()+"/dry-run", e.adminToken(t), nil)
+		assertStatus(t, resp, http.StatusOK)
+
+		var data map[string]string
+		decodeData(t, resp, &data)
+		jobID, err := uuid.Parse(data["job_id"])
+		if err != nil {
+			t.Fatalf("job_id = %q: %v", data["job_id"], err)
+		}
+		job, err := e.deps.jobs.GetByID(context.Background(), jobID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if job.Type != "dry_run" || job.PolicyID != policy.ID {
+			t.Errorf("job = %+v, want a dry_run job for the policy", job)
+		}
+	})
+
+	t.Run("returns 403 for non-admin user", func(t *testing.T) {
+		e := newTestEnv(t)
+		policy := createDBPolicy(t, e.deps, "protected", uuid.New())
+
+		resp := e.post(t, "/api/v1/policies/"+policy.ID.String()+"/dry-run", e.userToken(t), nil)
+		assertStatus(t, resp, http.StatusForbidden)
+	})
+
+	t.Run("returns 404 for non-existent policy", func(t *testing.T) {
+		e := newTestEnv(t)
+		resp := e.post(t, "/api/v1/policies/00000000-0000-0000-0000-000000000001/dry-run", e.adminToken(t), nil)
+		assertStatus(t, resp, http.StatusNotFound)
+	})
+}
+
+func TestPolicyHandler_ZeroKnowledge(t *testing.T) {
+	zkPolicy := func() map[string]any {
+		return map[string]any{
+			"name":           "zk-policy",
+			"agent_id":       uuid.New().String(),
+			"schedule":       "@daily",
+			"sources":        `["/data"]`,
+			"zero_knowledge": true,
+			"destinations":   []map[string]any{{"destination_id": uuid.New().String()}},
+		}
+	}
+
+	t.Run("creates a policy without repository passwords", func(t *testing.T) {
+		e := newTestEnv(t)
+		resp := e.post(t, "/api/v1/policies", e.adminToken(t), zkPolicy())
+		assertStatus(t, resp, http.StatusCreated)
+		var created struct {
+			ID            string `json:"id"`
+			ZeroKnowledge bool   `json:"zero_knowledge"`
+		}
+		decodeData(t, resp, &created)
+		if !created.ZeroKnowledge {
+			t.Error("zero_knowledge = false, want true")
+		}
+
+		policy, pds, err := e.deps.policies.GetByIDWithDestinations(context.Background(), uuid.MustParse(created.ID))
+		if err != nil {
+			t.Fatalf("GetByIDWithDestinations: %v", err)
+		}
+		if policy.RepoPassword != "" || len(pds) != 1 || pds[0].RepoPassword != "" {
+			t.Errorf("passwords stored for a zero-knowledge policy: %q, %+v", policy.RepoPassword, pds)
+		}
+
+		// There is no password to reveal.
+		url := "/api/v1/policies/" + created.ID + "/destinations/" + pds[0].DestinationID.String() + "/password"
+		assertStatus(t, e.get(t, url, e.adminToken(t)), http.StatusConflict)
+
+		// The mode is fixed at creation, and so is the absence of passwords.
+		assertStatus(t, e.patch(t, "/api/v1/policies/"+created.ID, e.adminToken(t), map[string]any{"zero_knowledge": false}), http.StatusBadRequest)
+		assertStatus(t, e.patch(t, "/api/v1/policies/"+created.ID, e.adminToken(t), map[string]any{"repo_password": "secret"}), http.StatusBadRequest)
+	})
+
+	for name, mutate := range map[string]func(map[string]any){
+		"a policy password":      func(b map[string]any) { b["repo_password"] = "secret" },
+		"a destination password": func(b map[string]any) { b["destinations"].([]map[string]any)[0]["repo_password"] = "secret" },
+		"a repository":           func(b map[string]any) { b["destinations"].([]map[string]any)[0]["repository_id"] = uuid.New().String() },
+		"no destinations":        func(b map[string]any) { delete(b, "destinations") },
+		"replication":            func(b map[string]any) { b["type"] = "replication" },
+	} {
+		t.Run("returns 400 with "+name, func(t *testing.T) {
+			e := newTestEnv(t)
+			body := zkPolicy()
+			mutate(body)
+			assertStatus(t, e.post(t, "/api/v1/policies", e.adminToken(t), body), http.StatusBadRequest)
+		})
+	}
+}
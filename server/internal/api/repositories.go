@@ -0,0 +1,340 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+	"github.com/arkeep-io/arkeep/server/internal/repositories"
+)
+
+// RepositoryHandler groups all repository-related HTTP handlers. A
+// repository is a restic repository below the root of a destination that
+// policies write into.
+type RepositoryHandler struct {
+	repo      repositories.RepositoryRepository
+	dests     repositories.DestinationRepository
+	auditRepo repositories.AuditRepository
+	logger    *zap.Logger
+}
+
+// NewRepositoryHandler creates a new RepositoryHandler.
+func NewRepositoryHandler(repo repositories.RepositoryRepository, dests repositories.DestinationRepository, auditRepo repositories.AuditRepository, logger *zap.Logger) *RepositoryHandler {
+	return &RepositoryHandler{
+		repo:      repo,
+		dests:     dests,
+		auditRepo: auditRepo,
+		logger:    logger.Named("repository_handler"),
+	}
+}
+
+// repositoryResponse is the JSON representation of a repository.
+// The password is write-only and never returned.
+type repositoryResponse struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	DestinationID string  `json:"destination_id"`
+	Path          string  `json:"path"`
+	ChunkerFromID *string `json:"chunker_from_id"`
+	InitializedAt *string `json:"initialized_at"` // nil until a job has written to it
+	PolicyCount   int64   `json:"policy_count"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+// repositoryToResponse converts a db.Repository to a repositoryResponse.
+func repositoryToResponse(r *db.Repository, policyCount int64) repositoryResponse {
+	resp := repositoryResponse{
+		ID:            r.ID.String(),
+		Name:          r.Name,
+		DestinationID: r.DestinationID.String(),
+		Path:          r.Path,
+		PolicyCount:   policyCount,
+		CreatedAt:     r.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:     r.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if r.ChunkerFromID != nil {
+		s := r.ChunkerFromID.String()
+		resp.ChunkerFromID = &s
+	}
+	if r.InitializedAt != nil {
+		s := r.InitializedAt.UTC().Format(time.RFC3339)
+		resp.InitializedAt = &s
+	}
+	return resp
+}
+
+// listRepositoriesResponse wraps a paginated list of repositories.
+type listRepositoriesResponse struct {
+	Items []repositoryResponse `json:"items"`
+	Total int64                `json:"total"`
+}
+
+// List handles GET /api/v1/repositories.
+func (h *RepositoryHandler) List(w http.ResponseWriter, r *http.Request) {
+	opts := paginationOpts(r)
+
+	repos, total, err := h.repo.List(r.Context(), opts)
+	if err != nil {
+		h.logger.Error("failed to list repositories", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	items := make([]repositoryResponse, len(repos))
+	for i := range repos {
+		count, err := h.repo.CountPolicies(r.Context(), repos[i].ID)
+		if err != nil {
+			h.logger.Error("failed to count repository policies", zap.String("id", repos[i].ID.String()), zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		items[i] = repositoryToResponse(&repos[i], count)
+	}
+
+	Ok(w, listRepositoriesResponse{Items: items, Total: total})
+}
+
+// createRepositoryRequest is the JSON body expected by POST
+// /api/v1/repositories. Path is relative to the destination root; an empty
+// path is the root itself. ChunkerFromID optionally names the repository
+// whose chunker parameters the new one is initialised with.
+type createRepositoryRequest struct {
+	Name          string `json:"name"`
+	DestinationID string `json:"destination_id"`
+	Path          string `json:"path"`
+	Password      string `json:"password"` // stored encrypted
+	ChunkerFromID string `json:"chunker_from_id"`
+}
+
+// Create handles POST /api/v1/repositories.
+func (h *RepositoryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req createRepositoryRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Name == "" {
+		ErrBadRequest(w, "name is required")
+		return
+	}
+	if req.Password == "" {
+		ErrBadRequest(w, "password is required")
+		return
+	}
+	path, err := cleanRepositoryPath(req.Path)
+	if err != nil {
+		ErrBadRequest(w, err.Error())
+		return
+	}
+	destID, err := uuid.Parse(req.DestinationID)
+	if err != nil {
+		ErrBadRequest(w, "destination_id must be a valid UUID")
+		return
+	}
+	if _, err := h.dests.GetByID(r.Context(), destID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrBadRequest(w, "destination not found")
+			return
+		}
+		h.logger.Error("failed to get destination for repository", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	repo := &db.Repository{
+		Name:          req.Name,
+		DestinationID: destID,
+		Path:          path,
+		Password:      db.EncryptedString(req.Password),
+	}
+	if req.ChunkerFromID != "" {
+		fromID, err := uuid.Parse(req.ChunkerFromID)
+		if err != nil {
+			ErrBadRequest(w, "chunker_from_id must be a valid UUID")
+			return
+		}
+		if _, err := h.repo.GetByID(r.Context(), fromID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				ErrBadRequest(w, "chunker_from_id: repository not found")
+				return
+			}
+			h.logger.Error("failed to get chunker source repository", zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		repo.ChunkerFromID = &fromID
+	}
+
+	if err := h.repo.Create(r.Context(), repo); err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			ErrConflict(w, "the destination already has a repository at this path")
+			return
+		}
+		h.logger.Error("failed to create repository", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	logAudit(r, h.auditRepo, h.logger, "repository.create", "repository", repo.ID.String(), map[string]any{"name": repo.Name, "destination_id": repo.DestinationID.String(), "path": repo.Path})
+	Created(w, repositoryToResponse(repo, 0))
+}
+
+// cleanRepositoryPath normalizes a repository path relative to the
+// destination root. It may not leave the root.
+func cleanRepositoryPath(path string) (string, error) {
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	for _, part := range strings.Split(path, "/") {
+		if part == ".." || part == "." || (part == "" && path != "") {
+			return "", errors.New("path must be a relative path below the destination root")
+		}
+	}
+	return path, nil
+}
+
+// GetByID handles GET /api/v1/repositories/{id}.
+func (h *RepositoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	repo, ok := h.repositoryFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := h.repo.CountPolicies(r.Context(), repo.ID)
+	if err != nil {
+		h.logger.Error("failed to count repository policies", zap.String("id", repo.ID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	Ok(w, repositoryToResponse(repo, count))
+}
+
+// updateRepositoryRequest is the JSON body for PATCH
+// /api/v1/repositories/{id}. All fields are optional — only non-nil values
+// are applied. Password only changes what Arkeep opens the repository with:
+// it must already be a key of the repository (e.g. after restic key passwd).
+type updateRepositoryRequest struct {
+	Name     *string `json:"name"`
+	Password *string `json:"password"`
+}
+
+// Update handles PATCH /api/v1/repositories/{id}.
+func (h *RepositoryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req updateRepositoryRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	repo, ok := h.repositoryFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			ErrBadRequest(w, "name cannot be empty")
+			return
+		}
+		repo.Name = *req.Name
+	}
+	if req.Password != nil {
+		if *req.Password == "" {
+			ErrBadRequest(w, "password cannot be empty")
+			return
+		}
+		repo.Password = db.EncryptedString(*req.Password)
+	}
+
+	if err := h.repo.Update(r.Context(), repo); err != nil {
+		h.logger.Error("failed to update repository", zap.String("id", repo.ID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	count, err := h.repo.CountPolicies(r.Context(), repo.ID)
+	if err != nil {
+		h.logger.Error("failed to count repository policies", zap.String("id", repo.ID.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	logAudit(r, h.auditRepo, h.logger, "repository.update", "repository", repo.ID.String(), map[string]any{"name": repo.Name, "password_changed": req.Password != nil})
+	Ok(w, repositoryToResponse(repo, count))
+}
+
+// Delete handles DELETE /api/v1/repositories/{id}. Only the record is
+// removed; the data in the backend is left untouched.
+// Returns 409 if a policy still writes into the repository.
+func (h *RepositoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	count, err := h.repo.CountPolicies(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to count repository policies", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	if count > 0 {
+		ErrConflict(w, "repository is still used by one or more policies")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return
+		}
+		h.logger.Error("failed to delete repository", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	logAudit(r, h.auditRepo, h.logger, "repository.delete", "repository", id.String(), map[string]any{})
+	NoContent(w)
+}
+
+// repositoryFromPath loads the repository named by the {id} URL parameter,
+// writing the error response and returning false when it cannot.
+func (h *RepositoryHandler) repositoryFromPath(w http.ResponseWriter, r *http.Request) (*db.Repository, bool) {
+	id, ok := parseUUID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+	repo, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return nil, false
+		}
+		h.logger.Error("failed to get repository", zap.String("id", id.String()), zap.Error(err))
+		ErrInternal(w)
+		return nil, false
+	}
+	return repo, true
+}
+
+// policyRepository resolves how a policy opens its repository on one of its
+// destinations: the repository entity pd writes into, nil for the
+// destination root, and the password — the repository's, pd's own or the
+// policy's, in that order.
+func policyRepository(ctx context.Context, repos repositories.RepositoryRepository, policy *db.Policy, pd *db.PolicyDestination) (*db.Repository, string, error) {
+	if pd.RepositoryID != nil {
+		repo, err := repos.GetByID(ctx, *pd.RepositoryID)
+		if err != nil {
+			return nil, "", err
+		}
+		return repo, string(repo.Password), nil
+	}
+	if pd.RepoPassword != "" {
+		return nil, string(pd.RepoPassword), nil
+	}
+	return nil, string(policy.RepoPassword), nil
+}
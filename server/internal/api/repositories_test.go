@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+// createDBRepository inserts a repository record directly.
+func createDBRepository(t *testing.T, deps *testDeps, destID uuid.UUID, path string) *db.Repository {
+	t.Helper()
+	r := &db.Repository{Name: "repo-" + path, DestinationID: destID, Path: path, Password: "repo-secret"}
+	if err := deps.repos.Create(context.Background(), r); err != nil {
+		t.Fatalf("createDBRepository: %v", err)
+	}
+	return r
+}
+
+func TestRepositoryHandler_Create(t *testing.T) {
+	t.Run("creates repository and returns 201", func(t *testing.T) {
+		e := newTestEnv(t)
+		dest := createDBDestination(t, e.deps, "minio", "s3")
+		from := createDBRepository(t, e.deps, dest.ID, "onsite")
+
+		resp := e.post(t, "/api/v1/repositories", e.adminToken(t), map[string]string{
+			"name":            "shared",
+			"destination_id":  dest.ID.String(),
+			"path":            "/hosts/shared/",
+			"password":        "supersecret",
+			"chunker_from_id": from.ID.String(),
+		})
+		assertStatus(t, resp, http.StatusCreated)
+
+		var data struct {
+			ID            string  `json:"id"`
+			Path          string  `json:"path"`
+			ChunkerFromID *string `json:"chunker_from_id"`
+			InitializedAt *string `json:"initialized_at"`
+			Password      *string `json:"password"`
+		}
+		decodeData(t, resp, &data)
+		if data.Path != "hosts/shared" {
+			t.Errorf("path = %q, want hosts/shared", data.Path)
+		}
+		if data.ChunkerFromID == nil || *data.ChunkerFromID != from.ID.String() {
+			t.Errorf("chunker_from_id = %v, want %s", data.ChunkerFromID, from.ID)
+		}
+		if data.InitializedAt != nil {
+			t.Errorf("initialized_at = %v, want nil for a new repository", *data.InitializedAt)
+		}
+		if data.Password != nil {
+			t.Error("password must never be returned")
+		}
+	})
+
+	t.Run("returns 409 for a second repository at the same path", func(t *testing.T) {
+		e := newTestEnv(t)
+		dest := createDBDestination(t, e.deps, "minio", "s3")
+		createDBRepository(t, e.deps, dest.ID, "shared")
+
+		resp := e.post(t, "/api/v1/repositories", e.adminToken(t), map[string]string{
+			"name":           "again",
+			"destination_id": dest.ID.String(),
+			"path":           "shared",
+			"password":       "supersecret",
+		})
+		assertStatus(t, resp, http.StatusConflict)
+	})
+
+	t.Run("returns 400 for invalid input", func(t *testing.T) {
+		e := newTestEnv(t)
+		dest := createDBDestination(t, e.deps, "minio", "s3")
+		cases := map[string]map[string]string{
+			"missing password":    {"name": "r", "destination_id": dest.ID.String()},
+			"path leaves root":    {"name": "r", "destination_id": dest.ID.String(), "password": "p", "path": "a/../../b"},
+			"unknown destination": {"name": "r", "destination_id": uuid.New().String(), "password": "p"},
+			"unknown chunker":     {"name": "r", "destination_id": dest.ID.String(), "password": "p", "chunker_from_id": uuid.New().String()},
+		}
+		for name, body := range cases {
+			t.Run(name, func(t *testing.T) {
+				resp := e.post(t, "/api/v1/repositories", e.adminToken(t), body)
+				assertStatus(t, resp, http.StatusBadRequest)
+			})
+		}
+	})
+}
+
+func TestRepositoryHandler_PolicyUsage(t *testing.T) {
+	e := newTestEnv(t)
+	dest := createDBDestination(t, e.deps, "minio", "s3")
+	other := createDBDestination(t, e.deps, "nas", "local")
+	repo := createDBRepository(t, e.deps, dest.ID, "shared")
+
+	policy := func(destID uuid.UUID) map[string]any {
+		return map[string]any{
+			"name":     "web",
+			"agent_id": uuid.New().String(),
+			"schedule": "@daily",
+			"sources":  `[{"type":"directory","path":"/srv"}]`,
+			"destinations": []map[string]any{
+				{"destination_id": destID.String(), "repository_id": repo.ID.String()},
+			},
+		}
+	}
+
+	// The repository's password replaces the policy's, which may be omitted.
+	resp := e.post(t, "/api/v1/policies", e.adminToken(t), policy(dest.ID))
+	assertStatus(t, resp, http.StatusCreated)
+	var data struct {
+		Destinations []struct {
+			RepositoryID *string `json:"repository_id"`
+		} `json:"destinations"`
+	}
+	decodeData(t, resp, &data)
+	if len(data.Destinations) != 1 || data.Destinations[0].RepositoryID == nil || *data.Destinations[0].RepositoryID != repo.ID.String() {
+		t.Errorf("destinations = %+v, want the repository", data.Destinations)
+	}
+
+	resp = e.post(t, "/api/v1/policies", e.adminToken(t), policy(other.ID))
+	assertStatus(t, resp, http.StatusBadRequest)
+
+	resp = e.get(t, "/api/v1/repositories/"+repo.ID.String(), e.adminToken(t))
+	assertStatus(t, resp, http.StatusOK)
+	var got struct {
+		PolicyCount int64 `json:"policy_count"`
+	}
+	decodeData(t, resp, &got)
+	if got.PolicyCount != 1 {
+		t.Errorf("policy_count = %d, want 1", got.PolicyCount)
+	}
+
+	resp = e.del(t, "/api/v1/repositories/"+repo.ID.String(), e.adminToken(t))
+	assertStatus(t, resp, http.StatusConflict)
+}
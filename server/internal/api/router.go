@@ -26,17 +26,22 @@ type RouterConfig struct {
 	Hub          *websocket.Hub
 
 	// Repositories — used directly by handlers that do not need service-layer logic.
-	Users         repositories.UserRepository
-	Agents        repositories.AgentRepository
-	Destinations  repositories.DestinationRepository
-	Policies      repositories.PolicyRepository
-	Jobs          repositories.JobRepository
-	Snapshots     repositories.SnapshotRepository
-	Notifications repositories.NotificationRepository
-	OIDCProviders repositories.OIDCProviderRepository
-	Settings      repositories.SettingsRepository
-	Dashboard     repositories.DashboardRepository
-	Audit         repositories.AuditRepository
+	Users               repositories.UserRepository
+	Agents              repositories.AgentRepository
+	Destinations        repositories.DestinationRepository
+	Policies            repositories.PolicyRepository
+	Jobs                repositories.JobRepository
+	Snapshots           repositories.SnapshotRepository
+	Notifications       repositories.NotificationRepository
+	OIDCProviders       repositories.OIDCProviderRepository
+	Settings            repositories.SettingsRepository
+	Dashboard           repositories.DashboardRepository
+	Audit               repositories.AuditRepository
+	TriggerTokens       repositories.TriggerTokenRepository
+	Repositories        repositories.RepositoryRepository
+	Keys                repositories.KeyRepository
+	Imports             repositories.ImportRepository
+	DatabaseConnections repositories.DatabaseConnectionRepository
 
 	// Secure controls whether auth cookies are set with the Secure flag.
 	Secure bool
@@ -83,9 +88,11 @@ func NewRouter(cfg RouterConfig) *chi.Mux {
 	}
 	agentHandler        := NewAgentHandler(cfg.Agents, cfg.AgentManager, cfg.Audit, cfg.Logger)
 	destinationHandler  := NewDestinationHandler(cfg.Destinations, cfg.Audit, cfg.Logger)
-	policyHandler       := NewPolicyHandler(cfg.Policies, cfg.Agents, cfg.Scheduler, cfg.Audit, cfg.Logger)
+	repositoryHandler   := NewRepositoryHandler(cfg.Repositories, cfg.Destinations, cfg.Audit, cfg.Logger)
+	connectionHandler   := NewDatabaseConnectionHandler(cfg.DatabaseConnections, cfg.Audit, cfg.Logger)
+	policyHandler       := NewPolicyHandler(cfg.Policies, cfg.Agents, cfg.Repositories, cfg.Scheduler, cfg.Audit, cfg.Logger)
 	jobHandler          := NewJobHandler(cfg.Jobs, cfg.Logger)
-	snapshotHandler     := NewSnapshotHandler(cfg.Snapshots, cfg.Destinations, cfg.Policies, cfg.Jobs, cfg.AgentManager, cfg.Audit, cfg.Logger)
+	snapshotHandler     := NewSnapshotHandler(cfg.Snapshots, cfg.Destinations, cfg.Policies, cfg.Repositories, cfg.Keys, cfg.DatabaseConnections, cfg.Jobs, cfg.AgentManager, cfg.Audit, cfg.Logger)
 	userHandler         := NewUserHandler(cfg.Users, cfg.Audit, cfg.Logger)
 	notificationHandler := NewNotificationHandler(cfg.Notifications, cfg.Logger)
 	settingsHandler     := NewSettingsHandler(cfg.OIDCProviders, cfg.Settings, cfg.Audit, cfg.Logger)
@@ -93,6 +100,10 @@ func NewRouter(cfg RouterConfig) *chi.Mux {
 	dashboardHandler    := NewDashboardHandler(cfg.Dashboard, cfg.Logger)
 	versionHandler      := newVersionHandler(cfg.ServerVersion)
 	auditHandler        := NewAuditHandler(cfg.Audit, cfg.Logger)
+	scheduleHandler     := NewScheduleHandler(cfg.Scheduler, cfg.Agents, cfg.Destinations, cfg.Policies, cfg.Logger)
+	triggerTokenHandler := NewTriggerTokenHandler(cfg.TriggerTokens, cfg.Policies, cfg.Scheduler, cfg.Audit, cfg.Logger)
+	keyHandler          := NewKeyHandler(cfg.Keys, cfg.Policies, cfg.Agents, cfg.Scheduler, cfg.Audit, cfg.Logger)
+	importHandler       := NewImportHandler(cfg.Imports, cfg.Repositories, cfg.Agents, cfg.Policies, cfg.Jobs, cfg.Snapshots, cfg.Scheduler, cfg.Audit, cfg.Logger)
 
 	healthHandler := newHealthHandler(cfg.DB, cfg.Scheduler)
 	r.Get("/health/live", healthHandler.Live)
@@ -130,6 +141,11 @@ func NewRouter(cfg RouterConfig) *chi.Mux {
 			}
 
 			r.Get("/ws", wsHandler.ServeWS)
+
+			// Inbound trigger webhook — authenticated by the secret token in
+			// the URL. Rate-limited per IP to slow down token guessing.
+			triggerLimiter := NewRateLimiter(30, time.Minute)
+			r.With(RateLimit(triggerLimiter)).Post("/hooks/trigger/{token}", triggerTokenHandler.Trigger)
 		})
 
 		// --- Authenticated routes ---
@@ -151,6 +167,7 @@ func NewRouter(cfg RouterConfig) *chi.Mux {
 			r.Patch("/agents/{id}", agentHandler.Update)
 			r.With(RequireRole("admin")).Delete("/agents/{id}", agentHandler.Delete)
 			r.Get("/agents/{id}/volumes", agentHandler.ListVolumes)
+			r.With(RequireRole("admin")).Get("/agents/{id}/escrow-kit", keyHandler.EscrowKit)
 
 			// Destinations
 			r.Get("/destinations", destinationHandler.List)
@@ -159,6 +176,24 @@ func NewRouter(cfg RouterConfig) *chi.Mux {
 			r.Patch("/destinations/{id}", destinationHandler.Update)
 			r.Delete("/destinations/{id}", destinationHandler.Delete)
 
+			// Repositories
+			r.Get("/repositories", repositoryHandler.List)
+			r.Post("/repositories", repositoryHandler.Create)
+			r.Get("/repositories/{id}", repositoryHandler.GetByID)
+			r.Patch("/repositories/{id}", repositoryHandler.Update)
+			r.Delete("/repositories/{id}", repositoryHandler.Delete)
+			r.With(RequireRole("admin")).Get("/repositories/{id}/imports", importHandler.List)
+			r.With(RequireRole("admin")).Post("/repositories/{id}/imports", importHandler.Create)
+			r.With(RequireRole("admin")).Get("/repositories/{id}/imports/{importID}", importHandler.GetByID)
+			r.With(RequireRole("admin")).Post("/repositories/{id}/imports/{importID}/apply", importHandler.Apply)
+
+			// Database connections
+			r.Get("/database-connections", connectionHandler.List)
+			r.Post("/database-connections", connectionHandler.Create)
+			r.Get("/database-connections/{id}", connectionHandler.GetByID)
+			r.Patch("/database-connections/{id}", connectionHandler.Update)
+			r.Delete("/database-connections/{id}", connectionHandler.Delete)
+
 			// Policies
 			r.Get("/policies", policyHandler.List)
 			r.Post("/policies", policyHandler.Create)
@@ -166,7 +201,19 @@ func NewRouter(cfg RouterConfig) *chi.Mux {
 			r.Patch("/policies/{id}", policyHandler.Update)
 			r.With(RequireRole("admin")).Delete("/policies/{id}", policyHandler.Delete)
 			r.With(RequireRole("admin")).Post("/policies/{id}/trigger", policyHandler.Trigger)
+			r.With(RequireRole("admin")).Post("/policies/{id}/dry-run", policyHandler.DryRun)
 			r.Get("/policies/{id}/jobs", jobHandler.ListByPolicy)
+			r.With(RequireRole("admin")).Get("/policies/{id}/destinations/{destinationID}/password", policyHandler.DestinationPassword)
+			r.With(RequireRole("admin")).Get("/policies/{id}/trigger-tokens", triggerTokenHandler.List)
+			r.With(RequireRole("admin")).Post("/policies/{id}/trigger-tokens", triggerTokenHandler.Create)
+			r.With(RequireRole("admin")).Post("/policies/{id}/trigger-tokens/{tokenID}/rotate", triggerTokenHandler.Rotate)
+			r.With(RequireRole("admin")).Delete("/policies/{id}/trigger-tokens/{tokenID}", triggerTokenHandler.Revoke)
+			r.With(RequireRole("admin")).Get("/policies/{id}/key-shares", keyHandler.ListShares)
+			r.With(RequireRole("admin")).Post("/policies/{id}/key-shares", keyHandler.CreateShare)
+
+			// Schedule
+			r.Get("/schedule/upcoming", scheduleHandler.Upcoming)
+			r.Get("/schedule/queue", scheduleHandler.Queue)
 
 			// Jobs
 			r.Get("/jobs", jobHandler.List)
@@ -205,6 +252,8 @@ func NewRouter(cfg RouterConfig) *chi.Mux {
 				// SMTP configuration
 				r.Get("/settings/smtp", settingsHandler.GetSMTP)
 				r.Put("/settings/smtp", settingsHandler.UpsertSMTP)
+				r.Get("/settings/escrow", settingsHandler.GetEscrow)
+				r.Put("/settings/escrow", settingsHandler.UpsertEscrow)
 
 				// Notification delivery queue visibility
 				r.Get("/notifications/queue", notificationHandler.ListDeliveryQueue)
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go.uber.org/zap"
+
+	"github.com/arkeep-io/arkeep/server/internal/repositories"
+	"github.com/arkeep-io/arkeep/server/internal/scheduler"
+)
+
+const (
+	// defaultUpcomingWindow is the projection window used when "to" is omitted.
+	defaultUpcomingWindow = 24 * time.Hour
+	// maxUpcomingWindow caps the projection window to keep the response bounded.
+	maxUpcomingWindow = 31 * 24 * time.Hour
+	// maxUpcomingRuns caps the number of runs returned in a single response.
+	maxUpcomingRuns = 5000
+)
+
+// ScheduleHandler exposes read-only views of the scheduler's projected runs
+// and of its destination wait queue.
+type ScheduleHandler struct {
+	scheduler  *scheduler.Scheduler
+	agentRepo  repositories.AgentRepository
+	destRepo   repositories.DestinationRepository
+	policyRepo repositories.PolicyRepository
+	logger     *zap.Logger
+}
+
+// NewScheduleHandler creates a new ScheduleHandler.
+func NewScheduleHandler(
+	sched *scheduler.Scheduler,
+	agentRepo repositories.AgentRepository,
+	destRepo repositories.DestinationRepository,
+	policyRepo repositories.PolicyRepository,
+	logger *zap.Logger,
+) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduler:  sched,
+		agentRepo:  agentRepo,
+		destRepo:   destRepo,
+		policyRepo: policyRepo,
+		logger:     logger.Named("schedule_handler"),
+	}
+}
+
+// upcomingRunResponse is the JSON representation of a single projected run.
+type upcomingRunResponse struct {
+	PolicyID       string   `json:"policy_id"`
+	ScheduleID     *string  `json:"schedule_id"` // schedule entry, null for the policy's main schedule
+	PolicyName     string   `json:"policy_name"`
+	AgentID        string   `json:"agent_id"`
+	AgentName      string   `json:"agent_name"`
+	DestinationIDs []string `json:"destination_ids"`
+	ScheduledAt    string   `json:"scheduled_at"`
+}
+
+// scheduleCollisionResponse groups the runs that hit the same destination at
+// the same instant — the situation that overloads a shared storage backend.
+type scheduleCollisionResponse struct {
+	DestinationID string   `json:"destination_id"`
+	ScheduledAt   string   `json:"scheduled_at"`
+	PolicyIDs     []string `json:"policy_ids"`
+}
+
+// upcomingRunsResponse wraps the projection for GET /api/v1/schedule/upcoming.
+type upcomingRunsResponse struct {
+	From       string                      `json:"from"`
+	To         string                      `json:"to"`
+	Items      []upcomingRunResponse       `json:"items"`
+	Collisions []scheduleCollisionResponse `json:"collisions"`
+	// Truncated is true when more than maxUpcomingRuns runs fall in the window.
+	Truncated bool `json:"truncated"`
+}
+
+// Upcoming handles GET /api/v1/schedule/upcoming.
+// Lists every projected run of every enabled policy in the requested window,
+// ordered by time, together with the destination collisions it contains.
+// Supported query parameters:
+//
+//	from — RFC3339 start of the window (exclusive), default now
+//	to   — RFC3339 end of the window (inclusive), default from + 24h, max from + 31d
+func (h *ScheduleHandler) Upcoming(w http.ResponseWriter, r *http.Request) {
+	from := time.Now().UTC()
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			ErrBadRequest(w, "invalid from timestamp, expected RFC3339")
+			return
+		}
+		from = t.UTC()
+	}
+	to := from.Add(defaultUpcomingWindow)
+	if raw := r.URL.Query().Get("to"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			ErrBadRequest(w, "invalid to timestamp, expected RFC3339")
+			return
+		}
+		to = t.UTC()
+	}
+	if !to.After(from) {
+		ErrBadRequest(w, "to must be after from")
+		return
+	}
+	if to.Sub(from) > maxUpcomingWindow {
+		ErrBadRequest(w, "window must not exceed 31 days")
+		return
+	}
+
+	runs, truncated, err := h.scheduler.Upcoming(r.Context(), from, to, maxUpcomingRuns)
+	if err != nil {
+		h.logger.Error("failed to project upcoming runs", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	// Resolve agent names once per agent rather than once per run.
+	agentNames := make(map[string]string)
+	for _, run := range runs {
+		id := run.AgentID.String()
+		if _, ok := agentNames[id]; ok {
+			continue
+		}
+		agentNames[id] = ""
+		if agent, err := h.agentRepo.GetByID(r.Context(), run.AgentID); err == nil {
+			agentNames[id] = agent.Name
+		}
+	}
+
+	type collisionKey struct {
+		destinationID string
+		at            time.Time
+	}
+	byDestination := make(map[collisionKey][]string)
+	var keys []collisionKey
+
+	items := make([]upcomingRunResponse, len(runs))
+	for i, run := range runs {
+		destIDs := make([]string, len(run.DestinationIDs))
+		for j, id := range run.DestinationIDs {
+			destIDs[j] = id.String()
+
+			k := collisionKey{destinationID: id.String(), at: run.ScheduledAt}
+			if _, ok := byDestination[k]; !ok {
+				keys = append(keys, k)
+			}
+			byDestination[k] = append(byDestination[k], run.PolicyID.String())
+		}
+		items[i] = upcomingRunResponse{
+			PolicyID:       run.PolicyID.String(),
+			PolicyName:     run.PolicyName,
+			AgentID:        run.AgentID.String(),
+			AgentName:      agentNames[run.AgentID.String()],
+			DestinationIDs: destIDs,
+			ScheduledAt:    run.ScheduledAt.Format(time.RFC3339),
+		}
+		if run.ScheduleID != nil {
+			id := run.ScheduleID.String()
+			items[i].ScheduleID = &id
+		}
+	}
+
+	// keys preserves first-seen order, which follows the time-ordered runs.
+	collisions := []scheduleCollisionResponse{}
+	for _, k := range keys {
+		if policyIDs := byDestination[k]; len(policyIDs) > 1 {
+			collisions = append(collisions, scheduleCollisionResponse{
+				DestinationID: k.destinationID,
+				ScheduledAt:   k.at.Format(time.RFC3339),
+				PolicyIDs:     policyIDs,
+			})
+		}
+	}
+
+	Ok(w, upcomingRunsResponse{
+		From:       from.Format(time.RFC3339),
+		To:         to.Format(time.RFC3339),
+		Items:      items,
+		Collisions: collisions,
+		Truncated:  truncated,
+	})
+}
+
+// queuedJobResponse is the JSON representation of a job waiting for a
+// destination slot.
+type queuedJobResponse struct {
+	JobID          string   `json:"job_id"`
+	PolicyID       string   `json:"policy_id"`
+	PolicyName     string   `json:"policy_name"`
+	AgentID        string   `json:"agent_id"`
+	DestinationIDs []string `json:"destination_ids"`
+	QueuedAt       string   `json:"queued_at"`
+}
+
+// destinationLoadResponse summarises the concurrency state of a destination.
+type destinationLoadResponse struct {
+	DestinationID     string `json:"destination_id"`
+	DestinationName   string `json:"destination_name"`
+	MaxConcurrentJobs int    `json:"max_concurrent_jobs"` // 0 = unlimited
+	Running           int    `json:"running"`
+	Queued            int    `json:"queued"`
+}
+
+// queueResponse wraps the wait queue for GET /api/v1/schedule/queue.
+type queueResponse struct {
+	Destinations []destinationLoadResponse `json:"destinations"`
+	Items        []queuedJobResponse       `json:"items"`
+}
+
+// Queue handles GET /api/v1/schedule/queue.
+// Returns the jobs waiting for a destination slot in dispatch order, and the
+// running/queued counts of every destination that is currently busy.
+func (h *ScheduleHandler) Queue(w http.ResponseWriter, r *http.Request) {
+	queued := h.scheduler.Queue()
+	running := h.scheduler.RunningByDestination()
+
+	queuedPerDest := make(map[uuid.UUID]int)
+	policyNames := make(map[uuid.UUID]string)
+	items := make([]queuedJobResponse, len(queued))
+	for i, q := range queued {
+		destIDs := make([]string, len(q.DestinationIDs))
+		for j, id := range q.DestinationIDs {
+			destIDs[j] = id.String()
+			queuedPerDest[id]++
+		}
+		if _, ok := policyNames[q.PolicyID]; !ok {
+			policyNames[q.PolicyID] = ""
+			if policy, err := h.policyRepo.GetByID(r.Context(), q.PolicyID); err == nil {
+				policyNames[q.PolicyID] = policy.Name
+			}
+		}
+		items[i] = queuedJobResponse{
+			JobID:          q.JobID.String(),
+			PolicyID:       q.PolicyID.String(),
+			PolicyName:     policyNames[q.PolicyID],
+			AgentID:        q.AgentID.String(),
+			DestinationIDs: destIDs,
+			QueuedAt:       q.QueuedAt.Format(time.RFC3339),
+		}
+	}
+
+	busy := make(map[uuid.UUID]bool)
+	for id := range running {
+		busy[id] = true
+	}
+	for id := range queuedPerDest {
+		busy[id] = true
+	}
+
+	destinations := make([]destinationLoadResponse, 0, len(busy))
+	for id := range busy {
+		load := destinationLoadResponse{
+			DestinationID: id.String(),
+			Running:       running[id],
+			Queued:        queuedPerDest[id],
+		}
+		if dest, err := h.destRepo.GetByID(r.Context(), id); err == nil {
+			load.DestinationName = dest.Name
+			load.MaxConcurrentJobs = dest.MaxConcurrentJobs
+		}
+		destinations = append(destinations, load)
+	}
+	sort.Slice(destinations, func(i, j int) bool {
+		return destinations[i].DestinationName < destinations[j].DestinationName
+	})
+
+	Ok(w, queueResponse{Destinations: destinations, Items: items})
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+func TestScheduleHandler_Upcoming(t *testing.T) {
+	type upcoming struct {
+		Items []struct {
+			PolicyID    string `json:"policy_id"`
+			ScheduledAt string `json:"scheduled_at"`
+		} `json:"items"`
+		Collisions []struct {
+			DestinationID string   `json:"destination_id"`
+			ScheduledAt   string   `json:"scheduled_at"`
+			PolicyIDs     []string `json:"policy_ids"`
+		} `json:"collisions"`
+		Truncated bool `json:"truncated"`
+	}
+
+	t.Run("returns 401 without token", func(t *testing.T) {
+		e := newTestEnv(t)
+		resp := e.get(t, "/api/v1/schedule/upcoming", "")
+		assertStatus(t, resp, http.StatusUnauthorized)
+	})
+
+	t.Run("projects runs in the policy timezone", func(t *testing.T) {
+		e := newTestEnv(t)
+		p := createDBPolicy(t, e.deps, "nightly", uuid.New())
+		p.Schedule = "0 2 * * *"
+		p.Timezone = "America/New_York"
+		if err := e.deps.policies.Update(context.Background(), p); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		resp := e.get(t, "/api/v1/schedule/upcoming?from=2026-01-01T00:00:00Z&to=2026-01-03T00:00:00Z", e.adminToken(t))
+		assertStatus(t, resp, http.StatusOK)
+
+		var data upcoming
+		decodeData(t, resp, &data)
+		// 02:00 in New York is 07:00 UTC in January (EST, UTC-5).
+		want := []string{"2026-01-01T07:00:00Z", "2026-01-02T07:00:00Z"}
+		if len(data.Items) != len(want) {
+			t.Fatalf("items = %d, want %d", len(data.Items), len(want))
+		}
+		for i, w := range want {
+			if data.Items[i].ScheduledAt != w {
+				t.Errorf("items[%d].scheduled_at = %q, want %q", i, data.Items[i].ScheduledAt, w)
+			}
+		}
+	})
+
+	t.Run("reports policies hitting the same destination at once", func(t *testing.T) {
+		e := newTestEnv(t)
+		ctx := context.Background()
+		dest := &db.Destination{Name: "minio", Type: "s3", Credentials: "{}", Config: "{}"}
+		if err := e.deps.dests.Create(ctx, dest); err != nil {
+			t.Fatalf("Create destination: %v", err)
+		}
+		for _, name := range []string{"a", "b"} {
+			p := createDBPolicy(t, e.deps, name, uuid.New())
+			p.Schedule = "0 2 * * *"
+			p.Timezone = "UTC"
+			if err := e.deps.policies.Update(ctx, p); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+			if err := e.deps.policies.AddDestination(ctx, &db.PolicyDestination{PolicyID: p.ID, DestinationID: dest.ID}); err != nil {
+				t.Fatalf("AddDestination: %v", err)
+			}
+		}
+
+		resp := e.get(t, "/api/v1/schedule/upcoming?from=2026-01-01T00:00:00Z&to=2026-01-01T12:00:00Z", e.adminToken(t))
+		assertStatus(t, resp, http.StatusOK)
+
+		var data upcoming
+		decodeData(t, resp, &data)
+		if len(data.Collisions) != 1 {
+			t.Fatalf("collisions = %d, want 1", len(data.Collisions))
+		}
+		c := data.Collisions[0]
+		if c.DestinationID != dest.ID.String() || c.ScheduledAt != "2026-01-01T02:00:00Z" || len(c.PolicyIDs) != 2 {
+			t.Errorf("unexpected collision: %+v", c)
+		}
+	})
+
+	t.Run("returns 400 for inverted window", func(t *testing.T) {
+		e := newTestEnv(t)
+		resp := e.get(t, "/api/v1/schedule/upcoming?from=2026-01-02T00:00:00Z&to=2026-01-01T00:00:00Z", e.adminToken(t))
+		assertStatus(t, resp, http.StatusBadRequest)
+	})
+
+	t.Run("returns 400 for window longer than 31 days", func(t *testing.T) {
+		e := newTestEnv(t)
+		resp := e.get(t, "/api/v1/schedule/upcoming?from=2026-01-01T00:00:00Z&to=2026-03-01T00:00:00Z", e.adminToken(t))
+		assertStatus(t, resp, http.StatusBadRequest)
+	})
+}
+
+func TestScheduleHandler_Queue(t *testing.T) {
+	t.Run("returns 401 without token", func(t *testing.T) {
+		e := newTestEnv(t)
+		resp := e.get(t, "/api/v1/schedule/queue", "")
+		assertStatus(t, resp, http.StatusUnauthorized)
+	})
+
+	t.Run("returns empty queue when nothing is waiting", func(t *testing.T) {
+		e := newTestEnv(t)
+		resp := e.get(t, "/api/v1/schedule/queue", e.adminToken(t))
+		assertStatus(t, resp, http.StatusOK)
+
+		var data struct {
+			Destinations []any `json:"destinations"`
+			Items        []any `json:"items"`
+		}
+		decodeData(t, resp, &data)
+		if data.Destinations == nil || data.Items == nil || len(data.Destinations) != 0 || len(data.Items) != 0 {
+			t.Errorf("unexpected queue: %+v", data)
+		}
+	})
+}
@@ -12,13 +12,17 @@ import (
 	"github.com/arkeep-io/arkeep/server/internal/db"
 	"github.com/arkeep-io/arkeep/server/internal/notification"
 	"github.com/arkeep-io/arkeep/server/internal/repositories"
+	"github.com/arkeep-io/arkeep/server/internal/scheduler"
+	"github.com/arkeep-io/arkeep/shared/keyseal"
 )
 
 // SettingsHandler groups settings-related HTTP handlers.
 // All routes in this handler are admin-only, enforced by RequireRole("admin")
-// in the router. Two configuration namespaces are supported:
+// in the router. Three configuration namespaces are supported:
 //   - OIDC: stored in the oidc_providers table via OIDCProviderRepository
 //   - SMTP: stored as key-value pairs in the settings table via SettingsRepository
+//   - Escrow: the public key zero-knowledge agents seal their escrow kits to,
+//     stored in the settings table
 type SettingsHandler struct {
 	oidcRepo     repositories.OIDCProviderRepository
 	settingsRepo repositories.SettingsRepository
@@ -408,6 +412,61 @@ func validateUpsertSMTP(req *upsertSMTPRequest) error {
 	return nil
 }
 
+// =============================================================================
+// Escrow
+// =============================================================================
+
+// escrowResponse is the escrow public key configuration. The matching
+// private key is held by the admin, offline; the server never sees it.
+type escrowResponse struct {
+	PublicKey   string `json:"public_key"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// GetEscrow handles GET /api/v1/settings/escrow (admin only).
+func (h *SettingsHandler) GetEscrow(w http.ResponseWriter, r *http.Request) {
+	setting, err := h.settingsRepo.Get(r.Context(), scheduler.EscrowPublicKeySetting)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			ErrNotFound(w)
+			return
+		}
+		h.logger.Error("failed to load escrow settings", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+	key := string(setting.Value)
+	Ok(w, escrowResponse{PublicKey: key, Fingerprint: keyseal.Fingerprint(key)})
+}
+
+type upsertEscrowRequest struct {
+	PublicKey string `json:"public_key"`
+}
+
+// UpsertEscrow handles PUT /api/v1/settings/escrow (admin only). Agents of
+// zero-knowledge policies export a new escrow kit to the key after their
+// next backup.
+func (h *SettingsHandler) UpsertEscrow(w http.ResponseWriter, r *http.Request) {
+	var req upsertEscrowRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if _, err := keyseal.ParsePublicKey(req.PublicKey); err != nil {
+		ErrBadRequest(w, "public_key must be a base64 X25519 public key (see arkeep-agent escrow keygen)")
+		return
+	}
+
+	if err := h.settingsRepo.Set(r.Context(), scheduler.EscrowPublicKeySetting, db.EncryptedString(req.PublicKey)); err != nil {
+		h.logger.Error("failed to save escrow setting", zap.Error(err))
+		ErrInternal(w)
+		return
+	}
+
+	fingerprint := keyseal.Fingerprint(req.PublicKey)
+	logAudit(r, h.auditRepo, h.logger, "settings.escrow.update", "settings", "", map[string]any{"fingerprint": fingerprint})
+	Ok(w, escrowResponse{PublicKey: req.PublicKey, Fingerprint: fingerprint})
+}
+
 // =============================================================================
 // Internal helpers
 // =============================================================================
@@ -3,6 +3,8 @@ package api
 import (
 	"net/http"
 	"testing"
+
+	"github.com/arkeep-io/arkeep/shared/keyseal"
 )
 
 func TestSettingsHandler_ListOIDC(t *testing.T) {
@@ -321,3 +323,37 @@ func TestSettingsHandler_UpsertSMTP(t *testing.T) {
 		assertStatus(t, resp, http.StatusUnauthorized)
 	})
 }
+
+func TestSettingsHandler_Escrow(t *testing.T) {
+	e := newTestEnv(t)
+
+	t.Run("returns 404 when no escrow key is configured", func(t *testing.T) {
+		assertStatus(t, e.get(t, "/api/v1/settings/escrow", e.adminToken(t)), http.StatusNotFound)
+	})
+
+	t.Run("returns 400 for an invalid public key", func(t *testing.T) {
+		resp := e.doJSON(t, "PUT", "/api/v1/settings/escrow", e.adminToken(t), map[string]any{"public_key": "not-a-key"})
+		assertStatus(t, resp, http.StatusBadRequest)
+	})
+
+	t.Run("returns 403 for non-admin", func(t *testing.T) {
+		assertStatus(t, e.get(t, "/api/v1/settings/escrow", e.userToken(t)), http.StatusForbidden)
+	})
+
+	t.Run("stores the public key", func(t *testing.T) {
+		_, publicKey, err := keyseal.GenerateKey()
+		if err != nil {
+			t.Fatalf("GenerateKey: %v", err)
+		}
+		resp := e.doJSON(t, "PUT", "/api/v1/settings/escrow", e.adminToken(t), map[string]any{"public_key": publicKey})
+		assertStatus(t, resp, http.StatusOK)
+
+		resp = e.get(t, "/api/v1/settings/escrow", e.adminToken(t))
+		assertStatus(t, resp, http.StatusOK)
+		var data escrowResponse
+		decodeData(t, resp, &data)
+		if data.PublicKey != publicKey || data.Fingerprint != keyseal.Fingerprint(publicKey) {
+			t.Errorf("escrow = %+v, want the stored key", data)
+		}
+	})
+}
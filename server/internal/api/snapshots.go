@@ -1,9 +1,11 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +16,7 @@ import (
 	"github.com/arkeep-io/arkeep/server/internal/db"
 	"github.com/arkeep-io/arkeep/server/internal/destutil"
 	"github.com/arkeep-io/arkeep/server/internal/repositories"
+	"github.com/arkeep-io/arkeep/server/internal/scheduler"
 	proto "github.com/arkeep-io/arkeep/shared/proto"
 )
 
@@ -26,6 +29,9 @@ type SnapshotHandler struct {
 	repo      repositories.SnapshotRepository
 	dests     repositories.DestinationRepository
 	policies  repositories.PolicyRepository
+	repos     repositories.RepositoryRepository
+	keys      repositories.KeyRepository
+	conns     repositories.DatabaseConnectionRepository
 	jobs      repositories.JobRepository
 	agentMgr  *agentmanager.Manager
 	auditRepo repositories.AuditRepository
@@ -37,6 +43,9 @@ func NewSnapshotHandler(
 	repo repositories.SnapshotRepository,
 	dests repositories.DestinationRepository,
 	policies repositories.PolicyRepository,
+	repos repositories.RepositoryRepository,
+	keys repositories.KeyRepository,
+	conns repositories.DatabaseConnectionRepository,
 	jobs repositories.JobRepository,
 	agentMgr *agentmanager.Manager,
 	auditRepo repositories.AuditRepository,
@@ -46,6 +55,9 @@ func NewSnapshotHandler(
 		repo:      repo,
 		dests:     dests,
 		policies:  policies,
+		repos:     repos,
+		keys:      keys,
+		conns:     conns,
 		jobs:      jobs,
 		agentMgr:  agentMgr,
 		auditRepo: auditRepo,
@@ -69,6 +81,9 @@ type snapshotResponse struct {
 	SizeBytes        int64  `json:"size_bytes"`
 	Tags             string `json:"tags"`
 	CreatedAt        string `json:"created_at"`
+	// OriginalID is the snapshot this one was copied from by a replication
+	// job, nil for backup snapshots.
+	OriginalID *string `json:"original_id"`
 }
 
 // listSnapshotsResponse wraps a paginated list of snapshots.
@@ -78,9 +93,19 @@ type listSnapshotsResponse struct {
 }
 
 // restoreRequest is the body for POST /api/v1/snapshots/{id}/restore.
+// A database dump is restored into a database when TargetPath is empty or
+// Database is set, and restored as a file into TargetPath otherwise.
 type restoreRequest struct {
-	AgentID    string `json:"agent_id"`
-	TargetPath string `json:"target_path"`
+	AgentID    string                  `json:"agent_id"`
+	TargetPath string                  `json:"target_path"`
+	Database   *restoreDatabaseRequest `json:"database"`
+}
+
+// restoreDatabaseRequest names the database a dump is restored into. Empty
+// fields default to the connection and database the dump was taken from.
+type restoreDatabaseRequest struct {
+	ConnectionID string `json:"connection_id"`
+	Name         string `json:"name"`
 }
 
 // restoreResponse is returned after a restore job is successfully dispatched.
@@ -95,6 +120,35 @@ type restorePayload struct {
 	RepoPassword     string            `json:"repo_password"`
 	TargetPath       string            `json:"target_path"`
 	Destination      destinationFields `json:"destination"`
+
+	// Zero-knowledge policies: the agent opens the repository with the key
+	// it holds under KeyID. Another agent needs the key shared with it
+	// first; SealedKey carries the share, sealed to that agent.
+	KeyID          string `json:"key_id,omitempty"`
+	KeyFingerprint string `json:"key_fingerprint,omitempty"`
+	SealedKey      string `json:"sealed_key,omitempty"`
+
+	// Database, set for the restore of a database dump, is the database the
+	// agent pipes the dump into with the engine's restore tool.
+	Database *restoreDatabaseFields `json:"database,omitempty"`
+
+	// Hooks are the pre_restore and post_restore steps of the snapshot's
+	// policy, sent only when restoring on the policy's own agent: they are
+	// written for that host.
+	Hooks scheduler.Hooks `json:"hooks,omitempty"`
+}
+
+// restoreDatabaseFields carries the target of a database restore with the
+// connection's decrypted password. Mirrors the agent's databasePayload.
+type restoreDatabaseFields struct {
+	Engine         string `json:"engine"`
+	Host           string `json:"host,omitempty"`
+	Port           int    `json:"port,omitempty"`
+	Username       string `json:"username,omitempty"`
+	Password       string `json:"password,omitempty"`
+	Database       string `json:"database"`
+	Filename       string `json:"filename"`
+	SourceDatabase string `json:"source_database,omitempty"`
 }
 
 // destinationFields carries the resolved details of the backup destination
@@ -119,7 +173,17 @@ func snapshotWithNamesToResponse(s repositories.SnapshotWithNames) snapshotRespo
 		SizeBytes:        s.SizeBytes,
 		Tags:             s.Tags,
 		CreatedAt:        s.SnapshotAt.UTC().Format(time.RFC3339),
+		OriginalID:       originalID(&s.Snapshot),
+	}
+}
+
+// originalID returns the ID of the snapshot s was copied from, or nil.
+func originalID(s *db.Snapshot) *string {
+	if s.OriginalID == nil {
+		return nil
 	}
+	id := s.OriginalID.String()
+	return &id
 }
 
 // -----------------------------------------------------------------------------
@@ -198,6 +262,7 @@ func (h *SnapshotHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		SizeBytes:        snapshot.SizeBytes,
 		Tags:             snapshot.Tags,
 		CreatedAt:        snapshot.SnapshotAt.UTC().Format(time.RFC3339),
+		OriginalID:       originalID(snapshot),
 	})
 }
 
@@ -224,12 +289,14 @@ func (h *SnapshotHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 // Restore handles POST /api/v1/snapshots/{id}/restore.
 // Creates a restore job and dispatches it to the chosen agent via gRPC.
-// The agent will run `restic restore <snapshot_id> --target <target_path>`.
+// The agent will run `restic restore <snapshot_id> --target <target_path>`,
+// or for a database dump pipe `restic dump` into the engine's restore tool.
 //
 // Flow:
 //  1. Load snapshot → get restic_snapshot_id, destination_id, policy_id
 //  2. Load destination → build repo URL and env (credentials)
-//  3. Load policy → get repo password
+//  3. Load policy → get repo password, or for a zero-knowledge policy the
+//     key ID and, when restoring onto another agent, the key shared with it
 //  4. Create db.Job{Type: "restore"} for the chosen agent
 //  5. Build and dispatch JobAssignment with JOB_TYPE_RESTORE
 func (h *SnapshotHandler) Restore(w http.ResponseWriter, r *http.Request) {
@@ -247,10 +314,6 @@ func (h *SnapshotHandler) Restore(w http.ResponseWriter, r *http.Request) {
 		ErrBadRequest(w, "agent_id is required")
 		return
 	}
-	if req.TargetPath == "" {
-		ErrBadRequest(w, "target_path is required")
-		return
-	}
 
 	agentID, err := uuid.Parse(req.AgentID)
 	if err != nil {
@@ -272,6 +335,21 @@ func (h *SnapshotHandler) Restore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var database *restoreDatabaseFields
+	if req.Database != nil || req.TargetPath == "" {
+		var msg string
+		database, msg, err = h.restoreDatabase(ctx, snapshot, req.Database)
+		if err != nil {
+			h.logger.Error("failed to resolve database for restore", zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		if msg != "" {
+			ErrBadRequest(w, msg)
+			return
+		}
+	}
+
 	// --- 2. Load destination ---
 	dest, err := h.dests.GetByID(ctx, snapshot.DestinationID)
 	if err != nil {
@@ -285,7 +363,7 @@ func (h *SnapshotHandler) Restore(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// --- 3. Load policy (for repo password) ---
-	policy, err := h.policies.GetByID(ctx, snapshot.PolicyID)
+	policy, policyDests, err := h.policies.GetByIDWithDestinations(ctx, snapshot.PolicyID)
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
 			ErrBadRequest(w, "policy not found")
@@ -296,6 +374,55 @@ func (h *SnapshotHandler) Restore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The policy's destination entry tells which repository and password
+	// the snapshot was written with.
+	repoURL := destutil.BuildRepoURL(dest)
+	password := string(policy.RepoPassword)
+	for i := range policyDests {
+		if policyDests[i].DestinationID != dest.ID {
+			continue
+		}
+		repo, pw, err := policyRepository(ctx, h.repos, policy, &policyDests[i])
+		if err != nil {
+			h.logger.Error("failed to load repository for restore", zap.Error(err))
+			ErrInternal(w)
+			return
+		}
+		if repo != nil {
+			repoURL = destutil.BuildRepositoryURL(dest, repo.Path)
+		}
+		password = pw
+	}
+
+	var keyID, keyFingerprint, sealedKey string
+	if policy.ZeroKnowledge {
+		pd := db.PolicyDestination{PolicyID: policy.ID, DestinationID: dest.ID}
+		for _, d := range policyDests {
+			if d.DestinationID == dest.ID {
+				pd = d
+			}
+		}
+		keyID, keyFingerprint, password = pd.KeyID(), pd.KeyFingerprint, ""
+		if agentID != policy.AgentID {
+			share, err := h.keys.GetSealedShare(ctx, keyID, agentID)
+			if err != nil {
+				if errors.Is(err, repositories.ErrNotFound) {
+					ErrConflict(w, "the repository key of this zero-knowledge policy has not been shared with the agent; share it first")
+					return
+				}
+				h.logger.Error("failed to load key share for restore", zap.Error(err))
+				ErrInternal(w)
+				return
+			}
+			sealedKey = share.SealedKey
+		}
+	}
+
+	var hooks scheduler.Hooks
+	if agentID == policy.AgentID {
+		hooks = policyHooks(policy.Hooks).Phases(scheduler.HookPreRestore, scheduler.HookPostRestore)
+	}
+
 	// --- 4. Create restore job ---
 	job := &db.Job{
 		PolicyID: snapshot.PolicyID,
@@ -312,14 +439,19 @@ func (h *SnapshotHandler) Restore(w http.ResponseWriter, r *http.Request) {
 	// --- 5. Build and dispatch ---
 	payload := restorePayload{
 		ResticSnapshotID: snapshot.SnapshotID,
-		RepoPassword:     string(policy.RepoPassword),
+		RepoPassword:     password,
 		TargetPath:       req.TargetPath,
 		Destination: destinationFields{
 			DestinationID: dest.ID.String(),
 			Type:          dest.Type,
-			RepoURL:       destutil.BuildRepoURL(dest),
+			RepoURL:       repoURL,
 			Env:           destutil.BuildEnv(dest),
 		},
+		KeyID:          keyID,
+		KeyFingerprint: keyFingerprint,
+		SealedKey:      sealedKey,
+		Database:       database,
+		Hooks:          hooks,
 	}
 
 	payloadBytes, err := json.Marshal(payload)
@@ -357,12 +489,16 @@ func (h *SnapshotHandler) Restore(w http.ResponseWriter, r *http.Request) {
 		zap.String("target_path", req.TargetPath),
 	)
 
-	logAudit(r, h.auditRepo, h.logger, "snapshot.restore", "snapshot", snapshotID.String(), map[string]any{
+	details := map[string]any{
 		"snapshot_id":    snapshot.SnapshotID,
 		"destination_id": snapshot.DestinationID.String(),
 		"target_path":    req.TargetPath,
 		"agent_id":       agentID.String(),
-	})
+	}
+	if database != nil {
+		details["database"] = database.Database
+	}
+	logAudit(r, h.auditRepo, h.logger, "snapshot.restore", "snapshot", snapshotID.String(), details)
 	Ok(w, restoreResponse{JobID: job.ID.String()})
 }
 
@@ -370,6 +506,79 @@ func (h *SnapshotHandler) Restore(w http.ResponseWriter, r *http.Request) {
 // Internal helpers
 // -----------------------------------------------------------------------------
 
+// restoreDatabase resolves the database a dump snapshot is restored into,
+// from the request or else from the snapshot's tags. It returns the message
+// of a 400 response when the restore is not possible.
+func (h *SnapshotHandler) restoreDatabase(ctx context.Context, snapshot *db.Snapshot, req *restoreDatabaseRequest) (*restoreDatabaseFields, string, error) {
+	engine, source, connID := databaseTags(snapshot.Tags)
+	if engine == "" {
+		if req == nil {
+			return nil, "target_path is required", nil
+		}
+		return nil, "the snapshot is not a database dump", nil
+	}
+	if engine == scheduler.EngineRedis {
+		return nil, "Redis dumps cannot be loaded into a running server: restore the RDB file with target_path", nil
+	}
+	if req == nil {
+		req = &restoreDatabaseRequest{}
+	}
+	if req.ConnectionID != "" {
+		connID = req.ConnectionID
+	}
+	id, err := uuid.Parse(connID)
+	if err != nil {
+		return nil, "database.connection_id must be a valid UUID", nil
+	}
+	if h.conns == nil {
+		return nil, "database restores are not available", nil
+	}
+	conn, err := h.conns.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, "database connection not found", nil
+		}
+		return nil, "", err
+	}
+	if conn.Engine != engine {
+		return nil, "the database connection is not a " + engine + " connection", nil
+	}
+
+	target := source
+	if req.Name != "" {
+		target = req.Name
+	}
+	if strings.HasPrefix(target, "-") {
+		return nil, "invalid database name", nil
+	}
+	return &restoreDatabaseFields{
+		Engine:         conn.Engine,
+		Host:           conn.Host,
+		Port:           conn.Port,
+		Username:       conn.Username,
+		Password:       string(conn.Password), // decrypted
+		Database:       target,
+		Filename:       scheduler.DumpFilename(engine, source),
+		SourceDatabase: source,
+	}, "", nil
+}
+
+// databaseTags reads the database tags of a dump snapshot: the engine and
+// name of the dumped database and the connection it was taken through. The
+// engine is "" when the snapshot is not a database dump.
+func databaseTags(tagsJSON string) (engine, database, connID string) {
+	var tags []string
+	_ = json.Unmarshal([]byte(tagsJSON), &tags)
+	for _, tag := range tags {
+		if rest, ok := strings.CutPrefix(tag, scheduler.DatabaseTagPrefix); ok {
+			engine, database, _ = strings.Cut(rest, ":")
+		} else if rest, ok := strings.CutPrefix(tag, scheduler.ConnectionTagPrefix); ok {
+			connID = rest
+		}
+	}
+	return engine, database, connID
+}
+
 func (h *SnapshotHandler) writeSnapshotList(w http.ResponseWriter, snapshots []repositories.SnapshotWithNames, total int64) {
 	items := make([]snapshotResponse, len(snapshots))
 	for i := range snapshots {
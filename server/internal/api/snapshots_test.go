@@ -167,6 +167,27 @@ func TestSnapshotHandler_Delete(t *testing.T) {
 	})
 }
 
+// createDBDumpSnapshot inserts the snapshot of a database dump, with the
+// given tags, written by a policy to a destination that exist.
+func createDBDumpSnapshot(t *testing.T, deps *testDeps, tags string) *db.Snapshot {
+	t.Helper()
+	dest := createDBDestination(t, deps, "nas", "local")
+	policy := createDBPolicy(t, deps, "db-"+uuid.NewString(), uuid.New())
+	s := &db.Snapshot{
+		PolicyID:      policy.ID,
+		DestinationID: dest.ID,
+		JobID:         uuid.New(),
+		SnapshotID:    "d0d0" + uuid.NewString()[:8],
+		SizeBytes:     2048,
+		Tags:          tags,
+		SnapshotAt:    time.Now(),
+	}
+	if err := deps.snaps.Create(context.Background(), s); err != nil {
+		t.Fatalf("createDBDumpSnapshot: %v", err)
+	}
+	return s
+}
+
 func TestSnapshotHandler_Restore(t *testing.T) {
 	t.Run("returns 404 for non-existent snapshot", func(t *testing.T) {
 		e := newTestEnv(t)
@@ -219,6 +240,48 @@ func TestSnapshotHandler_Restore(t *testing.T) {
 		assertStatus(t, resp, http.StatusBadRequest)
 	})
 
+	t.Run("restores a database dump into its database", func(t *testing.T) {
+		e := newTestEnv(t)
+		conn := createDBConnection(t, e.deps, "postgres")
+		s := createDBDumpSnapshot(t, e.deps, `["db:postgres:billing","db-connection:`+conn.ID.String()+`"]`)
+
+		// Resolution succeeded: the job reaches dispatch, which fails with
+		// the agent offline.
+		resp := e.post(t, "/api/v1/snapshots/"+s.ID.String()+"/restore",
+			e.adminToken(t), map[string]any{
+				"agent_id": uuid.NewString(),
+				"database": map[string]string{"name": "billing_restored"},
+			})
+		assertStatus(t, resp, http.StatusServiceUnavailable)
+	})
+
+	t.Run("returns 400 for database restores that cannot run", func(t *testing.T) {
+		e := newTestEnv(t)
+		pg := createDBConnection(t, e.deps, "postgres")
+		mysql := createDBConnection(t, e.deps, "mysql")
+		cases := map[string]struct {
+			tags     string
+			database map[string]string
+		}{
+			"not a dump":      {`[]`, map[string]string{}},
+			"redis":           {`["db:redis:cache:6379"]`, nil},
+			"engine mismatch": {`["db:postgres:app"]`, map[string]string{"connection_id": mysql.ID.String()}},
+			"no connection":   {`["db:postgres:app"]`, nil},
+			"option database": {`["db:postgres:app","db-connection:` + pg.ID.String() + `"]`, map[string]string{"name": "-c"}},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				s := createDBDumpSnapshot(t, e.deps, tc.tags)
+				body := map[string]any{"agent_id": uuid.NewString()}
+				if tc.database != nil {
+					body["database"] = tc.database
+				}
+				resp := e.post(t, "/api/v1/snapshots/"+s.ID.String()+"/restore", e.adminToken(t), body)
+				assertStatus(t, resp, http.StatusBadRequest)
+			})
+		}
+	})
+
 	t.Run("returns 401 without token", func(t *testing.T) {
 		e := newTestEnv(t)
 		resp := e.post(t, "/api/v1/snapshots/00000000-0000-0000-0000-000000000001/restore",
@@ -73,6 +73,11 @@ type testDeps struct {
 	settings repositories.SettingsRepository
 	audit    repositories.AuditRepository
 	dash     repositories.DashboardRepository
+	triggers repositories.TriggerTokenRepository
+	repos    repositories.RepositoryRepository
+	keys     repositories.KeyRepository
+	imports  repositories.ImportRepository
+	conns    repositories.DatabaseConnectionRepository
 }
 
 func newTestDeps(t *testing.T) *testDeps {
@@ -93,6 +98,11 @@ func newTestDeps(t *testing.T) *testDeps {
 		settings: repositories.NewSettingsRepository(gdb),
 		audit:    repositories.NewAuditRepository(gdb),
 		dash:     repositories.NewDashboardRepository(gdb),
+		triggers: repositories.NewTriggerTokenRepository(gdb),
+		repos:    repositories.NewRepositoryRepository(gdb),
+		keys:     repositories.NewKeyRepository(gdb),
+		imports:  repositories.NewImportRepository(gdb),
+		conns:    repositories.NewDatabaseConnectionRepository(gdb),
 	}
 }
 
@@ -122,7 +132,7 @@ func newTestAuthService(t *testing.T, deps *testDeps) *auth.AuthService {
 // them (no Start() is called), so tests remain deterministic and fast.
 func newTestScheduler(t *testing.T, deps *testDeps, mgr *agentmanager.Manager) *scheduler.Scheduler {
 	t.Helper()
-	sched, err := scheduler.New(deps.policies, deps.jobs, deps.dests, mgr, zap.NewNop())
+	sched, err := scheduler.New(scheduler.Config{Repositories: deps.repos, Settings: deps.settings, Keys: deps.keys, Imports: deps.imports, DatabaseConnections: deps.conns}, deps.policies, deps.jobs, deps.dests, mgr, zap.NewNop())
 	if err != nil {
 		t.Fatalf("newTestScheduler: %v", err)
 	}
@@ -150,26 +160,31 @@ func newTestEnv(t *testing.T) *testEnv {
 	hub := websocket.NewHub()
 
 	cfg := RouterConfig{
-		AuthService:   authSvc,
-		Scheduler:     sched,
-		AgentManager:  mgr,
-		Logger:        zap.NewNop(),
-		Hub:           hub,
-		Users:         deps.users,
-		Agents:        deps.agents,
-		Destinations:  deps.dests,
-		Policies:      deps.policies,
-		Jobs:          deps.jobs,
-		Snapshots:     deps.snaps,
-		Notifications: deps.notifs,
-		OIDCProviders: deps.oidc,
-		Settings:      deps.settings,
-		Dashboard:     deps.dash,
-		Audit:         deps.audit,
-		Secure:        false,
-		AutoCerts:     nil,
-		ServerVersion: "0.0.0-test",
-		DB:            deps.sqlDB,
+		AuthService:         authSvc,
+		Scheduler:           sched,
+		AgentManager:        mgr,
+		Logger:              zap.NewNop(),
+		Hub:                 hub,
+		Users:               deps.users,
+		Agents:              deps.agents,
+		Destinations:        deps.dests,
+		Policies:            deps.policies,
+		Jobs:                deps.jobs,
+		Snapshots:           deps.snaps,
+		Notifications:       deps.notifs,
+		OIDCProviders:       deps.oidc,
+		Settings:            deps.settings,
+		Dashboard:           deps.dash,
+		Audit:               deps.audit,
+		TriggerTokens:       deps.triggers,
+		Repositories:        deps.repos,
+		Keys:                deps.keys,
+		Imports:             deps.imports,
+		DatabaseConnections: deps.conns,
+		Secure:              false,
+		AutoCerts:           nil,
+		ServerVersion:       "0.0.0-test",
+		DB:                  deps.sqlDB,
 	}
 
 	srv := httptest.NewServer(NewRouter(cfg))
@@ -9,6 +9,8 @@ import (
 	"database/sql"
 	"embed"
 	"fmt"
+	"io/fs"
+	"strings"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -111,7 +113,7 @@ func Ping(ctx context.Context, database *gorm.DB) error {
 // runMigrations applies all pending up-migrations from the embedded SQL files.
 // ErrNoChange is treated as success.
 func runMigrations(sqlDB *sql.DB, driver string, log *zap.Logger) error {
-	src, err := iofs.New(migrationsFS, "migrations")
+	src, err := iofs.New(driverFS{FS: migrationsFS, driver: driver}, "migrations")
 	if err != nil {
 		return fmt.Errorf("failed to create migration source: %w", err)
 	}
@@ -120,6 +122,28 @@ func runMigrations(sqlDB *sql.DB, driver string, log *zap.Logger) error {
 
 	switch driver {
 	case "sqlite":
+		// Some migrations rebuild a table to change a constraint, which drops
+		// the original. With foreign keys enforced (e.g. a DSN with
+		// _pragma=foreign_keys(1)) the drop would cascade into the rows of
+		// the referencing tables. golang-migrate runs each migration in a
+		// transaction, where the pragma is a no-op, so it is switched off
+		// here for the whole run. SQLite is limited to a single connection,
+		// so every migration sees the setting.
+		var fk bool
+		if err := sqlDB.QueryRow("PRAGMA foreign_keys").Scan(&fk); err != nil {
+			return fmt.Errorf("failed to read foreign_keys pragma: %w", err)
+		}
+		if fk {
+			if _, err := sqlDB.Exec("PRAGMA foreign_keys = OFF"); err != nil {
+				return fmt.Errorf("failed to disable foreign keys: %w", err)
+			}
+			defer func() {
+				if _, err := sqlDB.Exec("PRAGMA foreign_keys = ON"); err != nil {
+					log.Error("failed to re-enable foreign keys", zap.Error(err))
+				}
+			}()
+		}
+
 		drv, err := migratesqlite.WithInstance(sqlDB, &migratesqlite.Config{})
 		if err != nil {
 			return fmt.Errorf("failed to create sqlite migrate driver: %w", err)
@@ -146,4 +170,43 @@ func runMigrations(sqlDB *sql.DB, driver string, log *zap.Logger) error {
 
 	log.Info("database migrations applied successfully")
 	return nil
-}
\ No newline at end of file
+}
+
+// driverFS filters the embedded migrations directory for a single driver.
+// Most migrations are plain "<version>_<name>.<up|down>.sql" files shared by
+// both drivers. A migration that cannot be expressed portably (e.g. changing a
+// CHECK constraint, which SQLite only supports via a table rebuild) ships one
+// file per driver instead: "<version>_<name>.<up|down>.<driver>.sql". Files
+// targeting the other driver are hidden so golang-migrate sees exactly one
+// file per version and direction.
+type driverFS struct {
+	embed.FS
+	driver string
+}
+
+// ReadDir implements fs.ReadDirFS, dropping entries meant for other drivers.
+func (f driverFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := f.FS.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]fs.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		if drv := migrationDriver(e.Name()); drv == "" || drv == f.driver {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered, nil
+}
+
+// migrationDriver returns the driver suffix of a migration file name
+// ("sqlite" for "000006_x.up.sqlite.sql"), or "" for portable migrations.
+func migrationDriver(name string) string {
+	base := strings.TrimSuffix(name, ".sql")
+	for _, drv := range []string{"sqlite", "postgres"} {
+		if strings.HasSuffix(base, "."+drv) {
+			return drv
+		}
+	}
+	return ""
+}
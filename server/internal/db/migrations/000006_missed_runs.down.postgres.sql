@@ -0,0 +1,4 @@
+-- Migration: 000006_missed_runs (PostgreSQL, rollback)
+-- The status CHECK constraints are not restored: rows with statuses outside
+-- the original set would make ADD CONSTRAINT fail.
+ALTER TABLE policies DROP COLUMN catch_up;
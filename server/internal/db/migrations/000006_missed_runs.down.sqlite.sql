@@ -0,0 +1,4 @@
+-- Migration: 000006_missed_runs (SQLite, rollback)
+-- The status CHECK constraints are not restored: rows with statuses outside
+-- the original set would make the rebuild fail.
+ALTER TABLE policies DROP COLUMN catch_up;
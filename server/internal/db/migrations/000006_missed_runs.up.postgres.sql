@@ -0,0 +1,9 @@
+-- Migration: 000006_missed_runs (PostgreSQL)
+-- Adds the per-policy catch_up flag and drops the status CHECK constraints on
+-- jobs and job_destinations. See the SQLite variant of this migration for the
+-- rationale.
+
+ALTER TABLE policies ADD COLUMN catch_up BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE jobs             DROP CONSTRAINT IF EXISTS jobs_status_check;
+ALTER TABLE job_destinations DROP CONSTRAINT IF EXISTS job_destinations_status_check;
@@ -0,0 +1,5 @@
+-- Migration: 000007_policy_timezone
+-- Adds an optional IANA timezone (e.g. "Europe/Rome") in which the policy's
+-- cron expression is evaluated. An empty value keeps the previous behaviour:
+-- the schedule is evaluated in the server's local timezone.
+ALTER TABLE policies ADD COLUMN timezone TEXT NOT NULL DEFAULT '';
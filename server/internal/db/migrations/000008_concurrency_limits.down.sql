@@ -0,0 +1,2 @@
+ALTER TABLE policies DROP COLUMN start_jitter_seconds;
+ALTER TABLE destinations DROP COLUMN max_concurrent_jobs;
@@ -0,0 +1,6 @@
+-- Migration: 000008_concurrency_limits
+-- Adds a per-destination cap on concurrently running backup jobs (0 means
+-- unlimited) and a per-policy maximum start delay in seconds used to spread
+-- policies that share the same cron expression (0 disables the delay).
+ALTER TABLE destinations ADD COLUMN max_concurrent_jobs INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE policies ADD COLUMN start_jitter_seconds INTEGER NOT NULL DEFAULT 0;
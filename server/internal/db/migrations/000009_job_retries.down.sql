@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_jobs_retry_of_id;
+
+ALTER TABLE jobs DROP COLUMN not_before;
+ALTER TABLE jobs DROP COLUMN failure_class;
+ALTER TABLE jobs DROP COLUMN retry_of_id;
+ALTER TABLE jobs DROP COLUMN attempt;
+
+ALTER TABLE policies DROP COLUMN retry_on;
+ALTER TABLE policies DROP COLUMN retry_backoff_seconds;
+ALTER TABLE policies DROP COLUMN retry_max_attempts;
@@ -0,0 +1,23 @@
+-- Migration: 000009_job_retries
+-- Adds per-policy automatic retries of failed backup jobs.
+--
+-- policies: retry_max_attempts is the total number of attempts per run
+-- (1 disables retries), retry_backoff_seconds the delay before the first
+-- retry (doubled for each further attempt) and retry_on the comma-separated
+-- failure classes that are retried ("network", "hook", "wrong_password",
+-- "other").
+--
+-- jobs: attempt numbers the job within its retry chain, retry_of_id links a
+-- retry to the attempt it replaces, failure_class records why the agent
+-- reported the job as failed, and not_before holds a retry back until its
+-- backoff has elapsed.
+ALTER TABLE policies ADD COLUMN retry_max_attempts INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE policies ADD COLUMN retry_backoff_seconds INTEGER NOT NULL DEFAULT 300;
+ALTER TABLE policies ADD COLUMN retry_on TEXT NOT NULL DEFAULT 'network';
+
+ALTER TABLE jobs ADD COLUMN attempt INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE jobs ADD COLUMN retry_of_id TEXT;
+ALTER TABLE jobs ADD COLUMN failure_class TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN not_before TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_retry_of_id ON jobs (retry_of_id);
@@ -0,0 +1,3 @@
+ALTER TABLE jobs DROP COLUMN last_activity_at;
+
+ALTER TABLE policies DROP COLUMN max_runtime_seconds;
@@ -0,0 +1,12 @@
+-- Migration: 000010_job_watchdog
+-- Adds job runtime limits and the stuck-job watchdog.
+--
+-- policies: max_runtime_seconds is enforced by the agent, which kills restic
+-- and the hooks once a backup has run that long (0 = unlimited).
+--
+-- jobs: last_activity_at is refreshed on every status report and (throttled)
+-- log line from the agent. The server-side watchdog fails running jobs whose
+-- last activity is older than the configured stuck-job timeout.
+ALTER TABLE policies ADD COLUMN max_runtime_seconds INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE jobs ADD COLUMN last_activity_at TIMESTAMP;
@@ -0,0 +1,5 @@
+ALTER TABLE jobs DROP COLUMN triggered_by_id;
+
+DROP INDEX IF EXISTS idx_policy_dependencies_depends_on_id;
+DROP INDEX IF EXISTS idx_policy_dependencies_policy_depends_on;
+DROP TABLE IF EXISTS policy_dependencies;
@@ -0,0 +1,26 @@
+-- Migration: 000011_policy_dependencies
+-- Adds "run after" dependencies between policies.
+--
+-- policy_dependencies: policy_id runs after depends_on_id finishes, provided
+-- the upstream outcome matches condition ("success", "failure", "always").
+-- Policies with dependencies may have an empty schedule, in which case they
+-- only run when triggered by a dependency or manually.
+--
+-- jobs: triggered_by_id links a job started by a dependency to the upstream
+-- job whose outcome triggered it.
+CREATE TABLE IF NOT EXISTS policy_dependencies (
+    id              TEXT        NOT NULL PRIMARY KEY,
+    created_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    policy_id       TEXT        NOT NULL,
+    depends_on_id   TEXT        NOT NULL,
+    condition       TEXT        NOT NULL DEFAULT 'success',
+
+    CONSTRAINT fk_policy_dependencies_policy     FOREIGN KEY (policy_id)     REFERENCES policies (id) ON DELETE CASCADE,
+    CONSTRAINT fk_policy_dependencies_depends_on FOREIGN KEY (depends_on_id) REFERENCES policies (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_policy_dependencies_policy_depends_on ON policy_dependencies (policy_id, depends_on_id);
+CREATE INDEX IF NOT EXISTS idx_policy_dependencies_depends_on_id ON policy_dependencies (depends_on_id);
+
+ALTER TABLE jobs ADD COLUMN triggered_by_id TEXT;
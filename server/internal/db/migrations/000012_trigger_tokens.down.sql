@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_trigger_tokens_hash;
+DROP INDEX IF EXISTS idx_trigger_tokens_policy_id;
+DROP TABLE IF EXISTS trigger_tokens;
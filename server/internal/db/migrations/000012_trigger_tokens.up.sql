@@ -0,0 +1,27 @@
+-- Migration: 000012_trigger_tokens
+-- Adds per-policy trigger tokens for inbound webhooks.
+--
+-- trigger_tokens: a secret URL (POST /api/v1/hooks/trigger/{token}) that
+-- starts a backup of policy_id without a user session, e.g. from a CI
+-- pipeline. Only the SHA-256 hash of the token is stored; token_prefix keeps
+-- the first characters for identification in the UI. signing_secret is
+-- encrypted at rest and, when set, requests must carry an HMAC-SHA256
+-- signature of the body. Revoked tokens are kept for the audit trail.
+CREATE TABLE IF NOT EXISTS trigger_tokens (
+    id              TEXT        NOT NULL PRIMARY KEY,
+    created_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    policy_id       TEXT        NOT NULL,
+    name            TEXT        NOT NULL,
+    token_hash      TEXT        NOT NULL,
+    token_prefix    TEXT        NOT NULL DEFAULT '',
+    signing_secret  TEXT        NOT NULL DEFAULT '',
+    created_by      TEXT        NOT NULL,
+    last_used_at    TIMESTAMP,
+    revoked_at      TIMESTAMP,
+
+    CONSTRAINT fk_trigger_tokens_policy FOREIGN KEY (policy_id) REFERENCES policies (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_trigger_tokens_policy_id ON trigger_tokens (policy_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trigger_tokens_hash ON trigger_tokens (token_hash);
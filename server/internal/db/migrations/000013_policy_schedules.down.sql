@@ -0,0 +1,4 @@
+ALTER TABLE jobs DROP COLUMN schedule_id;
+
+DROP INDEX IF EXISTS idx_policy_schedules_policy_id;
+DROP TABLE IF EXISTS policy_schedules;
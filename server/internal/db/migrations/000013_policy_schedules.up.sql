@@ -0,0 +1,32 @@
+-- Migration: 000013_policy_schedules
+-- Adds additional schedule entries to policies.
+--
+-- policy_schedules: an extra cron schedule of policy_id, evaluated in the
+-- policy's timezone. Snapshots taken by the entry get the extra tags (comma
+-- separated, e.g. "schedule:hourly"). When custom_retention is set, the
+-- retention_* columns replace the policy's retention for those snapshots and
+-- forget is scoped to the entry's tags; the policy's own forget then keeps
+-- them untouched.
+--
+-- jobs: schedule_id is the entry that started the job, NULL for runs of the
+-- policy's own schedule, manual triggers and dependency runs.
+CREATE TABLE IF NOT EXISTS policy_schedules (
+    id                  TEXT        NOT NULL PRIMARY KEY,
+    created_at          TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    policy_id           TEXT        NOT NULL,
+    schedule            TEXT        NOT NULL,
+    tags                TEXT        NOT NULL DEFAULT '',
+    custom_retention    BOOLEAN     NOT NULL DEFAULT FALSE,
+    retention_hourly    INTEGER     NOT NULL DEFAULT 0,
+    retention_daily     INTEGER     NOT NULL DEFAULT 0,
+    retention_weekly    INTEGER     NOT NULL DEFAULT 0,
+    retention_monthly   INTEGER     NOT NULL DEFAULT 0,
+    retention_yearly    INTEGER     NOT NULL DEFAULT 0,
+
+    CONSTRAINT fk_policy_schedules_policy FOREIGN KEY (policy_id) REFERENCES policies (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_schedules_policy_id ON policy_schedules (policy_id);
+
+ALTER TABLE jobs ADD COLUMN schedule_id TEXT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_dry_run_results_job_id;
+DROP TABLE IF EXISTS dry_run_results;
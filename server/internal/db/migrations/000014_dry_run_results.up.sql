@@ -0,0 +1,23 @@
+-- Migration: 000014_dry_run_results
+-- Adds the results of backup dry runs.
+--
+-- dry_run_results: the size estimate reported by the agent for a job of type
+-- 'dry_run' (one row per job). largest_directories and unreadable_paths are
+-- JSON arrays; unreadable_paths is truncated by the agent, unreadable_count
+-- is the full count.
+CREATE TABLE IF NOT EXISTS dry_run_results (
+    id                   TEXT        NOT NULL PRIMARY KEY,
+    created_at           TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at           TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    job_id               TEXT        NOT NULL,
+    total_files          BIGINT      NOT NULL DEFAULT 0,
+    total_bytes          BIGINT      NOT NULL DEFAULT 0,
+    data_added           BIGINT      NOT NULL DEFAULT 0,
+    largest_directories  TEXT        NOT NULL DEFAULT '[]',
+    unreadable_paths     TEXT        NOT NULL DEFAULT '[]',
+    unreadable_count     BIGINT      NOT NULL DEFAULT 0,
+
+    CONSTRAINT fk_dry_run_results_job FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dry_run_results_job_id ON dry_run_results (job_id);
@@ -0,0 +1,10 @@
+ALTER TABLE job_destinations DROP COLUMN anomaly_reason;
+ALTER TABLE job_destinations DROP COLUMN anomaly;
+ALTER TABLE job_destinations DROP COLUMN data_added;
+ALTER TABLE job_destinations DROP COLUMN total_files;
+ALTER TABLE job_destinations DROP COLUMN files_unmodified;
+ALTER TABLE job_destinations DROP COLUMN files_changed;
+ALTER TABLE job_destinations DROP COLUMN files_new;
+
+ALTER TABLE policies DROP COLUMN anomaly_hold;
+ALTER TABLE policies DROP COLUMN anomaly_detection;
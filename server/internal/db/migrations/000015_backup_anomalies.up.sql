@@ -0,0 +1,21 @@
+-- Migration: 000015_backup_anomalies
+-- Adds per-run change statistics and anomaly detection on backup change rates.
+--
+-- policies: anomaly_detection compares every backup run with the recent runs
+-- of the same policy and destination and flags sudden change spikes (the
+-- signature of ransomware encrypting the sources). anomaly_hold additionally
+-- tags the last known-good snapshot so that retention cannot remove it.
+--
+-- job_destinations: the change statistics from the restic summary of the run
+-- (total_files is the number of files processed), and whether the run was
+-- flagged as anomalous and why.
+ALTER TABLE policies ADD COLUMN anomaly_detection BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE policies ADD COLUMN anomaly_hold BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE job_destinations ADD COLUMN files_new BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE job_destinations ADD COLUMN files_changed BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE job_destinations ADD COLUMN files_unmodified BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE job_destinations ADD COLUMN total_files BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE job_destinations ADD COLUMN data_added BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE job_destinations ADD COLUMN anomaly BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE job_destinations ADD COLUMN anomaly_reason TEXT NOT NULL DEFAULT '';
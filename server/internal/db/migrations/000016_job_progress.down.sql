@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_job_progress_job_id;
+DROP TABLE IF EXISTS job_progress;
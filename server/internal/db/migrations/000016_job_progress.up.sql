@@ -0,0 +1,26 @@
+-- Migration: 000016_job_progress
+-- Adds the latest progress snapshot of running jobs.
+--
+-- job_progress: one row per job, overwritten by every (throttled) progress
+-- report from the agent. Progress used to be streamed as JSON log lines;
+-- only the latest snapshot is worth keeping. destination_id is empty for
+-- jobs without destinations (dry runs).
+CREATE TABLE IF NOT EXISTS job_progress (
+    id                 TEXT              NOT NULL PRIMARY KEY,
+    created_at         TIMESTAMP         NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at         TIMESTAMP         NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    job_id             TEXT              NOT NULL,
+    destination_id     TEXT              NOT NULL DEFAULT '',
+    percent_done       DOUBLE PRECISION  NOT NULL DEFAULT 0,
+    files_done         BIGINT            NOT NULL DEFAULT 0,
+    total_files        BIGINT            NOT NULL DEFAULT 0,
+    bytes_done         BIGINT            NOT NULL DEFAULT 0,
+    total_bytes        BIGINT            NOT NULL DEFAULT 0,
+    current_file       TEXT              NOT NULL DEFAULT '',
+    seconds_remaining  BIGINT            NOT NULL DEFAULT 0,
+    seconds_elapsed    BIGINT            NOT NULL DEFAULT 0,
+
+    CONSTRAINT fk_job_progress_job FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_job_progress_job_id ON job_progress (job_id);
@@ -0,0 +1,4 @@
+ALTER TABLE policies DROP COLUMN warnings_as_failure;
+
+ALTER TABLE job_destinations DROP COLUMN file_error_count;
+ALTER TABLE job_destinations DROP COLUMN file_errors;
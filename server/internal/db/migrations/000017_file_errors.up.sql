@@ -0,0 +1,15 @@
+-- Migration: 000017_file_errors
+-- Records the files restic could not read in otherwise successful backups.
+--
+-- restic exits with code 3 when it created the snapshot but skipped some
+-- source files; the job destination is then "succeeded_with_warnings".
+--
+-- job_destinations: file_errors is a JSON array of {path, during, message},
+-- truncated by the agent; file_error_count is the full count.
+--
+-- policies: warnings_as_failure notifies runs with warnings as failures
+-- instead of successes.
+ALTER TABLE job_destinations ADD COLUMN file_errors TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE job_destinations ADD COLUMN file_error_count BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE policies ADD COLUMN warnings_as_failure BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+ALTER TABLE policies DROP COLUMN fan_out_parallelism;
+ALTER TABLE policies DROP COLUMN fan_out;
@@ -0,0 +1,9 @@
+-- Migration: 000018_fan_out
+-- Adds the fan-out mode of policies with several destinations.
+--
+-- fan_out is "sequential" (one destination after the other, in priority
+-- order), "parallel" (concurrent backups, at most fan_out_parallelism at a
+-- time, 0 = all) or "replicate" (back up to the highest-priority
+-- destination, then restic copy the snapshot to the others).
+ALTER TABLE policies ADD COLUMN fan_out TEXT NOT NULL DEFAULT 'sequential';
+ALTER TABLE policies ADD COLUMN fan_out_parallelism INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_snapshots_original_id;
+ALTER TABLE snapshots DROP COLUMN original_id;
+
+ALTER TABLE policies DROP COLUMN replicate_policy_id;
+ALTER TABLE policies DROP COLUMN replicate_host;
+ALTER TABLE policies DROP COLUMN replicate_tag;
+ALTER TABLE policies DROP COLUMN replicate_from_id;
+ALTER TABLE policies DROP COLUMN type;
@@ -0,0 +1,18 @@
+-- Migration: 000019_replication
+-- Adds replication policies, which copy the snapshots of a source
+-- destination to the policy's destinations with restic copy.
+--
+-- policies: type is "backup" or "replication". A replication policy copies
+-- the snapshots of replicate_from_id, optionally only those with
+-- replicate_tag, taken on replicate_host or by replicate_policy_id.
+--
+-- snapshots: original_id links a copied snapshot to the catalog record of
+-- the snapshot it was copied from.
+ALTER TABLE policies ADD COLUMN type TEXT NOT NULL DEFAULT 'backup';
+ALTER TABLE policies ADD COLUMN replicate_from_id TEXT;
+ALTER TABLE policies ADD COLUMN replicate_tag TEXT NOT NULL DEFAULT '';
+ALTER TABLE policies ADD COLUMN replicate_host TEXT NOT NULL DEFAULT '';
+ALTER TABLE policies ADD COLUMN replicate_policy_id TEXT;
+
+ALTER TABLE snapshots ADD COLUMN original_id TEXT;
+CREATE INDEX IF NOT EXISTS idx_snapshots_original_id ON snapshots (original_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_policy_destinations_repository_id;
+ALTER TABLE policy_destinations DROP COLUMN repository_id;
+
+DROP INDEX IF EXISTS idx_repositories_destination_path;
+DROP TABLE IF EXISTS repositories;
@@ -0,0 +1,31 @@
+-- Migration: 000020_repositories
+-- Adds restic repositories as entities of their own.
+--
+-- repositories: a restic repository at path below the root of
+-- destination_id, opened with password (encrypted at rest). Policies of any
+-- agent may write into the same repository to share deduplication. When
+-- chunker_from_id is set the repository is initialised with the chunker
+-- parameters of that repository, so that snapshots copied between the two
+-- deduplicate. initialized_at is set once a job has written to it.
+--
+-- policy_destinations: repository_id is the repository the policy writes
+-- into on that destination. NULL keeps the previous behaviour: the
+-- repository at the destination root, opened with the policy's password.
+CREATE TABLE IF NOT EXISTS repositories (
+    id              TEXT        NOT NULL PRIMARY KEY,
+    created_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    name            TEXT        NOT NULL,
+    destination_id  TEXT        NOT NULL,
+    path            TEXT        NOT NULL DEFAULT '',
+    password        TEXT        NOT NULL,
+    chunker_from_id TEXT,
+    initialized_at  TIMESTAMP,
+
+    CONSTRAINT fk_repositories_destination FOREIGN KEY (destination_id) REFERENCES destinations (id) ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_repositories_destination_path ON repositories (destination_id, path);
+
+ALTER TABLE policy_destinations ADD COLUMN repository_id TEXT;
+CREATE INDEX IF NOT EXISTS idx_policy_destinations_repository_id ON policy_destinations (repository_id);
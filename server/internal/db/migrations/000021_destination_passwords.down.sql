@@ -0,0 +1 @@
+ALTER TABLE policy_destinations DROP COLUMN repo_password;
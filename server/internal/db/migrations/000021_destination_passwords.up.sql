@@ -0,0 +1,9 @@
+-- Migration: 000021_destination_passwords
+-- Adds a repository password per policy destination.
+--
+-- policy_destinations: repo_password (encrypted at rest) opens the
+-- policy's repository on that destination, so that a compromised
+-- destination does not expose the key of the others. Existing rows keep an
+-- empty value, which falls back to the policy's repo_password their
+-- repositories were created with.
+ALTER TABLE policy_destinations ADD COLUMN repo_password TEXT NOT NULL DEFAULT '';
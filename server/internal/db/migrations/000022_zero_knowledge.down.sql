@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_key_shares_key_to_agent;
+DROP INDEX IF EXISTS idx_key_shares_from_agent_status;
+DROP INDEX IF EXISTS idx_key_shares_policy_id;
+DROP TABLE IF EXISTS key_shares;
+
+DROP INDEX IF EXISTS idx_key_escrow_kits_agent_id;
+DROP TABLE IF EXISTS key_escrow_kits;
+
+ALTER TABLE agents DROP COLUMN key_share_public_key;
+ALTER TABLE policy_destinations DROP COLUMN key_fingerprint;
+ALTER TABLE policies DROP COLUMN zero_knowledge;
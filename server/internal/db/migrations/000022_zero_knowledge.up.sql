@@ -0,0 +1,61 @@
+-- Migration: 000022_zero_knowledge
+-- Adds zero-knowledge policies, whose repository keys never leave the
+-- agents.
+--
+-- policies: zero_knowledge makes the agent generate and keep the repository
+-- key of each destination in its state dir; the server stores no password.
+--
+-- policy_destinations: key_fingerprint is the fingerprint the agent reported
+-- for the key of a zero-knowledge policy, empty until the first backup.
+--
+-- agents: key_share_public_key is the X25519 public key the agent announces
+-- at registration, which other agents seal shared keys to.
+--
+-- key_escrow_kits: the latest escrow kit of each agent, sealed by the agent
+-- to the admin's escrow public key. The server cannot open it.
+--
+-- key_shares: an admin-approved hand-over of the key of policy_id on
+-- destination_id to to_agent_id, for restores on another agent. The
+-- policy's agent seals the key to recipient_public_key; status goes from
+-- "pending" to "sealed" or "failed".
+ALTER TABLE policies ADD COLUMN zero_knowledge BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE policy_destinations ADD COLUMN key_fingerprint TEXT NOT NULL DEFAULT '';
+ALTER TABLE agents ADD COLUMN key_share_public_key TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS key_escrow_kits (
+    id                     TEXT        NOT NULL PRIMARY KEY,
+    created_at             TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at             TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    agent_id               TEXT        NOT NULL,
+    escrow_key_fingerprint TEXT        NOT NULL,
+    kit                    TEXT        NOT NULL,
+    key_count              INTEGER     NOT NULL DEFAULT 0,
+
+    CONSTRAINT fk_key_escrow_kits_agent FOREIGN KEY (agent_id) REFERENCES agents (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_key_escrow_kits_agent_id ON key_escrow_kits (agent_id);
+
+CREATE TABLE IF NOT EXISTS key_shares (
+    id                   TEXT        NOT NULL PRIMARY KEY,
+    created_at           TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at           TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    policy_id            TEXT        NOT NULL,
+    destination_id       TEXT        NOT NULL,
+    key_id               TEXT        NOT NULL,
+    from_agent_id        TEXT        NOT NULL,
+    to_agent_id          TEXT        NOT NULL,
+    recipient_public_key TEXT        NOT NULL,
+    key_fingerprint      TEXT        NOT NULL,
+    status               TEXT        NOT NULL DEFAULT 'pending',
+    sealed_key           TEXT        NOT NULL DEFAULT '',
+    error                TEXT        NOT NULL DEFAULT '',
+    requested_by         TEXT        NOT NULL,
+
+    CONSTRAINT fk_key_shares_policy FOREIGN KEY (policy_id) REFERENCES policies (id) ON DELETE CASCADE,
+    CONSTRAINT fk_key_shares_to_agent FOREIGN KEY (to_agent_id) REFERENCES agents (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_key_shares_policy_id ON key_shares (policy_id);
+CREATE INDEX IF NOT EXISTS idx_key_shares_from_agent_status ON key_shares (from_agent_id, status);
+CREATE INDEX IF NOT EXISTS idx_key_shares_key_to_agent ON key_shares (key_id, to_agent_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_repository_imports_agent_status;
+DROP INDEX IF EXISTS idx_repository_imports_repository_id;
+DROP TABLE IF EXISTS repository_imports;
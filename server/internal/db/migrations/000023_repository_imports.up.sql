@@ -0,0 +1,29 @@
+-- Migration: 000023_repository_imports
+-- Adds the adoption of restic repositories created outside Arkeep.
+--
+-- repository_imports: a scan of repository_id by agent_id, requested by an
+-- admin. The agent lists the repository's snapshots; snapshots holds them
+-- as a JSON array once status is "scanned". Applying the import creates the
+-- missing agents, draft policies and snapshot records, and sets status to
+-- "applied". A scan the agent could not run is "failed", with error set.
+--
+-- jobs.type gains "import": the job an applied import records the adopted
+-- snapshots of a policy under.
+CREATE TABLE IF NOT EXISTS repository_imports (
+    id            TEXT        NOT NULL PRIMARY KEY,
+    created_at    TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    repository_id TEXT        NOT NULL,
+    agent_id      TEXT        NOT NULL,
+    status        TEXT        NOT NULL DEFAULT 'scanning',
+    error         TEXT        NOT NULL DEFAULT '',
+    snapshots     TEXT        NOT NULL DEFAULT '[]',
+    applied_at    TIMESTAMP,
+    requested_by  TEXT        NOT NULL,
+
+    CONSTRAINT fk_repository_imports_repository FOREIGN KEY (repository_id) REFERENCES repositories (id) ON DELETE CASCADE,
+    CONSTRAINT fk_repository_imports_agent FOREIGN KEY (agent_id) REFERENCES agents (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_repository_imports_repository_id ON repository_imports (repository_id);
+CREATE INDEX IF NOT EXISTS idx_repository_imports_agent_status ON repository_imports (agent_id, status);
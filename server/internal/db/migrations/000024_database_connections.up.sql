@@ -0,0 +1,20 @@
+-- Migration: 000024_database_connections
+-- Adds the connections of database dump sources.
+--
+-- database_connections: how an agent reaches a database server to dump it:
+-- engine is one of "postgres", "mysql", "mongodb", "redis" or "sqlite";
+-- host and port are empty/0 for the tool's default (e.g. the local socket)
+-- and password is encrypted at rest, like destination credentials. Policies
+-- reference a connection from their sources JSON ({"type": "database",
+-- "connection_id": ..., "database": ...}).
+CREATE TABLE IF NOT EXISTS database_connections (
+    id         TEXT        NOT NULL PRIMARY KEY,
+    created_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    name       TEXT        NOT NULL,
+    engine     TEXT        NOT NULL,
+    host       TEXT        NOT NULL DEFAULT '',
+    port       INTEGER     NOT NULL DEFAULT 0,
+    username   TEXT        NOT NULL DEFAULT '',
+    password   TEXT        NOT NULL DEFAULT ''
+);
@@ -0,0 +1,9 @@
+ALTER TABLE policies ADD COLUMN hook_pre_backup TEXT NOT NULL DEFAULT '';
+ALTER TABLE policies ADD COLUMN hook_post_backup TEXT NOT NULL DEFAULT '';
+
+-- Only the first step of each backup phase survives.
+UPDATE policies SET
+    hook_pre_backup = COALESCE(json_extract(hooks, '$.pre_backup[0]'), ''),
+    hook_post_backup = COALESCE(json_extract(hooks, '$.post_backup[0]'), '');
+
+ALTER TABLE policies DROP COLUMN hooks;
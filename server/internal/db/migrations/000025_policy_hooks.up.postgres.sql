@@ -0,0 +1,32 @@
+-- Migration: 000025_policy_hooks (PostgreSQL)
+-- Replaces the single pre-backup and post-backup hooks of policies with
+-- ordered lists of hook steps per phase.
+--
+-- policies: hooks is a JSON object mapping each phase (pre_backup,
+-- post_backup, on_success, on_failure, pre_restore, post_restore) to its
+-- steps. The old hook_pre_backup and hook_post_backup columns held either a
+-- JSON step written by the GUI ({"name","command","args","timeout_secs"},
+-- the fields of a step) or a plain command written through the API; each
+-- becomes the only step of its phase. A plain command is never a JSON
+-- object with a "command" key, which is how GUI steps are told apart.
+-- Post-backup hook failures used to be logged only, so the post-backup step
+-- continues on error.
+
+ALTER TABLE policies ADD COLUMN hooks TEXT NOT NULL DEFAULT '{}';
+
+UPDATE policies SET hooks = jsonb_build_object(
+    'pre_backup', CASE
+        WHEN COALESCE(hook_pre_backup, '') = '' THEN '[]'::jsonb
+        WHEN hook_pre_backup ~ '^\s*\{.*"command".*\}\s*$' THEN jsonb_build_array(hook_pre_backup::jsonb)
+        ELSE jsonb_build_array(jsonb_build_object('command', hook_pre_backup))
+    END,
+    'post_backup', CASE
+        WHEN COALESCE(hook_post_backup, '') = '' THEN '[]'::jsonb
+        WHEN hook_post_backup ~ '^\s*\{.*"command".*\}\s*$' THEN jsonb_build_array(hook_post_backup::jsonb || '{"continue_on_error": true}'::jsonb)
+        ELSE jsonb_build_array(jsonb_build_object('command', hook_post_backup, 'continue_on_error', true))
+    END
+)::text
+WHERE COALESCE(hook_pre_backup, '') <> '' OR COALESCE(hook_post_backup, '') <> '';
+
+ALTER TABLE policies DROP COLUMN hook_pre_backup;
+ALTER TABLE policies DROP COLUMN hook_post_backup;
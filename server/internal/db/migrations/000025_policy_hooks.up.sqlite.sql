@@ -0,0 +1,30 @@
+-- Migration: 000025_policy_hooks (SQLite)
+-- Replaces the single pre-backup and post-backup hooks of policies with
+-- ordered lists of hook steps per phase.
+--
+-- policies: hooks is a JSON object mapping each phase (pre_backup,
+-- post_backup, on_success, on_failure, pre_restore, post_restore) to its
+-- steps. The old hook_pre_backup and hook_post_backup columns held either a
+-- JSON step written by the GUI ({"name","command","args","timeout_secs"},
+-- the fields of a step) or a plain command written through the API; each
+-- becomes the only step of its phase. Post-backup hook failures used to be
+-- logged only, so the post-backup step continues on error.
+
+ALTER TABLE policies ADD COLUMN hooks TEXT NOT NULL DEFAULT '{}';
+
+UPDATE policies SET hooks = json_object(
+    'pre_backup', CASE
+        WHEN COALESCE(hook_pre_backup, '') = '' THEN json_array()
+        WHEN json_valid(hook_pre_backup) AND substr(ltrim(hook_pre_backup), 1, 1) = '{' THEN json_array(json(hook_pre_backup))
+        ELSE json_array(json_object('command', hook_pre_backup))
+    END,
+    'post_backup', CASE
+        WHEN COALESCE(hook_post_backup, '') = '' THEN json_array()
+        WHEN json_valid(hook_post_backup) AND substr(ltrim(hook_post_backup), 1, 1) = '{' THEN json_array(json_set(hook_post_backup, '$.continue_on_error', json('true')))
+        ELSE json_array(json_object('command', hook_post_backup, 'continue_on_error', json('true')))
+    END
+)
+WHERE COALESCE(hook_pre_backup, '') <> '' OR COALESCE(hook_post_backup, '') <> '';
+
+ALTER TABLE policies DROP COLUMN hook_pre_backup;
+ALTER TABLE policies DROP COLUMN hook_post_backup;
@@ -111,6 +111,10 @@ type Agent struct {
 	// Advertised by the agent in the Register RPC via AgentCapabilities.docker.
 	// Used by the GUI to show or hide the Docker volume source option in the policy form.
 	DockerAvailable bool `gorm:"not null;default:false"`
+	// KeySharePublicKey is the X25519 public key (base64) the agent
+	// announces in the Register RPC. Keys of zero-knowledge policies are
+	// sealed to it when they are shared with this agent.
+	KeySharePublicKey string `gorm:"not null;default:''"`
 }
 
 // -----------------------------------------------------------------------------
@@ -127,6 +131,59 @@ type Destination struct {
 	Credentials EncryptedString `gorm:"type:text"` // JSON, encrypted
 	Config      string          `gorm:"type:text;default:'{}'"` // JSON, not sensitive
 	Enabled     bool            `gorm:"not null;default:true"`
+	// MaxConcurrentJobs caps how many backup jobs may write to this
+	// destination at once; further jobs wait in the scheduler queue.
+	// 0 means unlimited.
+	MaxConcurrentJobs int `gorm:"not null;default:0"`
+}
+
+// Repository is a restic repository at Path below the root of a
+// destination, opened with Password. Policies write into it through
+// PolicyDestination.RepositoryID; policies of different agents may share one
+// repository to deduplicate across hosts. When ChunkerFromID is set, the
+// repository is initialised with the chunker parameters of that repository
+// so that snapshots copied between the two deduplicate. InitializedAt is set
+// once a job has written to the repository.
+type Repository struct {
+	Base
+	Name          string          `gorm:"not null"`
+	DestinationID uuid.UUID       `gorm:"type:text;not null;uniqueIndex:idx_repositories_destination_path"`
+	Path          string          `gorm:"not null;default:'';uniqueIndex:idx_repositories_destination_path"` // below the destination root, empty = the root
+	Password      EncryptedString `gorm:"type:text;not null"` // Restic repository password
+	ChunkerFromID *uuid.UUID      `gorm:"type:text"`
+	InitializedAt *time.Time
+}
+
+// DatabaseConnection is how an agent reaches a database server to dump the
+// databases of a policy's database sources. Engine is one of "postgres",
+// "mysql", "mongodb", "redis" or "sqlite"; sqlite connections only carry the
+// engine, the source names the database file. Empty Host and zero Port use
+// the dump tool's defaults.
+type DatabaseConnection struct {
+	Base
+	Name     string          `gorm:"not null"`
+	Engine   string          `gorm:"not null"`
+	Host     string          `gorm:"not null;default:''"`
+	Port     int             `gorm:"not null;default:0"`
+	Username string          `gorm:"not null;default:''"`
+	Password EncryptedString `gorm:"type:text;not null;default:''"`
+}
+
+// RepositoryImport adopts a restic repository created outside Arkeep. An
+// admin asks AgentID to scan the repository; the agent reports its
+// snapshots, stored in Snapshots as a JSON array. Applying the import maps
+// each host to an agent, creates a draft policy per host and set of paths,
+// and records the snapshots in the catalog. Status is "scanning",
+// "scanned", "failed", "applying" while an apply runs, or "applied".
+type RepositoryImport struct {
+	Base
+	RepositoryID uuid.UUID `gorm:"type:text;not null;index"`
+	AgentID      uuid.UUID `gorm:"type:text;not null"`
+	Status       string    `gorm:"not null;default:'scanning'"`
+	Error        string    `gorm:"type:text;not null;default:''"`
+	Snapshots    string    `gorm:"type:text;not null;default:'[]'"`
+	AppliedAt    *time.Time
+	RequestedBy  uuid.UUID `gorm:"type:text;not null"`
 }
 
 // -----------------------------------------------------------------------------
@@ -145,16 +202,68 @@ type Policy struct {
 	SoftDelete
 	Name             string          `gorm:"not null"`
 	AgentID          uuid.UUID       `gorm:"type:text;not null;index"`
-	Schedule         string          `gorm:"not null"` // cron expression
+	Schedule         string          `gorm:"not null"` // cron expression; empty = only run via schedule entries or dependencies
+	Timezone         string          `gorm:"not null;default:''"` // IANA zone for Schedule; empty = server local time
 	Enabled          bool            `gorm:"not null;default:true"`
 	Sources          string          `gorm:"type:text;not null"` // JSON array of source paths
 	RetentionDaily   int             `gorm:"not null;default:7"`
 	RetentionWeekly  int             `gorm:"not null;default:4"`
 	RetentionMonthly int             `gorm:"not null;default:6"`
 	RetentionYearly  int             `gorm:"not null;default:1"`
-	RepoPassword     EncryptedString `gorm:"type:text;not null"` // Restic repository password
-	HookPreBackup    string          `gorm:"type:text;default:''"` // shell command, optional
-	HookPostBackup   string          `gorm:"type:text;default:''"` // shell command, optional
+	RepoPassword     EncryptedString `gorm:"type:text;not null"` // Restic repository password of destinations without their own
+	Hooks            string          `gorm:"type:text;not null;default:'{}'"` // JSON object of hook phase → ordered steps, see scheduler.Hooks
+	// CatchUp controls what happens to a scheduled run that was missed while
+	// the server was down: true runs it once at startup, false records a
+	// "missed" job and notifies.
+	CatchUp          bool            `gorm:"not null;default:false"`
+	// StartJitterSeconds is the upper bound of a delay added to every
+	// scheduled run. The actual delay is derived from the policy ID, so it is
+	// stable across runs and restarts. 0 disables the delay.
+	StartJitterSeconds int           `gorm:"not null;default:0"`
+	// Retry settings for failed backup runs. RetryMaxAttempts is the total
+	// number of attempts per run (1 = no retries); the n-th retry waits
+	// RetryBackoffSeconds * 2^(n-1). RetryOn is a comma-separated list of
+	// the failure classes that are retried: "network", "hook",
+	// "wrong_password", "other".
+	RetryMaxAttempts    int    `gorm:"not null;default:1"`
+	RetryBackoffSeconds int    `gorm:"not null;default:300"`
+	RetryOn             string `gorm:"not null;default:'network'"`
+	// MaxRuntimeSeconds bounds how long a backup may run before the agent
+	// kills restic and the hooks and fails the job. 0 means no limit.
+	MaxRuntimeSeconds int `gorm:"not null;default:0"`
+	// AnomalyDetection flags backup runs whose share of new and changed
+	// files spikes compared with the recent runs of the policy, the typical
+	// signature of ransomware encrypting the sources. AnomalyHold also tags
+	// the last known-good snapshot with "arkeep:hold" so that retention
+	// never removes it.
+	AnomalyDetection bool `gorm:"not null;default:true"`
+	AnomalyHold      bool `gorm:"not null;default:false"`
+	// WarningsAsFailure notifies backups that succeeded with warnings
+	// (unreadable source files) as failures rather than successes.
+	WarningsAsFailure bool `gorm:"not null;default:false"`
+	// FanOut is how a backup reaches the policy's destinations:
+	// "sequential", "parallel" (at most FanOutParallelism at a time, 0 =
+	// all) or "replicate" (backed up once to the highest-priority
+	// destination, then copied to the others with restic copy).
+	FanOut            string `gorm:"not null;default:'sequential'"`
+	FanOutParallelism int    `gorm:"not null;default:0"`
+	// Type is "backup" or "replication". A replication policy has no
+	// sources: its jobs copy the snapshots of the ReplicateFromID
+	// destination to the policy's destinations with restic copy, only
+	// those with ReplicateTag, taken on ReplicateHost or by
+	// ReplicatePolicyID when set. RepoPassword opens both repositories; it
+	// may be empty with ReplicatePolicyID, whose password is then used.
+	Type              string     `gorm:"not null;default:'backup'"`
+	ReplicateFromID   *uuid.UUID `gorm:"type:text"`
+	ReplicateTag      string     `gorm:"not null;default:''"`
+	ReplicateHost     string     `gorm:"not null;default:''"`
+	ReplicatePolicyID *uuid.UUID `gorm:"type:text"`
+	// ZeroKnowledge keeps the repository passwords away from the server:
+	// the agent generates the key of each destination on the first backup
+	// and keeps it in its state dir. The server only records the key
+	// fingerprints (PolicyDestination.KeyFingerprint); RepoPassword stays
+	// empty. Set at creation only.
+	ZeroKnowledge bool `gorm:"not null;default:false"`
 	LastRunAt        *time.Time
 	NextRunAt        *time.Time
 
@@ -165,13 +274,109 @@ type Policy struct {
 }
 
 // PolicyDestination is the join table between Policy and Destination.
-// Priority determines the order in which destinations are tried (lower = first).
+// Priority determines the order in which destinations are backed up (lower =
+// first); with fan-out "replicate" the first one is backed up and the others
+// receive copies of its snapshot.
 // This enables 3-2-1 backup rules with multiple destinations per policy.
+// RepositoryID is the repository the policy writes into on the destination;
+// nil means the repository at the destination root, opened with
+// RepoPassword, or the policy's RepoPassword when that is empty.
+// KeyFingerprint is the fingerprint of the agent-held key of a
+// zero-knowledge policy, empty until the agent created the key.
 type PolicyDestination struct {
 	Base
-	PolicyID      uuid.UUID `gorm:"type:text;not null;index"`
-	DestinationID uuid.UUID `gorm:"type:text;not null;index"`
-	Priority      int       `gorm:"not null;default:0"`
+	PolicyID      uuid.UUID       `gorm:"type:text;not null;index"`
+	DestinationID uuid.UUID       `gorm:"type:text;not null;index"`
+	Priority      int             `gorm:"not null;default:0"`
+	RepositoryID  *uuid.UUID      `gorm:"type:text;index"`
+	RepoPassword  EncryptedString `gorm:"type:text;not null;default:''"` // empty = the policy's
+	KeyFingerprint string          `gorm:"not null;default:''"`
+}
+
+// KeyID names the agent-held key of a zero-knowledge policy on the
+// destination: "<policy ID>/<destination ID>".
+func (pd *PolicyDestination) KeyID() string {
+	return pd.PolicyID.String() + "/" + pd.DestinationID.String()
+}
+
+// KeyEscrowKit is the latest escrow kit of an agent: every repository key
+// of its zero-knowledge policies, sealed by the agent to the admin's escrow
+// public key (see keyseal). Kit is base64-encoded; the server cannot open
+// it.
+type KeyEscrowKit struct {
+	Base
+	AgentID              uuid.UUID `gorm:"type:text;not null;uniqueIndex"`
+	EscrowKeyFingerprint string    `gorm:"not null"`
+	Kit                  string    `gorm:"type:text;not null"`
+	KeyCount             int       `gorm:"not null;default:0"`
+}
+
+// KeyShare hands the key of a zero-knowledge policy on one destination over
+// to another agent, so that it can restore the policy's snapshots. An admin
+// requests the share; the policy's agent (FromAgentID) seals the key to the
+// RecipientPublicKey of ToAgentID, which the server relays in the restore
+// payload. Status is "pending", "sealed" or "failed". SealedKey is
+// base64-encoded.
+type KeyShare struct {
+	Base
+	PolicyID           uuid.UUID `gorm:"type:text;not null;index"`
+	DestinationID      uuid.UUID `gorm:"type:text;not null"`
+	KeyID              string    `gorm:"not null"`
+	FromAgentID        uuid.UUID `gorm:"type:text;not null"`
+	ToAgentID          uuid.UUID `gorm:"type:text;not null"`
+	RecipientPublicKey string    `gorm:"not null"`
+	KeyFingerprint     string    `gorm:"not null"`
+	Status             string    `gorm:"not null;default:'pending'"`
+	SealedKey          string    `gorm:"type:text;not null;default:''"`
+	Error              string    `gorm:"type:text;not null;default:''"`
+	RequestedBy        uuid.UUID `gorm:"type:text;not null"`
+}
+
+// PolicyDependency makes a policy run after another one ("run after"): when
+// a job of DependsOnID finishes with an outcome matching Condition, the
+// scheduler starts a job of PolicyID. Condition is "success", "failure" or
+// "always". A policy with dependencies may have an empty Schedule, in which
+// case it only runs when triggered.
+type PolicyDependency struct {
+	Base
+	PolicyID    uuid.UUID `gorm:"type:text;not null;uniqueIndex:idx_policy_dependencies_policy_depends_on"`
+	DependsOnID uuid.UUID `gorm:"type:text;not null;uniqueIndex:idx_policy_dependencies_policy_depends_on;index"`
+	Condition   string    `gorm:"not null;default:'success'"`
+}
+
+// PolicySchedule is an additional cron schedule of a policy, evaluated in
+// the policy's timezone. Snapshots taken by the entry get the extra Tags
+// (comma-separated, e.g. "schedule:hourly"). With CustomRetention, the
+// entry's retention replaces the policy's for snapshots carrying all of its
+// Tags: forget is scoped to those tags and the policy's own forget keeps
+// them.
+type PolicySchedule struct {
+	Base
+	PolicyID         uuid.UUID `gorm:"type:text;not null;index"`
+	Schedule         string    `gorm:"not null"` // cron expression
+	Tags             string    `gorm:"not null;default:''"`
+	CustomRetention  bool      `gorm:"not null;default:false"`
+	RetentionHourly  int       `gorm:"not null;default:0"`
+	RetentionDaily   int       `gorm:"not null;default:0"`
+	RetentionWeekly  int       `gorm:"not null;default:0"`
+	RetentionMonthly int       `gorm:"not null;default:0"`
+	RetentionYearly  int       `gorm:"not null;default:0"`
+}
+
+// TriggerToken lets external systems such as CI pipelines start a policy
+// without a user session via POST /api/v1/hooks/trigger/{token}. Only the
+// SHA-256 hash of the token is stored. When SigningSecret is set, requests
+// must carry an HMAC-SHA256 signature of the body (see api/hooks.go).
+type TriggerToken struct {
+	Base
+	PolicyID      uuid.UUID       `gorm:"type:text;not null;index"`
+	Name          string          `gorm:"not null"`
+	TokenHash     string          `gorm:"not null;uniqueIndex"` // SHA-256 hex of the raw token
+	TokenPrefix   string          `gorm:"not null;default:''"`  // first characters of the raw token, for display
+	SigningSecret EncryptedString `gorm:"type:text;not null;default:''"`
+	CreatedBy     uuid.UUID       `gorm:"type:text;not null"`
+	LastUsedAt    *time.Time
+	RevokedAt     *time.Time
 }
 
 // -----------------------------------------------------------------------------
@@ -180,6 +385,11 @@ type PolicyDestination struct {
 
 // Job represents a single backup execution triggered by the scheduler or
 // manually. Status transitions: pending -> running -> succeeded | failed.
+// A job that never reached its agent ends as "missed" instead.
+//
+// A failed backup may be retried according to its policy: the retry is a new
+// Job with Attempt incremented and RetryOfID pointing to the failed attempt,
+// held back until NotBefore.
 //
 // Destinations and Logs are populated by GetByIDWithDetails via manual queries.
 // The gorm:"-" tag prevents GORM from attempting foreign key resolution on
@@ -188,12 +398,30 @@ type Job struct {
 	Base
 	PolicyID  uuid.UUID  `gorm:"type:text;not null;index"`
 	AgentID   uuid.UUID  `gorm:"type:text;not null;index"`
-	Type      string     `gorm:"not null;default:'backup'"` // "backup", "restore"
-	Status    string     `gorm:"not null;default:'pending'"` // "pending", "running", "succeeded", "failed", "cancelled"
+	Type      string     `gorm:"not null;default:'backup'"` // "backup", "restore", "dry_run", "replication", "import"
+	Status    string     `gorm:"not null;default:'pending'"` // "pending", "running", "succeeded", "failed", "partial", "cancelled", "missed"
 	StartedAt *time.Time
 	EndedAt   *time.Time
 	Error     string `gorm:"type:text;default:''"` // populated on failure
 
+	Attempt      int        `gorm:"not null;default:1"`
+	RetryOfID    *uuid.UUID `gorm:"type:text;index"`
+	FailureClass string     `gorm:"not null;default:''"` // "network", "hook", "wrong_password", "other"
+	NotBefore    *time.Time // retries are not dispatched before this time
+
+	// TriggeredByID is the upstream job whose outcome started this job via
+	// a policy dependency. nil for scheduled and manual runs.
+	TriggeredByID *uuid.UUID `gorm:"type:text"`
+
+	// ScheduleID is the PolicySchedule entry that started this job. nil for
+	// runs of Policy.Schedule, manual runs and dependency runs.
+	ScheduleID *uuid.UUID `gorm:"type:text"`
+
+	// LastActivityAt is the last time the agent reported status or logs for
+	// the job. The scheduler's watchdog fails running jobs that stay silent
+	// for too long.
+	LastActivityAt *time.Time
+
 	// Populated manually by GetByIDWithDetails — not managed by GORM.
 	Destinations []JobDestination `gorm:"-"`
 	Logs         []JobLog         `gorm:"-"`
@@ -205,14 +433,64 @@ type JobDestination struct {
 	Base
 	JobID         uuid.UUID  `gorm:"type:text;not null;index"`
 	DestinationID uuid.UUID  `gorm:"type:text;not null;index"`
-	Status        string     `gorm:"not null;default:'pending'"` // mirrors Job.Status
+	Status        string     `gorm:"not null;default:'pending'"` // mirrors Job.Status, plus "succeeded_with_warnings"
 	SnapshotID    string     `gorm:"default:''"` // opaque ID returned by the backup engine
 	SizeBytes     int64      `gorm:"default:0"`
 	StartedAt     *time.Time
 	EndedAt       *time.Time
 	Error         string `gorm:"type:text;default:''"`
+	// Change statistics from the restic summary of a successful run.
+	// TotalFiles is the number of files processed.
+	FilesNew        int64 `gorm:"not null;default:0"`
+	FilesChanged    int64 `gorm:"not null;default:0"`
+	FilesUnmodified int64 `gorm:"not null;default:0"`
+	TotalFiles      int64 `gorm:"not null;default:0"`
+	DataAdded       int64 `gorm:"not null;default:0"`
+	// Anomaly is set when the run's change rate deviates from the baseline
+	// of the previous runs; AnomalyReason describes the deviation.
+	Anomaly       bool   `gorm:"not null;default:false"`
+	AnomalyReason string `gorm:"type:text;not null;default:''"`
+	// FileErrors is a JSON array of the files restic could not read in a
+	// "succeeded_with_warnings" run, truncated by the agent;
+	// FileErrorCount is the full count.
+	FileErrors     string `gorm:"type:text;not null;default:'[]'"`
+	FileErrorCount int64  `gorm:"not null;default:0"`
+}
+
+// DryRunResult is the size estimate of a "dry_run" job, as reported by the
+// agent. LargestDirectories and UnreadablePaths are JSON arrays; the agent
+// truncates UnreadablePaths, UnreadableCount is the full count.
+type DryRunResult struct {
+	Base
+	JobID              uuid.UUID `gorm:"type:text;not null;uniqueIndex"`
+	TotalFiles         int64     `gorm:"not null;default:0"`
+	TotalBytes         int64     `gorm:"not null;default:0"`
+	DataAdded          int64     `gorm:"not null;default:0"`
+	LargestDirectories string    `gorm:"type:text;not null;default:'[]'"`
+	UnreadablePaths    string    `gorm:"type:text;not null;default:'[]'"`
+	UnreadableCount    int64     `gorm:"not null;default:0"`
 }
 
+// JobProgress is the latest progress snapshot of a running job, overwritten
+// by every progress report from the agent. DestinationID is empty for jobs
+// without destinations.
+type JobProgress struct {
+	Base
+	JobID            uuid.UUID `gorm:"type:text;not null;uniqueIndex"`
+	DestinationID    string    `gorm:"type:text;not null;default:''"`
+	PercentDone      float64   `gorm:"not null;default:0"` // 0 to 1
+	FilesDone        int64     `gorm:"not null;default:0"`
+	TotalFiles       int64     `gorm:"not null;default:0"`
+	BytesDone        int64     `gorm:"not null;default:0"`
+	TotalBytes       int64     `gorm:"not null;default:0"`
+	CurrentFile      string    `gorm:"type:text;not null;default:''"`
+	SecondsRemaining int64     `gorm:"not null;default:0"`
+	SecondsElapsed   int64     `gorm:"not null;default:0"`
+}
+
+// TableName maps to the migration-created table name.
+func (JobProgress) TableName() string { return "job_progress" }
+
 // JobLog stores structured log lines emitted during a job execution.
 // Logs are flushed to the database in batches during execution so that
 // the GUI can show partial logs even for in-progress jobs.
@@ -241,6 +519,9 @@ type Snapshot struct {
 	FileCount     int64     `gorm:"default:0"`
 	Tags          string    `gorm:"type:text;default:'[]'"` // JSON array
 	SnapshotAt    time.Time `gorm:"not null;index"`
+	// OriginalID is the snapshot record this one was copied from by a
+	// replication job. nil for snapshots created by a backup.
+	OriginalID *uuid.UUID `gorm:"type:text;index"`
 }
 
 // -----------------------------------------------------------------------------
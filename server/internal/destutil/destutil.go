@@ -7,6 +7,7 @@ package destutil
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/arkeep-io/arkeep/server/internal/db"
 )
@@ -75,6 +76,21 @@ func BuildRepoURL(dest *db.Destination) string {
 	return ""
 }
 
+// BuildRepositoryURL constructs the restic repository URL of a repository
+// below the root of dest. An empty path is the destination root itself.
+func BuildRepositoryURL(dest *db.Destination, path string) string {
+	root := BuildRepoURL(dest)
+	path = strings.Trim(path, "/")
+	if root == "" || path == "" {
+		return root
+	}
+	if strings.HasSuffix(root, ":") {
+		// A bare remote such as "rclone:remote:" takes a relative path.
+		return root + path
+	}
+	return strings.TrimSuffix(root, "/") + "/" + path
+}
+
 // BuildEnv derives backend-specific environment variables from a destination.
 // For S3, AWS credentials are extracted from the Credentials JSON.
 // For rclone, the credentials JSON is a flat map of RCLONE_CONFIG_* env vars.
@@ -14,9 +14,13 @@ package grpc
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
@@ -32,6 +36,7 @@ import (
 	"github.com/arkeep-io/arkeep/server/internal/metrics"
 	"github.com/arkeep-io/arkeep/server/internal/notification"
 	"github.com/arkeep-io/arkeep/server/internal/repositories"
+	"github.com/arkeep-io/arkeep/server/internal/scheduler"
 	"github.com/arkeep-io/arkeep/server/internal/websocket"
 	proto "github.com/arkeep-io/arkeep/shared/proto"
 	"github.com/google/uuid"
@@ -47,8 +52,13 @@ type Server struct {
 	agentRepo    repositories.AgentRepository
 	jobRepo      repositories.JobRepository
 	snapshotRepo repositories.SnapshotRepository
+	policyRepo   repositories.PolicyRepository
+	repoRepo     repositories.RepositoryRepository // may be nil
+	keyRepo      repositories.KeyRepository        // may be nil
+	importRepo   repositories.ImportRepository     // may be nil
 	hub          *websocket.Hub
 	notifSvc     notification.Service
+	scheduler    JobScheduler     // may be nil (e.g. in tests)
 	metrics      *metrics.Metrics // may be nil when metrics are disabled
 	logger       *zap.Logger
 	sharedSecret string // shared secret agents must present in gRPC metadata
@@ -86,6 +96,46 @@ type Config struct {
 	// Metrics is the Prometheus metrics collector. Optional — if nil, no
 	// job metrics are recorded.
 	Metrics *metrics.Metrics
+	// Scheduler receives agent lifecycle events so that jobs queued while an
+	// agent was offline are dispatched when it reconnects. Optional — if nil,
+	// pending jobs are only picked up by the next scheduled run.
+	Scheduler JobScheduler
+	// Policies resolves per-policy notification settings. Optional — if nil,
+	// jobs that completed with warnings are notified as successes.
+	Policies repositories.PolicyRepository
+	// Repositories records when a repository entity was first written to.
+	// Optional — if nil (or Policies is nil), InitializedAt stays unset and
+	// the scheduler keeps sending the chunker source of the repository.
+	Repositories repositories.RepositoryRepository
+	// Keys stores the escrow kits and key shares of zero-knowledge policies.
+	// Optional — if nil, ReportKeyEscrow and ReportKeyShare are unavailable.
+	Keys repositories.KeyRepository
+	// Imports stores the scans of repositories being adopted. Optional — if
+	// nil, ReportRepositoryScan is unavailable.
+	Imports repositories.ImportRepository
+}
+
+// JobScheduler is the subset of scheduler.Scheduler used by the gRPC server.
+// Declared as an interface so that tests can run the server without a real
+// scheduler.
+type JobScheduler interface {
+	// DispatchPending dispatches every pending job of the given agent.
+	DispatchPending(ctx context.Context, agentID uuid.UUID)
+	// JobFinished frees the destination slots of a job that reached a
+	// terminal state and dispatches queued jobs that now fit.
+	JobFinished(ctx context.Context, jobID uuid.UUID)
+	// DispatchQueued dispatches queued jobs whose destinations have room.
+	DispatchQueued(ctx context.Context)
+	// RetryFailedJob schedules a retry of a failed job if its policy allows
+	// it. Returns nil when the failure is final.
+	RetryFailedJob(ctx context.Context, jobID uuid.UUID) (*db.Job, error)
+	// TriggerDependents starts the policies that run after the job's policy
+	// and whose condition matches the job's final outcome.
+	TriggerDependents(ctx context.Context, jobID uuid.UUID) []*db.Job
+	// CheckBackupAnomaly compares a successful destination run with the
+	// previous runs of its policy and returns the ID of the snapshot the
+	// agent must hold, or "".
+	CheckBackupAnomaly(ctx context.Context, job *db.Job, run *db.JobDestination) string
 }
 
 // New creates a new Server instance with the given dependencies.
@@ -103,8 +153,13 @@ func New(
 		agentRepo:         agentRepo,
 		jobRepo:           jobRepo,
 		snapshotRepo:      snapshotRepo,
+		policyRepo:        cfg.Policies,
+		repoRepo:          cfg.Repositories,
+		keyRepo:           cfg.Keys,
+		importRepo:        cfg.Imports,
 		hub:               hub,
 		notifSvc:          cfg.NotifService,
+		scheduler:         cfg.Scheduler,
 		metrics:           cfg.Metrics,
 		logger:            logger.Named("grpc"),
 		sharedSecret:      cfg.SharedSecret,
@@ -293,6 +348,7 @@ func (s *Server) Register(ctx context.Context, req *proto.RegisterRequest) (*pro
 			existing.OS = req.Os
 			existing.Arch = req.Arch
 			existing.DockerAvailable = req.Capabilities != nil && req.Capabilities.Docker
+			existing.KeySharePublicKey = req.KeySharePublicKey
 
 			if err := s.agentRepo.Update(ctx, existing); err != nil {
 				logger.Error("register: failed to update agent record", zap.Error(err))
@@ -316,6 +372,38 @@ func (s *Server) Register(ctx context.Context, req *proto.RegisterRequest) (*pro
 		)
 	}
 
+	// ── Placeholder left by a repository import ───────────────────────────────
+	// Applying an import creates an agent for each host found in the
+	// repository that has none yet. The first agent to register with that
+	// hostname takes the record over, together with the draft policies and
+	// snapshots attached to it. A record that was never registered has no
+	// version and was never seen.
+	if placeholder, err := s.agentRepo.GetByHostname(ctx, req.Hostname); err == nil && placeholder.Version == "" && placeholder.LastSeenAt == nil {
+		placeholder.Version = req.Version
+		placeholder.OS = req.Os
+		placeholder.Arch = req.Arch
+		placeholder.DockerAvailable = req.Capabilities != nil && req.Capabilities.Docker
+		placeholder.KeySharePublicKey = req.KeySharePublicKey
+
+		if err := s.agentRepo.Update(ctx, placeholder); err != nil {
+			logger.Error("register: failed to update imported agent record", zap.Error(err))
+			return nil, status.Error(codes.Internal, "registration failed")
+		}
+
+		s.cacheCapabilities(placeholder.ID.String(), req.Capabilities)
+
+		logger.Info("agent registered, taking over the record of a repository import",
+			zap.String("agent_id", placeholder.ID.String()),
+		)
+		return &proto.RegisterResponse{
+			AgentId:   placeholder.ID.String(),
+			AgentName: placeholder.Name,
+		}, nil
+	} else if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		logger.Error("register: hostname lookup failed", zap.Error(err))
+		return nil, status.Error(codes.Internal, "registration failed")
+	}
+
 	// ── First-time registration ───────────────────────────────────────────────
 	// ID is a UUIDv7 generated in the BeforeCreate hook (see db/models.go).
 	// Default display name is the hostname — the user can rename it in the GUI.
@@ -326,7 +414,8 @@ func (s *Server) Register(ctx context.Context, req *proto.RegisterRequest) (*pro
 		OS:              req.Os,
 		Arch:            req.Arch,
 		Status:          "offline", // transitions to "online" when StreamJobs opens
-		DockerAvailable: req.Capabilities != nil && req.Capabilities.Docker,
+		DockerAvailable:   req.Capabilities != nil && req.Capabilities.Docker,
+		KeySharePublicKey: req.KeySharePublicKey,
 	}
 
 	if err := s.agentRepo.Create(ctx, agent); err != nil {
@@ -424,6 +513,17 @@ func (s *Server) StreamJobs(req *proto.StreamJobsRequest, stream proto.AgentServ
 	// Register RPC — StreamJobsRequest does not carry capability fields.
 	s.agentManager.Register(req.AgentId, agent.Hostname, s.dockerAvailable(req.AgentId), stream)
 
+	// Hand over jobs that were queued while the agent was offline (scheduled
+	// runs, catch-up runs after a server restart). Runs in a goroutine because
+	// Dispatch writes to the stream this handler is about to block on.
+	if s.scheduler != nil {
+		go func() {
+			dispatchCtx, dispatchCancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer dispatchCancel()
+			s.scheduler.DispatchPending(dispatchCtx, agentID)
+		}()
+	}
+
 	// Block until the client disconnects or the server shuts down.
 	<-ctx.Done()
 
@@ -458,6 +558,16 @@ func (s *Server) StreamJobs(req *proto.StreamJobsRequest, stream proto.AgentServ
 		)
 	}
 
+	// Deregister freed the destination slots of the orphaned jobs; let queued
+	// jobs of other agents take them.
+	if s.scheduler != nil {
+		go func() {
+			queueCtx, queueCancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer queueCancel()
+			s.scheduler.DispatchQueued(queueCtx)
+		}()
+	}
+
 	if s.notifSvc != nil {
 		go func() {
 			notifCtx, notifCancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -495,8 +605,13 @@ func (s *Server) ReportJobStatus(ctx context.Context, req *proto.JobStatusReport
 		err = s.jobRepo.UpdateStatus(ctx, jobID, "succeeded", nil, &now, "")
 		dbStatus = "succeeded"
 	case proto.JobStatus_JOB_STATUS_FAILED:
-		err = s.jobRepo.UpdateStatus(ctx, jobID, "failed", nil, &now, req.Message)
+		// A backup that still stored a snapshot on some of its
+		// destinations is partial rather than failed.
 		dbStatus = "failed"
+		if succeeded, failed := s.destinationOutcomes(ctx, jobID); len(succeeded) > 0 && len(failed) > 0 {
+			dbStatus = "partial"
+		}
+		err = s.jobRepo.UpdateStatus(ctx, jobID, dbStatus, nil, &now, req.Message)
 	case proto.JobStatus_JOB_STATUS_CANCELLED:
 		err = s.jobRepo.UpdateStatus(ctx, jobID, "cancelled", nil, &now, req.Message)
 		dbStatus = "cancelled"
@@ -511,15 +626,46 @@ func (s *Server) ReportJobStatus(ctx context.Context, req *proto.JobStatusReport
 		)
 		return nil, status.Error(codes.Internal, "failed to update job status")
 	}
+	if dbStatus == "running" {
+		s.touchJobActivity(ctx, jobID, now)
+	}
+
+	// A failed backup may be retried according to its policy. Only the
+	// final failure of a retry chain is notified, to avoid alert spam.
+	// A partial backup is not retried: the retry would back up to every
+	// destination again, including those that already have the snapshot.
+	var retry *db.Job
+	if dbStatus == "failed" || dbStatus == "partial" {
+		if err := s.jobRepo.SetFailureClass(ctx, jobID, failureClassFromProto(req.FailureClass)); err != nil {
+			s.logger.Warn("failed to record job failure class",
+				zap.String("job_id", req.JobId),
+				zap.Error(err),
+			)
+		}
+	}
+	if dbStatus == "failed" {
+		if s.scheduler != nil {
+			retry, err = s.scheduler.RetryFailedJob(ctx, jobID)
+			if err != nil {
+				s.logger.Error("failed to schedule job retry",
+					zap.String("job_id", req.JobId),
+					zap.Error(err),
+				)
+			}
+		}
+	}
 
 	wsPayload := map[string]any{
 		"job_id":  req.JobId,
 		"status":  dbStatus,
 		"message": req.Message,
 	}
+	if retry != nil {
+		wsPayload["retry_job_id"] = retry.ID.String()
+	}
 	// Include finished_at for terminal states so the GUI can update the
 	// elapsed-time display without waiting for a full REST fetch.
-	if dbStatus == "succeeded" || dbStatus == "failed" || dbStatus == "cancelled" {
+	if dbStatus == "succeeded" || dbStatus == "failed" || dbStatus == "partial" || dbStatus == "cancelled" {
 		wsPayload["finished_at"] = now.Format(time.RFC3339)
 	}
 	s.hub.Publish("job:"+req.JobId, websocket.Message{
@@ -529,8 +675,27 @@ func (s *Server) ReportJobStatus(ctx context.Context, req *proto.JobStatusReport
 
 	// Fire notifications for terminal job states. Non-fatal: run in a
 	// goroutine so a slow notification path never delays the gRPC response.
-	if s.notifSvc != nil && (req.Status == proto.JobStatus_JOB_STATUS_COMPLETED || req.Status == proto.JobStatus_JOB_STATUS_FAILED) {
-		go s.notifyJobTerminal(jobID, req.Status, req.Message)
+	if s.notifSvc != nil && retry == nil && (dbStatus == "succeeded" || dbStatus == "failed" || dbStatus == "partial") {
+		go s.notifyJobTerminal(jobID, dbStatus, req.Message)
+	}
+
+	// Free the job's destination slots so that queued jobs can start.
+	if s.scheduler != nil && dbStatus != "running" {
+		go func() {
+			queueCtx, queueCancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer queueCancel()
+			s.scheduler.JobFinished(queueCtx, jobID)
+		}()
+	}
+
+	// Start the policies that run after this one. A failure that is retried
+	// is not the final outcome yet.
+	if s.scheduler != nil && (dbStatus == "succeeded" || dbStatus == "partial" || (dbStatus == "failed" && retry == nil)) {
+		go func() {
+			depCtx, depCancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer depCancel()
+			s.scheduler.TriggerDependents(depCtx, jobID)
+		}()
 	}
 
 	// Record Prometheus metrics for terminal states. Non-fatal: goroutine.
@@ -547,9 +712,25 @@ func (s *Server) ReportJobStatus(ctx context.Context, req *proto.JobStatusReport
 	return &proto.JobStatusResponse{Ok: true}, nil
 }
 
+// failureClassFromProto maps the agent's failure classification to the value
+// stored in Job.FailureClass. Older agents do not classify failures; those are
+// stored as "other".
+func failureClassFromProto(c proto.FailureClass) string {
+	switch c {
+	case proto.FailureClass_FAILURE_CLASS_NETWORK:
+		return "network"
+	case proto.FailureClass_FAILURE_CLASS_HOOK:
+		return "hook"
+	case proto.FailureClass_FAILURE_CLASS_WRONG_PASSWORD:
+		return "wrong_password"
+	default:
+		return "other"
+	}
+}
+
 // notifyJobTerminal fetches the job details and fires the appropriate
 // notification. Runs in a goroutine — errors are logged, never propagated.
-func (s *Server) notifyJobTerminal(jobID uuid.UUID, st proto.JobStatus, errMsg string) {
+func (s *Server) notifyJobTerminal(jobID uuid.UUID, dbStatus, errMsg string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -561,17 +742,86 @@ func (s *Server) notifyJobTerminal(jobID uuid.UUID, st proto.JobStatus, errMsg s
 		)
 		return
 	}
+	// A dry run is an on-demand estimate, not a backup: its outcome is
+	// visible on the job page and does not warrant a notification.
+	if job.Type == "dry_run" {
+		return
+	}
 
-	switch st {
-	case proto.JobStatus_JOB_STATUS_COMPLETED:
+	switch dbStatus {
+	case "succeeded":
+		if fileErrors := s.jobFileErrors(ctx, jobID); fileErrors > 0 {
+			asFailure := false
+			if s.policyRepo != nil {
+				if policy, err := s.policyRepo.GetByID(ctx, job.PolicyID); err == nil {
+					asFailure = policy.WarningsAsFailure
+				}
+			}
+			warning := fmt.Sprintf("%d source file(s) could not be read and are missing from the snapshots", fileErrors)
+			if err := s.notifSvc.NotifyJobWarnings(ctx, jobID, job.PolicyID, job.PolicyName, warning, asFailure); err != nil {
+				s.logger.Warn("failed to send job-warnings notification", zap.Error(err))
+			}
+			return
+		}
 		if err := s.notifSvc.NotifyJobSucceeded(ctx, jobID, job.PolicyID, job.PolicyName); err != nil {
 			s.logger.Warn("failed to send job-succeeded notification", zap.Error(err))
 		}
-	case proto.JobStatus_JOB_STATUS_FAILED:
+	case "failed":
 		if err := s.notifSvc.NotifyJobFailed(ctx, jobID, job.PolicyID, job.PolicyName, errMsg); err != nil {
 			s.logger.Warn("failed to send job-failed notification", zap.Error(err))
 		}
+	case "partial":
+		succeeded, failed := s.destinationOutcomes(ctx, jobID)
+		if err := s.notifSvc.NotifyBackupPartial(ctx, jobID, job.PolicyID, job.PolicyName, failed, succeeded, errMsg); err != nil {
+			s.logger.Warn("failed to send backup-partial notification", zap.Error(err))
+		}
+	}
+}
+
+// destinationOutcomes splits the destinations of a job by outcome: those
+// that received a snapshot and those that did not (failed, or never reached
+// because the job was interrupted). Both hold destination names.
+func (s *Server) destinationOutcomes(ctx context.Context, jobID uuid.UUID) (succeeded, failed []string) {
+	destinations, err := s.jobRepo.ListDestinationsByJob(ctx, jobID)
+	if err != nil {
+		s.logger.Warn("could not list job destinations",
+			zap.String("job_id", jobID.String()),
+			zap.Error(err),
+		)
+		return nil, nil
+	}
+	for _, d := range destinations {
+		name := d.DestinationName
+		if name == "" {
+			name = d.DestinationID.String()
+		}
+		if d.Status == "succeeded" || d.Status == "succeeded_with_warnings" {
+			succeeded = append(succeeded, name)
+		} else {
+			failed = append(failed, name)
+		}
+	}
+	return succeeded, failed
+}
+
+// jobFileErrors returns the number of source files left out of the job's
+// snapshots, summed over its destinations.
+func (s *Server) jobFileErrors(ctx context.Context, jobID uuid.UUID) int64 {
+	destinations, err := s.jobRepo.ListDestinationsByJob(ctx, jobID)
+	if err != nil {
+		s.logger.Warn("could not list job destinations",
+			zap.String("job_id", jobID.String()),
+			zap.Error(err),
+		)
+		return 0
+	}
+	var n int64
+	for _, d := range destinations {
+		if d.Status == "succeeded_with_warnings" {
+			n += d.FileErrorCount
+		}
 	}
+	return n
 }
 
 // recordJobMetrics fetches the minimal job fields needed to record Prometheus
@@ -608,12 +858,28 @@ func (s *Server) recordJobMetrics(jobID uuid.UUID, dbStatus string) {
 // See server/internal/repository/job.go for the BulkCreateLogs implementation.
 const logFlushBatchSize = 50
 
+// activityTouchInterval throttles how often StreamLogs refreshes
+// Job.LastActivityAt. Must stay well below the scheduler's stuck-job timeout.
+const activityTouchInterval = 30 * time.Second
+
+// touchJobActivity records agent activity on a job for the stuck-job
+// watchdog. Non-fatal: failures are logged.
+func (s *Server) touchJobActivity(ctx context.Context, jobID uuid.UUID, at time.Time) {
+	if err := s.jobRepo.TouchActivity(ctx, jobID, at); err != nil {
+		s.logger.Warn("failed to record job activity",
+			zap.String("job_id", jobID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
 func (s *Server) StreamLogs(stream proto.AgentService_StreamLogsServer) error {
 	var (
-		entries  []*proto.LogEntry
-		jobID    uuid.UUID
-		jobIDSet bool
-		flushed  int // index into entries up to which we have already persisted
+		entries   []*proto.LogEntry
+		jobID     uuid.UUID
+		jobIDSet  bool
+		flushed   int       // index into entries up to which we have already persisted
+		touchedAt time.Time // last time the job's activity timestamp was refreshed
 	)
 
 	// flushBatch persists entries[flushed:end] to the DB.
@@ -673,6 +939,13 @@ func (s *Server) StreamLogs(stream proto.AgentService_StreamLogsServer) error {
 
 		entries = append(entries, entry)
 
+		// Keep the job's last-activity timestamp fresh for the stuck-job
+		// watchdog, without a DB write per log line.
+		if now := time.Now().UTC(); now.Sub(touchedAt) >= activityTouchInterval {
+			s.touchJobActivity(stream.Context(), jobID, now)
+			touchedAt = now
+		}
+
 		// Publish each log line to WebSocket in real-time so the GUI can
 		// display a live log tail without waiting for the job to complete.
 		// timestamp is included so the frontend can display the correct time
@@ -710,6 +983,13 @@ func (s *Server) StreamLogs(stream proto.AgentService_StreamLogsServer) error {
 	})
 }
 
+// fileError is the JSON shape stored in JobDestination.FileErrors.
+type fileError struct {
+	Path    string `json:"path"`
+	During  string `json:"during"`
+	Message string `json:"message"`
+}
+
 // ReportDestinationStatus handles per-destination result reports from agents.
 // Called once per destination after the backup to that destination completes
 // or fails. Persists the restic snapshot ID, byte count, and final status so
@@ -765,7 +1045,14 @@ func (s *Server) ReportDestinationStatus(ctx context.Context, req *proto.Destina
 		startedAt = req.StartedAt.AsTime().UTC()
 	}
 
-	if err := s.jobRepo.UpdateDestinationStatus(ctx, jobDestID, req.Status, &startedAt, &now, req.SnapshotId, req.SizeBytes, req.Error); err != nil {
+	// The size of the destination's run includes the database dumps, which
+	// are reported as snapshots of their own.
+	sizeBytes := req.SizeBytes
+	for _, ss := range req.StdinSnapshots {
+		sizeBytes += ss.SizeBytes
+	}
+
+	if err := s.jobRepo.UpdateDestinationStatus(ctx, jobDestID, req.Status, &startedAt, &now, req.SnapshotId, sizeBytes, req.Error); err != nil {
 		s.logger.Error("ReportDestinationStatus: failed to update destination status",
 			zap.String("job_id", req.JobId),
 			zap.String("destination_id", req.DestinationId),
@@ -773,48 +1060,127 @@ func (s *Server) ReportDestinationStatus(ctx context.Context, req *proto.Destina
 		)
 		return nil, status.Error(codes.Internal, "failed to update destination status")
 	}
+	s.touchJobActivity(ctx, jobID, now)
+
+	// Change statistics and the anomaly check only apply to successful runs,
+	// with or without warnings. Both are non-fatal, like the snapshot
+	// creation below.
+	var job *db.Job
+	var holdSnapshotID string
+	if req.FileErrorCount > 0 {
+		fileErrors := make([]fileError, 0, len(req.FileErrors))
+		for _, fe := range req.FileErrors {
+			fileErrors = append(fileErrors, fileError{Path: fe.Path, During: fe.During, Message: fe.Message})
+		}
+		data, _ := json.Marshal(fileErrors)
+		if err := s.jobRepo.SetDestinationFileErrors(ctx, jobDestID, string(data), int64(req.FileErrorCount)); err != nil {
+			s.logger.Warn("ReportDestinationStatus: failed to record file errors",
+				zap.String("job_id", req.JobId),
+				zap.Error(err),
+			)
+		}
+	}
+	if req.Status == "succeeded" || req.Status == "succeeded_with_warnings" {
+		stats := repositories.ChangeStats{
+			FilesNew:        int64(req.FilesNew),
+			FilesChanged:    int64(req.FilesChanged),
+			FilesUnmodified: int64(req.FilesUnmodified),
+			TotalFiles:      int64(req.TotalFiles),
+			DataAdded:       int64(req.DataAdded),
+		}
+		if err := s.jobRepo.SetDestinationChangeStats(ctx, jobDestID, stats); err != nil {
+			s.logger.Warn("ReportDestinationStatus: failed to record change statistics",
+				zap.String("job_id", req.JobId),
+				zap.Error(err),
+			)
+		}
+
+		// The job record is fetched to resolve PolicyID, which is not carried
+		// in the DestinationStatusReport proto message.
+		job, err = s.jobRepo.GetByID(ctx, jobID)
+		if err != nil {
+			s.logger.Warn("ReportDestinationStatus: could not fetch job",
+				zap.String("job_id", req.JobId),
+				zap.Error(err),
+			)
+		} else if s.scheduler != nil && req.TotalFiles > 0 {
+			// Older agents do not report change statistics.
+			holdSnapshotID = s.scheduler.CheckBackupAnomaly(ctx, job, &db.JobDestination{
+				Base:          db.Base{ID: jobDestID},
+				DestinationID: destID,
+				FilesNew:      stats.FilesNew,
+				FilesChanged:  stats.FilesChanged,
+				TotalFiles:    stats.TotalFiles,
+			})
+		}
+	}
+
+	if job != nil {
+		s.markRepositoryInitialized(ctx, job, destID, now)
+	}
+
+	// The agent of a zero-knowledge policy reports the fingerprint of the
+	// repository key it holds, so that restores and key shares can check it.
+	if req.KeyFingerprint != "" {
+		if job == nil {
+			job, _ = s.jobRepo.GetByID(ctx, jobID)
+		}
+		if job != nil && s.policyRepo != nil {
+			if err := s.policyRepo.SetDestinationKeyFingerprint(ctx, job.PolicyID, destID, req.KeyFingerprint); err != nil {
+				s.logger.Warn("ReportDestinationStatus: failed to record key fingerprint",
+					zap.String("job_id", req.JobId),
+					zap.Error(err),
+				)
+			}
+		}
+	}
 
 	// If the backup to this destination succeeded and the agent reported a
 	// restic snapshot ID, persist a Snapshot record. This is the primary way
 	// snapshots are created — there is no separate catalog sync step.
 	//
-	// The job record is fetched to resolve PolicyID, which is not carried in
-	// the DestinationStatusReport proto message.
 	// Snapshot creation is non-fatal: a failure here does not roll back the
 	// destination status update that already succeeded above.
-	if req.Status == "succeeded" && req.SnapshotId != "" {
-		job, err := s.jobRepo.GetByID(ctx, jobID)
-		if err != nil {
-			s.logger.Warn("ReportDestinationStatus: could not fetch job for snapshot creation",
+	if job != nil && req.SnapshotId != "" {
+		snap := &db.Snapshot{
+			PolicyID:      job.PolicyID,
+			DestinationID: destID,
+			JobID:         jobID,
+			SnapshotID:    req.SnapshotId,
+			SizeBytes:     req.SizeBytes,
+			FileCount:     int64(req.TotalFiles),
+			Tags:          "[]",
+			SnapshotAt:    now,
+		}
+		if err := s.snapshotRepo.Create(ctx, snap); err != nil {
+			s.logger.Error("ReportDestinationStatus: failed to create snapshot record",
 				zap.String("job_id", req.JobId),
+				zap.String("snapshot_id", req.SnapshotId),
 				zap.Error(err),
 			)
 		} else {
-			snap := &db.Snapshot{
-				PolicyID:      job.PolicyID,
-				DestinationID: destID,
-				JobID:         jobID,
-				SnapshotID:    req.SnapshotId,
-				SizeBytes:     req.SizeBytes,
-				Tags:          "[]",
-				SnapshotAt:    now,
-			}
-			if err := s.snapshotRepo.Create(ctx, snap); err != nil {
-				s.logger.Error("ReportDestinationStatus: failed to create snapshot record",
-					zap.String("job_id", req.JobId),
-					zap.String("snapshot_id", req.SnapshotId),
-					zap.Error(err),
-				)
-			} else {
-				s.logger.Info("snapshot record created",
-					zap.String("job_id", req.JobId),
-					zap.String("destination_id", req.DestinationId),
-					zap.String("snapshot_id", req.SnapshotId),
-				)
-			}
+			s.logger.Info("snapshot record created",
+				zap.String("job_id", req.JobId),
+				zap.String("destination_id", req.DestinationId),
+				zap.String("snapshot_id", req.SnapshotId),
+			)
+		}
+	}
+
+	// Database sources are dumped into snapshots of their own, one per
+	// database, reported next to the snapshot of the files.
+	if job != nil {
+		for _, ss := range req.StdinSnapshots {
+			s.recordStdinSnapshot(ctx, job, destID, ss, now)
 		}
 	}
 
+	// A replication job reports the snapshots it copied instead of a
+	// snapshot of its own.
+	if job != nil && len(req.CopiedSnapshots) > 0 {
+		s.recordCopies(ctx, job, destID, req.CopiedSnapshots)
+	}
+
 	s.logger.Info("destination status updated",
 		zap.String("job_id", req.JobId),
 		zap.String("destination_id", req.DestinationId),
@@ -823,7 +1189,456 @@ func (s *Server) ReportDestinationStatus(ctx context.Context, req *proto.Destina
 		zap.Int64("size_bytes", req.SizeBytes),
 	)
 
-	return &proto.DestinationStatusResponse{Ok: true}, nil
+	return &proto.DestinationStatusResponse{Ok: true, HoldSnapshotId: holdSnapshotID}, nil
+}
+
+// recordStdinSnapshot adds the snapshot of a database dump to the catalog,
+// with the tags that name its database. Failures are logged, like the
+// creation of the job's main snapshot.
+func (s *Server) recordStdinSnapshot(ctx context.Context, job *db.Job, destID uuid.UUID, ss *proto.StdinSnapshot, at time.Time) {
+	if ss.SnapshotId == "" {
+		return
+	}
+	tags, _ := json.Marshal(append([]string{}, ss.Tags...))
+	snap := &db.Snapshot{
+		PolicyID:      job.PolicyID,
+		DestinationID: destID,
+		JobID:         job.ID,
+		SnapshotID:    ss.SnapshotId,
+		SizeBytes:     ss.SizeBytes,
+		FileCount:     1,
+		Tags:          string(tags),
+		SnapshotAt:    at,
+	}
+	if err := s.snapshotRepo.Create(ctx, snap); err != nil {
+		s.logger.Error("ReportDestinationStatus: failed to create database snapshot record",
+			zap.String("job_id", job.ID.String()),
+			zap.String("snapshot_id", ss.SnapshotId),
+			zap.Error(err),
+		)
+		return
+	}
+	s.logger.Info("database snapshot record created",
+		zap.String("job_id", job.ID.String()),
+		zap.String("destination_id", destID.String()),
+		zap.String("snapshot_id", ss.SnapshotId),
+		zap.String("filename", ss.Filename),
+	)
+}
+
+// markRepositoryInitialized records that the repository the job's policy
+// writes into on destID exists, once a job has succeeded on it. Failures are
+// logged: the repository is then marked by a later job.
+func (s *Server) markRepositoryInitialized(ctx context.Context, job *db.Job, destID uuid.UUID, at time.Time) {
+	if s.policyRepo == nil || s.repoRepo == nil {
+		return
+	}
+	_, destinations, err := s.policyRepo.GetByIDWithDestinations(ctx, job.PolicyID)
+	if err != nil {
+		return
+	}
+	for _, pd := range destinations {
+		if pd.DestinationID != destID || pd.RepositoryID == nil {
+			continue
+		}
+		if err := s.repoRepo.MarkInitialized(ctx, *pd.RepositoryID, at); err != nil {
+			s.logger.Warn("ReportDestinationStatus: failed to mark repository initialized",
+				zap.String("job_id", job.ID.String()),
+				zap.String("repository_id", pd.RepositoryID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// recordCopies adds the snapshots a replication job copied to a destination
+// to the catalog, linked to the records of their originals in the policy's
+// source destination. Copies already in the catalog are skipped: the agent
+// reports every copy the destination holds, including earlier ones. A copy
+// belongs to the policy of its original, or to the policy named by its
+// "policy:<id>" tag when the original is not in the catalog. Failures are
+// logged, like the creation of backup snapshots.
+func (s *Server) recordCopies(ctx context.Context, job *db.Job, destID uuid.UUID, copies []*proto.CopiedSnapshot) {
+	var sourceID *uuid.UUID
+	if s.policyRepo != nil {
+		if policy, err := s.policyRepo.GetByID(ctx, job.PolicyID); err == nil {
+			sourceID = policy.ReplicateFromID
+		}
+	}
+
+	recorded := 0
+	for _, c := range copies {
+		if _, err := s.snapshotRepo.GetBySnapshotID(ctx, destID, c.SnapshotId); err == nil {
+			continue
+		}
+
+		tags, _ := json.Marshal(append([]string{}, c.Tags...))
+		snap := &db.Snapshot{
+			PolicyID:      job.PolicyID,
+			DestinationID: destID,
+			JobID:         job.ID,
+			SnapshotID:    c.SnapshotId,
+			Tags:          string(tags),
+			SnapshotAt:    time.Now().UTC(),
+		}
+		if c.Time != nil {
+			snap.SnapshotAt = c.Time.AsTime().UTC()
+		}
+		for _, tag := range c.Tags {
+			if rest, ok := strings.CutPrefix(tag, "policy:"); ok {
+				if id, err := uuid.Parse(rest); err == nil {
+					snap.PolicyID = id
+				}
+			}
+		}
+		if sourceID != nil {
+			if original, err := s.snapshotRepo.GetBySnapshotID(ctx, *sourceID, c.OriginalId); err == nil {
+				snap.PolicyID = original.PolicyID
+				snap.SizeBytes = original.SizeBytes
+				snap.FileCount = original.FileCount
+				snap.SnapshotAt = original.SnapshotAt
+				snap.OriginalID = &original.ID
+			}
+		}
+
+		if err := s.snapshotRepo.Create(ctx, snap); err != nil {
+			s.logger.Error("ReportDestinationStatus: failed to create copied snapshot record",
+				zap.String("job_id", job.ID.String()),
+				zap.String("snapshot_id", c.SnapshotId),
+				zap.Error(err),
+			)
+			continue
+		}
+		recorded++
+	}
+
+	if recorded > 0 {
+		s.logger.Info("copied snapshot records created",
+			zap.String("job_id", job.ID.String()),
+			zap.String("destination_id", destID.String()),
+			zap.Int("count", recorded),
+		)
+	}
+}
+
+// ReportSnapshotHold records that the agent tagged a snapshot as held after
+// an anomalous backup run. restic rewrote the snapshot with a new ID, so the
+// snapshot record is moved to it.
+func (s *Server) ReportSnapshotHold(ctx context.Context, req *proto.SnapshotHoldReport) (*proto.SnapshotHoldResponse, error) {
+	destID, err := uuid.Parse(req.DestinationId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid destination_id")
+	}
+	if req.OldSnapshotId == "" || req.NewSnapshotId == "" {
+		return nil, status.Error(codes.InvalidArgument, "old_snapshot_id and new_snapshot_id are required")
+	}
+
+	if err := s.snapshotRepo.ReplaceTagged(ctx, destID, req.OldSnapshotId, req.NewSnapshotId, scheduler.HoldTag); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "snapshot not found")
+		}
+		s.logger.Error("ReportSnapshotHold: failed to update snapshot record",
+			zap.String("destination_id", req.DestinationId),
+			zap.String("snapshot_id", req.OldSnapshotId),
+			zap.Error(err),
+		)
+		return nil, status.Error(codes.Internal, "failed to update snapshot record")
+	}
+
+	s.logger.Info("snapshot held",
+		zap.String("job_id", req.JobId),
+		zap.String("destination_id", req.DestinationId),
+		zap.String("old_snapshot_id", req.OldSnapshotId),
+		zap.String("new_snapshot_id", req.NewSnapshotId),
+	)
+
+	return &proto.SnapshotHoldResponse{Ok: true}, nil
+}
+
+// ReportKeyEscrow stores the escrow kit an agent exported after a backup of
+// a zero-knowledge policy, replacing its previous kit. The kit is sealed to
+// the admin's escrow public key and opaque to the server.
+func (s *Server) ReportKeyEscrow(ctx context.Context, req *proto.KeyEscrowReport) (*proto.KeyEscrowResponse, error) {
+	if s.keyRepo == nil {
+		return nil, status.Error(codes.Unimplemented, "key escrow is not available")
+	}
+	agentID, err := uuid.Parse(req.AgentId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid agent_id")
+	}
+	if len(req.Kit) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "kit is required")
+	}
+
+	kit := &db.KeyEscrowKit{
+		AgentID:              agentID,
+		EscrowKeyFingerprint: req.EscrowKeyFingerprint,
+		Kit:                  base64.StdEncoding.EncodeToString(req.Kit),
+		KeyCount:             int(req.KeyCount),
+	}
+	if err := s.keyRepo.SaveEscrowKit(ctx, kit); err != nil {
+		s.logger.Error("ReportKeyEscrow: failed to save escrow kit",
+			zap.String("agent_id", req.AgentId),
+			zap.Error(err),
+		)
+		return nil, status.Error(codes.Internal, "failed to save escrow kit")
+	}
+
+	s.logger.Info("escrow kit stored",
+		zap.String("agent_id", req.AgentId),
+		zap.String("escrow_key_fingerprint", req.EscrowKeyFingerprint),
+		zap.Uint32("key_count", req.KeyCount),
+	)
+	return &proto.KeyEscrowResponse{Ok: true}, nil
+}
+
+// ReportKeyShare completes a key share with the key the policy's agent
+// sealed to the recipient, or with the error that prevented it. Only the
+// agent the share was requested from may complete it, once.
+func (s *Server) ReportKeyShare(ctx context.Context, req *proto.KeyShareReport) (*proto.KeyShareResponse, error) {
+	if s.keyRepo == nil {
+		return nil, status.Error(codes.Unimplemented, "key shares are not available")
+	}
+	shareID, err := uuid.Parse(req.ShareId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid share_id")
+	}
+	share, err := s.keyRepo.GetShare(ctx, shareID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "key share not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to look up key share")
+	}
+	if share.FromAgentID.String() != req.AgentId {
+		return nil, status.Error(codes.PermissionDenied, "key share was requested from another agent")
+	}
+	if req.Error == "" && len(req.SealedKey) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "sealed_key or error is required")
+	}
+
+	sealed := ""
+	if req.Error == "" {
+		sealed = base64.StdEncoding.EncodeToString(req.SealedKey)
+	}
+	if err := s.keyRepo.CompleteShare(ctx, shareID, sealed, req.Error); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, status.Error(codes.FailedPrecondition, "key share is already completed")
+		}
+		s.logger.Error("ReportKeyShare: failed to complete key share",
+			zap.String("share_id", req.ShareId),
+			zap.Error(err),
+		)
+		return nil, status.Error(codes.Internal, "failed to complete key share")
+	}
+
+	s.logger.Info("key share completed",
+		zap.String("share_id", req.ShareId),
+		zap.String("to_agent_id", share.ToAgentID.String()),
+		zap.Bool("failed", req.Error != ""),
+	)
+	return &proto.KeyShareResponse{Ok: true}, nil
+}
+
+// scannedSnapshot is the JSON shape of one element of
+// RepositoryImport.Snapshots.
+type scannedSnapshot struct {
+	SnapshotID string    `json:"snapshot_id"`
+	Time       time.Time `json:"time"`
+	Hostname   string    `json:"hostname"`
+	Paths      []string  `json:"paths"`
+	Tags       []string  `json:"tags"`
+	SizeBytes  int64     `json:"size_bytes"`
+	FileCount  int64     `json:"file_count"`
+}
+
+// ReportRepositoryScan completes a repository import with the snapshots the
+// agent found, or with the error that prevented the scan. Only the agent the
+// scan was requested from may complete it, once. A successful scan proves
+// that the repository exists, so it is marked initialised.
+func (s *Server) ReportRepositoryScan(ctx context.Context, req *proto.RepositoryScanReport) (*proto.RepositoryScanResponse, error) {
+	if s.importRepo == nil {
+		return nil, status.Error(codes.Unimplemented, "repository imports are not available")
+	}
+	importID, err := uuid.Parse(req.ImportId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid import_id")
+	}
+	imp, err := s.importRepo.GetByID(ctx, importID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "repository import not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to look up repository import")
+	}
+	if imp.AgentID.String() != req.AgentId {
+		return nil, status.Error(codes.PermissionDenied, "repository scan was requested from another agent")
+	}
+
+	snapshots := make([]scannedSnapshot, len(req.Snapshots))
+	for i, snap := range req.Snapshots {
+		snapshots[i] = scannedSnapshot{
+			SnapshotID: snap.SnapshotId,
+			Time:       snap.Time.AsTime().UTC(),
+			Hostname:   snap.Hostname,
+			Paths:      snap.Paths,
+			Tags:       snap.Tags,
+			SizeBytes:  snap.SizeBytes,
+			FileCount:  snap.FileCount,
+		}
+	}
+	snapshotsJSON, err := json.Marshal(snapshots)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to encode snapshots")
+	}
+
+	if err := s.importRepo.CompleteScan(ctx, importID, string(snapshotsJSON), req.Error); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, status.Error(codes.FailedPrecondition, "repository scan is already completed")
+		}
+		s.logger.Error("ReportRepositoryScan: failed to complete repository scan",
+			zap.String("import_id", req.ImportId),
+			zap.Error(err),
+		)
+		return nil, status.Error(codes.Internal, "failed to complete repository scan")
+	}
+
+	if req.Error == "" && s.repoRepo != nil {
+		if err := s.repoRepo.MarkInitialized(ctx, imp.RepositoryID, time.Now().UTC()); err != nil {
+			s.logger.Warn("ReportRepositoryScan: failed to mark repository initialized",
+				zap.String("repository_id", imp.RepositoryID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	s.logger.Info("repository scan completed",
+		zap.String("import_id", req.ImportId),
+		zap.Int("snapshots", len(snapshots)),
+		zap.Bool("failed", req.Error != ""),
+	)
+	return &proto.RepositoryScanResponse{Ok: true}, nil
+}
+
+// dryRunDirectory and dryRunUnreadable are the JSON shapes stored in
+// DryRunResult.LargestDirectories and DryRunResult.UnreadablePaths.
+type dryRunDirectory struct {
+	Path  string `json:"path"`
+	Bytes uint64 `json:"bytes"`
+	Files uint64 `json:"files"`
+}
+
+type dryRunUnreadable struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// ReportDryRun persists the result of a JOB_TYPE_DRY_RUN job. The agent calls
+// it once, just before reporting the job as completed.
+func (s *Server) ReportDryRun(ctx context.Context, req *proto.DryRunReport) (*proto.DryRunResponse, error) {
+	jobID, err := uuid.Parse(req.JobId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid job_id")
+	}
+
+	dirs := make([]dryRunDirectory, 0, len(req.LargestDirectories))
+	for _, d := range req.LargestDirectories {
+		dirs = append(dirs, dryRunDirectory{Path: d.Path, Bytes: d.Bytes, Files: d.Files})
+	}
+	unreadable := make([]dryRunUnreadable, 0, len(req.UnreadablePaths))
+	for _, u := range req.UnreadablePaths {
+		unreadable = append(unreadable, dryRunUnreadable{Path: u.Path, Error: u.Error})
+	}
+	dirsJSON, err := json.Marshal(dirs)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to encode largest directories")
+	}
+	unreadableJSON, err := json.Marshal(unreadable)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to encode unreadable paths")
+	}
+
+	result := &db.DryRunResult{
+		JobID:              jobID,
+		TotalFiles:         int64(req.TotalFiles),
+		TotalBytes:         int64(req.TotalBytes),
+		DataAdded:          int64(req.DataAdded),
+		LargestDirectories: string(dirsJSON),
+		UnreadablePaths:    string(unreadableJSON),
+		UnreadableCount:    int64(req.UnreadableCount),
+	}
+	if err := s.jobRepo.SaveDryRunResult(ctx, result); err != nil {
+		s.logger.Error("ReportDryRun: failed to save dry-run result",
+			zap.String("job_id", req.JobId),
+			zap.Error(err),
+		)
+		return nil, status.Error(codes.Internal, "failed to save dry-run result")
+	}
+	s.touchJobActivity(ctx, jobID, time.Now().UTC())
+
+	s.logger.Info("dry-run result stored",
+		zap.String("job_id", req.JobId),
+		zap.String("agent_id", req.AgentId),
+		zap.Uint64("total_files", req.TotalFiles),
+		zap.Uint64("total_bytes", req.TotalBytes),
+		zap.Uint64("unreadable_count", req.UnreadableCount),
+	)
+
+	return &proto.DryRunResponse{Ok: true}, nil
+}
+
+// ReportProgress stores the latest progress snapshot of a running job and
+// relays it to the GUI. The agent throttles reports, so every one of them is
+// persisted and published.
+func (s *Server) ReportProgress(ctx context.Context, req *proto.JobProgress) (*proto.JobProgressResponse, error) {
+	jobID, err := uuid.Parse(req.JobId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid job_id")
+	}
+
+	progress := &db.JobProgress{
+		JobID:            jobID,
+		DestinationID:    req.DestinationId,
+		PercentDone:      req.PercentDone,
+		FilesDone:        int64(req.FilesDone),
+		TotalFiles:       int64(req.TotalFiles),
+		BytesDone:        int64(req.BytesDone),
+		TotalBytes:       int64(req.TotalBytes),
+		CurrentFile:      req.CurrentFile,
+		SecondsRemaining: int64(req.SecondsRemaining),
+		SecondsElapsed:   int64(req.SecondsElapsed),
+	}
+	if err := s.jobRepo.SaveProgress(ctx, progress); err != nil {
+		s.logger.Error("ReportProgress: failed to save progress",
+			zap.String("job_id", req.JobId),
+			zap.Error(err),
+		)
+		return nil, status.Error(codes.Internal, "failed to save progress")
+	}
+	s.touchJobActivity(ctx, jobID, time.Now().UTC())
+
+	s.hub.Publish("job:"+req.JobId, websocket.Message{
+		Type:    websocket.MsgJobProgress,
+		Payload: progressPayload(progress),
+	})
+
+	return &proto.JobProgressResponse{Ok: true}, nil
+}
+
+// progressPayload is the job.progress WebSocket payload.
+func progressPayload(p *db.JobProgress) map[string]any {
+	return map[string]any{
+		"job_id":            p.JobID.String(),
+		"destination_id":    p.DestinationID,
+		"percent_done":      p.PercentDone,
+		"files_done":        p.FilesDone,
+		"total_files":       p.TotalFiles,
+		"bytes_done":        p.BytesDone,
+		"total_bytes":       p.TotalBytes,
+		"current_file":      p.CurrentFile,
+		"seconds_remaining": p.SecondsRemaining,
+		"seconds_elapsed":   p.SecondsElapsed,
+	}
 }
 
 // ReportVolumeList receives the Docker volume list from an agent in response
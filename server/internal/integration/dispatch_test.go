@@ -9,6 +9,7 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/arkeep-io/arkeep/server/internal/db"
+	"github.com/arkeep-io/arkeep/server/internal/repositories"
 	proto "github.com/arkeep-io/arkeep/shared/proto"
 )
 
@@ -145,6 +146,96 @@ func TestJobDispatchFailed(t *testing.T) {
 	waitForJobStatus(t, ts.jobRepo, job.ID.String(), "failed")
 }
 
+// TestDestinationWarnings verifies that a destination reported as
+// "succeeded_with_warnings" keeps its snapshot and stores the per-file errors.
+func TestDestinationWarnings(t *testing.T) {
+	ts := newTestServer(t)
+	agent := newFakeAgent(t, ts.addr)
+	agentID := agent.register(t)
+	ctx := context.Background()
+
+	job := &db.Job{PolicyID: uuid.New(), AgentID: mustParseUUID(t, agentID), Type: "backup", Status: "running"}
+	if err := ts.jobRepo.Create(ctx, job); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	destID := uuid.New()
+	if err := ts.jobRepo.CreateDestination(ctx, &db.JobDestination{JobID: job.ID, DestinationID: destID}); err != nil {
+		t.Fatalf("create job destination: %v", err)
+	}
+
+	_, err := agent.client.ReportDestinationStatus(ctx, &proto.DestinationStatusReport{
+		JobId:         job.ID.String(),
+		AgentId:       agentID,
+		DestinationId: destID.String(),
+		Status:        "succeeded_with_warnings",
+		SnapshotId:    "abc123",
+		StartedAt:     timestamppb.Now(),
+		FileErrors: []*proto.FileError{
+			{Path: "/data/locked", During: "archival", Message: "permission denied"},
+		},
+		FileErrorCount: 3,
+	})
+	if err != nil {
+		t.Fatalf("ReportDestinationStatus: %v", err)
+	}
+
+	dests, err := ts.jobRepo.ListDestinationsByJob(ctx, job.ID)
+	if err != nil || len(dests) != 1 {
+		t.Fatalf("ListDestinationsByJob = %v, %v", dests, err)
+	}
+	d := dests[0]
+	if d.Status != "succeeded_with_warnings" || d.SnapshotID != "abc123" {
+		t.Errorf("destination = %s snapshot %q, want succeeded_with_warnings abc123", d.Status, d.SnapshotID)
+	}
+	want := `[{"path":"/data/locked","during":"archival","message":"permission denied"}]`
+	if d.FileErrorCount != 3 || d.FileErrors != want {
+		t.Errorf("file errors = %d %s, want 3 %s", d.FileErrorCount, d.FileErrors, want)
+	}
+}
+
+// TestJobPartial verifies that a failed backup that still stored a snapshot
+// on one of its destinations ends as "partial", and as "failed" when no
+// destination got one.
+func TestJobPartial(t *testing.T) {
+	ts := newTestServer(t)
+	agent := newFakeAgent(t, ts.addr)
+	agentID := agent.register(t)
+	ctx := context.Background()
+
+	runJob := func(outcomes ...string) string {
+		job := &db.Job{PolicyID: uuid.New(), AgentID: mustParseUUID(t, agentID), Type: "backup", Status: "running"}
+		if err := ts.jobRepo.Create(ctx, job); err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		for _, outcome := range outcomes {
+			destID := uuid.New()
+			if err := ts.jobRepo.CreateDestination(ctx, &db.JobDestination{JobID: job.ID, DestinationID: destID}); err != nil {
+				t.Fatalf("create job destination: %v", err)
+			}
+			report := &proto.DestinationStatusReport{
+				JobId:         job.ID.String(),
+				AgentId:       agentID,
+				DestinationId: destID.String(),
+				Status:        outcome,
+				StartedAt:     timestamppb.Now(),
+			}
+			if outcome == "succeeded" {
+				report.SnapshotId = "snap-" + destID.String()
+			} else {
+				report.Error = "connection refused"
+			}
+			if _, err := agent.client.ReportDestinationStatus(ctx, report); err != nil {
+				t.Fatalf("ReportDestinationStatus: %v", err)
+			}
+		}
+		agent.reportStatus(t, job.ID.String(), proto.JobStatus_JOB_STATUS_FAILED)
+		return job.ID.String()
+	}
+
+	waitForJobStatus(t, ts.jobRepo, runJob("succeeded", "failed"), "partial")
+	waitForJobStatus(t, ts.jobRepo, runJob("failed", "failed"), "failed")
+}
+
 // TestDispatchToOfflineAgent verifies that dispatching to an agent that has
 // no open stream returns an error immediately (no blocking).
 func TestDispatchToOfflineAgent(t *testing.T) {
@@ -172,3 +263,72 @@ func timeoutCtx(t *testing.T, seconds int) context.Context {
 	t.Cleanup(cancel)
 	return ctx
 }
+
+// TestReplicationCopies verifies that the snapshots a replication job
+// reports are added to the catalog once, linked to their originals.
+func TestReplicationCopies(t *testing.T) {
+	ts := newTestServer(t)
+	agent := newFakeAgent(t, ts.addr)
+	agentID := agent.register(t)
+	ctx := context.Background()
+
+	sourceID, targetID := uuid.New(), uuid.New()
+	backup := &db.Policy{Name: "nas", AgentID: mustParseUUID(t, agentID), Sources: "[]", RepoPassword: "secret"}
+	if err := ts.policyRepo.Create(ctx, backup); err != nil {
+		t.Fatalf("create backup policy: %v", err)
+	}
+	replication := &db.Policy{Name: "nas-to-b2", AgentID: backup.AgentID, Sources: "[]", RepoPassword: "secret", Type: "replication", ReplicateFromID: &sourceID}
+	if err := ts.policyRepo.Create(ctx, replication); err != nil {
+		t.Fatalf("create replication policy: %v", err)
+	}
+	original := &db.Snapshot{PolicyID: backup.ID, DestinationID: sourceID, JobID: uuid.New(), SnapshotID: "orig1", SizeBytes: 1024, Tags: "[]", SnapshotAt: time.Now().UTC().Add(-time.Hour)}
+	if err := ts.snapshotRepo.Create(ctx, original); err != nil {
+		t.Fatalf("create original snapshot: %v", err)
+	}
+
+	otherPolicy := uuid.New()
+	for range 2 {
+		job := &db.Job{PolicyID: replication.ID, AgentID: backup.AgentID, Type: "replication", Status: "running"}
+		if err := ts.jobRepo.Create(ctx, job); err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		if err := ts.jobRepo.CreateDestination(ctx, &db.JobDestination{JobID: job.ID, DestinationID: targetID}); err != nil {
+			t.Fatalf("create job destination: %v", err)
+		}
+		// The agent reports every copy the target holds, on each run.
+		_, err := agent.client.ReportDestinationStatus(ctx, &proto.DestinationStatusReport{
+			JobId:         job.ID.String(),
+			AgentId:       agentID,
+			DestinationId: targetID.String(),
+			Status:        "succeeded",
+			StartedAt:     timestamppb.Now(),
+			CopiedSnapshots: []*proto.CopiedSnapshot{
+				{SnapshotId: "copy1", OriginalId: "orig1"},
+				{SnapshotId: "copy2", OriginalId: "uncataloged", Tags: []string{"policy:" + otherPolicy.String()}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("ReportDestinationStatus: %v", err)
+		}
+	}
+
+	copied, total, err := ts.snapshotRepo.ListByDestination(ctx, targetID, repositories.ListOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListByDestination: %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("copied snapshots = %d, want 2", total)
+	}
+	for _, s := range copied {
+		switch s.SnapshotID {
+		case "copy1":
+			if s.OriginalID == nil || *s.OriginalID != original.ID || s.PolicyID != backup.ID || s.SizeBytes != 1024 {
+				t.Errorf("copy1 = %+v, want a copy of %s in policy %s", s.Snapshot, original.ID, backup.ID)
+			}
+		case "copy2":
+			if s.OriginalID != nil || s.PolicyID != otherPolicy {
+				t.Errorf("copy2 = %+v, want no original and the policy of its tag", s.Snapshot)
+			}
+		}
+	}
+}
@@ -49,7 +49,10 @@ type testServer struct {
 	agentMgr  *agentmanager.Manager
 	agentRepo repositories.AgentRepository
 	jobRepo   repositories.JobRepository
-	cancel    context.CancelFunc // cancels the server context → graceful stop
+	// policyRepo and snapshotRepo back the replication catalog tests.
+	policyRepo   repositories.PolicyRepository
+	snapshotRepo repositories.SnapshotRepository
+	cancel       context.CancelFunc // cancels the server context → graceful stop
 }
 
 // newTestServer starts a real gRPC server on a free loopback port backed by a
@@ -70,11 +73,12 @@ func newTestServer(t *testing.T) *testServer {
 	agentRepo := repositories.NewAgentRepository(gdb)
 	jobRepo := repositories.NewJobRepository(gdb)
 	snapshotRepo := repositories.NewSnapshotRepository(gdb)
+	policyRepo := repositories.NewPolicyRepository(gdb)
 	agentMgr := agentmanager.New(zap.NewNop())
 	hub := websocket.NewHub()
 
 	srv := grpcserver.New(
-		grpcserver.Config{SharedSecret: testAgentSecret},
+		grpcserver.Config{SharedSecret: testAgentSecret, Policies: policyRepo},
 		agentMgr,
 		agentRepo,
 		jobRepo,
@@ -93,11 +97,13 @@ func newTestServer(t *testing.T) *testServer {
 	go func() { _ = srv.Serve(ctx, lis) }()
 
 	ts := &testServer{
-		addr:      lis.Addr().String(),
-		agentMgr:  agentMgr,
-		agentRepo: agentRepo,
-		jobRepo:   jobRepo,
-		cancel:    cancel,
+		addr:         lis.Addr().String(),
+		agentMgr:     agentMgr,
+		agentRepo:    agentRepo,
+		jobRepo:      jobRepo,
+		policyRepo:   policyRepo,
+		snapshotRepo: snapshotRepo,
+		cancel:       cancel,
 	}
 
 	t.Cleanup(func() {
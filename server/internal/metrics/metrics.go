@@ -26,7 +26,7 @@ import (
 // Metrics holds all custom Prometheus metric collectors.
 type Metrics struct {
 	// JobsTotal counts completed jobs, partitioned by terminal status and job type.
-	// Incremented once per job on first terminal state (succeeded / failed / cancelled).
+	// Incremented once per job on first terminal state (succeeded / failed / partial / cancelled).
 	JobsTotal *prometheus.CounterVec
 
 	// JobDurationSeconds records job wall-clock duration in seconds for jobs
@@ -14,6 +14,7 @@ import (
 	"github.com/arkeep-io/arkeep/server/internal/db"
 	"github.com/arkeep-io/arkeep/server/internal/repositories"
 	"github.com/arkeep-io/arkeep/server/internal/websocket"
+	"github.com/arkeep-io/arkeep/shared/types"
 )
 
 // Service is the single entry point for creating and delivering notifications.
@@ -21,7 +22,8 @@ import (
 // WebSocket Hub, and fans out to external channels (email, webhook).
 //
 // Callers (scheduler, gRPC handlers, etc.) should use the typed methods
-// (NotifyJobSucceeded, NotifyJobFailed, NotifyAgentOffline) rather than
+// (NotifyJobSucceeded, NotifyJobFailed, NotifyBackupPartial, NotifyJobMissed,
+// NotifyAgentOffline, NotifyBackupAnomaly) rather than
 // constructing events manually, so that notification content stays consistent
 // across the codebase.
 type Service interface {
@@ -33,9 +35,31 @@ type Service interface {
 	// errMsg is the error string from the backup engine, included in the body.
 	NotifyJobFailed(ctx context.Context, jobID, policyID uuid.UUID, policyName, errMsg string) error
 
+	// NotifyJobWarnings creates the notification for a job that completed
+	// with warnings: restic created every snapshot but could not read some
+	// source files. asFailure sends it as a failure notification instead of
+	// a success notification, per the policy's setting.
+	NotifyJobWarnings(ctx context.Context, jobID, policyID uuid.UUID, policyName, warning string, asFailure bool) error
+
+	// NotifyBackupPartial creates a notification for a backup that stored a
+	// snapshot on some of its destinations but failed on the others. failed
+	// and succeeded hold destination names.
+	NotifyBackupPartial(ctx context.Context, jobID, policyID uuid.UUID, policyName string, failed, succeeded []string, errMsg string) error
+
 	// NotifyAgentOffline creates a notification when an agent stops sending
 	// heartbeats and is marked offline by the agent manager.
 	NotifyAgentOffline(ctx context.Context, agentID uuid.UUID, agentName string) error
+
+	// NotifyJobMissed creates a notification when a scheduled run did not
+	// happen: the server was down at fire time, or the agent stayed offline
+	// until the pending job expired. reason is included in the body.
+	NotifyJobMissed(ctx context.Context, jobID, policyID uuid.UUID, policyName, reason string) error
+
+	// NotifyBackupAnomaly creates a notification when a backup run to a
+	// destination changed far more files than the recent runs of the policy.
+	// heldSnapshotID is the known-good snapshot protected from retention, or
+	// empty when none was held.
+	NotifyBackupAnomaly(ctx context.Context, jobID, policyID uuid.UUID, policyName, destinationName, reason, heldSnapshotID string) error
 }
 
 // NotificationService is the concrete implementation of Service.
@@ -139,6 +163,44 @@ func (s *NotificationService) NotifyJobFailed(ctx context.Context, jobID, policy
 	})
 }
 
+func (s *NotificationService) NotifyJobWarnings(ctx context.Context, jobID, policyID uuid.UUID, policyName, warning string, asFailure bool) error {
+	payload := map[string]any{
+		"job_id":      jobID.String(),
+		"policy_id":   policyID.String(),
+		"policy_name": policyName,
+		"warning":     warning,
+	}
+	notifType := "job_success"
+	if asFailure {
+		notifType = "job_failure"
+		payload["error"] = warning
+	}
+	return s.notify(ctx, event{
+		notifType: notifType,
+		title:     fmt.Sprintf("Backup completed with warnings: %s", policyName),
+		body:      fmt.Sprintf("Policy \"%s\" completed at %s with warnings: %s", policyName, time.Now().UTC().Format(time.RFC3339), warning),
+		payload:   payload,
+	})
+}
+
+func (s *NotificationService) NotifyBackupPartial(ctx context.Context, jobID, policyID uuid.UUID, policyName string, failed, succeeded []string, errMsg string) error {
+	payload := map[string]any{
+		"job_id":                 jobID.String(),
+		"policy_id":              policyID.String(),
+		"policy_name":            policyName,
+		"failed_destinations":    failed,
+		"succeeded_destinations": succeeded,
+		"error":                  errMsg,
+	}
+	return s.notify(ctx, event{
+		notifType: string(types.NotificationEventBackupPartial),
+		title:     fmt.Sprintf("Backup partially failed: %s", policyName),
+		body: fmt.Sprintf("Policy \"%s\" failed on %s at %s. Snapshots were still stored on %s.",
+			policyName, strings.Join(failed, ", "), time.Now().UTC().Format(time.RFC3339), strings.Join(succeeded, ", ")),
+		payload: payload,
+	})
+}
+
 func (s *NotificationService) NotifyAgentOffline(ctx context.Context, agentID uuid.UUID, agentName string) error {
 	payload := map[string]any{
 		"agent_id":   agentID.String(),
@@ -152,6 +214,42 @@ func (s *NotificationService) NotifyAgentOffline(ctx context.Context, agentID uu
 	})
 }
 
+func (s *NotificationService) NotifyJobMissed(ctx context.Context, jobID, policyID uuid.UUID, policyName, reason string) error {
+	payload := map[string]any{
+		"job_id":      jobID.String(),
+		"policy_id":   policyID.String(),
+		"policy_name": policyName,
+		"reason":      reason,
+	}
+	return s.notify(ctx, event{
+		notifType: "job_missed",
+		title:     fmt.Sprintf("Backup missed: %s", policyName),
+		body:      fmt.Sprintf("Policy \"%s\" did not run: %s", policyName, reason),
+		payload:   payload,
+	})
+}
+
+func (s *NotificationService) NotifyBackupAnomaly(ctx context.Context, jobID, policyID uuid.UUID, policyName, destinationName, reason, heldSnapshotID string) error {
+	payload := map[string]any{
+		"job_id":           jobID.String(),
+		"policy_id":        policyID.String(),
+		"policy_name":      policyName,
+		"destination_name": destinationName,
+		"reason":           reason,
+		"held_snapshot_id": heldSnapshotID,
+	}
+	body := fmt.Sprintf("The backup of policy \"%s\" to \"%s\" looks anomalous: %s. This is the typical signature of ransomware encrypting the sources.", policyName, destinationName, reason)
+	if heldSnapshotID != "" {
+		body += fmt.Sprintf(" Snapshot %s, the last known-good one, is held and will not be removed by retention.", shortID(heldSnapshotID))
+	}
+	return s.notify(ctx, event{
+		notifType: "backup_anomaly",
+		title:     fmt.Sprintf("Backup anomaly: %s", policyName),
+		body:      body,
+		payload:   payload,
+	})
+}
+
 // -----------------------------------------------------------------------------
 // Internal event dispatch
 // -----------------------------------------------------------------------------
@@ -432,6 +530,14 @@ func (s *NotificationService) configuredRecipients(ctx context.Context) []string
 	return out
 }
 
+// shortID returns the 8-character short form of a restic snapshot ID.
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
 // truncateError returns the error message truncated to 500 characters to avoid
 // storing unbounded data in the last_error column.
 func truncateError(err error) string {
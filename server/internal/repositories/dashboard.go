@@ -24,6 +24,7 @@ type DashboardStats struct {
 	JobsTodayTotal     int64
 	JobsTodaySucceeded int64
 	JobsTodayFailed    int64
+	JobsTodayPartial   int64
 
 	// Snapshot totals (all time)
 	SnapshotsTotal     int64
@@ -34,11 +35,13 @@ type DashboardStats struct {
 	SizeActivity []DaySizeActivity
 }
 
-// DayJobActivity holds the succeeded and failed job counts for a single calendar day.
+// DayJobActivity holds the succeeded, failed and partial job counts for a
+// single calendar day.
 type DayJobActivity struct {
 	Date      string // "YYYY-MM-DD"
 	Succeeded int64
 	Failed    int64
+	Partial   int64
 }
 
 // DaySizeActivity holds the total bytes backed up for a single calendar day,
@@ -131,6 +134,11 @@ func (r *gormDashboardRepository) GetStats(ctx context.Context) (*DashboardStats
 		return nil, fmt.Errorf("dashboard: jobs today failed: %w", err)
 	}
 
+	if err := d.Raw(`SELECT COUNT(*) FROM jobs WHERE created_at >= ? AND created_at < ? AND status = 'partial'`, todayStart, todayEnd).
+		Scan(&stats.JobsTodayPartial).Error; err != nil {
+		return nil, fmt.Errorf("dashboard: jobs today partial: %w", err)
+	}
+
 	// ── Snapshots ────────────────────────────────────────────────────────────
 
 	if err := d.Raw(`SELECT COUNT(*) FROM snapshots`).
@@ -163,7 +171,7 @@ func (r *gormDashboardRepository) GetStats(ctx context.Context) (*DashboardStats
 		       COUNT(*) AS count
 		FROM jobs
 		WHERE created_at >= ?
-		  AND status IN ('succeeded', 'failed')
+		  AND status IN ('succeeded', 'failed', 'partial')
 		GROUP BY %s, status
 		ORDER BY date ASC
 	`, dateExpr, dateExpr), weekStart).Scan(&jobRows).Error; err != nil {
@@ -171,13 +179,16 @@ func (r *gormDashboardRepository) GetStats(ctx context.Context) (*DashboardStats
 	}
 
 	// Build a map for quick lookup, then materialise the 7-day slice.
-	type dayCounts struct{ succeeded, failed int64 }
+	type dayCounts struct{ succeeded, failed, partial int64 }
 	jobMap := make(map[string]dayCounts)
 	for _, row := range jobRows {
 		c := jobMap[row.Date]
-		if row.Status == "succeeded" {
+		switch row.Status {
+		case "succeeded":
 			c.succeeded = row.Count
-		} else {
+		case "partial":
+			c.partial = row.Count
+		default:
 			c.failed = row.Count
 		}
 		jobMap[row.Date] = c
@@ -192,6 +203,7 @@ func (r *gormDashboardRepository) GetStats(ctx context.Context) (*DashboardStats
 			Date:      day,
 			Succeeded: c.succeeded,
 			Failed:    c.failed,
+			Partial:   c.partial,
 		}
 	}
 
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+// gormDatabaseConnectionRepository is the GORM implementation of
+// DatabaseConnectionRepository.
+type gormDatabaseConnectionRepository struct {
+	db *gorm.DB
+}
+
+// NewDatabaseConnectionRepository returns a DatabaseConnectionRepository
+// backed by the provided *gorm.DB.
+func NewDatabaseConnectionRepository(db *gorm.DB) DatabaseConnectionRepository {
+	return &gormDatabaseConnectionRepository{db: db}
+}
+
+// Create inserts a new connection record. Password is automatically
+// encrypted by EncryptedString.Value().
+func (r *gormDatabaseConnectionRepository) Create(ctx context.Context, conn *db.DatabaseConnection) error {
+	if err := r.db.WithContext(ctx).Create(conn).Error; err != nil {
+		return fmt.Errorf("database_connections: create: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a connection by its UUID.
+// Returns ErrNotFound if no record exists.
+func (r *gormDatabaseConnectionRepository) GetByID(ctx context.Context, id uuid.UUID) (*db.DatabaseConnection, error) {
+	var conn db.DatabaseConnection
+	err := r.db.WithContext(ctx).First(&conn, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("database_connections: get by id: %w", err)
+	}
+	return &conn, nil
+}
+
+// Update persists all fields of an existing connection record.
+func (r *gormDatabaseConnectionRepository) Update(ctx context.Context, conn *db.DatabaseConnection) error {
+	result := r.db.WithContext(ctx).Save(conn)
+	if result.Error != nil {
+		return fmt.Errorf("database_connections: update: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete permanently removes a connection record by ID. Callers should
+// check CountPolicies first.
+// Returns ErrNotFound if no record exists.
+func (r *gormDatabaseConnectionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&db.DatabaseConnection{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("database_connections: delete: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List returns a paginated list of connections and the total count.
+func (r *gormDatabaseConnectionRepository) List(ctx context.Context, opts ListOptions) ([]db.DatabaseConnection, int64, error) {
+	var conns []db.DatabaseConnection
+	var total int64
+
+	if err := r.db.WithContext(ctx).Model(&db.DatabaseConnection{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("database_connections: list count: %w", err)
+	}
+
+	if err := r.db.WithContext(ctx).
+		Limit(opts.Limit).
+		Offset(opts.Offset).
+		Order("created_at ASC").
+		Find(&conns).Error; err != nil {
+		return nil, 0, fmt.Errorf("database_connections: list: %w", err)
+	}
+
+	return conns, total, nil
+}
+
+// CountPolicies returns the number of non-deleted policies whose sources
+// JSON references the connection. Sources are stored as JSON text, so the
+// connection ID is matched as a substring: UUIDs do not collide with other
+// source fields.
+func (r *gormDatabaseConnectionRepository) CountPolicies(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&db.Policy{}).
+		Where("sources LIKE ?", "%"+id.String()+"%").
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("database_connections: count policies: %w", err)
+	}
+	return count, nil
+}
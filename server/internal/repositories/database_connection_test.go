@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+func TestDatabaseConnectionCountPolicies(t *testing.T) {
+	gormDB := newTestDB(t)
+	conns := NewDatabaseConnectionRepository(gormDB)
+	policies := NewPolicyRepository(gormDB)
+	ctx := context.Background()
+
+	conn := &db.DatabaseConnection{Name: "pg", Engine: "postgres", Host: "localhost", Username: "backup", Password: "secret"}
+	if err := conns.Create(ctx, conn); err != nil {
+		t.Fatalf("Create connection: %v", err)
+	}
+	got, err := conns.GetByID(ctx, conn.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Password != "secret" {
+		t.Errorf("password = %q, want the decrypted password", got.Password)
+	}
+
+	// Two policies dump through the connection, the second one is deleted;
+	// a third one only backs up files.
+	sources := []string{
+		`[{"type":"database","connection_id":"` + conn.ID.String() + `","database":"app"}]`,
+		`[{"type":"database","connection_id":"` + conn.ID.String() + `","database":"wiki"}]`,
+		`[{"type":"path","path":"/srv"}]`,
+	}
+	var ids []uuid.UUID
+	for _, src := range sources {
+		p := &db.Policy{Name: "p", AgentID: uuid.New(), Schedule: "0 2 * * *", Sources: src, RepoPassword: "pw"}
+		if err := policies.Create(ctx, p); err != nil {
+			t.Fatalf("Create policy: %v", err)
+		}
+		ids = append(ids, p.ID)
+	}
+	if err := policies.Delete(ctx, ids[1]); err != nil {
+		t.Fatalf("Delete policy: %v", err)
+	}
+
+	count, err := conns.CountPolicies(ctx, conn.ID)
+	if err != nil {
+		t.Fatalf("CountPolicies: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("CountPolicies = %d, want 1", count)
+	}
+}
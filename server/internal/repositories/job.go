@@ -9,6 +9,7 @@ import (
 	"github.com/arkeep-io/arkeep/server/internal/db"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // gormJobRepository is the GORM implementation of JobRepository.
@@ -154,6 +155,145 @@ func (r *gormJobRepository) FailRunningJobsForAgent(ctx context.Context, agentID
 	return result.RowsAffected, nil
 }
 
+// ListPendingBefore returns all jobs still in "pending" state that were created
+// before the given time, oldest first. Used by the scheduler to expire jobs
+// whose agent never came back online to pick them up.
+func (r *gormJobRepository) ListPendingBefore(ctx context.Context, before time.Time) ([]db.Job, error) {
+	var jobs []db.Job
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND created_at < ?", "pending", before).
+		Order("created_at ASC").
+		Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("jobs: list pending before: %w", err)
+	}
+	return jobs, nil
+}
+
+// SetFailureClass records why the agent reported the job as failed.
+// Returns ErrNotFound if no job matches.
+func (r *gormJobRepository) SetFailureClass(ctx context.Context, id uuid.UUID, failureClass string) error {
+	result := r.db.WithContext(ctx).
+		Model(&db.Job{}).
+		Where("id = ?", id).
+		Update("failure_class", failureClass)
+	if result.Error != nil {
+		return fmt.Errorf("jobs: set failure class: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// maxRetryChainLength bounds the walk in ListRetryChain so that a corrupt
+// retry_of_id cycle can never loop forever. It is well above the highest
+// accepted Policy.RetryMaxAttempts.
+const maxRetryChainLength = 100
+
+// ListRetryChain returns every attempt of the run the given job belongs to,
+// ordered by attempt: the original job first, then each retry. A job that
+// was never retried yields a single-element chain.
+// Returns ErrNotFound if the job does not exist.
+func (r *gormJobRepository) ListRetryChain(ctx context.Context, id uuid.UUID) ([]db.Job, error) {
+	job, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk back to the original attempt.
+	chain := []db.Job{*job}
+	for len(chain) < maxRetryChainLength && chain[0].RetryOfID != nil {
+		prev, err := r.GetByID(ctx, *chain[0].RetryOfID)
+		if errors.Is(err, ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jobs: list retry chain: %w", err)
+		}
+		chain = append([]db.Job{*prev}, chain...)
+	}
+
+	// Walk forward through the retries.
+	for len(chain) < maxRetryChainLength {
+		var next db.Job
+		err := r.db.WithContext(ctx).
+			Where("retry_of_id = ?", chain[len(chain)-1].ID).
+			Order("created_at ASC").
+			First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jobs: list retry chain: %w", err)
+		}
+		chain = append(chain, next)
+	}
+	return chain, nil
+}
+
+// ListScheduledRetries returns pending retry jobs whose backoff ends after
+// the given time, soonest first. Used by the scheduler on startup to re-arm
+// the timers of retries scheduled before a restart.
+func (r *gormJobRepository) ListScheduledRetries(ctx context.Context, after time.Time) ([]db.Job, error) {
+	var jobs []db.Job
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND not_before > ?", "pending", after).
+		Order("not_before ASC").
+		Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("jobs: list scheduled retries: %w", err)
+	}
+	return jobs, nil
+}
+
+// TouchActivity records agent activity on a job by setting last_activity_at.
+// Returns ErrNotFound if no job matches.
+func (r *gormJobRepository) TouchActivity(ctx context.Context, id uuid.UUID, at time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&db.Job{}).
+		Where("id = ?", id).
+		Update("last_activity_at", at)
+	if result.Error != nil {
+		return fmt.Errorf("jobs: touch activity: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListRunningInactiveSince returns all jobs in "running" state whose last
+// activity is older than the given time, oldest first. Jobs that never
+// reported activity fall back to started_at, then created_at. Used by the
+// scheduler's watchdog to find jobs whose agent hung without disconnecting.
+func (r *gormJobRepository) ListRunningInactiveSince(ctx context.Context, before time.Time) ([]db.Job, error) {
+	var jobs []db.Job
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND COALESCE(last_activity_at, started_at, created_at) < ?", "running", before).
+		Order("created_at ASC").
+		Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("jobs: list running inactive since: %w", err)
+	}
+	return jobs, nil
+}
+
+// FailRunningJob marks a single job as "failed" with the given error message,
+// but only while it is still "running" — a terminal report from the agent
+// that arrives first wins. Returns whether the job was failed.
+func (r *gormJobRepository) FailRunningJob(ctx context.Context, id uuid.UUID, errMsg string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&db.Job{}).
+		Where("id = ? AND status = ?", id, "running").
+		Updates(map[string]interface{}{
+			"status":   "failed",
+			"ended_at": time.Now().UTC(),
+			"error":    errMsg,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("jobs: fail running job: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // JobWithNames extends db.Job with denormalised policy and agent names.
 // Populated via LEFT JOIN in the List* methods so the API can return
 // display-ready responses without per-row lookups. LEFT JOIN ensures jobs
@@ -330,6 +470,97 @@ func (r *gormJobRepository) UpdateDestinationStatus(ctx context.Context, id uuid
 	return nil
 }
 
+// ChangeStats holds the change statistics of a backup run to one
+// destination, as reported in the restic summary.
+type ChangeStats struct {
+	FilesNew        int64
+	FilesChanged    int64
+	FilesUnmodified int64
+	TotalFiles      int64
+	DataAdded       int64
+}
+
+// SetDestinationChangeStats records the change statistics of a successful
+// backup run to a destination.
+func (r *gormJobRepository) SetDestinationChangeStats(ctx context.Context, id uuid.UUID, stats ChangeStats) error {
+	result := r.db.WithContext(ctx).
+		Model(&db.JobDestination{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"files_new":        stats.FilesNew,
+			"files_changed":    stats.FilesChanged,
+			"files_unmodified": stats.FilesUnmodified,
+			"total_files":      stats.TotalFiles,
+			"data_added":       stats.DataAdded,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("jobs: set destination change stats: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetDestinationFileErrors records the files restic could not read in a
+// backup run to a destination. fileErrors is a JSON array, count the full
+// number of errors.
+func (r *gormJobRepository) SetDestinationFileErrors(ctx context.Context, id uuid.UUID, fileErrors string, count int64) error {
+	result := r.db.WithContext(ctx).
+		Model(&db.JobDestination{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"file_errors":      fileErrors,
+			"file_error_count": count,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("jobs: set destination file errors: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkDestinationAnomaly flags a backup run to a destination as anomalous.
+func (r *gormJobRepository) MarkDestinationAnomaly(ctx context.Context, id uuid.UUID, reason string) error {
+	result := r.db.WithContext(ctx).
+		Model(&db.JobDestination{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"anomaly":        true,
+			"anomaly_reason": reason,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("jobs: mark destination anomaly: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListBaselineRuns returns the most recent successful, non-anomalous backup
+// runs of a policy to a destination, newest first. Runs with warnings count
+// as successful. Anomalous runs are left out so that an attack in progress
+// does not raise the baseline it is compared with.
+func (r *gormJobRepository) ListBaselineRuns(ctx context.Context, policyID, destinationID, excludeID uuid.UUID, limit int) ([]db.JobDestination, error) {
+	var runs []db.JobDestination
+	if err := r.db.WithContext(ctx).
+		Model(&db.JobDestination{}).
+		Select("job_destinations.*").
+		Joins("JOIN jobs ON jobs.id = job_destinations.job_id").
+		Where("jobs.policy_id = ? AND jobs.type = ?", policyID, "backup").
+		Where("job_destinations.destination_id = ? AND job_destinations.id <> ?", destinationID, excludeID).
+		Where("job_destinations.status IN ? AND job_destinations.anomaly = ?", []string{"succeeded", "succeeded_with_warnings"}, false).
+		Order("job_destinations.ended_at DESC").
+		Limit(limit).
+		Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("jobs: list baseline runs: %w", err)
+	}
+	return runs, nil
+}
+
 // -----------------------------------------------------------------------------
 // JobLog
 // -----------------------------------------------------------------------------
@@ -360,3 +591,68 @@ func (r *gormJobRepository) GetLogs(ctx context.Context, jobID uuid.UUID) ([]db.
 	return logs, nil
 }
 
+
+// -----------------------------------------------------------------------------
+// DryRunResult
+// -----------------------------------------------------------------------------
+
+// SaveDryRunResult stores the result of a dry-run job, replacing any result
+// already stored for it (e.g. a duplicate report after an agent reconnect).
+func (r *gormJobRepository) SaveDryRunResult(ctx context.Context, result *db.DryRunResult) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("job_id = ?", result.JobID).Delete(&db.DryRunResult{}).Error; err != nil {
+			return fmt.Errorf("jobs: save dry run result: %w", err)
+		}
+		if err := tx.Create(result).Error; err != nil {
+			return fmt.Errorf("jobs: save dry run result: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetDryRunResult returns the result of a dry-run job.
+// Returns ErrNotFound if the agent has not reported one.
+func (r *gormJobRepository) GetDryRunResult(ctx context.Context, jobID uuid.UUID) (*db.DryRunResult, error) {
+	var result db.DryRunResult
+	if err := r.db.WithContext(ctx).First(&result, "job_id = ?", jobID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("jobs: get dry run result: %w", err)
+	}
+	return &result, nil
+}
+
+// -----------------------------------------------------------------------------
+// JobProgress
+// -----------------------------------------------------------------------------
+
+// SaveProgress stores the latest progress snapshot of a job, overwriting the
+// previous one. Called for every progress report, so it is a single upsert.
+func (r *gormJobRepository) SaveProgress(ctx context.Context, progress *db.JobProgress) error {
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "job_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"updated_at", "destination_id", "percent_done", "files_done", "total_files",
+				"bytes_done", "total_bytes", "current_file", "seconds_remaining", "seconds_elapsed",
+			}),
+		}).
+		Create(progress).Error; err != nil {
+		return fmt.Errorf("jobs: save progress: %w", err)
+	}
+	return nil
+}
+
+// GetProgress returns the latest progress snapshot of a job.
+// Returns ErrNotFound if the agent has not reported progress.
+func (r *gormJobRepository) GetProgress(ctx context.Context, jobID uuid.UUID) (*db.JobProgress, error) {
+	var progress db.JobProgress
+	if err := r.db.WithContext(ctx).First(&progress, "job_id = ?", jobID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("jobs: get progress: %w", err)
+	}
+	return &progress, nil
+}
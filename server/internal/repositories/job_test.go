@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+func TestListPendingBefore(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewJobRepository(gormDB)
+	ctx := context.Background()
+
+	policyID, agentID := uuid.New(), uuid.New()
+	newJob := func(status string, createdAt time.Time) *db.Job {
+		j := &db.Job{PolicyID: policyID, AgentID: agentID, Status: status}
+		j.CreatedAt = createdAt
+		if err := repo.Create(ctx, j); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		return j
+	}
+
+	now := time.Now().UTC()
+	old := newJob("pending", now.Add(-48*time.Hour))
+	newJob("pending", now.Add(-time.Hour))
+	newJob("failed", now.Add(-72*time.Hour))
+
+	jobs, err := repo.ListPendingBefore(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("ListPendingBefore: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != old.ID {
+		t.Fatalf("ListPendingBefore() returned %d jobs, want only %s", len(jobs), old.ID)
+	}
+
+	// "missed" must be accepted by the jobs table now that the status CHECK
+	// constraint is gone.
+	if err := repo.UpdateStatus(ctx, old.ID, "missed", nil, &now, "agent offline"); err != nil {
+		t.Fatalf("UpdateStatus(missed): %v", err)
+	}
+	jobs, err = repo.ListPendingBefore(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("ListPendingBefore: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Errorf("after marking missed: got %d jobs, want 0", len(jobs))
+	}
+}
+
+func TestListRetryChain(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewJobRepository(gormDB)
+	ctx := context.Background()
+
+	policyID, agentID := uuid.New(), uuid.New()
+	first := &db.Job{PolicyID: policyID, AgentID: agentID, Status: "failed"}
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	second := &db.Job{PolicyID: policyID, AgentID: agentID, Status: "failed", Attempt: 2, RetryOfID: &first.ID}
+	if err := repo.Create(ctx, second); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	third := &db.Job{PolicyID: policyID, AgentID: agentID, Status: "pending", Attempt: 3, RetryOfID: &second.ID}
+	if err := repo.Create(ctx, third); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// The whole chain is returned in attempt order from any of its members.
+	for _, id := range []uuid.UUID{first.ID, second.ID, third.ID} {
+		chain, err := repo.ListRetryChain(ctx, id)
+		if err != nil {
+			t.Fatalf("ListRetryChain: %v", err)
+		}
+		if len(chain) != 3 || chain[0].ID != first.ID || chain[1].ID != second.ID || chain[2].ID != third.ID {
+			t.Errorf("ListRetryChain(%s) returned %d jobs, want first, second, third", id, len(chain))
+		}
+	}
+	if chain, _ := repo.ListRetryChain(ctx, first.ID); chain[0].Attempt != 1 {
+		t.Errorf("first attempt = %d, want the column default 1", chain[0].Attempt)
+	}
+
+	if err := repo.SetFailureClass(ctx, second.ID, "network"); err != nil {
+		t.Fatalf("SetFailureClass: %v", err)
+	}
+	got, err := repo.GetByID(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.FailureClass != "network" {
+		t.Errorf("FailureClass = %q, want network", got.FailureClass)
+	}
+}
+
+func TestListRunningInactiveSince(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewJobRepository(gormDB)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	newRunning := func(lastActivity time.Time) *db.Job {
+		j := &db.Job{PolicyID: uuid.New(), AgentID: uuid.New(), Status: "running"}
+		if err := repo.Create(ctx, j); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := repo.TouchActivity(ctx, j.ID, lastActivity); err != nil {
+			t.Fatalf("TouchActivity: %v", err)
+		}
+		return j
+	}
+	silent := newRunning(now.Add(-3 * time.Hour))
+	newRunning(now.Add(-time.Minute))
+
+	jobs, err := repo.ListRunningInactiveSince(ctx, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("ListRunningInactiveSince: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != silent.ID {
+		t.Fatalf("ListRunningInactiveSince() returned %d jobs, want only %s", len(jobs), silent.ID)
+	}
+
+	// FailRunningJob only fails jobs that are still running.
+	failed, err := repo.FailRunningJob(ctx, silent.ID, "stuck")
+	if err != nil || !failed {
+		t.Fatalf("FailRunningJob = %v, %v, want true", failed, err)
+	}
+	failed, err = repo.FailRunningJob(ctx, silent.ID, "stuck")
+	if err != nil || failed {
+		t.Errorf("second FailRunningJob = %v, %v, want false", failed, err)
+	}
+}
+
+func TestSaveDryRunResult(t *testing.T) {
+	repo := NewJobRepository(newTestDB(t))
+	ctx := context.Background()
+
+	job := &db.Job{PolicyID: uuid.New(), AgentID: uuid.New(), Type: "dry_run", Status: "running"}
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.GetDryRunResult(ctx, job.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetDryRunResult() before report error = %v, want ErrNotFound", err)
+	}
+
+	// A second report for the same job replaces the first.
+	for _, files := range []int64{10, 20} {
+		if err := repo.SaveDryRunResult(ctx, &db.DryRunResult{
+			JobID:              job.ID,
+			TotalFiles:         files,
+			TotalBytes:         files * 1024,
+			LargestDirectories: `[{"path":"/data","bytes":1024,"files":1}]`,
+			UnreadablePaths:    `[]`,
+		}); err != nil {
+			t.Fatalf("SaveDryRunResult: %v", err)
+		}
+	}
+	got, err := repo.GetDryRunResult(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetDryRunResult: %v", err)
+	}
+	if got.TotalFiles != 20 || got.TotalBytes != 20*1024 {
+		t.Errorf("GetDryRunResult() = %+v, want the second report", got)
+	}
+}
+
+func TestSaveProgress(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewJobRepository(gormDB)
+	ctx := context.Background()
+
+	job := &db.Job{PolicyID: uuid.New(), AgentID: uuid.New(), Type: "backup", Status: "running"}
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.GetProgress(ctx, job.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetProgress() before report error = %v, want ErrNotFound", err)
+	}
+
+	// Only the latest snapshot is kept: later reports overwrite the row.
+	for _, pct := range []float64{0.25, 0.5} {
+		if err := repo.SaveProgress(ctx, &db.JobProgress{
+			JobID:       job.ID,
+			PercentDone: pct,
+			FilesDone:   int64(pct * 100),
+			TotalFiles:  100,
+			CurrentFile: "/data/file",
+		}); err != nil {
+			t.Fatalf("SaveProgress: %v", err)
+		}
+	}
+	got, err := repo.GetProgress(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetProgress: %v", err)
+	}
+	if got.PercentDone != 0.5 || got.FilesDone != 50 {
+		t.Errorf("GetProgress() = %+v, want the second report", got)
+	}
+	var count int64
+	if err := gormDB.Model(&db.JobProgress{}).Where("job_id = ?", job.ID).Count(&count).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("job_progress rows = %d, want 1", count)
+	}
+}
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+// gormKeyRepository is the GORM implementation of KeyRepository.
+type gormKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewKeyRepository returns a KeyRepository backed by the provided *gorm.DB.
+func NewKeyRepository(db *gorm.DB) KeyRepository {
+	return &gormKeyRepository{db: db}
+}
+
+// SaveEscrowKit upserts the escrow kit of an agent, keyed by agent_id.
+func (r *gormKeyRepository) SaveEscrowKit(ctx context.Context, kit *db.KeyEscrowKit) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "agent_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"escrow_key_fingerprint", "kit", "key_count", "updated_at"}),
+		}).
+		Create(kit).Error
+	if err != nil {
+		return fmt.Errorf("key_escrow_kits: save: %w", err)
+	}
+	return nil
+}
+
+// GetEscrowKit retrieves the escrow kit of an agent.
+// Returns ErrNotFound if no record exists.
+func (r *gormKeyRepository) GetEscrowKit(ctx context.Context, agentID uuid.UUID) (*db.KeyEscrowKit, error) {
+	var kit db.KeyEscrowKit
+	err := r.db.WithContext(ctx).First(&kit, "agent_id = ?", agentID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("key_escrow_kits: get: %w", err)
+	}
+	return &kit, nil
+}
+
+// CreateShare inserts a new key share.
+func (r *gormKeyRepository) CreateShare(ctx context.Context, share *db.KeyShare) error {
+	if err := r.db.WithContext(ctx).Create(share).Error; err != nil {
+		return fmt.Errorf("key_shares: create: %w", err)
+	}
+	return nil
+}
+
+// GetShare retrieves a key share by its UUID.
+// Returns ErrNotFound if no record exists.
+func (r *gormKeyRepository) GetShare(ctx context.Context, id uuid.UUID) (*db.KeyShare, error) {
+	var share db.KeyShare
+	err := r.db.WithContext(ctx).First(&share, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("key_shares: get by id: %w", err)
+	}
+	return &share, nil
+}
+
+// ListSharesByPolicy returns every key share of a policy, newest first.
+func (r *gormKeyRepository) ListSharesByPolicy(ctx context.Context, policyID uuid.UUID) ([]db.KeyShare, error) {
+	var shares []db.KeyShare
+	err := r.db.WithContext(ctx).
+		Where("policy_id = ?", policyID).
+		Order("created_at DESC").
+		Find(&shares).Error
+	if err != nil {
+		return nil, fmt.Errorf("key_shares: list by policy: %w", err)
+	}
+	return shares, nil
+}
+
+// ListPendingShares returns the pending shares of keys held by an agent,
+// oldest first.
+func (r *gormKeyRepository) ListPendingShares(ctx context.Context, fromAgentID uuid.UUID) ([]db.KeyShare, error) {
+	var shares []db.KeyShare
+	err := r.db.WithContext(ctx).
+		Where("from_agent_id = ? AND status = ?", fromAgentID, "pending").
+		Order("created_at ASC").
+		Find(&shares).Error
+	if err != nil {
+		return nil, fmt.Errorf("key_shares: list pending: %w", err)
+	}
+	return shares, nil
+}
+
+// GetSealedShare returns the most recent sealed share of a key with an
+// agent. Returns ErrNotFound if there is none.
+func (r *gormKeyRepository) GetSealedShare(ctx context.Context, keyID string, toAgentID uuid.UUID) (*db.KeyShare, error) {
+	var share db.KeyShare
+	err := r.db.WithContext(ctx).
+		Where("key_id = ? AND to_agent_id = ? AND status = ?", keyID, toAgentID, "sealed").
+		Order("created_at DESC").
+		First(&share).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("key_shares: get sealed: %w", err)
+	}
+	return &share, nil
+}
+
+// CompleteShare moves a pending share to "sealed" with the sealed key, or
+// to "failed" with errMsg when it is set.
+// Returns ErrNotFound if the share does not exist or is not pending.
+func (r *gormKeyRepository) CompleteShare(ctx context.Context, id uuid.UUID, sealedKey, errMsg string) error {
+	updates := map[string]any{"status": "sealed", "sealed_key": sealedKey, "error": ""}
+	if errMsg != "" {
+		updates = map[string]any{"status": "failed", "sealed_key": "", "error": errMsg}
+	}
+	result := r.db.WithContext(ctx).
+		Model(&db.KeyShare{}).
+		Where("id = ? AND status = ?", id, "pending").
+		Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("key_shares: complete: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
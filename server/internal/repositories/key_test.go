@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+func TestKeyRepository_EscrowKit(t *testing.T) {
+	gormDB := newTestDB(t)
+	keys := NewKeyRepository(gormDB)
+	agents := NewAgentRepository(gormDB)
+	ctx := context.Background()
+
+	agent := &db.Agent{Name: "web", Hostname: "web"}
+	if err := agents.Create(ctx, agent); err != nil {
+		t.Fatalf("Create agent: %v", err)
+	}
+	if _, err := keys.GetEscrowKit(ctx, agent.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetEscrowKit before save: err = %v, want ErrNotFound", err)
+	}
+
+	for _, n := range []int{1, 2} {
+		kit := &db.KeyEscrowKit{AgentID: agent.ID, EscrowKeyFingerprint: "fp", Kit: "sealed", KeyCount: n}
+		if err := keys.SaveEscrowKit(ctx, kit); err != nil {
+			t.Fatalf("SaveEscrowKit: %v", err)
+		}
+	}
+	got, err := keys.GetEscrowKit(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("GetEscrowKit: %v", err)
+	}
+	if got.KeyCount != 2 {
+		t.Errorf("key_count = %d, want the latest kit's 2", got.KeyCount)
+	}
+}
+
+func TestKeyRepository_Shares(t *testing.T) {
+	gormDB := newTestDB(t)
+	keys := NewKeyRepository(gormDB)
+	agents := NewAgentRepository(gormDB)
+	policies := NewPolicyRepository(gormDB)
+	ctx := context.Background()
+
+	from := &db.Agent{Name: "web", Hostname: "web"}
+	to := &db.Agent{Name: "dr", Hostname: "dr"}
+	for _, a := range []*db.Agent{from, to} {
+		if err := agents.Create(ctx, a); err != nil {
+			t.Fatalf("Create agent: %v", err)
+		}
+	}
+	p := &db.Policy{Name: "p", AgentID: from.ID, Schedule: "0 2 * * *", Sources: `["/data"]`, ZeroKnowledge: true}
+	if err := policies.Create(ctx, p); err != nil {
+		t.Fatalf("Create policy: %v", err)
+	}
+	pd := db.PolicyDestination{PolicyID: p.ID, DestinationID: uuid.New()}
+
+	newShare := func() *db.KeyShare {
+		share := &db.KeyShare{
+			PolicyID:           p.ID,
+			DestinationID:      pd.DestinationID,
+			KeyID:              pd.KeyID(),
+			FromAgentID:        from.ID,
+			ToAgentID:          to.ID,
+			RecipientPublicKey: "pub",
+			KeyFingerprint:     "fp",
+			Status:             "pending",
+			RequestedBy:        uuid.New(),
+		}
+		if err := keys.CreateShare(ctx, share); err != nil {
+			t.Fatalf("CreateShare: %v", err)
+		}
+		return share
+	}
+	failed, sealed := newShare(), newShare()
+
+	pending, err := keys.ListPendingShares(ctx, from.ID)
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("ListPendingShares = %d, %v; want 2", len(pending), err)
+	}
+
+	if err := keys.CompleteShare(ctx, failed.ID, "", "key not found"); err != nil {
+		t.Fatalf("CompleteShare (failed): %v", err)
+	}
+	if _, err := keys.GetSealedShare(ctx, pd.KeyID(), to.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSealedShare with only a failed share: err = %v, want ErrNotFound", err)
+	}
+	if err := keys.CompleteShare(ctx, sealed.ID, "c2VhbGVk", ""); err != nil {
+		t.Fatalf("CompleteShare (sealed): %v", err)
+	}
+	// A share is completed once.
+	if err := keys.CompleteShare(ctx, sealed.ID, "", "late error"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("CompleteShare twice: err = %v, want ErrNotFound", err)
+	}
+
+	got, err := keys.GetSealedShare(ctx, pd.KeyID(), to.ID)
+	if err != nil {
+		t.Fatalf("GetSealedShare: %v", err)
+	}
+	if got.ID != sealed.ID || got.SealedKey != "c2VhbGVk" {
+		t.Errorf("sealed share = %+v, want %s with its key", got, sealed.ID)
+	}
+	if pending, _ := keys.ListPendingShares(ctx, from.ID); len(pending) != 0 {
+		t.Errorf("pending shares = %d after completion, want 0", len(pending))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/arkeep-io/arkeep/server/internal/db"
@@ -31,6 +32,24 @@ func (r *gormPolicyRepository) Create(ctx context.Context, policy *db.Policy) er
 	return nil
 }
 
+// CreateDisabled inserts a new policy with Enabled false. GORM's Create
+// skips zero-value booleans and falls back to the column default (true), so
+// the policy is disabled in the same transaction: it is never visible, nor
+// scheduled, as enabled.
+func (r *gormPolicyRepository) CreateDisabled(ctx context.Context, policy *db.Policy) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(policy).Error; err != nil {
+			return err
+		}
+		return tx.Model(&db.Policy{}).Where("id = ?", policy.ID).Update("enabled", false).Error
+	})
+	if err != nil {
+		return fmt.Errorf("policies: create disabled: %w", err)
+	}
+	policy.Enabled = false
+	return nil
+}
+
 // GetByID retrieves a policy by its UUID. Soft-deleted policies are excluded.
 // Returns ErrNotFound if no record exists.
 func (r *gormPolicyRepository) GetByID(ctx context.Context, id uuid.UUID) (*db.Policy, error) {
@@ -181,6 +200,23 @@ func (r *gormPolicyRepository) UpdateSchedule(ctx context.Context, id uuid.UUID,
 	return nil
 }
 
+// UpdateNextRun updates next_run_at only. Called by the scheduler when a
+// policy is (re)scheduled, so the GUI shows the upcoming run before the
+// policy has ever executed.
+func (r *gormPolicyRepository) UpdateNextRun(ctx context.Context, id uuid.UUID, nextRunAt *time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&db.Policy{}).
+		Where("id = ?", id).
+		Update("next_run_at", nextRunAt)
+	if result.Error != nil {
+		return fmt.Errorf("policies: update next run: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 // -----------------------------------------------------------------------------
 // PolicyDestination
 // -----------------------------------------------------------------------------
@@ -224,4 +260,154 @@ func (r *gormPolicyRepository) UpdateDestinationPriority(ctx context.Context, po
 		return ErrNotFound
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// SetDestinationKeyFingerprint records the key fingerprint of a policy
+// destination unless one is already recorded.
+func (r *gormPolicyRepository) SetDestinationKeyFingerprint(ctx context.Context, policyID, destinationID uuid.UUID, fingerprint string) error {
+	err := r.db.WithContext(ctx).
+		Model(&db.PolicyDestination{}).
+		Where("policy_id = ? AND destination_id = ? AND key_fingerprint = ''", policyID, destinationID).
+		Update("key_fingerprint", fingerprint).Error
+	if err != nil {
+		return fmt.Errorf("policies: set destination key fingerprint: %w", err)
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// PolicyDependency
+// -----------------------------------------------------------------------------
+
+// ListDependencies returns the dependencies of a policy, i.e. the policies it
+// runs after, oldest first.
+func (r *gormPolicyRepository) ListDependencies(ctx context.Context, policyID uuid.UUID) ([]db.PolicyDependency, error) {
+	var deps []db.PolicyDependency
+	if err := r.db.WithContext(ctx).
+		Where("policy_id = ?", policyID).
+		Order("created_at ASC").
+		Find(&deps).Error; err != nil {
+		return nil, fmt.Errorf("policies: list dependencies: %w", err)
+	}
+	return deps, nil
+}
+
+// ListDependents returns the dependencies that point at a policy, i.e. one
+// row per policy that runs after it.
+func (r *gormPolicyRepository) ListDependents(ctx context.Context, policyID uuid.UUID) ([]db.PolicyDependency, error) {
+	var deps []db.PolicyDependency
+	if err := r.db.WithContext(ctx).
+		Where("depends_on_id = ?", policyID).
+		Order("created_at ASC").
+		Find(&deps).Error; err != nil {
+		return nil, fmt.Errorf("policies: list dependents: %w", err)
+	}
+	return deps, nil
+}
+
+// ListAllDependencies returns every dependency whose two policies are not
+// soft-deleted. Soft deletes do not cascade, so rows of deleted policies are
+// filtered here rather than removed.
+func (r *gormPolicyRepository) ListAllDependencies(ctx context.Context) ([]db.PolicyDependency, error) {
+	live := r.db.Model(&db.Policy{}).Select("id")
+	var deps []db.PolicyDependency
+	if err := r.db.WithContext(ctx).
+		Where("policy_id IN (?) AND depends_on_id IN (?)", live, live).
+		Find(&deps).Error; err != nil {
+		return nil, fmt.Errorf("policies: list all dependencies: %w", err)
+	}
+	return deps, nil
+}
+
+// SetDependencies replaces all dependencies of a policy in a single
+// transaction. PolicyID of each entry is overwritten with policyID.
+func (r *gormPolicyRepository) SetDependencies(ctx context.Context, policyID uuid.UUID, deps []db.PolicyDependency) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("policy_id = ?", policyID).Delete(&db.PolicyDependency{}).Error; err != nil {
+			return err
+		}
+		for i := range deps {
+			deps[i].PolicyID = policyID
+			if err := tx.Create(&deps[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("policies: set dependencies: %w", err)
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// PolicySchedule
+// -----------------------------------------------------------------------------
+
+// ListSchedules returns the additional schedule entries of a policy, oldest
+// first.
+func (r *gormPolicyRepository) ListSchedules(ctx context.Context, policyID uuid.UUID) ([]db.PolicySchedule, error) {
+	var schedules []db.PolicySchedule
+	if err := r.db.WithContext(ctx).
+		Where("policy_id = ?", policyID).
+		Order("created_at ASC").
+		Find(&schedules).Error; err != nil {
+		return nil, fmt.Errorf("policies: list schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// GetSchedule returns a single schedule entry by its UUID.
+// Returns ErrNotFound if no record exists.
+func (r *gormPolicyRepository) GetSchedule(ctx context.Context, id uuid.UUID) (*db.PolicySchedule, error) {
+	var schedule db.PolicySchedule
+	err := r.db.WithContext(ctx).First(&schedule, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("policies: get schedule: %w", err)
+	}
+	return &schedule, nil
+}
+
+// SetSchedules replaces all schedule entries of a policy in a single
+// transaction. Entries whose ID matches an existing entry of the policy are
+// updated in place, so that jobs keep pointing at them; all other entries
+// get a new ID. Existing entries missing from schedules are deleted.
+func (r *gormPolicyRepository) SetSchedules(ctx context.Context, policyID uuid.UUID, schedules []db.PolicySchedule) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []uuid.UUID
+		if err := tx.Model(&db.PolicySchedule{}).
+			Where("policy_id = ?", policyID).
+			Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+
+		ids := make([]uuid.UUID, 0, len(schedules))
+		for i := range schedules {
+			schedules[i].PolicyID = policyID
+			if schedules[i].ID != uuid.Nil && slices.Contains(existing, schedules[i].ID) {
+				if err := tx.Save(&schedules[i]).Error; err != nil {
+					return err
+				}
+			} else {
+				schedules[i].ID = uuid.Nil
+				if err := tx.Create(&schedules[i]).Error; err != nil {
+					return err
+				}
+			}
+			ids = append(ids, schedules[i].ID)
+		}
+
+		stale := tx.Where("policy_id = ?", policyID)
+		if len(ids) > 0 {
+			stale = stale.Where("id NOT IN ?", ids)
+		}
+		return stale.Delete(&db.PolicySchedule{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("policies: set schedules: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"bytes"
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/google/uuid"
@@ -79,3 +80,129 @@ func TestActivePoliciesCount(t *testing.T) {
 		t.Errorf("after soft-delete: ActivePoliciesCount() = %d, want 2", got)
 	}
 }
+
+func TestCreateDisabled(t *testing.T) {
+	repo := NewPolicyRepository(newTestDB(t))
+	ctx := context.Background()
+
+	p := &db.Policy{Name: "draft", AgentID: uuid.New(), Schedule: "@daily", Sources: `["/data"]`}
+	if err := repo.CreateDisabled(ctx, p); err != nil {
+		t.Fatalf("CreateDisabled: %v", err)
+	}
+	if p.Enabled {
+		t.Error("policy.Enabled = true after CreateDisabled, want false")
+	}
+	got, err := repo.GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Enabled {
+		t.Error("stored policy is enabled, want disabled")
+	}
+	if got := repo.ActivePoliciesCount(ctx); got != 0 {
+		t.Errorf("ActivePoliciesCount() = %d, want 0", got)
+	}
+}
+
+func TestSetDependencies(t *testing.T) {
+	repo := NewPolicyRepository(newTestDB(t))
+	ctx := context.Background()
+
+	agentID := uuid.New()
+	var a, b, c db.Policy
+	for _, p := range []*db.Policy{&a, &b, &c} {
+		*p = db.Policy{Name: "test-policy", AgentID: agentID, Schedule: "0 2 * * *", Enabled: true, Sources: `["/data"]`}
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	// c runs after a and b; replacing the set keeps only the new entries.
+	if err := repo.SetDependencies(ctx, c.ID, []db.PolicyDependency{{DependsOnID: a.ID, Condition: "success"}}); err != nil {
+		t.Fatalf("SetDependencies: %v", err)
+	}
+	if err := repo.SetDependencies(ctx, c.ID, []db.PolicyDependency{
+		{DependsOnID: a.ID, Condition: "always"},
+		{DependsOnID: b.ID, Condition: "failure"},
+	}); err != nil {
+		t.Fatalf("SetDependencies (replace): %v", err)
+	}
+	deps, err := repo.ListDependencies(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("ListDependencies: %v", err)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("ListDependencies() returned %d entries, want 2", len(deps))
+	}
+
+	dependents, err := repo.ListDependents(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("ListDependents: %v", err)
+	}
+	if len(dependents) != 1 || dependents[0].PolicyID != c.ID || dependents[0].Condition != "always" {
+		t.Errorf("ListDependents(a) = %+v, want c on always", dependents)
+	}
+
+	// Dependencies of soft-deleted policies are not part of the graph.
+	if err := repo.Delete(ctx, b.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	all, err := repo.ListAllDependencies(ctx)
+	if err != nil {
+		t.Fatalf("ListAllDependencies: %v", err)
+	}
+	if len(all) != 1 || all[0].DependsOnID != a.ID {
+		t.Errorf("ListAllDependencies() = %+v, want only c -> a", all)
+	}
+}
+
+func TestSetSchedules(t *testing.T) {
+	repo := NewPolicyRepository(newTestDB(t))
+	ctx := context.Background()
+
+	p := db.Policy{Name: "test-policy", AgentID: uuid.New(), Schedule: "0 2 * * *", Enabled: true, Sources: `["/data"]`}
+	if err := repo.Create(ctx, &p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := repo.SetSchedules(ctx, p.ID, []db.PolicySchedule{
+		{Schedule: "0 * * * *", Tags: "schedule:hourly", CustomRetention: true, RetentionHourly: 48},
+		{Schedule: "0 3 * * 0", Tags: "schedule:weekly"},
+	}); err != nil {
+		t.Fatalf("SetSchedules: %v", err)
+	}
+	first, err := repo.ListSchedules(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListSchedules: %v", err)
+	}
+	if len(first) != 2 {
+		t.Fatalf("ListSchedules() returned %d entries, want 2", len(first))
+	}
+
+	// Replacing the set updates entries by ID and drops the others.
+	hourly, weekly := first[0], first[1]
+	if hourly.Tags != "schedule:hourly" {
+		hourly, weekly = weekly, hourly
+	}
+	hourly.Schedule = "30 * * * *"
+	if err := repo.SetSchedules(ctx, p.ID, []db.PolicySchedule{hourly, {Schedule: "0 4 1 * *", Tags: "schedule:monthly"}}); err != nil {
+		t.Fatalf("SetSchedules (replace): %v", err)
+	}
+	got, err := repo.ListSchedules(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListSchedules: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ListSchedules() returned %d entries, want 2", len(got))
+	}
+	kept, err := repo.GetSchedule(ctx, hourly.ID)
+	if err != nil {
+		t.Fatalf("GetSchedule: %v", err)
+	}
+	if kept.Schedule != "30 * * * *" || kept.RetentionHourly != 48 {
+		t.Errorf("GetSchedule() = %+v, want the updated hourly entry", kept)
+	}
+	if _, err := repo.GetSchedule(ctx, weekly.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSchedule(dropped) error = %v, want ErrNotFound", err)
+	}
+}
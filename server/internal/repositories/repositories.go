@@ -89,12 +89,52 @@ type DestinationRepository interface {
 	List(ctx context.Context, opts ListOptions) ([]db.Destination, int64, error)
 }
 
+// -----------------------------------------------------------------------------
+// RepositoryRepository
+// -----------------------------------------------------------------------------
+
+// RepositoryRepository manages the restic repositories policies write into.
+type RepositoryRepository interface {
+	Create(ctx context.Context, repo *db.Repository) error
+	GetByID(ctx context.Context, id uuid.UUID) (*db.Repository, error)
+	Update(ctx context.Context, repo *db.Repository) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, opts ListOptions) ([]db.Repository, int64, error)
+	// CountPolicies returns the number of policies writing into a repository.
+	CountPolicies(ctx context.Context, id uuid.UUID) (int64, error)
+	// MarkInitialized records that the repository exists in its backend. A
+	// repository already marked keeps its first InitializedAt.
+	MarkInitialized(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// -----------------------------------------------------------------------------
+// DatabaseConnectionRepository
+// -----------------------------------------------------------------------------
+
+// DatabaseConnectionRepository manages the connections of database dump
+// sources.
+type DatabaseConnectionRepository interface {
+	Create(ctx context.Context, conn *db.DatabaseConnection) error
+	GetByID(ctx context.Context, id uuid.UUID) (*db.DatabaseConnection, error)
+	Update(ctx context.Context, conn *db.DatabaseConnection) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, opts ListOptions) ([]db.DatabaseConnection, int64, error)
+	// CountPolicies returns the number of policies with a source dumping a
+	// database through the connection.
+	CountPolicies(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
 // -----------------------------------------------------------------------------
 // PolicyRepository
 // -----------------------------------------------------------------------------
 
 type PolicyRepository interface {
 	Create(ctx context.Context, policy *db.Policy) error
+
+	// CreateDisabled inserts a new policy that is disabled from the start,
+	// in a single transaction.
+	CreateDisabled(ctx context.Context, policy *db.Policy) error
+
 	GetByID(ctx context.Context, id uuid.UUID) (*db.Policy, error)
 
 	// GetByIDWithDestinations retrieves a policy together with its associated
@@ -110,6 +150,10 @@ type PolicyRepository interface {
 	ListEnabled(ctx context.Context) ([]db.Policy, error)
 	UpdateSchedule(ctx context.Context, id uuid.UUID, lastRunAt, nextRunAt time.Time) error
 
+	// UpdateNextRun sets next_run_at without touching last_run_at. nil clears
+	// the value (e.g. when a policy is disabled).
+	UpdateNextRun(ctx context.Context, id uuid.UUID, nextRunAt *time.Time) error
+
 	// ActivePoliciesCount returns the count of enabled, non-deleted policies.
 	// Used by telemetry.
 	ActivePoliciesCount(ctx context.Context) int
@@ -118,6 +162,109 @@ type PolicyRepository interface {
 	AddDestination(ctx context.Context, pd *db.PolicyDestination) error
 	RemoveDestination(ctx context.Context, policyID, destinationID uuid.UUID) error
 	UpdateDestinationPriority(ctx context.Context, policyID, destinationID uuid.UUID, priority int) error
+	// SetDestinationKeyFingerprint records the fingerprint of the agent-held
+	// key of a zero-knowledge policy on a destination. A fingerprint already
+	// recorded is kept.
+	SetDestinationKeyFingerprint(ctx context.Context, policyID, destinationID uuid.UUID, fingerprint string) error
+
+	// PolicyDependency
+	// ListDependencies returns the policies the given policy runs after.
+	ListDependencies(ctx context.Context, policyID uuid.UUID) ([]db.PolicyDependency, error)
+	// ListDependents returns the dependencies of other policies on the given one.
+	ListDependents(ctx context.Context, policyID uuid.UUID) ([]db.PolicyDependency, error)
+	// ListAllDependencies returns every dependency between non-deleted
+	// policies. Used for cycle detection.
+	ListAllDependencies(ctx context.Context) ([]db.PolicyDependency, error)
+	// SetDependencies replaces the dependencies of the given policy.
+	SetDependencies(ctx context.Context, policyID uuid.UUID, deps []db.PolicyDependency) error
+
+	// PolicySchedule
+	// ListSchedules returns the additional schedule entries of a policy.
+	ListSchedules(ctx context.Context, policyID uuid.UUID) ([]db.PolicySchedule, error)
+	// GetSchedule returns a single schedule entry by ID.
+	GetSchedule(ctx context.Context, id uuid.UUID) (*db.PolicySchedule, error)
+	// SetSchedules replaces the schedule entries of the given policy.
+	// Entries with an ID of an existing entry are updated in place.
+	SetSchedules(ctx context.Context, policyID uuid.UUID, schedules []db.PolicySchedule) error
+}
+
+// -----------------------------------------------------------------------------
+// KeyRepository
+// -----------------------------------------------------------------------------
+
+// KeyRepository stores the sealed key material of zero-knowledge policies:
+// the escrow kits of the agents and the key shares between agents. None of
+// it can be opened by the server.
+type KeyRepository interface {
+	// SaveEscrowKit replaces the escrow kit of kit.AgentID.
+	SaveEscrowKit(ctx context.Context, kit *db.KeyEscrowKit) error
+	// GetEscrowKit returns the escrow kit of an agent. Returns ErrNotFound
+	// if the agent never sent one.
+	GetEscrowKit(ctx context.Context, agentID uuid.UUID) (*db.KeyEscrowKit, error)
+
+	CreateShare(ctx context.Context, share *db.KeyShare) error
+	GetShare(ctx context.Context, id uuid.UUID) (*db.KeyShare, error)
+	ListSharesByPolicy(ctx context.Context, policyID uuid.UUID) ([]db.KeyShare, error)
+	// ListPendingShares returns the pending shares the given agent has to
+	// seal, oldest first.
+	ListPendingShares(ctx context.Context, fromAgentID uuid.UUID) ([]db.KeyShare, error)
+	// GetSealedShare returns the latest sealed share of a key with an agent.
+	// Returns ErrNotFound if there is none.
+	GetSealedShare(ctx context.Context, keyID string, toAgentID uuid.UUID) (*db.KeyShare, error)
+	// CompleteShare records the outcome of a pending share: the sealed key,
+	// or the error the agent reported. Returns ErrNotFound if the share does
+	// not exist or is no longer pending.
+	CompleteShare(ctx context.Context, id uuid.UUID, sealedKey, errMsg string) error
+}
+
+// -----------------------------------------------------------------------------
+// ImportRepository
+// -----------------------------------------------------------------------------
+
+// ImportRepository stores the imports of restic repositories created
+// outside Arkeep, from the scan to the adoption.
+type ImportRepository interface {
+	Create(ctx context.Context, imp *db.RepositoryImport) error
+	GetByID(ctx context.Context, id uuid.UUID) (*db.RepositoryImport, error)
+	// ListByRepository returns the imports of a repository, newest first.
+	ListByRepository(ctx context.Context, repositoryID uuid.UUID) ([]db.RepositoryImport, error)
+	// ListPendingScans returns the imports the given agent has to scan,
+	// oldest first.
+	ListPendingScans(ctx context.Context, agentID uuid.UUID) ([]db.RepositoryImport, error)
+	// CompleteScan records the outcome of a scan: the snapshots found, or
+	// the error the agent reported. Returns ErrNotFound if the import does
+	// not exist or is no longer scanning.
+	CompleteScan(ctx context.Context, id uuid.UUID, snapshots, errMsg string) error
+	// StartApply claims a scanned import for applying, so that concurrent
+	// applies cannot both run. Returns ErrNotFound if the import does not
+	// exist or is not scanned.
+	StartApply(ctx context.Context, id uuid.UUID) error
+	// ReleaseApply hands an import that failed to apply back to "scanned".
+	// Returns ErrNotFound if the import is not applying.
+	ReleaseApply(ctx context.Context, id uuid.UUID) error
+	// MarkApplied records that a claimed import was applied. Returns
+	// ErrNotFound if the import does not exist or is not applying.
+	MarkApplied(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// -----------------------------------------------------------------------------
+// TriggerTokenRepository
+// -----------------------------------------------------------------------------
+
+// TriggerTokenRepository manages the per-policy tokens accepted by the
+// inbound trigger webhook. Tokens are looked up by the SHA-256 hash of the
+// raw value; the raw token is never stored.
+type TriggerTokenRepository interface {
+	Create(ctx context.Context, token *db.TriggerToken) error
+	GetByID(ctx context.Context, id uuid.UUID) (*db.TriggerToken, error)
+	// GetByHash returns the token with the given hash, revoked or not.
+	GetByHash(ctx context.Context, hash string) (*db.TriggerToken, error)
+	ListByPolicy(ctx context.Context, policyID uuid.UUID) ([]db.TriggerToken, error)
+	// Rotate replaces the hash, prefix and signing secret of a token that has
+	// not been revoked, invalidating the previous value immediately.
+	Rotate(ctx context.Context, id uuid.UUID, hash, prefix string, signingSecret db.EncryptedString) error
+	Revoke(ctx context.Context, id uuid.UUID) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
 }
 
 // -----------------------------------------------------------------------------
@@ -131,6 +278,13 @@ type JobRepository interface {
     Update(ctx context.Context, job *db.Job) error
     UpdateStatus(ctx context.Context, id uuid.UUID, status string, startedAt *time.Time, endedAt *time.Time, errMsg string) error
     FailRunningJobsForAgent(ctx context.Context, agentID uuid.UUID, errMsg string) (int64, error)
+    ListPendingBefore(ctx context.Context, before time.Time) ([]db.Job, error)
+    SetFailureClass(ctx context.Context, id uuid.UUID, failureClass string) error
+    ListRetryChain(ctx context.Context, id uuid.UUID) ([]db.Job, error)
+    ListScheduledRetries(ctx context.Context, after time.Time) ([]db.Job, error)
+    TouchActivity(ctx context.Context, id uuid.UUID, at time.Time) error
+    ListRunningInactiveSince(ctx context.Context, before time.Time) ([]db.Job, error)
+    FailRunningJob(ctx context.Context, id uuid.UUID, errMsg string) (bool, error)
     List(ctx context.Context, opts ListOptions) ([]JobWithNames, int64, error)
     ListByType(ctx context.Context, jobType string, opts ListOptions) ([]JobWithNames, int64, error)
     ListByPolicy(ctx context.Context, policyID uuid.UUID, opts ListOptions) ([]JobWithNames, int64, error)
@@ -140,10 +294,25 @@ type JobRepository interface {
     CreateDestination(ctx context.Context, jd *db.JobDestination) error
     ListDestinationsByJob(ctx context.Context, jobID uuid.UUID) ([]JobDestinationWithName, error)
     UpdateDestinationStatus(ctx context.Context, id uuid.UUID, status string, startedAt *time.Time, endedAt *time.Time, snapshotID string, sizeBytes int64, errMsg string) error
+    SetDestinationChangeStats(ctx context.Context, id uuid.UUID, stats ChangeStats) error
+    SetDestinationFileErrors(ctx context.Context, id uuid.UUID, fileErrors string, count int64) error
+    MarkDestinationAnomaly(ctx context.Context, id uuid.UUID, reason string) error
+    // ListBaselineRuns returns the most recent successful, non-anomalous
+    // backup runs of a policy to a destination, newest first, leaving out
+    // the job destination excludeID.
+    ListBaselineRuns(ctx context.Context, policyID, destinationID, excludeID uuid.UUID, limit int) ([]db.JobDestination, error)
 
     // JobLog
     BulkCreateLogs(ctx context.Context, logs []db.JobLog) error
     GetLogs(ctx context.Context, jobID uuid.UUID) ([]db.JobLog, error)
+
+    // DryRunResult
+    SaveDryRunResult(ctx context.Context, result *db.DryRunResult) error
+    GetDryRunResult(ctx context.Context, jobID uuid.UUID) (*db.DryRunResult, error)
+
+    // JobProgress
+    SaveProgress(ctx context.Context, progress *db.JobProgress) error
+    GetProgress(ctx context.Context, jobID uuid.UUID) (*db.JobProgress, error)
 }
 
 // -----------------------------------------------------------------------------
@@ -153,8 +322,15 @@ type JobRepository interface {
 type SnapshotRepository interface {
 	Create(ctx context.Context, snapshot *db.Snapshot) error
 	GetByID(ctx context.Context, id uuid.UUID) (*db.Snapshot, error)
+	// GetBySnapshotID returns the record of the restic snapshot snapshotID
+	// in a destination. Returns ErrNotFound when the catalog has none.
+	GetBySnapshotID(ctx context.Context, destinationID uuid.UUID, snapshotID string) (*db.Snapshot, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	DeleteBySnapshotID(ctx context.Context, snapshotID string) error
+	// ReplaceTagged records a tag added to a snapshot of a destination.
+	// restic rewrites a snapshot when its tags change, so the record moves
+	// from oldSnapshotID to newSnapshotID.
+	ReplaceTagged(ctx context.Context, destinationID uuid.UUID, oldSnapshotID, newSnapshotID, tag string) error
 	List(ctx context.Context, opts ListOptions) ([]SnapshotWithNames, int64, error)
 	ListByPolicy(ctx context.Context, policyID uuid.UUID, opts ListOptions) ([]SnapshotWithNames, int64, error)
 	ListByDestination(ctx context.Context, destinationID uuid.UUID, opts ListOptions) ([]SnapshotWithNames, int64, error)
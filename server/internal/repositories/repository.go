@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+// gormRepositoryRepository is the GORM implementation of RepositoryRepository.
+type gormRepositoryRepository struct {
+	db *gorm.DB
+}
+
+// NewRepositoryRepository returns a RepositoryRepository backed by the provided *gorm.DB.
+func NewRepositoryRepository(db *gorm.DB) RepositoryRepository {
+	return &gormRepositoryRepository{db: db}
+}
+
+// Create inserts a new repository record. Password is automatically
+// encrypted by EncryptedString.Value().
+// Returns ErrConflict if the destination already has a repository at Path.
+func (r *gormRepositoryRepository) Create(ctx context.Context, repo *db.Repository) error {
+	if err := r.db.WithContext(ctx).Create(repo).Error; err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") || strings.Contains(err.Error(), "duplicate key") {
+			return ErrConflict
+		}
+		return fmt.Errorf("repositories: create: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a repository by its UUID.
+// Returns ErrNotFound if no record exists.
+func (r *gormRepositoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*db.Repository, error) {
+	var repo db.Repository
+	err := r.db.WithContext(ctx).First(&repo, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("repositories: get by id: %w", err)
+	}
+	return &repo, nil
+}
+
+// Update persists all fields of an existing repository record.
+func (r *gormRepositoryRepository) Update(ctx context.Context, repo *db.Repository) error {
+	result := r.db.WithContext(ctx).Save(repo)
+	if result.Error != nil {
+		return fmt.Errorf("repositories: update: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete permanently removes a repository record by ID. It does not touch
+// the data in the backend. Callers should check CountPolicies first.
+// Returns ErrNotFound if no record exists.
+func (r *gormRepositoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&db.Repository{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("repositories: delete: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List returns a paginated list of repositories and the total count.
+func (r *gormRepositoryRepository) List(ctx context.Context, opts ListOptions) ([]db.Repository, int64, error) {
+	var repos []db.Repository
+	var total int64
+
+	if err := r.db.WithContext(ctx).Model(&db.Repository{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("repositories: list count: %w", err)
+	}
+
+	if err := r.db.WithContext(ctx).
+		Limit(opts.Limit).
+		Offset(opts.Offset).
+		Order("created_at ASC").
+		Find(&repos).Error; err != nil {
+		return nil, 0, fmt.Errorf("repositories: list: %w", err)
+	}
+
+	return repos, total, nil
+}
+
+// CountPolicies returns the number of non-deleted policies with a
+// destination entry pointing at the repository.
+func (r *gormRepositoryRepository) CountPolicies(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&db.PolicyDestination{}).
+		Joins("JOIN policies ON policies.id = policy_destinations.policy_id AND policies.deleted_at IS NULL").
+		Where("policy_destinations.repository_id = ?", id).
+		Distinct("policy_destinations.policy_id").
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("repositories: count policies: %w", err)
+	}
+	return count, nil
+}
+
+// MarkInitialized sets InitializedAt unless it is already set.
+func (r *gormRepositoryRepository) MarkInitialized(ctx context.Context, id uuid.UUID, at time.Time) error {
+	err := r.db.WithContext(ctx).
+		Model(&db.Repository{}).
+		Where("id = ? AND initialized_at IS NULL", id).
+		Update("initialized_at", at).Error
+	if err != nil {
+		return fmt.Errorf("repositories: mark initialized: %w", err)
+	}
+	return nil
+}
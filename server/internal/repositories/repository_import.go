@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+// gormImportRepository is the GORM implementation of ImportRepository.
+type gormImportRepository struct {
+	db *gorm.DB
+}
+
+// NewImportRepository returns an ImportRepository backed by the provided
+// *gorm.DB.
+func NewImportRepository(db *gorm.DB) ImportRepository {
+	return &gormImportRepository{db: db}
+}
+
+// Create inserts a new repository import.
+func (r *gormImportRepository) Create(ctx context.Context, imp *db.RepositoryImport) error {
+	if err := r.db.WithContext(ctx).Create(imp).Error; err != nil {
+		return fmt.Errorf("repository_imports: create: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a repository import by its UUID.
+// Returns ErrNotFound if no record exists.
+func (r *gormImportRepository) GetByID(ctx context.Context, id uuid.UUID) (*db.RepositoryImport, error) {
+	var imp db.RepositoryImport
+	err := r.db.WithContext(ctx).First(&imp, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("repository_imports: get by id: %w", err)
+	}
+	return &imp, nil
+}
+
+// ListByRepository returns every import of a repository, newest first.
+func (r *gormImportRepository) ListByRepository(ctx context.Context, repositoryID uuid.UUID) ([]db.RepositoryImport, error) {
+	var imports []db.RepositoryImport
+	err := r.db.WithContext(ctx).
+		Where("repository_id = ?", repositoryID).
+		Order("created_at DESC").
+		Find(&imports).Error
+	if err != nil {
+		return nil, fmt.Errorf("repository_imports: list by repository: %w", err)
+	}
+	return imports, nil
+}
+
+// ListPendingScans returns the scanning imports of an agent, oldest first.
+func (r *gormImportRepository) ListPendingScans(ctx context.Context, agentID uuid.UUID) ([]db.RepositoryImport, error) {
+	var imports []db.RepositoryImport
+	err := r.db.WithContext(ctx).
+		Where("agent_id = ? AND status = ?", agentID, "scanning").
+		Order("created_at ASC").
+		Find(&imports).Error
+	if err != nil {
+		return nil, fmt.Errorf("repository_imports: list pending scans: %w", err)
+	}
+	return imports, nil
+}
+
+// CompleteScan moves a scanning import to "scanned" with the snapshots JSON,
+// or to "failed" with errMsg when it is set.
+// Returns ErrNotFound if the import does not exist or is not scanning.
+func (r *gormImportRepository) CompleteScan(ctx context.Context, id uuid.UUID, snapshots, errMsg string) error {
+	updates := map[string]any{"status": "scanned", "snapshots": snapshots, "error": ""}
+	if errMsg != "" {
+		updates = map[string]any{"status": "failed", "snapshots": "[]", "error": errMsg}
+	}
+	result := r.db.WithContext(ctx).
+		Model(&db.RepositoryImport{}).
+		Where("id = ? AND status = ?", id, "scanning").
+		Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("repository_imports: complete scan: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// StartApply moves a scanned import to "applying", claiming it for one
+// caller. Returns ErrNotFound if the import does not exist or is not scanned.
+func (r *gormImportRepository) StartApply(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&db.RepositoryImport{}).
+		Where("id = ? AND status = ?", id, "scanned").
+		Update("status", "applying")
+	if result.Error != nil {
+		return fmt.Errorf("repository_imports: start apply: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ReleaseApply moves an applying import back to "scanned" so it can be
+// applied again. Returns ErrNotFound if the import is not applying.
+func (r *gormImportRepository) ReleaseApply(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&db.RepositoryImport{}).
+		Where("id = ? AND status = ?", id, "applying").
+		Update("status", "scanned")
+	if result.Error != nil {
+		return fmt.Errorf("repository_imports: release apply: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkApplied moves an applying import to "applied".
+// Returns ErrNotFound if the import does not exist or is not applying.
+func (r *gormImportRepository) MarkApplied(ctx context.Context, id uuid.UUID, at time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&db.RepositoryImport{}).
+		Where("id = ? AND status = ?", id, "applying").
+		Updates(map[string]any{"status": "applied", "applied_at": at})
+	if result.Error != nil {
+		return fmt.Errorf("repository_imports: mark applied: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
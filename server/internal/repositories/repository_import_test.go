@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+func TestImportRepository_Lifecycle(t *testing.T) {
+	gormDB := newTestDB(t)
+	imports := NewImportRepository(gormDB)
+	agents := NewAgentRepository(gormDB)
+	dests := NewDestinationRepository(gormDB)
+	repos := NewRepositoryRepository(gormDB)
+	ctx := context.Background()
+
+	agent := &db.Agent{Name: "web", Hostname: "web"}
+	if err := agents.Create(ctx, agent); err != nil {
+		t.Fatalf("Create agent: %v", err)
+	}
+	dest := &db.Destination{Name: "nas", Type: "local", Config: `{"path":"/mnt/backup"}`}
+	if err := dests.Create(ctx, dest); err != nil {
+		t.Fatalf("Create destination: %v", err)
+	}
+	repo := &db.Repository{Name: "legacy", DestinationID: dest.ID, Path: "legacy", Password: "secret"}
+	if err := repos.Create(ctx, repo); err != nil {
+		t.Fatalf("Create repository: %v", err)
+	}
+
+	newImport := func() *db.RepositoryImport {
+		imp := &db.RepositoryImport{RepositoryID: repo.ID, AgentID: agent.ID, Status: "scanning", RequestedBy: uuid.New()}
+		if err := imports.Create(ctx, imp); err != nil {
+			t.Fatalf("Create import: %v", err)
+		}
+		return imp
+	}
+	failed, scanned := newImport(), newImport()
+
+	pending, err := imports.ListPendingScans(ctx, agent.ID)
+	if err != nil {
+		t.Fatalf("ListPendingScans: %v", err)
+	}
+	if len(pending) != 2 || pending[0].ID != failed.ID {
+		t.Fatalf("pending = %+v, want both imports, oldest first", pending)
+	}
+
+	if err := imports.CompleteScan(ctx, failed.ID, "", "wrong password"); err != nil {
+		t.Fatalf("CompleteScan failed: %v", err)
+	}
+	if err := imports.CompleteScan(ctx, scanned.ID, `[{"snapshot_id":"abc"}]`, ""); err != nil {
+		t.Fatalf("CompleteScan scanned: %v", err)
+	}
+	if err := imports.CompleteScan(ctx, scanned.ID, "[]", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("CompleteScan twice: err = %v, want ErrNotFound", err)
+	}
+
+	// Only a scanned import can be claimed, and only by one caller at a time.
+	if err := imports.StartApply(ctx, failed.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("StartApply failed import: err = %v, want ErrNotFound", err)
+	}
+	if err := imports.MarkApplied(ctx, scanned.ID, time.Now().UTC()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("MarkApplied unclaimed import: err = %v, want ErrNotFound", err)
+	}
+	if err := imports.StartApply(ctx, scanned.ID); err != nil {
+		t.Fatalf("StartApply: %v", err)
+	}
+	if err := imports.StartApply(ctx, scanned.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("StartApply twice: err = %v, want ErrNotFound", err)
+	}
+	if err := imports.ReleaseApply(ctx, scanned.ID); err != nil {
+		t.Fatalf("ReleaseApply: %v", err)
+	}
+	if err := imports.ReleaseApply(ctx, scanned.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ReleaseApply unclaimed import: err = %v, want ErrNotFound", err)
+	}
+	if err := imports.StartApply(ctx, scanned.ID); err != nil {
+		t.Fatalf("StartApply after release: %v", err)
+	}
+	if err := imports.MarkApplied(ctx, scanned.ID, time.Now().UTC()); err != nil {
+		t.Fatalf("MarkApplied: %v", err)
+	}
+	if err := imports.MarkApplied(ctx, scanned.ID, time.Now().UTC()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("MarkApplied twice: err = %v, want ErrNotFound", err)
+	}
+
+	got, err := imports.GetByID(ctx, scanned.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != "applied" || got.AppliedAt == nil || got.Snapshots != `[{"snapshot_id":"abc"}]` {
+		t.Errorf("import = %+v, want applied with the scanned snapshots", got)
+	}
+
+	list, err := imports.ListByRepository(ctx, repo.ID)
+	if err != nil {
+		t.Fatalf("ListByRepository: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != scanned.ID || list[1].Error != "wrong password" {
+		t.Errorf("imports = %+v, want both, newest first", list)
+	}
+	if pending, _ := imports.ListPendingScans(ctx, agent.ID); len(pending) != 0 {
+		t.Errorf("pending after scans = %+v, want none", pending)
+	}
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+func TestRepositoryCountPolicies(t *testing.T) {
+	gormDB := newTestDB(t)
+	repos := NewRepositoryRepository(gormDB)
+	policies := NewPolicyRepository(gormDB)
+	dests := NewDestinationRepository(gormDB)
+	ctx := context.Background()
+
+	dest := &db.Destination{Name: "minio", Type: "s3", Credentials: "{}", Config: "{}"}
+	if err := dests.Create(ctx, dest); err != nil {
+		t.Fatalf("Create destination: %v", err)
+	}
+	repo := &db.Repository{Name: "shared", DestinationID: dest.ID, Path: "shared", Password: "secret"}
+	if err := repos.Create(ctx, repo); err != nil {
+		t.Fatalf("Create repository: %v", err)
+	}
+
+	// Two policies on different agents share the repository; a third one
+	// writes to the destination root and a fourth is deleted.
+	var ids []uuid.UUID
+	for i := range 4 {
+		p := &db.Policy{Name: "p", AgentID: uuid.New(), Schedule: "0 2 * * *", Sources: `["/data"]`, RepoPassword: "pw"}
+		if err := policies.Create(ctx, p); err != nil {
+			t.Fatalf("Create policy: %v", err)
+		}
+		pd := &db.PolicyDestination{PolicyID: p.ID, DestinationID: dest.ID}
+		if i != 2 {
+			pd.RepositoryID = &repo.ID
+		}
+		if err := policies.AddDestination(ctx, pd); err != nil {
+			t.Fatalf("AddDestination: %v", err)
+		}
+		ids = append(ids, p.ID)
+	}
+	if err := policies.Delete(ctx, ids[3]); err != nil {
+		t.Fatalf("Delete policy: %v", err)
+	}
+
+	count, err := repos.CountPolicies(ctx, repo.ID)
+	if err != nil {
+		t.Fatalf("CountPolicies: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("CountPolicies = %d, want 2", count)
+	}
+}
+
+func TestRepositoryMarkInitialized(t *testing.T) {
+	gormDB := newTestDB(t)
+	repos := NewRepositoryRepository(gormDB)
+	dests := NewDestinationRepository(gormDB)
+	ctx := context.Background()
+
+	dest := &db.Destination{Name: "local", Type: "local", Credentials: "{}", Config: "{}"}
+	if err := dests.Create(ctx, dest); err != nil {
+		t.Fatalf("Create destination: %v", err)
+	}
+	repo := &db.Repository{Name: "repo", DestinationID: dest.ID, Password: "secret"}
+	if err := repos.Create(ctx, repo); err != nil {
+		t.Fatalf("Create repository: %v", err)
+	}
+
+	first := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := repos.MarkInitialized(ctx, repo.ID, first); err != nil {
+		t.Fatalf("MarkInitialized: %v", err)
+	}
+	if err := repos.MarkInitialized(ctx, repo.ID, first.Add(time.Hour)); err != nil {
+		t.Fatalf("MarkInitialized again: %v", err)
+	}
+
+	got, err := repos.GetByID(ctx, repo.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.InitializedAt == nil || !got.InitializedAt.Equal(first) {
+		t.Errorf("InitializedAt = %v, want %v", got.InitializedAt, first)
+	}
+	if string(got.Password) != "secret" {
+		t.Errorf("Password = %q, want the decrypted value", got.Password)
+	}
+}
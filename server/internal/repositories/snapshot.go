@@ -2,8 +2,10 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/arkeep-io/arkeep/server/internal/db"
 	"github.com/google/uuid"
@@ -53,6 +55,22 @@ func (r *gormSnapshotRepository) GetByID(ctx context.Context, id uuid.UUID) (*db
 	return &snapshot, nil
 }
 
+// GetBySnapshotID retrieves the record of an engine snapshot in a destination.
+// Returns ErrNotFound if no record exists.
+func (r *gormSnapshotRepository) GetBySnapshotID(ctx context.Context, destinationID uuid.UUID, snapshotID string) (*db.Snapshot, error) {
+	var snapshot db.Snapshot
+	err := r.db.WithContext(ctx).
+		Where("destination_id = ? AND snapshot_id = ?", destinationID, snapshotID).
+		First(&snapshot).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("snapshots: get by snapshot id: %w", err)
+	}
+	return &snapshot, nil
+}
+
 // Delete permanently removes a snapshot record by ID.
 // Note: this only removes the cached record from the database — the actual
 // snapshot in the backup engine must be deleted separately via the backup
@@ -155,4 +173,48 @@ func (r *gormSnapshotRepository) DeleteBySnapshotID(ctx context.Context, snapsho
 		return ErrNotFound
 	}
 	return nil
-}
\ No newline at end of file
+}
+// ReplaceTagged records that tag was added to a snapshot of a destination.
+// restic rewrites a snapshot when its tags change, so the snapshot record and
+// the job destination that created it move from oldSnapshotID to
+// newSnapshotID. Returns ErrNotFound when no snapshot record matches.
+func (r *gormSnapshotRepository) ReplaceTagged(ctx context.Context, destinationID uuid.UUID, oldSnapshotID, newSnapshotID, tag string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var snap db.Snapshot
+		if err := tx.
+			Where("destination_id = ? AND snapshot_id = ?", destinationID, oldSnapshotID).
+			First(&snap).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("snapshots: replace tagged: %w", err)
+		}
+
+		var tags []string
+		if err := json.Unmarshal([]byte(snap.Tags), &tags); err != nil {
+			tags = nil
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+		tagsJSON, err := json.Marshal(tags)
+		if err != nil {
+			return fmt.Errorf("snapshots: replace tagged: %w", err)
+		}
+
+		if err := tx.Model(&db.Snapshot{}).
+			Where("id = ?", snap.ID).
+			Updates(map[string]interface{}{
+				"snapshot_id": newSnapshotID,
+				"tags":        string(tagsJSON),
+			}).Error; err != nil {
+			return fmt.Errorf("snapshots: replace tagged: %w", err)
+		}
+		if err := tx.Model(&db.JobDestination{}).
+			Where("destination_id = ? AND snapshot_id = ?", destinationID, oldSnapshotID).
+			Update("snapshot_id", newSnapshotID).Error; err != nil {
+			return fmt.Errorf("snapshots: replace tagged: update job destination: %w", err)
+		}
+		return nil
+	})
+}
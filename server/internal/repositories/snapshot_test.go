@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+func TestReplaceTagged(t *testing.T) {
+	gormDB := newTestDB(t)
+	snapshots := NewSnapshotRepository(gormDB)
+	jobs := NewJobRepository(gormDB)
+	ctx := context.Background()
+
+	destID := uuid.New()
+	job := &db.Job{PolicyID: uuid.New(), AgentID: uuid.New(), Type: "backup", Status: "succeeded"}
+	if err := jobs.Create(ctx, job); err != nil {
+		t.Fatalf("Create job: %v", err)
+	}
+	jd := &db.JobDestination{JobID: job.ID, DestinationID: destID, SnapshotID: "old"}
+	if err := jobs.CreateDestination(ctx, jd); err != nil {
+		t.Fatalf("CreateDestination: %v", err)
+	}
+	snap := &db.Snapshot{PolicyID: job.PolicyID, DestinationID: destID, JobID: job.ID, SnapshotID: "old", Tags: "[]", SnapshotAt: time.Now().UTC()}
+	if err := snapshots.Create(ctx, snap); err != nil {
+		t.Fatalf("Create snapshot: %v", err)
+	}
+
+	if err := snapshots.ReplaceTagged(ctx, destID, "old", "new", "arkeep:hold"); err != nil {
+		t.Fatalf("ReplaceTagged: %v", err)
+	}
+	got, err := snapshots.GetByID(ctx, snap.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.SnapshotID != "new" || got.Tags != `["arkeep:hold"]` {
+		t.Errorf("snapshot = %q tags %s, want new tagged arkeep:hold", got.SnapshotID, got.Tags)
+	}
+	dests, err := jobs.ListDestinationsByJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("ListDestinationsByJob: %v", err)
+	}
+	if len(dests) != 1 || dests[0].SnapshotID != "new" {
+		t.Errorf("job destination snapshot = %+v, want new", dests)
+	}
+
+	if err := snapshots.ReplaceTagged(ctx, destID, "old", "newer", "arkeep:hold"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ReplaceTagged(stale id) error = %v, want ErrNotFound", err)
+	}
+}
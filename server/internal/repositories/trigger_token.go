@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/arkeep-io/arkeep/server/internal/db"
+)
+
+// gormTriggerTokenRepository is the GORM implementation of TriggerTokenRepository.
+type gormTriggerTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewTriggerTokenRepository returns a TriggerTokenRepository backed by the provided *gorm.DB.
+func NewTriggerTokenRepository(db *gorm.DB) TriggerTokenRepository {
+	return &gormTriggerTokenRepository{db: db}
+}
+
+// Create inserts a new trigger token. SigningSecret is automatically
+// encrypted by EncryptedString.Value().
+func (r *gormTriggerTokenRepository) Create(ctx context.Context, token *db.TriggerToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return fmt.Errorf("trigger_tokens: create: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a trigger token by its UUID.
+// Returns ErrNotFound if no record exists.
+func (r *gormTriggerTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*db.TriggerToken, error) {
+	var token db.TriggerToken
+	err := r.db.WithContext(ctx).First(&token, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("trigger_tokens: get by id: %w", err)
+	}
+	return &token, nil
+}
+
+// GetByHash retrieves a trigger token by the SHA-256 hash of its raw value.
+// Revoked tokens are returned too; callers must check RevokedAt.
+// Returns ErrNotFound if no record exists.
+func (r *gormTriggerTokenRepository) GetByHash(ctx context.Context, hash string) (*db.TriggerToken, error) {
+	var token db.TriggerToken
+	err := r.db.WithContext(ctx).First(&token, "token_hash = ?", hash).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("trigger_tokens: get by hash: %w", err)
+	}
+	return &token, nil
+}
+
+// ListByPolicy returns all trigger tokens of a policy, including revoked
+// ones, oldest first.
+func (r *gormTriggerTokenRepository) ListByPolicy(ctx context.Context, policyID uuid.UUID) ([]db.TriggerToken, error) {
+	var tokens []db.TriggerToken
+	err := r.db.WithContext(ctx).
+		Where("policy_id = ?", policyID).
+		Order("created_at ASC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("trigger_tokens: list by policy: %w", err)
+	}
+	return tokens, nil
+}
+
+// Rotate replaces the hash, display prefix and signing secret of an active
+// token. Returns ErrNotFound if the token does not exist or was revoked.
+func (r *gormTriggerTokenRepository) Rotate(ctx context.Context, id uuid.UUID, hash, prefix string, signingSecret db.EncryptedString) error {
+	result := r.db.WithContext(ctx).
+		Model(&db.TriggerToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]any{
+			"token_hash":     hash,
+			"token_prefix":   prefix,
+			"signing_secret": signingSecret,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("trigger_tokens: rotate: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Revoke sets the RevokedAt timestamp on a trigger token, invalidating it.
+// Returns ErrNotFound if the token does not exist or was already revoked.
+func (r *gormTriggerTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&db.TriggerToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", gorm.Expr("CURRENT_TIMESTAMP"))
+	if result.Error != nil {
+		return fmt.Errorf("trigger_tokens: revoke: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records when a trigger token was last accepted.
+func (r *gormTriggerTokenRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	err := r.db.WithContext(ctx).
+		Model(&db.TriggerToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+	if err != nil {
+		return fmt.Errorf("trigger_tokens: touch last used: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CommandTagPrefix, followed by the file name the output is stored under,
+// tags the snapshots of command sources.
+const CommandTagPrefix = "command:"
+
+// commandPayload carries a command source of a backup: the agent runs
+// Command with restic backup --stdin-from-command and stores its stdout as
+// Filename. Mirrors the struct in the agent executor.
+type commandPayload struct {
+	Command  string   `json:"command"`
+	Filename string   `json:"filename"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// buildCommands extracts the command sources of a policy's sources JSON.
+func buildCommands(sourcesJSON string) ([]commandPayload, error) {
+	var sources []struct {
+		Type     string `json:"type"`
+		Command  string `json:"command"`
+		Filename string `json:"filename"`
+	}
+	if err := json.Unmarshal([]byte(sourcesJSON), &sources); err != nil {
+		return nil, fmt.Errorf("invalid sources JSON: %w", err)
+	}
+
+	var commands []commandPayload
+	for _, src := range sources {
+		if src.Type != "command" {
+			continue
+		}
+		commands = append(commands, commandPayload{
+			Command:  src.Command,
+			Filename: src.Filename,
+			Tags:     []string{CommandTagPrefix + src.Filename},
+		})
+	}
+	return commands, nil
+}
@@ -0,0 +1,193 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// Database engines of database sources. EngineMySQL covers MariaDB too.
+const (
+	EnginePostgres = "postgres"
+	EngineMySQL    = "mysql"
+	EngineMongoDB  = "mongodb"
+	EngineRedis    = "redis"
+	EngineSQLite   = "sqlite"
+)
+
+// DatabaseEngines lists every valid database engine.
+var DatabaseEngines = []string{EnginePostgres, EngineMySQL, EngineMongoDB, EngineRedis, EngineSQLite}
+
+// ContainerDumpEngines lists the engines with a dump preset for docker-dump
+// sources, whose databases run in a container. Other engines are dumped
+// with a custom command.
+var ContainerDumpEngines = []string{EnginePostgres, EngineMySQL, EngineMongoDB}
+
+// Tags of the snapshots of a database dump: DatabaseTagPrefix is followed
+// by "<engine>:<database>", ConnectionTagPrefix by the ID of the connection
+// the dump was taken through and ContainerTagPrefix by the name of the
+// container it was taken in. Restores read them back to pipe the dump into
+// the engine's restore tool.
+const (
+	DatabaseTagPrefix   = "db:"
+	ConnectionTagPrefix = "db-connection:"
+	ContainerTagPrefix  = "db-container:"
+)
+
+// DatabaseTag returns the tag of the snapshots of a database's dumps.
+// Redis dumps the whole instance: database is then the connection's
+// address.
+func DatabaseTag(engine, database string) string {
+	return DatabaseTagPrefix + engine + ":" + database
+}
+
+// dumpFilenameUnsafe matches the characters replaced in dump file names.
+var dumpFilenameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// DumpFilename returns the name a database dump is stored under in its
+// snapshot: restic backup --stdin-filename. SQLite databases are named by
+// their file, whose base name is used.
+func DumpFilename(engine, database string) string {
+	ext := ".sql"
+	switch engine {
+	case EngineMongoDB:
+		ext = ".archive"
+	case EngineRedis:
+		ext = ".rdb"
+	case EngineSQLite:
+		database = path.Base(database)
+	}
+	return engine + "-" + dumpFilenameUnsafe.ReplaceAllString(database, "_") + ext
+}
+
+// databasePayload carries a database source of a backup, or the target of
+// a database restore, with the connection's decrypted password. Mirrors the
+// struct in the agent executor.
+type databasePayload struct {
+	Engine   string `json:"engine"`
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Database string `json:"database"`
+	// Filename is the name of the dump in its snapshot and Tags the tags
+	// the snapshot gets on top of the job's.
+	Filename string   `json:"filename"`
+	Tags     []string `json:"tags,omitempty"`
+	// SourceDatabase, for restores, is the database the dump was taken
+	// of, when the dump is restored under another name.
+	SourceDatabase string `json:"source_database,omitempty"`
+	// Container is set for docker-dump sources: the agent runs the dump
+	// inside it through the Docker API, with the engine's preset or, when
+	// Engine is empty, with Command. ContainerCredentials has the preset
+	// read the user and password from the container's environment.
+	Container            string `json:"container,omitempty"`
+	Command              string `json:"command,omitempty"`
+	ContainerCredentials bool   `json:"container_credentials,omitempty"`
+}
+
+// databaseSource is a source object of type "database" or "docker-dump" in
+// a policy's sources JSON. Database sources dump through a connection;
+// docker-dump sources dump inside a container, with an engine preset or a
+// custom command.
+type databaseSource struct {
+	Type         string `json:"type"`
+	ConnectionID string `json:"connection_id"`
+	Database     string `json:"database"`
+
+	Container            string `json:"container"`
+	Engine               string `json:"engine"`
+	Command              string `json:"command"`
+	Username             string `json:"username"`
+	ContainerCredentials bool   `json:"container_credentials"`
+}
+
+// buildDatabases resolves the database sources of a policy into the
+// payloads the agent dumps them with.
+func (s *Scheduler) buildDatabases(ctx context.Context, sourcesJSON string) ([]databasePayload, error) {
+	var sources []databaseSource
+	if err := json.Unmarshal([]byte(sourcesJSON), &sources); err != nil {
+		return nil, fmt.Errorf("invalid sources JSON: %w", err)
+	}
+
+	var databases []databasePayload
+	for _, src := range sources {
+		if src.Type == "docker-dump" {
+			databases = append(databases, containerDump(src))
+			continue
+		}
+		if src.Type != "database" {
+			continue
+		}
+		if s.conns == nil {
+			return nil, fmt.Errorf("database sources are not supported without a connection repository")
+		}
+		connID, err := uuid.Parse(src.ConnectionID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connection_id %q", src.ConnectionID)
+		}
+		conn, err := s.conns.GetByID(ctx, connID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load database connection %s: %w", connID, err)
+		}
+
+		name := src.Database
+		if conn.Engine == EngineRedis {
+			name = redisAddress(conn.Host, conn.Port)
+		} else if name == "" {
+			return nil, fmt.Errorf("database source of connection %q has no database", conn.Name)
+		}
+		databases = append(databases, databasePayload{
+			Engine:   conn.Engine,
+			Host:     conn.Host,
+			Port:     conn.Port,
+			Username: conn.Username,
+			Password: string(conn.Password), // decrypted
+			Database: src.Database,
+			Filename: DumpFilename(conn.Engine, name),
+			Tags:     []string{DatabaseTag(conn.Engine, name), ConnectionTagPrefix + conn.ID.String()},
+		})
+	}
+	return databases, nil
+}
+
+// redisAddress names a Redis instance in tags and dump file names.
+func redisAddress(host string, port int) string {
+	if host == "" {
+		host = "localhost"
+	}
+	if port == 0 {
+		port = 6379
+	}
+	return fmt.Sprintf("%s:%d", host, port)
+}
+
+// containerDump builds the payload of a docker-dump source. A preset dump
+// of one database is tagged like a dump taken through a connection, so it
+// can be restored through one. Dumps of a whole server and custom commands
+// are only tagged with their container: their files are restored as is.
+func containerDump(src databaseSource) databasePayload {
+	db := databasePayload{
+		Engine:               src.Engine,
+		Username:             src.Username,
+		Database:             src.Database,
+		Container:            src.Container,
+		Command:              src.Command,
+		ContainerCredentials: src.ContainerCredentials,
+		Tags:                 []string{ContainerTagPrefix + src.Container},
+	}
+	switch {
+	case src.Engine == "":
+		db.Filename = dumpFilenameUnsafe.ReplaceAllString(src.Container, "_") + ".dump"
+	case src.Database == "":
+		db.Filename = DumpFilename(src.Engine, src.Container)
+	default:
+		db.Filename = DumpFilename(src.Engine, src.Database)
+		db.Tags = append([]string{DatabaseTag(src.Engine, src.Database)}, db.Tags...)
+	}
+	return db
+}
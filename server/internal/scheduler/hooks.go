@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Phases of a policy's hooks, in the order a job runs them. Pre-backup and
+// post-backup steps surround the backup, then on-success or on-failure steps
+// run depending on its outcome. Restores of the policy's snapshots run the
+// pre-restore and post-restore steps.
+const (
+	HookPreBackup   = "pre_backup"
+	HookPostBackup  = "post_backup"
+	HookOnSuccess   = "on_success"
+	HookOnFailure   = "on_failure"
+	HookPreRestore  = "pre_restore"
+	HookPostRestore = "post_restore"
+)
+
+// HookPhases lists every valid hook phase.
+var HookPhases = []string{HookPreBackup, HookPostBackup, HookOnSuccess, HookOnFailure, HookPreRestore, HookPostRestore}
+
+// HookContextVars are the environment variables the agent sets for every
+// hook step, describing the job it runs in. ARKEEP_JOB_STATUS is running,
+// success or failed; ARKEEP_SNAPSHOT_ID is the first snapshot the backup
+// took, or the snapshot being restored, and empty in pre_backup steps.
+var HookContextVars = []string{"ARKEEP_JOB_ID", "ARKEEP_POLICY_ID", "ARKEEP_HOOK_PHASE", "ARKEEP_JOB_STATUS", "ARKEEP_SNAPSHOT_ID"}
+
+// HookStep is one command of a hook phase. The agent runs Command through
+// the host's shell with Args as positional parameters, in WorkingDir, as the
+// RunAs user when set, with Env on top of the job's context variables
+// (ARKEEP_JOB_ID, ARKEEP_JOB_STATUS, ARKEEP_SNAPSHOT_ID, ...). A failed step
+// stops its phase unless ContinueOnError is set. Mirrors the struct in the
+// agent executor.
+type HookStep struct {
+	Name            string            `json:"name,omitempty"`
+	Command         string            `json:"command"`
+	Args            []string          `json:"args,omitempty"`
+	Env             map[string]string `json:"env,omitempty"`
+	WorkingDir      string            `json:"working_dir,omitempty"`
+	RunAs           string            `json:"run_as,omitempty"`
+	TimeoutSecs     int               `json:"timeout_secs,omitempty"` // 0 = the agent's default
+	ContinueOnError bool              `json:"continue_on_error,omitempty"`
+}
+
+// Hooks maps each hook phase of a policy to its ordered steps. It is stored
+// as JSON in db.Policy.Hooks.
+type Hooks map[string][]HookStep
+
+// ParseHooks decodes the hooks JSON of a policy. An empty string has no
+// hooks.
+func ParseHooks(hooksJSON string) (Hooks, error) {
+	hooks := Hooks{}
+	if hooksJSON == "" {
+		return hooks, nil
+	}
+	if err := json.Unmarshal([]byte(hooksJSON), &hooks); err != nil {
+		return nil, fmt.Errorf("invalid hooks JSON: %w", err)
+	}
+	return hooks, nil
+}
+
+// Phases returns the hooks of the given phases only, nil when none has a
+// step.
+func (h Hooks) Phases(phases ...string) Hooks {
+	var out Hooks
+	for _, phase := range phases {
+		if steps := h[phase]; len(steps) > 0 {
+			if out == nil {
+				out = Hooks{}
+			}
+			out[phase] = steps
+		}
+	}
+	return out
+}
@@ -4,9 +4,14 @@
 // (to load credentials for dispatch), and AgentManager (to dispatch jobs to
 // connected agents via the open gRPC stream).
 //
-// Each policy maps to exactly one gocron job, identified by the policy UUID.
-// Jobs run in singleton mode: if a policy's previous job is still running when
-// the next tick fires, the new execution is skipped to avoid overlapping backups.
+// Each schedule of a policy maps to one gocron job: Policy.Schedule and every
+// additional db.PolicySchedule entry. All of them are tagged with the policy
+// UUID, so RemovePolicy drops them together; entry jobs are also tagged with
+// the entry UUID. The cron expressions are evaluated in the policy's IANA
+// timezone when one is set (via a CRON_TZ= prefix), otherwise in the server's
+// local timezone. Jobs run in singleton mode: if a schedule's previous job is
+// still running when its next tick fires, the new execution is skipped to
+// avoid overlapping backups.
 //
 // Dispatch flow:
 //  1. Tick fires → create Job + JobDestination records in DB (status: pending)
@@ -15,6 +20,67 @@
 //  3. Attempt immediate dispatch via AgentManager if agent is connected
 //  4. If agent is offline, the job stays pending; DispatchPending retries
 //     when the agent reconnects (called from the gRPC server on StreamJobs open)
+//
+// Concurrency limits and jitter:
+//   - Destinations may cap how many backup jobs write to them at once
+//     (Destination.MaxConcurrentJobs). Slots are reserved atomically by
+//     AgentManager.DispatchLimited; a job whose destination is full stays
+//     pending in an in-memory FIFO wait queue and is dispatched by
+//     DispatchQueued as soon as a running job finishes (JobFinished, called
+//     by the gRPC server). The queue is rebuilt from pending jobs by
+//     DispatchPending after a server restart.
+//   - Policies may spread their start time with Policy.StartJitterSeconds.
+//     The delay is derived from the policy ID, so it is stable across runs
+//     and included in NextRunAt and in the upcoming-runs projection.
+//
+// Schedule entries:
+//   - A job started by a schedule entry records it in Job.ScheduleID. Its
+//     snapshots get the entry's extra tags. An entry with its own retention
+//     scopes forget to the policy tag plus its tags; the policy's retention
+//     then keeps those snapshots (restic --keep-tag) and groups by tags so
+//     that they do not stand in for the policy's own daily snapshots.
+//
+// Retries:
+//   - When an agent reports a backup as failed, RetryFailedJob checks the
+//     policy's retry settings against the failure class. A retry is a new
+//     pending Job linked to the failed one (Job.RetryOfID) and held back until
+//     Job.NotBefore; a one-shot gocron timer dispatches it when the backoff
+//     elapses. Timers are re-armed from the database on Start.
+//
+// Missed runs:
+//   - On Start, each enabled policy is checked for a cron fire time that fell
+//     between its last run and now (i.e. while the server was down). Depending
+//     on Policy.CatchUp the run is either executed once immediately or
+//     recorded as a "missed" job and notified. Several missed fire times
+//     collapse into a single catch-up run.
+//   - A periodic sweep marks jobs that stayed pending past PendingDeadline
+//     because their agent never came back online as "missed".
+//
+// Dependencies:
+//   - A policy may run after other policies (db.PolicyDependency) on their
+//     success, failure or either. When an upstream job reaches its final
+//     outcome, TriggerDependents (called by the gRPC server) starts a job for
+//     every dependent policy whose condition matches. A failure that is going
+//     to be retried is not final; a partial backup (some destinations
+//     failed) counts as a failure. Policies with dependencies may have no
+//     schedule of their own; they are then not registered with gocron.
+//   - Dependencies must not form a cycle; FindDependencyCycle is checked
+//     when a policy is saved.
+//
+// Stuck jobs:
+//   - Policy.MaxRuntimeSeconds is sent to the agent, which kills restic and
+//     the hooks once a backup runs that long.
+//   - Orphan recovery in the gRPC server only covers agents that disconnect.
+//     A watchdog sweep fails running jobs without status or log activity
+//     (Job.LastActivityAt) for StuckJobTimeout, asks the agent to abort them
+//     and notifies.
+//
+// Anomalies:
+//   - CheckBackupAnomaly (called by the gRPC server for each successful
+//     destination run) compares the share of new and changed files with the
+//     recent runs of the policy to the same destination. A spike is flagged
+//     on the job destination and notified; with Policy.AnomalyHold the last
+//     known-good snapshot is tagged HoldTag, which forget always keeps.
 package scheduler
 
 import (
@@ -22,16 +88,24 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/go-co-op/gocron/v2"
 	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/arkeep-io/arkeep/server/internal/agentmanager"
 	"github.com/arkeep-io/arkeep/server/internal/db"
+	"github.com/arkeep-io/arkeep/server/internal/notification"
 	"github.com/arkeep-io/arkeep/server/internal/repositories"
 	"github.com/arkeep-io/arkeep/server/internal/destutil"
 	proto "github.com/arkeep-io/arkeep/shared/proto"
@@ -45,13 +119,31 @@ import (
 // before dispatch. The gRPC channel provides transport security.
 // The agent must never log or expose these values.
 type backupPayload struct {
-	Sources        string               `json:"sources"`
-	RepoPassword   string               `json:"repo_password"`
-	Destinations   []destinationPayload `json:"destinations"`
-	Retention      retentionPayload     `json:"retention"`
-	HookPreBackup  string               `json:"hook_pre_backup"`
-	HookPostBackup string               `json:"hook_post_backup"`
-	Tags           []string             `json:"tags"`
+	Sources      string               `json:"sources"`
+	RepoPassword string               `json:"repo_password"`
+	Destinations []destinationPayload `json:"destinations"`
+	Retention    retentionPayload     `json:"retention"`
+	Tags         []string             `json:"tags"`
+	// Hooks are the policy's backup hook phases: pre_backup, post_backup,
+	// on_success and on_failure.
+	Hooks Hooks `json:"hooks,omitempty"`
+	// MaxRuntimeSeconds is the policy's runtime limit, enforced by the agent.
+	// 0 means no limit.
+	MaxRuntimeSeconds int `json:"max_runtime_seconds"`
+	// FanOut and Parallelism mirror Policy.FanOut and
+	// Policy.FanOutParallelism.
+	FanOut      string `json:"fan_out"`
+	Parallelism int    `json:"parallelism"`
+	// EscrowPublicKey is the admin's escrow key, set for zero-knowledge
+	// policies when one is configured: the agent seals its keys to it and
+	// sends the kit back via ReportKeyEscrow.
+	EscrowPublicKey string `json:"escrow_public_key,omitempty"`
+	// Databases are the policy's database sources, each dumped into a
+	// snapshot of its own with restic backup --stdin.
+	Databases []databasePayload `json:"databases,omitempty"`
+	// Commands are the policy's command sources, whose output is stored
+	// in a snapshot of its own each.
+	Commands []commandPayload `json:"commands,omitempty"`
 }
 
 // destinationPayload carries the resolved details of a single backup target.
@@ -65,33 +157,410 @@ type destinationPayload struct {
 	Config        string            `json:"config"`
 	Env           map[string]string `json:"env"`
 	Priority      int               `json:"priority"`
+	// RepoPassword, when set, replaces the job's password for this
+	// destination: the password of the policy destination, or that of the
+	// repository entity (RepositoryID) the policy writes into. ChunkerFrom
+	// is the repository whose chunker parameters a repository that has not
+	// been initialised yet is created with.
+	RepositoryID string              `json:"repository_id"`
+	RepoPassword string              `json:"repo_password"`
+	ChunkerFrom  *destinationPayload `json:"chunker_from"`
+	// KeyID names the agent-held key of a zero-knowledge policy, which
+	// replaces the passwords above. KeyFingerprint is the fingerprint the
+	// server recorded for it; empty lets the agent create the key.
+	KeyID          string `json:"key_id,omitempty"`
+	KeyFingerprint string `json:"key_fingerprint,omitempty"`
+}
+
+// replicatePayload is the JSON-encoded payload embedded in a JobAssignment
+// for JOB_TYPE_REPLICATE jobs. Mirrors the struct in the agent executor.
+// RepoPassword opens the source repository and the destinations that are
+// not linked to a repository entity.
+type replicatePayload struct {
+	RepoPassword string               `json:"repo_password"`
+	Source       destinationPayload   `json:"source"`
+	Destinations []destinationPayload `json:"destinations"`
+	// Tags and Host select the snapshots of Source to copy; every tag must
+	// be present.
+	Tags              []string `json:"tags"`
+	Host              string   `json:"host"`
+	MaxRuntimeSeconds int      `json:"max_runtime_seconds"`
+}
+
+// dryRunPayload is the JSON-encoded payload embedded in a JobAssignment for
+// JOB_TYPE_DRY_RUN jobs. Mirrors the struct in the agent executor.
+type dryRunPayload struct {
+	Sources           string `json:"sources"`
+	MaxRuntimeSeconds int    `json:"max_runtime_seconds"`
 }
 
-// retentionPayload mirrors the keep_* fields from db.Policy.
+// retentionPayload mirrors the keep_* fields from db.Policy, or from a
+// db.PolicySchedule entry with its own retention.
 type retentionPayload struct {
+	Hourly  int `json:"hourly,omitempty"`
 	Daily   int `json:"daily"`
 	Weekly  int `json:"weekly"`
 	Monthly int `json:"monthly"`
 	Yearly  int `json:"yearly"`
+	// Tags scopes forget to the snapshots carrying all of these tags. Empty
+	// means the whole repository.
+	Tags []string `json:"tags,omitempty"`
+	// KeepTags lists comma-separated tag sets whose snapshots forget must
+	// keep because another retention applies to them.
+	KeepTags []string `json:"keep_tags,omitempty"`
 }
 
 // ErrPolicyDisabled is returned by TriggerNow when the target policy is disabled.
 var ErrPolicyDisabled = errors.New("policy is disabled")
 
+// ErrReplicationDryRun is returned by DryRun for replication policies, which
+// have no sources to scan.
+var ErrReplicationDryRun = errors.New("replication policies have no sources to dry-run")
+
+const (
+	// defaultPendingDeadline is how long a job may wait for its agent to come
+	// online before it is marked "missed". One day matches the most common
+	// schedule: by then the next nightly run has already been queued.
+	defaultPendingDeadline = 24 * time.Hour
+
+	// pendingSweepInterval is how often the scheduler looks for expired
+	// pending jobs.
+	pendingSweepInterval = 5 * time.Minute
+
+	// pendingSweepTag identifies the internal sweep job in gocron. It can never
+	// collide with a policy tag, which is always a UUID.
+	pendingSweepTag = "system:pending-sweep"
+
+	// queueSweepInterval is how often the wait queue is drained even if no
+	// job finished, e.g. after a destination limit was raised.
+	queueSweepInterval = time.Minute
+
+	// queueSweepTag identifies the internal queue drain job in gocron.
+	queueSweepTag = "system:queue-sweep"
+
+	// defaultStuckJobTimeout is how long a running job may go without any
+	// status or log activity before the watchdog fails it. restic streams
+	// progress every second, so silence this long means the agent hung.
+	defaultStuckJobTimeout = 2 * time.Hour
+
+	// watchdogInterval is how often the watchdog looks for stuck jobs.
+	watchdogInterval = time.Minute
+
+	// watchdogTag identifies the internal stuck-job watchdog in gocron.
+	watchdogTag = "system:watchdog"
+
+	// retryTagPrefix prefixes the gocron tag of a retry timer, followed by
+	// the retry job ID. Retry timers are deliberately not tagged with the
+	// policy ID, so that rescheduling a policy does not drop them.
+	retryTagPrefix = "retry:"
+
+	// maxRetryBackoff caps the delay before a single retry.
+	maxRetryBackoff = 24 * time.Hour
+)
+
+// Failure classes attached to failed backups by the agent (see
+// proto.FailureClass). Policy.RetryOn lists the classes that are retried.
+const (
+	FailureNetwork       = "network"
+	FailureHook          = "hook"
+	FailureWrongPassword = "wrong_password"
+	FailureOther         = "other"
+)
+
+// FailureClasses lists every failure class a policy can retry on.
+var FailureClasses = []string{FailureNetwork, FailureHook, FailureWrongPassword, FailureOther}
+
+// Conditions of a policy dependency (db.PolicyDependency.Condition) on the
+// outcome of the upstream job.
+const (
+	DependencyOnSuccess = "success"
+	DependencyOnFailure = "failure"
+	DependencyAlways    = "always"
+)
+
+// DependencyConditions lists every valid dependency condition.
+var DependencyConditions = []string{DependencyOnSuccess, DependencyOnFailure, DependencyAlways}
+
+// Policy types (db.Policy.Type). The jobs of a replication policy have the
+// type "replication".
+const (
+	PolicyTypeBackup      = "backup"
+	PolicyTypeReplication = "replication"
+)
+
+// PolicyTypes lists every valid policy type.
+var PolicyTypes = []string{PolicyTypeBackup, PolicyTypeReplication}
+
+// Fan-out modes of a policy with several destinations (db.Policy.FanOut).
+const (
+	FanOutSequential = "sequential"
+	FanOutParallel   = "parallel"
+	FanOutReplicate  = "replicate"
+)
+
+// FanOutModes lists every valid fan-out mode.
+var FanOutModes = []string{FanOutSequential, FanOutParallel, FanOutReplicate}
+
+// Container modes of a docker-volume source: what the agent does to the
+// containers that mount the volume while restic reads it. Stopping gives
+// an application-consistent copy; pausing freezes the processes without
+// restarting them. The empty mode leaves the containers running.
+const (
+	ContainersStop  = "stop"
+	ContainersPause = "pause"
+)
+
+// ContainerModes lists every valid container mode.
+var ContainerModes = []string{ContainersStop, ContainersPause}
+
+// HoldTag is the restic tag that protects a snapshot from retention: the
+// agent always passes it to restic forget as --keep-tag. It is put on the
+// last known-good snapshot when a backup run looks anomalous.
+const HoldTag = "arkeep:hold"
+
+// EscrowPublicKeySetting is the settings key of the admin's escrow public
+// key (base64 X25519), which the agents of zero-knowledge policies seal
+// their escrow kits to.
+const EscrowPublicKeySetting = "zero_knowledge.escrow_public_key"
+
+const (
+	// anomalyBaselineRuns is how many previous runs make up the baseline a
+	// backup run is compared with.
+	anomalyBaselineRuns = 10
+
+	// anomalyMinBaselineRuns is how many previous runs are needed before a
+	// run is judged at all; the first backups of a policy are always large.
+	anomalyMinBaselineRuns = 5
+
+	// anomalyMinChangeRatio is the share of new and changed files below
+	// which a run is never anomalous, however quiet the baseline.
+	anomalyMinChangeRatio = 0.3
+
+	// anomalyDeviations is how many standard deviations above the baseline
+	// mean the change ratio of an anomalous run is.
+	anomalyDeviations = 3
+)
+
+// cronParser mirrors the parser gocron uses for CronJob(expr, false) so that
+// missed-run detection and the upcoming-runs projection compute exactly the
+// fire times gocron uses.
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// cronSpec returns the crontab passed to gocron for one schedule of policy:
+// the schedule prefixed with CRON_TZ=<zone> when the policy has a timezone.
+func cronSpec(policy *db.Policy, schedule string) string {
+	if policy.Timezone == "" {
+		return schedule
+	}
+	return "CRON_TZ=" + policy.Timezone + " " + schedule
+}
+
+// policyCron is one cron schedule of a policy: Policy.Schedule when entry is
+// nil, otherwise a PolicySchedule entry.
+type policyCron struct {
+	schedule string
+	entry    *db.PolicySchedule
+}
+
+// scheduleID returns the entry ID, or nil for the policy's own schedule.
+func (c policyCron) scheduleID() *uuid.UUID {
+	if c.entry == nil {
+		return nil
+	}
+	return &c.entry.ID
+}
+
+// policyCrons returns every cron schedule of policy: its own Schedule, if
+// set, followed by its schedule entries.
+func (s *Scheduler) policyCrons(ctx context.Context, policy *db.Policy) ([]policyCron, error) {
+	entries, err := s.policies.ListSchedules(ctx, policy.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schedule entries: %w", err)
+	}
+	crons := make([]policyCron, 0, len(entries)+1)
+	if policy.Schedule != "" {
+		crons = append(crons, policyCron{schedule: policy.Schedule})
+	}
+	for i := range entries {
+		crons = append(crons, policyCron{schedule: entries[i].Schedule, entry: &entries[i]})
+	}
+	return crons, nil
+}
+
+// splitTags converts a comma-separated tag list into a slice, skipping empty
+// items.
+func splitTags(tags string) []string {
+	var out []string
+	for _, t := range strings.Split(tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// snapshotSettings returns the snapshot tags and the retention for a job of
+// policy started by the schedule entry scheduleID (nil for every other run),
+// given all schedule entries of the policy.
+//
+// A job of an entry with its own retention forgets only the snapshots
+// carrying the policy tag and the entry's tags. Every other job applies the
+// policy's retention and keeps the snapshots of such entries.
+func snapshotSettings(policy *db.Policy, scheduleID *uuid.UUID, entries []db.PolicySchedule) ([]string, retentionPayload) {
+	policyTag := fmt.Sprintf("policy:%s", policy.ID.String())
+	tags := []string{policyTag}
+	retention := retentionPayload{
+		Daily:   policy.RetentionDaily,
+		Weekly:  policy.RetentionWeekly,
+		Monthly: policy.RetentionMonthly,
+		Yearly:  policy.RetentionYearly,
+	}
+
+	for _, e := range entries {
+		if scheduleID == nil || e.ID != *scheduleID {
+			continue
+		}
+		tags = append(tags, splitTags(e.Tags)...)
+		if e.CustomRetention {
+			return tags, retentionPayload{
+				Hourly:  e.RetentionHourly,
+				Daily:   e.RetentionDaily,
+				Weekly:  e.RetentionWeekly,
+				Monthly: e.RetentionMonthly,
+				Yearly:  e.RetentionYearly,
+				Tags:    slices.Clone(tags),
+			}
+		}
+	}
+
+	for _, e := range entries {
+		if e.CustomRetention {
+			keep := append([]string{policyTag}, splitTags(e.Tags)...)
+			retention.KeepTags = append(retention.KeepTags, strings.Join(keep, ","))
+		}
+	}
+	return tags, retention
+}
+
+// UpcomingRun is a single projected execution of a policy, as returned by
+// Upcoming.
+type UpcomingRun struct {
+	PolicyID       uuid.UUID
+	ScheduleID     *uuid.UUID // schedule entry, nil for Policy.Schedule
+	PolicyName     string
+	AgentID        uuid.UUID
+	DestinationIDs []uuid.UUID
+	ScheduledAt    time.Time
+}
+
+// QueuedJob is a pending job waiting for a slot on one of its destinations,
+// as returned by Queue.
+type QueuedJob struct {
+	JobID          uuid.UUID
+	PolicyID       uuid.UUID
+	AgentID        uuid.UUID
+	DestinationIDs []uuid.UUID
+	QueuedAt       time.Time
+}
+
+// retryBackoff returns how long to wait before retrying a job that failed on
+// the given attempt: RetryBackoffSeconds doubled for every earlier retry,
+// capped at maxRetryBackoff.
+func retryBackoff(policy *db.Policy, attempt int) time.Duration {
+	backoff := time.Duration(policy.RetryBackoffSeconds) * time.Second
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
+
+// retriesOn reports whether policy retries failures of the given class.
+// Failures reported without a class (older agents) count as "other".
+func retriesOn(policy *db.Policy, failureClass string) bool {
+	if failureClass == "" {
+		failureClass = FailureOther
+	}
+	for _, c := range strings.Split(policy.RetryOn, ",") {
+		if strings.TrimSpace(c) == failureClass {
+			return true
+		}
+	}
+	return false
+}
+
+// startDelay returns the stable start delay of policy: a value in
+// [0, StartJitterSeconds) seconds derived from the policy ID, so that every
+// run of the policy is shifted by the same amount.
+func startDelay(policy *db.Policy) time.Duration {
+	if policy.StartJitterSeconds <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write(policy.ID[:])
+	return time.Duration(h.Sum64()%uint64(policy.StartJitterSeconds)) * time.Second
+}
+
 // Scheduler wraps gocron and coordinates job creation and dispatch.
 // The zero value is not usable — create instances with New.
 type Scheduler struct {
-	cron     gocron.Scheduler
-	policies repositories.PolicyRepository
-	jobs     repositories.JobRepository
-	dests    repositories.DestinationRepository
-	agentMgr *agentmanager.Manager
-	logger   *zap.Logger
-	running  atomic.Bool
+	cron            gocron.Scheduler
+	policies        repositories.PolicyRepository
+	jobs            repositories.JobRepository
+	dests           repositories.DestinationRepository
+	repos           repositories.RepositoryRepository         // may be nil
+	settings        repositories.SettingsRepository           // may be nil
+	keys            repositories.KeyRepository                // may be nil
+	imports         repositories.ImportRepository             // may be nil
+	conns           repositories.DatabaseConnectionRepository // may be nil
+	agentMgr        *agentmanager.Manager
+	notifSvc        notification.Service // may be nil
+	pendingDeadline time.Duration
+	stuckJobTimeout time.Duration
+	logger          *zap.Logger
+	running         atomic.Bool
+
+	// queue holds jobs waiting for a destination slot, oldest first.
+	// drainMu serializes DispatchQueued so that a job is never sent twice.
+	queueMu sync.Mutex
+	queue   []QueuedJob
+	drainMu sync.Mutex
+}
+
+// Config holds the optional dependencies and tunables of the Scheduler.
+type Config struct {
+	// NotifService is used to notify admins about missed runs. Optional — if
+	// nil, missed runs are still recorded as jobs but no notification is sent.
+	NotifService notification.Service
+	// Repositories resolves the repository entities policies write into.
+	// Optional — if nil, a destination linked to a repository is skipped at
+	// dispatch rather than written with the wrong URL or password.
+	Repositories repositories.RepositoryRepository
+	// Settings provides the escrow public key of zero-knowledge policies
+	// (EscrowPublicKeySetting). Optional — if nil, agents are not asked for
+	// escrow kits.
+	Settings repositories.SettingsRepository
+	// Keys holds the key shares of zero-knowledge policies. Optional — if
+	// nil, key shares requested while an agent was offline are not sent on
+	// reconnect.
+	Keys repositories.KeyRepository
+	// Imports holds the scans of repositories being adopted. Optional — if
+	// nil, scans requested while an agent was offline are not sent on
+	// reconnect.
+	Imports repositories.ImportRepository
+	// DatabaseConnections resolves the connections of database sources.
+	// Optional — if nil, policies with database sources are not sent.
+	DatabaseConnections repositories.DatabaseConnectionRepository
+	// PendingDeadline is how long a job may stay pending while its agent is
+	// offline before it is marked "missed". Zero means defaultPendingDeadline.
+	PendingDeadline time.Duration
+	// StuckJobTimeout is how long a running job may go without status or log
+	// activity before the watchdog fails it. Zero means defaultStuckJobTimeout.
+	StuckJobTimeout time.Duration
 }
 
 // New creates and configures a new Scheduler. Call Start to begin processing.
 func New(
+	cfg Config,
 	policies repositories.PolicyRepository,
 	jobs repositories.JobRepository,
 	dests repositories.DestinationRepository,
@@ -103,38 +572,100 @@ func New(
 		return nil, fmt.Errorf("failed to create gocron scheduler: %w", err)
 	}
 
+	pendingDeadline := cfg.PendingDeadline
+	if pendingDeadline <= 0 {
+		pendingDeadline = defaultPendingDeadline
+	}
+	stuckJobTimeout := cfg.StuckJobTimeout
+	if stuckJobTimeout <= 0 {
+		stuckJobTimeout = defaultStuckJobTimeout
+	}
+
 	return &Scheduler{
-		cron:     s,
-		policies: policies,
-		jobs:     jobs,
-		dests:    dests,
-		agentMgr: agentMgr,
-		logger:   logger.Named("scheduler"),
+		cron:            s,
+		policies:        policies,
+		jobs:            jobs,
+		dests:           dests,
+		repos:           cfg.Repositories,
+		settings:        cfg.Settings,
+		keys:            cfg.Keys,
+		imports:         cfg.Imports,
+		conns:           cfg.DatabaseConnections,
+		agentMgr:        agentMgr,
+		notifSvc:        cfg.NotifService,
+		pendingDeadline: pendingDeadline,
+		stuckJobTimeout: stuckJobTimeout,
+		logger:          logger.Named("scheduler"),
 	}, nil
 }
 
 // Start loads all enabled policies from the database, schedules them, and
-// starts the underlying gocron scheduler. It should be called once at server
-// startup, after the database connection is established.
+// starts the underlying gocron scheduler. Runs missed while the server was
+// down are detected here and either caught up or recorded as missed (see
+// handleMissedRun). It should be called once at server startup, after the
+// database connection is established.
 func (s *Scheduler) Start(ctx context.Context) error {
 	enabled, err := s.policies.ListEnabled(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to load enabled policies: %w", err)
 	}
 
+	now := time.Now().UTC()
 	for i := range enabled {
-		if err := s.addJob(&enabled[i]); err != nil {
+		if err := s.addJob(ctx, &enabled[i]); err != nil {
 			s.logger.Error("failed to schedule policy",
 				zap.String("policy_id", enabled[i].ID.String()),
 				zap.String("policy_name", enabled[i].Name),
 				zap.Error(err),
 			)
+			continue
 		}
+		s.handleMissedRun(ctx, &enabled[i], now)
+	}
+
+	if _, err := s.cron.NewJob(
+		gocron.DurationJob(pendingSweepInterval),
+		gocron.NewTask(s.expirePendingJobs),
+		gocron.WithTags(pendingSweepTag),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	); err != nil {
+		return fmt.Errorf("failed to schedule pending job sweep: %w", err)
+	}
+	if _, err := s.cron.NewJob(
+		gocron.DurationJob(queueSweepInterval),
+		gocron.NewTask(func(ctx context.Context) { s.DispatchQueued(ctx) }),
+		gocron.WithTags(queueSweepTag),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	); err != nil {
+		return fmt.Errorf("failed to schedule queue sweep: %w", err)
+	}
+	if _, err := s.cron.NewJob(
+		gocron.DurationJob(watchdogInterval),
+		gocron.NewTask(s.failStuckJobs),
+		gocron.WithTags(watchdogTag),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	); err != nil {
+		return fmt.Errorf("failed to schedule stuck job watchdog: %w", err)
+	}
+
+	retries, err := s.jobs.ListScheduledRetries(ctx, now)
+	if err != nil {
+		s.logger.Error("failed to load scheduled retries", zap.Error(err))
+	}
+	for i := range retries {
+		s.armRetry(&retries[i])
 	}
 
-	s.logger.Info("scheduler started", zap.Int("policies_scheduled", len(enabled)))
+	s.logger.Info("scheduler started",
+		zap.Int("policies_scheduled", len(enabled)),
+		zap.Int("retries_scheduled", len(retries)),
+	)
 	s.cron.Start()
 	s.running.Store(true)
+
+	for i := range enabled {
+		s.refreshNextRun(ctx, &enabled[i])
+	}
 	return nil
 }
 
@@ -158,9 +689,14 @@ func (s *Scheduler) IsRunning() bool {
 // AddPolicy schedules a newly created or re-enabled policy. Safe to call while
 // the scheduler is running. Called by the REST handler after policy creation.
 func (s *Scheduler) AddPolicy(policy *db.Policy) error {
-	if err := s.addJob(policy); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.addJob(ctx, policy); err != nil {
 		return fmt.Errorf("failed to add policy %s to scheduler: %w", policy.ID, err)
 	}
+	s.refreshNextRun(ctx, policy)
+
 	s.logger.Info("policy added to scheduler",
 		zap.String("policy_id", policy.ID.String()),
 		zap.String("policy_name", policy.Name),
@@ -178,12 +714,23 @@ func (s *Scheduler) RemovePolicy(policyID uuid.UUID) error {
 	return nil
 }
 
-// UpdatePolicy reschedules a policy after its cron expression or enabled state
-// has changed. Removes the existing gocron job and adds a new one.
+// UpdatePolicy reschedules a policy after its cron expressions, schedule
+// entries or enabled state have changed. Removes the existing gocron jobs and
+// adds new ones.
 func (s *Scheduler) UpdatePolicy(policy *db.Policy) error {
 	s.cron.RemoveByTags(policy.ID.String())
 
 	if !policy.Enabled {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.policies.UpdateNextRun(ctx, policy.ID, nil); err != nil {
+			s.logger.Warn("failed to clear next run of disabled policy",
+				zap.String("policy_id", policy.ID.String()),
+				zap.Error(err),
+			)
+		}
+		policy.NextRunAt = nil
+
 		s.logger.Info("policy disabled, removed from scheduler",
 			zap.String("policy_id", policy.ID.String()),
 		)
@@ -193,6 +740,73 @@ func (s *Scheduler) UpdatePolicy(policy *db.Policy) error {
 	return s.AddPolicy(policy)
 }
 
+// Upcoming projects every scheduled run of every enabled policy in the
+// half-open interval (from, to], ordered by time. At most limit runs are
+// returned; truncated reports whether more runs fell in the interval.
+//
+// Projections are computed from the cron expressions rather than from gocron,
+// which only tracks the next fire time of each job.
+func (s *Scheduler) Upcoming(ctx context.Context, from, to time.Time, limit int) (runs []UpcomingRun, truncated bool, err error) {
+	enabled, err := s.policies.ListEnabled(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load enabled policies: %w", err)
+	}
+
+	for i := range enabled {
+		p := &enabled[i]
+		crons, err := s.policyCrons(ctx, p)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to load schedules for policy %s: %w", p.ID, err)
+		}
+		if len(crons) == 0 {
+			continue
+		}
+
+		_, destinations, err := s.policies.GetByIDWithDestinations(ctx, p.ID)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to load destinations for policy %s: %w", p.ID, err)
+		}
+		destIDs := make([]uuid.UUID, len(destinations))
+		for j, pd := range destinations {
+			destIDs[j] = pd.DestinationID
+		}
+
+		// Each schedule contributes at most limit+1 runs: enough to fill the
+		// result on its own and to detect truncation. Fire times are shifted
+		// by the policy's start delay, so the window is shifted back by it.
+		delay := startDelay(p)
+		for _, c := range crons {
+			sched, err := cronParser.Parse(cronSpec(p, c.schedule))
+			if err != nil {
+				s.logger.Warn("skipping policy schedule with invalid cron in projection",
+					zap.String("policy_id", p.ID.String()),
+					zap.String("schedule", c.schedule),
+					zap.Error(err),
+				)
+				continue
+			}
+			for n, t := 0, sched.Next(from.Add(-delay)); !t.IsZero() && !t.Add(delay).After(to) && n <= limit; n, t = n+1, sched.Next(t) {
+				runs = append(runs, UpcomingRun{
+					PolicyID:       p.ID,
+					ScheduleID:     c.scheduleID(),
+					PolicyName:     p.Name,
+					AgentID:        p.AgentID,
+					DestinationIDs: destIDs,
+					ScheduledAt:    t.Add(delay).UTC(),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].ScheduledAt.Before(runs[j].ScheduledAt)
+	})
+	if len(runs) > limit {
+		return runs[:limit], true, nil
+	}
+	return runs, false, nil
+}
+
 // TriggerNow manually triggers an immediate job run for a policy, bypassing
 // the cron schedule. Used by the REST handler for on-demand backups.
 // It returns the created Job so the caller can surface its ID to the client.
@@ -205,13 +819,65 @@ func (s *Scheduler) TriggerNow(ctx context.Context, policyID uuid.UUID) (*db.Job
 		zap.String("policy_id", policyID.String()),
 		zap.String("policy_name", policy.Name),
 	)
-	return s.runJob(policy, destinations)
+	return s.runJob(policy, destinations, nil, nil)
+}
+
+// DryRun creates a "dry_run" job for a policy and dispatches it: the agent
+// scans the policy's sources with restic backup --dry-run and reports the
+// size of a first backup. It runs for disabled policies too, so that a new
+// policy can be sized before it is enabled. The job has no destinations and
+// does not count towards their concurrency limits; it does not update the
+// policy's run timestamps, trigger dependents or get retried.
+func (s *Scheduler) DryRun(ctx context.Context, policyID uuid.UUID) (*db.Job, error) {
+	policy, err := s.policies.GetByID(ctx, policyID)
+	if err != nil {
+		return nil, fmt.Errorf("policy not found: %w", err)
+	}
+	if policy.Type == PolicyTypeReplication {
+		return nil, ErrReplicationDryRun
+	}
+
+	job := &db.Job{
+		PolicyID: policy.ID,
+		AgentID:  policy.AgentID,
+		Type:     "dry_run",
+		Status:   "pending",
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create dry-run job for policy %s: %w", policy.ID, err)
+	}
+	s.logger.Info("dry run requested",
+		zap.String("job_id", job.ID.String()),
+		zap.String("policy_id", policy.ID.String()),
+		zap.String("policy_name", policy.Name),
+	)
+
+	if err := s.dispatch(job, policy, nil); err != nil {
+		// Non-fatal: the job is persisted as pending. DispatchPending will
+		// retry when the agent reconnects.
+		s.logger.Warn("dispatch failed, job remains pending",
+			zap.String("job_id", job.ID.String()),
+			zap.String("agent_id", policy.AgentID.String()),
+			zap.Error(err),
+		)
+	}
+	return job, nil
 }
 
+// redispatchJobTypes are the job types DispatchPending re-sends. Restore jobs
+// are not re-sent: their request payload is not saved with the job, so it
+// cannot be rebuilt.
+var redispatchJobTypes = []string{"backup", "replication", "dry_run"}
+
 // DispatchPending looks up all pending jobs for a given agent and attempts to
 // dispatch them via AgentManager. Called by the gRPC server when an agent
 // reconnects, ensuring jobs created while the agent was offline are not lost.
+// Pending key shares are sent along. Only the job types in
+// redispatchJobTypes are re-sent: their payload is rebuilt from the policy.
 func (s *Scheduler) DispatchPending(ctx context.Context, agentID uuid.UUID) {
+	s.dispatchPendingShares(ctx, agentID)
+	s.dispatchPendingScans(ctx, agentID)
+
 	opts := repositories.ListOptions{Limit: 100, Offset: 0}
 	pendingJobs, _, err := s.jobs.ListByAgent(ctx, agentID, opts)
 	if err != nil {
@@ -222,9 +888,14 @@ func (s *Scheduler) DispatchPending(ctx context.Context, agentID uuid.UUID) {
 		return
 	}
 
+	now := time.Now()
 	for i := range pendingJobs {
 		j := &pendingJobs[i]
-		if j.Status != "pending" {
+		if j.Status != "pending" || !slices.Contains(redispatchJobTypes, j.Type) {
+			continue
+		}
+		// Retries still in their backoff are dispatched by their own timer.
+		if j.NotBefore != nil && j.NotBefore.After(now) {
 			continue
 		}
 
@@ -251,102 +922,1087 @@ func (s *Scheduler) DispatchPending(ctx context.Context, agentID uuid.UUID) {
 	}
 }
 
-// addJob registers a single policy as a gocron job with singleton mode.
-// The policy UUID is used as the gocron tag for later identification.
-func (s *Scheduler) addJob(policy *db.Policy) error {
-	_, err := s.cron.NewJob(
-		gocron.CronJob(policy.Schedule, false),
-		gocron.NewTask(func(p db.Policy) {
-			// Re-fetch destinations at tick time to pick up any changes made
-			// since the job was scheduled. The policy snapshot passed in via
-			// closure may be stale if destinations were added or removed.
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
+// scanPayload is the JSON-encoded payload of a JOB_TYPE_SCAN_REPOSITORY
+// assignment. Mirrors the struct in the agent executor.
+type scanPayload struct {
+	Repository destinationPayload `json:"repository"`
+}
 
-			_, destinations, err := s.policies.GetByIDWithDestinations(ctx, p.ID)
-			if err != nil {
-				s.logger.Error("failed to load destinations at tick time",
-					zap.String("policy_id", p.ID.String()),
-					zap.Error(err),
-				)
-				return
-			}
+// DispatchRepositoryScan asks the agent of imp to list the snapshots of the
+// repository being imported. The assignment's job ID is the import ID; the
+// agent answers with ReportRepositoryScan. Returns
+// agentmanager.ErrAgentNotConnected when the agent is offline — the import
+// stays scanning and is sent by DispatchPending.
+func (s *Scheduler) DispatchRepositoryScan(ctx context.Context, imp *db.RepositoryImport) error {
+	if s.repos == nil {
+		return errors.New("repositories are not configured")
+	}
+	repo, err := s.repos.GetByID(ctx, imp.RepositoryID)
+	if err != nil {
+		return fmt.Errorf("failed to load repository: %w", err)
+	}
+	dest, err := s.dests.GetByID(ctx, repo.DestinationID)
+	if err != nil {
+		return fmt.Errorf("failed to load destination: %w", err)
+	}
+	payload := rootPayload(dest)
+	payload.RepoURL = destutil.BuildRepositoryURL(dest, repo.Path)
+	payload.RepositoryID = repo.ID.String()
+	payload.RepoPassword = string(repo.Password) // decrypted
 
-			if _, err := s.runJob(&p, destinations); err != nil && !errors.Is(err, ErrPolicyDisabled) {
-				s.logger.Error("job run failed",
-					zap.String("policy_id", p.ID.String()),
-					zap.String("policy_name", p.Name),
-					zap.Error(err),
-				)
-			}
-		}, *policy),
-		gocron.WithTags(policy.ID.String()),
-		gocron.WithSingletonMode(gocron.LimitModeReschedule),
-	)
+	payloadBytes, err := json.Marshal(scanPayload{Repository: payload})
 	if err != nil {
-		return fmt.Errorf("gocron.NewJob failed for policy %s (schedule: %q): %w",
-			policy.ID, policy.Schedule, err)
+		return fmt.Errorf("failed to marshal scan payload: %w", err)
 	}
-	return nil
+	return s.agentMgr.Dispatch(imp.AgentID.String(), &proto.JobAssignment{
+		JobId:       imp.ID.String(),
+		Type:        proto.JobType_JOB_TYPE_SCAN_REPOSITORY,
+		Payload:     payloadBytes,
+		ScheduledAt: timestamppb.Now(),
+	})
 }
 
-// runJob is the core execution unit called by gocron on each tick (or manually
-// via TriggerNow). It creates the Job and JobDestination DB records, updates
-// policy timestamps, and dispatches the assignment to the agent.
-// It returns the created Job so callers can surface its ID.
-func (s *Scheduler) runJob(policy *db.Policy, destinations []db.PolicyDestination) (*db.Job, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if !policy.Enabled {
-		s.logger.Info("skipping job for disabled policy",
-			zap.String("policy_id", policy.ID.String()),
-		)
-		return nil, ErrPolicyDisabled
+// dispatchPendingScans sends the repository scans requested from agentID
+// while it was offline.
+func (s *Scheduler) dispatchPendingScans(ctx context.Context, agentID uuid.UUID) {
+	if s.imports == nil {
+		return
 	}
-
-	// --- Create Job record ---
-	job := &db.Job{
-		PolicyID: policy.ID,
-		AgentID:  policy.AgentID,
-		Status:   "pending",
+	imports, err := s.imports.ListPendingScans(ctx, agentID)
+	if err != nil {
+		s.logger.Error("failed to fetch pending repository scans for agent",
+			zap.String("agent_id", agentID.String()),
+			zap.Error(err),
+		)
+		return
 	}
-	if err := s.jobs.Create(ctx, job); err != nil {
-		return nil, fmt.Errorf("failed to create job record for policy %s: %w", policy.ID, err)
+	for i := range imports {
+		if err := s.DispatchRepositoryScan(ctx, &imports[i]); err != nil {
+			s.logger.Warn("failed to dispatch pending repository scan to reconnected agent",
+				zap.String("import_id", imports[i].ID.String()),
+				zap.String("agent_id", agentID.String()),
+				zap.Error(err),
+			)
+		}
 	}
+}
 
-	s.logger.Info("job created",
-		zap.String("job_id", job.ID.String()),
-		zap.String("policy_id", policy.ID.String()),
-		zap.String("policy_name", policy.Name),
-		zap.String("agent_id", policy.AgentID.String()),
-	)
+// keySharePayload is the JSON-encoded payload of a JOB_TYPE_SHARE_KEY
+// assignment. Mirrors the struct in the agent connection manager.
+type keySharePayload struct {
+	KeyID              string `json:"key_id"`
+	RecipientPublicKey string `json:"recipient_public_key"`
+	KeyFingerprint     string `json:"key_fingerprint"`
+}
 
-	// --- Create JobDestination records ---
-	for _, pd := range destinations {
-		jd := &db.JobDestination{
-			JobID:         job.ID,
-			DestinationID: pd.DestinationID,
-			Status:        "pending",
+// DispatchKeyShare asks the agent holding the key of share to seal it to the
+// recipient. The assignment's job ID is the share ID; the agent answers with
+// ReportKeyShare. Returns agentmanager.ErrAgentNotConnected when the agent is
+// offline — the share stays pending and is sent by DispatchPending.
+func (s *Scheduler) DispatchKeyShare(share *db.KeyShare) error {
+	payloadBytes, err := json.Marshal(keySharePayload{
+		KeyID:              share.KeyID,
+		RecipientPublicKey: share.RecipientPublicKey,
+		KeyFingerprint:     share.KeyFingerprint,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal key share payload: %w", err)
+	}
+	return s.agentMgr.Dispatch(share.FromAgentID.String(), &proto.JobAssignment{
+		JobId:       share.ID.String(),
+		PolicyId:    share.PolicyID.String(),
+		Type:        proto.JobType_JOB_TYPE_SHARE_KEY,
+		Payload:     payloadBytes,
+		ScheduledAt: timestamppb.Now(),
+	})
+}
+
+// dispatchPendingShares sends the key shares requested from agentID while it
+// was offline.
+func (s *Scheduler) dispatchPendingShares(ctx context.Context, agentID uuid.UUID) {
+	if s.keys == nil {
+		return
+	}
+	shares, err := s.keys.ListPendingShares(ctx, agentID)
+	if err != nil {
+		s.logger.Error("failed to fetch pending key shares for agent",
+			zap.String("agent_id", agentID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	for i := range shares {
+		if err := s.DispatchKeyShare(&shares[i]); err != nil {
+			s.logger.Warn("failed to dispatch pending key share to reconnected agent",
+				zap.String("share_id", shares[i].ID.String()),
+				zap.String("agent_id", agentID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// RetryFailedJob decides whether a failed backup job is retried according to
+// its policy (RetryMaxAttempts, RetryOn) and, if so, creates the next attempt
+// and arms its backoff timer. It returns the retry job, or nil when the
+// failure is final. Called by the gRPC server after an agent reports a
+// failure and by the stuck-job watchdog; the caller only notifies about final
+// failures.
+func (s *Scheduler) RetryFailedJob(ctx context.Context, jobID uuid.UUID) (*db.Job, error) {
+	job, err := s.jobs.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job: %w", err)
+	}
+	if (job.Type != "backup" && job.Type != "replication") || job.Status != "failed" {
+		return nil, nil
+	}
+
+	// A duplicate failure report must not fork the chain.
+	chain, err := s.jobs.ListRetryChain(ctx, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load retry chain: %w", err)
+	}
+	if last := chain[len(chain)-1]; last.ID != job.ID {
+		return &last, nil
+	}
+
+	policy, destinations, err := s.policies.GetByIDWithDestinations(ctx, job.PolicyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policy: %w", err)
+	}
+	if !policy.Enabled || job.Attempt >= policy.RetryMaxAttempts || !retriesOn(policy, job.FailureClass) {
+		return nil, nil
+	}
+
+	notBefore := time.Now().UTC().Add(retryBackoff(policy, job.Attempt))
+	retry := &db.Job{
+		PolicyID:  job.PolicyID,
+		AgentID:   job.AgentID,
+		Type:      job.Type,
+		Status:    "pending",
+		Attempt:   job.Attempt + 1,
+		RetryOfID: &job.ID,
+		NotBefore: &notBefore,
+		// The retry repeats the same run, with the same tags and retention.
+		ScheduleID: job.ScheduleID,
+	}
+	if err := s.jobs.Create(ctx, retry); err != nil {
+		return nil, fmt.Errorf("failed to create retry job: %w", err)
+	}
+	s.createJobDestinations(ctx, retry, destinations)
+	s.armRetry(retry)
+
+	s.logger.Info("failed job will be retried",
+		zap.String("job_id", job.ID.String()),
+		zap.String("retry_job_id", retry.ID.String()),
+		zap.String("failure_class", job.FailureClass),
+		zap.Int("attempt", retry.Attempt),
+		zap.Int("max_attempts", policy.RetryMaxAttempts),
+		zap.Time("not_before", notBefore),
+	)
+	return retry, nil
+}
+
+// armRetry registers a one-shot gocron timer that dispatches a retry job
+// once its NotBefore has passed. Retries whose backoff already elapsed (e.g.
+// while the server was down) fire immediately.
+func (s *Scheduler) armRetry(job *db.Job) {
+	startAt := gocron.OneTimeJobStartImmediately()
+	if job.NotBefore != nil && job.NotBefore.After(time.Now()) {
+		startAt = gocron.OneTimeJobStartDateTime(*job.NotBefore)
+	}
+	if _, err := s.cron.NewJob(
+		gocron.OneTimeJob(startAt),
+		gocron.NewTask(s.dispatchRetry, job.ID),
+		gocron.WithTags(retryTagPrefix+job.ID.String()),
+	); err != nil {
+		// The job stays pending and is picked up by DispatchPending on the
+		// agent's next reconnect.
+		s.logger.Error("failed to arm retry timer",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// dispatchRetry sends a retry job to its agent when its backoff elapses.
+// Retries of policies disabled in the meantime are cancelled.
+func (s *Scheduler) dispatchRetry(jobID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	job, err := s.jobs.GetByID(ctx, jobID)
+	if err != nil {
+		s.logger.Warn("failed to load retry job", zap.String("job_id", jobID.String()), zap.Error(err))
+		return
+	}
+	if job.Status != "pending" {
+		return
+	}
+
+	policy, destinations, err := s.policies.GetByIDWithDestinations(ctx, job.PolicyID)
+	if err != nil {
+		s.logger.Warn("failed to load policy for retry job",
+			zap.String("job_id", jobID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	if !policy.Enabled {
+		now := time.Now().UTC()
+		if err := s.jobs.UpdateStatus(ctx, jobID, "cancelled", nil, &now, "policy disabled before retry"); err != nil {
+			s.logger.Warn("failed to cancel retry job", zap.String("job_id", jobID.String()), zap.Error(err))
+		}
+		return
+	}
+
+	if err := s.dispatch(job, policy, destinations); err != nil {
+		s.logger.Warn("retry dispatch failed, job remains pending",
+			zap.String("job_id", jobID.String()),
+			zap.String("agent_id", job.AgentID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// TriggerDependents starts a job for every policy that runs after the policy
+// of the given job and whose dependency condition matches the job's outcome.
+// Only final outcomes of backup jobs count: the caller must not call it for a
+// failure that is going to be retried. Disabled or deleted dependents are
+// skipped. Returns the started jobs.
+func (s *Scheduler) TriggerDependents(ctx context.Context, jobID uuid.UUID) []*db.Job {
+	job, err := s.jobs.GetByID(ctx, jobID)
+	if err != nil {
+		s.logger.Warn("failed to load job for dependents", zap.String("job_id", jobID.String()), zap.Error(err))
+		return nil
+	}
+	if job.Type != "backup" || (job.Status != "succeeded" && job.Status != "failed" && job.Status != "partial") {
+		return nil
+	}
+
+	deps, err := s.policies.ListDependents(ctx, job.PolicyID)
+	if err != nil {
+		s.logger.Error("failed to list dependent policies",
+			zap.String("policy_id", job.PolicyID.String()),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	var started []*db.Job
+	for _, dep := range deps {
+		if !dependencyMatches(dep.Condition, job.Status) {
+			continue
+		}
+		policy, destinations, err := s.policies.GetByIDWithDestinations(ctx, dep.PolicyID)
+		if err != nil {
+			if !errors.Is(err, repositories.ErrNotFound) {
+				s.logger.Warn("failed to load dependent policy",
+					zap.String("policy_id", dep.PolicyID.String()),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+		s.logger.Info("dependency triggered",
+			zap.String("policy_id", policy.ID.String()),
+			zap.String("policy_name", policy.Name),
+			zap.String("upstream_job_id", job.ID.String()),
+			zap.String("upstream_status", job.Status),
+			zap.String("condition", dep.Condition),
+		)
+		j, err := s.runJob(policy, destinations, &job.ID, nil)
+		if err != nil {
+			if !errors.Is(err, ErrPolicyDisabled) {
+				s.logger.Error("dependent job run failed",
+					zap.String("policy_id", policy.ID.String()),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+		started = append(started, j)
+	}
+	return started
+}
+
+// dependencyMatches reports whether a dependency with the given condition
+// fires for an upstream job that ended with status.
+func dependencyMatches(condition, status string) bool {
+	switch condition {
+	case DependencyAlways:
+		return true
+	case DependencyOnFailure:
+		// A partial backup failed on at least one destination.
+		return status == "failed" || status == "partial"
+	default:
+		return status == "succeeded"
+	}
+}
+
+// FindDependencyCycle reports whether giving policyID the upstream policies
+// dependsOn would create a cycle, given the existing dependencies edges (the
+// current dependencies of policyID itself are ignored). It returns the cycle
+// as a path of policy IDs starting and ending with policyID, or nil.
+func FindDependencyCycle(edges []db.PolicyDependency, policyID uuid.UUID, dependsOn []uuid.UUID) []uuid.UUID {
+	upstream := make(map[uuid.UUID][]uuid.UUID)
+	for _, e := range edges {
+		if e.PolicyID != policyID {
+			upstream[e.PolicyID] = append(upstream[e.PolicyID], e.DependsOnID)
+		}
+	}
+	upstream[policyID] = dependsOn
+
+	// Depth-first search along "runs after" edges, looking for a way back to
+	// policyID.
+	visited := make(map[uuid.UUID]bool)
+	var path []uuid.UUID
+	var visit func(id uuid.UUID) bool
+	visit = func(id uuid.UUID) bool {
+		path = append(path, id)
+		for _, next := range upstream[id] {
+			if next == policyID {
+				path = append(path, next)
+				return true
+			}
+			if !visited[next] {
+				visited[next] = true
+				if visit(next) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if visit(policyID) {
+		return path
+	}
+	return nil
+}
+
+// changeRatio returns the share of the files processed by a backup run that
+// were new or changed.
+func changeRatio(run db.JobDestination) float64 {
+	if run.TotalFiles <= 0 {
+		return 0
+	}
+	return float64(run.FilesNew+run.FilesChanged) / float64(run.TotalFiles)
+}
+
+// detectAnomaly compares the change ratio of run with the baseline runs. It
+// returns a human-readable reason when the run is anomalous, or "" if not.
+func detectAnomaly(run db.JobDestination, baseline []db.JobDestination) string {
+	if len(baseline) < anomalyMinBaselineRuns {
+		return ""
+	}
+	ratio := changeRatio(run)
+	if ratio < anomalyMinChangeRatio {
+		return ""
+	}
+
+	var sum, sumSq float64
+	for _, b := range baseline {
+		r := changeRatio(b)
+		sum += r
+		sumSq += r * r
+	}
+	n := float64(len(baseline))
+	mean := sum / n
+	stddev := math.Sqrt(math.Max(sumSq/n-mean*mean, 0))
+	if ratio <= mean+anomalyDeviations*stddev {
+		return ""
+	}
+	return fmt.Sprintf("%.0f%% of files new or changed (%d of %d), against %.0f%% on average over the previous %d runs",
+		ratio*100, run.FilesNew+run.FilesChanged, run.TotalFiles, mean*100, len(baseline))
+}
+
+// CheckBackupAnomaly compares the change statistics of a successful backup
+// run to a destination with the previous runs of the policy to the same
+// destination. An anomalous run is flagged and notified. If the policy holds
+// snapshots on anomalies, the ID of the last known-good snapshot is returned:
+// the agent tags it with HoldTag before applying retention. Returns "" when
+// nothing is to be held. Errors are logged, never propagated: anomaly
+// detection must not fail the backup report.
+func (s *Scheduler) CheckBackupAnomaly(ctx context.Context, job *db.Job, run *db.JobDestination) string {
+	if job.Type != "backup" {
+		return ""
+	}
+	policy, err := s.policies.GetByID(ctx, job.PolicyID)
+	if err != nil {
+		s.logger.Warn("anomaly check: policy not found",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err),
+		)
+		return ""
+	}
+	if !policy.AnomalyDetection {
+		return ""
+	}
+
+	baseline, err := s.jobs.ListBaselineRuns(ctx, policy.ID, run.DestinationID, run.ID, anomalyBaselineRuns)
+	if err != nil {
+		s.logger.Warn("anomaly check: failed to load baseline runs",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err),
+		)
+		return ""
+	}
+	reason := detectAnomaly(*run, baseline)
+	if reason == "" {
+		return ""
+	}
+
+	if err := s.jobs.MarkDestinationAnomaly(ctx, run.ID, reason); err != nil {
+		s.logger.Warn("anomaly check: failed to flag job destination",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	// baseline is ordered newest first and only holds successful runs that
+	// were not anomalous themselves.
+	var hold string
+	if policy.AnomalyHold {
+		for _, b := range baseline {
+			if b.SnapshotID != "" {
+				hold = b.SnapshotID
+				break
+			}
+		}
+	}
+
+	s.logger.Warn("anomalous backup run detected",
+		zap.String("job_id", job.ID.String()),
+		zap.String("policy_id", policy.ID.String()),
+		zap.String("destination_id", run.DestinationID.String()),
+		zap.String("reason", reason),
+		zap.String("held_snapshot_id", hold),
+	)
+	s.notifyAnomaly(job, policy.Name, run.DestinationID, reason, hold)
+	return hold
+}
+
+// JobFinished releases the destination slots held by a job that reached a
+// terminal state and dispatches queued jobs that now fit. Called by the gRPC
+// server when an agent reports a terminal job status.
+func (s *Scheduler) JobFinished(ctx context.Context, jobID uuid.UUID) {
+	s.dequeue(jobID)
+	if s.agentMgr.Release(jobID.String()) {
+		s.DispatchQueued(ctx)
+	}
+}
+
+// DispatchQueued walks the wait queue in FIFO order and dispatches every job
+// whose destinations have a free slot. Jobs that are no longer pending, or
+// whose agent went offline, leave the queue: the latter are picked up again
+// by DispatchPending when the agent reconnects.
+func (s *Scheduler) DispatchQueued(ctx context.Context) {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+
+	for _, q := range s.Queue() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		job, err := s.jobs.GetByID(ctx, q.JobID)
+		if err != nil || job.Status != "pending" {
+			s.dequeue(q.JobID)
+			continue
+		}
+		policy, destinations, err := s.policies.GetByIDWithDestinations(ctx, job.PolicyID)
+		if err != nil {
+			s.logger.Warn("failed to load policy for queued job",
+				zap.String("job_id", q.JobID.String()),
+				zap.Error(err),
+			)
+			s.dequeue(q.JobID)
+			continue
+		}
+
+		err = s.send(job, policy, destinations)
+		switch {
+		case err == nil:
+			s.dequeue(q.JobID)
+			s.logger.Info("queued job dispatched",
+				zap.String("job_id", q.JobID.String()),
+				zap.Duration("waited", time.Since(q.QueuedAt)),
+			)
+		case errors.Is(err, agentmanager.ErrDestinationBusy):
+			// Keep the job at its position in the queue.
+		default:
+			s.dequeue(q.JobID)
+			s.logger.Warn("failed to dispatch queued job, job remains pending",
+				zap.String("job_id", q.JobID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// Queue returns a snapshot of the wait queue, oldest first.
+func (s *Scheduler) Queue() []QueuedJob {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+	return slices.Clone(s.queue)
+}
+
+// RunningByDestination returns the number of backup jobs currently holding a
+// slot on each destination.
+func (s *Scheduler) RunningByDestination() map[uuid.UUID]int {
+	running := s.agentMgr.RunningByDestination()
+	result := make(map[uuid.UUID]int, len(running))
+	for raw, n := range running {
+		if id, err := uuid.Parse(raw); err == nil {
+			result[id] = n
+		}
+	}
+	return result
+}
+
+// enqueue appends a job to the wait queue unless it is already queued, in
+// which case it keeps its position.
+func (s *Scheduler) enqueue(job *db.Job, policyDests []db.PolicyDestination) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	if slices.ContainsFunc(s.queue, func(q QueuedJob) bool { return q.JobID == job.ID }) {
+		return
+	}
+	destIDs := make([]uuid.UUID, len(policyDests))
+	for i, pd := range policyDests {
+		destIDs[i] = pd.DestinationID
+	}
+	s.queue = append(s.queue, QueuedJob{
+		JobID:          job.ID,
+		PolicyID:       job.PolicyID,
+		AgentID:        job.AgentID,
+		DestinationIDs: destIDs,
+		QueuedAt:       time.Now().UTC(),
+	})
+}
+
+// dequeue removes a job from the wait queue. No-op if it is not queued.
+func (s *Scheduler) dequeue(jobID uuid.UUID) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+	s.queue = slices.DeleteFunc(s.queue, func(q QueuedJob) bool { return q.JobID == jobID })
+}
+
+// missedRunAt returns the first cron fire time of policy that fell after its
+// last activity and at or before now, i.e. a run gocron never fired because
+// the server was down, together with the schedule entry it belongs to (nil
+// for Policy.Schedule). The zero time means nothing was missed.
+//
+// The reference point is the later of LastRunAt and the creation time of the
+// newest job for the policy, so that a run already recorded as missed (which
+// does not touch LastRunAt) is not reported again on the next restart. Policies
+// that never ran are measured from their creation time.
+func (s *Scheduler) missedRunAt(ctx context.Context, policy *db.Policy, now time.Time) (time.Time, *db.PolicySchedule, error) {
+	crons, err := s.policyCrons(ctx, policy)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	if len(crons) == 0 {
+		return time.Time{}, nil, nil
+	}
+
+	since := policy.CreatedAt
+	if policy.LastRunAt != nil {
+		since = *policy.LastRunAt
+	}
+	latest, _, err := s.jobs.ListByPolicy(ctx, policy.ID, repositories.ListOptions{Limit: 1})
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to load latest job: %w", err)
+	}
+	if len(latest) > 0 && latest[0].CreatedAt.After(since) {
+		since = latest[0].CreatedAt
+	}
+
+	var next time.Time
+	var entry *db.PolicySchedule
+	for _, c := range crons {
+		sched, err := cronParser.Parse(cronSpec(policy, c.schedule))
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("invalid schedule %q: %w", c.schedule, err)
+		}
+		if t := sched.Next(since); next.IsZero() || t.Before(next) {
+			next, entry = t, c.entry
+		}
+	}
+	if next.After(now) {
+		return time.Time{}, nil, nil
+	}
+	return next, entry, nil
+}
+
+// handleMissedRun checks whether policy missed a scheduled run while the
+// server was down and reacts according to Policy.CatchUp: catch-up policies
+// run once immediately, the others get a "missed" job and a notification.
+// Errors are logged — a failed check must never prevent the scheduler from
+// starting.
+func (s *Scheduler) handleMissedRun(ctx context.Context, policy *db.Policy, now time.Time) {
+	missedAt, entry, err := s.missedRunAt(ctx, policy, now)
+	if err != nil {
+		s.logger.Warn("failed to check policy for missed runs",
+			zap.String("policy_id", policy.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	if missedAt.IsZero() {
+		return
+	}
+
+	s.logger.Info("scheduled run missed while server was down",
+		zap.String("policy_id", policy.ID.String()),
+		zap.String("policy_name", policy.Name),
+		zap.Time("missed_at", missedAt),
+		zap.Bool("catch_up", policy.CatchUp),
+	)
+
+	if policy.CatchUp {
+		_, destinations, err := s.policies.GetByIDWithDestinations(ctx, policy.ID)
+		if err != nil {
+			s.logger.Error("failed to load destinations for catch-up run",
+				zap.String("policy_id", policy.ID.String()),
+				zap.Error(err),
+			)
+			return
+		}
+		if _, err := s.runJob(policy, destinations, nil, entry); err != nil {
+			s.logger.Error("catch-up run failed",
+				zap.String("policy_id", policy.ID.String()),
+				zap.Error(err),
+			)
+		}
+		return
+	}
+
+	reason := fmt.Sprintf("scheduled run at %s was missed while the server was down", missedAt.Format(time.RFC3339))
+	job := &db.Job{
+		PolicyID: policy.ID,
+		AgentID:  policy.AgentID,
+		Status:   "missed",
+		EndedAt:  &now,
+		Error:    reason,
+	}
+	if entry != nil {
+		job.ScheduleID = &entry.ID
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		s.logger.Error("failed to record missed job",
+			zap.String("policy_id", policy.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	s.notifyMissed(job, policy.Name, reason)
+}
+
+// expirePendingJobs marks jobs that have been pending for longer than
+// pendingDeadline as "missed", provided their agent is still offline. Jobs
+// whose agent is connected are left alone: they are either in flight or will
+// be picked up by DispatchPending. Runs periodically as a gocron job.
+func (s *Scheduler) expirePendingJobs() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	pending, err := s.jobs.ListPendingBefore(ctx, now.Add(-s.pendingDeadline))
+	if err != nil {
+		s.logger.Error("failed to list expired pending jobs", zap.Error(err))
+		return
+	}
+
+	for i := range pending {
+		j := &pending[i]
+		if s.agentMgr.IsConnected(j.AgentID.String()) {
+			continue
+		}
+
+		reason := fmt.Sprintf("agent stayed offline for more than %s", s.pendingDeadline)
+		if err := s.jobs.UpdateStatus(ctx, j.ID, "missed", nil, &now, reason); err != nil {
+			s.logger.Warn("failed to mark pending job as missed",
+				zap.String("job_id", j.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		s.logger.Info("pending job expired",
+			zap.String("job_id", j.ID.String()),
+			zap.String("agent_id", j.AgentID.String()),
+		)
+
+		policyName := ""
+		if policy, err := s.policies.GetByID(ctx, j.PolicyID); err == nil {
+			policyName = policy.Name
+		}
+		s.notifyMissed(j, policyName, reason)
+	}
+}
+
+// failStuckJobs fails running jobs that have shown no status or log activity
+// for longer than stuckJobTimeout and asks their agent to abort them. Covers
+// agents that hang without closing their stream, which orphan recovery in the
+// gRPC server never sees. Runs periodically as a gocron job.
+func (s *Scheduler) failStuckJobs(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	stuck, err := s.jobs.ListRunningInactiveSince(ctx, time.Now().UTC().Add(-s.stuckJobTimeout))
+	if err != nil {
+		s.logger.Error("failed to list stuck jobs", zap.Error(err))
+		return
+	}
+
+	for i := range stuck {
+		j := &stuck[i]
+		reason := fmt.Sprintf("no activity from agent for more than %s", s.stuckJobTimeout)
+		failed, err := s.jobs.FailRunningJob(ctx, j.ID, reason)
+		if err != nil {
+			s.logger.Warn("failed to fail stuck job",
+				zap.String("job_id", j.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		if !failed {
+			// The agent reported a final status in the meantime.
+			continue
+		}
+		s.logger.Warn("stuck job failed by watchdog",
+			zap.String("job_id", j.ID.String()),
+			zap.String("agent_id", j.AgentID.String()),
+			zap.Duration("timeout", s.stuckJobTimeout),
+		)
+
+		if err := s.agentMgr.Abort(j.AgentID.String(), j.ID.String()); err != nil {
+			s.logger.Warn("failed to abort stuck job on agent",
+				zap.String("job_id", j.ID.String()),
+				zap.String("agent_id", j.AgentID.String()),
+				zap.Error(err),
+			)
+		}
+		s.jobFailed(ctx, j, FailureOther, reason)
+	}
+}
+
+// jobFailed applies to a job the server itself failed what the gRPC server
+// does when an agent reports a failure: it records the failure class, retries
+// backup and replication jobs according to their policy, frees the job's
+// destination slots and, once the failure is final, starts the dependent
+// policies of a backup and notifies. Dry runs are not notified.
+func (s *Scheduler) jobFailed(ctx context.Context, j *db.Job, failureClass, reason string) {
+	if err := s.jobs.SetFailureClass(ctx, j.ID, failureClass); err != nil {
+		s.logger.Warn("failed to record job failure class",
+			zap.String("job_id", j.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	var retry *db.Job
+	if j.Type == "backup" || j.Type == "replication" {
+		var err error
+		retry, err = s.RetryFailedJob(ctx, j.ID)
+		if err != nil {
+			s.logger.Error("failed to schedule job retry",
+				zap.String("job_id", j.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	s.JobFinished(ctx, j.ID)
+	if retry != nil {
+		return
+	}
+	if j.Type == "backup" {
+		s.TriggerDependents(ctx, j.ID)
+	}
+	if j.Type != "dry_run" {
+		policyName := ""
+		if policy, err := s.policies.GetByID(ctx, j.PolicyID); err == nil {
+			policyName = policy.Name
+		}
+		s.notifyFailed(j, policyName, reason)
+	}
+}
+
+// notifyFailed fires a job-failed notification in a goroutine. No-op without
+// a NotifService.
+func (s *Scheduler) notifyFailed(job *db.Job, policyName, reason string) {
+	if s.notifSvc == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s.notifSvc.NotifyJobFailed(ctx, job.ID, job.PolicyID, policyName, reason); err != nil {
+			s.logger.Warn("failed to send job-failed notification", zap.Error(err))
+		}
+	}()
+}
+
+// notifyMissed fires a missed-run notification in a goroutine so that a slow
+// notification path never delays scheduling. No-op without a NotifService.
+func (s *Scheduler) notifyMissed(job *db.Job, policyName, reason string) {
+	if s.notifSvc == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s.notifSvc.NotifyJobMissed(ctx, job.ID, job.PolicyID, policyName, reason); err != nil {
+			s.logger.Warn("failed to send job-missed notification", zap.Error(err))
+		}
+	}()
+}
+
+// notifyAnomaly fires a backup-anomaly notification in a goroutine. No-op
+// without a NotifService.
+func (s *Scheduler) notifyAnomaly(job *db.Job, policyName string, destinationID uuid.UUID, reason, heldSnapshotID string) {
+	if s.notifSvc == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		destName := destinationID.String()
+		if dest, err := s.dests.GetByID(ctx, destinationID); err == nil {
+			destName = dest.Name
+		}
+		if err := s.notifSvc.NotifyBackupAnomaly(ctx, job.ID, job.PolicyID, policyName, destName, reason, heldSnapshotID); err != nil {
+			s.logger.Warn("failed to send backup-anomaly notification", zap.Error(err))
 		}
-		if err := s.jobs.CreateDestination(ctx, jd); err != nil {
-			// Log but continue — we still want to attempt other destinations.
-			s.logger.Error("failed to create job destination record",
-				zap.String("job_id", job.ID.String()),
-				zap.String("destination_id", pd.DestinationID.String()),
-				zap.Error(err),
-			)
+	}()
+}
+
+// cronJob returns the gocron job registered for one schedule of policyID:
+// the schedule entry scheduleID, or Policy.Schedule when scheduleID is nil.
+// Returns nil if the schedule is not registered.
+func (s *Scheduler) cronJob(policyID uuid.UUID, scheduleID *uuid.UUID) gocron.Job {
+	tag := policyID.String()
+	for _, j := range s.cron.Jobs() {
+		tags := j.Tags()
+		if !slices.Contains(tags, tag) {
+			continue
+		}
+		if scheduleID == nil && len(tags) == 1 || scheduleID != nil && slices.Contains(tags, scheduleID.String()) {
+			return j
+		}
+	}
+	return nil
+}
+
+// nextRunAt returns the next run of policy whose fire time is strictly after
+// the given time, across all of its schedules and including the policy's
+// start delay, or the zero time for a policy without a schedule. The gocron
+// job handles are authoritative; a cron expression is only evaluated directly
+// when its handle has no usable value yet, i.e. before the scheduler is
+// started or while gocron is still rescheduling the job whose tick is
+// currently executing (its NextRun is then the current tick).
+func (s *Scheduler) nextRunAt(ctx context.Context, policy *db.Policy, after time.Time) (time.Time, error) {
+	crons, err := s.policyCrons(ctx, policy)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Policies without a schedule have no next run: the zero time.
+	var next time.Time
+	for _, c := range crons {
+		t, err := s.cronNextRun(policy, c, after)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+	if next.IsZero() {
+		return next, nil
+	}
+	return next.Add(startDelay(policy)).UTC(), nil
+}
+
+// cronNextRun returns the first fire time of one schedule of policy strictly
+// after the given time, without the start delay.
+func (s *Scheduler) cronNextRun(policy *db.Policy, c policyCron, after time.Time) (time.Time, error) {
+	if j := s.cronJob(policy.ID, c.scheduleID()); j != nil {
+		if next, err := j.NextRun(); err == nil && next.After(after) {
+			return next, nil
+		}
+	}
+	sched, err := cronParser.Parse(cronSpec(policy, c.schedule))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid schedule %q: %w", c.schedule, err)
+	}
+	return sched.Next(after), nil
+}
+
+// refreshNextRun recomputes and persists NextRunAt for a freshly scheduled
+// policy. Errors are logged — a stale NextRunAt is cosmetic.
+func (s *Scheduler) refreshNextRun(ctx context.Context, policy *db.Policy) {
+	next, err := s.nextRunAt(ctx, policy, time.Now())
+	if err != nil {
+		s.logger.Warn("failed to compute next run",
+			zap.String("policy_id", policy.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	var nextRun *time.Time
+	if !next.IsZero() {
+		nextRun = &next
+	}
+	if err := s.policies.UpdateNextRun(ctx, policy.ID, nextRun); err != nil {
+		s.logger.Warn("failed to update next run",
+			zap.String("policy_id", policy.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	policy.NextRunAt = nextRun
+}
+
+// addJob registers every schedule of a policy as a gocron job with
+// singleton mode. The policy UUID is used as the gocron tag of all of them
+// for later identification; entry jobs are also tagged with the entry UUID.
+func (s *Scheduler) addJob(ctx context.Context, policy *db.Policy) error {
+	// Policies without a schedule only run via their dependencies.
+	crons, err := s.policyCrons(ctx, policy)
+	if err != nil {
+		return err
+	}
+	for _, c := range crons {
+		if err := s.addCronJob(policy, c); err != nil {
+			s.cron.RemoveByTags(policy.ID.String())
+			return err
 		}
 	}
+	return nil
+}
+
+// addCronJob registers one schedule of a policy as a gocron job.
+func (s *Scheduler) addCronJob(policy *db.Policy, c policyCron) error {
+	tags := []string{policy.ID.String()}
+	if c.entry != nil {
+		tags = append(tags, c.entry.ID.String())
+	}
+	_, err := s.cron.NewJob(
+		gocron.CronJob(cronSpec(policy, c.schedule), false),
+		gocron.NewTask(func(jobCtx context.Context, p db.Policy) {
+			// Wait out the policy's start delay. jobCtx is cancelled when the
+			// policy is removed or the scheduler shuts down, which drops the
+			// delayed run instead of blocking shutdown.
+			if delay := startDelay(&p); delay > 0 {
+				select {
+				case <-jobCtx.Done():
+					return
+				case <-time.After(delay):
+				}
+			}
+
+			// Re-fetch destinations at tick time to pick up any changes made
+			// since the job was scheduled. The policy snapshot passed in via
+			// closure may be stale if destinations were added or removed.
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, destinations, err := s.policies.GetByIDWithDestinations(ctx, p.ID)
+			if err != nil {
+				s.logger.Error("failed to load destinations at tick time",
+					zap.String("policy_id", p.ID.String()),
+					zap.Error(err),
+				)
+				return
+			}
+
+			if _, err := s.runJob(&p, destinations, nil, c.entry); err != nil && !errors.Is(err, ErrPolicyDisabled) {
+				s.logger.Error("job run failed",
+					zap.String("policy_id", p.ID.String()),
+					zap.String("policy_name", p.Name),
+					zap.Error(err),
+				)
+			}
+		}, *policy),
+		gocron.WithTags(tags...),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return fmt.Errorf("gocron.NewJob failed for policy %s (schedule: %q): %w",
+			policy.ID, c.schedule, err)
+	}
+	return nil
+}
+
+// runJob is the core execution unit called by gocron on each tick (or manually
+// via TriggerNow, or by a dependency via TriggerDependents). It creates the
+// Job and JobDestination DB records, updates policy timestamps, and
+// dispatches the assignment to the agent. triggeredBy is the upstream job of
+// a dependency run, entry the schedule entry of a run started by one; both
+// are nil otherwise.
+// It returns the created Job so callers can surface its ID.
+func (s *Scheduler) runJob(policy *db.Policy, destinations []db.PolicyDestination, triggeredBy *uuid.UUID, entry *db.PolicySchedule) (*db.Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if !policy.Enabled {
+		s.logger.Info("skipping job for disabled policy",
+			zap.String("policy_id", policy.ID.String()),
+		)
+		return nil, ErrPolicyDisabled
+	}
+
+	// --- Create Job record ---
+	job := &db.Job{
+		PolicyID:      policy.ID,
+		AgentID:       policy.AgentID,
+		Status:        "pending",
+		TriggeredByID: triggeredBy,
+	}
+	if policy.Type == PolicyTypeReplication {
+		job.Type = "replication"
+	}
+	if entry != nil {
+		job.ScheduleID = &entry.ID
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create job record for policy %s: %w", policy.ID, err)
+	}
+
+	s.logger.Info("job created",
+		zap.String("job_id", job.ID.String()),
+		zap.String("policy_id", policy.ID.String()),
+		zap.String("policy_name", policy.Name),
+		zap.String("agent_id", policy.AgentID.String()),
+	)
+
+	// --- Create JobDestination records ---
+	s.createJobDestinations(ctx, job, destinations)
 
 	// --- Update policy schedule timestamps ---
 	now := time.Now().UTC()
-	if err := s.policies.UpdateSchedule(ctx, policy.ID, now, now); err != nil {
+	if next, err := s.nextRunAt(ctx, policy, now); err != nil {
+		s.logger.Warn("failed to compute next run",
+			zap.String("policy_id", policy.ID.String()),
+			zap.Error(err),
+		)
+	} else if err := s.policies.UpdateSchedule(ctx, policy.ID, now, next); err != nil {
 		// Non-fatal — the job was already created, just log the failure.
 		s.logger.Warn("failed to update policy schedule timestamps",
 			zap.String("policy_id", policy.ID.String()),
 			zap.Error(err),
 		)
+	} else if next.IsZero() {
+		// Dependency-only policies have no next run.
+		if err := s.policies.UpdateNextRun(ctx, policy.ID, nil); err != nil {
+			s.logger.Warn("failed to clear next run",
+				zap.String("policy_id", policy.ID.String()),
+				zap.Error(err),
+			)
+		}
 	}
 
 	// --- Dispatch to agent ---
@@ -363,17 +2019,68 @@ func (s *Scheduler) runJob(policy *db.Policy, destinations []db.PolicyDestinatio
 	return job, nil
 }
 
-// dispatch builds a complete JobAssignment with the full backup payload and
-// sends it to the agent via AgentManager. It loads full destination records
-// (including decrypted credentials) so the agent has everything it needs
-// without making additional calls back to the server.
+// createJobDestinations creates a pending JobDestination record for each of
+// the policy's destinations.
+func (s *Scheduler) createJobDestinations(ctx context.Context, job *db.Job, destinations []db.PolicyDestination) {
+	for _, pd := range destinations {
+		jd := &db.JobDestination{
+			JobID:         job.ID,
+			DestinationID: pd.DestinationID,
+			Status:        "pending",
+		}
+		if err := s.jobs.CreateDestination(ctx, jd); err != nil {
+			// Log but continue — we still want to attempt other destinations.
+			s.logger.Error("failed to create job destination record",
+				zap.String("job_id", job.ID.String()),
+				zap.String("destination_id", pd.DestinationID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// dispatch sends a job to its agent, or puts it in the wait queue when one of
+// its destinations is at its concurrency limit. A queued job is not an error:
+// it stays pending and is sent by DispatchQueued once a slot frees up.
 func (s *Scheduler) dispatch(job *db.Job, policy *db.Policy, policyDests []db.PolicyDestination) error {
+	if job.Type == "dry_run" {
+		return s.sendDryRun(job, policy)
+	}
+	err := s.send(job, policy, policyDests)
+	if errors.Is(err, agentmanager.ErrDestinationBusy) {
+		s.enqueue(job, policyDests)
+		s.logger.Info("destination at concurrency limit, job queued",
+			zap.String("job_id", job.ID.String()),
+			zap.String("policy_id", job.PolicyID.String()),
+			zap.Error(err),
+		)
+		return nil
+	}
+	if err == nil {
+		s.dequeue(job.ID)
+	}
+	return err
+}
+
+// send builds a complete JobAssignment with the full backup payload and
+// sends it to the agent via AgentManager, reserving a slot on each
+// destination. It loads full destination records (including decrypted
+// credentials) so the agent has everything it needs without making
+// additional calls back to the server. Returns an error wrapping
+// agentmanager.ErrDestinationBusy when a destination is full. Replication
+// jobs are sent by sendReplication.
+func (s *Scheduler) send(job *db.Job, policy *db.Policy, policyDests []db.PolicyDestination) error {
+	if job.Type == "replication" {
+		return s.sendReplication(job, policy, policyDests)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	limits := make(map[string]int, len(policyDests))
 	destPayloads := make([]destinationPayload, 0, len(policyDests))
 	for _, pd := range policyDests {
-		dest, err := s.dests.GetByID(ctx, pd.DestinationID)
+		dest, payload, err := s.resolveDestination(ctx, pd)
 		if err != nil {
 			s.logger.Error("failed to load destination for dispatch",
 				zap.String("destination_id", pd.DestinationID.String()),
@@ -381,35 +2088,55 @@ func (s *Scheduler) dispatch(job *db.Job, policy *db.Policy, policyDests []db.Po
 			)
 			continue
 		}
-		destPayloads = append(destPayloads, destinationPayload{
-			DestinationID: dest.ID.String(),
-			Type:          dest.Type,
-			RepoURL:       destutil.BuildRepoURL(dest),
-			Credentials:   string(dest.Credentials), // decrypted by EncryptedString scanner
-			Config:        dest.Config,
-			Env:           destutil.BuildEnv(dest),
-			Priority:      pd.Priority,
-		})
+		limits[dest.ID.String()] = dest.MaxConcurrentJobs
+		if policy.ZeroKnowledge {
+			// The agent opens the repository with its own key.
+			payload.RepoPassword = ""
+			payload.KeyID = pd.KeyID()
+			payload.KeyFingerprint = pd.KeyFingerprint
+		}
+		destPayloads = append(destPayloads, payload)
 	}
 
 	sourcesFlat, err := buildSourcesList(policy.Sources)
 	if err != nil {
 		return fmt.Errorf("failed to build sources list: %w", err)
 	}
+	databases, err := s.buildDatabases(ctx, policy.Sources)
+	if err != nil {
+		return fmt.Errorf("failed to build database sources: %w", err)
+	}
+	commands, err := buildCommands(policy.Sources)
+	if err != nil {
+		return fmt.Errorf("failed to build command sources: %w", err)
+	}
+	hooks, err := ParseHooks(policy.Hooks)
+	if err != nil {
+		return fmt.Errorf("failed to load hooks: %w", err)
+	}
+
+	entries, err := s.policies.ListSchedules(ctx, policy.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load schedule entries: %w", err)
+	}
+	tags, retention := snapshotSettings(policy, job.ScheduleID, entries)
 
 	payload := backupPayload{
 		Sources:      sourcesFlat,
 		RepoPassword: string(policy.RepoPassword), // decrypted
 		Destinations: destPayloads,
-		Retention: retentionPayload{
-			Daily:   policy.RetentionDaily,
-			Weekly:  policy.RetentionWeekly,
-			Monthly: policy.RetentionMonthly,
-			Yearly:  policy.RetentionYearly,
-		},
-		HookPreBackup:  policy.HookPreBackup,
-		HookPostBackup: policy.HookPostBackup,
-		Tags:           []string{fmt.Sprintf("policy:%s", policy.ID.String())},
+		Retention:    retention,
+		Tags:         tags,
+		Hooks:        hooks.Phases(HookPreBackup, HookPostBackup, HookOnSuccess, HookOnFailure),
+
+		MaxRuntimeSeconds: policy.MaxRuntimeSeconds,
+		FanOut:            policy.FanOut,
+		Parallelism:       policy.FanOutParallelism,
+		Databases:         databases,
+		Commands:          commands,
+	}
+	if policy.ZeroKnowledge {
+		payload.EscrowPublicKey = s.escrowPublicKey(ctx)
 	}
 
 	payloadBytes, err := json.Marshal(payload)
@@ -425,7 +2152,7 @@ func (s *Scheduler) dispatch(job *db.Job, policy *db.Policy, policyDests []db.Po
 		ScheduledAt: timestamppb.Now(),
 	}
 
-	if err := s.agentMgr.Dispatch(job.AgentID.String(), assignment); err != nil {
+	if err := s.agentMgr.DispatchLimited(job.AgentID.String(), assignment, limits); err != nil {
 		return fmt.Errorf("agentmanager dispatch error: %w", err)
 	}
 
@@ -437,14 +2164,211 @@ func (s *Scheduler) dispatch(job *db.Job, policy *db.Policy, policyDests []db.Po
 	return nil
 }
 
+// sendDryRun sends a dry-run job to its agent. The payload only carries the
+// sources: the agent runs the dry run against a scratch repository.
+func (s *Scheduler) sendDryRun(job *db.Job, policy *db.Policy) error {
+	sourcesFlat, err := buildSourcesList(policy.Sources)
+	if err != nil {
+		return fmt.Errorf("failed to build sources list: %w", err)
+	}
+
+	payloadBytes, err := json.Marshal(dryRunPayload{
+		Sources:           sourcesFlat,
+		MaxRuntimeSeconds: policy.MaxRuntimeSeconds,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+
+	assignment := &proto.JobAssignment{
+		JobId:       job.ID.String(),
+		PolicyId:    job.PolicyID.String(),
+		Type:        proto.JobType_JOB_TYPE_DRY_RUN,
+		Payload:     payloadBytes,
+		ScheduledAt: timestamppb.Now(),
+	}
+	if err := s.agentMgr.Dispatch(job.AgentID.String(), assignment); err != nil {
+		return fmt.Errorf("agentmanager dispatch error: %w", err)
+	}
+
+	s.logger.Info("dry-run job dispatched",
+		zap.String("job_id", job.ID.String()),
+		zap.String("agent_id", job.AgentID.String()),
+	)
+	return nil
+}
+
+// sendReplication builds the payload of a replication job and sends it to
+// the agent like send, reserving a slot on the source destination as well.
+// The source repository password is the policy's or, when the policy has
+// none, the one the replicated policy uses on the source destination.
+func (s *Scheduler) sendReplication(job *db.Job, policy *db.Policy, policyDests []db.PolicyDestination) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if policy.ReplicateFromID == nil {
+		return errors.New("replication policy has no source destination")
+	}
+	source, err := s.dests.GetByID(ctx, *policy.ReplicateFromID)
+	if err != nil {
+		return fmt.Errorf("failed to load source destination: %w", err)
+	}
+	limits := map[string]int{source.ID.String(): source.MaxConcurrentJobs}
+
+	password := string(policy.RepoPassword) // decrypted
+	var tags []string
+	if policy.ReplicateTag != "" {
+		tags = append(tags, policy.ReplicateTag)
+	}
+	if policy.ReplicatePolicyID != nil {
+		tags = append(tags, fmt.Sprintf("policy:%s", policy.ReplicatePolicyID.String()))
+		if password == "" {
+			replicated, replicatedDests, err := s.policies.GetByIDWithDestinations(ctx, *policy.ReplicatePolicyID)
+			if err != nil {
+				return fmt.Errorf("failed to load replicated policy: %w", err)
+			}
+			password = string(replicated.RepoPassword)
+			for _, pd := range replicatedDests {
+				if pd.DestinationID == source.ID && pd.RepoPassword != "" {
+					password = string(pd.RepoPassword)
+				}
+			}
+		}
+	}
+
+	destPayloads := make([]destinationPayload, 0, len(policyDests))
+	for _, pd := range policyDests {
+		dest, payload, err := s.resolveDestination(ctx, pd)
+		if err != nil {
+			s.logger.Error("failed to load destination for dispatch",
+				zap.String("destination_id", pd.DestinationID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		limits[dest.ID.String()] = dest.MaxConcurrentJobs
+		destPayloads = append(destPayloads, payload)
+	}
+
+	payloadBytes, err := json.Marshal(replicatePayload{
+		RepoPassword:      password,
+		Source:            rootPayload(source),
+		Destinations:      destPayloads,
+		Tags:              tags,
+		Host:              policy.ReplicateHost,
+		MaxRuntimeSeconds: policy.MaxRuntimeSeconds,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+
+	assignment := &proto.JobAssignment{
+		JobId:       job.ID.String(),
+		PolicyId:    job.PolicyID.String(),
+		Type:        proto.JobType_JOB_TYPE_REPLICATE,
+		Payload:     payloadBytes,
+		ScheduledAt: timestamppb.Now(),
+	}
+	if err := s.agentMgr.DispatchLimited(job.AgentID.String(), assignment, limits); err != nil {
+		return fmt.Errorf("agentmanager dispatch error: %w", err)
+	}
+
+	s.logger.Info("replication job dispatched",
+		zap.String("job_id", job.ID.String()),
+		zap.String("agent_id", job.AgentID.String()),
+		zap.String("source_destination_id", source.ID.String()),
+		zap.Int("destinations", len(destPayloads)),
+	)
+	return nil
+}
+
+// resolveDestination loads the destination of a policy destination entry and
+// builds its payload, with the entry's own repository password if it has
+// one. An entry linked to a repository points at the repository's path and
+// carries its password instead, plus the repository to copy chunker
+// parameters from while the repository has not been initialised.
+func (s *Scheduler) resolveDestination(ctx context.Context, pd db.PolicyDestination) (*db.Destination, destinationPayload, error) {
+	dest, err := s.dests.GetByID(ctx, pd.DestinationID)
+	if err != nil {
+		return nil, destinationPayload{}, err
+	}
+	payload := rootPayload(dest)
+	payload.Priority = pd.Priority
+	payload.RepoPassword = string(pd.RepoPassword) // decrypted
+	if pd.RepositoryID == nil {
+		return dest, payload, nil
+	}
+
+	if s.repos == nil {
+		return nil, destinationPayload{}, errors.New("repositories are not configured")
+	}
+	repo, err := s.repos.GetByID(ctx, *pd.RepositoryID)
+	if err != nil {
+		return nil, destinationPayload{}, fmt.Errorf("failed to load repository: %w", err)
+	}
+	payload.RepoURL = destutil.BuildRepositoryURL(dest, repo.Path)
+	payload.RepositoryID = repo.ID.String()
+	payload.RepoPassword = string(repo.Password) // decrypted
+	if repo.InitializedAt == nil && repo.ChunkerFromID != nil {
+		from, err := s.repos.GetByID(ctx, *repo.ChunkerFromID)
+		if err != nil {
+			return nil, destinationPayload{}, fmt.Errorf("failed to load chunker source repository: %w", err)
+		}
+		fromDest, err := s.dests.GetByID(ctx, from.DestinationID)
+		if err != nil {
+			return nil, destinationPayload{}, fmt.Errorf("failed to load chunker source destination: %w", err)
+		}
+		chunkerFrom := rootPayload(fromDest)
+		chunkerFrom.RepoURL = destutil.BuildRepositoryURL(fromDest, from.Path)
+		chunkerFrom.RepositoryID = from.ID.String()
+		chunkerFrom.RepoPassword = string(from.Password)
+		payload.ChunkerFrom = &chunkerFrom
+	}
+	return dest, payload, nil
+}
+
+// escrowPublicKey returns the configured escrow public key, or "" when none
+// is set.
+func (s *Scheduler) escrowPublicKey(ctx context.Context) string {
+	if s.settings == nil {
+		return ""
+	}
+	setting, err := s.settings.Get(ctx, EscrowPublicKeySetting)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			s.logger.Warn("failed to load escrow public key", zap.Error(err))
+		}
+		return ""
+	}
+	return string(setting.Value)
+}
+
+// rootPayload builds the payload of the repository at the root of dest.
+func rootPayload(dest *db.Destination) destinationPayload {
+	return destinationPayload{
+		DestinationID: dest.ID.String(),
+		Type:          dest.Type,
+		RepoURL:       destutil.BuildRepoURL(dest),
+		Credentials:   string(dest.Credentials), // decrypted by EncryptedString scanner
+		Config:        dest.Config,
+		Env:           destutil.BuildEnv(dest),
+	}
+}
+
 // buildSourcesList converts the policy sources JSON (array of source objects
 // saved by the GUI) into the flat string array the agent executor expects.
 // Directory sources become plain paths; docker-volume sources become
-// "docker-volume://<volume-name>" URIs.
+// "docker-volume://<volume-name>" URIs, with "?containers=<mode>" when the
+// source has a container mode; docker-label sources become
+// "docker-label://<label>" URIs, expanded by the agent at run time into the
+// volumes carrying the label. Database and docker-dump sources are left out:
+// they travel in backupPayload.Databases, and command sources in
+// backupPayload.Commands.
 func buildSourcesList(sourcesJSON string) (string, error) {
 	var sources []struct {
-		Type string `json:"type"`
-		Path string `json:"path"`
+		Type       string `json:"type"`
+		Path       string `json:"path"`
+		Containers string `json:"containers"`
 	}
 	if err := json.Unmarshal([]byte(sourcesJSON), &sources); err != nil {
 		return "", fmt.Errorf("invalid sources JSON: %w", err)
@@ -452,7 +2376,15 @@ func buildSourcesList(sourcesJSON string) (string, error) {
 	paths := make([]string, 0, len(sources))
 	for _, s := range sources {
 		if s.Type == "docker-volume" {
-			paths = append(paths, "docker-volume://"+s.Path)
+			uri := "docker-volume://" + s.Path
+			if s.Containers != "" {
+				uri += "?containers=" + s.Containers
+			}
+			paths = append(paths, uri)
+		} else if s.Type == "docker-label" {
+			paths = append(paths, "docker-label://"+s.Path)
+		} else if s.Type == "database" || s.Type == "docker-dump" || s.Type == "command" {
+			continue
 		} else {
 			paths = append(paths, s.Path)
 		}
@@ -0,0 +1,1144 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/arkeep-io/arkeep/server/internal/agentmanager"
+	"github.com/arkeep-io/arkeep/server/internal/db"
+	"github.com/arkeep-io/arkeep/server/internal/repositories"
+	proto "github.com/arkeep-io/arkeep/shared/proto"
+)
+
+// TestMain initialises the AES key required by EncryptedString fields.
+func TestMain(m *testing.M) {
+	if err := db.InitEncryption(bytes.Repeat([]byte("k"), 32)); err != nil {
+		panic("db.InitEncryption: " + err.Error())
+	}
+	os.Exit(m.Run())
+}
+
+type testRepos struct {
+	policies repositories.PolicyRepository
+	jobs     repositories.JobRepository
+	dests    repositories.DestinationRepository
+	repos    repositories.RepositoryRepository
+	settings repositories.SettingsRepository
+	conns    repositories.DatabaseConnectionRepository
+}
+
+// newTestScheduler opens a fresh in-memory database and returns an unstarted
+// Scheduler wired to it, plus the repositories for assertions.
+func newTestScheduler(t *testing.T) (*Scheduler, *testRepos) {
+	t.Helper()
+	gdb, err := db.New(db.Config{
+		Driver:   "sqlite",
+		DSN:      ":memory:",
+		Logger:   zap.NewNop(),
+		LogLevel: gormlogger.Silent,
+	})
+	if err != nil {
+		t.Fatalf("db.New: %v", err)
+	}
+	repos := &testRepos{
+		policies: repositories.NewPolicyRepository(gdb),
+		jobs:     repositories.NewJobRepository(gdb),
+		dests:    repositories.NewDestinationRepository(gdb),
+		repos:    repositories.NewRepositoryRepository(gdb),
+		settings: repositories.NewSettingsRepository(gdb),
+		conns:    repositories.NewDatabaseConnectionRepository(gdb),
+	}
+	s, err := New(Config{Repositories: repos.repos, Settings: repos.settings, DatabaseConnections: repos.conns}, repos.policies, repos.jobs, repos.dests, agentmanager.New(zap.NewNop()), zap.NewNop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Stop() })
+	return s, repos
+}
+
+// createPolicy inserts an enabled daily policy whose last run was lastRun.
+func createPolicy(t *testing.T, repos *testRepos, catchUp bool, lastRun time.Time) *db.Policy {
+	t.Helper()
+	ctx := context.Background()
+	p := &db.Policy{
+		Name:         "nightly",
+		AgentID:      uuid.New(),
+		Schedule:     "0 2 * * *",
+		Enabled:      true,
+		Sources:      `[{"type":"directory","path":"/data"}]`,
+		RepoPassword: "secret",
+		CatchUp:      catchUp,
+	}
+	if err := repos.policies.Create(ctx, p); err != nil {
+		t.Fatalf("Create policy: %v", err)
+	}
+	if err := repos.policies.UpdateSchedule(ctx, p.ID, lastRun, lastRun); err != nil {
+		t.Fatalf("UpdateSchedule: %v", err)
+	}
+	return p
+}
+
+func listJobs(t *testing.T, repos *testRepos, policyID uuid.UUID) []repositories.JobWithNames {
+	t.Helper()
+	jobs, _, err := repos.jobs.ListByPolicy(context.Background(), policyID, repositories.ListOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListByPolicy: %v", err)
+	}
+	return jobs
+}
+
+func TestStart_RecordsMissedRun(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	p := createPolicy(t, repos, false, time.Now().UTC().Add(-72*time.Hour))
+
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	jobs := listJobs(t, repos, p.ID)
+	if len(jobs) != 1 {
+		t.Fatalf("jobs = %d, want 1", len(jobs))
+	}
+	if jobs[0].Status != "missed" {
+		t.Errorf("status = %q, want missed", jobs[0].Status)
+	}
+
+	// A second start must not report the same missed run again.
+	s2, err := New(Config{}, repos.policies, repos.jobs, repos.dests, agentmanager.New(zap.NewNop()), zap.NewNop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = s2.Stop() })
+	if err := s2.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if jobs := listJobs(t, repos, p.ID); len(jobs) != 1 {
+		t.Errorf("after restart: jobs = %d, want 1", len(jobs))
+	}
+}
+
+func TestStart_CatchUpRunsOnce(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	p := createPolicy(t, repos, true, time.Now().UTC().Add(-72*time.Hour))
+
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// Three nightly runs were missed; catch-up collapses them into a single
+	// run, which stays pending because the agent is not connected.
+	jobs := listJobs(t, repos, p.ID)
+	if len(jobs) != 1 {
+		t.Fatalf("jobs = %d, want 1", len(jobs))
+	}
+	if jobs[0].Status != "pending" {
+		t.Errorf("status = %q, want pending", jobs[0].Status)
+	}
+}
+
+func TestStart_NothingMissed(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	p := createPolicy(t, repos, false, time.Now().UTC())
+
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if jobs := listJobs(t, repos, p.ID); len(jobs) != 0 {
+		t.Errorf("jobs = %d, want 0", len(jobs))
+	}
+}
+
+// recordingStream is a proto.AgentService_StreamJobsServer that records the
+// IDs of the jobs dispatched through it.
+type recordingStream struct {
+	grpc.ServerStream
+	sent     []string
+	payloads [][]byte
+}
+
+func (r *recordingStream) Send(job *proto.JobAssignment) error {
+	r.sent = append(r.sent, job.JobId)
+	r.payloads = append(r.payloads, job.Payload)
+	return nil
+}
+
+func TestDispatchPending_SkipsRestoreJobs(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+	p := createPolicy(t, repos, false, time.Now().UTC())
+
+	// Both jobs were created while the agent was offline.
+	backup := &db.Job{PolicyID: p.ID, AgentID: p.AgentID, Type: "backup", Status: "pending"}
+	restore := &db.Job{PolicyID: p.ID, AgentID: p.AgentID, Type: "restore", Status: "pending"}
+	for _, j := range []*db.Job{backup, restore} {
+		if err := repos.jobs.Create(ctx, j); err != nil {
+			t.Fatalf("Create job: %v", err)
+		}
+	}
+
+	stream := &recordingStream{}
+	s.agentMgr.Register(p.AgentID.String(), "host", false, stream)
+	s.DispatchPending(ctx, p.AgentID)
+
+	if len(stream.sent) != 1 || stream.sent[0] != backup.ID.String() {
+		t.Errorf("sent = %v, want only the backup job %s", stream.sent, backup.ID)
+	}
+}
+
+func TestExpirePendingJobs(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+	p := createPolicy(t, repos, false, time.Now().UTC())
+
+	stale := &db.Job{PolicyID: p.ID, AgentID: p.AgentID, Status: "pending"}
+	stale.CreatedAt = time.Now().UTC().Add(-2 * defaultPendingDeadline)
+	fresh := &db.Job{PolicyID: p.ID, AgentID: p.AgentID, Status: "pending"}
+	for _, j := range []*db.Job{stale, fresh} {
+		if err := repos.jobs.Create(ctx, j); err != nil {
+			t.Fatalf("Create job: %v", err)
+		}
+	}
+
+	s.expirePendingJobs()
+
+	got, err := repos.jobs.GetByID(ctx, stale.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != "missed" {
+		t.Errorf("stale job status = %q, want missed", got.Status)
+	}
+	got, err = repos.jobs.GetByID(ctx, fresh.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != "pending" {
+		t.Errorf("fresh job status = %q, want pending", got.Status)
+	}
+}
+
+func TestStart_PersistsNextRunInPolicyTimezone(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+	p := createPolicy(t, repos, false, time.Now().UTC())
+	p.Timezone = "Asia/Tokyo"
+	if err := repos.policies.Update(ctx, p); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if err := s.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	got, err := repos.policies.GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.NextRunAt == nil {
+		t.Fatal("NextRunAt = nil, want the next scheduled fire time")
+	}
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	if local := got.NextRunAt.In(tokyo); local.Hour() != 2 || local.Minute() != 0 {
+		t.Errorf("NextRunAt = %s, want 02:00 in Asia/Tokyo", local)
+	}
+	if !got.NextRunAt.After(time.Now()) {
+		t.Errorf("NextRunAt = %s, want a future time", got.NextRunAt)
+	}
+}
+
+func TestDispatch_QueuesJobsBeyondDestinationLimit(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+
+	dest := &db.Destination{Name: "minio", Type: "s3", Credentials: "{}", Config: "{}", MaxConcurrentJobs: 1}
+	if err := repos.dests.Create(ctx, dest); err != nil {
+		t.Fatalf("Create destination: %v", err)
+	}
+	agentID := uuid.New()
+	stream := &recordingStream{}
+	s.agentMgr.Register(agentID.String(), "host", false, stream)
+
+	var jobs []*db.Job
+	for range 2 {
+		p := createPolicy(t, repos, false, time.Now().UTC())
+		p.AgentID = agentID
+		if err := repos.policies.Update(ctx, p); err != nil {
+			t.Fatalf("Update policy: %v", err)
+		}
+		if err := repos.policies.AddDestination(ctx, &db.PolicyDestination{PolicyID: p.ID, DestinationID: dest.ID}); err != nil {
+			t.Fatalf("AddDestination: %v", err)
+		}
+		job, err := s.TriggerNow(ctx, p.ID)
+		if err != nil {
+			t.Fatalf("TriggerNow: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if len(stream.sent) != 1 || stream.sent[0] != jobs[0].ID.String() {
+		t.Fatalf("sent = %v, want only the first job", stream.sent)
+	}
+	queue := s.Queue()
+	if len(queue) != 1 || queue[0].JobID != jobs[1].ID {
+		t.Fatalf("queue = %+v, want the second job", queue)
+	}
+	if got := s.RunningByDestination()[dest.ID]; got != 1 {
+		t.Errorf("running on destination = %d, want 1", got)
+	}
+
+	s.JobFinished(ctx, jobs[0].ID)
+
+	if len(stream.sent) != 2 || stream.sent[1] != jobs[1].ID.String() {
+		t.Errorf("sent = %v, want the queued job dispatched after the first finished", stream.sent)
+	}
+	if queue := s.Queue(); len(queue) != 0 {
+		t.Errorf("queue = %+v, want empty", queue)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+
+	agentID := uuid.New()
+	stream := &recordingStream{}
+	s.agentMgr.Register(agentID.String(), "host", false, stream)
+
+	// Dry runs work on disabled policies and do not touch destinations.
+	dest := &db.Destination{Name: "minio", Type: "s3", Credentials: "{}", Config: "{}", MaxConcurrentJobs: 1}
+	if err := repos.dests.Create(ctx, dest); err != nil {
+		t.Fatalf("Create destination: %v", err)
+	}
+	p := createPolicy(t, repos, false, time.Now().UTC())
+	p.AgentID = agentID
+	p.Enabled = false
+	if err := repos.policies.Update(ctx, p); err != nil {
+		t.Fatalf("Update policy: %v", err)
+	}
+	if err := repos.policies.AddDestination(ctx, &db.PolicyDestination{PolicyID: p.ID, DestinationID: dest.ID}); err != nil {
+		t.Fatalf("AddDestination: %v", err)
+	}
+
+	job, err := s.DryRun(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("DryRun: %v", err)
+	}
+	if job.Type != "dry_run" {
+		t.Errorf("job type = %q, want dry_run", job.Type)
+	}
+	if len(stream.sent) != 1 || stream.sent[0] != job.ID.String() {
+		t.Fatalf("sent = %v, want the dry-run job", stream.sent)
+	}
+	dests, err := repos.jobs.ListDestinationsByJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("ListDestinationsByJob: %v", err)
+	}
+	if len(dests) != 0 {
+		t.Errorf("job destinations = %d, want 0", len(dests))
+	}
+	if got := s.RunningByDestination()[dest.ID]; got != 0 {
+		t.Errorf("running on destination = %d, want 0", got)
+	}
+
+	if _, err := s.DryRun(ctx, uuid.New()); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("DryRun(unknown) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestTriggerNow_Replication(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+
+	agentID := uuid.New()
+	stream := &recordingStream{}
+	s.agentMgr.Register(agentID.String(), "host", false, stream)
+
+	source := &db.Destination{Name: "local", Type: "local", Credentials: "{}", Config: "{}", MaxConcurrentJobs: 1}
+	target := &db.Destination{Name: "offsite", Type: "s3", Credentials: "{}", Config: "{}"}
+	for _, d := range []*db.Destination{source, target} {
+		if err := repos.dests.Create(ctx, d); err != nil {
+			t.Fatalf("Create destination: %v", err)
+		}
+	}
+	p := createPolicy(t, repos, false, time.Now().UTC())
+	p.AgentID = agentID
+	p.Type = PolicyTypeReplication
+	p.ReplicateFromID = &source.ID
+	if err := repos.policies.Update(ctx, p); err != nil {
+		t.Fatalf("Update policy: %v", err)
+	}
+	if err := repos.policies.AddDestination(ctx, &db.PolicyDestination{PolicyID: p.ID, DestinationID: target.ID}); err != nil {
+		t.Fatalf("AddDestination: %v", err)
+	}
+
+	job, err := s.TriggerNow(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("TriggerNow: %v", err)
+	}
+	if job.Type != "replication" {
+		t.Errorf("job type = %q, want replication", job.Type)
+	}
+	if len(stream.sent) != 1 || stream.sent[0] != job.ID.String() {
+		t.Fatalf("sent = %v, want the replication job", stream.sent)
+	}
+	// The source repository is busy for the duration of the copy, so it
+	// counts against its own concurrency limit alongside the target.
+	running := s.RunningByDestination()
+	if running[source.ID] != 1 || running[target.ID] != 1 {
+		t.Errorf("running = %v, want source and target reserved", running)
+	}
+
+	if _, err := s.DryRun(ctx, p.ID); !errors.Is(err, ErrReplicationDryRun) {
+		t.Errorf("DryRun error = %v, want ErrReplicationDryRun", err)
+	}
+}
+
+func TestSend_ResolvesRepository(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+
+	agentID := uuid.New()
+	stream := &recordingStream{}
+	s.agentMgr.Register(agentID.String(), "host", false, stream)
+
+	dest := &db.Destination{Name: "nas", Type: "local", Credentials: "{}", Config: `{"path":"/mnt/backup"}`}
+	if err := repos.dests.Create(ctx, dest); err != nil {
+		t.Fatalf("Create destination: %v", err)
+	}
+	onsite := &db.Repository{Name: "onsite", DestinationID: dest.ID, Path: "onsite", Password: "onsite-secret"}
+	if err := repos.repos.Create(ctx, onsite); err != nil {
+		t.Fatalf("Create repository: %v", err)
+	}
+	shared := &db.Repository{Name: "shared", DestinationID: dest.ID, Path: "hosts/shared", Password: "shared-secret", ChunkerFromID: &onsite.ID}
+	if err := repos.repos.Create(ctx, shared); err != nil {
+		t.Fatalf("Create repository: %v", err)
+	}
+
+	p := createPolicy(t, repos, false, time.Now().UTC())
+	p.AgentID = agentID
+	if err := repos.policies.Update(ctx, p); err != nil {
+		t.Fatalf("Update policy: %v", err)
+	}
+	if err := repos.policies.AddDestination(ctx, &db.PolicyDestination{PolicyID: p.ID, DestinationID: dest.ID, RepositoryID: &shared.ID}); err != nil {
+		t.Fatalf("AddDestination: %v", err)
+	}
+
+	if _, err := s.TriggerNow(ctx, p.ID); err != nil {
+		t.Fatalf("TriggerNow: %v", err)
+	}
+	if len(stream.payloads) != 1 {
+		t.Fatalf("sent = %v, want one job", stream.sent)
+	}
+	var payload backupPayload
+	if err := json.Unmarshal(stream.payloads[0], &payload); err != nil {
+		t.Fatalf("Unmarshal payload: %v", err)
+	}
+	if len(payload.Destinations) != 1 {
+		t.Fatalf("destinations = %+v, want one", payload.Destinations)
+	}
+	got := payload.Destinations[0]
+	if got.RepoURL != "/mnt/backup/hosts/shared" || got.RepoPassword != "shared-secret" || got.RepositoryID != shared.ID.String() {
+		t.Errorf("destination = %+v, want the shared repository", got)
+	}
+	if got.ChunkerFrom == nil || got.ChunkerFrom.RepoURL != "/mnt/backup/onsite" || got.ChunkerFrom.RepoPassword != "onsite-secret" {
+		t.Errorf("chunker_from = %+v, want the onsite repository", got.ChunkerFrom)
+	}
+
+	// Once initialised, the repository no longer needs a chunker source.
+	if err := repos.repos.MarkInitialized(ctx, shared.ID, time.Now().UTC()); err != nil {
+		t.Fatalf("MarkInitialized: %v", err)
+	}
+	_, pds, err := repos.policies.GetByIDWithDestinations(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByIDWithDestinations: %v", err)
+	}
+	_, again, err := s.resolveDestination(ctx, pds[0])
+	if err != nil {
+		t.Fatalf("resolveDestination: %v", err)
+	}
+	if again.ChunkerFrom != nil {
+		t.Errorf("chunker_from = %+v, want nil after initialisation", again.ChunkerFrom)
+	}
+
+	// Without a repository, the destination's own password is sent.
+	_, own, err := s.resolveDestination(ctx, db.PolicyDestination{PolicyID: p.ID, DestinationID: dest.ID, RepoPassword: "own-secret"})
+	if err != nil {
+		t.Fatalf("resolveDestination: %v", err)
+	}
+	if own.RepoURL != "/mnt/backup" || own.RepoPassword != "own-secret" {
+		t.Errorf("destination = %+v, want the root with its own password", own)
+	}
+}
+
+func TestSend_ZeroKnowledge(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+
+	agentID := uuid.New()
+	stream := &recordingStream{}
+	s.agentMgr.Register(agentID.String(), "host", false, stream)
+	if err := repos.settings.Set(ctx, EscrowPublicKeySetting, "escrow-public-key"); err != nil {
+		t.Fatalf("Set escrow key: %v", err)
+	}
+
+	dest := &db.Destination{Name: "nas", Type: "local", Credentials: "{}", Config: `{"path":"/mnt/backup"}`}
+	if err := repos.dests.Create(ctx, dest); err != nil {
+		t.Fatalf("Create destination: %v", err)
+	}
+	p := createPolicy(t, repos, false, time.Now().UTC())
+	p.AgentID = agentID
+	p.ZeroKnowledge = true
+	p.RepoPassword = ""
+	if err := repos.policies.Update(ctx, p); err != nil {
+		t.Fatalf("Update policy: %v", err)
+	}
+	if err := repos.policies.AddDestination(ctx, &db.PolicyDestination{PolicyID: p.ID, DestinationID: dest.ID}); err != nil {
+		t.Fatalf("AddDestination: %v", err)
+	}
+	if err := repos.policies.SetDestinationKeyFingerprint(ctx, p.ID, dest.ID, "fp-repo"); err != nil {
+		t.Fatalf("SetDestinationKeyFingerprint: %v", err)
+	}
+
+	if _, err := s.TriggerNow(ctx, p.ID); err != nil {
+		t.Fatalf("TriggerNow: %v", err)
+	}
+	if len(stream.payloads) != 1 {
+		t.Fatalf("sent = %v, want one job", stream.sent)
+	}
+	var payload backupPayload
+	if err := json.Unmarshal(stream.payloads[0], &payload); err != nil {
+		t.Fatalf("Unmarshal payload: %v", err)
+	}
+	if payload.EscrowPublicKey != "escrow-public-key" {
+		t.Errorf("escrow_public_key = %q, want the configured key", payload.EscrowPublicKey)
+	}
+	if len(payload.Destinations) != 1 {
+		t.Fatalf("destinations = %+v, want one", payload.Destinations)
+	}
+	got := payload.Destinations[0]
+	wantKeyID := p.ID.String() + "/" + dest.ID.String()
+	if got.RepoPassword != "" || got.KeyID != wantKeyID || got.KeyFingerprint != "fp-repo" {
+		t.Errorf("destination = %+v, want key %s (fp-repo) and no password", got, wantKeyID)
+	}
+}
+
+func TestDispatchKeyShare_AgentOffline(t *testing.T) {
+	s, _ := newTestScheduler(t)
+	share := &db.KeyShare{PolicyID: uuid.New(), FromAgentID: uuid.New(), ToAgentID: uuid.New()}
+	share.ID = uuid.New()
+
+	// The share stays pending: the caller must be able to tell this apart
+	// from a failed send.
+	if err := s.DispatchKeyShare(share); !errors.Is(err, agentmanager.ErrAgentNotConnected) {
+		t.Errorf("DispatchKeyShare error = %v, want ErrAgentNotConnected", err)
+	}
+}
+
+func TestDispatchRepositoryScan_AgentOffline(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+
+	dest := &db.Destination{Name: "nas", Type: "local", Credentials: "{}", Config: `{"path":"/mnt/backup"}`}
+	if err := repos.dests.Create(ctx, dest); err != nil {
+		t.Fatalf("Create destination: %v", err)
+	}
+	repo := &db.Repository{Name: "legacy", DestinationID: dest.ID, Path: "legacy", Password: "secret"}
+	if err := repos.repos.Create(ctx, repo); err != nil {
+		t.Fatalf("Create repository: %v", err)
+	}
+	imp := &db.RepositoryImport{RepositoryID: repo.ID, AgentID: uuid.New(), Status: "scanning"}
+	imp.ID = uuid.New()
+
+	// The import stays scanning until the agent reconnects.
+	if err := s.DispatchRepositoryScan(ctx, imp); !errors.Is(err, agentmanager.ErrAgentNotConnected) {
+		t.Errorf("DispatchRepositoryScan error = %v, want ErrAgentNotConnected", err)
+	}
+}
+
+func TestStartDelay_StableAndBounded(t *testing.T) {
+	p := &db.Policy{StartJitterSeconds: 600}
+	p.ID = uuid.New()
+
+	d := startDelay(p)
+	if d < 0 || d >= 600*time.Second {
+		t.Errorf("startDelay = %s, want within [0, 10m)", d)
+	}
+	if again := startDelay(p); again != d {
+		t.Errorf("startDelay changed between calls: %s then %s", d, again)
+	}
+
+	p.StartJitterSeconds = 0
+	if d := startDelay(p); d != 0 {
+		t.Errorf("startDelay without jitter = %s, want 0", d)
+	}
+}
+
+// failJob creates a job of p in the failed state with the given attempt
+// number and failure class.
+func failJob(t *testing.T, repos *testRepos, p *db.Policy, attempt int, failureClass string) *db.Job {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now().UTC()
+	j := &db.Job{PolicyID: p.ID, AgentID: p.AgentID, Status: "running", Attempt: attempt}
+	if err := repos.jobs.Create(ctx, j); err != nil {
+		t.Fatalf("Create job: %v", err)
+	}
+	if err := repos.jobs.UpdateStatus(ctx, j.ID, "failed", nil, &now, "boom"); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if err := repos.jobs.SetFailureClass(ctx, j.ID, failureClass); err != nil {
+		t.Fatalf("SetFailureClass: %v", err)
+	}
+	return j
+}
+
+func TestRetryFailedJob(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+	p := createPolicy(t, repos, false, time.Now().UTC())
+	p.RetryMaxAttempts = 3
+	p.RetryBackoffSeconds = 60
+	p.RetryOn = "network,hook"
+	if err := repos.policies.Update(ctx, p); err != nil {
+		t.Fatalf("Update policy: %v", err)
+	}
+
+	failed := failJob(t, repos, p, 2, FailureNetwork)
+	retry, err := s.RetryFailedJob(ctx, failed.ID)
+	if err != nil {
+		t.Fatalf("RetryFailedJob: %v", err)
+	}
+	if retry == nil {
+		t.Fatal("RetryFailedJob returned nil, want a retry")
+	}
+	if retry.Attempt != 3 || retry.RetryOfID == nil || *retry.RetryOfID != failed.ID {
+		t.Errorf("retry = attempt %d of %v, want attempt 3 of %s", retry.Attempt, retry.RetryOfID, failed.ID)
+	}
+	// Second retry: the base backoff is doubled.
+	if wait := time.Until(*retry.NotBefore); wait < 110*time.Second || wait > 120*time.Second {
+		t.Errorf("retry not before %s from now, want ~2m", wait)
+	}
+
+	// A duplicate failure report must not create another retry.
+	again, err := s.RetryFailedJob(ctx, failed.ID)
+	if err != nil {
+		t.Fatalf("RetryFailedJob: %v", err)
+	}
+	if again == nil || again.ID != retry.ID {
+		t.Errorf("duplicate RetryFailedJob = %v, want the existing retry %s", again, retry.ID)
+	}
+
+	// The pending retry is not dispatched before its backoff elapses.
+	stream := &recordingStream{}
+	s.agentMgr.Register(p.AgentID.String(), "host", false, stream)
+	s.DispatchPending(ctx, p.AgentID)
+	if len(stream.sent) != 0 {
+		t.Errorf("sent = %v, want nothing before the backoff elapses", stream.sent)
+	}
+}
+
+func TestRetryFailedJob_FinalFailures(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+	p := createPolicy(t, repos, false, time.Now().UTC())
+	p.RetryMaxAttempts = 2
+	p.RetryOn = FailureNetwork
+	if err := repos.policies.Update(ctx, p); err != nil {
+		t.Fatalf("Update policy: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		attempt      int
+		failureClass string
+	}{
+		{"class not retried", 1, FailureWrongPassword},
+		{"unclassified", 1, ""},
+		{"attempts exhausted", 2, FailureNetwork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failed := failJob(t, repos, p, tt.attempt, tt.failureClass)
+			retry, err := s.RetryFailedJob(ctx, failed.ID)
+			if err != nil {
+				t.Fatalf("RetryFailedJob: %v", err)
+			}
+			if retry != nil {
+				t.Errorf("RetryFailedJob = attempt %d, want no retry", retry.Attempt)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff_Capped(t *testing.T) {
+	p := &db.Policy{RetryBackoffSeconds: 3600}
+	if got := retryBackoff(p, 1); got != time.Hour {
+		t.Errorf("retryBackoff(1) = %s, want 1h", got)
+	}
+	if got := retryBackoff(p, 3); got != 4*time.Hour {
+		t.Errorf("retryBackoff(3) = %s, want 4h", got)
+	}
+	if got := retryBackoff(p, 9); got != maxRetryBackoff {
+		t.Errorf("retryBackoff(9) = %s, want %s", got, maxRetryBackoff)
+	}
+}
+
+func TestFailStuckJobs_RetriesLikeAgentFailures(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+	p := createPolicy(t, repos, false, time.Now().UTC())
+	p.RetryMaxAttempts = 2
+	p.RetryBackoffSeconds = 3600
+	p.RetryOn = FailureOther
+	if err := repos.policies.Update(ctx, p); err != nil {
+		t.Fatalf("Update policy: %v", err)
+	}
+	dependent := createPolicy(t, repos, false, time.Now().UTC())
+	if err := repos.policies.SetDependencies(ctx, dependent.ID, []db.PolicyDependency{{DependsOnID: p.ID, Condition: DependencyAlways}}); err != nil {
+		t.Fatalf("SetDependencies: %v", err)
+	}
+
+	backup := &db.Job{PolicyID: p.ID, AgentID: p.AgentID, Type: "backup", Status: "running"}
+	restore := &db.Job{PolicyID: p.ID, AgentID: p.AgentID, Type: "restore", Status: "running"}
+	for _, j := range []*db.Job{backup, restore} {
+		if err := repos.jobs.Create(ctx, j); err != nil {
+			t.Fatalf("Create job: %v", err)
+		}
+		if err := repos.jobs.TouchActivity(ctx, j.ID, time.Now().UTC().Add(-2*s.stuckJobTimeout)); err != nil {
+			t.Fatalf("TouchActivity: %v", err)
+		}
+	}
+
+	s.failStuckJobs(ctx)
+
+	// The backup is retried, so its failure is not final yet and the
+	// dependent policy must not start. The restore is failed for good.
+	var retries int
+	for _, j := range listJobs(t, repos, p.ID) {
+		if j.RetryOfID != nil && *j.RetryOfID == backup.ID {
+			retries++
+		}
+	}
+	if retries != 1 {
+		t.Errorf("retries of the stuck backup = %d, want 1", retries)
+	}
+	if got := len(listJobs(t, repos, dependent.ID)); got != 0 {
+		t.Errorf("dependent jobs = %d, want 0 while the backup is retried", got)
+	}
+	got, err := repos.jobs.GetByID(ctx, restore.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != "failed" || got.FailureClass != FailureOther {
+		t.Errorf("stuck restore = %s (%q), want failed (other)", got.Status, got.FailureClass)
+	}
+}
+
+func TestFailStuckJobs(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+	p := createPolicy(t, repos, false, time.Now().UTC())
+
+	stream := &recordingStream{}
+	s.agentMgr.Register(p.AgentID.String(), "host", false, stream)
+
+	now := time.Now().UTC()
+	stuck := &db.Job{PolicyID: p.ID, AgentID: p.AgentID, Status: "running"}
+	active := &db.Job{PolicyID: p.ID, AgentID: p.AgentID, Status: "running"}
+	for _, j := range []*db.Job{stuck, active} {
+		if err := repos.jobs.Create(ctx, j); err != nil {
+			t.Fatalf("Create job: %v", err)
+		}
+	}
+	if err := repos.jobs.TouchActivity(ctx, stuck.ID, now.Add(-2*s.stuckJobTimeout)); err != nil {
+		t.Fatalf("TouchActivity: %v", err)
+	}
+	if err := repos.jobs.TouchActivity(ctx, active.ID, now); err != nil {
+		t.Fatalf("TouchActivity: %v", err)
+	}
+
+	s.failStuckJobs(ctx)
+
+	got, err := repos.jobs.GetByID(ctx, stuck.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != "failed" || got.FailureClass != FailureOther {
+		t.Errorf("stuck job = %s (%q), want failed (other)", got.Status, got.FailureClass)
+	}
+	if len(stream.sent) != 1 || stream.sent[0] != stuck.ID.String() {
+		t.Errorf("sent = %v, want an abort for the stuck job", stream.sent)
+	}
+	got, err = repos.jobs.GetByID(ctx, active.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != "running" {
+		t.Errorf("active job status = %q, want running", got.Status)
+	}
+}
+
+func TestTriggerDependents(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+	upstream := createPolicy(t, repos, false, time.Now().UTC())
+
+	// onSuccess only runs after its dependency, it has no schedule of its own.
+	onSuccess := createPolicy(t, repos, false, time.Now().UTC())
+	onSuccess.Schedule = ""
+	if err := repos.policies.Update(ctx, onSuccess); err != nil {
+		t.Fatalf("Update policy: %v", err)
+	}
+	onFailure := createPolicy(t, repos, false, time.Now().UTC())
+	always := createPolicy(t, repos, false, time.Now().UTC())
+	for p, condition := range map[*db.Policy]string{onSuccess: DependencyOnSuccess, onFailure: DependencyOnFailure, always: DependencyAlways} {
+		dep := []db.PolicyDependency{{DependsOnID: upstream.ID, Condition: condition}}
+		if err := repos.policies.SetDependencies(ctx, p.ID, dep); err != nil {
+			t.Fatalf("SetDependencies: %v", err)
+		}
+	}
+
+	failed := failJob(t, repos, upstream, 1, FailureOther)
+	started := s.TriggerDependents(ctx, failed.ID)
+	if len(started) != 2 {
+		t.Fatalf("TriggerDependents(failed) started %d jobs, want 2", len(started))
+	}
+	if got := len(listJobs(t, repos, onFailure.ID)); got != 1 {
+		t.Errorf("on-failure dependent jobs = %d, want 1", got)
+	}
+	if got := len(listJobs(t, repos, onSuccess.ID)); got != 0 {
+		t.Errorf("on-success dependent jobs = %d, want 0", got)
+	}
+
+	now := time.Now().UTC()
+	succeeded := &db.Job{PolicyID: upstream.ID, AgentID: upstream.AgentID, Type: "backup", Status: "running"}
+	if err := repos.jobs.Create(ctx, succeeded); err != nil {
+		t.Fatalf("Create job: %v", err)
+	}
+	if err := repos.jobs.UpdateStatus(ctx, succeeded.ID, "succeeded", nil, &now, ""); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	s.TriggerDependents(ctx, succeeded.ID)
+	jobs := listJobs(t, repos, onSuccess.ID)
+	if len(jobs) != 1 {
+		t.Fatalf("on-success dependent jobs = %d, want 1", len(jobs))
+	}
+	if jobs[0].TriggeredByID == nil || *jobs[0].TriggeredByID != succeeded.ID {
+		t.Errorf("TriggeredByID = %v, want %s", jobs[0].TriggeredByID, succeeded.ID)
+	}
+	if got := len(listJobs(t, repos, always.ID)); got != 2 {
+		t.Errorf("always dependent jobs = %d, want 2", got)
+	}
+}
+
+func TestFindDependencyCycle(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	// b runs after a, c runs after b.
+	edges := []db.PolicyDependency{
+		{PolicyID: b, DependsOnID: a},
+		{PolicyID: c, DependsOnID: b},
+	}
+
+	if cycle := FindDependencyCycle(edges, c, []uuid.UUID{a}); cycle != nil {
+		t.Errorf("c after a: cycle = %v, want none", cycle)
+	}
+	cycle := FindDependencyCycle(edges, a, []uuid.UUID{c})
+	want := []uuid.UUID{a, c, b, a}
+	if len(cycle) != len(want) {
+		t.Fatalf("a after c: cycle = %v, want %v", cycle, want)
+	}
+	for i := range want {
+		if cycle[i] != want[i] {
+			t.Fatalf("a after c: cycle = %v, want %v", cycle, want)
+		}
+	}
+}
+
+func TestSnapshotSettings(t *testing.T) {
+	p := &db.Policy{RetentionDaily: 7, RetentionWeekly: 4}
+	p.ID = uuid.New()
+	policyTag := "policy:" + p.ID.String()
+	hourly := db.PolicySchedule{Schedule: "0 * * * *", Tags: "schedule:hourly", CustomRetention: true, RetentionHourly: 48}
+	hourly.ID = uuid.New()
+	weekly := db.PolicySchedule{Schedule: "0 3 * * 0", Tags: "schedule:weekly"}
+	weekly.ID = uuid.New()
+	entries := []db.PolicySchedule{hourly, weekly}
+
+	t.Run("entry with its own retention forgets its tags only", func(t *testing.T) {
+		tags, ret := snapshotSettings(p, &hourly.ID, entries)
+		want := []string{policyTag, "schedule:hourly"}
+		if !slices.Equal(tags, want) || !slices.Equal(ret.Tags, want) {
+			t.Errorf("tags = %v, retention tags = %v, want %v", tags, ret.Tags, want)
+		}
+		if ret.Hourly != 48 || ret.Daily != 0 || len(ret.KeepTags) != 0 {
+			t.Errorf("retention = %+v, want the entry's retention", ret)
+		}
+	})
+
+	t.Run("other runs keep the entry's snapshots", func(t *testing.T) {
+		for _, id := range []*uuid.UUID{nil, &weekly.ID} {
+			tags, ret := snapshotSettings(p, id, entries)
+			if id != nil && !slices.Contains(tags, "schedule:weekly") {
+				t.Errorf("tags = %v, want the entry tag", tags)
+			}
+			if ret.Daily != 7 || len(ret.Tags) != 0 {
+				t.Errorf("retention = %+v, want the policy's retention over the repository", ret)
+			}
+			if !slices.Equal(ret.KeepTags, []string{policyTag + ",schedule:hourly"}) {
+				t.Errorf("keep tags = %v, want the hourly tag set", ret.KeepTags)
+			}
+		}
+	})
+}
+
+func TestAddPolicy_RegistersEachSchedule(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+	p := createPolicy(t, repos, false, time.Now().UTC())
+	if err := repos.policies.SetSchedules(ctx, p.ID, []db.PolicySchedule{
+		{Schedule: "*/5 * * * *", Tags: "schedule:frequent"},
+		{Schedule: "0 3 * * 0", Tags: "schedule:weekly"},
+	}); err != nil {
+		t.Fatalf("SetSchedules: %v", err)
+	}
+
+	if err := s.AddPolicy(p); err != nil {
+		t.Fatalf("AddPolicy: %v", err)
+	}
+	if n := len(s.cron.Jobs()); n != 3 {
+		t.Fatalf("registered %d gocron jobs, want 3", n)
+	}
+
+	// The next run is the earliest of all schedules: the 5-minute entry.
+	got, err := repos.policies.GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.NextRunAt == nil || got.NextRunAt.After(time.Now().Add(5*time.Minute)) {
+		t.Errorf("NextRunAt = %v, want within 5 minutes", got.NextRunAt)
+	}
+
+	if err := s.RemovePolicy(p.ID); err != nil {
+		t.Fatalf("RemovePolicy: %v", err)
+	}
+	if n := len(s.cron.Jobs()); n != 0 {
+		t.Errorf("%d gocron jobs left after RemovePolicy, want 0", n)
+	}
+}
+
+func TestCheckBackupAnomaly(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+	p := createPolicy(t, repos, false, time.Now().UTC())
+	p.AnomalyHold = true
+	if err := repos.policies.Update(ctx, p); err != nil {
+		t.Fatalf("Update policy: %v", err)
+	}
+	destID := uuid.New()
+
+	// run records a successful backup run of p to destID, endedAt ago.
+	run := func(filesChanged int64, snapshotID string, endedAgo time.Duration) (*db.Job, *db.JobDestination) {
+		t.Helper()
+		job := &db.Job{PolicyID: p.ID, AgentID: p.AgentID, Type: "backup", Status: "succeeded"}
+		if err := repos.jobs.Create(ctx, job); err != nil {
+			t.Fatalf("Create job: %v", err)
+		}
+		jd := &db.JobDestination{JobID: job.ID, DestinationID: destID}
+		if err := repos.jobs.CreateDestination(ctx, jd); err != nil {
+			t.Fatalf("CreateDestination: %v", err)
+		}
+		endedAt := time.Now().UTC().Add(-endedAgo)
+		if err := repos.jobs.UpdateDestinationStatus(ctx, jd.ID, "succeeded", &endedAt, &endedAt, snapshotID, 0, ""); err != nil {
+			t.Fatalf("UpdateDestinationStatus: %v", err)
+		}
+		jd.FilesChanged, jd.TotalFiles = filesChanged, 1000
+		if err := repos.jobs.SetDestinationChangeStats(ctx, jd.ID, repositories.ChangeStats{FilesChanged: filesChanged, TotalFiles: 1000}); err != nil {
+			t.Fatalf("SetDestinationChangeStats: %v", err)
+		}
+		return job, jd
+	}
+
+	for i := range anomalyMinBaselineRuns {
+		run(10+int64(i), fmt.Sprintf("snap-%d", i), time.Duration(10-i)*time.Hour)
+	}
+	good, goodRun := run(12, "snap-good", time.Hour)
+	if hold := s.CheckBackupAnomaly(ctx, good, goodRun); hold != "" {
+		t.Errorf("CheckBackupAnomaly(quiet run) = %q, want no hold", hold)
+	}
+
+	// Too few previous runs to judge: even a full rewrite of a new
+	// destination is not flagged.
+	fresh := &db.JobDestination{Base: db.Base{ID: uuid.New()}, DestinationID: uuid.New(), FilesChanged: 900, TotalFiles: 1000}
+	if hold := s.CheckBackupAnomaly(ctx, good, fresh); hold != "" {
+		t.Errorf("CheckBackupAnomaly(no baseline) = %q, want no hold", hold)
+	}
+
+	// 80% of files changed against ~1% in the baseline: snap-good, the
+	// newest snapshot, is the last known-good one.
+	spike, spikeRun := run(800, "snap-spike", 0)
+	if hold := s.CheckBackupAnomaly(ctx, spike, spikeRun); hold != "snap-good" {
+		t.Fatalf("CheckBackupAnomaly(spike) = %q, want snap-good", hold)
+	}
+	dests, err := repos.jobs.ListDestinationsByJob(ctx, spike.ID)
+	if err != nil {
+		t.Fatalf("ListDestinationsByJob: %v", err)
+	}
+	if len(dests) != 1 || !dests[0].Anomaly || dests[0].AnomalyReason == "" {
+		t.Errorf("job destination = %+v, want it flagged as anomalous", dests)
+	}
+
+	// Flagged runs stay out of the baseline: the attack cannot make itself
+	// the new normal, and snap-spike is never held.
+	next, nextRun := run(790, "snap-next", 0)
+	if hold := s.CheckBackupAnomaly(ctx, next, nextRun); hold != "snap-good" {
+		t.Errorf("CheckBackupAnomaly(second spike) = %q, want snap-good", hold)
+	}
+}
+
+func TestBuildSourcesList(t *testing.T) {
+	got, err := buildSourcesList(`[
+		{"type":"directory","path":"/srv"},
+		{"type":"docker-volume","path":"pgdata","containers":"stop"},
+		{"type":"docker-volume","path":"cache"},
+		{"type":"docker-label","path":"arkeep.backup=true"},
+		{"type":"database","connection_id":"0b9f5bb5-3d1c-4c1e-9a51-0f2d1c3b4a5e","database":"app"},
+		{"type":"docker-dump","container":"pg","engine":"postgres","database":"app"},
+		{"type":"command","command":"etcdctl snapshot save -","filename":"etcd.db"}
+	]`)
+	if err != nil {
+		t.Fatalf("buildSourcesList: %v", err)
+	}
+	want := `["/srv","docker-volume://pgdata?containers=stop","docker-volume://cache","docker-label://arkeep.backup=true"]`
+	if got != want {
+		t.Errorf("sources = %s, want %s", got, want)
+	}
+}
+
+func TestBuildDatabases(t *testing.T) {
+	s, repos := newTestScheduler(t)
+	ctx := context.Background()
+
+	pg := &db.DatabaseConnection{Name: "pg", Engine: EnginePostgres, Host: "db.internal", Username: "backup", Password: "pg-secret"}
+	cache := &db.DatabaseConnection{Name: "cache", Engine: EngineRedis, Host: "redis"}
+	for _, c := range []*db.DatabaseConnection{pg, cache} {
+		if err := repos.conns.Create(ctx, c); err != nil {
+			t.Fatalf("Create connection: %v", err)
+		}
+	}
+
+	got, err := s.buildDatabases(ctx, `[
+		{"type":"directory","path":"/srv"},
+		{"type":"database","connection_id":"`+pg.ID.String()+`","database":"billing"},
+		{"type":"database","connection_id":"`+cache.ID.String()+`"}
+	]`)
+	if err != nil {
+		t.Fatalf("buildDatabases: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("databases = %+v, want two", got)
+	}
+	if got[0].Password != "pg-secret" || got[0].Filename != "postgres-billing.sql" ||
+		!slices.Equal(got[0].Tags, []string{"db:postgres:billing", "db-connection:" + pg.ID.String()}) {
+		t.Errorf("postgres = %+v", got[0])
+	}
+	// Redis dumps the whole instance, named by its address.
+	if got[1].Filename != "redis-redis_6379.rdb" || got[1].Tags[0] != "db:redis:redis:6379" {
+		t.Errorf("redis = %+v", got[1])
+	}
+
+	got, err = s.buildDatabases(ctx, `[
+		{"type":"docker-dump","container":"pg","engine":"postgres","database":"billing","container_credentials":true},
+		{"type":"docker-dump","container":"mongo","engine":"mongodb"},
+		{"type":"docker-dump","container":"influx","command":"influx backup -"}
+	]`)
+	if err != nil {
+		t.Fatalf("buildDatabases: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("databases = %+v, want three", got)
+	}
+	// A dump of one database is tagged like one taken through a connection.
+	if got[0].Container != "pg" || !got[0].ContainerCredentials || got[0].Filename != "postgres-billing.sql" ||
+		!slices.Equal(got[0].Tags, []string{"db:postgres:billing", "db-container:pg"}) {
+		t.Errorf("postgres container = %+v", got[0])
+	}
+	if got[1].Filename != "mongodb-mongo.archive" || !slices.Equal(got[1].Tags, []string{"db-container:mongo"}) {
+		t.Errorf("whole mongodb server = %+v", got[1])
+	}
+	if got[2].Command != "influx backup -" || got[2].Filename != "influx.dump" {
+		t.Errorf("custom command = %+v", got[2])
+	}
+
+	if _, err := s.buildDatabases(ctx, `[{"type":"database","connection_id":"`+uuid.NewString()+`","database":"x"}]`); err == nil {
+		t.Error("buildDatabases succeeded with an unknown connection")
+	}
+}
+
+func TestBuildCommands(t *testing.T) {
+	got, err := buildCommands(`[
+		{"type":"directory","path":"/srv"},
+		{"type":"command","command":"etcdctl snapshot save -","filename":"etcd.db"}
+	]`)
+	if err != nil {
+		t.Fatalf("buildCommands: %v", err)
+	}
+	want := commandPayload{Command: "etcdctl snapshot save -", Filename: "etcd.db", Tags: []string{"command:etcd.db"}}
+	if len(got) != 1 || got[0].Command != want.Command || got[0].Filename != want.Filename || !slices.Equal(got[0].Tags, want.Tags) {
+		t.Errorf("commands = %+v, want [%+v]", got, want)
+	}
+}
+
+func TestDumpFilename(t *testing.T) {
+	cases := map[[2]string]string{
+		{EnginePostgres, "app"}:               "postgres-app.sql",
+		{EngineMySQL, "shop db"}:              "mysql-shop_db.sql",
+		{EngineMongoDB, "events"}:             "mongodb-events.archive",
+		{EngineSQLite, "/var/lib/app/db.sq3"}: "sqlite-db.sq3.sql",
+	}
+	for in, want := range cases {
+		if got := DumpFilename(in[0], in[1]); got != want {
+			t.Errorf("DumpFilename(%q, %q) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
+
+func TestParseHooks(t *testing.T) {
+	hooks, err := ParseHooks(`{
+		"pre_backup": [{"command":"systemctl stop app","timeout_secs":60},{"command":"sync","continue_on_error":true}],
+		"post_backup": [],
+		"pre_restore": [{"command":"systemctl stop app","run_as":"root"}]
+	}`)
+	if err != nil {
+		t.Fatalf("ParseHooks: %v", err)
+	}
+	if steps := hooks[HookPreBackup]; len(steps) != 2 || steps[0].TimeoutSecs != 60 || !steps[1].ContinueOnError {
+		t.Errorf("pre_backup = %+v", steps)
+	}
+
+	backup := hooks.Phases(HookPreBackup, HookPostBackup, HookOnSuccess, HookOnFailure)
+	if len(backup) != 1 || len(backup[HookPreBackup]) != 2 {
+		t.Errorf("backup phases = %+v, want pre_backup only", backup)
+	}
+	if got := hooks.Phases(HookPostRestore); got != nil {
+		t.Errorf("Phases without steps = %+v, want nil", got)
+	}
+
+	if hooks, err := ParseHooks(""); err != nil || len(hooks) != 0 {
+		t.Errorf("ParseHooks(\"\") = %+v, %v", hooks, err)
+	}
+	if _, err := ParseHooks("pg_dump mydb"); err == nil {
+		t.Error("ParseHooks accepted a plain command")
+	}
+}
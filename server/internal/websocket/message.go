@@ -22,6 +22,10 @@ const (
 	// MsgJobLog is sent for each streamed log line during an active backup.
 	MsgJobLog MessageType = "job.log"
 
+	// MsgJobProgress is sent for each (throttled) progress report of a
+	// running backup or dry run.
+	MsgJobProgress MessageType = "job.progress"
+
 	// MsgAgentStatus is sent when an agent connects, disconnects, or errors.
 	MsgAgentStatus MessageType = "agent.status"
 
@@ -57,6 +61,7 @@ type Message struct {
 	// Payload carries the event-specific data. The shape varies by Type:
 	//   - job.status:    {"status":"running","started_at":"..."}
 	//   - job.log:       {"level":"info","message":"...","timestamp":"..."}
+	//   - job.progress:  {"percent_done":0.42,"files_done":120,"bytes_done":...}
 	//   - agent.status:  {"status":"online","ip_address":"..."}
 	//   - agent.metrics: {"cpu_percent":12.5,"mem_percent":60.1,"disk_percent":45.0}
 	//   - notification:  {"id":"...","type":"...","title":"...","body":"..."}
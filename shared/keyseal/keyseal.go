@@ -0,0 +1,152 @@
+// Package keyseal implements the public-key encryption behind zero-knowledge
+// policies, shared by the agent, which seals repository keys, and the admin
+// tooling, which opens escrow kits.
+//
+// A sealed message is readable only with the recipient's X25519 private key:
+// the sender derives an AES-256-GCM key from an ephemeral X25519 key pair and
+// the recipient's public key (ECDH + HKDF-SHA256), and prepends the ephemeral
+// public key to the ciphertext. The server relays sealed messages without
+// being able to open them.
+package keyseal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Purposes bind a sealed message to what it carries, so that a message
+// sealed for one purpose cannot be passed off as another.
+const (
+	PurposeEscrowKit = "arkeep escrow kit"
+	PurposeKeyShare  = "arkeep key share"
+)
+
+// ErrOpen is returned when a sealed message cannot be opened: wrong private
+// key, wrong purpose or tampered ciphertext.
+var ErrOpen = errors.New("keyseal: message cannot be opened with this key")
+
+// GenerateKey returns a new X25519 key pair, both halves base64-encoded.
+func GenerateKey() (privateKey, publicKey string, err error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("keyseal: failed to generate key: %w", err)
+	}
+	return encode(priv.Bytes()), encode(priv.PublicKey().Bytes()), nil
+}
+
+// ParsePublicKey decodes a base64-encoded X25519 public key.
+func ParsePublicKey(s string) (*ecdh.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("keyseal: public key is not base64: %w", err)
+	}
+	pub, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("keyseal: invalid public key: %w", err)
+	}
+	return pub, nil
+}
+
+// ParsePrivateKey decodes a base64-encoded X25519 private key.
+func ParsePrivateKey(s string) (*ecdh.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("keyseal: private key is not base64: %w", err)
+	}
+	priv, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("keyseal: invalid private key: %w", err)
+	}
+	return priv, nil
+}
+
+// Seal encrypts plaintext to the base64-encoded public key for purpose.
+func Seal(publicKey, purpose string, plaintext []byte) ([]byte, error) {
+	recipient, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("keyseal: failed to generate ephemeral key: %w", err)
+	}
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, fmt.Errorf("keyseal: key agreement failed: %w", err)
+	}
+	aead, err := newAEAD(shared, ephemeral.PublicKey().Bytes(), recipient.Bytes(), purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	out := append([]byte{}, ephemeral.PublicKey().Bytes()...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("keyseal: failed to generate nonce: %w", err)
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, []byte(purpose)), nil
+}
+
+// Open decrypts a message sealed to the public half of privateKey for
+// purpose.
+func Open(privateKey *ecdh.PrivateKey, purpose string, sealed []byte) ([]byte, error) {
+	const keySize = 32
+	if len(sealed) < keySize {
+		return nil, ErrOpen
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(sealed[:keySize])
+	if err != nil {
+		return nil, ErrOpen
+	}
+	shared, err := privateKey.ECDH(ephemeral)
+	if err != nil {
+		return nil, ErrOpen
+	}
+	aead, err := newAEAD(shared, ephemeral.Bytes(), privateKey.PublicKey().Bytes(), purpose)
+	if err != nil {
+		return nil, err
+	}
+	rest := sealed[keySize:]
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrOpen
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(purpose))
+	if err != nil {
+		return nil, ErrOpen
+	}
+	return plaintext, nil
+}
+
+// Fingerprint identifies a repository key or a public key without revealing
+// it: the first 16 bytes of its SHA-256, hex-encoded.
+func Fingerprint(key string) string {
+	sum := sha256.Sum256([]byte("arkeep fingerprint\x00" + key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// newAEAD derives the AES-256-GCM cipher of one sealed message.
+func newAEAD(shared, ephemeralPub, recipientPub []byte, purpose string) (cipher.AEAD, error) {
+	salt := append(append([]byte{}, ephemeralPub...), recipientPub...)
+	key, err := hkdf.Key(sha256.New, shared, salt, purpose, 32)
+	if err != nil {
+		return nil, fmt.Errorf("keyseal: key derivation failed: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("keyseal: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func encode(b []byte) string {
+	return base64.StdEncoding.EncodeToString(b)
+}
@@ -41,25 +41,63 @@ const (
 	// the JobAssignment carries a correlation_id generated by the REST handler
 	// rather than a real DB job UUID. The agent responds via ReportVolumeList.
 	JobType_JOB_TYPE_LIST_VOLUMES JobType = 5
+	// JOB_TYPE_ABORT is a synthetic, non-persisted job type used to ask the
+	// agent to stop a job it is running, e.g. when the server's watchdog gave
+	// up on it. The job_id field carries the ID of the job to abort. The agent
+	// kills restic and the hooks and does not report a final status: the
+	// server has already recorded the outcome.
+	JobType_JOB_TYPE_ABORT JobType = 6
+	// JOB_TYPE_DRY_RUN runs restic backup --dry-run over the policy's sources
+	// to estimate the size of a first backup. Nothing is written to the
+	// policy's destinations and no hooks run. The agent reports the estimate
+	// via ReportDryRun.
+	JobType_JOB_TYPE_DRY_RUN JobType = 7
+	// JOB_TYPE_REPLICATE copies the snapshots of a source destination to the
+	// policy's destinations via restic copy, optionally filtered by tag and
+	// host. Each destination reports the snapshots it received via
+	// ReportDestinationStatus (copied_snapshots).
+	JobType_JOB_TYPE_REPLICATE JobType = 8
+	// JOB_TYPE_SHARE_KEY is a synthetic, non-persisted job type used to ask
+	// the agent of a zero-knowledge policy to seal one of its repository keys
+	// to another agent's public key, after an admin requested the share. The
+	// job_id field carries the ID of the key share; the payload names the key
+	// and the recipient. The agent responds via ReportKeyShare.
+	JobType_JOB_TYPE_SHARE_KEY JobType = 9
+	// JOB_TYPE_SCAN_REPOSITORY is a synthetic, non-persisted job type used to
+	// list the snapshots of an existing restic repository before it is
+	// adopted. The job_id field carries the ID of the repository import; the
+	// payload carries the repository. The agent responds via
+	// ReportRepositoryScan.
+	JobType_JOB_TYPE_SCAN_REPOSITORY JobType = 10
 )
 
 // Enum value maps for JobType.
 var (
 	JobType_name = map[int32]string{
-		0: "JOB_TYPE_UNSPECIFIED",
-		1: "JOB_TYPE_BACKUP",
-		2: "JOB_TYPE_VERIFY",
-		3: "JOB_TYPE_RESTORE",
-		4: "JOB_TYPE_FORGET",
-		5: "JOB_TYPE_LIST_VOLUMES",
+		0:  "JOB_TYPE_UNSPECIFIED",
+		1:  "JOB_TYPE_BACKUP",
+		2:  "JOB_TYPE_VERIFY",
+		3:  "JOB_TYPE_RESTORE",
+		4:  "JOB_TYPE_FORGET",
+		5:  "JOB_TYPE_LIST_VOLUMES",
+		6:  "JOB_TYPE_ABORT",
+		7:  "JOB_TYPE_DRY_RUN",
+		8:  "JOB_TYPE_REPLICATE",
+		9:  "JOB_TYPE_SHARE_KEY",
+		10: "JOB_TYPE_SCAN_REPOSITORY",
 	}
 	JobType_value = map[string]int32{
-		"JOB_TYPE_UNSPECIFIED":  0,
-		"JOB_TYPE_BACKUP":       1,
-		"JOB_TYPE_VERIFY":       2,
-		"JOB_TYPE_RESTORE":      3,
-		"JOB_TYPE_FORGET":       4,
-		"JOB_TYPE_LIST_VOLUMES": 5,
+		"JOB_TYPE_UNSPECIFIED":     0,
+		"JOB_TYPE_BACKUP":          1,
+		"JOB_TYPE_VERIFY":          2,
+		"JOB_TYPE_RESTORE":         3,
+		"JOB_TYPE_FORGET":          4,
+		"JOB_TYPE_LIST_VOLUMES":    5,
+		"JOB_TYPE_ABORT":           6,
+		"JOB_TYPE_DRY_RUN":         7,
+		"JOB_TYPE_REPLICATE":       8,
+		"JOB_TYPE_SHARE_KEY":       9,
+		"JOB_TYPE_SCAN_REPOSITORY": 10,
 	}
 )
 
@@ -90,6 +128,68 @@ func (JobType) EnumDescriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{0}
 }
 
+// FailureClass is the agent's best guess at why a job failed.
+type FailureClass int32
+
+const (
+	FailureClass_FAILURE_CLASS_UNSPECIFIED FailureClass = 0
+	// FAILURE_CLASS_NETWORK: the repository backend could not be reached
+	// (connection refused, DNS failure, timeouts, resets).
+	FailureClass_FAILURE_CLASS_NETWORK FailureClass = 1
+	// FAILURE_CLASS_HOOK: the pre-backup hook failed.
+	FailureClass_FAILURE_CLASS_HOOK FailureClass = 2
+	// FAILURE_CLASS_WRONG_PASSWORD: restic could not open the repository with
+	// the configured password.
+	FailureClass_FAILURE_CLASS_WRONG_PASSWORD FailureClass = 3
+	// FAILURE_CLASS_OTHER: any failure not covered above.
+	FailureClass_FAILURE_CLASS_OTHER FailureClass = 4
+)
+
+// Enum value maps for FailureClass.
+var (
+	FailureClass_name = map[int32]string{
+		0: "FAILURE_CLASS_UNSPECIFIED",
+		1: "FAILURE_CLASS_NETWORK",
+		2: "FAILURE_CLASS_HOOK",
+		3: "FAILURE_CLASS_WRONG_PASSWORD",
+		4: "FAILURE_CLASS_OTHER",
+	}
+	FailureClass_value = map[string]int32{
+		"FAILURE_CLASS_UNSPECIFIED":    0,
+		"FAILURE_CLASS_NETWORK":        1,
+		"FAILURE_CLASS_HOOK":           2,
+		"FAILURE_CLASS_WRONG_PASSWORD": 3,
+		"FAILURE_CLASS_OTHER":          4,
+	}
+)
+
+func (x FailureClass) Enum() *FailureClass {
+	p := new(FailureClass)
+	*p = x
+	return p
+}
+
+func (x FailureClass) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FailureClass) Descriptor() protoreflect.EnumDescriptor {
+	return file_agent_proto_enumTypes[1].Descriptor()
+}
+
+func (FailureClass) Type() protoreflect.EnumType {
+	return &file_agent_proto_enumTypes[1]
+}
+
+func (x FailureClass) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FailureClass.Descriptor instead.
+func (FailureClass) EnumDescriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{1}
+}
+
 // JobStatus represents the lifecycle states of a job as seen by the agent.
 type JobStatus int32
 
@@ -135,11 +235,11 @@ func (x JobStatus) String() string {
 }
 
 func (JobStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_agent_proto_enumTypes[1].Descriptor()
+	return file_agent_proto_enumTypes[2].Descriptor()
 }
 
 func (JobStatus) Type() protoreflect.EnumType {
-	return &file_agent_proto_enumTypes[1]
+	return &file_agent_proto_enumTypes[2]
 }
 
 func (x JobStatus) Number() protoreflect.EnumNumber {
@@ -148,7 +248,7 @@ func (x JobStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use JobStatus.Descriptor instead.
 func (JobStatus) EnumDescriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{1}
+	return file_agent_proto_rawDescGZIP(), []int{2}
 }
 
 // LogLevel mirrors common logging severity levels.
@@ -192,11 +292,11 @@ func (x LogLevel) String() string {
 }
 
 func (LogLevel) Descriptor() protoreflect.EnumDescriptor {
-	return file_agent_proto_enumTypes[2].Descriptor()
+	return file_agent_proto_enumTypes[3].Descriptor()
 }
 
 func (LogLevel) Type() protoreflect.EnumType {
-	return &file_agent_proto_enumTypes[2]
+	return &file_agent_proto_enumTypes[3]
 }
 
 func (x LogLevel) Number() protoreflect.EnumNumber {
@@ -205,7 +305,7 @@ func (x LogLevel) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use LogLevel.Descriptor instead.
 func (LogLevel) EnumDescriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{2}
+	return file_agent_proto_rawDescGZIP(), []int{3}
 }
 
 // RegisterRequest carries static information about the agent's host environment.
@@ -228,9 +328,13 @@ type RegisterRequest struct {
 	// for deduplication and upsert, ensuring the same physical agent is never
 	// registered twice even if its hostname changes (e.g. Docker redeploy).
 	// Empty on first-ever registration; populated on all subsequent connects.
-	AgentId       string `protobuf:"bytes,6,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	AgentId string `protobuf:"bytes,6,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// key_share_public_key is the X25519 public key (base64) other agents seal
+	// the repository keys of zero-knowledge policies to when they are shared
+	// with this agent for a restore.
+	KeySharePublicKey string `protobuf:"bytes,7,opt,name=key_share_public_key,json=keySharePublicKey,proto3" json:"key_share_public_key,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *RegisterRequest) Reset() {
@@ -305,6 +409,13 @@ func (x *RegisterRequest) GetAgentId() string {
 	return ""
 }
 
+func (x *RegisterRequest) GetKeySharePublicKey() string {
+	if x != nil {
+		return x.KeySharePublicKey
+	}
+	return ""
+}
+
 // AgentCapabilities describes which optional features are available on the agent.
 // Capabilities depend on the binaries installed on the host or container image.
 type AgentCapabilities struct {
@@ -754,7 +865,10 @@ type JobStatusReport struct {
 	// Examples: "Starting backup of /var/data", "3 files added, 0 errors", "connection refused"
 	Message string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
 	// timestamp is when this status transition occurred on the agent.
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// failure_class classifies a FAILED report so the server can decide
+	// whether the policy's retry settings apply. Unset for other statuses.
+	FailureClass  FailureClass `protobuf:"varint,6,opt,name=failure_class,json=failureClass,proto3,enum=agent.FailureClass" json:"failure_class,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -824,6 +938,13 @@ func (x *JobStatusReport) GetTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *JobStatusReport) GetFailureClass() FailureClass {
+	if x != nil {
+		return x.FailureClass
+	}
+	return FailureClass_FAILURE_CLASS_UNSPECIFIED
+}
+
 // JobStatusResponse acknowledges receipt of the status report.
 type JobStatusResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -880,7 +1001,8 @@ type DestinationStatusReport struct {
 	AgentId string `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	// destination_id is the UUID of the destination this report refers to.
 	DestinationId string `protobuf:"bytes,3,opt,name=destination_id,json=destinationId,proto3" json:"destination_id,omitempty"`
-	// status is either "succeeded" or "failed".
+	// status is "succeeded", "succeeded_with_warnings" (restic created the
+	// snapshot but could not read some source files) or "failed".
 	Status string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
 	// snapshot_id is the full restic snapshot ID created by this backup run.
 	// Empty when status is "failed".
@@ -893,9 +1015,33 @@ type DestinationStatusReport struct {
 	// started_at is when the agent began the backup to this destination.
 	// Recorded immediately before invoking restic so the server can persist
 	// an accurate started_at on the JobDestination row.
-	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	StartedAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	// Change statistics from the restic summary event. Zero when status is
+	// "failed". total_files is the number of files processed.
+	FilesNew        uint64 `protobuf:"varint,9,opt,name=files_new,json=filesNew,proto3" json:"files_new,omitempty"`
+	FilesChanged    uint64 `protobuf:"varint,10,opt,name=files_changed,json=filesChanged,proto3" json:"files_changed,omitempty"`
+	FilesUnmodified uint64 `protobuf:"varint,11,opt,name=files_unmodified,json=filesUnmodified,proto3" json:"files_unmodified,omitempty"`
+	TotalFiles      uint64 `protobuf:"varint,12,opt,name=total_files,json=totalFiles,proto3" json:"total_files,omitempty"`
+	DataAdded       uint64 `protobuf:"varint,13,opt,name=data_added,json=dataAdded,proto3" json:"data_added,omitempty"`
+	// file_errors lists the per-file errors restic reported, at most 100;
+	// file_error_count is the full count. Only set with
+	// "succeeded_with_warnings".
+	FileErrors     []*FileError `protobuf:"bytes,14,rep,name=file_errors,json=fileErrors,proto3" json:"file_errors,omitempty"`
+	FileErrorCount uint64       `protobuf:"varint,15,opt,name=file_error_count,json=fileErrorCount,proto3" json:"file_error_count,omitempty"`
+	// copied_snapshots lists the snapshots of the source destination that a
+	// JOB_TYPE_REPLICATE job found copied to this destination, including
+	// those copied by earlier runs. snapshot_id is then empty.
+	CopiedSnapshots []*CopiedSnapshot `protobuf:"bytes,16,rep,name=copied_snapshots,json=copiedSnapshots,proto3" json:"copied_snapshots,omitempty"`
+	// key_fingerprint is the fingerprint of the repository key the agent
+	// opened the destination with, for zero-knowledge policies. Empty
+	// otherwise.
+	KeyFingerprint string `protobuf:"bytes,17,opt,name=key_fingerprint,json=keyFingerprint,proto3" json:"key_fingerprint,omitempty"`
+	// stdin_snapshots lists the snapshots of data streamed to restic on
+	// stdin during the same backup, such as database dumps: one per stream,
+	// besides the snapshot of the file sources in snapshot_id.
+	StdinSnapshots []*StdinSnapshot `protobuf:"bytes,18,rep,name=stdin_snapshots,json=stdinSnapshots,proto3" json:"stdin_snapshots,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *DestinationStatusReport) Reset() {
@@ -984,28 +1130,102 @@ func (x *DestinationStatusReport) GetStartedAt() *timestamppb.Timestamp {
 	return nil
 }
 
-// DestinationStatusResponse acknowledges receipt of the destination report.
-type DestinationStatusResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+func (x *DestinationStatusReport) GetFilesNew() uint64 {
+	if x != nil {
+		return x.FilesNew
+	}
+	return 0
+}
+
+func (x *DestinationStatusReport) GetFilesChanged() uint64 {
+	if x != nil {
+		return x.FilesChanged
+	}
+	return 0
+}
+
+func (x *DestinationStatusReport) GetFilesUnmodified() uint64 {
+	if x != nil {
+		return x.FilesUnmodified
+	}
+	return 0
+}
+
+func (x *DestinationStatusReport) GetTotalFiles() uint64 {
+	if x != nil {
+		return x.TotalFiles
+	}
+	return 0
+}
+
+func (x *DestinationStatusReport) GetDataAdded() uint64 {
+	if x != nil {
+		return x.DataAdded
+	}
+	return 0
+}
+
+func (x *DestinationStatusReport) GetFileErrors() []*FileError {
+	if x != nil {
+		return x.FileErrors
+	}
+	return nil
+}
+
+func (x *DestinationStatusReport) GetFileErrorCount() uint64 {
+	if x != nil {
+		return x.FileErrorCount
+	}
+	return 0
+}
+
+func (x *DestinationStatusReport) GetCopiedSnapshots() []*CopiedSnapshot {
+	if x != nil {
+		return x.CopiedSnapshots
+	}
+	return nil
+}
+
+func (x *DestinationStatusReport) GetKeyFingerprint() string {
+	if x != nil {
+		return x.KeyFingerprint
+	}
+	return ""
+}
+
+func (x *DestinationStatusReport) GetStdinSnapshots() []*StdinSnapshot {
+	if x != nil {
+		return x.StdinSnapshots
+	}
+	return nil
+}
+
+// StdinSnapshot is a snapshot restic took of a stream read on stdin.
+type StdinSnapshot struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	SnapshotId string                 `protobuf:"bytes,1,opt,name=snapshot_id,json=snapshotId,proto3" json:"snapshot_id,omitempty"`
+	// filename is the name the stream is stored under in the snapshot.
+	Filename      string   `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	Tags          []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	SizeBytes     int64    `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DestinationStatusResponse) Reset() {
-	*x = DestinationStatusResponse{}
+func (x *StdinSnapshot) Reset() {
+	*x = StdinSnapshot{}
 	mi := &file_agent_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DestinationStatusResponse) String() string {
+func (x *StdinSnapshot) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DestinationStatusResponse) ProtoMessage() {}
+func (*StdinSnapshot) ProtoMessage() {}
 
-func (x *DestinationStatusResponse) ProtoReflect() protoreflect.Message {
+func (x *StdinSnapshot) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1017,52 +1237,68 @@ func (x *DestinationStatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DestinationStatusResponse.ProtoReflect.Descriptor instead.
-func (*DestinationStatusResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use StdinSnapshot.ProtoReflect.Descriptor instead.
+func (*StdinSnapshot) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *DestinationStatusResponse) GetOk() bool {
+func (x *StdinSnapshot) GetSnapshotId() string {
 	if x != nil {
-		return x.Ok
+		return x.SnapshotId
 	}
-	return false
+	return ""
 }
 
-// LogEntry is a single log line produced by the agent during job execution.
-// Entries are streamed in order and buffered server-side for bulk DB insert.
-type LogEntry struct {
+func (x *StdinSnapshot) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *StdinSnapshot) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *StdinSnapshot) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+// CopiedSnapshot is a snapshot restic copy wrote to a destination.
+type CopiedSnapshot struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// job_id links this log entry to its parent job record.
-	JobId string `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	// agent_id is included so the server can validate the sender.
-	AgentId string `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	// level indicates severity, used for filtering in the GUI log viewer.
-	Level LogLevel `protobuf:"varint,3,opt,name=level,proto3,enum=agent.LogLevel" json:"level,omitempty"`
-	// message is the raw log line from the executor or restic wrapper.
-	// Sensitive values (passwords, credentials) must be redacted by the agent
-	// before including them here. See agent/internal/restic/redact.go.
-	Message string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
-	// timestamp is when the log line was produced on the agent.
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// snapshot_id is the ID of the copy in the destination repository.
+	SnapshotId string `protobuf:"bytes,1,opt,name=snapshot_id,json=snapshotId,proto3" json:"snapshot_id,omitempty"`
+	// original_id is the ID of the snapshot in the source repository.
+	OriginalId string   `protobuf:"bytes,2,opt,name=original_id,json=originalId,proto3" json:"original_id,omitempty"`
+	Tags       []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Hostname   string   `protobuf:"bytes,4,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	// time is when the original snapshot was taken.
+	Time          *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=time,proto3" json:"time,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LogEntry) Reset() {
-	*x = LogEntry{}
+func (x *CopiedSnapshot) Reset() {
+	*x = CopiedSnapshot{}
 	mi := &file_agent_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LogEntry) String() string {
+func (x *CopiedSnapshot) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LogEntry) ProtoMessage() {}
+func (*CopiedSnapshot) ProtoMessage() {}
 
-func (x *LogEntry) ProtoReflect() protoreflect.Message {
+func (x *CopiedSnapshot) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1074,70 +1310,72 @@ func (x *LogEntry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
-func (*LogEntry) Descriptor() ([]byte, []int) {
+// Deprecated: Use CopiedSnapshot.ProtoReflect.Descriptor instead.
+func (*CopiedSnapshot) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *LogEntry) GetJobId() string {
+func (x *CopiedSnapshot) GetSnapshotId() string {
 	if x != nil {
-		return x.JobId
+		return x.SnapshotId
 	}
 	return ""
 }
 
-func (x *LogEntry) GetAgentId() string {
+func (x *CopiedSnapshot) GetOriginalId() string {
 	if x != nil {
-		return x.AgentId
+		return x.OriginalId
 	}
 	return ""
 }
 
-func (x *LogEntry) GetLevel() LogLevel {
+func (x *CopiedSnapshot) GetTags() []string {
 	if x != nil {
-		return x.Level
+		return x.Tags
 	}
-	return LogLevel_LOG_LEVEL_UNSPECIFIED
+	return nil
 }
 
-func (x *LogEntry) GetMessage() string {
+func (x *CopiedSnapshot) GetHostname() string {
 	if x != nil {
-		return x.Message
+		return x.Hostname
 	}
 	return ""
 }
 
-func (x *LogEntry) GetTimestamp() *timestamppb.Timestamp {
+func (x *CopiedSnapshot) GetTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Timestamp
+		return x.Time
 	}
 	return nil
 }
 
-// LogStreamResponse is returned by the server when the client closes the stream.
-type LogStreamResponse struct {
+// FileError is a source file or directory restic could not back up.
+type FileError struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// entries_received is the total number of log entries the server accepted.
-	// The agent can use this for basic consistency checking.
-	EntriesReceived uint32 `protobuf:"varint,1,opt,name=entries_received,json=entriesReceived,proto3" json:"entries_received,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	// path is the file restic reported the error for.
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// during is the backup phase: "scan", "archival" or similar.
+	During        string `protobuf:"bytes,2,opt,name=during,proto3" json:"during,omitempty"`
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LogStreamResponse) Reset() {
-	*x = LogStreamResponse{}
+func (x *FileError) Reset() {
+	*x = FileError{}
 	mi := &file_agent_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LogStreamResponse) String() string {
+func (x *FileError) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LogStreamResponse) ProtoMessage() {}
+func (*FileError) ProtoMessage() {}
 
-func (x *LogStreamResponse) ProtoReflect() protoreflect.Message {
+func (x *FileError) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1149,46 +1387,59 @@ func (x *LogStreamResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LogStreamResponse.ProtoReflect.Descriptor instead.
-func (*LogStreamResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use FileError.ProtoReflect.Descriptor instead.
+func (*FileError) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *LogStreamResponse) GetEntriesReceived() uint32 {
+func (x *FileError) GetPath() string {
 	if x != nil {
-		return x.EntriesReceived
+		return x.Path
 	}
-	return 0
+	return ""
 }
 
-// VolumeInfo holds the metadata of a single Docker volume on the agent host.
-// Mirrors docker.VolumeInfo in agent/internal/docker/discovery.go.
-type VolumeInfo struct {
+func (x *FileError) GetDuring() string {
+	if x != nil {
+		return x.During
+	}
+	return ""
+}
+
+func (x *FileError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// DestinationStatusResponse acknowledges receipt of the destination report.
+type DestinationStatusResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// name is the Docker volume name (e.g. "myapp_postgres_data").
-	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	// mountpoint is the absolute host path where the volume data lives.
-	Mountpoint string `protobuf:"bytes,2,opt,name=mountpoint,proto3" json:"mountpoint,omitempty"`
-	// driver is the volume driver (e.g. "local", "rexray/s3").
-	Driver        string `protobuf:"bytes,3,opt,name=driver,proto3" json:"driver,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Ok    bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	// hold_snapshot_id is set when the server flagged the backup run as
+	// anomalous and the policy holds the last known-good snapshot: the agent
+	// tags that snapshot "arkeep:hold" on the destination before applying
+	// retention, then reports the rewritten snapshot via ReportSnapshotHold.
+	HoldSnapshotId string `protobuf:"bytes,2,opt,name=hold_snapshot_id,json=holdSnapshotId,proto3" json:"hold_snapshot_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *VolumeInfo) Reset() {
-	*x = VolumeInfo{}
+func (x *DestinationStatusResponse) Reset() {
+	*x = DestinationStatusResponse{}
 	mi := &file_agent_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *VolumeInfo) String() string {
+func (x *DestinationStatusResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VolumeInfo) ProtoMessage() {}
+func (*DestinationStatusResponse) ProtoMessage() {}
 
-func (x *VolumeInfo) ProtoReflect() protoreflect.Message {
+func (x *DestinationStatusResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1200,40 +1451,230 @@ func (x *VolumeInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VolumeInfo.ProtoReflect.Descriptor instead.
-func (*VolumeInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use DestinationStatusResponse.ProtoReflect.Descriptor instead.
+func (*DestinationStatusResponse) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *VolumeInfo) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
-}
-
-func (x *VolumeInfo) GetMountpoint() string {
+func (x *DestinationStatusResponse) GetOk() bool {
 	if x != nil {
-		return x.Mountpoint
+		return x.Ok
 	}
-	return ""
+	return false
 }
 
-func (x *VolumeInfo) GetDriver() string {
+func (x *DestinationStatusResponse) GetHoldSnapshotId() string {
 	if x != nil {
-		return x.Driver
+		return x.HoldSnapshotId
 	}
 	return ""
 }
 
-// VolumeListReport is sent by the agent in response to a JOB_TYPE_LIST_VOLUMES
-// assignment. The correlation_id echoes the job_id from the JobAssignment so
-// the server can match this response to the waiting REST request.
-type VolumeListReport struct {
+// LogEntry is a single log line produced by the agent during job execution.
+// Entries are streamed in order and buffered server-side for bulk DB insert.
+type LogEntry struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// agent_id identifies the reporting agent.
-	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	// correlation_id echoes the job_id from the JOB_TYPE_LIST_VOLUMES assignment.
+	// job_id links this log entry to its parent job record.
+	JobId string `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// agent_id is included so the server can validate the sender.
+	AgentId string `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// level indicates severity, used for filtering in the GUI log viewer.
+	Level LogLevel `protobuf:"varint,3,opt,name=level,proto3,enum=agent.LogLevel" json:"level,omitempty"`
+	// message is the raw log line from the executor or restic wrapper.
+	// Sensitive values (passwords, credentials) must be redacted by the agent
+	// before including them here. See agent/internal/restic/redact.go.
+	Message string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	// timestamp is when the log line was produced on the agent.
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogEntry) Reset() {
+	*x = LogEntry{}
+	mi := &file_agent_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogEntry) ProtoMessage() {}
+
+func (x *LogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
+func (*LogEntry) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *LogEntry) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *LogEntry) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *LogEntry) GetLevel() LogLevel {
+	if x != nil {
+		return x.Level
+	}
+	return LogLevel_LOG_LEVEL_UNSPECIFIED
+}
+
+func (x *LogEntry) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LogEntry) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// LogStreamResponse is returned by the server when the client closes the stream.
+type LogStreamResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// entries_received is the total number of log entries the server accepted.
+	// The agent can use this for basic consistency checking.
+	EntriesReceived uint32 `protobuf:"varint,1,opt,name=entries_received,json=entriesReceived,proto3" json:"entries_received,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *LogStreamResponse) Reset() {
+	*x = LogStreamResponse{}
+	mi := &file_agent_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogStreamResponse) ProtoMessage() {}
+
+func (x *LogStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogStreamResponse.ProtoReflect.Descriptor instead.
+func (*LogStreamResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *LogStreamResponse) GetEntriesReceived() uint32 {
+	if x != nil {
+		return x.EntriesReceived
+	}
+	return 0
+}
+
+// VolumeInfo holds the metadata of a single Docker volume on the agent host.
+// Mirrors docker.VolumeInfo in agent/internal/docker/discovery.go.
+type VolumeInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name is the Docker volume name (e.g. "myapp_postgres_data").
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// mountpoint is the absolute host path where the volume data lives.
+	Mountpoint string `protobuf:"bytes,2,opt,name=mountpoint,proto3" json:"mountpoint,omitempty"`
+	// driver is the volume driver (e.g. "local", "rexray/s3").
+	Driver        string `protobuf:"bytes,3,opt,name=driver,proto3" json:"driver,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VolumeInfo) Reset() {
+	*x = VolumeInfo{}
+	mi := &file_agent_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VolumeInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VolumeInfo) ProtoMessage() {}
+
+func (x *VolumeInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VolumeInfo.ProtoReflect.Descriptor instead.
+func (*VolumeInfo) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *VolumeInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *VolumeInfo) GetMountpoint() string {
+	if x != nil {
+		return x.Mountpoint
+	}
+	return ""
+}
+
+func (x *VolumeInfo) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+// VolumeListReport is sent by the agent in response to a JOB_TYPE_LIST_VOLUMES
+// assignment. The correlation_id echoes the job_id from the JobAssignment so
+// the server can match this response to the waiting REST request.
+type VolumeListReport struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// agent_id identifies the reporting agent.
+	AgentId string `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// correlation_id echoes the job_id from the JOB_TYPE_LIST_VOLUMES assignment.
 	CorrelationId string `protobuf:"bytes,2,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
 	// volumes is the list of Docker volumes found on the agent host.
 	// Empty if Docker is unavailable or no volumes exist.
@@ -1245,21 +1686,830 @@ type VolumeListReport struct {
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *VolumeListReport) Reset() {
-	*x = VolumeListReport{}
-	mi := &file_agent_proto_msgTypes[15]
+func (x *VolumeListReport) Reset() {
+	*x = VolumeListReport{}
+	mi := &file_agent_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VolumeListReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VolumeListReport) ProtoMessage() {}
+
+func (x *VolumeListReport) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VolumeListReport.ProtoReflect.Descriptor instead.
+func (*VolumeListReport) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *VolumeListReport) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *VolumeListReport) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *VolumeListReport) GetVolumes() []*VolumeInfo {
+	if x != nil {
+		return x.Volumes
+	}
+	return nil
+}
+
+func (x *VolumeListReport) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// VolumeListResponse acknowledges receipt of the volume list report.
+type VolumeListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VolumeListResponse) Reset() {
+	*x = VolumeListResponse{}
+	mi := &file_agent_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VolumeListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VolumeListResponse) ProtoMessage() {}
+
+func (x *VolumeListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VolumeListResponse.ProtoReflect.Descriptor instead.
+func (*VolumeListResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *VolumeListResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+// DirectorySize is the size of the files directly inside one directory.
+type DirectorySize struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Bytes         uint64                 `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	Files         uint64                 `protobuf:"varint,3,opt,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DirectorySize) Reset() {
+	*x = DirectorySize{}
+	mi := &file_agent_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DirectorySize) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirectorySize) ProtoMessage() {}
+
+func (x *DirectorySize) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirectorySize.ProtoReflect.Descriptor instead.
+func (*DirectorySize) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DirectorySize) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DirectorySize) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *DirectorySize) GetFiles() uint64 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+// UnreadablePath is a source path restic could not read during a dry run.
+type UnreadablePath struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnreadablePath) Reset() {
+	*x = UnreadablePath{}
+	mi := &file_agent_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnreadablePath) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnreadablePath) ProtoMessage() {}
+
+func (x *UnreadablePath) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnreadablePath.ProtoReflect.Descriptor instead.
+func (*UnreadablePath) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *UnreadablePath) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *UnreadablePath) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// DryRunReport is sent by the agent when a JOB_TYPE_DRY_RUN job has finished
+// scanning. All figures describe a first backup of the sources: the dry run
+// uses an empty scratch repository, so nothing is deduplicated against the
+// policy's existing snapshots.
+type DryRunReport struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// job_id links this report to the dry-run job record.
+	JobId string `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// agent_id identifies the reporting agent.
+	AgentId string `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// total_files is the number of files restic would back up.
+	TotalFiles uint64 `protobuf:"varint,3,opt,name=total_files,json=totalFiles,proto3" json:"total_files,omitempty"`
+	// total_bytes is the size of those files.
+	TotalBytes uint64 `protobuf:"varint,4,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	// data_added is the size restic would upload after deduplication and
+	// compression within the sources.
+	DataAdded uint64 `protobuf:"varint,5,opt,name=data_added,json=dataAdded,proto3" json:"data_added,omitempty"`
+	// largest_directories lists the directories holding the most data,
+	// largest first.
+	LargestDirectories []*DirectorySize `protobuf:"bytes,6,rep,name=largest_directories,json=largestDirectories,proto3" json:"largest_directories,omitempty"`
+	// unreadable_paths lists paths that could not be read, truncated to a
+	// bounded number of entries; unreadable_count is the full count.
+	UnreadablePaths []*UnreadablePath `protobuf:"bytes,7,rep,name=unreadable_paths,json=unreadablePaths,proto3" json:"unreadable_paths,omitempty"`
+	UnreadableCount uint64            `protobuf:"varint,8,opt,name=unreadable_count,json=unreadableCount,proto3" json:"unreadable_count,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DryRunReport) Reset() {
+	*x = DryRunReport{}
+	mi := &file_agent_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DryRunReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DryRunReport) ProtoMessage() {}
+
+func (x *DryRunReport) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DryRunReport.ProtoReflect.Descriptor instead.
+func (*DryRunReport) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DryRunReport) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *DryRunReport) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *DryRunReport) GetTotalFiles() uint64 {
+	if x != nil {
+		return x.TotalFiles
+	}
+	return 0
+}
+
+func (x *DryRunReport) GetTotalBytes() uint64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *DryRunReport) GetDataAdded() uint64 {
+	if x != nil {
+		return x.DataAdded
+	}
+	return 0
+}
+
+func (x *DryRunReport) GetLargestDirectories() []*DirectorySize {
+	if x != nil {
+		return x.LargestDirectories
+	}
+	return nil
+}
+
+func (x *DryRunReport) GetUnreadablePaths() []*UnreadablePath {
+	if x != nil {
+		return x.UnreadablePaths
+	}
+	return nil
+}
+
+func (x *DryRunReport) GetUnreadableCount() uint64 {
+	if x != nil {
+		return x.UnreadableCount
+	}
+	return 0
+}
+
+// DryRunResponse acknowledges receipt of the dry-run report.
+type DryRunResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DryRunResponse) Reset() {
+	*x = DryRunResponse{}
+	mi := &file_agent_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DryRunResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DryRunResponse) ProtoMessage() {}
+
+func (x *DryRunResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DryRunResponse.ProtoReflect.Descriptor instead.
+func (*DryRunResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DryRunResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+// SnapshotHoldReport tells the server that a snapshot was tagged as held.
+type SnapshotHoldReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	AgentId       string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	DestinationId string                 `protobuf:"bytes,3,opt,name=destination_id,json=destinationId,proto3" json:"destination_id,omitempty"`
+	// old_snapshot_id is the snapshot ID the server asked to hold.
+	OldSnapshotId string `protobuf:"bytes,4,opt,name=old_snapshot_id,json=oldSnapshotId,proto3" json:"old_snapshot_id,omitempty"`
+	// new_snapshot_id is the ID of the snapshot restic rewrote with the tag.
+	NewSnapshotId string `protobuf:"bytes,5,opt,name=new_snapshot_id,json=newSnapshotId,proto3" json:"new_snapshot_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotHoldReport) Reset() {
+	*x = SnapshotHoldReport{}
+	mi := &file_agent_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotHoldReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotHoldReport) ProtoMessage() {}
+
+func (x *SnapshotHoldReport) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotHoldReport.ProtoReflect.Descriptor instead.
+func (*SnapshotHoldReport) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SnapshotHoldReport) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *SnapshotHoldReport) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *SnapshotHoldReport) GetDestinationId() string {
+	if x != nil {
+		return x.DestinationId
+	}
+	return ""
+}
+
+func (x *SnapshotHoldReport) GetOldSnapshotId() string {
+	if x != nil {
+		return x.OldSnapshotId
+	}
+	return ""
+}
+
+func (x *SnapshotHoldReport) GetNewSnapshotId() string {
+	if x != nil {
+		return x.NewSnapshotId
+	}
+	return ""
+}
+
+// SnapshotHoldResponse acknowledges receipt of the hold report.
+type SnapshotHoldResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotHoldResponse) Reset() {
+	*x = SnapshotHoldResponse{}
+	mi := &file_agent_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotHoldResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotHoldResponse) ProtoMessage() {}
+
+func (x *SnapshotHoldResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotHoldResponse.ProtoReflect.Descriptor instead.
+func (*SnapshotHoldResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SnapshotHoldResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+// JobProgress is a progress snapshot of a running job, built from the latest
+// restic status event.
+type JobProgress struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	JobId   string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	AgentId string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// destination_id is the destination being backed up to. Empty for jobs
+	// without destinations (dry runs).
+	DestinationId string `protobuf:"bytes,3,opt,name=destination_id,json=destinationId,proto3" json:"destination_id,omitempty"`
+	// percent_done is between 0 and 1.
+	PercentDone float64 `protobuf:"fixed64,4,opt,name=percent_done,json=percentDone,proto3" json:"percent_done,omitempty"`
+	FilesDone   uint64  `protobuf:"varint,5,opt,name=files_done,json=filesDone,proto3" json:"files_done,omitempty"`
+	TotalFiles  uint64  `protobuf:"varint,6,opt,name=total_files,json=totalFiles,proto3" json:"total_files,omitempty"`
+	BytesDone   uint64  `protobuf:"varint,7,opt,name=bytes_done,json=bytesDone,proto3" json:"bytes_done,omitempty"`
+	TotalBytes  uint64  `protobuf:"varint,8,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	// current_file is one of the files restic is reading, empty between files.
+	CurrentFile string `protobuf:"bytes,9,opt,name=current_file,json=currentFile,proto3" json:"current_file,omitempty"`
+	// seconds_remaining is restic's ETA. Zero while restic is still scanning.
+	SecondsRemaining uint64 `protobuf:"varint,10,opt,name=seconds_remaining,json=secondsRemaining,proto3" json:"seconds_remaining,omitempty"`
+	SecondsElapsed   uint64 `protobuf:"varint,11,opt,name=seconds_elapsed,json=secondsElapsed,proto3" json:"seconds_elapsed,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *JobProgress) Reset() {
+	*x = JobProgress{}
+	mi := &file_agent_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobProgress) ProtoMessage() {}
+
+func (x *JobProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobProgress.ProtoReflect.Descriptor instead.
+func (*JobProgress) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *JobProgress) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *JobProgress) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *JobProgress) GetDestinationId() string {
+	if x != nil {
+		return x.DestinationId
+	}
+	return ""
+}
+
+func (x *JobProgress) GetPercentDone() float64 {
+	if x != nil {
+		return x.PercentDone
+	}
+	return 0
+}
+
+func (x *JobProgress) GetFilesDone() uint64 {
+	if x != nil {
+		return x.FilesDone
+	}
+	return 0
+}
+
+func (x *JobProgress) GetTotalFiles() uint64 {
+	if x != nil {
+		return x.TotalFiles
+	}
+	return 0
+}
+
+func (x *JobProgress) GetBytesDone() uint64 {
+	if x != nil {
+		return x.BytesDone
+	}
+	return 0
+}
+
+func (x *JobProgress) GetTotalBytes() uint64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *JobProgress) GetCurrentFile() string {
+	if x != nil {
+		return x.CurrentFile
+	}
+	return ""
+}
+
+func (x *JobProgress) GetSecondsRemaining() uint64 {
+	if x != nil {
+		return x.SecondsRemaining
+	}
+	return 0
+}
+
+func (x *JobProgress) GetSecondsElapsed() uint64 {
+	if x != nil {
+		return x.SecondsElapsed
+	}
+	return 0
+}
+
+// JobProgressResponse acknowledges receipt of the progress report.
+type JobProgressResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobProgressResponse) Reset() {
+	*x = JobProgressResponse{}
+	mi := &file_agent_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobProgressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobProgressResponse) ProtoMessage() {}
+
+func (x *JobProgressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobProgressResponse.ProtoReflect.Descriptor instead.
+func (*JobProgressResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *JobProgressResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+// KeyEscrowReport carries the escrow kit of an agent: every repository key
+// it holds, sealed to the admin's escrow public key.
+type KeyEscrowReport struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// escrow_key_fingerprint identifies the escrow public key the kit is
+	// sealed to.
+	EscrowKeyFingerprint string `protobuf:"bytes,2,opt,name=escrow_key_fingerprint,json=escrowKeyFingerprint,proto3" json:"escrow_key_fingerprint,omitempty"`
+	Kit                  []byte `protobuf:"bytes,3,opt,name=kit,proto3" json:"kit,omitempty"`
+	KeyCount             uint32 `protobuf:"varint,4,opt,name=key_count,json=keyCount,proto3" json:"key_count,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *KeyEscrowReport) Reset() {
+	*x = KeyEscrowReport{}
+	mi := &file_agent_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyEscrowReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyEscrowReport) ProtoMessage() {}
+
+func (x *KeyEscrowReport) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyEscrowReport.ProtoReflect.Descriptor instead.
+func (*KeyEscrowReport) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *KeyEscrowReport) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *KeyEscrowReport) GetEscrowKeyFingerprint() string {
+	if x != nil {
+		return x.EscrowKeyFingerprint
+	}
+	return ""
+}
+
+func (x *KeyEscrowReport) GetKit() []byte {
+	if x != nil {
+		return x.Kit
+	}
+	return nil
+}
+
+func (x *KeyEscrowReport) GetKeyCount() uint32 {
+	if x != nil {
+		return x.KeyCount
+	}
+	return 0
+}
+
+// KeyEscrowResponse acknowledges receipt of the escrow kit.
+type KeyEscrowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyEscrowResponse) Reset() {
+	*x = KeyEscrowResponse{}
+	mi := &file_agent_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyEscrowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyEscrowResponse) ProtoMessage() {}
+
+func (x *KeyEscrowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyEscrowResponse.ProtoReflect.Descriptor instead.
+func (*KeyEscrowResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *KeyEscrowResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+// KeyShareReport answers a JOB_TYPE_SHARE_KEY assignment.
+type KeyShareReport struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// share_id echoes the job_id of the JOB_TYPE_SHARE_KEY assignment.
+	ShareId string `protobuf:"bytes,2,opt,name=share_id,json=shareId,proto3" json:"share_id,omitempty"`
+	// sealed_key is the repository key sealed to the recipient's public key.
+	// Empty when error is set.
+	SealedKey []byte `protobuf:"bytes,3,opt,name=sealed_key,json=sealedKey,proto3" json:"sealed_key,omitempty"`
+	// error is set when the agent could not share the key (e.g. it does not
+	// hold it).
+	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyShareReport) Reset() {
+	*x = KeyShareReport{}
+	mi := &file_agent_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *VolumeListReport) String() string {
+func (x *KeyShareReport) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VolumeListReport) ProtoMessage() {}
+func (*KeyShareReport) ProtoMessage() {}
 
-func (x *VolumeListReport) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[15]
+func (x *KeyShareReport) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1270,62 +2520,62 @@ func (x *VolumeListReport) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VolumeListReport.ProtoReflect.Descriptor instead.
-func (*VolumeListReport) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use KeyShareReport.ProtoReflect.Descriptor instead.
+func (*KeyShareReport) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *VolumeListReport) GetAgentId() string {
+func (x *KeyShareReport) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *VolumeListReport) GetCorrelationId() string {
+func (x *KeyShareReport) GetShareId() string {
 	if x != nil {
-		return x.CorrelationId
+		return x.ShareId
 	}
 	return ""
 }
 
-func (x *VolumeListReport) GetVolumes() []*VolumeInfo {
+func (x *KeyShareReport) GetSealedKey() []byte {
 	if x != nil {
-		return x.Volumes
+		return x.SealedKey
 	}
 	return nil
 }
 
-func (x *VolumeListReport) GetError() string {
+func (x *KeyShareReport) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-// VolumeListResponse acknowledges receipt of the volume list report.
-type VolumeListResponse struct {
+// KeyShareResponse acknowledges receipt of the key share.
+type KeyShareResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *VolumeListResponse) Reset() {
-	*x = VolumeListResponse{}
-	mi := &file_agent_proto_msgTypes[16]
+func (x *KeyShareResponse) Reset() {
+	*x = KeyShareResponse{}
+	mi := &file_agent_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *VolumeListResponse) String() string {
+func (x *KeyShareResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VolumeListResponse) ProtoMessage() {}
+func (*KeyShareResponse) ProtoMessage() {}
 
-func (x *VolumeListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[16]
+func (x *KeyShareResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1336,12 +2586,224 @@ func (x *VolumeListResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VolumeListResponse.ProtoReflect.Descriptor instead.
-func (*VolumeListResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use KeyShareResponse.ProtoReflect.Descriptor instead.
+func (*KeyShareResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *VolumeListResponse) GetOk() bool {
+func (x *KeyShareResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+// RepositoryScanReport answers a JOB_TYPE_SCAN_REPOSITORY assignment.
+type RepositoryScanReport struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AgentId string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// import_id echoes the job_id of the JOB_TYPE_SCAN_REPOSITORY assignment.
+	ImportId  string             `protobuf:"bytes,2,opt,name=import_id,json=importId,proto3" json:"import_id,omitempty"`
+	Snapshots []*ScannedSnapshot `protobuf:"bytes,3,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	// error is set when the agent could not open the repository (e.g. wrong
+	// password).
+	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RepositoryScanReport) Reset() {
+	*x = RepositoryScanReport{}
+	mi := &file_agent_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RepositoryScanReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RepositoryScanReport) ProtoMessage() {}
+
+func (x *RepositoryScanReport) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RepositoryScanReport.ProtoReflect.Descriptor instead.
+func (*RepositoryScanReport) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *RepositoryScanReport) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RepositoryScanReport) GetImportId() string {
+	if x != nil {
+		return x.ImportId
+	}
+	return ""
+}
+
+func (x *RepositoryScanReport) GetSnapshots() []*ScannedSnapshot {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+func (x *RepositoryScanReport) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// ScannedSnapshot is one snapshot found in a scanned repository.
+type ScannedSnapshot struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	SnapshotId string                 `protobuf:"bytes,1,opt,name=snapshot_id,json=snapshotId,proto3" json:"snapshot_id,omitempty"` // full restic snapshot ID
+	Time       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	Hostname   string                 `protobuf:"bytes,3,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Paths      []string               `protobuf:"bytes,4,rep,name=paths,proto3" json:"paths,omitempty"`
+	Tags       []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	// size_bytes and file_count come from the snapshot summary, which restic
+	// records since 0.17; 0 for older snapshots.
+	SizeBytes     int64 `protobuf:"varint,6,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	FileCount     int64 `protobuf:"varint,7,opt,name=file_count,json=fileCount,proto3" json:"file_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScannedSnapshot) Reset() {
+	*x = ScannedSnapshot{}
+	mi := &file_agent_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScannedSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScannedSnapshot) ProtoMessage() {}
+
+func (x *ScannedSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScannedSnapshot.ProtoReflect.Descriptor instead.
+func (*ScannedSnapshot) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ScannedSnapshot) GetSnapshotId() string {
+	if x != nil {
+		return x.SnapshotId
+	}
+	return ""
+}
+
+func (x *ScannedSnapshot) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *ScannedSnapshot) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *ScannedSnapshot) GetPaths() []string {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+func (x *ScannedSnapshot) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ScannedSnapshot) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *ScannedSnapshot) GetFileCount() int64 {
+	if x != nil {
+		return x.FileCount
+	}
+	return 0
+}
+
+// RepositoryScanResponse acknowledges receipt of the scan.
+type RepositoryScanResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RepositoryScanResponse) Reset() {
+	*x = RepositoryScanResponse{}
+	mi := &file_agent_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RepositoryScanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RepositoryScanResponse) ProtoMessage() {}
+
+func (x *RepositoryScanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RepositoryScanResponse.ProtoReflect.Descriptor instead.
+func (*RepositoryScanResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RepositoryScanResponse) GetOk() bool {
 	if x != nil {
 		return x.Ok
 	}
@@ -1352,14 +2814,15 @@ var File_agent_proto protoreflect.FileDescriptor
 
 const file_agent_proto_rawDesc = "" +
 	"\n" +
-	"\vagent.proto\x12\x05agent\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc4\x01\n" +
+	"\vagent.proto\x12\x05agent\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf5\x01\n" +
 	"\x0fRegisterRequest\x12\x1a\n" +
 	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x0e\n" +
 	"\x02os\x18\x03 \x01(\tR\x02os\x12\x12\n" +
 	"\x04arch\x18\x04 \x01(\tR\x04arch\x12<\n" +
 	"\fcapabilities\x18\x05 \x01(\v2\x18.agent.AgentCapabilitiesR\fcapabilities\x12\x19\n" +
-	"\bagent_id\x18\x06 \x01(\tR\aagentId\"[\n" +
+	"\bagent_id\x18\x06 \x01(\tR\aagentId\x12/\n" +
+	"\x14key_share_public_key\x18\a \x01(\tR\x11keySharePublicKey\"[\n" +
 	"\x11AgentCapabilities\x12\x16\n" +
 	"\x06docker\x18\x01 \x01(\bR\x06docker\x12\x16\n" +
 	"\x06restic\x18\x02 \x01(\bR\x06restic\x12\x16\n" +
@@ -1386,15 +2849,16 @@ const file_agent_proto_rawDesc = "" +
 	"\tpolicy_id\x18\x02 \x01(\tR\bpolicyId\x12\"\n" +
 	"\x04type\x18\x03 \x01(\x0e2\x0e.agent.JobTypeR\x04type\x12\x18\n" +
 	"\apayload\x18\x04 \x01(\fR\apayload\x12=\n" +
-	"\fscheduled_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vscheduledAt\"\xc1\x01\n" +
+	"\fscheduled_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vscheduledAt\"\xfb\x01\n" +
 	"\x0fJobStatusReport\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
 	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12(\n" +
 	"\x06status\x18\x03 \x01(\x0e2\x10.agent.JobStatusR\x06status\x12\x18\n" +
 	"\amessage\x18\x04 \x01(\tR\amessage\x128\n" +
-	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"#\n" +
+	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x128\n" +
+	"\rfailure_class\x18\x06 \x01(\x0e2\x13.agent.FailureClassR\ffailureClass\"#\n" +
 	"\x11JobStatusResponse\x12\x0e\n" +
-	"\x02ok\x18\x01 \x01(\bR\x02ok\"\x9b\x02\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\"\xcf\x05\n" +
 	"\x17DestinationStatusReport\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
 	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12%\n" +
@@ -1406,9 +2870,43 @@ const file_agent_proto_rawDesc = "" +
 	"size_bytes\x18\x06 \x01(\x03R\tsizeBytes\x12\x14\n" +
 	"\x05error\x18\a \x01(\tR\x05error\x129\n" +
 	"\n" +
-	"started_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\"+\n" +
+	"started_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12\x1b\n" +
+	"\tfiles_new\x18\t \x01(\x04R\bfilesNew\x12#\n" +
+	"\rfiles_changed\x18\n" +
+	" \x01(\x04R\ffilesChanged\x12)\n" +
+	"\x10files_unmodified\x18\v \x01(\x04R\x0ffilesUnmodified\x12\x1f\n" +
+	"\vtotal_files\x18\f \x01(\x04R\n" +
+	"totalFiles\x12\x1d\n" +
+	"\n" +
+	"data_added\x18\r \x01(\x04R\tdataAdded\x121\n" +
+	"\vfile_errors\x18\x0e \x03(\v2\x10.agent.FileErrorR\n" +
+	"fileErrors\x12(\n" +
+	"\x10file_error_count\x18\x0f \x01(\x04R\x0efileErrorCount\x12@\n" +
+	"\x10copied_snapshots\x18\x10 \x03(\v2\x15.agent.CopiedSnapshotR\x0fcopiedSnapshots\x12'\n" +
+	"\x0fkey_fingerprint\x18\x11 \x01(\tR\x0ekeyFingerprint\x12=\n" +
+	"\x0fstdin_snapshots\x18\x12 \x03(\v2\x14.agent.StdinSnapshotR\x0estdinSnapshots\"\x7f\n" +
+	"\rStdinSnapshot\x12\x1f\n" +
+	"\vsnapshot_id\x18\x01 \x01(\tR\n" +
+	"snapshotId\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x03R\tsizeBytes\"\xb2\x01\n" +
+	"\x0eCopiedSnapshot\x12\x1f\n" +
+	"\vsnapshot_id\x18\x01 \x01(\tR\n" +
+	"snapshotId\x12\x1f\n" +
+	"\voriginal_id\x18\x02 \x01(\tR\n" +
+	"originalId\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x1a\n" +
+	"\bhostname\x18\x04 \x01(\tR\bhostname\x12.\n" +
+	"\x04time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"Q\n" +
+	"\tFileError\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06during\x18\x02 \x01(\tR\x06during\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"U\n" +
 	"\x19DestinationStatusResponse\x12\x0e\n" +
-	"\x02ok\x18\x01 \x01(\bR\x02ok\"\xb7\x01\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12(\n" +
+	"\x10hold_snapshot_id\x18\x02 \x01(\tR\x0eholdSnapshotId\"\xb7\x01\n" +
 	"\bLogEntry\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
 	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12%\n" +
@@ -1430,14 +2928,107 @@ const file_agent_proto_rawDesc = "" +
 	"\avolumes\x18\x03 \x03(\v2\x11.agent.VolumeInfoR\avolumes\x12\x14\n" +
 	"\x05error\x18\x04 \x01(\tR\x05error\"$\n" +
 	"\x12VolumeListResponse\x12\x0e\n" +
-	"\x02ok\x18\x01 \x01(\bR\x02ok*\x93\x01\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\"O\n" +
+	"\rDirectorySize\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x04R\x05bytes\x12\x14\n" +
+	"\x05files\x18\x03 \x01(\x04R\x05files\":\n" +
+	"\x0eUnreadablePath\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xd5\x02\n" +
+	"\fDryRunReport\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x1f\n" +
+	"\vtotal_files\x18\x03 \x01(\x04R\n" +
+	"totalFiles\x12\x1f\n" +
+	"\vtotal_bytes\x18\x04 \x01(\x04R\n" +
+	"totalBytes\x12\x1d\n" +
+	"\n" +
+	"data_added\x18\x05 \x01(\x04R\tdataAdded\x12E\n" +
+	"\x13largest_directories\x18\x06 \x03(\v2\x14.agent.DirectorySizeR\x12largestDirectories\x12@\n" +
+	"\x10unreadable_paths\x18\a \x03(\v2\x15.agent.UnreadablePathR\x0funreadablePaths\x12)\n" +
+	"\x10unreadable_count\x18\b \x01(\x04R\x0funreadableCount\" \n" +
+	"\x0eDryRunResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\"\xbd\x01\n" +
+	"\x12SnapshotHoldReport\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12%\n" +
+	"\x0edestination_id\x18\x03 \x01(\tR\rdestinationId\x12&\n" +
+	"\x0fold_snapshot_id\x18\x04 \x01(\tR\roldSnapshotId\x12&\n" +
+	"\x0fnew_snapshot_id\x18\x05 \x01(\tR\rnewSnapshotId\"&\n" +
+	"\x14SnapshotHoldResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\"\x82\x03\n" +
+	"\vJobProgress\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12%\n" +
+	"\x0edestination_id\x18\x03 \x01(\tR\rdestinationId\x12!\n" +
+	"\fpercent_done\x18\x04 \x01(\x01R\vpercentDone\x12\x1d\n" +
+	"\n" +
+	"files_done\x18\x05 \x01(\x04R\tfilesDone\x12\x1f\n" +
+	"\vtotal_files\x18\x06 \x01(\x04R\n" +
+	"totalFiles\x12\x1d\n" +
+	"\n" +
+	"bytes_done\x18\a \x01(\x04R\tbytesDone\x12\x1f\n" +
+	"\vtotal_bytes\x18\b \x01(\x04R\n" +
+	"totalBytes\x12!\n" +
+	"\fcurrent_file\x18\t \x01(\tR\vcurrentFile\x12+\n" +
+	"\x11seconds_remaining\x18\n" +
+	" \x01(\x04R\x10secondsRemaining\x12'\n" +
+	"\x0fseconds_elapsed\x18\v \x01(\x04R\x0esecondsElapsed\"%\n" +
+	"\x13JobProgressResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\"\x91\x01\n" +
+	"\x0fKeyEscrowReport\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x124\n" +
+	"\x16escrow_key_fingerprint\x18\x02 \x01(\tR\x14escrowKeyFingerprint\x12\x10\n" +
+	"\x03kit\x18\x03 \x01(\fR\x03kit\x12\x1b\n" +
+	"\tkey_count\x18\x04 \x01(\rR\bkeyCount\"#\n" +
+	"\x11KeyEscrowResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\"{\n" +
+	"\x0eKeyShareReport\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x19\n" +
+	"\bshare_id\x18\x02 \x01(\tR\ashareId\x12\x1d\n" +
+	"\n" +
+	"sealed_key\x18\x03 \x01(\fR\tsealedKey\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\"\n" +
+	"\x10KeyShareResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\"\x9a\x01\n" +
+	"\x14RepositoryScanReport\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1b\n" +
+	"\timport_id\x18\x02 \x01(\tR\bimportId\x124\n" +
+	"\tsnapshots\x18\x03 \x03(\v2\x16.agent.ScannedSnapshotR\tsnapshots\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\xe6\x01\n" +
+	"\x0fScannedSnapshot\x12\x1f\n" +
+	"\vsnapshot_id\x18\x01 \x01(\tR\n" +
+	"snapshotId\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x1a\n" +
+	"\bhostname\x18\x03 \x01(\tR\bhostname\x12\x14\n" +
+	"\x05paths\x18\x04 \x03(\tR\x05paths\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x06 \x01(\x03R\tsizeBytes\x12\x1d\n" +
+	"\n" +
+	"file_count\x18\a \x01(\x03R\tfileCount\"(\n" +
+	"\x16RepositoryScanResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok*\x8b\x02\n" +
 	"\aJobType\x12\x18\n" +
 	"\x14JOB_TYPE_UNSPECIFIED\x10\x00\x12\x13\n" +
 	"\x0fJOB_TYPE_BACKUP\x10\x01\x12\x13\n" +
 	"\x0fJOB_TYPE_VERIFY\x10\x02\x12\x14\n" +
 	"\x10JOB_TYPE_RESTORE\x10\x03\x12\x13\n" +
 	"\x0fJOB_TYPE_FORGET\x10\x04\x12\x19\n" +
-	"\x15JOB_TYPE_LIST_VOLUMES\x10\x05*\x8a\x01\n" +
+	"\x15JOB_TYPE_LIST_VOLUMES\x10\x05\x12\x12\n" +
+	"\x0eJOB_TYPE_ABORT\x10\x06\x12\x14\n" +
+	"\x10JOB_TYPE_DRY_RUN\x10\a\x12\x16\n" +
+	"\x12JOB_TYPE_REPLICATE\x10\b\x12\x16\n" +
+	"\x12JOB_TYPE_SHARE_KEY\x10\t\x12\x1c\n" +
+	"\x18JOB_TYPE_SCAN_REPOSITORY\x10\n" +
+	"*\x9b\x01\n" +
+	"\fFailureClass\x12\x1d\n" +
+	"\x19FAILURE_CLASS_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15FAILURE_CLASS_NETWORK\x10\x01\x12\x16\n" +
+	"\x12FAILURE_CLASS_HOOK\x10\x02\x12 \n" +
+	"\x1cFAILURE_CLASS_WRONG_PASSWORD\x10\x03\x12\x17\n" +
+	"\x13FAILURE_CLASS_OTHER\x10\x04*\x8a\x01\n" +
 	"\tJobStatus\x12\x1a\n" +
 	"\x16JOB_STATUS_UNSPECIFIED\x10\x00\x12\x16\n" +
 	"\x12JOB_STATUS_RUNNING\x10\x01\x12\x18\n" +
@@ -1449,7 +3040,7 @@ const file_agent_proto_rawDesc = "" +
 	"\x0fLOG_LEVEL_DEBUG\x10\x01\x12\x12\n" +
 	"\x0eLOG_LEVEL_INFO\x10\x02\x12\x12\n" +
 	"\x0eLOG_LEVEL_WARN\x10\x03\x12\x13\n" +
-	"\x0fLOG_LEVEL_ERROR\x10\x042\xf0\x03\n" +
+	"\x0fLOG_LEVEL_ERROR\x10\x042\x97\a\n" +
 	"\fAgentService\x12;\n" +
 	"\bRegister\x12\x16.agent.RegisterRequest\x1a\x17.agent.RegisterResponse\x12>\n" +
 	"\tHeartbeat\x12\x17.agent.HeartbeatRequest\x1a\x18.agent.HeartbeatResponse\x12>\n" +
@@ -1459,7 +3050,13 @@ const file_agent_proto_rawDesc = "" +
 	"\x17ReportDestinationStatus\x12\x1e.agent.DestinationStatusReport\x1a .agent.DestinationStatusResponse\x129\n" +
 	"\n" +
 	"StreamLogs\x12\x0f.agent.LogEntry\x1a\x18.agent.LogStreamResponse(\x01\x12F\n" +
-	"\x10ReportVolumeList\x12\x17.agent.VolumeListReport\x1a\x19.agent.VolumeListResponseB*Z(github.com/arkeep-io/arkeep/shared/protob\x06proto3"
+	"\x10ReportVolumeList\x12\x17.agent.VolumeListReport\x1a\x19.agent.VolumeListResponse\x12:\n" +
+	"\fReportDryRun\x12\x13.agent.DryRunReport\x1a\x15.agent.DryRunResponse\x12L\n" +
+	"\x12ReportSnapshotHold\x12\x19.agent.SnapshotHoldReport\x1a\x1b.agent.SnapshotHoldResponse\x12@\n" +
+	"\x0eReportProgress\x12\x12.agent.JobProgress\x1a\x1a.agent.JobProgressResponse\x12C\n" +
+	"\x0fReportKeyEscrow\x12\x16.agent.KeyEscrowReport\x1a\x18.agent.KeyEscrowResponse\x12@\n" +
+	"\x0eReportKeyShare\x12\x15.agent.KeyShareReport\x1a\x17.agent.KeyShareResponse\x12R\n" +
+	"\x14ReportRepositoryScan\x12\x1b.agent.RepositoryScanReport\x1a\x1d.agent.RepositoryScanResponseB*Z(github.com/arkeep-io/arkeep/shared/protob\x06proto3"
 
 var (
 	file_agent_proto_rawDescOnce sync.Once
@@ -1473,61 +3070,101 @@ func file_agent_proto_rawDescGZIP() []byte {
 	return file_agent_proto_rawDescData
 }
 
-var file_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
 var file_agent_proto_goTypes = []any{
 	(JobType)(0),                      // 0: agent.JobType
-	(JobStatus)(0),                    // 1: agent.JobStatus
-	(LogLevel)(0),                     // 2: agent.LogLevel
-	(*RegisterRequest)(nil),           // 3: agent.RegisterRequest
-	(*AgentCapabilities)(nil),         // 4: agent.AgentCapabilities
-	(*RegisterResponse)(nil),          // 5: agent.RegisterResponse
-	(*HeartbeatRequest)(nil),          // 6: agent.HeartbeatRequest
-	(*SystemMetrics)(nil),             // 7: agent.SystemMetrics
-	(*HeartbeatResponse)(nil),         // 8: agent.HeartbeatResponse
-	(*StreamJobsRequest)(nil),         // 9: agent.StreamJobsRequest
-	(*JobAssignment)(nil),             // 10: agent.JobAssignment
-	(*JobStatusReport)(nil),           // 11: agent.JobStatusReport
-	(*JobStatusResponse)(nil),         // 12: agent.JobStatusResponse
-	(*DestinationStatusReport)(nil),   // 13: agent.DestinationStatusReport
-	(*DestinationStatusResponse)(nil), // 14: agent.DestinationStatusResponse
-	(*LogEntry)(nil),                  // 15: agent.LogEntry
-	(*LogStreamResponse)(nil),         // 16: agent.LogStreamResponse
-	(*VolumeInfo)(nil),                // 17: agent.VolumeInfo
-	(*VolumeListReport)(nil),          // 18: agent.VolumeListReport
-	(*VolumeListResponse)(nil),        // 19: agent.VolumeListResponse
-	(*timestamppb.Timestamp)(nil),     // 20: google.protobuf.Timestamp
+	(FailureClass)(0),                 // 1: agent.FailureClass
+	(JobStatus)(0),                    // 2: agent.JobStatus
+	(LogLevel)(0),                     // 3: agent.LogLevel
+	(*RegisterRequest)(nil),           // 4: agent.RegisterRequest
+	(*AgentCapabilities)(nil),         // 5: agent.AgentCapabilities
+	(*RegisterResponse)(nil),          // 6: agent.RegisterResponse
+	(*HeartbeatRequest)(nil),          // 7: agent.HeartbeatRequest
+	(*SystemMetrics)(nil),             // 8: agent.SystemMetrics
+	(*HeartbeatResponse)(nil),         // 9: agent.HeartbeatResponse
+	(*StreamJobsRequest)(nil),         // 10: agent.StreamJobsRequest
+	(*JobAssignment)(nil),             // 11: agent.JobAssignment
+	(*JobStatusReport)(nil),           // 12: agent.JobStatusReport
+	(*JobStatusResponse)(nil),         // 13: agent.JobStatusResponse
+	(*DestinationStatusReport)(nil),   // 14: agent.DestinationStatusReport
+	(*StdinSnapshot)(nil),             // 15: agent.StdinSnapshot
+	(*CopiedSnapshot)(nil),            // 16: agent.CopiedSnapshot
+	(*FileError)(nil),                 // 17: agent.FileError
+	(*DestinationStatusResponse)(nil), // 18: agent.DestinationStatusResponse
+	(*LogEntry)(nil),                  // 19: agent.LogEntry
+	(*LogStreamResponse)(nil),         // 20: agent.LogStreamResponse
+	(*VolumeInfo)(nil),                // 21: agent.VolumeInfo
+	(*VolumeListReport)(nil),          // 22: agent.VolumeListReport
+	(*VolumeListResponse)(nil),        // 23: agent.VolumeListResponse
+	(*DirectorySize)(nil),             // 24: agent.DirectorySize
+	(*UnreadablePath)(nil),            // 25: agent.UnreadablePath
+	(*DryRunReport)(nil),              // 26: agent.DryRunReport
+	(*DryRunResponse)(nil),            // 27: agent.DryRunResponse
+	(*SnapshotHoldReport)(nil),        // 28: agent.SnapshotHoldReport
+	(*SnapshotHoldResponse)(nil),      // 29: agent.SnapshotHoldResponse
+	(*JobProgress)(nil),               // 30: agent.JobProgress
+	(*JobProgressResponse)(nil),       // 31: agent.JobProgressResponse
+	(*KeyEscrowReport)(nil),           // 32: agent.KeyEscrowReport
+	(*KeyEscrowResponse)(nil),         // 33: agent.KeyEscrowResponse
+	(*KeyShareReport)(nil),            // 34: agent.KeyShareReport
+	(*KeyShareResponse)(nil),          // 35: agent.KeyShareResponse
+	(*RepositoryScanReport)(nil),      // 36: agent.RepositoryScanReport
+	(*ScannedSnapshot)(nil),           // 37: agent.ScannedSnapshot
+	(*RepositoryScanResponse)(nil),    // 38: agent.RepositoryScanResponse
+	(*timestamppb.Timestamp)(nil),     // 39: google.protobuf.Timestamp
 }
 var file_agent_proto_depIdxs = []int32{
-	4,  // 0: agent.RegisterRequest.capabilities:type_name -> agent.AgentCapabilities
-	7,  // 1: agent.HeartbeatRequest.metrics:type_name -> agent.SystemMetrics
+	5,  // 0: agent.RegisterRequest.capabilities:type_name -> agent.AgentCapabilities
+	8,  // 1: agent.HeartbeatRequest.metrics:type_name -> agent.SystemMetrics
 	0,  // 2: agent.JobAssignment.type:type_name -> agent.JobType
-	20, // 3: agent.JobAssignment.scheduled_at:type_name -> google.protobuf.Timestamp
-	1,  // 4: agent.JobStatusReport.status:type_name -> agent.JobStatus
-	20, // 5: agent.JobStatusReport.timestamp:type_name -> google.protobuf.Timestamp
-	20, // 6: agent.DestinationStatusReport.started_at:type_name -> google.protobuf.Timestamp
-	2,  // 7: agent.LogEntry.level:type_name -> agent.LogLevel
-	20, // 8: agent.LogEntry.timestamp:type_name -> google.protobuf.Timestamp
-	17, // 9: agent.VolumeListReport.volumes:type_name -> agent.VolumeInfo
-	3,  // 10: agent.AgentService.Register:input_type -> agent.RegisterRequest
-	6,  // 11: agent.AgentService.Heartbeat:input_type -> agent.HeartbeatRequest
-	9,  // 12: agent.AgentService.StreamJobs:input_type -> agent.StreamJobsRequest
-	11, // 13: agent.AgentService.ReportJobStatus:input_type -> agent.JobStatusReport
-	13, // 14: agent.AgentService.ReportDestinationStatus:input_type -> agent.DestinationStatusReport
-	15, // 15: agent.AgentService.StreamLogs:input_type -> agent.LogEntry
-	18, // 16: agent.AgentService.ReportVolumeList:input_type -> agent.VolumeListReport
-	5,  // 17: agent.AgentService.Register:output_type -> agent.RegisterResponse
-	8,  // 18: agent.AgentService.Heartbeat:output_type -> agent.HeartbeatResponse
-	10, // 19: agent.AgentService.StreamJobs:output_type -> agent.JobAssignment
-	12, // 20: agent.AgentService.ReportJobStatus:output_type -> agent.JobStatusResponse
-	14, // 21: agent.AgentService.ReportDestinationStatus:output_type -> agent.DestinationStatusResponse
-	16, // 22: agent.AgentService.StreamLogs:output_type -> agent.LogStreamResponse
-	19, // 23: agent.AgentService.ReportVolumeList:output_type -> agent.VolumeListResponse
-	17, // [17:24] is the sub-list for method output_type
-	10, // [10:17] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	39, // 3: agent.JobAssignment.scheduled_at:type_name -> google.protobuf.Timestamp
+	2,  // 4: agent.JobStatusReport.status:type_name -> agent.JobStatus
+	39, // 5: agent.JobStatusReport.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 6: agent.JobStatusReport.failure_class:type_name -> agent.FailureClass
+	39, // 7: agent.DestinationStatusReport.started_at:type_name -> google.protobuf.Timestamp
+	17, // 8: agent.DestinationStatusReport.file_errors:type_name -> agent.FileError
+	16, // 9: agent.DestinationStatusReport.copied_snapshots:type_name -> agent.CopiedSnapshot
+	15, // 10: agent.DestinationStatusReport.stdin_snapshots:type_name -> agent.StdinSnapshot
+	39, // 11: agent.CopiedSnapshot.time:type_name -> google.protobuf.Timestamp
+	3,  // 12: agent.LogEntry.level:type_name -> agent.LogLevel
+	39, // 13: agent.LogEntry.timestamp:type_name -> google.protobuf.Timestamp
+	21, // 14: agent.VolumeListReport.volumes:type_name -> agent.VolumeInfo
+	24, // 15: agent.DryRunReport.largest_directories:type_name -> agent.DirectorySize
+	25, // 16: agent.DryRunReport.unreadable_paths:type_name -> agent.UnreadablePath
+	37, // 17: agent.RepositoryScanReport.snapshots:type_name -> agent.ScannedSnapshot
+	39, // 18: agent.ScannedSnapshot.time:type_name -> google.protobuf.Timestamp
+	4,  // 19: agent.AgentService.Register:input_type -> agent.RegisterRequest
+	7,  // 20: agent.AgentService.Heartbeat:input_type -> agent.HeartbeatRequest
+	10, // 21: agent.AgentService.StreamJobs:input_type -> agent.StreamJobsRequest
+	12, // 22: agent.AgentService.ReportJobStatus:input_type -> agent.JobStatusReport
+	14, // 23: agent.AgentService.ReportDestinationStatus:input_type -> agent.DestinationStatusReport
+	19, // 24: agent.AgentService.StreamLogs:input_type -> agent.LogEntry
+	22, // 25: agent.AgentService.ReportVolumeList:input_type -> agent.VolumeListReport
+	26, // 26: agent.AgentService.ReportDryRun:input_type -> agent.DryRunReport
+	28, // 27: agent.AgentService.ReportSnapshotHold:input_type -> agent.SnapshotHoldReport
+	30, // 28: agent.AgentService.ReportProgress:input_type -> agent.JobProgress
+	32, // 29: agent.AgentService.ReportKeyEscrow:input_type -> agent.KeyEscrowReport
+	34, // 30: agent.AgentService.ReportKeyShare:input_type -> agent.KeyShareReport
+	36, // 31: agent.AgentService.ReportRepositoryScan:input_type -> agent.RepositoryScanReport
+	6,  // 32: agent.AgentService.Register:output_type -> agent.RegisterResponse
+	9,  // 33: agent.AgentService.Heartbeat:output_type -> agent.HeartbeatResponse
+	11, // 34: agent.AgentService.StreamJobs:output_type -> agent.JobAssignment
+	13, // 35: agent.AgentService.ReportJobStatus:output_type -> agent.JobStatusResponse
+	18, // 36: agent.AgentService.ReportDestinationStatus:output_type -> agent.DestinationStatusResponse
+	20, // 37: agent.AgentService.StreamLogs:output_type -> agent.LogStreamResponse
+	23, // 38: agent.AgentService.ReportVolumeList:output_type -> agent.VolumeListResponse
+	27, // 39: agent.AgentService.ReportDryRun:output_type -> agent.DryRunResponse
+	29, // 40: agent.AgentService.ReportSnapshotHold:output_type -> agent.SnapshotHoldResponse
+	31, // 41: agent.AgentService.ReportProgress:output_type -> agent.JobProgressResponse
+	33, // 42: agent.AgentService.ReportKeyEscrow:output_type -> agent.KeyEscrowResponse
+	35, // 43: agent.AgentService.ReportKeyShare:output_type -> agent.KeyShareResponse
+	38, // 44: agent.AgentService.ReportRepositoryScan:output_type -> agent.RepositoryScanResponse
+	32, // [32:45] is the sub-list for method output_type
+	19, // [19:32] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_agent_proto_init() }
@@ -1540,8 +3177,8 @@ func file_agent_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_proto_rawDesc), len(file_agent_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   17,
+			NumEnums:      4,
+			NumMessages:   35,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -26,6 +26,12 @@ const (
 	AgentService_ReportDestinationStatus_FullMethodName = "/agent.AgentService/ReportDestinationStatus"
 	AgentService_StreamLogs_FullMethodName              = "/agent.AgentService/StreamLogs"
 	AgentService_ReportVolumeList_FullMethodName        = "/agent.AgentService/ReportVolumeList"
+	AgentService_ReportDryRun_FullMethodName            = "/agent.AgentService/ReportDryRun"
+	AgentService_ReportSnapshotHold_FullMethodName      = "/agent.AgentService/ReportSnapshotHold"
+	AgentService_ReportProgress_FullMethodName          = "/agent.AgentService/ReportProgress"
+	AgentService_ReportKeyEscrow_FullMethodName         = "/agent.AgentService/ReportKeyEscrow"
+	AgentService_ReportKeyShare_FullMethodName          = "/agent.AgentService/ReportKeyShare"
+	AgentService_ReportRepositoryScan_FullMethodName    = "/agent.AgentService/ReportRepositoryScan"
 )
 
 // AgentServiceClient is the client API for AgentService service.
@@ -86,6 +92,31 @@ type AgentServiceClient interface {
 	// back to the server, which correlates the response to the waiting REST request
 	// via the correlation_id carried in the job_id field of the JobAssignment.
 	ReportVolumeList(ctx context.Context, in *VolumeListReport, opts ...grpc.CallOption) (*VolumeListResponse, error)
+	// ReportDryRun is called by the agent once a JOB_TYPE_DRY_RUN job has
+	// scanned the policy's sources, before the final ReportJobStatus. It carries
+	// the size estimate the server stores with the job.
+	ReportDryRun(ctx context.Context, in *DryRunReport, opts ...grpc.CallOption) (*DryRunResponse, error)
+	// ReportSnapshotHold is called by the agent after it tagged a snapshot as
+	// held, as instructed by a DestinationStatusResponse. restic rewrites a
+	// snapshot when its tags change, so the report carries the new snapshot ID.
+	ReportSnapshotHold(ctx context.Context, in *SnapshotHoldReport, opts ...grpc.CallOption) (*SnapshotHoldResponse, error)
+	// ReportProgress is called by the agent while a backup or dry run is
+	// running, at most once per throttle interval. The server keeps only the
+	// latest report per job and relays it to the GUI as a job.progress message.
+	ReportProgress(ctx context.Context, in *JobProgress, opts ...grpc.CallOption) (*JobProgressResponse, error)
+	// ReportKeyEscrow is called by the agent after the repository keys of its
+	// zero-knowledge policies changed, or when the server announced a new
+	// escrow key. The kit is sealed to the admin's escrow key: the server only
+	// stores it.
+	ReportKeyEscrow(ctx context.Context, in *KeyEscrowReport, opts ...grpc.CallOption) (*KeyEscrowResponse, error)
+	// ReportKeyShare is called by the agent in response to a
+	// JOB_TYPE_SHARE_KEY assignment, with the repository key sealed to the
+	// recipient agent's public key.
+	ReportKeyShare(ctx context.Context, in *KeyShareReport, opts ...grpc.CallOption) (*KeyShareResponse, error)
+	// ReportRepositoryScan is called by the agent in response to a
+	// JOB_TYPE_SCAN_REPOSITORY assignment, with the snapshots of the scanned
+	// repository.
+	ReportRepositoryScan(ctx context.Context, in *RepositoryScanReport, opts ...grpc.CallOption) (*RepositoryScanResponse, error)
 }
 
 type agentServiceClient struct {
@@ -178,6 +209,66 @@ func (c *agentServiceClient) ReportVolumeList(ctx context.Context, in *VolumeLis
 	return out, nil
 }
 
+func (c *agentServiceClient) ReportDryRun(ctx context.Context, in *DryRunReport, opts ...grpc.CallOption) (*DryRunResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DryRunResponse)
+	err := c.cc.Invoke(ctx, AgentService_ReportDryRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ReportSnapshotHold(ctx context.Context, in *SnapshotHoldReport, opts ...grpc.CallOption) (*SnapshotHoldResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SnapshotHoldResponse)
+	err := c.cc.Invoke(ctx, AgentService_ReportSnapshotHold_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ReportProgress(ctx context.Context, in *JobProgress, opts ...grpc.CallOption) (*JobProgressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JobProgressResponse)
+	err := c.cc.Invoke(ctx, AgentService_ReportProgress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ReportKeyEscrow(ctx context.Context, in *KeyEscrowReport, opts ...grpc.CallOption) (*KeyEscrowResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KeyEscrowResponse)
+	err := c.cc.Invoke(ctx, AgentService_ReportKeyEscrow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ReportKeyShare(ctx context.Context, in *KeyShareReport, opts ...grpc.CallOption) (*KeyShareResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KeyShareResponse)
+	err := c.cc.Invoke(ctx, AgentService_ReportKeyShare_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ReportRepositoryScan(ctx context.Context, in *RepositoryScanReport, opts ...grpc.CallOption) (*RepositoryScanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RepositoryScanResponse)
+	err := c.cc.Invoke(ctx, AgentService_ReportRepositoryScan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgentServiceServer is the server API for AgentService service.
 // All implementations must embed UnimplementedAgentServiceServer
 // for forward compatibility.
@@ -236,6 +327,31 @@ type AgentServiceServer interface {
 	// back to the server, which correlates the response to the waiting REST request
 	// via the correlation_id carried in the job_id field of the JobAssignment.
 	ReportVolumeList(context.Context, *VolumeListReport) (*VolumeListResponse, error)
+	// ReportDryRun is called by the agent once a JOB_TYPE_DRY_RUN job has
+	// scanned the policy's sources, before the final ReportJobStatus. It carries
+	// the size estimate the server stores with the job.
+	ReportDryRun(context.Context, *DryRunReport) (*DryRunResponse, error)
+	// ReportSnapshotHold is called by the agent after it tagged a snapshot as
+	// held, as instructed by a DestinationStatusResponse. restic rewrites a
+	// snapshot when its tags change, so the report carries the new snapshot ID.
+	ReportSnapshotHold(context.Context, *SnapshotHoldReport) (*SnapshotHoldResponse, error)
+	// ReportProgress is called by the agent while a backup or dry run is
+	// running, at most once per throttle interval. The server keeps only the
+	// latest report per job and relays it to the GUI as a job.progress message.
+	ReportProgress(context.Context, *JobProgress) (*JobProgressResponse, error)
+	// ReportKeyEscrow is called by the agent after the repository keys of its
+	// zero-knowledge policies changed, or when the server announced a new
+	// escrow key. The kit is sealed to the admin's escrow key: the server only
+	// stores it.
+	ReportKeyEscrow(context.Context, *KeyEscrowReport) (*KeyEscrowResponse, error)
+	// ReportKeyShare is called by the agent in response to a
+	// JOB_TYPE_SHARE_KEY assignment, with the repository key sealed to the
+	// recipient agent's public key.
+	ReportKeyShare(context.Context, *KeyShareReport) (*KeyShareResponse, error)
+	// ReportRepositoryScan is called by the agent in response to a
+	// JOB_TYPE_SCAN_REPOSITORY assignment, with the snapshots of the scanned
+	// repository.
+	ReportRepositoryScan(context.Context, *RepositoryScanReport) (*RepositoryScanResponse, error)
 	mustEmbedUnimplementedAgentServiceServer()
 }
 
@@ -267,6 +383,24 @@ func (UnimplementedAgentServiceServer) StreamLogs(grpc.ClientStreamingServer[Log
 func (UnimplementedAgentServiceServer) ReportVolumeList(context.Context, *VolumeListReport) (*VolumeListResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ReportVolumeList not implemented")
 }
+func (UnimplementedAgentServiceServer) ReportDryRun(context.Context, *DryRunReport) (*DryRunResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReportDryRun not implemented")
+}
+func (UnimplementedAgentServiceServer) ReportSnapshotHold(context.Context, *SnapshotHoldReport) (*SnapshotHoldResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReportSnapshotHold not implemented")
+}
+func (UnimplementedAgentServiceServer) ReportProgress(context.Context, *JobProgress) (*JobProgressResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReportProgress not implemented")
+}
+func (UnimplementedAgentServiceServer) ReportKeyEscrow(context.Context, *KeyEscrowReport) (*KeyEscrowResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReportKeyEscrow not implemented")
+}
+func (UnimplementedAgentServiceServer) ReportKeyShare(context.Context, *KeyShareReport) (*KeyShareResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReportKeyShare not implemented")
+}
+func (UnimplementedAgentServiceServer) ReportRepositoryScan(context.Context, *RepositoryScanReport) (*RepositoryScanResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReportRepositoryScan not implemented")
+}
 func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
 func (UnimplementedAgentServiceServer) testEmbeddedByValue()                      {}
 
@@ -396,6 +530,114 @@ func _AgentService_ReportVolumeList_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_ReportDryRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DryRunReport)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ReportDryRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ReportDryRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ReportDryRun(ctx, req.(*DryRunReport))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ReportSnapshotHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotHoldReport)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ReportSnapshotHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ReportSnapshotHold_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ReportSnapshotHold(ctx, req.(*SnapshotHoldReport))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ReportProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobProgress)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ReportProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ReportProgress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ReportProgress(ctx, req.(*JobProgress))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ReportKeyEscrow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyEscrowReport)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ReportKeyEscrow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ReportKeyEscrow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ReportKeyEscrow(ctx, req.(*KeyEscrowReport))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ReportKeyShare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyShareReport)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ReportKeyShare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ReportKeyShare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ReportKeyShare(ctx, req.(*KeyShareReport))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ReportRepositoryScan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RepositoryScanReport)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ReportRepositoryScan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ReportRepositoryScan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ReportRepositoryScan(ctx, req.(*RepositoryScanReport))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AgentService_ServiceDesc is the grpc.ServiceDesc for AgentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -423,6 +665,30 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReportVolumeList",
 			Handler:    _AgentService_ReportVolumeList_Handler,
 		},
+		{
+			MethodName: "ReportDryRun",
+			Handler:    _AgentService_ReportDryRun_Handler,
+		},
+		{
+			MethodName: "ReportSnapshotHold",
+			Handler:    _AgentService_ReportSnapshotHold_Handler,
+		},
+		{
+			MethodName: "ReportProgress",
+			Handler:    _AgentService_ReportProgress_Handler,
+		},
+		{
+			MethodName: "ReportKeyEscrow",
+			Handler:    _AgentService_ReportKeyEscrow_Handler,
+		},
+		{
+			MethodName: "ReportKeyShare",
+			Handler:    _AgentService_ReportKeyShare_Handler,
+		},
+		{
+			MethodName: "ReportRepositoryScan",
+			Handler:    _AgentService_ReportRepositoryScan_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
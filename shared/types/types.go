@@ -24,6 +24,7 @@ const (
 	JobStatusRunning   JobStatus = "running"
 	JobStatusCompleted JobStatus = "completed"
 	JobStatusFailed    JobStatus = "failed"
+	JobStatusPartial   JobStatus = "partial" // some destinations failed, others got a snapshot
 	JobStatusMissed    JobStatus = "missed"
 	JobStatusCancelled JobStatus = "cancelled"
 )